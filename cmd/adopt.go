@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ronsanzone/clawd-bay/internal/adopt"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var adoptPath string
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt <session>",
+	Short: "Bring an existing tmux session under ClawdBay management",
+	Long: `Adopts a running tmux session without recreating it: the session is renamed
+with the cb_ prefix (if it lacks one) and pinned to a home path so it is grouped
+under its worktree in cb dash and cb list.
+
+The home path defaults to the session's first pane directory.
+
+Example:
+  cb adopt scratch
+  cb adopt scratch --path ~/code/repo/.worktrees/repo-feature`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdopt,
+}
+
+func init() {
+	adoptCmd.Flags().StringVar(&adoptPath, "path", "", "worktree to pin the session to (default: its first pane's directory)")
+	rootCmd.AddCommand(adoptCmd)
+}
+
+func runAdopt(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	managed, home, err := adopt.Session(tmuxClient, args[0], adoptPath)
+	if errors.Is(err, adopt.ErrNoHomePath) {
+		return fmt.Errorf("%w; pass --path", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	recordStartedSession(tmuxClient, managed, home, os.Stderr)
+	if managed != args[0] {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Adopted %s as %s (home: %s)\n", args[0], managed, home)
+	} else {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Adopted %s (home: %s)\n", managed, home)
+	}
+	return nil
+}
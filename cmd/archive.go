@@ -2,69 +2,107 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/git"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
+var archiveForce bool
+var archiveKillAgents bool
+var archivePath string
+
 var archiveCmd = &cobra.Command{
-	Use:   "archive [session-name]",
+	Use:   "archive [session-name | --path <worktree>]",
 	Short: "Archive workflow (kill session + remove worktree, keep branch)",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		tmuxClient := tmux.NewClient()
-		var sessionName string
-		var worktreePath string
+	Long: `Kills the workflow's tmux session and removes its worktree, keeping the branch.
 
-		if len(args) > 0 {
-			sessionName = args[0]
-			if !strings.HasPrefix(sessionName, "cb_") {
-				sessionName = "cb_" + sessionName
-			}
+Archiving is refused when the worktree has uncommitted changes or commits that
+have not been pushed; pass --force to archive anyway and discard them.
+
+With --kill-agents, coding agent processes that outlived the session (started
+with nohup, or reparented after their pane closed) and still work inside the
+worktree are sent SIGTERM before it is removed, so they cannot keep writing to
+or holding the directory. They are found with ps and lsof.
+
+With --path, the workflow is addressed by its worktree directory instead,
+which also works when its session is already gone: the session pinned to the
+worktree is killed if there is one, and the worktree is removed either way.
+Only linked worktrees are accepted, never a repository's main checkout.
 
-			// Try to find worktree path from session's pane
-			paneDir := tmuxClient.GetPaneWorkingDir(sessionName)
-			if paneDir != "" {
-				worktreePath = paneDir
+Example:
+  cb archive feat-auth
+  cb archive --path ~/src/app/.worktrees/app-feat-auth`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tmuxClient := tmux.NewClient()
+		var sessionName, worktreePath string
+		var err error
+		if archivePath != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("use either a session name or --path, not both")
 			}
+			sessionName, worktreePath, err = resolveArchivePath(tmuxClient, git.NewClient(), archivePath)
 		} else {
-			// Detect session from current directory
-			cwd, err := os.Getwd()
+			sessionName, worktreePath, err = resolveWorkflowTarget(tmuxClient, args)
+		}
+		if err != nil {
+			return err
+		}
+		label := sessionName
+		if label == "" {
+			label = worktreePath
+		}
+
+		if worktreePath != "" && !archiveForce {
+			report, err := checkArchiveLoss(git.NewClient(), worktreePath)
 			if err != nil {
-				return fmt.Errorf("failed to get current directory: %w", err)
+				return err
 			}
-			resolvedSessionName, resolvedWorktreePath, resolveErr := resolveSessionForCWD(tmuxClient, cwd)
-			if resolveErr != nil {
-				return resolveErr
+			if !report.empty() {
+				fmt.Print(report.String())
+				return fmt.Errorf("refusing to archive %s: work would be lost (use --force to archive anyway)", label)
 			}
-			sessionName = resolvedSessionName
-			worktreePath = resolvedWorktreePath
 		}
 
 		// Confirm
-		fmt.Printf("Archive workflow: %s\n", sessionName)
+		prompt := "This will kill the tmux session and remove the worktree. Continue? [y/N] "
+		if sessionName != "" {
+			fmt.Printf("Archive workflow: %s\n", sessionName)
+		} else {
+			fmt.Println("Archive workflow: no session is pinned to this worktree")
+			prompt = "This will remove the worktree. Continue? [y/N] "
+		}
 		if worktreePath != "" {
 			fmt.Printf("Worktree: %s\n", worktreePath)
 		}
-		fmt.Print("This will kill the tmux session and remove the worktree. Continue? [y/N] ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, _ := reader.ReadString('\n')
-		response = strings.TrimSpace(strings.ToLower(response))
-
-		if response != "y" && response != "yes" {
+		if !confirm(os.Stdin, prompt) {
 			fmt.Println("Cancelled")
 			return nil
 		}
 
-		// Kill tmux session
-		fmt.Println("Killing tmux session...")
-		killCmd := exec.Command("tmux", "kill-session", "-t", sessionName)
-		_ = killCmd.Run() // Ignore error if session doesn't exist
+		var branch string
+		if sessionName != "" {
+			branch, _ = tmuxClient.GetSessionOption(sessionName, tmux.SessionOptionBranch)
+
+			// Kill tmux session
+			fmt.Println("Killing tmux session...")
+			killSession(tmuxClient, sessionName, os.Stderr)
+			forgetSession(sessionName, os.Stderr)
+		} else {
+			branch, _ = git.NewClient().CurrentBranch(worktreePath)
+		}
+		if archiveKillAgents && worktreePath != "" {
+			killStrayAgents(worktreePath, os.Stdout, os.Stderr)
+		}
 
 		// Remove worktree if we detected it
 		if worktreePath != "" {
@@ -76,7 +114,12 @@ var archiveCmd = &cobra.Command{
 				return fmt.Errorf("failed to change to parent directory: %w", err)
 			}
 
-			removeCmd := exec.Command("git", "worktree", "remove", worktreePath)
+			removeArgs := []string{"worktree", "remove"}
+			if archiveForce {
+				removeArgs = append(removeArgs, "--force")
+			}
+			removeArgs = append(removeArgs, worktreePath)
+			removeCmd := exec.Command("git", removeArgs...)
 			removeCmd.Stdout = os.Stdout
 			removeCmd.Stderr = os.Stderr
 			if err := removeCmd.Run(); err != nil {
@@ -84,11 +127,126 @@ var archiveCmd = &cobra.Command{
 			}
 		}
 
-		fmt.Println("Workflow archived. Branch preserved.")
+		if branch != "" {
+			fmt.Printf("Workflow archived. Branch %s preserved.\n", branch)
+		} else {
+			fmt.Println("Workflow archived. Branch preserved.")
+		}
 		return nil
 	},
 }
 
 func init() {
+	archiveCmd.Flags().BoolVarP(&archiveForce, "force", "f", false, "archive even with uncommitted or unpushed changes")
+	archiveCmd.Flags().StringVar(&archivePath, "path", "", "archive the workflow of this worktree directory, with or without a session")
+	archiveCmd.Flags().BoolVar(&archiveKillAgents, "kill-agents", false, "terminate agent processes still running inside the worktree")
 	rootCmd.AddCommand(archiveCmd)
 }
+
+type archiveGitClient interface {
+	StatusPorcelain(dir string) ([]string, error)
+	UnpushedCommits(dir string) ([]string, error)
+}
+
+// archiveLossReport lists work that removing a worktree would put at risk.
+type archiveLossReport struct {
+	Uncommitted []string
+	Unpushed    []string
+}
+
+func (r archiveLossReport) empty() bool {
+	return len(r.Uncommitted) == 0 && len(r.Unpushed) == 0
+}
+
+func (r archiveLossReport) String() string {
+	var b strings.Builder
+	if len(r.Uncommitted) > 0 {
+		fmt.Fprintf(&b, "Uncommitted changes:\n%s\n", formatStatusEntries(r.Uncommitted))
+	}
+	if len(r.Unpushed) > 0 {
+		b.WriteString("Unpushed commits:\n")
+		for _, c := range r.Unpushed {
+			fmt.Fprintf(&b, "  %s\n", c)
+		}
+	}
+	return b.String()
+}
+
+// checkArchiveLoss inspects worktreePath for uncommitted and unpushed work.
+func checkArchiveLoss(gitClient archiveGitClient, worktreePath string) (archiveLossReport, error) {
+	uncommitted, err := gitClient.StatusPorcelain(worktreePath)
+	if err != nil {
+		return archiveLossReport{}, err
+	}
+	unpushed, err := gitClient.UnpushedCommits(worktreePath)
+	if err != nil {
+		return archiveLossReport{}, err
+	}
+	return archiveLossReport{Uncommitted: uncommitted, Unpushed: unpushed}, nil
+}
+
+type mainWorktreeFinder interface {
+	MainWorktree(dir string) (string, error)
+}
+
+// resolveArchivePath returns the session pinned to the worktree at path, or
+// "" when there is none, and the worktree's canonical path. Only linked
+// worktrees are accepted; a main checkout is refused.
+func resolveArchivePath(tmuxClient sessionResolver, gitClient mainWorktreeFinder, path string) (sessionName string, worktreePath string, err error) {
+	worktreePath, err = config.CanonicalPath(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve worktree path %s: %w", path, err)
+	}
+	mainPath, err := gitClient.MainWorktree(worktreePath)
+	if err != nil {
+		return "", "", fmt.Errorf("%s is not a git worktree: %w", worktreePath, err)
+	}
+	if canonical, err := config.CanonicalPath(mainPath); err == nil {
+		mainPath = canonical
+	}
+	if mainPath == worktreePath {
+		return "", "", fmt.Errorf("%s is a repository's main checkout, not a linked worktree", worktreePath)
+	}
+	sessionName, _ = sessionForWorktree(tmuxClient, worktreePath)
+	return sessionName, worktreePath, nil
+}
+
+// resolveWorkflowTarget returns the session and worktree addressed by an
+// optional session-name argument, falling back to the current directory.
+func resolveWorkflowTarget(tmuxClient *tmux.Client, args []string) (sessionName string, worktreePath string, err error) {
+	if len(args) > 0 {
+		sessionName = resolveSessionName(tmuxClient, args[0])
+
+		// Prefer the pinned home path so a pane that wandered elsewhere
+		// (e.g. into the main repo) can't redirect worktree removal.
+		return sessionName, sessionHomePath(tmuxClient, sessionName), nil
+	}
+
+	// Detect session from current directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return resolveSessionForCWD(tmuxClient, cwd)
+}
+
+// confirm prints prompt and reports whether the reply was y/yes.
+func confirm(in io.Reader, prompt string) bool {
+	fmt.Print(prompt)
+
+	reader := bufio.NewReader(in)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+
+	return response == "y" || response == "yes"
+}
+
+// killSession kills the named tmux session. A session (or server) that is
+// already gone is not an error; any other failure is reported as a warning.
+func killSession(tmuxClient interface{ KillSession(name string) error }, sessionName string, errWriter io.Writer) {
+	err := tmuxClient.KillSession(sessionName)
+	if err == nil || errors.Is(err, tmux.ErrNoSession) || errors.Is(err, tmux.ErrNoServer) {
+		return
+	}
+	_, _ = fmt.Fprintf(errWriter, "Warning: failed to kill tmux session %s: %v\n", sessionName, err)
+}
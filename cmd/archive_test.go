@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeArchiveGitClient struct {
+	status    []string
+	unpushed  []string
+	statusErr error
+}
+
+func (f fakeArchiveGitClient) StatusPorcelain(dir string) ([]string, error) {
+	return f.status, f.statusErr
+}
+
+func (f fakeArchiveGitClient) UnpushedCommits(dir string) ([]string, error) {
+	return f.unpushed, nil
+}
+
+func TestCheckArchiveLoss_Clean(t *testing.T) {
+	report, err := checkArchiveLoss(fakeArchiveGitClient{}, "/wt")
+	if err != nil {
+		t.Fatalf("checkArchiveLoss() error = %v", err)
+	}
+	if !report.empty() {
+		t.Fatalf("report = %+v, want empty", report)
+	}
+}
+
+func TestCheckArchiveLoss_ListsWorkAtRisk(t *testing.T) {
+	client := fakeArchiveGitClient{
+		status:   []string{" M cmd/start.go", "?? notes.txt"},
+		unpushed: []string{"abc123 add feature"},
+	}
+
+	report, err := checkArchiveLoss(client, "/wt")
+	if err != nil {
+		t.Fatalf("checkArchiveLoss() error = %v", err)
+	}
+	if report.empty() {
+		t.Fatal("report should not be empty")
+	}
+
+	out := report.String()
+	for _, want := range []string{"Uncommitted changes:", "   M cmd/start.go", "  ?? notes.txt", "Unpushed commits:", "  abc123 add feature"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("report missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCheckArchiveLoss_StatusError(t *testing.T) {
+	_, err := checkArchiveLoss(fakeArchiveGitClient{statusErr: errors.New("not a git repo")}, "/wt")
+	if err == nil {
+		t.Fatal("checkArchiveLoss() error = nil, want error")
+	}
+}
+
+type fakeKillClient struct {
+	err error
+}
+
+func (f fakeKillClient) KillSession(name string) error { return f.err }
+
+func TestKillSession_IgnoresMissingSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		warning bool
+	}{
+		{name: "killed", err: nil},
+		{name: "already gone", err: fmt.Errorf("failed to kill session: %w", tmux.ErrNoSession)},
+		{name: "no server", err: tmux.ErrNoServer},
+		{name: "other failure", err: errors.New("permission denied"), warning: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			killSession(fakeKillClient{err: tt.err}, "cb_demo", &out)
+			if got := out.Len() > 0; got != tt.warning {
+				t.Fatalf("warning = %q, want warning %v", out.String(), tt.warning)
+			}
+		})
+	}
+}
+
+type fakeMainWorktreeFinder struct {
+	main string
+	err  error
+}
+
+func (f fakeMainWorktreeFinder) MainWorktree(dir string) (string, error) {
+	return f.main, f.err
+}
+
+func TestResolveArchivePath(t *testing.T) {
+	repo, err := config.CanonicalPath(t.TempDir())
+	if err != nil {
+		t.Fatalf("CanonicalPath() error = %v", err)
+	}
+	pinned := filepath.Join(repo, ".worktrees", "repo-pinned")
+	orphan := filepath.Join(repo, ".worktrees", "repo-orphan")
+	for _, dir := range []string{pinned, orphan} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	resolver := fakeSessionResolver{
+		sessions: []tmux.Session{{Name: "cb_pinned"}},
+		homes:    map[string]string{"cb_pinned": pinned},
+	}
+	finder := fakeMainWorktreeFinder{main: repo}
+
+	tests := []struct {
+		name        string
+		path        string
+		finder      fakeMainWorktreeFinder
+		wantSession string
+		wantErr     string
+	}{
+		{name: "pinned session", path: pinned, finder: finder, wantSession: "cb_pinned"},
+		{name: "no session", path: orphan, finder: finder},
+		{name: "main checkout", path: repo, finder: finder, wantErr: "not a linked worktree"},
+		{name: "not a worktree", path: orphan, finder: fakeMainWorktreeFinder{err: errors.New("exit status 128")}, wantErr: "is not a git worktree"},
+		{name: "missing path", path: filepath.Join(repo, "nope"), finder: finder, wantErr: "failed to resolve worktree path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, worktree, err := resolveArchivePath(resolver, tt.finder, tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("resolveArchivePath() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveArchivePath() error = %v", err)
+			}
+			if session != tt.wantSession || worktree != tt.path {
+				t.Fatalf("resolveArchivePath() = (%q, %q), want (%q, %q)", session, worktree, tt.wantSession, tt.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var checkpointAll bool
+var checkpointMessage string
+
+var checkpointCmd = &cobra.Command{
+	Use:   "checkpoint [session-name]",
+	Short: "Commit all changes in a workflow's worktree",
+	Long: `Stages and commits every change (including untracked files) in a workflow's
+worktree with a generated "checkpoint: <timestamp>" message, protecting agent
+work between turns. The repository's commit hooks run as for any commit.
+
+Example:
+  cb checkpoint                 # Worktree for the current directory
+  cb checkpoint feat-auth       # Worktree owning cb_feat-auth
+  cb checkpoint --all           # Every active worktree
+  cb checkpoint -m "before refactor"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCheckpoint,
+}
+
+func init() {
+	checkpointCmd.Flags().BoolVarP(&checkpointAll, "all", "a", false, "checkpoint every active worktree")
+	checkpointCmd.Flags().StringVarP(&checkpointMessage, "message", "m", "", "commit message (default \"checkpoint: <timestamp>\")")
+	rootCmd.AddCommand(checkpointCmd)
+}
+
+type checkpointGitClient interface {
+	StatusPorcelain(dir string) ([]string, error)
+	CommitAll(dir, message string) error
+}
+
+// defaultCheckpointMessage returns the generated checkpoint commit message.
+func defaultCheckpointMessage(now time.Time) string {
+	return "checkpoint: " + now.Format("2006-01-02 15:04:05")
+}
+
+// checkpointWorktree commits all changes in worktreePath. It reports false
+// without committing when the worktree is already clean.
+func checkpointWorktree(gitClient checkpointGitClient, worktreePath, message string) (bool, error) {
+	changes, err := gitClient.StatusPorcelain(worktreePath)
+	if err != nil {
+		return false, err
+	}
+	if len(changes) == 0 {
+		return false, nil
+	}
+	if err := gitClient.CommitAll(worktreePath, message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func runCheckpoint(cmd *cobra.Command, args []string) error {
+	if checkpointAll && len(args) > 0 {
+		return fmt.Errorf("--all cannot be combined with a session name")
+	}
+
+	message := checkpointMessage
+	if message == "" {
+		message = defaultCheckpointMessage(time.Now())
+	}
+
+	tmuxClient := tmux.NewClient()
+	var paths []string
+	if checkpointAll {
+		result, err := discovery.NewService(tmuxClient).Discover()
+		if err != nil {
+			return err
+		}
+		for _, t := range syncTargetsFromDiscovery(result, nil) {
+			paths = append(paths, t.WorktreePath)
+		}
+	} else {
+		_, worktreePath, err := resolveWorkflowTarget(tmuxClient, args)
+		if err != nil {
+			return err
+		}
+		if worktreePath == "" {
+			return fmt.Errorf("could not determine worktree for session")
+		}
+		paths = append(paths, worktreePath)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(paths) == 0 {
+		_, _ = fmt.Fprintln(out, "No active worktrees to checkpoint.")
+		return nil
+	}
+
+	gitClient := git.NewClient()
+	failures := 0
+	for _, path := range paths {
+		committed, err := checkpointWorktree(gitClient, path, message)
+		switch {
+		case err != nil:
+			failures++
+			_, _ = fmt.Fprintf(out, "  %s: %v\n", path, err)
+		case committed:
+			_, _ = fmt.Fprintf(out, "  %s: committed %q\n", path, message)
+		default:
+			_, _ = fmt.Fprintf(out, "  %s: nothing to commit\n", path)
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("checkpoint failed for %d worktree(s)", failures)
+	}
+	return nil
+}
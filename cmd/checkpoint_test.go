@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+type fakeCheckpointGitClient struct {
+	status    []string
+	committed []string
+}
+
+func (f *fakeCheckpointGitClient) StatusPorcelain(dir string) ([]string, error) {
+	return f.status, nil
+}
+
+func (f *fakeCheckpointGitClient) CommitAll(dir, message string) error {
+	f.committed = append(f.committed, dir+": "+message)
+	return nil
+}
+
+func TestCheckpointWorktree_CommitsChanges(t *testing.T) {
+	client := &fakeCheckpointGitClient{status: []string{" M main.go"}}
+
+	committed, err := checkpointWorktree(client, "/wt", "checkpoint: x")
+	if err != nil {
+		t.Fatalf("checkpointWorktree() error = %v", err)
+	}
+	if !committed {
+		t.Fatal("checkpointWorktree() committed = false, want true")
+	}
+	if len(client.committed) != 1 || client.committed[0] != "/wt: checkpoint: x" {
+		t.Fatalf("commits = %v", client.committed)
+	}
+}
+
+func TestCheckpointWorktree_SkipsCleanTree(t *testing.T) {
+	client := &fakeCheckpointGitClient{}
+
+	committed, err := checkpointWorktree(client, "/wt", "checkpoint: x")
+	if err != nil {
+		t.Fatalf("checkpointWorktree() error = %v", err)
+	}
+	if committed || len(client.committed) != 0 {
+		t.Fatalf("clean tree should not be committed, got %v", client.committed)
+	}
+}
+
+func TestDefaultCheckpointMessage(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	if got := defaultCheckpointMessage(now); got != "checkpoint: 2026-03-04 05:06:07" {
+		t.Fatalf("defaultCheckpointMessage() = %q", got)
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var cleanDryRun bool
+var cleanYes bool
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Archive worktrees whose branches are merged and delete the branches",
+	Long: `Lists the linked worktrees of every configured project whose branch is fully
+merged into the base branch (the branch checked out in the main repo). After
+confirmation, each one is archived: its sessions are killed, the worktree is
+removed, and the branch is deleted.
+
+Every project is first pruned with git worktree prune, dropping the entries
+of worktrees deleted outside cb (shown as STALE by cb list and cb dash).
+
+Worktrees with uncommitted changes are skipped, and so are branches still at
+the base branch's tip (a workflow started but not yet committed to) and
+worktrees with a session whose agents are not all IDLE or DONE.
+
+Example:
+  cb clean                 # List merged worktrees, confirm, then clean them
+  cb clean --dry-run       # Only list them and the stale entries
+  cb clean -y              # Skip confirmation`,
+	Args: cobra.NoArgs,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().BoolVar(&cleanDryRun, "dry-run", false, "list merged worktrees and stale entries without removing anything")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "skip confirmation prompt")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+type cleanGitClient interface {
+	CurrentBranch(dir string) (string, error)
+	BranchTip(repoDir, branch string) (string, error)
+	MergedBranches(repoDir, base string) ([]string, error)
+	StatusPorcelain(dir string) ([]string, error)
+}
+
+type worktreePruner interface {
+	PruneWorktrees(repoDir string) error
+}
+
+// pruneStaleWorktrees runs git worktree prune in every valid project,
+// reporting the stale entries discovery found, and returns the number of
+// projects that failed. With dryRun it only reports them.
+func pruneStaleWorktrees(pruner worktreePruner, result discovery.Result, dryRun bool, out, errWriter io.Writer) int {
+	failures := 0
+	for _, project := range result.Projects {
+		if project.InvalidError != "" {
+			continue
+		}
+		stale := strings.Join(project.StaleWorktrees, ", ")
+		if dryRun {
+			if stale != "" {
+				_, _ = fmt.Fprintf(out, "%s: would prune %s\n", project.Name, stale)
+			}
+			continue
+		}
+		if err := pruner.PruneWorktrees(project.Path); err != nil {
+			_, _ = fmt.Fprintf(errWriter, "Error: %v\n", err)
+			failures++
+			continue
+		}
+		if stale != "" {
+			_, _ = fmt.Fprintf(out, "%s: pruned %s\n", project.Name, stale)
+		}
+	}
+	return failures
+}
+
+// cleanCandidate is a linked worktree whose branch is merged into its base.
+type cleanCandidate struct {
+	ProjectName  string
+	MainRepoPath string
+	BaseBranch   string
+	WorktreeName string
+	WorktreePath string
+	Branch       string
+	Sessions     []string
+}
+
+// findCleanCandidates returns the linked worktrees in result whose branch is
+// merged into their project's base branch. A branch still at the base tip has
+// no work of its own yet and is left alone. Worktrees and projects that cannot
+// be checked, have uncommitted changes, or have a session with busy agents are
+// described in skipped instead.
+func findCleanCandidates(gitClient cleanGitClient, result discovery.Result) (candidates []cleanCandidate, skipped []string) {
+	for _, project := range result.Projects {
+		if project.InvalidError != "" {
+			continue
+		}
+		var linked []discovery.WorktreeNode
+		for _, wt := range project.Worktrees {
+			if !wt.IsMainRepo && !wt.IsMissing && wt.Branch != "" {
+				linked = append(linked, wt)
+			}
+		}
+		if len(linked) == 0 {
+			continue
+		}
+
+		base, err := gitClient.CurrentBranch(project.Path)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", project.Name, err))
+			continue
+		}
+		merged, err := gitClient.MergedBranches(project.Path, base)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", project.Name, err))
+			continue
+		}
+		mergedSet := make(map[string]struct{}, len(merged))
+		for _, branch := range merged {
+			mergedSet[branch] = struct{}{}
+		}
+		baseTip, err := gitClient.BranchTip(project.Path, base)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("%s: %v", project.Name, err))
+			continue
+		}
+
+		for _, wt := range linked {
+			if _, ok := mergedSet[wt.Branch]; !ok || wt.Branch == base {
+				continue
+			}
+			tip, err := gitClient.BranchTip(project.Path, wt.Branch)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s/%s: %v", project.Name, wt.Name, err))
+				continue
+			}
+			if tip == baseTip {
+				continue
+			}
+			if busy := busySession(wt.Sessions); busy != nil {
+				skipped = append(skipped, fmt.Sprintf("%s/%s: session %s is %s", project.Name, wt.Name, busy.Name, busy.Status))
+				continue
+			}
+			dirty, err := gitClient.StatusPorcelain(wt.Path)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s/%s: %v", project.Name, wt.Name, err))
+				continue
+			}
+			if len(dirty) > 0 {
+				skipped = append(skipped, fmt.Sprintf("%s/%s: %d uncommitted change(s)", project.Name, wt.Name, len(dirty)))
+				continue
+			}
+			sessions := make([]string, 0, len(wt.Sessions))
+			for _, s := range wt.Sessions {
+				sessions = append(sessions, s.Name)
+			}
+			candidates = append(candidates, cleanCandidate{
+				ProjectName:  project.Name,
+				MainRepoPath: project.Path,
+				BaseBranch:   base,
+				WorktreeName: wt.Name,
+				WorktreePath: wt.Path,
+				Branch:       wt.Branch,
+				Sessions:     sessions,
+			})
+		}
+	}
+	return candidates, skipped
+}
+
+// busySession returns the first of sessions whose agents are not all IDLE or
+// DONE, or nil when every session can be killed without interrupting work.
+func busySession(sessions []discovery.SessionNode) *discovery.SessionNode {
+	for i, s := range sessions {
+		if s.Status != tmux.StatusIdle && s.Status != tmux.StatusDone {
+			return &sessions[i]
+		}
+	}
+	return nil
+}
+
+func writeCleanCandidates(w io.Writer, candidates []cleanCandidate) {
+	currentProject := ""
+	for _, c := range candidates {
+		if c.MainRepoPath != currentProject {
+			currentProject = c.MainRepoPath
+			_, _ = fmt.Fprintf(w, "%s (merged into %s)\n", c.ProjectName, c.BaseBranch)
+		}
+		line := fmt.Sprintf("  %-30s %s", discovery.WorktreeLabel(c.WorktreeName, c.Branch), c.WorktreePath)
+		if len(c.Sessions) > 0 {
+			line += "  [" + strings.Join(c.Sessions, ", ") + "]"
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	result, err := discovery.NewService(tmuxClient).Discover()
+	if err != nil {
+		return err
+	}
+
+	gitClient := git.NewClient()
+	out := cmd.OutOrStdout()
+	errOut := cmd.ErrOrStderr()
+	failures := pruneStaleWorktrees(gitClient, result, cleanDryRun, out, errOut)
+
+	candidates, skipped := findCleanCandidates(gitClient, result)
+	for _, s := range skipped {
+		_, _ = fmt.Fprintf(errOut, "Skipping %s\n", s)
+	}
+	if len(candidates) == 0 {
+		_, _ = fmt.Fprintln(out, "No merged worktrees to clean.")
+		return cleanFailures(failures)
+	}
+
+	writeCleanCandidates(out, candidates)
+	if cleanDryRun {
+		return cleanFailures(failures)
+	}
+	prompt := fmt.Sprintf("This will kill their sessions, remove %d worktree(s), and delete their branches. Continue? [y/N] ", len(candidates))
+	if !cleanYes && !confirm(os.Stdin, prompt) {
+		_, _ = fmt.Fprintln(out, "Cancelled")
+		return cleanFailures(failures)
+	}
+
+	for _, c := range candidates {
+		if err := cleanWorktree(tmuxClient, gitClient, c, errOut); err != nil {
+			_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+			failures++
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "Cleaned %s (branch %s deleted)\n", c.WorktreeName, c.Branch)
+	}
+	return cleanFailures(failures)
+}
+
+func cleanFailures(failures int) error {
+	if failures > 0 {
+		return fmt.Errorf("clean finished with %d project(s) or worktree(s) needing attention", failures)
+	}
+	return nil
+}
+
+// cleanWorktree kills c's sessions, removes its worktree, and deletes its
+// branch.
+func cleanWorktree(tmuxClient *tmux.Client, gitClient *git.Client, c cleanCandidate, errWriter io.Writer) error {
+	if err := removeWorkflow(tmuxClient, gitClient, c.MainRepoPath, c.WorktreePath, c.Sessions, errWriter); err != nil {
+		return err
+	}
+	return gitClient.DeleteBranch(c.MainRepoPath, c.Branch)
+}
+
+// removeWorkflow kills sessions and removes the worktree at worktreePath,
+// keeping its branch.
+func removeWorkflow(tmuxClient *tmux.Client, gitClient *git.Client, mainRepoPath, worktreePath string, sessions []string, errWriter io.Writer) error {
+	for _, name := range sessions {
+		killSession(tmuxClient, name, errWriter)
+		forgetSession(name, errWriter)
+	}
+
+	// Leave the worktree before removing it.
+	if cwd, err := os.Getwd(); err == nil && discovery.IsPathWithinOrEqual(cwd, worktreePath) {
+		if err := os.Chdir(mainRepoPath); err != nil {
+			return fmt.Errorf("failed to change to main repo: %w", err)
+		}
+	}
+
+	return gitClient.RemoveWorktree(mainRepoPath, worktreePath)
+}
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeCleanGitClient struct {
+	base      map[string]string
+	tips      map[string]string
+	merged    map[string][]string
+	statuses  map[string][]string
+	mergedErr error
+}
+
+func (f fakeCleanGitClient) CurrentBranch(dir string) (string, error) {
+	return f.base[dir], nil
+}
+
+func (f fakeCleanGitClient) BranchTip(repoDir, branch string) (string, error) {
+	if tip, ok := f.tips[branch]; ok {
+		return tip, nil
+	}
+	return "tip-" + branch, nil
+}
+
+func (f fakeCleanGitClient) MergedBranches(repoDir, base string) ([]string, error) {
+	return f.merged[repoDir], f.mergedErr
+}
+
+func (f fakeCleanGitClient) StatusPorcelain(dir string) ([]string, error) {
+	return f.statuses[dir], nil
+}
+
+func TestFindCleanCandidates(t *testing.T) {
+	result := discovery.Result{Projects: []discovery.ProjectNode{
+		{
+			Name: "repo",
+			Path: "/code/repo",
+			Worktrees: []discovery.WorktreeNode{
+				{Name: "repo", Path: "/code/repo", Branch: "main", IsMainRepo: true},
+				{Name: ".worktrees/repo-done", Path: "/code/repo/.worktrees/repo-done", Branch: "done",
+					Sessions: []discovery.SessionNode{{Name: "cb_done", Status: tmux.StatusDone}}},
+				{Name: ".worktrees/repo-open", Path: "/code/repo/.worktrees/repo-open", Branch: "open"},
+				{Name: ".worktrees/repo-fresh", Path: "/code/repo/.worktrees/repo-fresh", Branch: "fresh"},
+				{Name: ".worktrees/repo-busy", Path: "/code/repo/.worktrees/repo-busy", Branch: "busy",
+					Sessions: []discovery.SessionNode{{Name: "cb_busy", Status: tmux.StatusWorking}}},
+				{Name: ".worktrees/repo-dirty", Path: "/code/repo/.worktrees/repo-dirty", Branch: "dirty"},
+				{Name: ".worktrees/repo-detached", Path: "/code/repo/.worktrees/repo-detached"},
+				{Name: "(missing worktree)", IsMissing: true},
+			},
+		},
+		{Name: "broken", Path: "/code/broken", InvalidError: "not a git repository"},
+	}}
+
+	t.Run("lists clean merged worktrees and skips dirty, fresh, and busy ones", func(t *testing.T) {
+		client := fakeCleanGitClient{
+			base:     map[string]string{"/code/repo": "main"},
+			tips:     map[string]string{"main": "abc", "fresh": "abc"},
+			merged:   map[string][]string{"/code/repo": {"done", "fresh", "busy", "dirty", "main"}},
+			statuses: map[string][]string{"/code/repo/.worktrees/repo-dirty": {" M a.go"}},
+		}
+		candidates, skipped := findCleanCandidates(client, result)
+		want := []cleanCandidate{{
+			ProjectName:  "repo",
+			MainRepoPath: "/code/repo",
+			BaseBranch:   "main",
+			WorktreeName: ".worktrees/repo-done",
+			WorktreePath: "/code/repo/.worktrees/repo-done",
+			Branch:       "done",
+			Sessions:     []string{"cb_done"},
+		}}
+		if !reflect.DeepEqual(candidates, want) {
+			t.Fatalf("candidates = %+v, want %+v", candidates, want)
+		}
+		if len(skipped) != 2 || !strings.Contains(skipped[0], "repo-busy: session cb_busy is WORKING") ||
+			!strings.Contains(skipped[1], "repo-dirty: 1 uncommitted change(s)") {
+			t.Fatalf("skipped = %v, want the busy and dirty worktrees", skipped)
+		}
+	})
+
+	t.Run("reports projects whose branches cannot be listed", func(t *testing.T) {
+		client := fakeCleanGitClient{
+			base:      map[string]string{"/code/repo": "main"},
+			mergedErr: errors.New("boom"),
+		}
+		candidates, skipped := findCleanCandidates(client, result)
+		if len(candidates) != 0 {
+			t.Fatalf("candidates = %+v, want none", candidates)
+		}
+		if len(skipped) != 1 || skipped[0] != "repo: boom" {
+			t.Fatalf("skipped = %v, want [repo: boom]", skipped)
+		}
+	})
+}
+
+func TestWriteCleanCandidates(t *testing.T) {
+	var buf bytes.Buffer
+	writeCleanCandidates(&buf, []cleanCandidate{
+		{ProjectName: "repo", MainRepoPath: "/code/repo", BaseBranch: "main", WorktreeName: "repo-done", WorktreePath: "/wt/done", Branch: "done", Sessions: []string{"cb_done"}},
+		{ProjectName: "repo", MainRepoPath: "/code/repo", BaseBranch: "main", WorktreeName: "repo-old", WorktreePath: "/wt/old", Branch: "feat/old"},
+	})
+	want := "repo (merged into main)\n" +
+		"  repo-done                      /wt/done  [cb_done]\n" +
+		"  repo-old (feat/old)            /wt/old\n"
+	if buf.String() != want {
+		t.Fatalf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+type fakeWorktreePruner struct {
+	pruned []string
+	err    error
+}
+
+func (f *fakeWorktreePruner) PruneWorktrees(repoDir string) error {
+	f.pruned = append(f.pruned, repoDir)
+	return f.err
+}
+
+func TestPruneStaleWorktrees(t *testing.T) {
+	result := discovery.Result{Projects: []discovery.ProjectNode{
+		{Name: "repo", Path: "/code/repo", StaleWorktrees: []string{".worktrees/repo-gone"}},
+		{Name: "tidy", Path: "/code/tidy"},
+		{Name: "broken", Path: "/code/broken", InvalidError: "not a git repository"},
+	}}
+
+	t.Run("prunes every valid project and reports stale entries", func(t *testing.T) {
+		pruner := &fakeWorktreePruner{}
+		var out bytes.Buffer
+		if failures := pruneStaleWorktrees(pruner, result, false, &out, io.Discard); failures != 0 {
+			t.Fatalf("failures = %d, want 0", failures)
+		}
+		if want := []string{"/code/repo", "/code/tidy"}; !reflect.DeepEqual(pruner.pruned, want) {
+			t.Fatalf("pruned = %v, want %v", pruner.pruned, want)
+		}
+		if out.String() != "repo: pruned .worktrees/repo-gone\n" {
+			t.Fatalf("output = %q", out.String())
+		}
+	})
+
+	t.Run("dry run only reports", func(t *testing.T) {
+		pruner := &fakeWorktreePruner{}
+		var out bytes.Buffer
+		pruneStaleWorktrees(pruner, result, true, &out, io.Discard)
+		if len(pruner.pruned) != 0 {
+			t.Fatalf("pruned = %v, want none", pruner.pruned)
+		}
+		if out.String() != "repo: would prune .worktrees/repo-gone\n" {
+			t.Fatalf("output = %q", out.String())
+		}
+	})
+
+	t.Run("counts failed projects", func(t *testing.T) {
+		pruner := &fakeWorktreePruner{err: errors.New("boom")}
+		var errOut bytes.Buffer
+		if failures := pruneStaleWorktrees(pruner, result, false, io.Discard, &errOut); failures != 2 {
+			t.Fatalf("failures = %d, want 2", failures)
+		}
+		if !strings.Contains(errOut.String(), "boom") {
+			t.Fatalf("errors = %q, want boom", errOut.String())
+		}
+	})
+}
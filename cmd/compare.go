@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+// compareHistoryLines is how much scrollback is searched for the final
+// agent message.
+const compareHistoryLines = 200
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <session-name>...",
+	Short: "Compare the results of several workflows",
+	Long: `Reports, for each session's worktree, the diff stat and changed files against
+the main repo's checked-out branch, the session's agent status, and the agent's
+final message, so parallel attempts at the same task (for example from
+cb fanout) can be compared side by side.
+
+Example:
+  cb compare try1/fix-login try2/fix-login
+  cb compare cb_fix-login-a cb_fix-login-b cb_fix-login-c`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runCompare,
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+}
+
+type compareTmuxClient interface {
+	sessionResolver
+	listAgentDetector
+	ListWindows(session string) ([]tmux.Window, error)
+	CapturePane(target string, history int) (string, error)
+}
+
+type compareGitClient interface {
+	MainWorktree(dir string) (string, error)
+	CurrentBranch(dir string) (string, error)
+	NumStatAgainst(dir, base string) (git.DiffStat, []string, error)
+}
+
+// compareEntry is one session's row in a comparison report. Err is set when
+// the session could not be inspected.
+type compareEntry struct {
+	Session      string
+	WorktreePath string
+	Base         string
+	Stat         git.DiffStat
+	Files        []string
+	Status       tmux.Status
+	Message      string
+	Err          error
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	gitClient := git.NewClient()
+
+	entries := make([]compareEntry, 0, len(args))
+	for _, arg := range args {
+		entries = append(entries, compareSession(tmuxClient, gitClient, resolveSessionName(tmuxClient, arg)))
+	}
+	writeCompareReport(cmd.OutOrStdout(), entries)
+	return nil
+}
+
+// compareSession inspects one session's worktree and agent.
+func compareSession(tmuxClient compareTmuxClient, gitClient compareGitClient, session string) compareEntry {
+	entry := compareEntry{Session: session}
+	wins, err := tmuxClient.ListWindows(session)
+	if err != nil {
+		entry.Err = err
+		return entry
+	}
+	entry.Status = sessionStatusFromWindows(tmuxClient, session, wins)
+
+	entry.WorktreePath = sessionHomePath(tmuxClient, session)
+	if entry.WorktreePath == "" {
+		entry.Err = fmt.Errorf("session %s has no worktree path", session)
+		return entry
+	}
+	mainRepo, err := gitClient.MainWorktree(entry.WorktreePath)
+	if err != nil {
+		entry.Err = err
+		return entry
+	}
+	if entry.Base, err = gitClient.CurrentBranch(mainRepo); err != nil {
+		entry.Err = err
+		return entry
+	}
+	if entry.Stat, entry.Files, err = gitClient.NumStatAgainst(entry.WorktreePath, entry.Base); err != nil {
+		entry.Err = err
+		return entry
+	}
+
+	if target, ok := agentWindowTarget(tmuxClient, session, wins); ok {
+		if content, err := tmuxClient.CapturePane(target, compareHistoryLines); err == nil {
+			entry.Message = tmux.LastAgentMessage(content)
+		}
+	}
+	return entry
+}
+
+// agentWindowTarget returns the first window running a detected agent, or
+// else the first window named after an agent command (its agent may have
+// exited, leaving the final message on screen).
+func agentWindowTarget(detector listAgentDetector, session string, wins []tmux.Window) (string, bool) {
+	for _, w := range wins {
+		if detector.DetectAgentInfo(w.Target(session)).Detected {
+			return w.Target(session), true
+		}
+	}
+	for _, w := range wins {
+		for _, agent := range tmux.LaunchableAgents {
+			if w.Name == agent.LaunchCommand() {
+				return w.Target(session), true
+			}
+		}
+	}
+	return "", false
+}
+
+func writeCompareReport(w io.Writer, entries []compareEntry) {
+	for i, e := range entries {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		if e.Err != nil {
+			_, _ = fmt.Fprintf(w, "%s\n  error: %v\n", e.Session, e.Err)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%s (%s)\n", e.Session, e.Status)
+		_, _ = fmt.Fprintf(w, "  worktree: %s\n", e.WorktreePath)
+		_, _ = fmt.Fprintf(w, "  changes:  +%d −%d (%d files) vs %s\n", e.Stat.Insertions, e.Stat.Deletions, e.Stat.Files, e.Base)
+		for _, f := range e.Files {
+			_, _ = fmt.Fprintf(w, "      %s\n", f)
+		}
+		if e.Message == "" {
+			_, _ = fmt.Fprintln(w, "  final message: (none found)")
+			continue
+		}
+		_, _ = fmt.Fprintln(w, "  final message:")
+		for line := range strings.SplitSeq(e.Message, "\n") {
+			_, _ = fmt.Fprintln(w, strings.TrimRight("    "+line, " "))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeCompareTmuxClient struct {
+	windows map[string][]tmux.Window
+	agents  map[string]tmux.Status
+	panes   map[string]string
+}
+
+func (f *fakeCompareTmuxClient) ListSessions() ([]tmux.Session, error) { return nil, nil }
+
+func (f *fakeCompareTmuxClient) GetPaneWorkingDir(session string) string { return "" }
+
+func (f *fakeCompareTmuxClient) GetSessionOption(session, key string) (string, error) {
+	return "/repo/.worktrees/" + session, nil
+}
+
+func (f *fakeCompareTmuxClient) ListWindows(session string) ([]tmux.Window, error) {
+	wins, ok := f.windows[session]
+	if !ok {
+		return nil, tmux.ErrNoSession
+	}
+	return wins, nil
+}
+
+func (f *fakeCompareTmuxClient) DetectAgentInfo(target string) tmux.AgentInfo {
+	status, ok := f.agents[target]
+	if !ok {
+		return tmux.AgentInfo{Type: tmux.AgentNone, Status: tmux.StatusDone}
+	}
+	return tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: status}
+}
+
+func (f *fakeCompareTmuxClient) CapturePane(target string, history int) (string, error) {
+	return f.panes[target], nil
+}
+
+type fakeCompareGitClient struct{}
+
+func (fakeCompareGitClient) MainWorktree(dir string) (string, error) { return "/repo", nil }
+
+func (fakeCompareGitClient) CurrentBranch(dir string) (string, error) { return "main", nil }
+
+func (fakeCompareGitClient) NumStatAgainst(dir, base string) (git.DiffStat, []string, error) {
+	if dir == "/repo/.worktrees/cb_b" {
+		return git.DiffStat{}, nil, errors.New("bad revision")
+	}
+	return git.DiffStat{Files: 1, Insertions: 5, Deletions: 2}, []string{"login.go"}, nil
+}
+
+func TestCompareSessionsReport(t *testing.T) {
+	tmuxClient := &fakeCompareTmuxClient{
+		windows: map[string][]tmux.Window{
+			"cb_a": {{ID: "@1", Name: "shell"}, {ID: "@2", Name: "claude"}},
+			"cb_b": {{ID: "@3", Name: "claude"}},
+		},
+		agents: map[string]tmux.Status{"@2": tmux.StatusWaiting},
+		panes:  map[string]string{"@2": "⏺ Fixed the redirect.\n\n  Tests pass.\n╭──╮\n"},
+	}
+
+	var entries []compareEntry
+	for _, session := range []string{"cb_a", "cb_b", "cb_gone"} {
+		entries = append(entries, compareSession(tmuxClient, fakeCompareGitClient{}, session))
+	}
+	var buf bytes.Buffer
+	writeCompareReport(&buf, entries)
+
+	want := `cb_a (WAITING)
+  worktree: /repo/.worktrees/cb_a
+  changes:  +5 −2 (1 files) vs main
+      login.go
+  final message:
+    Fixed the redirect.
+
+    Tests pass.
+
+cb_b
+  error: bad revision
+
+cb_gone
+  error: tmux session not found
+`
+	if buf.String() != want {
+		t.Fatalf("report =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestAgentWindowTarget_FallsBackToAgentName(t *testing.T) {
+	detector := &fakeCompareTmuxClient{}
+	wins := []tmux.Window{{ID: "@1", Name: "shell"}, {ID: "@2", Name: "codex"}}
+
+	target, ok := agentWindowTarget(detector, "cb_a", wins)
+	if !ok || target != "@2" {
+		t.Fatalf("agentWindowTarget() = %q, %v; want @2", target, ok)
+	}
+	if _, ok := agentWindowTarget(detector, "cb_a", wins[:1]); ok {
+		t.Fatal("agentWindowTarget() should find nothing without an agent window")
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: "Report active worktrees that modify the same files",
+	Long: `Compares the files changed in each active worktree (one with a tmux session)
+against the base branch and reports pairs of worktrees touching the same paths,
+so parallel agents can be steered apart before their branches collide.
+
+Example:
+  cb conflicts`,
+	Args: cobra.NoArgs,
+	RunE: runConflicts,
+}
+
+func init() {
+	rootCmd.AddCommand(conflictsCmd)
+}
+
+// overlapPair is one unordered pair of worktrees sharing changed files.
+type overlapPair struct {
+	Left  string
+	Right string
+	Files []string
+}
+
+// overlapPairs flattens per-worktree overlaps into unique pairs, in worktree order.
+func overlapPairs(project discovery.ProjectNode) []overlapPair {
+	seen := make(map[[2]string]struct{})
+	var pairs []overlapPair
+	for _, wt := range project.Worktrees {
+		for _, o := range wt.Overlaps {
+			key := [2]string{wt.Name, o.Worktree}
+			if o.Worktree < wt.Name {
+				key = [2]string{o.Worktree, wt.Name}
+			}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			pairs = append(pairs, overlapPair{Left: wt.Name, Right: o.Worktree, Files: o.Files})
+		}
+	}
+	return pairs
+}
+
+func runConflicts(cmd *cobra.Command, args []string) error {
+	result, err := discovery.NewService(tmux.NewClient()).Discover()
+	if err != nil {
+		return err
+	}
+	if result.ConfigMissing {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No project config found. Add one with: cb project add <path>")
+		return nil
+	}
+
+	writeConflictReport(cmd.OutOrStdout(), result.Projects)
+	return nil
+}
+
+func writeConflictReport(out io.Writer, projects []discovery.ProjectNode) {
+	found := false
+	for _, project := range projects {
+		pairs := overlapPairs(project)
+		if len(pairs) == 0 {
+			continue
+		}
+		found = true
+		_, _ = fmt.Fprintln(out, project.Name)
+		for _, p := range pairs {
+			_, _ = fmt.Fprintf(out, "  %s <-> %s (%d shared)\n", p.Left, p.Right, len(p.Files))
+			for _, f := range p.Files {
+				_, _ = fmt.Fprintf(out, "      %s\n", f)
+			}
+		}
+	}
+	if !found {
+		_, _ = fmt.Fprintln(out, "No overlapping changes between active worktrees.")
+	}
+}
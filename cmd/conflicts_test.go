@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+)
+
+func TestOverlapPairs_DeduplicatesSymmetricOverlaps(t *testing.T) {
+	project := discovery.ProjectNode{
+		Name: "repo",
+		Worktrees: []discovery.WorktreeNode{
+			{Name: "(main repo)", IsMainRepo: true},
+			{Name: ".worktrees/repo-a", Overlaps: []discovery.FileOverlap{
+				{Worktree: ".worktrees/repo-b", Files: []string{"a.go"}},
+			}},
+			{Name: ".worktrees/repo-b", Overlaps: []discovery.FileOverlap{
+				{Worktree: ".worktrees/repo-a", Files: []string{"a.go"}},
+			}},
+		},
+	}
+
+	got := overlapPairs(project)
+	want := []overlapPair{{Left: ".worktrees/repo-a", Right: ".worktrees/repo-b", Files: []string{"a.go"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("overlapPairs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteConflictReport(t *testing.T) {
+	projects := []discovery.ProjectNode{{
+		Name: "repo",
+		Worktrees: []discovery.WorktreeNode{
+			{Name: ".worktrees/repo-a", Overlaps: []discovery.FileOverlap{
+				{Worktree: ".worktrees/repo-b", Files: []string{"a.go", "b.go"}},
+			}},
+		},
+	}}
+
+	var out bytes.Buffer
+	writeConflictReport(&out, projects)
+	want := "repo\n  .worktrees/repo-a <-> .worktrees/repo-b (2 shared)\n      a.go\n      b.go\n"
+	if out.String() != want {
+		t.Fatalf("writeConflictReport() = %q, want %q", out.String(), want)
+	}
+
+	out.Reset()
+	writeConflictReport(&out, []discovery.ProjectNode{{Name: "quiet"}})
+	if out.String() != "No overlapping changes between active worktrees.\n" {
+		t.Fatalf("writeConflictReport() empty = %q", out.String())
+	}
+}
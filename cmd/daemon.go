@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/daemon"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/events"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var daemonInterval time.Duration
+
+// daemonDefaultInterval is the default of cb daemon --interval.
+const daemonDefaultInterval = 2 * time.Second
+
+// daemonFetchTimeout bounds how long a client waits on the daemon before
+// falling back to its own discovery.
+const daemonFetchTimeout = 500 * time.Millisecond
+
+// daemonStaleRefreshes is how many refresh intervals a daemon snapshot may
+// lag behind before clients stop trusting it, as when the daemon's refresh
+// hangs, and discover directly.
+const daemonStaleRefreshes = 3
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Serve discovery state to other cb commands",
+	Long: `Runs in the foreground, rediscovering projects, worktrees, sessions, and agent
+statuses on an interval and keeping a per-window status history in memory.
+The result is served over a unix socket at ~/.local/state/cb/daemon.sock; while
+the daemon runs, cb dash and cb list read from it instead of querying tmux and
+git themselves. Sessions are recorded for cb restore on every refresh.
+
+Example:
+  cb daemon
+  cb daemon --interval 5s`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+func init() {
+	daemonCmd.Flags().DurationVar(&daemonInterval, "interval", daemonDefaultInterval, "how often to rediscover")
+	rootCmd.AddCommand(daemonCmd)
+}
+
+// daemonSocketPath returns the socket the daemon listens on.
+func daemonSocketPath() (string, error) {
+	c, err := config.New()
+	if err != nil {
+		return "", err
+	}
+	return c.DaemonSocketPath(), nil
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	if daemonInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	c, err := config.New()
+	if err != nil {
+		return err
+	}
+	if err := c.EnsureDirs(); err != nil {
+		return err
+	}
+	path := c.DaemonSocketPath()
+	ln, err := daemon.Listen(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(path) }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tmuxClient := tmux.NewClientWithContext(ctx)
+	server := daemon.NewServer(newRecordingDiscoverer(ctx, tmuxClient), tmuxClient, daemonInterval)
+	server.Events().Subscribe(logEvent)
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "cb daemon listening on %s (Ctrl-C to stop)\n", path)
+	return server.Serve(ctx, ln)
+}
+
+// logEvent records a daemon event in the debug log.
+func logEvent(ev events.Event) {
+	slog.Debug("daemon event", "kind", ev.Kind, "project", ev.Project, "worktree", ev.Worktree,
+		"session", ev.Session, "target", ev.Target, "status", ev.Status, "previous", ev.Previous)
+}
+
+// daemonDiscoverer serves discovery from a running cb daemon, falling back
+// to its own discovery when no daemon answers, the daemon's last refresh
+// failed, or its snapshot is stale.
+type daemonDiscoverer struct {
+	socketPath string
+	fetch      func(path string, timeout time.Duration) (daemon.Snapshot, error)
+	now        func() time.Time
+	fallback   interface {
+		Discover() (discovery.Result, error)
+	}
+}
+
+// newDaemonDiscoverer prefers the daemon, falling back to a recording
+// discovery service bound to ctx.
+func newDaemonDiscoverer(ctx context.Context, tmuxClient *tmux.Client) *daemonDiscoverer {
+	path, err := daemonSocketPath()
+	if err != nil {
+		slog.Debug("daemon socket unavailable", "err", err)
+	}
+	return &daemonDiscoverer{
+		socketPath: path,
+		fetch:      daemon.Fetch,
+		now:        time.Now,
+		fallback:   newRecordingDiscoverer(ctx, tmuxClient),
+	}
+}
+
+func (d *daemonDiscoverer) Discover() (discovery.Result, error) {
+	return d.discover(d.fallback.Discover)
+}
+
+// DiscoverContext is Discover with the fallback discovery bounded by ctx.
+func (d *daemonDiscoverer) DiscoverContext(ctx context.Context) (discovery.Result, error) {
+	return d.discover(func() (discovery.Result, error) {
+		return discovery.DiscoverWithContext(ctx, d.fallback)
+	})
+}
+
+func (d *daemonDiscoverer) discover(fallback func() (discovery.Result, error)) (discovery.Result, error) {
+	if d.socketPath != "" {
+		snap, err := d.fetch(d.socketPath, daemonFetchTimeout)
+		stale := err == nil && snapshotStale(snap, d.now())
+		if err == nil && snap.Err == "" && !stale {
+			return snap.Result, nil
+		}
+		slog.Debug("daemon snapshot unavailable, discovering directly", "err", err, "daemon_err", snap.Err, "stale", stale)
+	}
+	return fallback()
+}
+
+// snapshotStale reports whether snap was last refreshed more than
+// daemonStaleRefreshes of the daemon's intervals before now.
+func snapshotStale(snap daemon.Snapshot, now time.Time) bool {
+	interval := snap.Interval
+	if interval <= 0 {
+		interval = daemonDefaultInterval
+	}
+	return now.Sub(snap.UpdatedAt) > daemonStaleRefreshes*interval
+}
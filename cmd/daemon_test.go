@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/daemon"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+)
+
+type countingDiscoverer struct {
+	calls int
+}
+
+func (d *countingDiscoverer) Discover() (discovery.Result, error) {
+	d.calls++
+	return discovery.Result{ConfigMissing: true}, nil
+}
+
+func TestDaemonDiscoverer(t *testing.T) {
+	fromDaemon := discovery.Result{Projects: []discovery.ProjectNode{{Name: "repo"}}}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	fresh := now.Add(-time.Second)
+	tests := []struct {
+		name         string
+		snap         daemon.Snapshot
+		fetchErr     error
+		wantFallback bool
+	}{
+		{name: "daemon answers", snap: daemon.Snapshot{Result: fromDaemon, UpdatedAt: fresh, Interval: 2 * time.Second}},
+		{name: "no daemon", fetchErr: errors.New("connection refused"), wantFallback: true},
+		{name: "daemon refresh failed", snap: daemon.Snapshot{Result: fromDaemon, Err: "tmux hung", UpdatedAt: fresh}, wantFallback: true},
+		{
+			name:         "daemon refresh stuck",
+			snap:         daemon.Snapshot{Result: fromDaemon, UpdatedAt: now.Add(-time.Minute), Interval: 2 * time.Second},
+			wantFallback: true,
+		},
+		{
+			name: "slow interval is not stale",
+			snap: daemon.Snapshot{Result: fromDaemon, UpdatedAt: now.Add(-time.Minute), Interval: time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallback := &countingDiscoverer{}
+			d := &daemonDiscoverer{
+				socketPath: "/tmp/cb.sock",
+				fetch: func(path string, timeout time.Duration) (daemon.Snapshot, error) {
+					return tt.snap, tt.fetchErr
+				},
+				now:      func() time.Time { return now },
+				fallback: fallback,
+			}
+
+			result, err := d.Discover()
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			if got := fallback.calls == 1; got != tt.wantFallback {
+				t.Fatalf("fallback used = %v, want %v", got, tt.wantFallback)
+			}
+			if !tt.wantFallback && (len(result.Projects) != 1 || result.Projects[0].Name != "repo") {
+				t.Fatalf("result = %+v, want the daemon snapshot", result)
+			}
+		})
+	}
+}
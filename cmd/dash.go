@@ -1,19 +1,41 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 	"github.com/ronsanzone/clawd-bay/internal/tui"
+	"github.com/ronsanzone/clawd-bay/internal/zellij"
 	"github.com/spf13/cobra"
 )
 
 var dashMode string
+var dashFilter string
+var dashRepo string
+var dashWorkspace string
+var dashReadOnly bool
+var dashPopup bool
+
+// dashErrWriter receives warnings printed after the dashboard exits.
+var dashErrWriter io.Writer = os.Stderr
 
 type dashTmuxClient interface {
-	SelectWindow(session string, windowIndex int) error
+	HasSession(name string) bool
+	ListAllSessions() ([]tmux.Session, error)
+	ListWindows(session string) ([]tmux.Window, error)
+	SelectWindow(target string) error
 	AttachOrSwitchToSession(name string, inTmux bool) error
 }
 
@@ -22,11 +44,21 @@ func attachDashboardSelection(tmuxClient dashTmuxClient, model tui.Model, inTmux
 		return nil
 	}
 
-	if model.SelectedWindowIndex >= 0 {
-		if err := tmuxClient.SelectWindow(model.SelectedName, model.SelectedWindowIndex); err != nil {
+	// The session may have vanished between the last refresh and enter.
+	if !tmuxClient.HasSession(model.SelectedName) {
+		return sessionGoneError(tmuxClient, model.SelectedName)
+	}
+
+	if model.SelectedWindowTarget != "" {
+		if target, ok := resolveSelectedWindow(tmuxClient, model); !ok {
+			_, _ = fmt.Fprintf(dashErrWriter, "Window %s of session %s is gone; switching to the session instead.\n", model.SelectedWindow, model.SelectedName)
+		} else if err := tmuxClient.SelectWindow(target); err != nil {
+			if errors.Is(err, tmux.ErrWindowNotFound) {
+				return fmt.Errorf("window %s of session %s is gone — refresh the dashboard and try again", model.SelectedWindow, model.SelectedName)
+			}
 			return fmt.Errorf(
-				"failed to select window index %d for session %s: %w",
-				model.SelectedWindowIndex,
+				"failed to select window %s for session %s: %w",
+				model.SelectedWindow,
 				model.SelectedName,
 				err,
 			)
@@ -39,20 +71,232 @@ func attachDashboardSelection(tmuxClient dashTmuxClient, model tui.Model, inTmux
 	return nil
 }
 
+// resolveSelectedWindow re-resolves the selected window against the session's
+// current windows, since windows may have been moved, renumbered, or closed
+// since the refresh that captured model.SelectedWindowTarget. A window ID that
+// still exists wins; an index target is kept only while the window there has
+// the selected name; otherwise the only window with that name is used. ok is
+// false when none matches. When the windows cannot be listed, the captured
+// target is returned unchanged.
+func resolveSelectedWindow(tmuxClient dashTmuxClient, model tui.Model) (target string, ok bool) {
+	wins, err := tmuxClient.ListWindows(model.SelectedName)
+	if err != nil {
+		return model.SelectedWindowTarget, true
+	}
+
+	var byName []tmux.Window
+	for _, w := range wins {
+		if w.Target(model.SelectedName) == model.SelectedWindowTarget && (w.ID != "" || w.Name == model.SelectedWindow) {
+			return model.SelectedWindowTarget, true
+		}
+		if w.Name == model.SelectedWindow {
+			byName = append(byName, w)
+		}
+	}
+	if len(byName) == 1 {
+		return byName[0].Target(model.SelectedName), true
+	}
+	return "", false
+}
+
+// sessionGoneError explains that name no longer exists, suggesting running
+// sessions with similar names.
+func sessionGoneError(tmuxClient interface {
+	ListAllSessions() ([]tmux.Session, error)
+}, name string) error {
+	msg := fmt.Sprintf("session %s is gone — refresh the dashboard and try again", name)
+	sessions, err := tmuxClient.ListAllSessions()
+	if err != nil {
+		return errors.New(msg)
+	}
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	if suggestions := suggestSessionNames(name, names); len(suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+	}
+	return errors.New(msg)
+}
+
+// maxSessionSuggestions bounds how many near matches are suggested.
+const maxSessionSuggestions = 3
+
+// suggestSessionNames returns up to maxSessionSuggestions candidates close to
+// target: names containing (or contained in) it, or within a small edit
+// distance, closest first.
+func suggestSessionNames(target string, candidates []string) []string {
+	type scored struct {
+		name     string
+		distance int
+	}
+	threshold := max(2, len(target)/3)
+	var matches []scored
+	for _, c := range candidates {
+		if c == target {
+			continue
+		}
+		d := editDistance(target, c)
+		if d <= threshold || strings.Contains(c, target) || strings.Contains(target, c) {
+			matches = append(matches, scored{name: c, distance: d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var names []string
+	for i := 0; i < len(matches) && i < maxSessionSuggestions; i++ {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+// resolveDashRepoScope finds the configured project named by value, matching
+// its display name first and then its canonical path.
+func resolveDashRepoScope(cfg config.UserConfig, value string) (tui.RepoScope, error) {
+	var matches []config.ProjectConfig
+	for _, p := range cfg.Projects {
+		if projectDisplayName(p) == value {
+			matches = append(matches, p)
+		}
+	}
+	if len(matches) == 0 {
+		if canonical, err := config.CanonicalPath(value); err == nil {
+			for _, p := range cfg.Projects {
+				if p.Path == canonical {
+					matches = append(matches, p)
+				}
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return tui.RepoScope{}, fmt.Errorf("no configured project matched %q (see cb project list)", value)
+	case 1:
+		p := matches[0]
+		return tui.RepoScope{Label: projectDisplayName(p), Path: p.Path}, nil
+	default:
+		return tui.RepoScope{}, fmt.Errorf("project name %q is ambiguous; use its path", value)
+	}
+}
+
+// unknownWorkspaceError explains that no project is in workspace, listing
+// the configured workspaces.
+func unknownWorkspaceError(cfg config.UserConfig, workspace string) error {
+	workspaces := cfg.Workspaces()
+	if len(workspaces) == 0 {
+		return fmt.Errorf("no workspace %q: no project sets workspace in config.toml (see cb project add --workspace)", workspace)
+	}
+	return fmt.Errorf("no workspace %q (configured: %s)", workspace, strings.Join(workspaces, ", "))
+}
+
+func projectDisplayName(p config.ProjectConfig) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return filepath.Base(p.Path)
+}
+
 var dashCmd = &cobra.Command{
 	Use:   "dash",
 	Short: "Open interactive dashboard",
+	Long: `Opens the interactive dashboard.
+
+--repo scopes the dashboard to one configured project (by name or path) and
+--filter starts with a filter query already applied, which is handy for tmux
+bindings. --workspace shows only the projects grouped under a workspace with
+the workspace key of their [[projects]] entry; press w in the dashboard to
+switch between workspaces. --read-only disables every mutating keybinding,
+for sharing a monitoring view.
+
+--popup renders a compact, frameless dashboard that fills its terminal, sized
+for tmux display-popup. Bind it in ~/.tmux.conf with:
+
+  bind-key C display-popup -E -w 80% -h 60% "cb dash --popup"
+
+With multiplexer = "zellij" in the config file, the dashboard manages zellij
+sessions and tabs instead of tmux.
+
+Example:
+  cb dash --repo repo-a
+  cb dash --workspace work
+  cb dash --mode agents --filter waiting
+  tmux display-popup -E -w 80% -h 60% "cb dash --popup"`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		mode, err := tui.ParseDashboardMode(dashMode)
 		if err != nil {
 			return err
 		}
 
-		tmuxClient := tmux.NewClient()
-		model := tui.InitialModelWithMode(tmuxClient, mode)
+		theme, ascii, err := dashboardDisplay()
+		if err != nil {
+			return err
+		}
+
+		cfg, err := config.LoadUserConfig()
+		if err != nil {
+			return err
+		}
+		var scope tui.RepoScope
+		if dashRepo != "" {
+			if scope, err = resolveDashRepoScope(cfg, dashRepo); err != nil {
+				return err
+			}
+		}
+		if dashWorkspace != "" && !slices.Contains(cfg.Workspaces(), dashWorkspace) {
+			return unknownWorkspaceError(cfg, dashWorkspace)
+		}
+
+		// Refreshes run against a cancellable client so quitting kills any
+		// in-flight tmux/git/ps commands instead of leaving them behind.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		model, attachClient, inMultiplexer, err := dashboardBackend(ctx, cfg.Multiplexer, mode)
+		if err != nil {
+			return err
+		}
+		model.RepoScope = scope
+		model.Workspace = dashWorkspace
+		model = model.WithAgentWindowName(cfg.AgentWindowName)
+		model.ReadOnly = dashReadOnly
+		model.Compact = dashPopup
+		model.Styles = tui.NewStyles(theme)
+		model.Plain = colorDisabled()
+		model.ASCII = ascii
+		if dashFilter != "" {
+			model = model.WithFilter(dashFilter)
+		}
 
 		p := tea.NewProgram(model, tea.WithAltScreen())
 		finalModel, err := p.Run()
+		cancel()
 		if err != nil {
 			return err
 		}
@@ -60,14 +304,56 @@ var dashCmd = &cobra.Command{
 		// Handle selection (attach to session after TUI exits)
 		if m, ok := finalModel.(tui.Model); ok && m.SelectedName != "" {
 			fmt.Printf("Attaching to %s...\n", m.SelectedName)
-			return attachDashboardSelection(tmuxClient, m, os.Getenv("TMUX") != "")
+			return attachDashboardSelection(attachClient, m, inMultiplexer)
 		}
 
 		return nil
 	},
 }
 
+// dashboardBackend builds the dashboard model for the configured multiplexer
+// backend, with the client that attaches to the selection afterwards and
+// whether cb already runs inside that multiplexer.
+func dashboardBackend(ctx context.Context, backend string, mode tui.DashboardMode) (tui.Model, dashTmuxClient, bool, error) {
+	if backend == config.MultiplexerZellij {
+		c, err := config.New()
+		if err != nil {
+			return tui.Model{}, nil, false, err
+		}
+		client := zellij.NewClient(c.ZellijOptionsPath())
+		model := tui.InitialModelWithMode(client, mode)
+		model.Discoverer = discovery.NewServiceWithContext(ctx, client)
+		return model, client, os.Getenv("ZELLIJ") != "", nil
+	}
+
+	refreshClient := tmux.NewClientWithContext(ctx)
+	model := tui.InitialModelWithMode(refreshClient, mode)
+	model.Discoverer = newDaemonDiscoverer(ctx, refreshClient)
+	return model, tmux.NewClient(), os.Getenv("TMUX") != "", nil
+}
+
+// dashboardDisplay resolves the dashboard theme and ASCII mode from the
+// --theme and --ascii flags, falling back to the theme and ascii config keys.
+// The "auto" theme asks the terminal for its background color.
+func dashboardDisplay() (tui.Theme, bool, error) {
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		return tui.Theme{}, false, err
+	}
+	name := cfg.Theme
+	if themeName != "" {
+		name = themeName
+	}
+	theme, err := tui.ResolveTheme(name, lipgloss.HasDarkBackground)
+	return theme, asciiMode || cfg.ASCII, err
+}
+
 func init() {
 	dashCmd.Flags().StringVar(&dashMode, "mode", string(tui.DashboardModeWorktree), "dashboard mode: worktree or agents")
+	dashCmd.Flags().StringVar(&dashFilter, "filter", "", "start with this filter query applied")
+	dashCmd.Flags().StringVar(&dashRepo, "repo", "", "scope the dashboard to one configured project (name or path)")
+	dashCmd.Flags().StringVar(&dashWorkspace, "workspace", "", "show only the projects of this workspace")
+	dashCmd.Flags().BoolVar(&dashReadOnly, "read-only", false, "disable keybindings that create, kill, or archive sessions")
+	dashCmd.Flags().BoolVar(&dashPopup, "popup", false, "compact frameless rendering for tmux display-popup")
 	rootCmd.AddCommand(dashCmd)
 }
@@ -2,25 +2,43 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
 	"github.com/ronsanzone/clawd-bay/internal/tui"
 )
 
 type fakeDashTmuxClient struct {
-	calls               []string
-	selectedSession     string
-	selectedWindowIndex int
-	attachedSession     string
-	inTmux              bool
-	selectErr           error
-	attachErr           error
+	missing         bool
+	sessions        []tmux.Session
+	windows         []tmux.Window
+	calls           []string
+	selectedTarget  string
+	attachedSession string
+	inTmux          bool
+	selectErr       error
+	attachErr       error
 }
 
-func (f *fakeDashTmuxClient) SelectWindow(session string, windowIndex int) error {
+func (f *fakeDashTmuxClient) HasSession(name string) bool { return !f.missing }
+
+func (f *fakeDashTmuxClient) ListAllSessions() ([]tmux.Session, error) { return f.sessions, nil }
+
+// ListWindows fails unless windows are set, so attaching keeps the captured
+// window target.
+func (f *fakeDashTmuxClient) ListWindows(session string) ([]tmux.Window, error) {
+	if f.windows == nil {
+		return nil, errors.New("no windows")
+	}
+	return f.windows, nil
+}
+
+func (f *fakeDashTmuxClient) SelectWindow(target string) error {
 	f.calls = append(f.calls, "select")
-	f.selectedSession = session
-	f.selectedWindowIndex = windowIndex
+	f.selectedTarget = target
 	return f.selectErr
 }
 
@@ -34,8 +52,7 @@ func (f *fakeDashTmuxClient) AttachOrSwitchToSession(name string, inTmux bool) e
 func TestAttachDashboardSelection_SessionOnly(t *testing.T) {
 	client := &fakeDashTmuxClient{}
 	model := tui.Model{
-		SelectedName:        "cb_demo",
-		SelectedWindowIndex: -1,
+		SelectedName: "cb_demo",
 	}
 
 	err := attachDashboardSelection(client, model, true)
@@ -56,8 +73,9 @@ func TestAttachDashboardSelection_SessionOnly(t *testing.T) {
 func TestAttachDashboardSelection_WindowSelectionOrder(t *testing.T) {
 	client := &fakeDashTmuxClient{}
 	model := tui.Model{
-		SelectedName:        "cb_demo",
-		SelectedWindowIndex: 2,
+		SelectedName:         "cb_demo",
+		SelectedWindow:       "claude",
+		SelectedWindowTarget: "@12",
 	}
 
 	err := attachDashboardSelection(client, model, false)
@@ -70,8 +88,8 @@ func TestAttachDashboardSelection_WindowSelectionOrder(t *testing.T) {
 	if client.calls[0] != "select" || client.calls[1] != "attach" {
 		t.Fatalf("calls = %v, want [select attach]", client.calls)
 	}
-	if client.selectedSession != "cb_demo" || client.selectedWindowIndex != 2 {
-		t.Fatalf("select args = (%q, %d), want (%q, %d)", client.selectedSession, client.selectedWindowIndex, "cb_demo", 2)
+	if client.selectedTarget != "@12" {
+		t.Fatalf("selectedTarget = %q, want %q", client.selectedTarget, "@12")
 	}
 	if client.attachedSession != "cb_demo" {
 		t.Fatalf("attachedSession = %q, want %q", client.attachedSession, "cb_demo")
@@ -84,8 +102,8 @@ func TestAttachDashboardSelection_WindowSelectionOrder(t *testing.T) {
 func TestAttachDashboardSelection_SelectError(t *testing.T) {
 	client := &fakeDashTmuxClient{selectErr: errors.New("select failed")}
 	model := tui.Model{
-		SelectedName:        "cb_demo",
-		SelectedWindowIndex: 1,
+		SelectedName:         "cb_demo",
+		SelectedWindowTarget: "@11",
 	}
 
 	err := attachDashboardSelection(client, model, false)
@@ -100,8 +118,7 @@ func TestAttachDashboardSelection_SelectError(t *testing.T) {
 func TestAttachDashboardSelection_AttachError(t *testing.T) {
 	client := &fakeDashTmuxClient{attachErr: errors.New("attach failed")}
 	model := tui.Model{
-		SelectedName:        "cb_demo",
-		SelectedWindowIndex: -1,
+		SelectedName: "cb_demo",
 	}
 
 	err := attachDashboardSelection(client, model, false)
@@ -156,3 +173,158 @@ func TestDashModeParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveDashRepoScope(t *testing.T) {
+	cfg := config.UserConfig{Projects: []config.ProjectConfig{
+		{Path: "/code/repo-a", Name: "alpha"},
+		{Path: "/code/repo-b"},
+		{Path: "/other/dup", Name: "dup"},
+		{Path: "/more/dup", Name: "dup"},
+	}}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    tui.RepoScope
+		wantErr bool
+	}{
+		{name: "configured name", value: "alpha", want: tui.RepoScope{Label: "alpha", Path: "/code/repo-a"}},
+		{name: "directory name", value: "repo-b", want: tui.RepoScope{Label: "repo-b", Path: "/code/repo-b"}},
+		{name: "ambiguous", value: "dup", wantErr: true},
+		{name: "unknown", value: "nope", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDashRepoScope(cfg, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveDashRepoScope() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("resolveDashRepoScope() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownWorkspaceError(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.UserConfig
+		want string
+	}{
+		{name: "none configured", cfg: config.UserConfig{Projects: []config.ProjectConfig{{Path: "/code/api"}}}, want: "no project sets workspace"},
+		{name: "lists configured", cfg: config.UserConfig{Projects: []config.ProjectConfig{
+			{Path: "/code/api", Workspace: "work"},
+			{Path: "/oss/lib", Workspace: "oss"},
+		}}, want: "configured: oss, work"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := unknownWorkspaceError(tt.cfg, "home"); !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("unknownWorkspaceError() = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttachDashboardSelection_SessionGoneSuggestsNearMatches(t *testing.T) {
+	client := &fakeDashTmuxClient{
+		missing:  true,
+		sessions: []tmux.Session{{Name: "cb_auth-fix"}, {Name: "cb_auth-fix-2"}, {Name: "notes"}},
+	}
+	model := tui.Model{SelectedName: "cb_auth-fixx", SelectedWindowTarget: "@12"}
+
+	err := attachDashboardSelection(client, model, false)
+	if err == nil {
+		t.Fatal("attachDashboardSelection() error = nil, want session gone error")
+	}
+	if len(client.calls) != 0 {
+		t.Fatalf("calls = %v, want no tmux select/attach", client.calls)
+	}
+	want := "session cb_auth-fixx is gone — refresh the dashboard and try again (did you mean cb_auth-fix, cb_auth-fix-2?)"
+	if err.Error() != want {
+		t.Fatalf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestAttachDashboardSelection_WindowGone(t *testing.T) {
+	client := &fakeDashTmuxClient{selectErr: fmt.Errorf("failed to select window: %w", tmux.ErrWindowNotFound)}
+	model := tui.Model{SelectedName: "cb_demo", SelectedWindow: "claude", SelectedWindowTarget: "@14"}
+
+	err := attachDashboardSelection(client, model, false)
+	if err == nil || !strings.Contains(err.Error(), "window claude of session cb_demo is gone") {
+		t.Fatalf("error = %v, want window gone message", err)
+	}
+}
+
+func TestAttachDashboardSelection_ReresolvesMovedWindows(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		windows    []tmux.Window
+		wantCalls  string
+		wantTarget string
+	}{
+		{
+			name:       "window id still exists",
+			target:     "@12",
+			windows:    []tmux.Window{{ID: "@12", Index: 4, Name: "renamed"}},
+			wantCalls:  "select,attach",
+			wantTarget: "@12",
+		},
+		{
+			name:       "renumbered window found by name",
+			target:     "cb_demo:1",
+			windows:    []tmux.Window{{Index: 1, Name: "shell"}, {Index: 2, Name: "claude"}},
+			wantCalls:  "select,attach",
+			wantTarget: "cb_demo:2",
+		},
+		{
+			name:       "closed window falls back to the session",
+			target:     "@12",
+			windows:    []tmux.Window{{ID: "@3", Index: 0, Name: "shell"}},
+			wantCalls:  "attach",
+			wantTarget: "",
+		},
+		{
+			name:       "ambiguous name falls back to the session",
+			target:     "cb_demo:0",
+			windows:    []tmux.Window{{Index: 0, Name: "shell"}, {Index: 1, Name: "claude"}, {Index: 2, Name: "claude"}},
+			wantCalls:  "attach",
+			wantTarget: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings strings.Builder
+			orig := dashErrWriter
+			dashErrWriter = &warnings
+			t.Cleanup(func() { dashErrWriter = orig })
+
+			client := &fakeDashTmuxClient{windows: tt.windows}
+			model := tui.Model{SelectedName: "cb_demo", SelectedWindow: "claude", SelectedWindowTarget: tt.target}
+			if err := attachDashboardSelection(client, model, true); err != nil {
+				t.Fatalf("attachDashboardSelection() error = %v", err)
+			}
+			if got := strings.Join(client.calls, ","); got != tt.wantCalls || client.selectedTarget != tt.wantTarget {
+				t.Fatalf("calls = %s selecting %q, want %s selecting %q", got, client.selectedTarget, tt.wantCalls, tt.wantTarget)
+			}
+			if fellBack := tt.wantTarget == ""; fellBack != strings.Contains(warnings.String(), "is gone") {
+				t.Fatalf("warnings = %q", warnings.String())
+			}
+		})
+	}
+}
+
+func TestSuggestSessionNames(t *testing.T) {
+	candidates := []string{"cb_api", "cb_api-v2", "cb_web", "scratch", "cb_apx"}
+	got := suggestSessionNames("cb_api", candidates)
+	want := []string{"cb_apx", "cb_api-v2"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("suggestSessionNames() = %v, want %v", got, want)
+	}
+	if got := suggestSessionNames("cb_zzzzzzzz", candidates); len(got) != 0 {
+		t.Fatalf("suggestSessionNames() = %v, want none", got)
+	}
+}
@@ -0,0 +1,338 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/logging"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+// debugLogTailLines bounds how much of the debug log a dump includes.
+const debugLogTailLines = 2000
+
+var debugDumpOutput string
+
+// debugExecCommand runs the tmux listings of a dump; tests replace it.
+var debugExecCommand = func(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// debugTmuxListings are the tmux commands a dump records, by file name.
+var debugTmuxListings = []struct {
+	file string
+	args []string
+}{
+	{"tmux/version.txt", []string{"-V"}},
+	{"tmux/sessions.txt", []string{"list-sessions", "-F", "#{session_id}\t#{session_name}\t#{session_windows}\t#{session_attached}\t#{session_path}"}},
+	{"tmux/windows.txt", []string{"list-windows", "-a", "-F", "#{session_name}\t#{window_id}\t#{window_index}\t#{window_name}\t#{window_panes}\t#{window_activity}"}},
+	{"tmux/panes.txt", []string{"list-panes", "-a", "-F", "#{session_name}\t#{window_id}\t#{pane_id}\t#{pane_current_command}\t#{pane_tty}\t#{pane_current_path}"}},
+}
+
+var debugCmd = &cobra.Command{
+	Use:   "debug",
+	Short: "Collect diagnostics for bug reports",
+}
+
+var debugDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Write a diagnostics tarball to attach to a bug report",
+	Long: `Writes a gzipped tarball with what is needed to reproduce discovery issues:
+
+  info.txt          cb, Go, and platform versions
+  config.toml       the config file as written
+  discovery.json    the project/worktree/session tree cb dash would show
+  tmux/*.txt        tmux sessions, windows, and panes (no pane contents)
+  cb-debug.log      the last 2000 lines of the --debug log, if any
+  errors.txt        what could not be collected
+
+Your home directory is written as ~ everywhere, and pane contents are never
+captured: the debug log records only the size of each capture, and records
+carrying pane content from older cb versions are left out. To include a debug log, run the misbehaving command with --debug
+first, then run cb debug dump.
+
+Example:
+  cb debug dump
+  cb debug dump -o /tmp/cb-bug.tar.gz`,
+	Args: cobra.NoArgs,
+	RunE: runDebugDump,
+}
+
+var debugTimingsCmd = &cobra.Command{
+	Use:   "timings",
+	Short: "Summarize the tmux/git commands recorded in the debug log",
+	Long: `Reads the external commands recorded by the last run with --debug or
+--log-commands and prints, per command (program and subcommand), how often it
+ran, how many runs failed, and its total, average, and slowest duration. The
+most expensive commands come first, which points at what makes a refresh slow.
+
+Example:
+  cb --log-commands dash   # use the dashboard for a few refreshes, then quit
+  cb debug timings`,
+	Args: cobra.NoArgs,
+	RunE: runDebugTimings,
+}
+
+func init() {
+	debugDumpCmd.Flags().StringVarP(&debugDumpOutput, "output", "o", "", "tarball path (default: cb-debug-<timestamp>.tar.gz in the current directory)")
+	debugCmd.AddCommand(debugDumpCmd)
+	debugCmd.AddCommand(debugTimingsCmd)
+	rootCmd.AddCommand(debugCmd)
+}
+
+// debugFile is one entry of a debug dump.
+type debugFile struct {
+	name string
+	data []byte
+}
+
+func runDebugDump(cmd *cobra.Command, args []string) error {
+	now := time.Now()
+	path := debugDumpOutput
+	if path == "" {
+		path = fmt.Sprintf("cb-debug-%s.tar.gz", now.Format("20060102-150405"))
+	}
+
+	files := collectDebugFiles(now)
+	home, _ := os.UserHomeDir()
+	for i := range files {
+		files[i].data = redactHome(files[i].data, home)
+	}
+
+	var buf bytes.Buffer
+	if err := writeDebugTarball(&buf, files, now); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write debug dump: %w", err)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s (%d files). Check it before attaching it to a bug report.\n", path, len(files))
+	return nil
+}
+
+// collectDebugFiles gathers the dump entries. Anything that cannot be
+// collected is noted in errors.txt rather than failing the dump.
+func collectDebugFiles(now time.Time) []debugFile {
+	var errs []string
+	noteErr := func(err error) { errs = append(errs, err.Error()) }
+
+	files := []debugFile{{name: "info.txt", data: debugInfo(now)}}
+
+	if c, err := config.New(); err != nil {
+		noteErr(err)
+	} else if data, err := os.ReadFile(c.ConfigFilePath()); err != nil {
+		noteErr(fmt.Errorf("failed to read config: %w", err))
+	} else {
+		files = append(files, debugFile{name: "config.toml", data: data})
+	}
+
+	if result, err := discovery.NewService(tmux.NewClient()).Discover(); err != nil {
+		noteErr(fmt.Errorf("failed to discover sessions: %w", err))
+	} else if data, err := json.MarshalIndent(result, "", "  "); err != nil {
+		noteErr(fmt.Errorf("failed to encode discovery result: %w", err))
+	} else {
+		files = append(files, debugFile{name: "discovery.json", data: append(data, '\n')})
+	}
+
+	for _, listing := range debugTmuxListings {
+		output, err := debugExecCommand("tmux", listing.args...)
+		if err != nil {
+			noteErr(fmt.Errorf("tmux %s: %w: %s", listing.args[0], err, strings.TrimSpace(string(output))))
+			continue
+		}
+		files = append(files, debugFile{name: listing.file, data: output})
+	}
+
+	if data, err := os.ReadFile(debugLogPath()); err == nil {
+		files = append(files, debugFile{name: "cb-debug.log", data: tailLines(dropPaneContent(data), debugLogTailLines)})
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		noteErr(fmt.Errorf("failed to read debug log: %w", err))
+	}
+
+	if len(errs) > 0 {
+		files = append(files, debugFile{name: "errors.txt", data: []byte(strings.Join(errs, "\n") + "\n")})
+	}
+	return files
+}
+
+func debugInfo(now time.Time) []byte {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "cb %s\n", Version)
+	_, _ = fmt.Fprintf(&b, "go %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	_, _ = fmt.Fprintf(&b, "generated %s\n", now.Format(time.RFC3339))
+	_, _ = fmt.Fprintf(&b, "inside tmux: %t\n", os.Getenv("TMUX") != "")
+	_, _ = fmt.Fprintf(&b, "TERM=%s\n", os.Getenv("TERM"))
+	return []byte(b.String())
+}
+
+// redactHome replaces the home directory in data with ~, so a dump does not
+// reveal local account names.
+func redactHome(data []byte, home string) []byte {
+	if home == "" || home == "/" {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte(home), []byte("~"))
+}
+
+// dropPaneContent removes the debug log records that carry captured pane
+// content, which cb versions before the size-only detectAgentActivity record
+// wrote verbatim.
+func dropPaneContent(data []byte) []byte {
+	var out []byte
+	for line := range bytes.Lines(data) {
+		if bytes.Contains(line, []byte(" content=")) {
+			continue
+		}
+		out = append(out, line...)
+	}
+	return out
+}
+
+// tailLines returns the last n lines of data.
+func tailLines(data []byte, n int) []byte {
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	for i := end - 1; i >= 0; i-- {
+		if data[i] == '\n' {
+			n--
+			if n == 0 {
+				return data[i+1:]
+			}
+		}
+	}
+	return data
+}
+
+// writeDebugTarball writes files to w as a gzipped tarball under a
+// cb-debug/ directory.
+func writeDebugTarball(w io.Writer, files []debugFile, now time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    "cb-debug/" + f.name,
+			Mode:    0o600,
+			Size:    int64(len(f.data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write debug dump: %w", err)
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return fmt.Errorf("failed to write debug dump: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write debug dump: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write debug dump: %w", err)
+	}
+	return nil
+}
+
+// commandTiming aggregates the records of one command.
+type commandTiming struct {
+	Op       string
+	Count    int
+	Failures int
+	Total    time.Duration
+	Max      time.Duration
+}
+
+func runDebugTimings(cmd *cobra.Command, args []string) error {
+	path := debugLogPath()
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no debug log at %s; run a command with --log-commands or --debug first", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open debug log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	records, err := logging.ReadCommandRecords(f)
+	if err != nil {
+		return err
+	}
+	writeCommandTimings(cmd.OutOrStdout(), summarizeCommandTimings(records))
+	return nil
+}
+
+// summarizeCommandTimings groups records by command, most total time first.
+func summarizeCommandTimings(records []logging.CommandRecord) []commandTiming {
+	byOp := make(map[string]*commandTiming)
+	var timings []*commandTiming
+	for _, r := range records {
+		t, ok := byOp[r.Op]
+		if !ok {
+			t = &commandTiming{Op: r.Op}
+			byOp[r.Op] = t
+			timings = append(timings, t)
+		}
+		t.Count++
+		if r.Exit != 0 {
+			t.Failures++
+		}
+		t.Total += r.Duration
+		t.Max = max(t.Max, r.Duration)
+	}
+
+	rows := make([]commandTiming, 0, len(timings))
+	for _, t := range timings {
+		rows = append(rows, *t)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Total != rows[j].Total {
+			return rows[i].Total > rows[j].Total
+		}
+		return rows[i].Op < rows[j].Op
+	})
+	return rows
+}
+
+func writeCommandTimings(w io.Writer, rows []commandTiming) {
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(w, "No commands recorded. Run a command with --log-commands or --debug first.")
+		return
+	}
+	var count int
+	var total time.Duration
+	_, _ = fmt.Fprintf(w, "%-24s %6s %6s %10s %10s %10s\n", "COMMAND", "RUNS", "FAILED", "TOTAL", "AVG", "MAX")
+	for _, t := range rows {
+		_, _ = fmt.Fprintf(w, "%-24s %6d %6d %10s %10s %10s\n",
+			t.Op, t.Count, t.Failures, formatTiming(t.Total), formatTiming(t.Total/time.Duration(t.Count)), formatTiming(t.Max))
+		count += t.Count
+		total += t.Total
+	}
+	_, _ = fmt.Fprintf(w, "%d commands, %s total\n", count, formatTiming(total))
+}
+
+// formatTiming rounds d to a readable precision.
+func formatTiming(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/logging"
+)
+
+func TestWriteDebugTarball(t *testing.T) {
+	files := []debugFile{
+		{name: "info.txt", data: []byte("cb 0.2.0\n")},
+		{name: "tmux/sessions.txt", data: []byte("$1\tcb_feat\n")},
+	}
+	var buf bytes.Buffer
+	if err := writeDebugTarball(&buf, files, time.Unix(0, 0)); err != nil {
+		t.Fatalf("writeDebugTarball() error = %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	tr := tar.NewReader(gz)
+	got := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar Next() error = %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		got[header.Name] = string(data)
+	}
+
+	want := map[string]string{
+		"cb-debug/info.txt":          "cb 0.2.0\n",
+		"cb-debug/tmux/sessions.txt": "$1\tcb_feat\n",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("tarball entries = %v, want %v", got, want)
+	}
+	for name, data := range want {
+		if got[name] != data {
+			t.Errorf("entry %s = %q, want %q", name, got[name], data)
+		}
+	}
+}
+
+func TestRedactHome(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		home string
+		want string
+	}{
+		{"replaces every occurrence", `path = "/home/ron/src/app"` + "\n/home/ron/.config/cb", "/home/ron", `path = "~/src/app"` + "\n~/.config/cb"},
+		{"leaves other paths", "/tmp/cb-debug.log", "/home/ron", "/tmp/cb-debug.log"},
+		{"skips an empty home", "/src/app", "", "/src/app"},
+		{"skips a root home", "/src/app", "/", "/src/app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redactHome([]byte(tt.data), tt.home)); got != tt.want {
+				t.Errorf("redactHome() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailLines(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		n    int
+		want string
+	}{
+		{"keeps the last lines", "a\nb\nc\n", 2, "b\nc\n"},
+		{"without a trailing newline", "a\nb\nc", 2, "b\nc"},
+		{"shorter than n", "a\nb\n", 5, "a\nb\n"},
+		{"empty", "", 3, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tailLines([]byte(tt.data), tt.n)); got != tt.want {
+				t.Errorf("tailLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDropPaneContent(t *testing.T) {
+	data := "level=DEBUG msg=detectAgentActivity target=@1\n" +
+		"level=DEBUG msg=detectAgentActivity target=@1 content=\"secret token\\n> \"\n" +
+		"level=DEBUG msg=detectAgentActivity target=@1 bytes=42\n"
+	want := "level=DEBUG msg=detectAgentActivity target=@1\n" +
+		"level=DEBUG msg=detectAgentActivity target=@1 bytes=42\n"
+	if got := string(dropPaneContent([]byte(data))); got != want {
+		t.Fatalf("dropPaneContent() = %q, want %q", got, want)
+	}
+}
+
+func TestSummarizeCommandTimings(t *testing.T) {
+	records := []logging.CommandRecord{
+		{Op: "tmux list-panes", Duration: 2 * time.Millisecond},
+		{Op: "git rev-parse", Duration: 10 * time.Millisecond, Exit: 128},
+		{Op: "tmux list-panes", Duration: 4 * time.Millisecond},
+		{Op: "tmux capture-pane", Duration: 6 * time.Millisecond},
+	}
+
+	got := summarizeCommandTimings(records)
+	want := []commandTiming{
+		{Op: "git rev-parse", Count: 1, Failures: 1, Total: 10 * time.Millisecond, Max: 10 * time.Millisecond},
+		{Op: "tmux capture-pane", Count: 1, Total: 6 * time.Millisecond, Max: 6 * time.Millisecond},
+		{Op: "tmux list-panes", Count: 2, Total: 6 * time.Millisecond, Max: 4 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("summarizeCommandTimings() = %+v, want %+v", got, want)
+	}
+}
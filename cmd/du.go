@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/diskusage"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/ronsanzone/clawd-bay/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// duWorkers bounds how many worktrees are measured at once.
+const duWorkers = 4
+
+var duTop int
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Rank worktrees by disk usage",
+	Long: `Measures every linked worktree of the configured projects with du and lists
+them largest first, followed by the total. Dependencies and build output
+(node_modules, target/, .venv) count too, which is usually where the space
+goes.
+
+Main repos are left out, since their size includes .worktrees/. Measuring a
+large worktree takes a few seconds; up to four are measured at once. The
+dashboard's detail popup ("i") shows the same size for the session's worktree.
+
+Example:
+  cb du
+  cb du --top 5`,
+	Args: cobra.NoArgs,
+	RunE: runDu,
+}
+
+func init() {
+	duCmd.Flags().IntVar(&duTop, "top", 0, "only list the N largest worktrees (0 lists all)")
+	rootCmd.AddCommand(duCmd)
+}
+
+// duRow is one measured worktree.
+type duRow struct {
+	Project  string
+	Worktree string
+	Path     string
+	KB       int64
+	Err      error
+}
+
+// duTargets returns the linked worktrees of every valid project, unmeasured.
+func duTargets(result discovery.Result) []duRow {
+	var rows []duRow
+	for _, project := range result.Projects {
+		if project.InvalidError != "" {
+			continue
+		}
+		for _, wt := range project.Worktrees {
+			if wt.IsMainRepo || wt.IsMissing {
+				continue
+			}
+			rows = append(rows, duRow{
+				Project:  project.Name,
+				Worktree: discovery.WorktreeLabel(wt.Name, wt.Branch),
+				Path:     wt.Path,
+			})
+		}
+	}
+	return rows
+}
+
+// measureDuRows measures rows with up to workers calls of measure at once,
+// then sorts them largest first with failures last.
+func measureDuRows(rows []duRow, measure func(path string) (int64, error), workers int) {
+	sem := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+	for i := range rows {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(row *duRow) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			row.KB, row.Err = measure(row.Path)
+		}(&rows[i])
+	}
+	wg.Wait()
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if (rows[i].Err == nil) != (rows[j].Err == nil) {
+			return rows[i].Err == nil
+		}
+		return rows[i].KB > rows[j].KB
+	})
+}
+
+func writeDuRows(w, errWriter io.Writer, rows []duRow, top int) {
+	var total int64
+	measured := 0
+	for _, r := range rows {
+		if r.Err == nil {
+			total += r.KB
+			measured++
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "%7s  %-40s %s\n", "SIZE", "WORKTREE", "PROJECT")
+	for i, r := range rows {
+		if top > 0 && i >= top {
+			break
+		}
+		if r.Err != nil {
+			_, _ = fmt.Fprintf(errWriter, "Warning: %v\n", r.Err)
+			_, _ = fmt.Fprintf(w, "%7s  %-40s %s\n", "?", r.Worktree, r.Project)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "%7s  %-40s %s\n", tui.FormatMemory(r.KB), r.Worktree, r.Project)
+	}
+	_, _ = fmt.Fprintf(w, "%7s  total of %d worktree(s)\n", tui.FormatMemory(total), measured)
+}
+
+func runDu(cmd *cobra.Command, args []string) error {
+	result, err := discovery.NewService(tmux.NewClient()).Discover()
+	if err != nil {
+		return err
+	}
+
+	rows := duTargets(result)
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No worktrees to measure.")
+		return nil
+	}
+	measureDuRows(rows, diskusage.Measure, duWorkers)
+	writeDuRows(cmd.OutOrStdout(), cmd.ErrOrStderr(), rows, duTop)
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+)
+
+func TestDuTargets(t *testing.T) {
+	result := discovery.Result{Projects: []discovery.ProjectNode{
+		{
+			Name: "app",
+			Path: "/code/app",
+			Worktrees: []discovery.WorktreeNode{
+				{Name: "(main repo)", Path: "/code/app", IsMainRepo: true},
+				{Name: ".worktrees/app-feat-a-63143a", Path: "/code/app/.worktrees/app-feat-a-63143a", Branch: "feat/a-long-name"},
+				{Name: "(missing worktree)", IsMissing: true},
+			},
+		},
+		{Name: "broken", Path: "/code/broken", InvalidError: "missing"},
+	}}
+
+	rows := duTargets(result)
+	if len(rows) != 1 {
+		t.Fatalf("rows = %+v, want only the linked worktree", rows)
+	}
+	want := duRow{Project: "app", Worktree: ".worktrees/app-feat-a-63143a (feat/a-long-name)", Path: "/code/app/.worktrees/app-feat-a-63143a"}
+	if rows[0] != want {
+		t.Fatalf("rows[0] = %+v, want %+v", rows[0], want)
+	}
+}
+
+func TestMeasureDuRows(t *testing.T) {
+	sizes := map[string]int64{"/small": 10, "/big": 5 * 1024 * 1024, "/mid": 2048}
+	rows := []duRow{{Path: "/small"}, {Path: "/broken"}, {Path: "/big"}, {Path: "/mid"}}
+	measureDuRows(rows, func(path string) (int64, error) {
+		kb, ok := sizes[path]
+		if !ok {
+			return 0, errors.New("permission denied")
+		}
+		return kb, nil
+	}, 2)
+
+	var order []string
+	for _, r := range rows {
+		order = append(order, r.Path)
+	}
+	if got := strings.Join(order, " "); got != "/big /mid /small /broken" {
+		t.Fatalf("order = %s, want largest first with failures last", got)
+	}
+}
+
+func TestWriteDuRows(t *testing.T) {
+	rows := []duRow{
+		{Project: "app", Worktree: ".worktrees/app-big", KB: 5 * 1024 * 1024},
+		{Project: "app", Worktree: ".worktrees/app-mid", KB: 2048},
+		{Project: "lib", Worktree: ".worktrees/lib-x", Err: errors.New("permission denied")},
+	}
+
+	var out bytes.Buffer
+	writeDuRows(&out, io.Discard, rows, 1)
+	want := "   SIZE  WORKTREE                                 PROJECT\n" +
+		"   5.0G  .worktrees/app-big                       app\n" +
+		"   5.0G  total of 2 worktree(s)\n"
+	if out.String() != want {
+		t.Fatalf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
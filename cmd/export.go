@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the dashboard snapshot as a report",
+	Long: `Renders the current project/worktree/session tree with statuses as a report
+on stdout, ready to paste into a standup note or an issue update.
+
+Each session lists its rolled-up status, window count, tags, and note, followed
+by its agent windows and their statuses. Worktrees show their diff stat.
+
+Example:
+  cb export --format markdown
+  cb export > standup.md`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "markdown", "report format (markdown)")
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if format := strings.ToLower(exportFormat); format != "markdown" && format != "md" {
+		return fmt.Errorf("unsupported format %q (want markdown)", exportFormat)
+	}
+
+	result, err := newDaemonDiscoverer(context.Background(), tmux.NewClient()).Discover()
+	if err != nil {
+		return err
+	}
+	if result.ConfigMissing {
+		return fmt.Errorf("no project config found; add one with: cb project add <path>")
+	}
+	writeMarkdownReport(cmd.OutOrStdout(), result, time.Now())
+	return nil
+}
+
+// writeMarkdownReport renders result as a markdown report generated at now.
+func writeMarkdownReport(w io.Writer, result discovery.Result, now time.Time) {
+	_, _ = fmt.Fprintf(w, "# ClawdBay snapshot (%s)\n", now.Format("2006-01-02 15:04"))
+	if len(result.Projects) == 0 {
+		_, _ = fmt.Fprintln(w, "\n_No configured projects._")
+		return
+	}
+
+	for _, project := range result.Projects {
+		_, _ = fmt.Fprintf(w, "\n## %s\n\n", project.Name)
+		if project.InvalidError != "" {
+			_, _ = fmt.Fprintf(w, "> **Invalid:** %s\n\n", project.InvalidError)
+		}
+		if len(project.StaleWorktrees) > 0 {
+			_, _ = fmt.Fprintf(w, "> **Stale:** %s (deleted outside cb)\n\n", markdownEscape(strings.Join(project.StaleWorktrees, ", ")))
+		}
+		for _, wt := range project.Worktrees {
+			line := fmt.Sprintf("- **%s**", markdownEscape(wt.Name))
+			if stat := wt.DiffStat; stat != nil && stat.Files > 0 {
+				line += fmt.Sprintf(" (+%d -%d in %d files)", stat.Insertions, stat.Deletions, stat.Files)
+			}
+			_, _ = fmt.Fprintln(w, line)
+			if len(wt.Sessions) == 0 {
+				_, _ = fmt.Fprintln(w, "  - _no active session_")
+				continue
+			}
+			for _, s := range wt.Sessions {
+				writeMarkdownSession(w, s, result.WindowStatuses)
+			}
+		}
+	}
+}
+
+func writeMarkdownSession(w io.Writer, s discovery.SessionNode, statuses map[string]tmux.Status) {
+	windowWord := "windows"
+	if len(s.Windows) == 1 {
+		windowWord = "window"
+	}
+	line := fmt.Sprintf("  - `%s`: %s, %d %s", s.Name, s.Status, len(s.Windows), windowWord)
+	if len(s.Tags) > 0 {
+		line += " " + markdownEscape(formatTagChips(s.Tags))
+	}
+	if s.Note != "" {
+		line += " — " + markdownEscape(s.Note)
+	}
+	_, _ = fmt.Fprintln(w, line)
+	for _, win := range s.Windows {
+		if status, ok := statuses[win.Target(s.Name)]; ok {
+			_, _ = fmt.Fprintf(w, "    - %s: %s\n", markdownEscape(win.Name), status)
+		}
+	}
+}
+
+// markdownEscape backslash-escapes characters that would otherwise be read
+// as markdown formatting.
+func markdownEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]<>#|", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
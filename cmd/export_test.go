@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestWriteMarkdownReport(t *testing.T) {
+	result := discovery.Result{
+		Projects: []discovery.ProjectNode{{
+			Name: "repo",
+			Worktrees: []discovery.WorktreeNode{
+				{Name: "(main repo)", Sessions: []discovery.SessionNode{{
+					Name:    "cb_main",
+					Status:  tmux.StatusWaiting,
+					Windows: []tmux.Window{{ID: "@1", Index: 0, Name: "claude"}, {ID: "@2", Index: 1, Name: "shell"}},
+					Tags:    []string{"urgent"},
+					Note:    "needs *review*",
+				}}},
+				{Name: ".worktrees/repo-feat", DiffStat: &git.DiffStat{Files: 2, Insertions: 10, Deletions: 3}},
+			},
+		}},
+		WindowStatuses: map[string]tmux.Status{"@1": tmux.StatusWaiting},
+	}
+
+	var buf bytes.Buffer
+	writeMarkdownReport(&buf, result, time.Date(2026, 1, 2, 3, 4, 0, 0, time.Local))
+
+	want := "# ClawdBay snapshot (2026-01-02 03:04)\n" +
+		"\n## repo\n\n" +
+		"- **(main repo)**\n" +
+		"  - `cb_main`: WAITING, 2 windows \\#urgent — needs \\*review\\*\n" +
+		"    - claude: WAITING\n" +
+		"- **.worktrees/repo-feat** (+10 -3 in 2 files)\n" +
+		"  - _no active session_\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("writeMarkdownReport() =\n%s\nwant\n%s", got, want)
+	}
+}
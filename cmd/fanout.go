@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/agentconfig"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fanoutTaskFile string
+	fanoutAgent    string
+	fanoutPrefix   string
+	fanoutSplit    string
+	fanoutWait     bool
+	fanoutTimeout  time.Duration
+)
+
+var fanoutCmd = &cobra.Command{
+	Use:   "fanout --task-file <tasks.md>",
+	Short: "Start one agent workflow per task in a task file",
+	Long: `Reads tasks from a markdown file, creates a worktree and session for each
+like cb run, launches an agent in each, and sends it the task text. Prints a
+progress summary once every agent has its task; with --wait, blocks until all
+agents settle and prints the final summary.
+
+Each "## " heading starts a task. The heading becomes the branch name
+(sanitized like cb start) and the heading plus the text below it, up to the
+next heading, is the prompt.
+
+  ## Fix login redirect
+  The login form redirects to / instead of the page the user came from.
+
+  ## Add auth tests
+  Cover token refresh and expiry in internal/auth.
+
+Example:
+  cb fanout --task-file tasks.md
+  cb fanout --task-file tasks.md --prefix try1/ --agent codex --wait
+  cb fanout --task-file tasks.md --split "npm test -- --watch"`,
+	Args: cobra.NoArgs,
+	RunE: runFanout,
+}
+
+func init() {
+	fanoutCmd.Flags().StringVarP(&fanoutTaskFile, "task-file", "f", "", "Markdown file with one \"## \" section per task (required)")
+	fanoutCmd.Flags().StringVar(&fanoutAgent, "agent", string(tmux.AgentClaude), "Agent to launch: claude, codex, or opencode")
+	fanoutCmd.Flags().StringVar(&fanoutPrefix, "prefix", "", "Prefix for every task branch name (e.g. fanout/)")
+	fanoutCmd.Flags().StringVar(&fanoutSplit, "split", "", "Split each agent window and run this command in the second pane (\"\" for a shell)")
+	fanoutCmd.Flags().BoolVar(&fanoutWait, "wait", false, "Block until every agent is WAITING or DONE and print a final summary")
+	fanoutCmd.Flags().DurationVar(&fanoutTimeout, "timeout", 0, "Give up waiting after this long (0 means no limit)")
+	_ = fanoutCmd.MarkFlagRequired("task-file")
+	rootCmd.AddCommand(fanoutCmd)
+}
+
+// fanoutTask is one task parsed from a task file.
+type fanoutTask struct {
+	Branch string
+	Prompt string
+}
+
+// parseTaskFile splits markdown into tasks at "## " headings. Text before the
+// first heading is ignored. Prompts are flattened to one line because a
+// newline typed into an agent's input submits it.
+func parseTaskFile(content, prefix string) ([]fanoutTask, error) {
+	var tasks []fanoutTask
+	var title string
+	var body []string
+	flush := func() error {
+		if title == "" {
+			return nil
+		}
+		branch := sanitizeBranchName(prefix + title)
+		if branch == "" {
+			return fmt.Errorf("task %q has no usable branch name", title)
+		}
+		for _, t := range tasks {
+			if t.Branch == branch {
+				return fmt.Errorf("duplicate task branch %q", branch)
+			}
+		}
+		prompt := strings.Join(append([]string{title}, body...), " ")
+		tasks = append(tasks, fanoutTask{Branch: branch, Prompt: prompt})
+		return nil
+	}
+
+	for line := range strings.SplitSeq(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if heading, ok := strings.CutPrefix(trimmed, "## "); ok {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			title, body = strings.TrimSpace(heading), nil
+			continue
+		}
+		if title != "" && trimmed != "" {
+			body = append(body, trimmed)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks found; start each task with a \"## \" heading")
+	}
+	return tasks, nil
+}
+
+// fanoutRun tracks one task's workflow. Err is set when the task failed and
+// was skipped.
+type fanoutRun struct {
+	Task    fanoutTask
+	Session string
+	Target  string
+	Status  tmux.Status
+	Sent    bool
+	Err     error
+}
+
+func runFanout(cmd *cobra.Command, args []string) error {
+	content, err := os.ReadFile(fanoutTaskFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", fanoutTaskFile, err)
+	}
+	tasks, err := parseTaskFile(string(content), fanoutPrefix)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", fanoutTaskFile, err)
+	}
+	agent, err := agentconfig.ParseAgentName(fanoutAgent)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	tmuxClient := tmux.NewClient()
+	runs := make([]*fanoutRun, 0, len(tasks))
+	for _, task := range tasks {
+		run := &fanoutRun{Task: task}
+		runs = append(runs, run)
+		wf, err := createWorkflow(tmuxClient, workflowSpec{Branch: task.Branch, Agent: agent, Out: out})
+		if err != nil {
+			run.Err = err
+			continue
+		}
+		run.Session = wf.Session
+		run.Target, run.Err = launchAgentWindow(tmuxClient, wf.Session, wf.WorktreeDir, agent, agentWindowNaming{})
+		if run.Err == nil && cmd.Flags().Changed("split") {
+			run.Err = splitAgentWindow(tmuxClient, wf.Session, run.Target, wf.WorktreeDir, fanoutSplit)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "Waiting for %d agent(s) to be ready...\n", len(runs))
+	seedFanoutAgents(tmuxClient, newStatusPoller(runReadyTimeout), runs)
+	if fanoutWait {
+		settleFanoutAgents(tmuxClient, newStatusPoller(fanoutTimeout), runs)
+	}
+	writeFanoutSummary(out, runs)
+
+	failed := 0
+	for _, run := range runs {
+		if run.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d task(s) failed", failed, len(runs))
+	}
+	return nil
+}
+
+type fanoutTmuxClient interface {
+	DetectAgentInfo(target string) tmux.AgentInfo
+	SendText(target, text string) error
+}
+
+// seedFanoutAgents sends each run its task as soon as its agent is ready,
+// until all are seeded or the poller times out.
+func seedFanoutAgents(client fanoutTmuxClient, poller statusPoller, runs []*fanoutRun) {
+	detect := func() (int, error) {
+		pending := 0
+		for _, run := range runs {
+			if run.Err != nil || run.Sent {
+				continue
+			}
+			info := client.DetectAgentInfo(run.Target)
+			run.Status = info.Status
+			if !agentReady(info) {
+				pending++
+				continue
+			}
+			if err := client.SendText(run.Target, run.Task.Prompt); err != nil {
+				run.Err = err
+				continue
+			}
+			run.Sent = true
+		}
+		return pending, nil
+	}
+	if _, err := pollUntil(poller, detect, func(pending int) bool { return pending == 0 }); err != nil {
+		for _, run := range runs {
+			if run.Err == nil && !run.Sent {
+				run.Err = fmt.Errorf("agent did not become ready: %w", err)
+			}
+		}
+	}
+}
+
+// settleFanoutAgents polls seeded runs until every agent has settled (see
+// agentSettled) or the poller times out, recording each final status.
+func settleFanoutAgents(client fanoutTmuxClient, poller statusPoller, runs []*fanoutRun) {
+	settled := make(map[*fanoutRun]func(tmux.AgentInfo) bool)
+	done := make(map[*fanoutRun]bool)
+	for _, run := range runs {
+		if run.Err == nil {
+			settled[run] = agentSettled(poller.Now)
+		}
+	}
+	detect := func() (int, error) {
+		pending := 0
+		for run, isSettled := range settled {
+			if done[run] {
+				continue
+			}
+			info := client.DetectAgentInfo(run.Target)
+			run.Status = info.Status
+			if isSettled(info) {
+				done[run] = true
+				continue
+			}
+			pending++
+		}
+		return pending, nil
+	}
+	if _, err := pollUntil(poller, detect, func(pending int) bool { return pending == 0 }); err != nil {
+		for run := range settled {
+			if !done[run] {
+				run.Err = fmt.Errorf("agent did not finish (last status %s): %w", run.Status, err)
+			}
+		}
+	}
+}
+
+func writeFanoutSummary(w io.Writer, runs []*fanoutRun) {
+	_, _ = fmt.Fprintln(w, "Fan-out summary:")
+	var sessions []string
+	for _, run := range runs {
+		if run.Err != nil {
+			_, _ = fmt.Fprintf(w, "  %-30s FAILED: %v\n", run.Task.Branch, run.Err)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "  %-30s %-30s %s\n", run.Task.Branch, run.Session, run.Status)
+		sessions = append(sessions, run.Session)
+	}
+	if len(sessions) > 1 {
+		_, _ = fmt.Fprintf(w, "Compare results with: cb compare %s\n", strings.Join(sessions, " "))
+	}
+}
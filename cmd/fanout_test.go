@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestParseTaskFile(t *testing.T) {
+	content := `# Sprint tasks
+
+Intro text is ignored.
+
+## Fix login redirect
+The login form redirects to /
+instead of the original page.
+
+## Add auth tests
+`
+	got, err := parseTaskFile(content, "try1/")
+	if err != nil {
+		t.Fatalf("parseTaskFile() error = %v", err)
+	}
+	want := []fanoutTask{
+		{Branch: "try1/fix-login-redirect", Prompt: "Fix login redirect The login form redirects to / instead of the original page."},
+		{Branch: "try1/add-auth-tests", Prompt: "Add auth tests"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseTaskFile() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTaskFile_Errors(t *testing.T) {
+	tests := map[string]string{
+		"no tasks":  "# Title\njust text\n",
+		"duplicate": "## Fix bug\n## fix  bug\n",
+		"unusable":  "## !!!\n",
+	}
+	for name, content := range tests {
+		if _, err := parseTaskFile(content, ""); err == nil {
+			t.Errorf("%s: parseTaskFile() should fail", name)
+		}
+	}
+}
+
+type fakeFanoutTmuxClient struct {
+	statuses map[string]tmux.Status
+	sent     []string
+}
+
+func (f *fakeFanoutTmuxClient) DetectAgentInfo(target string) tmux.AgentInfo {
+	status, ok := f.statuses[target]
+	if !ok {
+		return tmux.AgentInfo{Type: tmux.AgentNone, Status: tmux.StatusDone}
+	}
+	return tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: status}
+}
+
+func (f *fakeFanoutTmuxClient) SendText(target, text string) error {
+	f.sent = append(f.sent, target+" "+text)
+	return nil
+}
+
+func TestSeedFanoutAgents(t *testing.T) {
+	client := &fakeFanoutTmuxClient{statuses: map[string]tmux.Status{"@1": tmux.StatusWaiting}}
+	runs := []*fanoutRun{
+		{Task: fanoutTask{Branch: "a", Prompt: "do a"}, Target: "@1"},
+		{Task: fanoutTask{Branch: "b", Prompt: "do b"}, Target: "@2"}, // agent never starts
+		{Task: fanoutTask{Branch: "c"}, Err: errors.New("worktree exists")},
+	}
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	seedFanoutAgents(client, statusPoller{Interval: time.Second, Timeout: 5 * time.Second, Now: clock.Now, Sleep: clock.Sleep}, runs)
+
+	if !reflect.DeepEqual(client.sent, []string{"@1 do a"}) {
+		t.Fatalf("sent = %v, want only the ready agent", client.sent)
+	}
+	if !runs[0].Sent || runs[0].Err != nil {
+		t.Fatalf("run a = %+v, want sent", runs[0])
+	}
+	if runs[1].Err == nil || !errors.Is(runs[1].Err, errPollTimeout) {
+		t.Fatalf("run b error = %v, want timeout", runs[1].Err)
+	}
+}
+
+func TestWriteFanoutSummary(t *testing.T) {
+	runs := []*fanoutRun{
+		{Task: fanoutTask{Branch: "a"}, Session: "cb_a", Status: tmux.StatusWorking},
+		{Task: fanoutTask{Branch: "b"}, Err: errors.New("boom")},
+	}
+
+	var buf bytes.Buffer
+	writeFanoutSummary(&buf, runs)
+	out := buf.String()
+	if !strings.Contains(out, "cb_a") || !strings.Contains(out, "WORKING") || !strings.Contains(out, "FAILED: boom") {
+		t.Fatalf("summary = %q", out)
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/ronsanzone/clawd-bay/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// errNothingWaiting is returned by cb focus when no agent is WAITING.
+var errNothingWaiting = errors.New("no agents are waiting")
+
+var focusCmd = &cobra.Command{
+	Use:   "focus",
+	Short: "Jump to the most urgent WAITING agent",
+	Long: `Finds the WAITING agent window that most needs attention and switches the
+tmux client to it (or attaches, outside tmux). Agents asking for permission
+come first, then questions, then plain input prompts; ties go to the session
+and window that sort first. Sessions matched by ignore_sessions are skipped.
+
+Bind it to a key for one-keystroke triage:
+
+  bind-key N run-shell "cb focus"
+
+Exits with status 1 when no agent is waiting.
+
+Example:
+  cb focus`,
+	Args: cobra.NoArgs,
+	RunE: runFocus,
+}
+
+func init() {
+	rootCmd.AddCommand(focusCmd)
+}
+
+func runFocus(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	rows, err := tmuxClient.ListSessionWindowInfo()
+	if err != nil {
+		return fmt.Errorf("failed to list agent windows: %w", err)
+	}
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+
+	row, ok := focusTarget(rows, cfg.IgnoresSession)
+	if !ok {
+		return errNothingWaiting
+	}
+	selection := tui.Model{
+		SelectedName:         row.SessionName,
+		SelectedWindow:       row.Window.Name,
+		SelectedWindowTarget: row.Window.Target(row.SessionName),
+	}
+	return attachDashboardSelection(tmuxClient, selection, os.Getenv("TMUX") != "")
+}
+
+// focusTarget returns the WAITING agent window with the most urgent waiting
+// reason, breaking ties by session name and window index, skipping sessions
+// for which ignored returns true.
+func focusTarget(rows []tmux.SessionWindowInfo, ignored func(string) bool) (tmux.SessionWindowInfo, bool) {
+	var best tmux.SessionWindowInfo
+	found := false
+	for _, row := range rows {
+		if !row.AgentInfo.Detected || row.AgentInfo.Status != tmux.StatusWaiting || ignored(row.SessionName) {
+			continue
+		}
+		if !found || focusBefore(row, best) {
+			best, found = row, true
+		}
+	}
+	return best, found
+}
+
+func focusBefore(a, b tmux.SessionWindowInfo) bool {
+	if ua, ub := a.AgentInfo.WaitingReason.Urgency(), b.AgentInfo.WaitingReason.Urgency(); ua != ub {
+		return ua > ub
+	}
+	if a.SessionName != b.SessionName {
+		return a.SessionName < b.SessionName
+	}
+	return a.Window.Index < b.Window.Index
+}
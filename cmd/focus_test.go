@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestFocusTarget(t *testing.T) {
+	waiting := func(session string, index int, reason tmux.WaitingReason) tmux.SessionWindowInfo {
+		return tmux.SessionWindowInfo{
+			SessionName: session,
+			Window:      tmux.Window{ID: "@" + session, Index: index},
+			AgentInfo:   tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusWaiting, WaitingReason: reason},
+		}
+	}
+	working := tmux.SessionWindowInfo{
+		SessionName: "cb_a",
+		AgentInfo:   tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusWorking},
+	}
+	none := func(string) bool { return false }
+
+	tests := []struct {
+		name        string
+		rows        []tmux.SessionWindowInfo
+		ignored     func(string) bool
+		wantSession string
+		wantIndex   int
+		wantOK      bool
+	}{
+		{
+			name:        "permission beats input",
+			rows:        []tmux.SessionWindowInfo{working, waiting("cb_a", 1, tmux.WaitingInput), waiting("cb_b", 2, tmux.WaitingPermission)},
+			ignored:     none,
+			wantSession: "cb_b",
+			wantIndex:   2,
+			wantOK:      true,
+		},
+		{
+			name:        "ties go to the first session and window",
+			rows:        []tmux.SessionWindowInfo{waiting("cb_b", 0, tmux.WaitingQuestion), waiting("cb_a", 3, tmux.WaitingQuestion), waiting("cb_a", 1, tmux.WaitingQuestion)},
+			ignored:     none,
+			wantSession: "cb_a",
+			wantIndex:   1,
+			wantOK:      true,
+		},
+		{
+			name:        "ignored sessions are skipped",
+			rows:        []tmux.SessionWindowInfo{waiting("scratch", 0, tmux.WaitingPermission), waiting("cb_a", 1, tmux.WaitingInput)},
+			ignored:     func(name string) bool { return name == "scratch" },
+			wantSession: "cb_a",
+			wantIndex:   1,
+			wantOK:      true,
+		},
+		{
+			name:    "nothing waiting",
+			rows:    []tmux.SessionWindowInfo{working},
+			ignored: none,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := focusTarget(tt.rows, tt.ignored)
+			if ok != tt.wantOK || got.SessionName != tt.wantSession || got.Window.Index != tt.wantIndex {
+				t.Fatalf("focusTarget() = %s:%d, %v; want %s:%d, %v", got.SessionName, got.Window.Index, ok, tt.wantSession, tt.wantIndex, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// rowTemplateFuncs are available to --format templates in addition to the
+// text/template builtins.
+var rowTemplateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// parseRowTemplate parses a --format value, a Go template executed once per
+// output row.
+func parseRowTemplate(format string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(rowTemplateFuncs).Option("missingkey=error").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// executeRowTemplate writes tmpl applied to each row, one row per line.
+func executeRowTemplate[T any](w io.Writer, tmpl *template.Template, rows []T) error {
+	for _, row := range rows {
+		if err := tmpl.Execute(w, row); err != nil {
+			return fmt.Errorf("failed to apply --format template: %w", err)
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListRow is one session as seen by cb list --format templates. Project,
+// Worktree, and WorktreePath are empty for unmanaged sessions; Branch,
+// Agent, and Created are empty (zero) unless cb recorded them when it
+// created the session.
+type ListRow struct {
+	Project      string
+	Worktree     string
+	WorktreePath string
+	Name         string
+	Branch       string
+	Agent        tmux.AgentType
+	Created      time.Time
+	Status       tmux.Status
+	Windows      int
+	Tags         []string
+	Note         string
+	Managed      bool
+}
+
+// listRows flattens the sessions of projects into template rows.
+func listRows(projects []discovery.ProjectNode) []ListRow {
+	var rows []ListRow
+	for _, project := range projects {
+		for _, wt := range project.Worktrees {
+			for _, s := range wt.Sessions {
+				rows = append(rows, ListRow{
+					Project:      project.Name,
+					Worktree:     wt.Name,
+					WorktreePath: wt.Path,
+					Name:         s.Name,
+					Branch:       s.Branch,
+					Agent:        s.Agent,
+					Created:      s.CreatedAt,
+					Status:       s.Status,
+					Windows:      len(s.Windows),
+					Tags:         s.Tags,
+					Note:         s.Note,
+					Managed:      true,
+				})
+			}
+		}
+	}
+	return rows
+}
+
+// unmanagedListRows converts unmanaged agent sessions into template rows.
+func unmanagedListRows(sessions []unmanagedSession) []ListRow {
+	rows := make([]ListRow, 0, len(sessions))
+	for _, s := range sessions {
+		rows = append(rows, ListRow{Name: s.Name, Status: s.Status, Windows: s.AgentWindows})
+	}
+	return rows
+}
+
+// ClistRow is one tmux window as seen by cb clist --format templates.
+type ClistRow struct {
+	Session  string
+	Window   string
+	Index    int
+	Repo     string
+	Project  string
+	Agent    tmux.AgentType
+	Detected bool
+	Status   tmux.Status
+	Managed  bool
+	Tags     []string
+}
+
+// clistRows converts session window info into template rows.
+func clistRows(infos []tmux.SessionWindowInfo) []ClistRow {
+	rows := make([]ClistRow, 0, len(infos))
+	for _, info := range infos {
+		rows = append(rows, ClistRow{
+			Session:  info.SessionName,
+			Window:   info.Window.Name,
+			Index:    info.Window.Index,
+			Repo:     info.RepoName,
+			Project:  info.Project,
+			Agent:    info.AgentInfo.Type,
+			Detected: info.AgentInfo.Detected,
+			Status:   info.AgentInfo.Status,
+			Managed:  info.Managed,
+			Tags:     info.Tags,
+		})
+	}
+	return rows
+}
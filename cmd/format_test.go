@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestExecuteRowTemplate_ListRows(t *testing.T) {
+	projects := []discovery.ProjectNode{{
+		Name: "repo",
+		Worktrees: []discovery.WorktreeNode{
+			{Name: "(main repo)", Path: "/src/repo", Sessions: []discovery.SessionNode{
+				{Name: "cb_main", Status: tmux.StatusWorking, Windows: []tmux.Window{{Name: "claude"}}, Tags: []string{"backend", "urgent"},
+					Branch: "main", Agent: tmux.AgentClaude, CreatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)},
+			}},
+			{Name: "feature", Path: "/src/repo/.worktrees/feature"},
+		},
+	}}
+	rows := append(listRows(projects), unmanagedListRows([]unmanagedSession{{Name: "scratch", Status: tmux.StatusIdle, AgentWindows: 2}})...)
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{name: "fields", format: "{{.Name}} {{.Status}}", want: "cb_main WORKING\nscratch IDLE\n"},
+		{name: "funcs", format: `{{.Project}}|{{join .Tags ","}}|{{lower (printf "%s" .Status)}}|{{.Windows}}`, want: "repo|backend,urgent|working|1\n||idle|2\n"},
+		{name: "recorded metadata", format: `{{.Branch}}|{{.Agent}}|{{if not .Created.IsZero}}{{.Created.Format "2006-01-02"}}{{end}}`, want: "main|claude|2025-03-01\n||\n"},
+		{name: "conditionals", format: "{{if not .Managed}}{{.Name}}{{end}}", want: "\nscratch\n"},
+		{name: "parse error", format: "{{.Name", wantErr: "invalid --format template"},
+		{name: "unknown field", format: "{{.Nope}}", wantErr: "failed to apply --format template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tmpl, err := parseRowTemplate(tt.format)
+			if err == nil {
+				err = executeRowTemplate(&buf, tmpl, rows)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Fatalf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClistRows(t *testing.T) {
+	rows := clistRows([]tmux.SessionWindowInfo{{
+		SessionName: "cb_main",
+		RepoName:    "repo-feat",
+		Project:     "repo",
+		Window:      tmux.Window{Index: 2, Name: "claude"},
+		AgentInfo:   tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusWaiting},
+		Managed:     true,
+	}})
+
+	tmpl, err := parseRowTemplate("{{.Session}}:{{.Index}} {{.Window}} {{.Agent}} {{.Status}} {{.Project}}/{{.Repo}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := executeRowTemplate(&buf, tmpl, rows); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "cb_main:2 claude claude WAITING repo/repo-feat\n"; got != want {
+		t.Fatalf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var gcDryRun bool
+var gcYes bool
+var gcOlderThan time.Duration
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Archive workflows that are DONE and have been inactive too long",
+	Long: `Archives abandoned workflows by the policy in the [gc] table of config.toml:
+every linked worktree whose sessions are all DONE and whose windows have had
+no output for archive_after (or --older-than) is archived like cb archive
+does: its sessions are killed and the worktree is removed, keeping the branch.
+
+The workflows are listed before anything is archived, and confirmation is
+asked; --yes skips it, to run cb gc from cron or launchd. No other notice is
+given, so to be warned first, run cb gc --dry-run on a schedule before the
+unattended cb gc --yes. Worktrees with uncommitted changes or unpushed
+commits are never archived.
+
+Example:
+  cb gc                      # Archive by the [gc] policy, after confirming
+  cb gc --dry-run            # Only list what would be archived
+  cb gc --older-than 72h -y  # Override the policy, skip confirmation`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "list the workflows that would be archived without archiving them")
+	gcCmd.Flags().BoolVarP(&gcYes, "yes", "y", false, "skip confirmation prompt")
+	gcCmd.Flags().DurationVar(&gcOlderThan, "older-than", 0, "inactivity after which DONE workflows are archived (default: [gc] archive_after)")
+	rootCmd.AddCommand(gcCmd)
+}
+
+type gcTmuxClient interface {
+	SessionActivity(name string) (time.Time, error)
+}
+
+// gcCandidate is a linked worktree whose sessions are all DONE and inactive.
+type gcCandidate struct {
+	ProjectName  string
+	MainRepoPath string
+	WorktreeName string
+	WorktreePath string
+	Branch       string
+	Sessions     []string
+	// Inactive is how long ago the most recently active session had
+	// activity.
+	Inactive time.Duration
+}
+
+// findGCCandidates returns the linked worktrees in result with at least one
+// session, all DONE and inactive for olderThan as of now. Worktrees that
+// cannot be checked or hold uncommitted or unpushed work are described in
+// skipped instead.
+func findGCCandidates(tmuxClient gcTmuxClient, gitClient archiveGitClient, result discovery.Result, olderThan time.Duration, now time.Time) (candidates []gcCandidate, skipped []string) {
+	for _, project := range result.Projects {
+		if project.InvalidError != "" {
+			continue
+		}
+		for _, wt := range project.Worktrees {
+			if wt.IsMainRepo || wt.IsMissing || len(wt.Sessions) == 0 {
+				continue
+			}
+			inactive, ok, err := worktreeInactivity(tmuxClient, wt, now)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s/%s: %v", project.Name, wt.Name, err))
+				continue
+			}
+			if !ok || inactive < olderThan {
+				continue
+			}
+
+			report, err := checkArchiveLoss(gitClient, wt.Path)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s/%s: %v", project.Name, wt.Name, err))
+				continue
+			}
+			if !report.empty() {
+				skipped = append(skipped, fmt.Sprintf("%s/%s: %d uncommitted change(s), %d unpushed commit(s)",
+					project.Name, wt.Name, len(report.Uncommitted), len(report.Unpushed)))
+				continue
+			}
+
+			sessions := make([]string, 0, len(wt.Sessions))
+			for _, s := range wt.Sessions {
+				sessions = append(sessions, s.Name)
+			}
+			candidates = append(candidates, gcCandidate{
+				ProjectName:  project.Name,
+				MainRepoPath: project.Path,
+				WorktreeName: wt.Name,
+				WorktreePath: wt.Path,
+				Branch:       wt.Branch,
+				Sessions:     sessions,
+				Inactive:     inactive,
+			})
+		}
+	}
+	return candidates, skipped
+}
+
+// worktreeInactivity returns how long the windows of wt's sessions have all
+// been without output, and false when any session is not DONE.
+func worktreeInactivity(tmuxClient gcTmuxClient, wt discovery.WorktreeNode, now time.Time) (time.Duration, bool, error) {
+	var latest time.Time
+	for _, s := range wt.Sessions {
+		if s.Status != tmux.StatusDone {
+			return 0, false, nil
+		}
+		activity, err := tmuxClient.SessionActivity(s.Name)
+		if err != nil {
+			return 0, false, err
+		}
+		if activity.After(latest) {
+			latest = activity
+		}
+	}
+	return now.Sub(latest), true, nil
+}
+
+func writeGCCandidates(w io.Writer, candidates []gcCandidate) {
+	currentProject := ""
+	for _, c := range candidates {
+		if c.MainRepoPath != currentProject {
+			currentProject = c.MainRepoPath
+			_, _ = fmt.Fprintln(w, c.ProjectName)
+		}
+		_, _ = fmt.Fprintf(w, "  %-30s inactive %-8s [%s]\n",
+			discovery.WorktreeLabel(c.WorktreeName, c.Branch), formatInactive(c.Inactive), strings.Join(c.Sessions, ", "))
+	}
+}
+
+// formatInactive rounds d to hours, or minutes below an hour.
+func formatInactive(d time.Duration) string {
+	if d < time.Hour {
+		return d.Round(time.Minute).String()
+	}
+	return fmt.Sprintf("%dh", int(d/time.Hour))
+}
+
+// gcPolicy returns the inactivity after which workflows are archived:
+// --older-than when given, else the [gc] table's archive_after.
+func gcPolicy(cmd *cobra.Command) (time.Duration, error) {
+	if cmd.Flags().Changed("older-than") {
+		if gcOlderThan <= 0 {
+			return 0, fmt.Errorf("--older-than must be positive")
+		}
+		return gcOlderThan, nil
+	}
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		return 0, err
+	}
+	d, ok := cfg.GC.ArchiveAfterDuration()
+	if !ok {
+		return 0, fmt.Errorf("no gc policy: set archive_after in the [gc] table of config.toml, or pass --older-than")
+	}
+	return d, nil
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	olderThan, err := gcPolicy(cmd)
+	if err != nil {
+		return err
+	}
+
+	tmuxClient := tmux.NewClient()
+	result, err := discovery.NewService(tmuxClient).Discover()
+	if err != nil {
+		return err
+	}
+
+	gitClient := git.NewClient()
+	out := cmd.OutOrStdout()
+	errOut := cmd.ErrOrStderr()
+	candidates, skipped := findGCCandidates(tmuxClient, gitClient, result, olderThan, time.Now())
+	for _, s := range skipped {
+		_, _ = fmt.Fprintf(errOut, "Skipping %s\n", s)
+	}
+	if len(candidates) == 0 {
+		_, _ = fmt.Fprintf(out, "No DONE workflows inactive for %s.\n", olderThan)
+		return nil
+	}
+
+	writeGCCandidates(out, candidates)
+	if gcDryRun {
+		return nil
+	}
+	prompt := fmt.Sprintf("This will kill their sessions and remove %d worktree(s), keeping the branches. Continue? [y/N] ", len(candidates))
+	if !gcYes && !confirm(os.Stdin, prompt) {
+		_, _ = fmt.Fprintln(out, "Cancelled")
+		return nil
+	}
+
+	failures := 0
+	for _, c := range candidates {
+		if err := removeWorkflow(tmuxClient, gitClient, c.MainRepoPath, c.WorktreePath, c.Sessions, errOut); err != nil {
+			_, _ = fmt.Fprintf(errOut, "Error: %v\n", err)
+			failures++
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "Archived %s\n", c.WorktreeName)
+	}
+	if failures > 0 {
+		return fmt.Errorf("gc failed to archive %d worktree(s)", failures)
+	}
+	return nil
+}
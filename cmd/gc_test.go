@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeGCTmuxClient map[string]time.Time
+
+func (f fakeGCTmuxClient) SessionActivity(name string) (time.Time, error) {
+	at, ok := f[name]
+	if !ok {
+		return time.Time{}, errors.New("can't find session")
+	}
+	return at, nil
+}
+
+type fakeGCGitClient struct {
+	statuses map[string][]string
+	unpushed map[string][]string
+}
+
+func (f fakeGCGitClient) StatusPorcelain(dir string) ([]string, error) {
+	return f.statuses[dir], nil
+}
+
+func (f fakeGCGitClient) UnpushedCommits(dir string) ([]string, error) {
+	return f.unpushed[dir], nil
+}
+
+func TestFindGCCandidates(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	done := func(name string) discovery.SessionNode {
+		return discovery.SessionNode{Name: name, Status: tmux.StatusDone}
+	}
+	worktree := func(name string, sessions ...discovery.SessionNode) discovery.WorktreeNode {
+		return discovery.WorktreeNode{Name: ".worktrees/" + name, Path: "/code/repo/.worktrees/" + name, Branch: name, Sessions: sessions}
+	}
+	result := discovery.Result{Projects: []discovery.ProjectNode{
+		{
+			Name: "repo",
+			Path: "/code/repo",
+			Worktrees: []discovery.WorktreeNode{
+				{Name: "repo", Path: "/code/repo", IsMainRepo: true, Sessions: []discovery.SessionNode{done("cb_main")}},
+				worktree("old", done("cb_old"), done("cb_old-2")),
+				worktree("recent", done("cb_recent")),
+				worktree("working", done("cb_working-a"), discovery.SessionNode{Name: "cb_working-b", Status: tmux.StatusWorking}),
+				worktree("dirty", done("cb_dirty")),
+				worktree("unpushed", done("cb_unpushed")),
+				worktree("gone", done("cb_gone")),
+				worktree("empty"),
+				{Name: "(missing worktree)", IsMissing: true, Sessions: []discovery.SessionNode{done("cb_orphan")}},
+			},
+		},
+		{Name: "broken", Path: "/code/broken", InvalidError: "not a git repository"},
+	}}
+	stale := now.Add(-72 * time.Hour)
+	tmuxClient := fakeGCTmuxClient{
+		"cb_main":      stale,
+		"cb_old":       stale,
+		"cb_old-2":     now.Add(-50 * time.Hour),
+		"cb_recent":    now.Add(-time.Hour),
+		"cb_working-a": stale,
+		"cb_working-b": stale,
+		"cb_dirty":     stale,
+		"cb_unpushed":  stale,
+		"cb_orphan":    stale,
+	}
+	gitClient := fakeGCGitClient{
+		statuses: map[string][]string{"/code/repo/.worktrees/dirty": {" M a.go"}},
+		unpushed: map[string][]string{"/code/repo/.worktrees/unpushed": {"abc123 wip"}},
+	}
+
+	candidates, skipped := findGCCandidates(tmuxClient, gitClient, result, 48*time.Hour, now)
+	want := []gcCandidate{{
+		ProjectName:  "repo",
+		MainRepoPath: "/code/repo",
+		WorktreeName: ".worktrees/old",
+		WorktreePath: "/code/repo/.worktrees/old",
+		Branch:       "old",
+		Sessions:     []string{"cb_old", "cb_old-2"},
+		Inactive:     50 * time.Hour,
+	}}
+	if !reflect.DeepEqual(candidates, want) {
+		t.Fatalf("candidates = %+v, want %+v", candidates, want)
+	}
+	wantSkipped := []string{
+		"repo/.worktrees/dirty: 1 uncommitted change(s), 0 unpushed commit(s)",
+		"repo/.worktrees/unpushed: 0 uncommitted change(s), 1 unpushed commit(s)",
+		"repo/.worktrees/gone: can't find session",
+	}
+	if !reflect.DeepEqual(skipped, wantSkipped) {
+		t.Fatalf("skipped = %q, want %q", skipped, wantSkipped)
+	}
+}
+
+func TestWriteGCCandidates(t *testing.T) {
+	var buf bytes.Buffer
+	writeGCCandidates(&buf, []gcCandidate{
+		{ProjectName: "repo", MainRepoPath: "/code/repo", WorktreeName: ".worktrees/repo-old", Branch: "old", Sessions: []string{"cb_old"}, Inactive: 50*time.Hour + 20*time.Minute},
+		{ProjectName: "repo", MainRepoPath: "/code/repo", WorktreeName: ".worktrees/repo-new", Branch: "new", Sessions: []string{"cb_new", "cb_new-2"}, Inactive: 30 * time.Minute},
+	})
+	got := buf.String()
+	want := "repo\n" +
+		"  .worktrees/repo-old            inactive 50h      [cb_old]\n" +
+		"  .worktrees/repo-new            inactive 30m0s    [cb_new, cb_new-2]\n"
+	if got != want {
+		t.Fatalf("writeGCCandidates() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -1,48 +1,55 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"text/template"
 
+	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/discovery"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
 type listAgentDetector interface {
-	DetectAgentInfo(session, window string) tmux.AgentInfo
+	DetectAgentInfo(target string) tmux.AgentInfo
 }
 
-func rollupStatuses(statuses []tmux.Status) tmux.Status {
-	hasWaiting := false
-	hasIdle := false
-	for _, s := range statuses {
-		switch s {
-		case tmux.StatusWorking:
-			return tmux.StatusWorking
-		case tmux.StatusWaiting:
-			hasWaiting = true
-		case tmux.StatusIdle:
-			hasIdle = true
-		}
-	}
-	if hasWaiting {
-		return tmux.StatusWaiting
-	}
-	if hasIdle {
-		return tmux.StatusIdle
-	}
-	return tmux.StatusDone
+func sessionStatusFromWindows(detector listAgentDetector, session string, wins []tmux.Window) tmux.Status {
+	return sessionInfoFromWindows(detector, session, wins).Status
 }
 
-func sessionStatusFromWindows(detector listAgentDetector, session string, wins []tmux.Window) tmux.Status {
+// sessionInfoFromWindows rolls up the agents in wins: the rolled-up status,
+// the most urgent waiting reason among WAITING agents, and the first known
+// limit reset time. Type is the agent type of the first detected window.
+func sessionInfoFromWindows(detector listAgentDetector, session string, wins []tmux.Window) tmux.AgentInfo {
+	rolled := tmux.AgentInfo{Type: tmux.AgentNone}
 	var statuses []tmux.Status
 	for _, w := range wins {
-		info := detector.DetectAgentInfo(session, w.Name)
-		if info.Detected {
-			statuses = append(statuses, info.Status)
+		info := detector.DetectAgentInfo(w.Target(session))
+		if !info.Detected {
+			continue
+		}
+		statuses = append(statuses, info.Status)
+		if !rolled.Detected {
+			rolled.Type, rolled.Detected = info.Type, true
+		}
+		if info.WaitingReason.Urgency() > rolled.WaitingReason.Urgency() {
+			rolled.WaitingReason = info.WaitingReason
+		}
+		if rolled.LimitReset == "" {
+			rolled.LimitReset = info.LimitReset
 		}
 	}
-	return rollupStatuses(statuses)
+	rolled.Status = tmux.RollupStatus(statuses)
+	if rolled.Status != tmux.StatusWaiting {
+		rolled.WaitingReason = ""
+	}
+	if rolled.Status != tmux.StatusLimited {
+		rolled.LimitReset = ""
+	}
+	return rolled
 }
 
 func formatListSessionLine(s discovery.SessionNode) string {
@@ -51,19 +58,156 @@ func formatListSessionLine(s discovery.SessionNode) string {
 	if windowCount == 1 {
 		windowWord = "window"
 	}
-	return fmt.Sprintf("    %-30s %d %s  (%s)", s.Name, windowCount, windowWord, s.Status)
+	status := string(s.Status)
+	if s.Status == tmux.StatusLimited && s.LimitReset != "" {
+		status += ", resets " + s.LimitReset
+	}
+	line := fmt.Sprintf("    %-30s %d %s  (%s)", s.Name, windowCount, windowWord, status)
+	if len(s.Tags) > 0 {
+		line += "  " + formatTagChips(s.Tags)
+	}
+	return line
+}
+
+// sessionHasTags reports whether s carries every tag in tags.
+func sessionHasTags(s discovery.SessionNode, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(s.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterProjectsByTags keeps only the sessions carrying every tag in tags,
+// dropping worktrees and projects left without sessions. No tags keeps
+// everything.
+func filterProjectsByTags(projects []discovery.ProjectNode, tags []string) []discovery.ProjectNode {
+	if len(tags) == 0 {
+		return projects
+	}
+	var kept []discovery.ProjectNode
+	for _, project := range projects {
+		var worktrees []discovery.WorktreeNode
+		for _, wt := range project.Worktrees {
+			var sessions []discovery.SessionNode
+			for _, s := range wt.Sessions {
+				if sessionHasTags(s, tags) {
+					sessions = append(sessions, s)
+				}
+			}
+			if len(sessions) > 0 {
+				wt.Sessions = sessions
+				worktrees = append(worktrees, wt)
+			}
+		}
+		if len(worktrees) > 0 {
+			project.Worktrees = worktrees
+			kept = append(kept, project)
+		}
+	}
+	return kept
+}
+
+// unmanagedSession is a non-cb_ tmux session with at least one detected agent.
+type unmanagedSession struct {
+	Name         string
+	AgentWindows int
+	Status       tmux.Status
+}
+
+// unmanagedAgentSessions groups detected agent windows of non-managed sessions
+// by session, preserving first-seen order. Sessions for which ignored returns
+// true are skipped.
+func unmanagedAgentSessions(rows []tmux.SessionWindowInfo, ignored func(string) bool) []unmanagedSession {
+	var sessions []unmanagedSession
+	statuses := map[string][]tmux.Status{}
+	for _, row := range rows {
+		if row.Managed || !row.AgentInfo.Detected || ignored(row.SessionName) {
+			continue
+		}
+		if _, ok := statuses[row.SessionName]; !ok {
+			sessions = append(sessions, unmanagedSession{Name: row.SessionName})
+		}
+		statuses[row.SessionName] = append(statuses[row.SessionName], row.AgentInfo.Status)
+	}
+	for i := range sessions {
+		sessions[i].AgentWindows = len(statuses[sessions[i].Name])
+		sessions[i].Status = tmux.RollupStatus(statuses[sessions[i].Name])
+	}
+	return sessions
 }
 
+func formatUnmanagedSessionLine(s unmanagedSession) string {
+	windowWord := "agent windows"
+	if s.AgentWindows == 1 {
+		windowWord = "agent window"
+	}
+	return fmt.Sprintf("    %-30s %d %s  (%s)  [unmanaged]", s.Name, s.AgentWindows, windowWord, s.Status)
+}
+
+var (
+	listAll    bool
+	listTags   []string
+	listFormat string
+)
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all active ClawdBay sessions",
+	Long: `Lists configured projects with their worktrees and cb_ sessions.
+
+With --all, tmux sessions outside ClawdBay that run a detected coding agent are
+listed too, marked [unmanaged] (the same windows the dashboard's agents mode shows).
+
+With --tag, only sessions carrying the tag are listed (repeat --tag to require
+several; see cb tag).
+
+With --format, each session is printed on its own line through a Go template
+instead of the tree. Fields: .Project .Worktree .WorktreePath .Name .Branch
+.Agent .Created .Status .Windows .Tags .Note .Managed; functions: join, lower,
+upper. .Branch, .Agent, and .Created are recorded by cb start, cb run, and
+cb fanout when they create the session. The shell passes \t through
+literally, so write a tab as {{"\t"}}.
+
+Example:
+  cb list --format '{{.Name}} {{.Status}}'
+  cb list --all --format '{{.Name}}{{"\t"}}{{join .Tags ","}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		var rowTmpl *template.Template
+		if listFormat != "" {
+			var err error
+			if rowTmpl, err = parseRowTemplate(listFormat); err != nil {
+				return err
+			}
+		}
+		tags, err := normalizeTags(listTags)
+		if err != nil {
+			return err
+		}
+
 		tmuxClient := tmux.NewClient()
-		result, err := discovery.NewService(tmuxClient).Discover()
+		result, err := newDaemonDiscoverer(context.Background(), tmuxClient).Discover()
 		if err != nil {
 			return err
 		}
 
+		if rowTmpl != nil {
+			rows := listRows(filterProjectsByTags(result.Projects, tags))
+			if listAll {
+				unmanaged, err := loadUnmanagedSessions(tmuxClient)
+				if err != nil {
+					return err
+				}
+				rows = append(rows, unmanagedListRows(unmanaged)...)
+			}
+			return executeRowTemplate(cmd.OutOrStdout(), rowTmpl, rows)
+		}
+
+		if listAll {
+			defer printUnmanagedSessions(tmuxClient)
+		}
+
 		if result.ConfigMissing {
 			fmt.Println("No project config found. Add one with: cb project add <path>")
 			return nil
@@ -74,14 +218,22 @@ var listCmd = &cobra.Command{
 			return nil
 		}
 
-		for _, project := range result.Projects {
+		projects := filterProjectsByTags(result.Projects, tags)
+		if len(tags) > 0 && len(projects) == 0 {
+			fmt.Printf("No sessions tagged %s\n", formatTagChips(tags))
+		}
+
+		for _, project := range projects {
 			fmt.Println(project.Name)
 			if project.InvalidError != "" {
 				fmt.Printf("  [INVALID] %s\n", project.InvalidError)
 			}
+			for _, name := range project.StaleWorktrees {
+				fmt.Printf("  [STALE] %s was deleted outside cb; run cb clean to prune it\n", name)
+			}
 
 			for _, wt := range project.Worktrees {
-				fmt.Printf("  %s\n", wt.Name)
+				fmt.Printf("  %s\n", discovery.WorktreeLabel(wt.Name, wt.Branch))
 				if len(wt.Sessions) == 0 {
 					fmt.Println("    (no active session)")
 					continue
@@ -96,6 +248,38 @@ var listCmd = &cobra.Command{
 	},
 }
 
+// loadUnmanagedSessions returns the non-ignored unmanaged sessions running a
+// detected agent.
+func loadUnmanagedSessions(tmuxClient *tmux.Client) ([]unmanagedSession, error) {
+	rows, err := tmuxClient.ListSessionWindowInfo()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+	return unmanagedAgentSessions(rows, cfg.IgnoresSession), nil
+}
+
+func printUnmanagedSessions(tmuxClient *tmux.Client) {
+	sessions, err := loadUnmanagedSessions(tmuxClient)
+	if err != nil {
+		fmt.Printf("Unmanaged agent sessions unavailable: %v\n", err)
+		return
+	}
+	if len(sessions) == 0 {
+		return
+	}
+	fmt.Println("(unmanaged)")
+	for _, s := range sessions {
+		fmt.Println(formatUnmanagedSessionLine(s))
+	}
+}
+
 func init() {
+	listCmd.Flags().BoolVarP(&listAll, "all", "a", false, "also list non-ClawdBay sessions running a detected agent")
+	listCmd.Flags().StringArrayVar(&listTags, "tag", nil, "only list sessions with this tag (repeatable)")
+	listCmd.Flags().StringVar(&listFormat, "format", "", "print each session through a Go template, e.g. '{{.Name}} {{.Status}}'")
 	rootCmd.AddCommand(listCmd)
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"text/template"
 
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 	"github.com/spf13/cobra"
@@ -29,15 +30,36 @@ func (l listClaudesOut) toString() string {
 	return fmt.Sprintf("%s %s (DETECTED AGENT: NONE)\n", l.windowName, repoName)
 }
 
+var clistFormat string
+
 var listClaudesCmd = &cobra.Command{
 	Use:   "clist",
 	Short: "List tmux windows and detected coding agents",
+	Long: `Lists every tmux window with its repo and detected coding agent.
+
+With --format, each window is printed on its own line through a Go template.
+Fields: .Session .Window .Index .Repo .Project .Agent .Detected .Status
+.Managed .Tags; functions: join, lower, upper.
+
+Example:
+  cb clist --format '{{.Session}}:{{.Index}} {{.Agent}} {{.Status}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		var rowTmpl *template.Template
+		if clistFormat != "" {
+			var err error
+			if rowTmpl, err = parseRowTemplate(clistFormat); err != nil {
+				return err
+			}
+		}
+
 		tmuxClient := tmux.NewClient()
 		rows, err := tmuxClient.ListSessionWindowInfo()
 		if err != nil {
 			return err
 		}
+		if rowTmpl != nil {
+			return executeRowTemplate(cmd.OutOrStdout(), rowTmpl, clistRows(rows))
+		}
 
 		if len(rows) == 0 {
 			fmt.Println("No active sessions. Start one with: cb start <branch-name>")
@@ -64,5 +86,6 @@ var listClaudesCmd = &cobra.Command{
 }
 
 func init() {
+	listClaudesCmd.Flags().StringVar(&clistFormat, "format", "", "print each window through a Go template, e.g. '{{.Session}} {{.Status}}'")
 	rootCmd.AddCommand(listClaudesCmd)
 }
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/discovery"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 )
@@ -12,8 +13,8 @@ type fakeListAgentDetector struct {
 	infoByWindow map[string]tmux.AgentInfo
 }
 
-func (f fakeListAgentDetector) DetectAgentInfo(session, window string) tmux.AgentInfo {
-	if info, ok := f.infoByWindow[session+":"+window]; ok {
+func (f fakeListAgentDetector) DetectAgentInfo(target string) tmux.AgentInfo {
+	if info, ok := f.infoByWindow[target]; ok {
 		return info
 	}
 	return tmux.AgentInfo{Type: tmux.AgentNone, Detected: false, Status: tmux.StatusDone}
@@ -22,16 +23,16 @@ func (f fakeListAgentDetector) DetectAgentInfo(session, window string) tmux.Agen
 func TestSessionStatusFromWindows_IgnoresNonAgents(t *testing.T) {
 	detector := fakeListAgentDetector{
 		infoByWindow: map[string]tmux.AgentInfo{
-			"cb_demo:shell":      {Type: tmux.AgentNone, Detected: false, Status: tmux.StatusDone},
-			"cb_demo:notes":      {Type: tmux.AgentNone, Detected: false, Status: tmux.StatusDone},
-			"cb_demo:random-win": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWorking},
+			"@1": {Type: tmux.AgentNone, Detected: false, Status: tmux.StatusDone},
+			"@2": {Type: tmux.AgentNone, Detected: false, Status: tmux.StatusDone},
+			"@3": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWorking},
 		},
 	}
 
 	wins := []tmux.Window{
-		{Name: "shell"},
-		{Name: "notes"},
-		{Name: "random-win"},
+		{ID: "@1", Name: "shell"},
+		{ID: "@2", Name: "notes"},
+		{ID: "@3", Name: "random-win"},
 	}
 
 	got := sessionStatusFromWindows(detector, "cb_demo", wins)
@@ -43,14 +44,14 @@ func TestSessionStatusFromWindows_IgnoresNonAgents(t *testing.T) {
 func TestSessionStatusFromWindows_MixedDetectedAgents(t *testing.T) {
 	detector := fakeListAgentDetector{
 		infoByWindow: map[string]tmux.AgentInfo{
-			"cb_demo:codex-main": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWaiting},
-			"cb_demo:open-run":   {Type: tmux.AgentOpenCode, Detected: true, Status: tmux.StatusIdle},
+			"@4": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWaiting},
+			"@5": {Type: tmux.AgentOpenCode, Detected: true, Status: tmux.StatusIdle},
 		},
 	}
 
 	wins := []tmux.Window{
-		{Name: "codex-main"},
-		{Name: "open-run"},
+		{ID: "@4", Name: "codex-main"},
+		{ID: "@5", Name: "open-run"},
 	}
 
 	got := sessionStatusFromWindows(detector, "cb_demo", wins)
@@ -62,12 +63,12 @@ func TestSessionStatusFromWindows_MixedDetectedAgents(t *testing.T) {
 func TestSessionStatusFromWindows_NoDetectedAgents(t *testing.T) {
 	detector := fakeListAgentDetector{
 		infoByWindow: map[string]tmux.AgentInfo{
-			"cb_demo:shell": {Type: tmux.AgentNone, Detected: false, Status: tmux.StatusDone},
+			"@1": {Type: tmux.AgentNone, Detected: false, Status: tmux.StatusDone},
 		},
 	}
 
 	wins := []tmux.Window{
-		{Name: "shell"},
+		{ID: "@1", Name: "shell"},
 	}
 
 	got := sessionStatusFromWindows(detector, "cb_demo", wins)
@@ -76,6 +77,23 @@ func TestSessionStatusFromWindows_NoDetectedAgents(t *testing.T) {
 	}
 }
 
+func TestSessionInfoFromWindows_MostUrgentWaitingReason(t *testing.T) {
+	detector := fakeListAgentDetector{
+		infoByWindow: map[string]tmux.AgentInfo{
+			"@1": {Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusWaiting, WaitingReason: tmux.WaitingInput},
+			"@2": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWaiting, WaitingReason: tmux.WaitingPermission},
+			"@3": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusIdle},
+		},
+	}
+
+	wins := []tmux.Window{{ID: "@1"}, {ID: "@2"}, {ID: "@3"}}
+
+	got := sessionInfoFromWindows(detector, "cb_demo", wins)
+	if got.Status != tmux.StatusWaiting || got.WaitingReason != tmux.WaitingPermission {
+		t.Fatalf("sessionInfoFromWindows() = %+v, want WAITING for permission", got)
+	}
+}
+
 func TestFormatListSessionLine(t *testing.T) {
 	t.Run("formats status and plural windows", func(t *testing.T) {
 		line := formatListSessionLine(discovery.SessionNode{
@@ -101,4 +119,98 @@ func TestFormatListSessionLine(t *testing.T) {
 			t.Fatalf("line = %q, want singular window", line)
 		}
 	})
+
+	t.Run("limited shows reset time", func(t *testing.T) {
+		line := formatListSessionLine(discovery.SessionNode{
+			Name:       "cb_demo",
+			Status:     tmux.StatusLimited,
+			LimitReset: "3pm (America/New_York)",
+			Windows:    []tmux.Window{{Name: "a"}},
+		})
+		if !strings.Contains(line, "(LIMITED, resets 3pm (America/New_York))") {
+			t.Fatalf("line = %q, want status with reset time", line)
+		}
+	})
+}
+
+func TestFilterProjectsByTags(t *testing.T) {
+	projects := []discovery.ProjectNode{
+		{Name: "api", Worktrees: []discovery.WorktreeNode{
+			{Name: "(main repo)", Sessions: []discovery.SessionNode{
+				{Name: "cb_api", Tags: []string{"backend", "urgent"}},
+				{Name: "cb_api_docs", Tags: []string{"docs"}},
+			}},
+			{Name: "feature"},
+		}},
+		{Name: "web", Worktrees: []discovery.WorktreeNode{
+			{Name: "(main repo)", Sessions: []discovery.SessionNode{{Name: "cb_web", Tags: []string{"urgent"}}}},
+		}},
+	}
+
+	names := func(projects []discovery.ProjectNode) string {
+		var got []string
+		for _, p := range projects {
+			for _, wt := range p.Worktrees {
+				for _, s := range wt.Sessions {
+					got = append(got, p.Name+"/"+wt.Name+"/"+s.Name)
+				}
+				if len(wt.Sessions) == 0 {
+					got = append(got, p.Name+"/"+wt.Name)
+				}
+			}
+		}
+		return strings.Join(got, " ")
+	}
+
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{tags: nil, want: "api/(main repo)/cb_api api/(main repo)/cb_api_docs api/feature web/(main repo)/cb_web"},
+		{tags: []string{"urgent"}, want: "api/(main repo)/cb_api web/(main repo)/cb_web"},
+		{tags: []string{"urgent", "backend"}, want: "api/(main repo)/cb_api"},
+		{tags: []string{"missing"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := names(filterProjectsByTags(projects, tt.tags)); got != tt.want {
+			t.Errorf("filterProjectsByTags(%v) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+
+	line := formatListSessionLine(projects[0].Worktrees[0].Sessions[0])
+	if !strings.HasSuffix(line, "#backend #urgent") {
+		t.Fatalf("line = %q, want tag chips", line)
+	}
+}
+
+func TestUnmanagedAgentSessions(t *testing.T) {
+	rows := []tmux.SessionWindowInfo{
+		{SessionName: "cb_feat", Managed: true, AgentInfo: tmux.AgentInfo{Detected: true, Status: tmux.StatusWorking}},
+		{SessionName: "scratch", AgentInfo: tmux.AgentInfo{Detected: true, Status: tmux.StatusIdle}},
+		{SessionName: "scratch", AgentInfo: tmux.AgentInfo{Detected: false}},
+		{SessionName: "notes", AgentInfo: tmux.AgentInfo{Detected: false}},
+		{SessionName: "scratch", AgentInfo: tmux.AgentInfo{Detected: true, Status: tmux.StatusWaiting}},
+		{SessionName: "other", AgentInfo: tmux.AgentInfo{Detected: true, Status: tmux.StatusWorking}},
+		{SessionName: "private", AgentInfo: tmux.AgentInfo{Detected: true, Status: tmux.StatusWorking}},
+	}
+
+	ignored := config.UserConfig{IgnoreSessions: []string{"priv*"}}
+	got := unmanagedAgentSessions(rows, ignored.IgnoresSession)
+	want := []unmanagedSession{
+		{Name: "scratch", AgentWindows: 2, Status: tmux.StatusWaiting},
+		{Name: "other", AgentWindows: 1, Status: tmux.StatusWorking},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("unmanagedAgentSessions() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("session[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	line := formatUnmanagedSessionLine(got[1])
+	if !strings.Contains(line, "1 agent window  (WORKING)  [unmanaged]") {
+		t.Fatalf("formatUnmanagedSessionLine() = %q", line)
+	}
 }
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var mergeFFOnly bool
+var mergeYes bool
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [session-name]",
+	Short: "Merge workflow branch into base, then archive it and delete the branch",
+	Long: `Merges the workflow's branch into the branch checked out in the main repo,
+then kills the tmux session, removes the worktree, and deletes the branch.
+
+Refuses to run when the worktree or main repo has uncommitted changes. A
+merge that conflicts is aborted, leaving the main repo as it was, and the
+conflicting paths are listed; nothing is archived.
+
+Example:
+  cb merge                 # Merge the workflow for the current directory
+  cb merge feat-auth       # Merge cb_feat-auth
+  cb merge --ff-only -y    # Only fast-forward, skip confirmation`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().BoolVar(&mergeFFOnly, "ff-only", false, "refuse to merge unless the base can be fast-forwarded")
+	mergeCmd.Flags().BoolVarP(&mergeYes, "yes", "y", false, "skip confirmation prompt")
+	rootCmd.AddCommand(mergeCmd)
+}
+
+type mergeGitClient interface {
+	CurrentBranch(dir string) (string, error)
+	StatusPorcelain(dir string) ([]string, error)
+	MainWorktree(dir string) (string, error)
+}
+
+type mergeRunner interface {
+	Merge(dir, branch string, ffOnly bool) error
+	AbortMerge(dir string) error
+	ConflictedFiles(dir string) ([]string, error)
+}
+
+// mergePlan describes a validated merge of a workflow branch into its base.
+type mergePlan struct {
+	Branch       string
+	BaseBranch   string
+	WorktreePath string
+	MainRepoPath string
+}
+
+// planMerge validates that worktreePath can be merged into the main repo's
+// checked-out branch without losing uncommitted work.
+func planMerge(gitClient mergeGitClient, worktreePath string) (mergePlan, error) {
+	if worktreePath == "" {
+		return mergePlan{}, fmt.Errorf("could not determine worktree for session")
+	}
+
+	mainRepoPath, err := gitClient.MainWorktree(worktreePath)
+	if err != nil {
+		return mergePlan{}, err
+	}
+	if samePath(mainRepoPath, worktreePath) {
+		return mergePlan{}, fmt.Errorf("%s is the main repo, not a workflow worktree", worktreePath)
+	}
+
+	branch, err := gitClient.CurrentBranch(worktreePath)
+	if err != nil {
+		return mergePlan{}, err
+	}
+	baseBranch, err := gitClient.CurrentBranch(mainRepoPath)
+	if err != nil {
+		return mergePlan{}, err
+	}
+	if branch == baseBranch {
+		return mergePlan{}, fmt.Errorf("worktree branch %s is the same as base branch", branch)
+	}
+
+	if err := requireCleanTree(gitClient, worktreePath); err != nil {
+		return mergePlan{}, err
+	}
+	if err := requireCleanTree(gitClient, mainRepoPath); err != nil {
+		return mergePlan{}, err
+	}
+
+	return mergePlan{
+		Branch:       branch,
+		BaseBranch:   baseBranch,
+		WorktreePath: worktreePath,
+		MainRepoPath: mainRepoPath,
+	}, nil
+}
+
+func requireCleanTree(gitClient mergeGitClient, dir string) error {
+	entries, err := gitClient.StatusPorcelain(dir)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s has uncommitted changes:\n%s\ncommit or stash them first", dir, formatStatusEntries(entries))
+}
+
+func formatStatusEntries(entries []string) string {
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lines = append(lines, "  "+e)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func samePath(a, b string) bool {
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// mergeWorkflowBranch merges plan's branch into its base in the main repo. A
+// conflicting merge is aborted so the main repo is left as it was, and the
+// error lists the conflicting paths.
+func mergeWorkflowBranch(gitClient mergeRunner, plan mergePlan, ffOnly bool) error {
+	err := gitClient.Merge(plan.MainRepoPath, plan.Branch, ffOnly)
+	if err == nil {
+		return nil
+	}
+	conflicts, _ := gitClient.ConflictedFiles(plan.MainRepoPath)
+	if len(conflicts) == 0 {
+		return err
+	}
+	if abortErr := gitClient.AbortMerge(plan.MainRepoPath); abortErr != nil {
+		return fmt.Errorf("merging %s into %s conflicts in:\n%s\nand the merge could not be aborted: %w",
+			plan.Branch, plan.BaseBranch, formatStatusEntries(conflicts), abortErr)
+	}
+	return fmt.Errorf("merging %s into %s conflicts in:\n%s\nthe merge was aborted; rebase the branch onto %s (cb sync) or merge it by hand",
+		plan.Branch, plan.BaseBranch, formatStatusEntries(conflicts), plan.BaseBranch)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	gitClient := git.NewClient()
+
+	sessionName, worktreePath, err := resolveWorkflowTarget(tmuxClient, args)
+	if err != nil {
+		return err
+	}
+
+	plan, err := planMerge(gitClient, worktreePath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Merge workflow: %s\n", sessionName)
+	fmt.Printf("Branch: %s -> %s (%s)\n", plan.Branch, plan.BaseBranch, plan.MainRepoPath)
+	fmt.Printf("Worktree: %s\n", plan.WorktreePath)
+	if !mergeYes && !confirm(os.Stdin, "This will merge, kill the tmux session, remove the worktree, and delete the branch. Continue? [y/N] ") {
+		fmt.Println("Cancelled")
+		return nil
+	}
+
+	fmt.Printf("Merging %s into %s...\n", plan.Branch, plan.BaseBranch)
+	if err := mergeWorkflowBranch(gitClient, plan, mergeFFOnly); err != nil {
+		return err
+	}
+
+	fmt.Println("Killing tmux session...")
+	killSession(tmuxClient, sessionName, cmd.ErrOrStderr())
+	forgetSession(sessionName, cmd.ErrOrStderr())
+
+	// Leave the worktree before removing it.
+	if err := os.Chdir(plan.MainRepoPath); err != nil {
+		return fmt.Errorf("failed to change to main repo: %w", err)
+	}
+
+	fmt.Printf("Removing worktree: %s\n", plan.WorktreePath)
+	if err := gitClient.RemoveWorktree(plan.MainRepoPath, plan.WorktreePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleting branch: %s\n", plan.Branch)
+	if err := gitClient.DeleteBranch(plan.MainRepoPath, plan.Branch); err != nil {
+		return err
+	}
+
+	fmt.Println("Workflow merged and archived.")
+	return nil
+}
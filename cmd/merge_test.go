@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type fakeMergeGitClient struct {
+	branches  map[string]string
+	statuses  map[string][]string
+	mainRepo  string
+	statusErr error
+}
+
+func (f fakeMergeGitClient) CurrentBranch(dir string) (string, error) {
+	return f.branches[dir], nil
+}
+
+func (f fakeMergeGitClient) StatusPorcelain(dir string) ([]string, error) {
+	return f.statuses[dir], f.statusErr
+}
+
+func (f fakeMergeGitClient) MainWorktree(dir string) (string, error) {
+	return f.mainRepo, nil
+}
+
+func TestPlanMerge(t *testing.T) {
+	const repo = "/code/repo"
+	const worktree = "/code/repo/.worktrees/repo-feat"
+
+	tests := []struct {
+		name     string
+		client   fakeMergeGitClient
+		worktree string
+		wantErr  string
+	}{
+		{
+			name: "clean worktree merges into main repo branch",
+			client: fakeMergeGitClient{
+				mainRepo: repo,
+				branches: map[string]string{repo: "main", worktree: "feat"},
+			},
+			worktree: worktree,
+		},
+		{
+			name:     "missing worktree path",
+			client:   fakeMergeGitClient{mainRepo: repo},
+			worktree: "",
+			wantErr:  "could not determine worktree",
+		},
+		{
+			name: "refuses main repo",
+			client: fakeMergeGitClient{
+				mainRepo: repo,
+				branches: map[string]string{repo: "main"},
+			},
+			worktree: repo + "/",
+			wantErr:  "is the main repo",
+		},
+		{
+			name: "refuses same branch",
+			client: fakeMergeGitClient{
+				mainRepo: repo,
+				branches: map[string]string{repo: "main", worktree: "main"},
+			},
+			worktree: worktree,
+			wantErr:  "same as base branch",
+		},
+		{
+			name: "refuses dirty worktree and lists entries",
+			client: fakeMergeGitClient{
+				mainRepo: repo,
+				branches: map[string]string{repo: "main", worktree: "feat"},
+				statuses: map[string][]string{worktree: {" M main.go", "?? scratch.txt"}},
+			},
+			worktree: worktree,
+			wantErr:  "?? scratch.txt",
+		},
+		{
+			name: "refuses dirty main repo",
+			client: fakeMergeGitClient{
+				mainRepo: repo,
+				branches: map[string]string{repo: "main", worktree: "feat"},
+				statuses: map[string][]string{repo: {"M  go.mod"}},
+			},
+			worktree: worktree,
+			wantErr:  repo + " has uncommitted changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planMerge(tt.client, tt.worktree)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("planMerge() expected error containing %q, got nil", tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %q, want to contain %q", err.Error(), tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("planMerge() error = %v", err)
+			}
+			if plan.Branch != "feat" || plan.BaseBranch != "main" {
+				t.Fatalf("plan branches = (%q, %q), want (feat, main)", plan.Branch, plan.BaseBranch)
+			}
+			if plan.MainRepoPath != repo || plan.WorktreePath != worktree {
+				t.Fatalf("plan paths = (%q, %q), want (%q, %q)", plan.MainRepoPath, plan.WorktreePath, repo, worktree)
+			}
+		})
+	}
+}
+
+type fakeMergeRunner struct {
+	mergeErr  error
+	conflicts []string
+	aborted   bool
+}
+
+func (f *fakeMergeRunner) Merge(dir, branch string, ffOnly bool) error { return f.mergeErr }
+
+func (f *fakeMergeRunner) AbortMerge(dir string) error {
+	f.aborted = true
+	return nil
+}
+
+func (f *fakeMergeRunner) ConflictedFiles(dir string) ([]string, error) { return f.conflicts, nil }
+
+func TestMergeWorkflowBranch(t *testing.T) {
+	plan := mergePlan{Branch: "feat", BaseBranch: "main", MainRepoPath: "/code/repo"}
+	mergeErr := errors.New("exit status 1")
+
+	tests := []struct {
+		name        string
+		runner      fakeMergeRunner
+		wantErr     []string
+		wantAborted bool
+	}{
+		{name: "clean merge"},
+		{name: "failure without conflicts", runner: fakeMergeRunner{mergeErr: mergeErr}, wantErr: []string{"exit status 1"}},
+		{
+			name:        "conflict is aborted and listed",
+			runner:      fakeMergeRunner{mergeErr: mergeErr, conflicts: []string{"a.go", "b/c.go"}},
+			wantErr:     []string{"feat into main conflicts in", "  a.go\n  b/c.go", "merge was aborted"},
+			wantAborted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := tt.runner
+			err := mergeWorkflowBranch(&runner, plan, false)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("mergeWorkflowBranch() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("mergeWorkflowBranch() error = nil")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %q, want containing %q", err, want)
+				}
+			}
+			if runner.aborted != tt.wantAborted {
+				t.Errorf("aborted = %v, want %v", runner.aborted, tt.wantAborted)
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.input), func(t *testing.T) {
+			if got := confirm(strings.NewReader(tt.input), ""); got != tt.want {
+				t.Fatalf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
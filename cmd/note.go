@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var noteClear bool
+
+var noteCmd = &cobra.Command{
+	Use:   "note <session-name> [text]",
+	Short: "Attach a freeform note to a session",
+	Long: `Stores a note on a session, such as what it is waiting on. The note is kept in
+the session's @cb_note tmux option, so it lives as long as the session. cb dash
+shows it truncated on the session row and in full in the detail popup (i).
+
+With no text, prints the session's current note.
+
+Example:
+  cb note feature "waiting on review from Sam"
+  cb note feature
+  cb note feature --clear`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runNote,
+}
+
+func init() {
+	noteCmd.Flags().BoolVar(&noteClear, "clear", false, "remove the session's note")
+	rootCmd.AddCommand(noteCmd)
+}
+
+// sessionOptionClient reads and writes the tmux session options behind
+// cb note and cb tag.
+type sessionOptionClient interface {
+	HasSession(name string) bool
+	GetSessionOption(session, key string) (string, error)
+	SetSessionOption(session, key, value string) error
+	UnsetSessionOption(session, key string) error
+}
+
+// sessionNote reads, sets, or clears the note on session. It returns the
+// note after the change ("" once cleared).
+func sessionNote(tmuxClient sessionOptionClient, session string, text *string, clear bool) (string, error) {
+	if !tmuxClient.HasSession(session) {
+		return "", fmt.Errorf("session %s not found", session)
+	}
+	switch {
+	case clear:
+		return "", tmuxClient.UnsetSessionOption(session, tmux.SessionOptionNote)
+	case text != nil:
+		note := strings.TrimSpace(*text)
+		if note == "" {
+			return "", fmt.Errorf("note is empty (use --clear to remove it)")
+		}
+		return note, tmuxClient.SetSessionOption(session, tmux.SessionOptionNote, note)
+	default:
+		note, err := tmuxClient.GetSessionOption(session, tmux.SessionOptionNote)
+		if err != nil {
+			// tmux reports an unset user option as an error.
+			return "", nil
+		}
+		return note, nil
+	}
+}
+
+func runNote(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	session := resolveSessionName(tmuxClient, args[0])
+	var text *string
+	if len(args) == 2 {
+		if noteClear {
+			return fmt.Errorf("cannot combine a note with --clear")
+		}
+		text = &args[1]
+	}
+
+	note, err := sessionNote(tmuxClient, session, text, noteClear)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	switch {
+	case noteClear:
+		_, _ = fmt.Fprintf(out, "Cleared note on %s\n", session)
+	case text != nil:
+		_, _ = fmt.Fprintf(out, "Noted %s: %s\n", session, note)
+	case note == "":
+		_, _ = fmt.Fprintf(out, "%s has no note\n", session)
+	default:
+		_, _ = fmt.Fprintln(out, note)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeNoteClient struct {
+	sessions map[string]bool
+	options  map[string]string
+}
+
+func (f *fakeNoteClient) HasSession(name string) bool { return f.sessions[name] }
+
+func (f *fakeNoteClient) GetSessionOption(session, key string) (string, error) {
+	value, ok := f.options[session+"/"+key]
+	if !ok {
+		return "", errors.New("invalid option")
+	}
+	return value, nil
+}
+
+func (f *fakeNoteClient) SetSessionOption(session, key, value string) error {
+	f.options[session+"/"+key] = value
+	return nil
+}
+
+func (f *fakeNoteClient) UnsetSessionOption(session, key string) error {
+	delete(f.options, session+"/"+key)
+	return nil
+}
+
+func TestSessionNote(t *testing.T) {
+	text := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		existing string
+		text     *string
+		clear    bool
+		session  string
+		want     string
+		wantOpt  string
+		wantErr  string
+	}{
+		{name: "sets note", session: "cb_feat", text: text("  waiting on review "), want: "waiting on review", wantOpt: "waiting on review"},
+		{name: "replaces note", session: "cb_feat", existing: "old", text: text("new"), want: "new", wantOpt: "new"},
+		{name: "reads note", session: "cb_feat", existing: "old", want: "old", wantOpt: "old"},
+		{name: "reads missing note", session: "cb_feat"},
+		{name: "clears note", session: "cb_feat", existing: "old", clear: true},
+		{name: "rejects empty note", session: "cb_feat", existing: "old", text: text("  "), wantOpt: "old", wantErr: "note is empty"},
+		{name: "missing session", session: "cb_gone", wantErr: "session cb_gone not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeNoteClient{sessions: map[string]bool{"cb_feat": true}, options: map[string]string{}}
+			if tt.existing != "" {
+				client.options["cb_feat/"+tmux.SessionOptionNote] = tt.existing
+			}
+
+			got, err := sessionNote(client, tt.session, tt.text, tt.clear)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("sessionNote() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("sessionNote() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("sessionNote() = %q, want %q", got, tt.want)
+			}
+			if opt := client.options["cb_feat/"+tmux.SessionOptionNote]; opt != tt.wantOpt {
+				t.Errorf("stored note = %q, want %q", opt, tt.wantOpt)
+			}
+		})
+	}
+}
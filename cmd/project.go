@@ -10,6 +10,7 @@ import (
 )
 
 var projectAddName string
+var projectAddWorkspace string
 var projectRemoveByName string
 
 var projectCmd = &cobra.Command{
@@ -51,6 +52,7 @@ var projectListCmd = &cobra.Command{
 
 func init() {
 	projectAddCmd.Flags().StringVar(&projectAddName, "name", "", "optional project display name")
+	projectAddCmd.Flags().StringVar(&projectAddWorkspace, "workspace", "", "optional workspace to group the project in (see cb dash --workspace)")
 	projectRemoveCmd.Flags().StringVar(&projectRemoveByName, "name", "", "remove by exact configured project name")
 
 	projectCmd.AddCommand(projectAddCmd)
@@ -69,20 +71,21 @@ func runProjectAdd(cmd *cobra.Command, args []string) error {
 	if projectAddName != "" && name == "" {
 		return fmt.Errorf("--name must be non-empty when provided")
 	}
-
-	cfg, err := config.LoadUserConfig()
-	if err != nil {
-		return err
+	workspace := strings.TrimSpace(projectAddWorkspace)
+	if projectAddWorkspace != "" && workspace == "" {
+		return fmt.Errorf("--workspace must be non-empty when provided")
 	}
 
-	for _, p := range cfg.Projects {
-		if p.Path == canonicalPath {
-			return fmt.Errorf("project already configured: %s", canonicalPath)
+	err = config.UpdateUserConfig(func(cfg *config.UserConfig) error {
+		for _, p := range cfg.Projects {
+			if p.Path == canonicalPath {
+				return fmt.Errorf("project already configured: %s", canonicalPath)
+			}
 		}
-	}
-
-	cfg.Projects = append(cfg.Projects, config.ProjectConfig{Path: canonicalPath, Name: name})
-	if err := config.SaveUserConfig(cfg); err != nil {
+		cfg.Projects = append(cfg.Projects, config.ProjectConfig{Path: canonicalPath, Name: name, Workspace: workspace})
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -91,44 +94,41 @@ func runProjectAdd(cmd *cobra.Command, args []string) error {
 }
 
 func runProjectRemove(cmd *cobra.Command, args []string) error {
-	cfg, err := config.LoadUserConfig()
-	if err != nil {
-		return err
-	}
-
 	if strings.TrimSpace(projectRemoveByName) != "" {
-		return removeProjectByName(cmd, cfg, strings.TrimSpace(projectRemoveByName))
+		return removeProjectByName(cmd, strings.TrimSpace(projectRemoveByName))
 	}
-	return removeProjectByPath(cmd, cfg, args[0])
+	return removeProjectByPath(cmd, args[0])
 }
 
-func removeProjectByPath(cmd *cobra.Command, cfg config.UserConfig, inputPath string) error {
+func removeProjectByPath(cmd *cobra.Command, inputPath string) error {
 	canonicalInputPath, err := config.CanonicalPath(inputPath)
 	if err != nil {
 		return fmt.Errorf("failed to canonicalize removal path %q: %w", inputPath, err)
 	}
 
-	filtered := make([]config.ProjectConfig, 0, len(cfg.Projects))
-	removedCount := 0
-	for _, p := range cfg.Projects {
-		canonicalConfiguredPath, canonicalErr := config.CanonicalPath(p.Path)
-		if canonicalErr != nil {
+	err = config.UpdateUserConfig(func(cfg *config.UserConfig) error {
+		filtered := make([]config.ProjectConfig, 0, len(cfg.Projects))
+		removedCount := 0
+		for _, p := range cfg.Projects {
+			canonicalConfiguredPath, canonicalErr := config.CanonicalPath(p.Path)
+			if canonicalErr != nil {
+				filtered = append(filtered, p)
+				continue
+			}
+			if canonicalConfiguredPath == canonicalInputPath {
+				removedCount++
+				continue
+			}
 			filtered = append(filtered, p)
-			continue
 		}
-		if canonicalConfiguredPath == canonicalInputPath {
-			removedCount++
-			continue
-		}
-		filtered = append(filtered, p)
-	}
-
-	if removedCount == 0 {
-		return fmt.Errorf("no configured project matched canonical path %s", canonicalInputPath)
-	}
 
-	cfg.Projects = filtered
-	if err := config.SaveUserConfig(cfg); err != nil {
+		if removedCount == 0 {
+			return fmt.Errorf("no configured project matched canonical path %s", canonicalInputPath)
+		}
+		cfg.Projects = filtered
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -136,25 +136,29 @@ func removeProjectByPath(cmd *cobra.Command, cfg config.UserConfig, inputPath st
 	return nil
 }
 
-func removeProjectByName(cmd *cobra.Command, cfg config.UserConfig, name string) error {
-	matchIndexes := make([]int, 0, 1)
-	for i, p := range cfg.Projects {
-		if p.Name == name {
-			matchIndexes = append(matchIndexes, i)
+func removeProjectByName(cmd *cobra.Command, name string) error {
+	var removedPath string
+	err := config.UpdateUserConfig(func(cfg *config.UserConfig) error {
+		matchIndexes := make([]int, 0, 1)
+		for i, p := range cfg.Projects {
+			if p.Name == name {
+				matchIndexes = append(matchIndexes, i)
+			}
 		}
-	}
 
-	if len(matchIndexes) == 0 {
-		return fmt.Errorf("no configured project matched name %q", name)
-	}
-	if len(matchIndexes) > 1 {
-		return fmt.Errorf("project name %q is ambiguous; use canonical path removal", name)
-	}
+		if len(matchIndexes) == 0 {
+			return fmt.Errorf("no configured project matched name %q", name)
+		}
+		if len(matchIndexes) > 1 {
+			return fmt.Errorf("project name %q is ambiguous; use canonical path removal", name)
+		}
 
-	idx := matchIndexes[0]
-	removedPath := cfg.Projects[idx].Path
-	cfg.Projects = append(cfg.Projects[:idx], cfg.Projects[idx+1:]...)
-	if err := config.SaveUserConfig(cfg); err != nil {
+		idx := matchIndexes[0]
+		removedPath = cfg.Projects[idx].Path
+		cfg.Projects = append(cfg.Projects[:idx], cfg.Projects[idx+1:]...)
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -192,7 +196,11 @@ func runProjectList(cmd *cobra.Command, _ []string) error {
 			status = fmt.Sprintf("INVALID: configured path is not canonical (canonical=%s)", canonicalPath)
 		}
 
-		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n  path: %s\n  status: %s\n", displayName, p.Path, status)
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n  path: %s\n", displayName, p.Path)
+		if p.Workspace != "" {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  workspace: %s\n", p.Workspace)
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  status: %s\n", status)
 	}
 
 	return nil
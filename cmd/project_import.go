@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// projectImportMaxDepth bounds how deep under a root repositories are
+// searched for; ghq's host/owner/repo layout needs 3.
+const projectImportMaxDepth = 4
+
+var projectImportGhq bool
+var projectImportDryRun bool
+
+// ghqRootsFunc resolves the ghq roots; tests replace it.
+var ghqRootsFunc = ghqRoots
+
+var projectImportCmd = &cobra.Command{
+	Use:   "import [root...]",
+	Short: "Add every git repository under the given roots as a project",
+	Long: `Searches each root (up to 4 directories deep) for git repositories and adds
+every one that is not configured yet as a project. With --ghq, the roots of
+ghq (https://github.com/x-motemen/ghq) are searched as well.
+
+A project whose directory name is already used by another project is named
+after more of its path instead ("acme/api" next to an existing "api"), so
+every project keeps a distinct name in cb dash.
+
+Example:
+  cb project import --ghq
+  cb project import ~/src ~/work --dry-run`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !projectImportGhq {
+			return fmt.Errorf("expected at least 1 root, or use --ghq")
+		}
+		return nil
+	},
+	RunE: runProjectImport,
+}
+
+func init() {
+	projectImportCmd.Flags().BoolVar(&projectImportGhq, "ghq", false, "also import the repositories under the ghq roots")
+	projectImportCmd.Flags().BoolVar(&projectImportDryRun, "dry-run", false, "print the projects that would be added without saving them")
+	projectCmd.AddCommand(projectImportCmd)
+}
+
+func runProjectImport(cmd *cobra.Command, args []string) error {
+	roots := slices.Clone(args)
+	if projectImportGhq {
+		ghq, err := ghqRootsFunc()
+		if err != nil {
+			return err
+		}
+		roots = append(roots, ghq...)
+	}
+
+	var repos []string
+	for _, root := range roots {
+		found, err := findGitRepos(root, projectImportMaxDepth)
+		if err != nil {
+			return err
+		}
+		repos = append(repos, found...)
+	}
+
+	var added []config.ProjectConfig
+	err := config.UpdateUserConfig(func(cfg *config.UserConfig) error {
+		added = importProjects(cfg.Projects, repos)
+		if len(added) == 0 || projectImportDryRun {
+			return config.ErrUnchanged
+		}
+		cfg.Projects = append(cfg.Projects, added...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for _, p := range added {
+		if p.Name != "" {
+			_, _ = fmt.Fprintf(out, "Added project %q: %s\n", p.Name, p.Path)
+		} else {
+			_, _ = fmt.Fprintf(out, "Added project: %s\n", p.Path)
+		}
+	}
+	if len(added) == 0 {
+		_, _ = fmt.Fprintf(out, "No new repositories found (%d already configured).\n", len(repos))
+		return nil
+	}
+	if projectImportDryRun {
+		_, _ = fmt.Fprintf(out, "Dry run: %d projects not saved.\n", len(added))
+	}
+	return nil
+}
+
+// ghqRoots returns ghq's roots: "ghq root --all" when ghq is installed,
+// otherwise the ghq.root git config values, otherwise ~/ghq.
+func ghqRoots() ([]string, error) {
+	output, err := exec.Command("ghq", "root", "--all").Output()
+	if err != nil {
+		output, _ = exec.Command("git", "config", "--path", "--get-all", "ghq.root").Output()
+	}
+	roots := strings.Fields(string(output))
+	if len(roots) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		roots = []string{filepath.Join(home, "ghq")}
+	}
+	return roots, nil
+}
+
+// findGitRepos returns the canonical paths of the git repositories under
+// root, at most maxDepth directories down, in lexical order. Repositories
+// are not searched for nested ones, and hidden directories are skipped.
+func findGitRepos(root string, maxDepth int) ([]string, error) {
+	canonicalRoot, err := config.CanonicalPath(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to canonicalize import root %q: %w", root, err)
+	}
+
+	var repos []string
+	err = filepath.WalkDir(canonicalRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped, not fatal.
+			if d != nil && d.IsDir() && path != canonicalRoot {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != canonicalRoot && strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir
+		}
+		if _, statErr := os.Stat(filepath.Join(path, ".git")); statErr == nil {
+			repos = append(repos, path)
+			return fs.SkipDir
+		}
+		rel, _ := filepath.Rel(canonicalRoot, path)
+		if rel != "." && strings.Count(rel, string(filepath.Separator))+1 >= maxDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s for repositories: %w", canonicalRoot, err)
+	}
+	return repos, nil
+}
+
+// importProjects returns projects for the repos that are not configured yet.
+// A repo keeps an empty name (shown as its directory name) unless that is
+// taken by a configured or earlier imported project; then it is named after
+// as many trailing path elements as make it unique.
+func importProjects(configured []config.ProjectConfig, repos []string) []config.ProjectConfig {
+	paths := make(map[string]bool, len(configured))
+	taken := make(map[string]bool, len(configured))
+	for _, p := range configured {
+		paths[p.Path] = true
+		taken[projectDisplayName(p)] = true
+	}
+
+	var added []config.ProjectConfig
+	for _, repo := range repos {
+		if paths[repo] {
+			continue
+		}
+		paths[repo] = true
+
+		project := config.ProjectConfig{Path: repo}
+		if name := uniqueProjectName(repo, taken); name != filepath.Base(repo) {
+			project.Name = name
+		}
+		taken[projectDisplayName(project)] = true
+		added = append(added, project)
+	}
+	return added
+}
+
+// uniqueProjectName returns the shortest trailing part of path ("repo",
+// "owner/repo", "host/owner/repo", ...) that is not taken, or the whole path.
+func uniqueProjectName(path string, taken map[string]bool) string {
+	elems := strings.Split(strings.Trim(filepath.ToSlash(path), "/"), "/")
+	for n := 1; n <= len(elems); n++ {
+		name := strings.Join(elems[len(elems)-n:], "/")
+		if !taken[name] {
+			return name
+		}
+	}
+	return path
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+)
+
+func TestFindGitRepos(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"github.com/acme/api/.git",
+		"github.com/acme/api/vendor/nested/.git",
+		"github.com/other/api/.git",
+		"gitlab.com/team/web/.git",
+		".cache/hidden/.git",
+		"a/b/c/d/too-deep/.git",
+		"notes",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	canonicalRoot, err := config.CanonicalPath(root)
+	if err != nil {
+		t.Fatalf("CanonicalPath() error = %v", err)
+	}
+
+	got, err := findGitRepos(root, projectImportMaxDepth)
+	if err != nil {
+		t.Fatalf("findGitRepos() error = %v", err)
+	}
+	var rel []string
+	for _, repo := range got {
+		r, _ := filepath.Rel(canonicalRoot, repo)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	want := []string{"github.com/acme/api", "github.com/other/api", "gitlab.com/team/web"}
+	if !reflect.DeepEqual(rel, want) {
+		t.Fatalf("findGitRepos() = %q, want %q", rel, want)
+	}
+}
+
+func TestImportProjects(t *testing.T) {
+	configured := []config.ProjectConfig{
+		{Path: "/src/api"},
+		{Path: "/src/legacy", Name: "web"},
+	}
+	repos := []string{
+		"/src/api",
+		"/ghq/github.com/acme/api",
+		"/ghq/github.com/other/api",
+		"/ghq/gitlab.com/acme/api",
+		"/ghq/github.com/acme/web",
+		"/ghq/github.com/acme/cli",
+	}
+
+	got := importProjects(configured, repos)
+	want := []config.ProjectConfig{
+		{Path: "/ghq/github.com/acme/api", Name: "acme/api"},
+		{Path: "/ghq/github.com/other/api", Name: "other/api"},
+		{Path: "/ghq/gitlab.com/acme/api", Name: "gitlab.com/acme/api"},
+		{Path: "/ghq/github.com/acme/web", Name: "acme/web"},
+		{Path: "/ghq/github.com/acme/cli"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("importProjects() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunProjectImport_Ghq(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	ghqRoot := filepath.Join(home, "ghq")
+	if err := os.MkdirAll(filepath.Join(ghqRoot, "github.com", "acme", "api", ".git"), 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+
+	origRoots := ghqRootsFunc
+	ghqRootsFunc = func() ([]string, error) { return []string{ghqRoot}, nil }
+	t.Cleanup(func() {
+		ghqRootsFunc = origRoots
+		projectImportGhq, projectImportDryRun = false, false
+	})
+	projectImportGhq = true
+
+	cmd, out := testProjectCmd()
+	if err := runProjectImport(cmd, nil); err != nil {
+		t.Fatalf("runProjectImport() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Added project: ") {
+		t.Fatalf("output = %q, want an added project", out.String())
+	}
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if len(cfg.Projects) != 1 || filepath.Base(cfg.Projects[0].Path) != "api" {
+		t.Fatalf("projects = %+v, want the api clone", cfg.Projects)
+	}
+
+	out.Reset()
+	if err := runProjectImport(cmd, nil); err != nil {
+		t.Fatalf("second runProjectImport() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "No new repositories found (1 already configured)") {
+		t.Fatalf("second import output = %q", out.String())
+	}
+}
@@ -22,6 +22,8 @@ func TestRunProjectAdd_Success(t *testing.T) {
 	}
 
 	projectAddName = "my repo"
+	projectAddWorkspace = " work "
+	t.Cleanup(func() { projectAddWorkspace = "" })
 	projectRemoveByName = ""
 	cmd, _ := testProjectCmd()
 
@@ -46,6 +48,9 @@ func TestRunProjectAdd_Success(t *testing.T) {
 	if cfg.Projects[0].Name != "my repo" {
 		t.Fatalf("stored name = %q, want my repo", cfg.Projects[0].Name)
 	}
+	if cfg.Projects[0].Workspace != "work" {
+		t.Fatalf("stored workspace = %q, want work", cfg.Projects[0].Workspace)
+	}
 }
 
 func TestRunProjectAdd_InvalidPath(t *testing.T) {
@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/queue"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var queueWatchInterval time.Duration
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Queue prompts for a session's agent",
+	Long: `Each session has a prompt queue. cb queue watch feeds the next prompt to the
+session's agent whenever it finishes its current work and is back at its input
+prompt, so a batch of follow-ups can run unattended.`,
+}
+
+var queueAddCmd = &cobra.Command{
+	Use:   "add <session-name> <prompt>",
+	Short: "Append a prompt to a session's queue",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runQueueAdd,
+}
+
+var queueListCmd = &cobra.Command{
+	Use:   "list [session-name]",
+	Short: "List queued prompts",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runQueueList,
+}
+
+var queueClearCmd = &cobra.Command{
+	Use:   "clear <session-name>",
+	Short: "Drop every queued prompt for a session",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runQueueClear,
+}
+
+var queueWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Send queued prompts to agents as they become ready",
+	Long: `Runs in the foreground, checking every session with queued prompts. When the
+session's agent is IDLE or WAITING at its input prompt (not at a permission or
+confirmation dialog), the next prompt is typed into it. A session gets no
+further prompt until its agent has been seen WORKING on the last one.`,
+	Args: cobra.NoArgs,
+	RunE: runQueueWatch,
+}
+
+func init() {
+	queueWatchCmd.Flags().DurationVar(&queueWatchInterval, "interval", runPollInterval, "how often to check agents")
+
+	queueCmd.AddCommand(queueAddCmd)
+	queueCmd.AddCommand(queueListCmd)
+	queueCmd.AddCommand(queueClearCmd)
+	queueCmd.AddCommand(queueWatchCmd)
+	rootCmd.AddCommand(queueCmd)
+}
+
+// promptQueue returns the store for the user's prompt queues.
+func promptQueue() (*queue.Store, error) {
+	c, err := config.New()
+	if err != nil {
+		return nil, err
+	}
+	return queue.NewStore(c.PromptQueuePath()), nil
+}
+
+func runQueueAdd(cmd *cobra.Command, args []string) error {
+	text := strings.TrimSpace(args[1])
+	if text == "" {
+		return fmt.Errorf("prompt is empty")
+	}
+	tmuxClient := tmux.NewClient()
+	session := resolveSessionName(tmuxClient, args[0])
+	if !tmuxClient.HasSession(session) {
+		return fmt.Errorf("session %s not found", session)
+	}
+
+	store, err := promptQueue()
+	if err != nil {
+		return err
+	}
+	n, err := store.Add(session, queue.Prompt{Text: text, AddedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Queued prompt %d for %s\n", n, session)
+	return nil
+}
+
+func runQueueList(cmd *cobra.Command, args []string) error {
+	store, err := promptQueue()
+	if err != nil {
+		return err
+	}
+	queues, err := store.Load()
+	if err != nil {
+		return err
+	}
+	sessions, err := store.Sessions()
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		sessions = []string{resolveSessionName(tmux.NewClient(), args[0])}
+	}
+	writeQueueList(cmd.OutOrStdout(), sessions, queues)
+	return nil
+}
+
+func writeQueueList(w io.Writer, sessions []string, queues map[string][]queue.Prompt) {
+	printed := false
+	for _, session := range sessions {
+		pending := queues[session]
+		if len(pending) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintln(w, session)
+		for i, p := range pending {
+			_, _ = fmt.Fprintf(w, "  %d. %s\n", i+1, p.Text)
+		}
+		printed = true
+	}
+	if !printed {
+		_, _ = fmt.Fprintln(w, "No queued prompts.")
+	}
+}
+
+func runQueueClear(cmd *cobra.Command, args []string) error {
+	session := resolveSessionName(tmux.NewClient(), args[0])
+	store, err := promptQueue()
+	if err != nil {
+		return err
+	}
+	n, err := store.Clear(session)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Cleared %d queued prompt(s) for %s\n", n, session)
+	return nil
+}
+
+func runQueueWatch(cmd *cobra.Command, args []string) error {
+	if queueWatchInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	store, err := promptQueue()
+	if err != nil {
+		return err
+	}
+	d := newQueueDispatcher(tmux.NewClient(), store, cmd.OutOrStdout())
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Watching prompt queues in %s (Ctrl-C to stop)\n", store.Path())
+	for {
+		if err := d.dispatch(); err != nil {
+			return err
+		}
+		time.Sleep(queueWatchInterval)
+	}
+}
+
+type queueTmuxClient interface {
+	ListWindows(session string) ([]tmux.Window, error)
+	DetectAgentInfo(target string) tmux.AgentInfo
+	AtInputPrompt(target string) bool
+	SendText(target, text string) error
+}
+
+// queueDispatcher sends queued prompts to agents that are ready for input.
+type queueDispatcher struct {
+	client queueTmuxClient
+	store  *queue.Store
+	out    io.Writer
+	// sent holds agent windows that were sent a prompt and have not been
+	// seen WORKING since, so one idle spell is never fed two prompts.
+	sent map[string]bool
+}
+
+func newQueueDispatcher(client queueTmuxClient, store *queue.Store, out io.Writer) *queueDispatcher {
+	return &queueDispatcher{client: client, store: store, out: out, sent: map[string]bool{}}
+}
+
+// dispatch makes one pass over the queued sessions, sending at most one
+// prompt to each.
+func (d *queueDispatcher) dispatch() error {
+	queues, err := d.store.Load()
+	if err != nil {
+		return err
+	}
+	sessions, err := d.store.Sessions()
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		target, info, ok := d.agentWindow(session)
+		if !ok {
+			continue
+		}
+		if info.Status == tmux.StatusWorking {
+			delete(d.sent, target)
+			continue
+		}
+		if d.sent[target] || !d.readyForPrompt(target, info) {
+			continue
+		}
+
+		prompt := queues[session][0]
+		if err := d.client.SendText(target, prompt.Text); err != nil {
+			_, _ = fmt.Fprintf(d.out, "Warning: failed to send queued prompt to %s: %v\n", session, err)
+			continue
+		}
+		d.sent[target] = true
+		if _, _, err := d.store.Pop(session); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(d.out, "Sent queued prompt to %s (%d left): %s\n", session, len(queues[session])-1, prompt.Text)
+	}
+	return nil
+}
+
+// agentWindow returns the first window of session running a detected agent.
+func (d *queueDispatcher) agentWindow(session string) (string, tmux.AgentInfo, bool) {
+	wins, err := d.client.ListWindows(session)
+	if err != nil {
+		if !errors.Is(err, tmux.ErrNoSession) && !errors.Is(err, tmux.ErrNoServer) {
+			_, _ = fmt.Fprintf(d.out, "Warning: %v\n", err)
+		}
+		slog.Debug("queue: session unavailable", "session", session, "err", err)
+		return "", tmux.AgentInfo{}, false
+	}
+	for _, w := range wins {
+		target := w.Target(session)
+		if info := d.client.DetectAgentInfo(target); info.Detected {
+			return target, info, true
+		}
+	}
+	return "", tmux.AgentInfo{}, false
+}
+
+// readyForPrompt reports whether typed text would reach the agent as its next
+// instruction rather than answer a dialog.
+func (d *queueDispatcher) readyForPrompt(target string, info tmux.AgentInfo) bool {
+	switch info.Status {
+	case tmux.StatusIdle:
+		return true
+	case tmux.StatusWaiting:
+		return d.client.AtInputPrompt(target)
+	default:
+		return false
+	}
+}
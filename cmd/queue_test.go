@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/queue"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeQueueTmuxClient struct {
+	status  tmux.Status
+	atInput bool
+	sent    []string
+}
+
+func (f *fakeQueueTmuxClient) ListWindows(session string) ([]tmux.Window, error) {
+	return []tmux.Window{{ID: "@1", Index: 0, Name: "shell"}, {ID: "@2", Index: 1, Name: "claude"}}, nil
+}
+
+func (f *fakeQueueTmuxClient) DetectAgentInfo(target string) tmux.AgentInfo {
+	if target != "@2" {
+		return tmux.AgentInfo{Type: tmux.AgentNone, Status: tmux.StatusDone}
+	}
+	return tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: f.status}
+}
+
+func (f *fakeQueueTmuxClient) AtInputPrompt(target string) bool { return f.atInput }
+
+func (f *fakeQueueTmuxClient) SendText(target, text string) error {
+	f.sent = append(f.sent, target+" "+text)
+	return nil
+}
+
+func TestQueueDispatcher_SendsOnePromptPerIdleSpell(t *testing.T) {
+	store := queue.NewStore(filepath.Join(t.TempDir(), "queue.json"))
+	for _, text := range []string{"first", "second"} {
+		if _, err := store.Add("cb_feat", queue.Prompt{Text: text}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	client := &fakeQueueTmuxClient{status: tmux.StatusWaiting}
+	d := newQueueDispatcher(client, store, &bytes.Buffer{})
+
+	steps := []struct {
+		status  tmux.Status
+		atInput bool
+	}{
+		{tmux.StatusWaiting, false}, // permission dialog: not ready
+		{tmux.StatusWaiting, true},  // at input: sends "first"
+		{tmux.StatusIdle, false},    // not yet seen working: no resend
+		{tmux.StatusWorking, false}, // working on "first"
+		{tmux.StatusIdle, false},    // idle again: sends "second"
+		{tmux.StatusWorking, false},
+		{tmux.StatusIdle, false}, // queue empty
+	}
+	for i, step := range steps {
+		client.status, client.atInput = step.status, step.atInput
+		if err := d.dispatch(); err != nil {
+			t.Fatalf("step %d: dispatch() error = %v", i, err)
+		}
+	}
+
+	want := []string{"@2 first", "@2 second"}
+	if !reflect.DeepEqual(client.sent, want) {
+		t.Fatalf("sent = %v, want %v", client.sent, want)
+	}
+	queues, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(queues) != 0 {
+		t.Fatalf("queues = %v, want drained", queues)
+	}
+}
+
+func TestWriteQueueList(t *testing.T) {
+	queues := map[string][]queue.Prompt{"cb_a": {{Text: "one"}, {Text: "two"}}}
+
+	var buf bytes.Buffer
+	writeQueueList(&buf, []string{"cb_a", "cb_b"}, queues)
+	if want := "cb_a\n  1. one\n  2. two\n"; buf.String() != want {
+		t.Fatalf("output = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	writeQueueList(&buf, []string{"cb_b"}, queues)
+	if want := "No queued prompts.\n"; buf.String() != want {
+		t.Fatalf("output = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var renameWindow string
+
+var renameCmd = &cobra.Command{
+	Use:   "rename <session-name> <new-name>",
+	Short: "Rename a managed session",
+	Long: `Renames a running ClawdBay session in place; its windows and agents keep
+running. The cb_ prefix is added to both names when missing. The session's home
+path, note, and tags stay attached, and its cb restore record, cb stats
+history, and pin move to the new name.
+
+--window also renames the session's agent window (the first window running a
+detected agent).
+
+Example:
+  cb rename feature-login login-flow
+  cb rename cb_feature-login login-flow --window reviewer`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRename,
+}
+
+func init() {
+	renameCmd.Flags().StringVar(&renameWindow, "window", "", "also rename the session's agent window")
+	rootCmd.AddCommand(renameCmd)
+}
+
+type renameTmuxClient interface {
+	HasSession(name string) bool
+	RenameSession(oldName, newName string) error
+	GetSessionOption(session, key string) (string, error)
+	SetSessionOption(session, key, value string) error
+	ListWindows(session string) ([]tmux.Window, error)
+	DetectAgentInfo(target string) tmux.AgentInfo
+	RenameWindow(session, name string) error
+}
+
+// renameSession renames the tmux session oldName to newName. Session options
+// such as the note and tags move with the session; the home path is re-pinned
+// under the new name.
+func renameSession(tmuxClient renameTmuxClient, oldName, newName string) error {
+	if err := validateSessionName(newName); err != nil {
+		return err
+	}
+	if !tmuxClient.HasSession(oldName) {
+		return fmt.Errorf("session %s not found", oldName)
+	}
+	if oldName == newName {
+		return fmt.Errorf("session is already named %s", newName)
+	}
+	if tmuxClient.HasSession(newName) {
+		return fmt.Errorf("session %s already exists", newName)
+	}
+
+	home, homeErr := tmuxClient.GetSessionOption(oldName, tmux.SessionOptionHomePath)
+	if err := tmuxClient.RenameSession(oldName, newName); err != nil {
+		return err
+	}
+	if homeErr == nil && home != "" {
+		return tmuxClient.SetSessionOption(newName, tmux.SessionOptionHomePath, home)
+	}
+	return nil
+}
+
+// renameAgentWindow renames the first window of session running a detected
+// agent.
+func renameAgentWindow(tmuxClient renameTmuxClient, session, name string) error {
+	windows, err := tmuxClient.ListWindows(session)
+	if err != nil {
+		return err
+	}
+	for _, w := range windows {
+		target := w.Target(session)
+		if tmuxClient.DetectAgentInfo(target).Type != tmux.AgentNone {
+			return tmuxClient.RenameWindow(target, name)
+		}
+	}
+	return fmt.Errorf("session %s has no agent window to rename", session)
+}
+
+// validateSessionName rejects names tmux cannot use as a session target.
+func validateSessionName(name string) error {
+	base := strings.TrimPrefix(name, "cb_")
+	if strings.TrimSpace(base) == "" {
+		return fmt.Errorf("new session name is empty")
+	}
+	if strings.ContainsAny(name, ".: \t") {
+		return fmt.Errorf("invalid session name %q: must not contain '.', ':', or spaces", name)
+	}
+	return nil
+}
+
+// renameSessionRecords moves the registry entry, activity, and pin recorded
+// for oldName to newName, warning on failure.
+func renameSessionRecords(oldName, newName string, errWriter io.Writer) {
+	if store, err := sessionRegistry(); err != nil || store.Rename(oldName, newName) != nil {
+		_, _ = fmt.Fprintf(errWriter, "Warning: failed to rename %s in session registry\n", oldName)
+	}
+	if store, err := activityStore(); err != nil || store.Rename(oldName, newName) != nil {
+		_, _ = fmt.Fprintf(errWriter, "Warning: failed to rename %s in activity history\n", oldName)
+	}
+
+	err := config.UpdateUserConfig(func(cfg *config.UserConfig) error {
+		if !cfg.RenamePinnedSession(oldName, newName) {
+			return config.ErrUnchanged
+		}
+		return nil
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(errWriter, "Warning: failed to move the pin on %s: %v\n", oldName, err)
+	}
+}
+
+func runRename(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	oldName := resolveSessionName(tmuxClient, args[0])
+	newName := managedSessionName(strings.TrimSpace(args[1]))
+
+	if err := renameSession(tmuxClient, oldName, newName); err != nil {
+		return err
+	}
+	renameSessionRecords(oldName, newName, cmd.ErrOrStderr())
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Renamed %s to %s\n", oldName, newName)
+
+	if renameWindow == "" {
+		return nil
+	}
+	if err := renameAgentWindow(tmuxClient, newName, renameWindow); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Renamed agent window to %s\n", renameWindow)
+	return nil
+}
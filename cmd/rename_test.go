@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeRenameClient struct {
+	fakeNoteClient
+	windows       map[string][]tmux.Window
+	agents        map[string]tmux.AgentType
+	renamedWindow map[string]string
+}
+
+func (f *fakeRenameClient) RenameSession(oldName, newName string) error {
+	delete(f.sessions, oldName)
+	f.sessions[newName] = true
+	for key, value := range f.options {
+		if rest, ok := strings.CutPrefix(key, oldName+"/"); ok {
+			delete(f.options, key)
+			f.options[newName+"/"+rest] = value
+		}
+	}
+	return nil
+}
+
+func (f *fakeRenameClient) ListWindows(session string) ([]tmux.Window, error) {
+	return f.windows[session], nil
+}
+
+func (f *fakeRenameClient) DetectAgentInfo(target string) tmux.AgentInfo {
+	agent, ok := f.agents[target]
+	if !ok {
+		agent = tmux.AgentNone
+	}
+	return tmux.AgentInfo{Type: agent}
+}
+
+func (f *fakeRenameClient) RenameWindow(target, name string) error {
+	f.renamedWindow[target] = name
+	return nil
+}
+
+func newFakeRenameClient() *fakeRenameClient {
+	return &fakeRenameClient{
+		fakeNoteClient: fakeNoteClient{
+			sessions: map[string]bool{"cb_old": true, "cb_taken": true},
+			options: map[string]string{
+				"cb_old/" + tmux.SessionOptionHomePath: "/repo/.worktrees/old",
+				"cb_old/" + tmux.SessionOptionNote:     "waiting on review",
+			},
+		},
+		windows:       map[string][]tmux.Window{},
+		agents:        map[string]tmux.AgentType{},
+		renamedWindow: map[string]string{},
+	}
+}
+
+func TestRenameSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldName string
+		newName string
+		wantErr string
+	}{
+		{name: "renames and keeps options", oldName: "cb_old", newName: "cb_new"},
+		{name: "missing session", oldName: "cb_gone", newName: "cb_new", wantErr: "session cb_gone not found"},
+		{name: "name taken", oldName: "cb_old", newName: "cb_taken", wantErr: "already exists"},
+		{name: "same name", oldName: "cb_old", newName: "cb_old", wantErr: "already named"},
+		{name: "invalid name", oldName: "cb_old", newName: "cb_a:b", wantErr: "invalid session name"},
+		{name: "empty name", oldName: "cb_old", newName: "cb_", wantErr: "empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeRenameClient()
+			err := renameSession(client, tt.oldName, tt.newName)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("renameSession() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renameSession() error = %v", err)
+			}
+			if client.sessions[tt.oldName] || !client.sessions[tt.newName] {
+				t.Fatalf("sessions = %v, want %s renamed to %s", client.sessions, tt.oldName, tt.newName)
+			}
+			if got := client.options[tt.newName+"/"+tmux.SessionOptionHomePath]; got != "/repo/.worktrees/old" {
+				t.Errorf("home path = %q, want /repo/.worktrees/old", got)
+			}
+			if got := client.options[tt.newName+"/"+tmux.SessionOptionNote]; got != "waiting on review" {
+				t.Errorf("note = %q, want it carried over", got)
+			}
+		})
+	}
+}
+
+func TestRenameAgentWindow(t *testing.T) {
+	client := newFakeRenameClient()
+	client.windows["cb_old"] = []tmux.Window{{Index: 0, Name: "shell"}, {Index: 1, Name: "claude"}}
+	client.agents["cb_old:1"] = tmux.AgentClaude
+
+	if err := renameAgentWindow(client, "cb_old", "reviewer"); err != nil {
+		t.Fatalf("renameAgentWindow() error = %v", err)
+	}
+	if got := client.renamedWindow["cb_old:1"]; got != "reviewer" || len(client.renamedWindow) != 1 {
+		t.Fatalf("renamed windows = %v, want cb_old:1 -> reviewer", client.renamedWindow)
+	}
+
+	delete(client.agents, "cb_old:1")
+	if err := renameAgentWindow(client, "cb_old", "reviewer"); err == nil {
+		t.Fatal("renameAgentWindow() error = nil, want no agent window error")
+	}
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/activity"
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/registry"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var restoreAgents bool
+var restoreResume bool
+var restoreDryRun bool
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Recreate recorded sessions after tmux or the machine restarts",
+	Long: `Recreates every recorded ClawdBay session that is no longer running: the tmux
+session is created in its worktree, re-pinned to that home path, and its windows
+are recreated by name with their recorded pane splits and layout. With --agents,
+windows that were running a coding agent relaunch it; with --resume, the agent
+picks up its most recent conversation in the worktree (claude --continue,
+codex resume --last, opencode --continue, or the [resume] config command).
+
+Sessions are recorded by cb start and refreshed whenever cb dash or cb list runs;
+cb archive and cb merge forget them.
+
+Example:
+  cb restore
+  cb restore --agents
+  cb restore --resume
+  cb restore --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: runRestore,
+}
+
+func init() {
+	restoreCmd.Flags().BoolVar(&restoreAgents, "agents", false, "relaunch coding agents in windows that were running one")
+	restoreCmd.Flags().BoolVar(&restoreResume, "resume", false, "relaunch coding agents on their most recent conversation (implies --agents)")
+	restoreCmd.Flags().BoolVar(&restoreDryRun, "dry-run", false, "show what would be restored without creating sessions")
+	rootCmd.AddCommand(restoreCmd)
+}
+
+// sessionRegistry returns the store for the user's session registry.
+func sessionRegistry() (*registry.Store, error) {
+	c, err := config.New()
+	if err != nil {
+		return nil, err
+	}
+	return registry.NewStore(c.SessionRegistryPath()), nil
+}
+
+// registrySessionsFromDiscovery converts live discovered sessions into
+// registry entries stamped with now.
+func registrySessionsFromDiscovery(result discovery.Result, now time.Time) []registry.Session {
+	var sessions []registry.Session
+	for _, project := range result.Projects {
+		for _, wt := range project.Worktrees {
+			if wt.IsMissing {
+				// Their worktree is gone, so they cannot be recreated.
+				continue
+			}
+			for _, s := range wt.Sessions {
+				entry := registry.Session{
+					Name:      s.Name,
+					HomePath:  wt.Path,
+					Windows:   make([]registry.Window, 0, len(s.Windows)),
+					UpdatedAt: now,
+				}
+				for _, w := range s.Windows {
+					window := registry.Window{Index: w.Index, Name: w.Name}
+					if agent, ok := result.WindowAgents[w.Target(s.Name)]; ok && agent != tmux.AgentNone {
+						window.Agent = string(agent)
+					}
+					entry.Windows = append(entry.Windows, window)
+				}
+				sessions = append(sessions, entry)
+			}
+		}
+	}
+	return sessions
+}
+
+// recordingDiscoverer records live sessions into the registry after each
+// successful discovery, writing only when the recorded shape changes, and
+// folds session statuses into the activity store (see cb stats).
+type recordingDiscoverer struct {
+	inner interface {
+		Discover() (discovery.Result, error)
+	}
+	store    *registry.Store
+	activity *activity.Store
+	layouts  func(session string) (map[int]tmux.WindowLayout, error)
+	last     []registry.Session
+}
+
+func (d *recordingDiscoverer) Discover() (discovery.Result, error) {
+	return d.record(d.inner.Discover())
+}
+
+// DiscoverContext is Discover with the inner discovery bounded by ctx.
+func (d *recordingDiscoverer) DiscoverContext(ctx context.Context) (discovery.Result, error) {
+	return d.record(discovery.DiscoverWithContext(ctx, d.inner))
+}
+
+func (d *recordingDiscoverer) record(result discovery.Result, err error) (discovery.Result, error) {
+	if err == nil && d.activity != nil {
+		recordActivity(d.activity, result, time.Now())
+	}
+	if err != nil || d.store == nil {
+		return result, err
+	}
+
+	sessions := registrySessionsFromDiscovery(result, time.Now())
+	if d.layouts != nil {
+		applyWindowLayouts(sessions, d.layouts)
+	}
+	if sameRegistryShape(sessions, d.last) {
+		return result, nil
+	}
+	if upsertErr := d.store.Upsert(sessions...); upsertErr != nil {
+		slog.Debug("failed to record sessions", "err", upsertErr)
+		return result, nil
+	}
+	d.last = sessions
+	return result, nil
+}
+
+// applyWindowLayouts fills in pane counts and layouts for multi-pane windows.
+// Sessions whose layouts cannot be read keep their windows unchanged.
+func applyWindowLayouts(sessions []registry.Session, layouts func(session string) (map[int]tmux.WindowLayout, error)) {
+	for i := range sessions {
+		byIndex, err := layouts(sessions[i].Name)
+		if err != nil {
+			continue
+		}
+		for j := range sessions[i].Windows {
+			layout, ok := byIndex[sessions[i].Windows[j].Index]
+			if !ok || layout.Panes <= 1 {
+				continue
+			}
+			sessions[i].Windows[j].Panes = layout.Panes
+			sessions[i].Windows[j].Layout = layout.Layout
+		}
+	}
+}
+
+func sameRegistryShape(a, b []registry.Session) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].HomePath != b[i].HomePath || !reflect.DeepEqual(a[i].Windows, b[i].Windows) {
+			return false
+		}
+	}
+	return true
+}
+
+// newRecordingDiscoverer wraps a discovery service so that its results keep
+// the session registry and activity store current. Store errors only disable
+// recording.
+// Discovery's git commands are killed once ctx is done.
+func newRecordingDiscoverer(ctx context.Context, tmuxClient *tmux.Client) *recordingDiscoverer {
+	store, err := sessionRegistry()
+	if err != nil {
+		slog.Debug("session registry unavailable", "err", err)
+	}
+	tracker, err := activityStore()
+	if err != nil {
+		slog.Debug("activity store unavailable", "err", err)
+	}
+	return &recordingDiscoverer{
+		inner:    discovery.NewServiceWithContext(ctx, tmuxClient),
+		store:    store,
+		activity: tracker,
+		layouts:  tmuxClient.ListWindowLayouts,
+	}
+}
+
+// forgetSession drops a session from the registry, warning on failure.
+func forgetSession(sessionName string, errWriter io.Writer) {
+	store, err := sessionRegistry()
+	if err == nil {
+		err = store.Remove(sessionName)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(errWriter, "Warning: failed to remove %s from session registry: %v\n", sessionName, err)
+	}
+}
+
+type restoreTmuxClient interface {
+	HasSession(name string) bool
+	CreateSession(name, workdir string) error
+	SetSessionOption(session, key, value string) error
+	windowBuilderClient
+}
+
+// agentLaunchCommand returns the shell command that starts agent, or "" when
+// the agent type is unknown.
+func agentLaunchCommand(agent string) string {
+	return tmux.AgentType(agent).LaunchCommand()
+}
+
+// agentResumeCommand returns the shell command that starts agent on its most
+// recent conversation, or "" when the agent type is unknown.
+func agentResumeCommand(agent string) string {
+	return tmux.AgentType(agent).ResumeCommand()
+}
+
+// restoreSession recreates sess in tmux. The first recorded window reuses the
+// session's initial window; the rest are created in order. Recorded pane
+// splits are recreated and their layout reapplied. Agents are relaunched with
+// the command launch returns for them; a nil launch leaves them out.
+func restoreSession(tmuxClient restoreTmuxClient, sess registry.Session, launch func(agent string) string) error {
+	if err := tmuxClient.CreateSession(sess.Name, sess.HomePath); err != nil {
+		return err
+	}
+	if err := tmuxClient.SetSessionOption(sess.Name, tmux.SessionOptionHomePath, sess.HomePath); err != nil {
+		return err
+	}
+
+	return buildSessionWindows(tmuxClient, sess.Name, sess.HomePath, restoreWindowSpecs(sess.Windows, launch))
+}
+
+// restoreWindowSpecs orders recorded windows by index and converts them to
+// window specs, placing the agent command (if relaunching) in the first pane.
+func restoreWindowSpecs(windows []registry.Window, launch func(agent string) string) []windowSpec {
+	sorted := append([]registry.Window(nil), windows...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+
+	specs := make([]windowSpec, 0, len(sorted))
+	for _, w := range sorted {
+		panes := make([]string, max(w.Panes, 1))
+		if launch != nil && w.Agent != "" {
+			panes[0] = launch(w.Agent)
+		}
+		specs = append(specs, windowSpec{Name: w.Name, Layout: w.Layout, Panes: panes})
+	}
+	return specs
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	store, err := sessionRegistry()
+	if err != nil {
+		return err
+	}
+	sessions, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(sessions) == 0 {
+		_, _ = fmt.Fprintf(out, "No recorded sessions in %s.\n", store.Path())
+		return nil
+	}
+
+	var launch func(agent string) string
+	switch {
+	case restoreResume:
+		launch = agentResumeCommand
+	case restoreAgents:
+		launch = agentLaunchCommand
+	}
+
+	tmuxClient := tmux.NewClient()
+	failures := 0
+	for _, sess := range sessions {
+		switch {
+		case tmuxClient.HasSession(sess.Name):
+			_, _ = fmt.Fprintf(out, "  %-30s already running\n", sess.Name)
+		case !dirExists(sess.HomePath):
+			_, _ = fmt.Fprintf(out, "  %-30s skipped (missing worktree %s)\n", sess.Name, sess.HomePath)
+		case restoreDryRun:
+			_, _ = fmt.Fprintf(out, "  %-30s would restore %d window(s) in %s\n", sess.Name, len(sess.Windows), sess.HomePath)
+		default:
+			if err := restoreSession(tmuxClient, sess, launch); err != nil {
+				failures++
+				_, _ = fmt.Fprintf(out, "  %-30s failed (%v)\n", sess.Name, err)
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "  %-30s restored\n", sess.Name)
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("failed to restore %d session(s)", failures)
+	}
+	return nil
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/registry"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeRestoreTmuxClient struct {
+	calls []string
+}
+
+func (f *fakeRestoreTmuxClient) HasSession(name string) bool { return false }
+
+func (f *fakeRestoreTmuxClient) CreateSession(name, workdir string) error {
+	f.calls = append(f.calls, "create "+name+" "+workdir)
+	return nil
+}
+
+func (f *fakeRestoreTmuxClient) SetSessionOption(session, key, value string) error {
+	f.calls = append(f.calls, "option "+session+" "+key+" "+value)
+	return nil
+}
+
+func (f *fakeRestoreTmuxClient) RenameWindow(session, name string) error {
+	f.calls = append(f.calls, "rename "+session+" "+name)
+	return nil
+}
+
+func (f *fakeRestoreTmuxClient) CreateWindowWithShellInDir(session, name, command, workdir string) error {
+	f.calls = append(f.calls, strings.TrimSpace("window "+session+" "+name+" "+workdir+" "+command))
+	return nil
+}
+
+func (f *fakeRestoreTmuxClient) SendCommand(session, window, command string) error {
+	f.calls = append(f.calls, "send "+session+":"+window+" "+command)
+	return nil
+}
+
+func (f *fakeRestoreTmuxClient) SplitWindow(session, window, workdir string) (string, error) {
+	f.calls = append(f.calls, "split "+session+":"+window+" "+workdir)
+	return "%1", nil
+}
+
+func (f *fakeRestoreTmuxClient) SendCommandToPane(target, command string) error {
+	f.calls = append(f.calls, "send "+target+" "+command)
+	return nil
+}
+
+func (f *fakeRestoreTmuxClient) SelectLayout(session, window, layout string) error {
+	f.calls = append(f.calls, "layout "+session+":"+window+" "+layout)
+	return nil
+}
+
+func TestRegistrySessionsFromDiscovery(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := discovery.Result{
+		Projects: []discovery.ProjectNode{{
+			Name: "repo",
+			Worktrees: []discovery.WorktreeNode{
+				{Name: "(main repo)", Path: "/repo", IsMainRepo: true},
+				{Name: ".worktrees/repo-feat", Path: "/repo/.worktrees/repo-feat", Sessions: []discovery.SessionNode{{
+					Name:    "cb_feat",
+					Windows: []tmux.Window{{ID: "@3", Index: 0, Name: "shell"}, {ID: "@4", Index: 1, Name: "agent"}},
+				}}},
+			},
+		}},
+		WindowAgents: map[string]tmux.AgentType{"@4": tmux.AgentCodex},
+	}
+
+	got := registrySessionsFromDiscovery(result, now)
+	want := []registry.Session{{
+		Name:      "cb_feat",
+		HomePath:  "/repo/.worktrees/repo-feat",
+		Windows:   []registry.Window{{Index: 0, Name: "shell"}, {Index: 1, Name: "agent", Agent: "codex"}},
+		UpdatedAt: now,
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("registrySessionsFromDiscovery() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRestoreSession_RecreatesWindowsAndAgents(t *testing.T) {
+	client := &fakeRestoreTmuxClient{}
+	sess := registry.Session{
+		Name:     "cb_feat",
+		HomePath: "/wt",
+		Windows: []registry.Window{
+			{Index: 2, Name: "codex", Agent: "codex"},
+			{Index: 0, Name: "claude", Agent: "claude"},
+			{Index: 1, Name: "shell", Panes: 3, Layout: "abcd,80x24,0,0[...]"},
+		},
+	}
+
+	if err := restoreSession(client, sess, agentLaunchCommand); err != nil {
+		t.Fatalf("restoreSession() error = %v", err)
+	}
+
+	want := []string{
+		"create cb_feat /wt",
+		"option cb_feat " + tmux.SessionOptionHomePath + " /wt",
+		"rename cb_feat claude",
+		"send cb_feat:claude claude",
+		"window cb_feat shell /wt",
+		"split cb_feat:shell /wt",
+		"split cb_feat:shell /wt",
+		"layout cb_feat:shell abcd,80x24,0,0[...]",
+		"window cb_feat codex /wt codex",
+	}
+	if !reflect.DeepEqual(client.calls, want) {
+		t.Fatalf("calls = %v, want %v", client.calls, want)
+	}
+}
+
+func TestRestoreSession_ResumesAgents(t *testing.T) {
+	client := &fakeRestoreTmuxClient{}
+	sess := registry.Session{
+		Name:     "cb_feat",
+		HomePath: "/wt",
+		Windows: []registry.Window{
+			{Index: 0, Name: "claude", Agent: "claude"},
+			{Index: 1, Name: "codex", Agent: "codex"},
+		},
+	}
+
+	if err := restoreSession(client, sess, agentResumeCommand); err != nil {
+		t.Fatalf("restoreSession() error = %v", err)
+	}
+	want := []string{
+		"create cb_feat /wt",
+		"option cb_feat " + tmux.SessionOptionHomePath + " /wt",
+		"rename cb_feat claude",
+		"send cb_feat:claude claude --continue",
+		"window cb_feat codex /wt codex resume --last",
+	}
+	if !reflect.DeepEqual(client.calls, want) {
+		t.Fatalf("calls = %v, want %v", client.calls, want)
+	}
+}
+
+func TestRestoreSession_WithoutAgents(t *testing.T) {
+	client := &fakeRestoreTmuxClient{}
+	sess := registry.Session{Name: "cb_feat", HomePath: "/wt", Windows: []registry.Window{{Index: 0, Name: "claude", Agent: "claude"}}}
+
+	if err := restoreSession(client, sess, nil); err != nil {
+		t.Fatalf("restoreSession() error = %v", err)
+	}
+	for _, call := range client.calls {
+		if strings.HasPrefix(call, "send ") {
+			t.Fatalf("agent should not be relaunched, got %q", call)
+		}
+	}
+}
+
+type staticDiscoverer struct {
+	result discovery.Result
+}
+
+func (d staticDiscoverer) Discover() (discovery.Result, error) { return d.result, nil }
+
+func TestRecordingDiscoverer_UpsertsLiveSessions(t *testing.T) {
+	store := registry.NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+	if err := store.Upsert(registry.Session{Name: "cb_offline", HomePath: "/old"}); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	d := &recordingDiscoverer{
+		inner: staticDiscoverer{result: discovery.Result{Projects: []discovery.ProjectNode{{
+			Worktrees: []discovery.WorktreeNode{{Path: "/wt", Sessions: []discovery.SessionNode{{Name: "cb_live"}}}},
+		}}}},
+		store: store,
+	}
+	if _, err := d.Discover(); err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	sessions, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].Name != "cb_live" || sessions[1].Name != "cb_offline" {
+		t.Fatalf("sessions = %+v, want cb_live and cb_offline", sessions)
+	}
+}
+
+func TestAgentLaunchCommand(t *testing.T) {
+	tests := map[string]string{
+		"claude":    "claude",
+		"codex":     "codex",
+		"open_code": "opencode",
+		"":          "",
+		"none":      "",
+	}
+	for agent, want := range tests {
+		if got := agentLaunchCommand(agent); got != want {
+			t.Errorf("agentLaunchCommand(%q) = %q, want %q", agent, got, want)
+		}
+	}
+}
+
+func TestApplyWindowLayouts(t *testing.T) {
+	sessions := []registry.Session{
+		{Name: "cb_a", Windows: []registry.Window{{Index: 0, Name: "shell"}, {Index: 1, Name: "split"}}},
+		{Name: "cb_gone", Windows: []registry.Window{{Index: 0, Name: "shell"}}},
+	}
+	layouts := func(session string) (map[int]tmux.WindowLayout, error) {
+		if session == "cb_gone" {
+			return nil, errors.New("no such session")
+		}
+		return map[int]tmux.WindowLayout{
+			0: {Panes: 1, Layout: "single"},
+			1: {Panes: 2, Layout: "split-layout"},
+		}, nil
+	}
+
+	applyWindowLayouts(sessions, layouts)
+
+	if w := sessions[0].Windows[0]; w.Panes != 0 || w.Layout != "" {
+		t.Fatalf("single-pane window should not record a layout, got %+v", w)
+	}
+	if w := sessions[0].Windows[1]; w.Panes != 2 || w.Layout != "split-layout" {
+		t.Fatalf("split window = %+v, want 2 panes with layout", w)
+	}
+	if w := sessions[1].Windows[0]; w.Panes != 0 {
+		t.Fatalf("unreadable session should be unchanged, got %+v", w)
+	}
+}
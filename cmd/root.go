@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"runtime"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/ronsanzone/clawd-bay/internal/agentconfig"
+	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/logging"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +21,10 @@ import (
 var Version = "0.2.0"
 
 var debug bool
+var logCommands bool
+var themeName string
+var noColor bool
+var asciiMode bool
 
 var rootCmd = &cobra.Command{
 	Use:     "cb",
@@ -22,9 +34,17 @@ var rootCmd = &cobra.Command{
 
 Create isolated git worktree workflows and track session status
 from an interactive dashboard.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		logging.Setup(debug)
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		logging.Setup(debug, logCommands, debugLogPath())
+		if colorDisabled() {
+			lipgloss.SetColorProfile(termenv.Ascii)
+		}
+		agentconfig.Apply()
 		slog.Debug("cb starting", "command", cmd.Name(), "debug", debug)
+		if err := checkPlatform(runtime.GOOS); err != nil {
+			return err
+		}
+		return checkTmuxVersion(cmd, tmux.NewClient())
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Default to dashboard
@@ -37,12 +57,100 @@ from an interactive dashboard.`,
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug logging")
+	rootCmd.PersistentFlags().BoolVar(&logCommands, "log-commands", false, "record every tmux/git command with its duration and exit status in the debug log (implied by --debug; see cb debug timings)")
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "", "dashboard colors: dark, light, or auto (overrides the theme config key)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colors and styling (also set by the NO_COLOR environment variable)")
+	rootCmd.PersistentFlags().BoolVar(&asciiMode, "ascii", false, "draw the dashboard with ASCII characters only (overrides the ascii config key)")
+}
+
+// colorDisabled reports whether output must be unstyled, via --no-color or a
+// non-empty NO_COLOR (https://no-color.org).
+func colorDisabled() bool {
+	return noColor || os.Getenv("NO_COLOR") != ""
+}
+
+// debugLogPath returns the debug log in the state directory, or in the temp
+// directory when the home directory is unknown.
+func debugLogPath() string {
+	c, err := config.New()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "cb-debug.log")
+	}
+	return c.DebugLogPath()
+}
+
+// errNativeWindows explains how to run cb on Windows, where tmux and the Unix
+// tools it shells out to (ps, a POSIX shell) are missing.
+var errNativeWindows = errors.New("cb needs tmux, which does not run natively on Windows; install WSL (wsl --install), then install and run cb inside your WSL distribution")
+
+// checkPlatform fails early on platforms cb cannot drive instead of letting
+// the first tmux call fail with "executable file not found".
+func checkPlatform(goos string) error {
+	if goos == "windows" {
+		return errNativeWindows
+	}
+	return nil
+}
+
+// tmuxlessCommands are top-level commands that work without tmux, so an old
+// tmux does not block them.
+var tmuxlessCommands = map[string]bool{"project": true, "tmux-install": true, "debug": true, "completion": true, "help": true}
+
+// checkTmuxVersion fails early with an upgrade hint when the installed tmux is
+// older than tmux.MinVersion, instead of letting commands hit format or
+// option errors later. A missing or unrecognized tmux is left to the
+// commands themselves.
+func checkTmuxVersion(cmd *cobra.Command, client interface {
+	CheckVersion() (tmux.Version, error)
+}) error {
+	top := cmd
+	for top.HasParent() && top.Parent().HasParent() {
+		top = top.Parent()
+	}
+	if tmuxlessCommands[top.Name()] {
+		return nil
+	}
+	version, err := client.CheckVersion()
+	if errors.Is(err, tmux.ErrUnsupportedVersion) {
+		return err
+	}
+	slog.Debug("tmux version", "version", version, "err", err)
+	return nil
+}
+
+// exitCodeError is a command error that exits with a specific status
+// instead of the default 1, for scripts that branch on the outcome. A nil err
+// reports an outcome rather than a failure and exits without a message.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// exitCode returns the process exit status for a command error.
+func exitCode(err error) int {
+	var codeErr *exitCodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.code
+	}
+	return 1
 }
 
 // Execute runs the root command.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		var codeErr *exitCodeError
+		if !errors.As(err, &codeErr) || codeErr.err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
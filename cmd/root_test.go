@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeVersionChecker struct {
+	err   error
+	calls int
+}
+
+func (f *fakeVersionChecker) CheckVersion() (tmux.Version, error) {
+	f.calls++
+	return tmux.Version{Major: 2, Minor: 9}, f.err
+}
+
+func TestCheckTmuxVersion(t *testing.T) {
+	tooOld := fmt.Errorf("%w: tmux 2.9", tmux.ErrUnsupportedVersion)
+
+	old := &fakeVersionChecker{err: tooOld}
+	if err := checkTmuxVersion(listCmd, old); !errors.Is(err, tmux.ErrUnsupportedVersion) {
+		t.Fatalf("checkTmuxVersion(list) error = %v, want ErrUnsupportedVersion", err)
+	}
+
+	skipped := &fakeVersionChecker{err: tooOld}
+	if err := checkTmuxVersion(projectAddCmd, skipped); err != nil || skipped.calls != 0 {
+		t.Fatalf("checkTmuxVersion(project add) = %v after %d checks, want nil without checking", err, skipped.calls)
+	}
+
+	missing := &fakeVersionChecker{err: errors.New("exec: tmux: not found")}
+	if err := checkTmuxVersion(listCmd, missing); err != nil {
+		t.Fatalf("checkTmuxVersion() with tmux missing error = %v, want nil", err)
+	}
+}
+
+func TestCheckPlatform(t *testing.T) {
+	if err := checkPlatform("windows"); !errors.Is(err, errNativeWindows) {
+		t.Fatalf("checkPlatform(windows) error = %v, want errNativeWindows", err)
+	}
+	for _, goos := range []string{"linux", "darwin"} {
+		if err := checkPlatform(goos); err != nil {
+			t.Fatalf("checkPlatform(%s) error = %v", goos, err)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/agentconfig"
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runPrompt       string
+	runAgent        string
+	runTemplate     string
+	runSplit        string
+	runWaitForAgent bool
+	runTimeout      time.Duration
+	runPurpose      string
+)
+
+// runPollInterval is how often cb run re-checks the agent's status.
+const runPollInterval = 2 * time.Second
+
+// runSettleGrace is how long after the prompt is sent an agent never seen
+// WORKING still counts as settled once it is WAITING, IDLE, or exited: a
+// short task can start and finish between two polls.
+const runSettleGrace = 30 * time.Second
+
+// runReadyTimeout bounds how long cb run waits for the agent to start and
+// show its prompt before sending the prompt text.
+const runReadyTimeout = 60 * time.Second
+
+var runCmd = &cobra.Command{
+	Use:   "run <branch-name>",
+	Short: "Start a workflow, launch an agent, and send it a prompt",
+	Long: `Creates a worktree and detached tmux session like cb start --detach,
+launches a coding agent in a new window, and sends it the prompt once the agent
+is ready. With --wait, blocks until the agent stops working (WAITING or DONE)
+and prints a summary, which makes it usable from scripts and CI.
+
+With --split, the agent window gets a second pane below the agent running the
+given command (--split "" opens a plain shell), for a test runner or log tail
+next to the agent.
+
+--purpose names the agent window by the agent_window_name scheme in the
+config file ("claude-review" by default) instead of after the agent alone.
+
+Example:
+  cb run fix-login --prompt "Fix the login redirect bug"
+  cb run fix-login --prompt "Add tests for auth" --wait --timeout 30m
+  cb run fix-login --agent codex --prompt "Update the changelog"
+  cb run fix-login --prompt "Make the tests pass" --split "go test ./... -count=1"
+  cb run fix-login --prompt "Review the diff" --purpose review`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRun,
+}
+
+func init() {
+	runCmd.Flags().StringVarP(&runPrompt, "prompt", "p", "", "Prompt to send to the agent (required)")
+	runCmd.Flags().StringVar(&runAgent, "agent", string(tmux.AgentClaude), "Agent to launch: claude, codex, or opencode")
+	runCmd.Flags().StringVarP(&runTemplate, "template", "t", "", "Create windows from the named session template")
+	runCmd.Flags().StringVar(&runSplit, "split", "", "Split the agent window and run this command in the second pane (\"\" for a shell)")
+	runCmd.Flags().BoolVar(&runWaitForAgent, "wait", false, "Block until the agent is WAITING or DONE and print a summary")
+	runCmd.Flags().DurationVar(&runTimeout, "timeout", 0, "Give up waiting after this long (0 means no limit)")
+	runCmd.Flags().StringVar(&runPurpose, "purpose", "", "Name the agent window for this purpose (see agent_window_name)")
+	rootCmd.AddCommand(runCmd)
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	prompt := strings.TrimSpace(runPrompt)
+	if prompt == "" {
+		return fmt.Errorf("--prompt is required")
+	}
+	agent, err := agentconfig.ParseAgentName(runAgent)
+	if err != nil {
+		return err
+	}
+	purpose, err := parseAgentPurpose(runPurpose)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	tmuxClient := tmux.NewClient()
+	wf, err := createWorkflow(tmuxClient, workflowSpec{
+		Branch:   args[0],
+		Template: runTemplate,
+		Agent:    agent,
+		Out:      out,
+	})
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	target, err := launchAgentWindow(tmuxClient, wf.Session, wf.WorktreeDir, agent, agentWindowNaming{Scheme: cfg.AgentWindowName, Purpose: purpose})
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("split") {
+		if err := splitAgentWindow(tmuxClient, wf.Session, target, wf.WorktreeDir, runSplit); err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Launched %s in %s, waiting for it to be ready...\n", agent.LaunchCommand(), wf.Session)
+
+	detect := func() (tmux.AgentInfo, error) { return tmuxClient.DetectAgentInfo(target), nil }
+	if _, err := pollUntil(newStatusPoller(runReadyTimeout), detect, agentReady); err != nil {
+		return fmt.Errorf("%s did not become ready in %s: %w", agent.LaunchCommand(), wf.Session, err)
+	}
+	if err := tmuxClient.SendText(target, prompt); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Prompt sent to %s\n", wf.Session)
+
+	if !runWaitForAgent {
+		_, _ = fmt.Fprintf(out, "Attach with: tmux attach -t %s\n", wf.Session)
+		return nil
+	}
+
+	info, err := pollUntil(newStatusPoller(runTimeout), detect, agentSettled(time.Now))
+	if err != nil {
+		return fmt.Errorf("%s in %s did not finish (last status %s): %w", agent.LaunchCommand(), wf.Session, info.Status, err)
+	}
+
+	summary := runSummary{
+		Session:     wf.Session,
+		WorktreeDir: wf.WorktreeDir,
+		Agent:       agent,
+		Status:      info.Status,
+		Elapsed:     time.Since(started),
+	}
+	gitClient := git.NewClient()
+	if cwd, err := os.Getwd(); err == nil {
+		if base, err := gitClient.CurrentBranch(cwd); err == nil && base != "" {
+			if stat, _, err := gitClient.NumStatAgainst(wf.WorktreeDir, base); err == nil {
+				summary.Diff = &stat
+			}
+		}
+	}
+	writeRunSummary(out, summary)
+	return nil
+}
+
+type runTmuxClient interface {
+	CreateWindowWithShellInDir(session, name, command, workdir string) error
+	ListWindows(session string) ([]tmux.Window, error)
+	SetWindowOption(target, key, value string) error
+}
+
+// agentWindowNaming names agent windows by the agent_window_name scheme.
+// The zero value names them after the agent's command.
+type agentWindowNaming struct {
+	Scheme  string
+	Purpose string
+}
+
+func (n agentWindowNaming) name(agent tmux.AgentType) string {
+	return config.RenderAgentWindowName(n.Scheme, agent.LaunchCommand(), n.Purpose)
+}
+
+// launchAgentWindow opens a window named by naming in session, starts the
+// agent in it, and returns the window's target. A window started for a
+// purpose records it (tmux.WindowOptionPurpose).
+func launchAgentWindow(client runTmuxClient, session, workdir string, agent tmux.AgentType, naming agentWindowNaming) (string, error) {
+	name := naming.name(agent)
+	if err := client.CreateWindowWithShellInDir(session, name, agent.LaunchCommand(), workdir); err != nil {
+		return "", err
+	}
+	windows, err := client.ListWindows(session)
+	if err != nil {
+		return "", err
+	}
+	// The new window is the highest-indexed one with this name.
+	for i := len(windows) - 1; i >= 0; i-- {
+		if windows[i].Name != name {
+			continue
+		}
+		target := windows[i].Target(session)
+		if naming.Purpose != "" {
+			if err := client.SetWindowOption(target, tmux.WindowOptionPurpose, naming.Purpose); err != nil {
+				slog.Debug("launchAgentWindow: failed to record purpose", "target", target, "err", err)
+			}
+		}
+		return target, nil
+	}
+	return "", fmt.Errorf("window %s not found in session %s after creating it", name, session)
+}
+
+type paneSplitter interface {
+	SplitWindow(session, window, workdir string) (string, error)
+	SendCommandToPane(target, command string) error
+}
+
+// splitAgentWindow adds a pane to the agent window at target, in workdir,
+// and types command into it ("" leaves a plain shell). The new pane is
+// created in the background, so the agent keeps focus and receives prompts.
+func splitAgentWindow(client paneSplitter, session, target, workdir, command string) error {
+	window := strings.TrimPrefix(target, session+":")
+	paneID, err := client.SplitWindow(session, window, workdir)
+	if err != nil {
+		return err
+	}
+	if command == "" {
+		return nil
+	}
+	return client.SendCommandToPane(paneID, command)
+}
+
+// errPollTimeout is returned by pollUntil when the poller's timeout elapses.
+var errPollTimeout = errors.New("timed out")
+
+// statusPoller paces repeated status checks.
+type statusPoller struct {
+	Interval time.Duration
+	// Timeout bounds the whole poll; zero means no limit.
+	Timeout time.Duration
+	Now     func() time.Time
+	Sleep   func(time.Duration)
+}
+
+func newStatusPoller(timeout time.Duration) statusPoller {
+	return statusPoller{Interval: runPollInterval, Timeout: timeout, Now: time.Now, Sleep: time.Sleep}
+}
+
+// pollUntil calls detect until done reports true for its result and returns
+// that result. It stops early with detect's error, or with errPollTimeout and
+// the last result once the poller's timeout elapses.
+func pollUntil[T any](p statusPoller, detect func() (T, error), done func(T) bool) (T, error) {
+	deadline := time.Time{}
+	if p.Timeout > 0 {
+		deadline = p.Now().Add(p.Timeout)
+	}
+	for {
+		v, err := detect()
+		if err != nil {
+			return v, err
+		}
+		if done(v) {
+			return v, nil
+		}
+		if !deadline.IsZero() && !p.Now().Before(deadline) {
+			return v, fmt.Errorf("%w after %s", errPollTimeout, p.Timeout)
+		}
+		p.Sleep(p.Interval)
+	}
+}
+
+// agentReady reports whether an agent has started and is neither busy nor
+// failed, so a prompt typed now reaches its input box.
+func agentReady(info tmux.AgentInfo) bool {
+	return info.Detected && info.Status != tmux.StatusWorking && info.Status != tmux.StatusError
+}
+
+// agentSettled returns a condition that holds once the agent has worked on
+// the prompt sent just before and stopped: it failed, or it is WAITING, IDLE,
+// or exited after being seen WORKING or after runSettleGrace (by now) has
+// passed, so a task finished between two polls still settles.
+func agentSettled(now func() time.Time) func(tmux.AgentInfo) bool {
+	sawWorking := false
+	graceEnds := now().Add(runSettleGrace)
+	return func(info tmux.AgentInfo) bool {
+		if info.Detected && info.Status == tmux.StatusError {
+			return true
+		}
+		if info.Detected && info.Status == tmux.StatusWorking {
+			sawWorking = true
+			return false
+		}
+		return sawWorking || !now().Before(graceEnds)
+	}
+}
+
+// runSummary is printed by cb run --wait once the agent settles.
+type runSummary struct {
+	Session     string
+	WorktreeDir string
+	Agent       tmux.AgentType
+	Status      tmux.Status
+	Elapsed     time.Duration
+	// Diff is nil when the diff against the base branch is unavailable.
+	Diff *git.DiffStat
+}
+
+func writeRunSummary(w io.Writer, s runSummary) {
+	_, _ = fmt.Fprintf(w, "Session:  %s\n", s.Session)
+	_, _ = fmt.Fprintf(w, "Worktree: %s\n", s.WorktreeDir)
+	_, _ = fmt.Fprintf(w, "Agent:    %s\n", s.Agent.LaunchCommand())
+	_, _ = fmt.Fprintf(w, "Status:   %s\n", s.Status)
+	_, _ = fmt.Fprintf(w, "Elapsed:  %s\n", s.Elapsed.Round(time.Second))
+	if s.Diff != nil {
+		_, _ = fmt.Fprintf(w, "Changes:  +%d −%d (%d files)\n", s.Diff.Insertions, s.Diff.Deletions, s.Diff.Files)
+	}
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeRunTmuxClient struct {
+	created []string
+	windows []tmux.Window
+	options []string
+}
+
+func (f *fakeRunTmuxClient) CreateWindowWithShellInDir(session, name, command, workdir string) error {
+	f.created = append(f.created, session+" "+name+" "+command+" "+workdir)
+	f.windows = append(f.windows, tmux.Window{ID: "@9", Index: len(f.windows), Name: name})
+	return nil
+}
+
+func (f *fakeRunTmuxClient) ListWindows(session string) ([]tmux.Window, error) {
+	return f.windows, nil
+}
+
+func (f *fakeRunTmuxClient) SetWindowOption(target, key, value string) error {
+	f.options = append(f.options, target+" "+key+" "+value)
+	return nil
+}
+
+type fakePaneSplitter struct {
+	calls []string
+}
+
+func (f *fakePaneSplitter) SplitWindow(session, window, workdir string) (string, error) {
+	f.calls = append(f.calls, "split "+session+":"+window+" "+workdir)
+	return "%7", nil
+}
+
+func (f *fakePaneSplitter) SendCommandToPane(target, command string) error {
+	f.calls = append(f.calls, "send "+target+" "+command)
+	return nil
+}
+
+func TestSplitAgentWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		command string
+		want    []string
+	}{
+		{"runs the command", "@9", "go test ./...", []string{"split cb_feat:@9 /wt", "send %7 go test ./..."}},
+		{"plain shell", "@9", "", []string{"split cb_feat:@9 /wt"}},
+		{"index target", "cb_feat:2", "make watch", []string{"split cb_feat:2 /wt", "send %7 make watch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakePaneSplitter{}
+			if err := splitAgentWindow(client, "cb_feat", tt.target, "/wt", tt.command); err != nil {
+				t.Fatalf("splitAgentWindow() error = %v", err)
+			}
+			if !reflect.DeepEqual(client.calls, tt.want) {
+				t.Fatalf("calls = %v, want %v", client.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaunchAgentWindow(t *testing.T) {
+	client := &fakeRunTmuxClient{windows: []tmux.Window{{ID: "@1", Index: 0, Name: "codex"}}}
+
+	target, err := launchAgentWindow(client, "cb_feat", "/wt", tmux.AgentCodex, agentWindowNaming{})
+	if err != nil {
+		t.Fatalf("launchAgentWindow() error = %v", err)
+	}
+	if target != "@9" {
+		t.Fatalf("target = %q, want the new window @9", target)
+	}
+	if len(client.created) != 1 || client.created[0] != "cb_feat codex codex /wt" {
+		t.Fatalf("created = %v", client.created)
+	}
+	if len(client.options) != 0 {
+		t.Fatalf("options = %v, want none without a purpose", client.options)
+	}
+
+	naming := agentWindowNaming{Scheme: "{agent}/{purpose}", Purpose: "review"}
+	if _, err := launchAgentWindow(client, "cb_feat", "/wt", tmux.AgentCodex, naming); err != nil {
+		t.Fatalf("launchAgentWindow() with purpose error = %v", err)
+	}
+	if len(client.created) != 2 || client.created[1] != "cb_feat codex/review codex /wt" {
+		t.Fatalf("created = %v, want a codex/review window running codex", client.created)
+	}
+	if len(client.options) != 1 || client.options[0] != "@9 @cb_purpose review" {
+		t.Fatalf("options = %v, want the purpose recorded on the new window", client.options)
+	}
+}
+
+// fakeClock advances only when the poller sleeps.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time        { return c.now }
+func (c *fakeClock) Sleep(d time.Duration) { c.now = c.now.Add(d) }
+
+func sequenceDetector(statuses ...tmux.Status) func() (tmux.AgentInfo, error) {
+	i := 0
+	return func() (tmux.AgentInfo, error) {
+		s := statuses[min(i, len(statuses)-1)]
+		i++
+		if s == tmux.StatusDone {
+			return tmux.AgentInfo{Type: tmux.AgentNone, Status: tmux.StatusDone}, nil
+		}
+		return tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: s}, nil
+	}
+}
+
+func TestPollUntil_AgentSettled(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []tmux.Status
+		timeout  time.Duration
+		want     tmux.Status
+		wantErr  bool
+	}{
+		{name: "waiting after work", statuses: []tmux.Status{tmux.StatusWaiting, tmux.StatusWorking, tmux.StatusWorking, tmux.StatusWaiting}, want: tmux.StatusWaiting},
+		{name: "idle after work", statuses: []tmux.Status{tmux.StatusWorking, tmux.StatusIdle}, want: tmux.StatusIdle},
+		{name: "agent exited", statuses: []tmux.Status{tmux.StatusWorking, tmux.StatusDone}, want: tmux.StatusDone},
+		{name: "agent failed before working", statuses: []tmux.Status{tmux.StatusIdle, tmux.StatusError}, want: tmux.StatusError},
+		{name: "not seen working within the timeout", statuses: []tmux.Status{tmux.StatusIdle}, want: tmux.StatusIdle, wantErr: true},
+		{name: "idle past the grace period", statuses: []tmux.Status{tmux.StatusIdle}, timeout: time.Minute, want: tmux.StatusIdle},
+		{name: "exited past the grace period", statuses: []tmux.Status{tmux.StatusDone}, timeout: time.Minute, want: tmux.StatusDone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+			timeout := cmp.Or(tt.timeout, 10*time.Second)
+			p := statusPoller{Interval: time.Second, Timeout: timeout, Now: clock.Now, Sleep: clock.Sleep}
+
+			info, err := pollUntil(p, sequenceDetector(tt.statuses...), agentSettled(clock.Now))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pollUntil() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errPollTimeout) {
+				t.Fatalf("pollUntil() error = %v, want errPollTimeout", err)
+			}
+			if info.Status != tt.want {
+				t.Fatalf("status = %s, want %s", info.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentReady(t *testing.T) {
+	if agentReady(tmux.AgentInfo{Status: tmux.StatusDone}) {
+		t.Fatal("undetected agent should not be ready")
+	}
+	if agentReady(tmux.AgentInfo{Detected: true, Status: tmux.StatusWorking}) {
+		t.Fatal("working agent should not be ready")
+	}
+	if agentReady(tmux.AgentInfo{Detected: true, Status: tmux.StatusError}) {
+		t.Fatal("failed agent should not be ready")
+	}
+	if !agentReady(tmux.AgentInfo{Detected: true, Status: tmux.StatusWaiting}) {
+		t.Fatal("agent at its prompt should be ready")
+	}
+}
+
+func TestWriteRunSummary(t *testing.T) {
+	var buf bytes.Buffer
+	writeRunSummary(&buf, runSummary{
+		Session:     "cb_feat",
+		WorktreeDir: "/wt",
+		Agent:       tmux.AgentOpenCode,
+		Status:      tmux.StatusWaiting,
+		Elapsed:     83*time.Second + 400*time.Millisecond,
+		Diff:        &git.DiffStat{Files: 2, Insertions: 10, Deletions: 3},
+	})
+
+	want := "Session:  cb_feat\nWorktree: /wt\nAgent:    opencode\nStatus:   WAITING\nElapsed:  1m23s\nChanges:  +10 −3 (2 files)\n"
+	if buf.String() != want {
+		t.Fatalf("summary =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
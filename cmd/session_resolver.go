@@ -3,14 +3,76 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 )
 
 type sessionResolver interface {
 	ListSessions() ([]tmux.Session, error)
 	GetPaneWorkingDir(session string) string
+	GetSessionOption(session, key string) (string, error)
+}
+
+// sessionHomePath returns the worktree a session is pinned to via
+// @cb_home_path, falling back to its first pane's working directory for
+// sessions started before pinning existed.
+func sessionHomePath(tmuxClient sessionResolver, session string) string {
+	if home, err := tmuxClient.GetSessionOption(session, tmux.SessionOptionHomePath); err == nil && home != "" {
+		return home
+	}
+	return tmuxClient.GetPaneWorkingDir(session)
+}
+
+// managedSessionName adds the cb_ prefix to a session-name argument that
+// lacks it.
+func managedSessionName(name string) string {
+	if strings.HasPrefix(name, "cb_") {
+		return name
+	}
+	return "cb_" + name
+}
+
+// resolveSessionName resolves a session-name argument by resolveSessionArg
+// under the configured session_name style.
+func resolveSessionName(tmuxClient sessionResolver, arg string) string {
+	style := ""
+	if cfg, err := config.LoadUserConfig(); err == nil {
+		style = cfg.SessionName
+	}
+	return resolveSessionArg(tmuxClient, arg, style)
+}
+
+// resolveSessionArg returns the session a name argument addresses: the
+// session of that name (cb_ prefix added when missing); else the session
+// the session_name style gives the argument read as a branch; else the
+// session whose recorded branch (@cb_branch) it is. So "feature/add-login"
+// finds cb_feature-add-login under session_name = "dashed". An argument
+// matching nothing is returned with the prefix.
+func resolveSessionArg(tmuxClient sessionResolver, arg, style string) string {
+	name := managedSessionName(arg)
+	sessions, err := tmuxClient.ListSessions()
+	if err != nil {
+		return name
+	}
+	exists := func(candidate string) bool {
+		return slices.ContainsFunc(sessions, func(s tmux.Session) bool { return s.Name == candidate })
+	}
+	if exists(name) {
+		return name
+	}
+	branch := strings.TrimPrefix(arg, "cb_")
+	if styled := config.SessionNameForBranch(style, branch); exists(styled) {
+		return styled
+	}
+	for _, s := range sessions {
+		if recorded, err := tmuxClient.GetSessionOption(s.Name, tmux.SessionOptionBranch); err == nil && recorded == branch {
+			return s.Name
+		}
+	}
+	return name
 }
 
 func resolveSessionForCWD(tmuxClient sessionResolver, cwd string) (sessionName string, worktreePath string, err error) {
@@ -33,7 +95,7 @@ func resolveSessionForCWD(tmuxClient sessionResolver, cwd string) (sessionName s
 
 	var candidates []candidate
 	for _, s := range sessions {
-		panePath := tmuxClient.GetPaneWorkingDir(s.Name)
+		panePath := sessionHomePath(tmuxClient, s.Name)
 		if panePath == "" {
 			continue
 		}
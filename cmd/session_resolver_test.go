@@ -1,15 +1,19 @@
 package cmd
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
 
+	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 )
 
 type fakeSessionResolver struct {
 	sessions []tmux.Session
 	paths    map[string]string
+	homes    map[string]string
+	branches map[string]string
 	err      error
 }
 
@@ -21,6 +25,42 @@ func (f fakeSessionResolver) GetPaneWorkingDir(session string) string {
 	return f.paths[session]
 }
 
+func (f fakeSessionResolver) GetSessionOption(session, key string) (string, error) {
+	if home, ok := f.homes[session]; ok && key == tmux.SessionOptionHomePath {
+		return home, nil
+	}
+	if branch, ok := f.branches[session]; ok && key == tmux.SessionOptionBranch {
+		return branch, nil
+	}
+	return "", errors.New("option not set")
+}
+
+func TestResolveSessionArg(t *testing.T) {
+	resolver := fakeSessionResolver{
+		sessions: []tmux.Session{{Name: "cb_feature/old"}, {Name: "cb_feature-add-login"}, {Name: "cb_fix-2"}},
+		branches: map[string]string{"cb_fix-2": "fix"},
+	}
+	tests := []struct {
+		name  string
+		arg   string
+		style string
+		want  string
+	}{
+		{"exact name", "cb_feature/old", "", "cb_feature/old"},
+		{"prefix added", "feature/old", "", "cb_feature/old"},
+		{"styled branch", "feature/add-login", config.SessionNameDashed, "cb_feature-add-login"},
+		{"recorded branch", "fix", "", "cb_fix-2"},
+		{"no match", "feature/none", config.SessionNameDashed, "cb_feature/none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveSessionArg(resolver, tt.arg, tt.style); got != tt.want {
+				t.Fatalf("resolveSessionArg(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestResolveSessionForCWD_ExactMatchPreferred(t *testing.T) {
 	wd := t.TempDir()
 	exact := filepath.Join(wd, "project", ".worktrees", "project-feat")
@@ -114,3 +154,36 @@ func TestResolveSessionForCWD_SlashBranchPath(t *testing.T) {
 		t.Fatalf("worktreePath = %q, want %q", worktreePath, filepath.Clean(panePath))
 	}
 }
+
+func TestResolveSessionForCWD_PrefersPinnedHomePath(t *testing.T) {
+	wd := t.TempDir()
+	repo := filepath.Join(wd, "project")
+	feat := filepath.Join(repo, ".worktrees", "project-feat")
+
+	// cb_feat's pane wandered into the main repo, but it is pinned to its
+	// worktree; cb_main really lives in the main repo.
+	resolver := fakeSessionResolver{
+		sessions: []tmux.Session{{Name: "cb_feat"}, {Name: "cb_main"}},
+		paths: map[string]string{
+			"cb_feat": repo,
+			"cb_main": repo,
+		},
+		homes: map[string]string{"cb_feat": feat},
+	}
+
+	session, worktreePath, err := resolveSessionForCWD(resolver, filepath.Join(feat, "src"))
+	if err != nil {
+		t.Fatalf("resolveSessionForCWD() error = %v", err)
+	}
+	if session != "cb_feat" || worktreePath != feat {
+		t.Fatalf("got (%q, %q), want (cb_feat, %q)", session, worktreePath, feat)
+	}
+
+	session, _, err = resolveSessionForCWD(resolver, repo)
+	if err != nil {
+		t.Fatalf("resolveSessionForCWD() error = %v", err)
+	}
+	if session != "cb_main" {
+		t.Fatalf("session = %q, want cb_main (cb_feat is pinned elsewhere)", session)
+	}
+}
@@ -1,19 +1,34 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ronsanzone/clawd-bay/internal/agentconfig"
 	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/registry"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 	"github.com/spf13/cobra"
 )
 
 var startDetach bool
+var startJSON bool
+var startTemplate string
+var startWindows string
+var startAddProject bool
+var startAgents string
+var startPurpose string
 var startErrWriter io.Writer = os.Stderr
 
 var startCmd = &cobra.Command{
@@ -24,102 +39,491 @@ var startCmd = &cobra.Command{
 Example:
   cb start proj-123-auth-feature
   cb start feature/add-login
-  cb start --detach my-branch   # Create without attaching`,
+  cb start --detach my-branch   # Create without attaching
+  cb start --json my-branch     # Create detached and print the result as JSON
+  cb start --template web my-branch   # Create windows from a session template
+  cb start --windows shell,tests:"npm test -- --watch" my-branch
+  cb start --add-project my-branch   # Also register this repo as a project
+  cb start --agents claude,codex my-branch   # Race two agents on the same task
+  cb start --agents claude --purpose review my-branch
+
+Starting from a repo that is not a configured project asks whether to add
+it, so the new session shows up in cb dash; --add-project adds it without
+asking.
+
+--agents opens one window per listed agent after the session's first window,
+each named after and running the agent's launch command, and selects the
+first of them. With --purpose, the windows are named by the agent_window_name
+scheme in the config file instead ("claude-review" by default). When the
+branch already has a session, attaching to it launches the --agents in it
+too.
+
+The [start] config table sets defaults for --detach, --agents, and
+--add-project; flags given on the command line override them.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runStart,
 }
 
 func init() {
 	startCmd.Flags().BoolVarP(&startDetach, "detach", "d", false, "Create session without attaching to it")
+	startCmd.Flags().BoolVar(&startJSON, "json", false, "Print the created session as JSON (implies --detach)")
+	startCmd.Flags().StringVarP(&startTemplate, "template", "t", "", "Create windows from the named session template")
+	startCmd.Flags().StringVar(&startWindows, "windows", "", "Create extra windows: comma-separated name or name:command entries")
+	startCmd.Flags().BoolVar(&startAddProject, "add-project", false, "Add the current repo to the configured projects if it is missing")
+	startCmd.Flags().StringVar(&startAgents, "agents", "", "Launch agents in their own windows: comma-separated claude, codex, or opencode")
+	startCmd.Flags().StringVar(&startPurpose, "purpose", "", "Name the agent windows for this purpose (see agent_window_name)")
 	rootCmd.AddCommand(startCmd)
 }
 
+// startOptions are cb start's flag values after applying the [start] config
+// defaults to the flags not given.
+type startOptions struct {
+	Detach     bool
+	AddProject bool
+	Agents     string
+}
+
+func resolveStartOptions(changed func(flag string) bool, defaults config.StartConfig) startOptions {
+	opts := startOptions{Detach: startDetach, AddProject: startAddProject, Agents: startAgents}
+	if !changed("detach") {
+		opts.Detach = opts.Detach || defaults.Detach
+	}
+	if !changed("add-project") {
+		opts.AddProject = opts.AddProject || defaults.AddProject
+	}
+	if !changed("agents") {
+		opts.Agents = strings.Join(defaults.Agents, ",")
+	}
+	return opts
+}
+
 func runStart(cmd *cobra.Command, args []string) error {
-	branchName := sanitizeBranchName(args[0])
+	extraWindows, err := parseWindowsFlag(startWindows)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+	opts := resolveStartOptions(cmd.Flags().Changed, cfg.Start)
+	agents, err := parseAgentsFlag(opts.Agents)
+	if err != nil {
+		return err
+	}
+	purpose, err := parseAgentPurpose(startPurpose)
+	if err != nil {
+		return err
+	}
+	if purpose != "" && len(agents) == 0 {
+		return fmt.Errorf("--purpose names agent windows; pass --agents too")
+	}
+	var recordedAgent tmux.AgentType
+	if len(agents) > 0 {
+		recordedAgent = agents[0]
+	}
+
+	// With --json, stdout carries only the result; progress goes to stderr.
+	var out io.Writer = os.Stdout
+	detach := opts.Detach
+	if startJSON {
+		out = startErrWriter
+		detach = true
+	}
+
+	// Unconfigured repos are registered with --add-project, or after asking
+	// when someone is at the terminal to answer.
+	addProject := func(string) bool { return opts.AddProject }
+	if !opts.AddProject && !startJSON && stdinIsTerminal() {
+		addProject = func(repoPath string) bool {
+			return confirm(os.Stdin, fmt.Sprintf("%s is not a configured project, so its sessions will not appear in cb dash. Add it? [y/N] ", repoPath))
+		}
+	}
+
+	tmuxClient := tmux.NewClient()
+	wf, err := createWorkflow(tmuxClient, workflowSpec{
+		Branch:       args[0],
+		Template:     startTemplate,
+		ExtraWindows: extraWindows,
+		AddProject:   addProject,
+		Agent:        recordedAgent,
+		Out:          out,
+	})
+	naming := agentWindowNaming{Scheme: cfg.AgentWindowName, Purpose: purpose}
+	var existing *existingWorkflowError
+	if errors.As(err, &existing) && !detach {
+		// Agents asked for on the command line join the existing session;
+		// [start] defaults only apply to sessions cb start creates.
+		var extraAgents []tmux.AgentType
+		prompt := fmt.Sprintf("Session %s already exists for %s. Attach to it? [y/N] ", existing.Session, existing.WorktreeDir)
+		if cmd.Flags().Changed("agents") {
+			extraAgents = agents
+		}
+		if len(extraAgents) > 0 {
+			prompt = fmt.Sprintf("Session %s already exists for %s. Launch %s in it and attach? [y/N] ", existing.Session, existing.WorktreeDir, opts.Agents)
+		}
+		if !confirm(os.Stdin, prompt) {
+			return fmt.Errorf("worktree directory already exists: %s", existing.WorktreeDir)
+		}
+		if err := launchAgentWindows(tmuxClient, existing.Session, existing.WorktreeDir, extraAgents, naming, out); err != nil {
+			return err
+		}
+		return attachOrSwitch(tmuxClient, existing.Session)
+	}
+	if err != nil {
+		return err
+	}
+	if err := launchAgentWindows(tmuxClient, wf.Session, wf.WorktreeDir, agents, naming, out); err != nil {
+		return err
+	}
+
+	if startJSON {
+		return writeStartResult(cmd.OutOrStdout(), tmuxClient, wf.Session, wf.Branch, wf.WorktreeDir)
+	}
+
+	// If detach mode, just print instructions and exit
+	if detach {
+		_, _ = fmt.Fprintf(out, "Session created. Attach with: tmux attach -t %s\n", wf.Session)
+		return nil
+	}
+
+	return attachOrSwitch(tmuxClient, wf.Session)
+}
+
+// parseAgentsFlag parses the comma-separated --agents value. An agent may
+// be listed more than once to race copies of it.
+func parseAgentsFlag(value string) ([]tmux.AgentType, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	var agents []tmux.AgentType
+	for name := range strings.SplitSeq(value, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		agent, err := agentconfig.ParseAgentName(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --agents entry: %w", err)
+		}
+		agents = append(agents, agent)
+	}
+	return agents, nil
+}
+
+// parseAgentPurpose validates a --purpose value, which becomes part of
+// agent window names.
+func parseAgentPurpose(value string) (string, error) {
+	purpose := strings.TrimSpace(value)
+	if strings.Contains(purpose, ".") {
+		return "", fmt.Errorf("invalid --purpose %q: it cannot contain '.'", value)
+	}
+	return purpose, nil
+}
+
+type agentWindowLauncher interface {
+	runTmuxClient
+	SelectWindow(target string) error
+}
+
+// launchAgentWindows opens a window per agent in session (see
+// launchAgentWindow) and selects the first one, so attaching lands on it.
+func launchAgentWindows(client agentWindowLauncher, session, workdir string, agents []tmux.AgentType, naming agentWindowNaming, out io.Writer) error {
+	var first string
+	for _, agent := range agents {
+		target, err := launchAgentWindow(client, session, workdir, agent, naming)
+		if err != nil {
+			return fmt.Errorf("failed to launch %s: %w", agent.LaunchCommand(), err)
+		}
+		_, _ = fmt.Fprintf(out, "Launched %s in %s\n", agent.LaunchCommand(), session)
+		if first == "" {
+			first = target
+		}
+	}
+	if first == "" {
+		return nil
+	}
+	return client.SelectWindow(first)
+}
+
+// workflowSpec describes a worktree and session to create from the current
+// repository.
+type workflowSpec struct {
+	// Branch is the raw branch argument; it is sanitized before use.
+	Branch       string
+	Template     string
+	ExtraWindows []windowSpec
+	// AddProject decides whether an unconfigured repo is added to the
+	// config; nil only warns.
+	AddProject func(repoPath string) bool
+	// Agent is the agent the caller launches in the session, recorded as
+	// its @cb_session_agent option; empty when none is launched.
+	Agent tmux.AgentType
+	// Out receives progress messages and git output.
+	Out io.Writer
+}
+
+// workflow is a worktree and tmux session created by createWorkflow.
+type workflow struct {
+	Session     string
+	Branch      string
+	WorktreeDir string
+}
+
+// existingWorkflowError reports that the branch's worktree already exists
+// with a session pinned to it.
+type existingWorkflowError struct {
+	Session     string
+	WorktreeDir string
+}
+
+func (e *existingWorkflowError) Error() string {
+	return fmt.Sprintf("session %s already exists for %s (attach with: tmux attach -t %s)", e.Session, e.WorktreeDir, e.Session)
+}
+
+// createWorkflow creates the worktree and detached tmux session for
+// spec.Branch in the repository at the current directory, applying the
+// template and extra windows and recording the session for cb restore.
+func createWorkflow(tmuxClient *tmux.Client, spec workflowSpec) (workflow, error) {
+	branchName := sanitizeBranchName(spec.Branch)
 	if branchName == "" {
-		return fmt.Errorf("branch name %q is invalid after sanitization; use letters, numbers, '-', '_', or '/'", args[0])
+		return workflow{}, fmt.Errorf("branch name %q is invalid after sanitization; use letters, numbers, '-', '_', or '/'", spec.Branch)
+	}
+	out := spec.Out
+
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		return workflow{}, err
+	}
+	var windowSpecs []windowSpec
+	if spec.Template != "" {
+		tmpl, ok := cfg.FindTemplate(spec.Template)
+		if !ok {
+			return workflow{}, fmt.Errorf("unknown session template %q (see: cb template list)", spec.Template)
+		}
+		windowSpecs = templateWindowSpecs(tmpl)
 	}
 
 	// Verify we're in a git repository
 	if _, err := exec.Command("git", "rev-parse", "--git-dir").Output(); err != nil {
-		return fmt.Errorf("not in a git repository")
+		return workflow{}, fmt.Errorf("not in a git repository")
 	}
 	repoTopLevelOutput, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
 	if err != nil {
-		return fmt.Errorf("failed to determine repository root: %w", err)
+		return workflow{}, fmt.Errorf("failed to determine repository root: %w", err)
 	}
-	if err := warnIfRepoNotConfigured(strings.TrimSpace(string(repoTopLevelOutput))); err != nil {
-		return err
+	project, err := ensureRepoConfigured(strings.TrimSpace(string(repoTopLevelOutput)), spec.AddProject)
+	if err != nil {
+		return workflow{}, err
 	}
 
 	// Get current directory info
 	cwd, err := os.Getwd()
 	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+		return workflow{}, fmt.Errorf("failed to get current directory: %w", err)
 	}
 	projectName := filepath.Base(cwd)
 
 	// Ensure .worktrees directory exists
 	worktreesDir := filepath.Join(cwd, ".worktrees")
 	if err := os.MkdirAll(worktreesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .worktrees directory: %w", err)
+		return workflow{}, fmt.Errorf("failed to create .worktrees directory: %w", err)
 	}
 
 	// Add .worktrees/ to .gitignore if not already present
-	ensureGitignoreEntry(cwd, ".worktrees/")
+	if cfg.Start.ManagesGitignore() {
+		ensureGitignoreEntry(cwd, ".worktrees/")
+	}
 
-	worktreeDir := filepath.Join(cwd, ".worktrees", projectName+"-"+branchName)
+	worktreeName, err := config.RenderWorktreeName(cfg.WorktreeName, projectName, branchName, cfg.WorktreeNameMax)
+	if err != nil {
+		return workflow{}, err
+	}
+	worktreeDir := resolveWorktreeDir(worktreesDir, worktreeName, branchName, git.NewClient().CurrentBranch)
 
-	// Check if worktree directory already exists
+	// An existing worktree is only reusable through the session pinned to it.
 	if _, err := os.Stat(worktreeDir); err == nil {
-		return fmt.Errorf("worktree directory already exists: %s", worktreeDir)
+		if existing, ok := sessionForWorktree(tmuxClient, worktreeDir); ok {
+			return workflow{}, &existingWorkflowError{Session: existing, WorktreeDir: worktreeDir}
+		}
+		return workflow{}, fmt.Errorf("worktree directory already exists: %s", worktreeDir)
 	}
 
-	// Check if branch already exists
+	// A session with the default name may belong to another repo's branch.
+	baseSessionName := config.SessionNameForBranch(cfg.SessionName, branchName)
+	sessionName := tmux.UniqueName(baseSessionName, tmuxClient.HasSession)
+	if sessionName != baseSessionName {
+		_, _ = fmt.Fprintf(out, "Session %s already exists, using %s\n", baseSessionName, sessionName)
+	}
+
+	// Check if branch already exists, locally or only on the remote
+	var worktreeArgs []string
 	checkBranch := exec.Command("git", "rev-parse", "--verify", branchName)
 	if checkBranch.Run() == nil {
 		// Branch exists, create worktree without -b flag
-		fmt.Printf("Branch %s exists, creating worktree...\n", branchName)
-		gitCmd := exec.Command("git", "worktree", "add", worktreeDir, branchName)
-		gitCmd.Stdout = os.Stdout
-		gitCmd.Stderr = os.Stderr
-		if err := gitCmd.Run(); err != nil {
-			return fmt.Errorf("failed to create worktree: %w", err)
-		}
+		_, _ = fmt.Fprintf(out, "Branch %s exists, creating worktree...\n", branchName)
+		worktreeArgs = []string{"worktree", "add", worktreeDir, branchName}
 	} else {
-		// Create new branch and worktree
-		fmt.Printf("Creating worktree: %s\n", worktreeDir)
-		gitCmd := exec.Command("git", "worktree", "add", worktreeDir, "-b", branchName)
-		gitCmd.Stdout = os.Stdout
-		gitCmd.Stderr = os.Stderr
-		if err := gitCmd.Run(); err != nil {
-			return fmt.Errorf("failed to create worktree: %w", err)
+		remoteRef, err := fetchRemoteBranch(git.NewClient(), cwd, branchName)
+		if err != nil {
+			return workflow{}, err
 		}
+		if remoteRef != "" {
+			// Remote-only branch: track it instead of forking a new one
+			_, _ = fmt.Fprintf(out, "Branch %s exists on %s, creating worktree tracking %s...\n", branchName, startRemote, remoteRef)
+			worktreeArgs = []string{"worktree", "add", "--track", "-b", branchName, worktreeDir, remoteRef}
+		} else {
+			// Create new branch and worktree
+			_, _ = fmt.Fprintf(out, "Creating worktree: %s\n", worktreeDir)
+			worktreeArgs = []string{"worktree", "add", worktreeDir, "-b", branchName}
+		}
+	}
+	gitCmd := exec.Command("git", worktreeArgs...)
+	gitCmd.Stdout = out
+	gitCmd.Stderr = os.Stderr
+	if err := gitCmd.Run(); err != nil {
+		return workflow{}, fmt.Errorf("failed to create worktree: %w", err)
 	}
 
 	// Create tmux session
-	sessionName := "cb_" + branchName
-	tmuxClient := tmux.NewClient()
-
-	fmt.Printf("Creating tmux session: %s\n", sessionName)
+	_, _ = fmt.Fprintf(out, "Creating tmux session: %s\n", sessionName)
 	if err := tmuxClient.CreateSession(sessionName, worktreeDir); err != nil {
-		return fmt.Errorf("failed to create tmux session: %w", err)
+		return workflow{}, fmt.Errorf("failed to create tmux session: %w", err)
 	}
 	persistSessionHomePath(tmuxClient, sessionName, worktreeDir, startErrWriter)
+	persistSessionMetadata(tmuxClient, sessionName, sessionMetadata{
+		Branch:    branchName,
+		Project:   project,
+		Agent:     spec.Agent,
+		CreatedAt: time.Now(),
+	}, startErrWriter)
+	if len(windowSpecs) > 0 {
+		if err := buildSessionWindows(tmuxClient, sessionName, worktreeDir, windowSpecs); err != nil {
+			return workflow{}, fmt.Errorf("failed to apply template %q: %w", spec.Template, err)
+		}
+	}
+	if len(spec.ExtraWindows) > 0 {
+		if err := appendSessionWindows(tmuxClient, sessionName, worktreeDir, spec.ExtraWindows); err != nil {
+			return workflow{}, fmt.Errorf("failed to create extra windows: %w", err)
+		}
+	}
+	recordStartedSession(tmuxClient, sessionName, worktreeDir, startErrWriter)
 
-	// If detach mode, just print instructions and exit
-	if startDetach {
-		fmt.Printf("Session created. Attach with: tmux attach -t %s\n", sessionName)
-		return nil
+	return workflow{Session: sessionName, Branch: branchName, WorktreeDir: worktreeDir}, nil
+}
+
+// resolveWorktreeDir returns the directory for branch's worktree named name
+// under worktreesDir. When another branch's worktree already occupies that
+// name (e.g. two long branches truncated alike), name-2, name-3, ... is used.
+func resolveWorktreeDir(worktreesDir, name, branch string, branchAt func(dir string) (string, error)) string {
+	free := func(dir string) bool {
+		if _, err := os.Stat(dir); err != nil {
+			return true
+		}
+		current, err := branchAt(dir)
+		return err == nil && current == branch
 	}
+	for i := 1; ; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", name, i)
+		}
+		dir := filepath.Join(worktreesDir, candidate)
+		if free(dir) {
+			return dir
+		}
+	}
+}
+
+// startRemote is the remote searched for branches that do not exist locally.
+const startRemote = "origin"
+
+type remoteBranchFetcher interface {
+	RemoteBranchExists(dir, remote, branch string) (bool, error)
+	FetchBranch(dir, remote, branch string) error
+}
+
+// fetchRemoteBranch fetches branch from startRemote when it exists only there
+// and returns its remote-tracking ref (e.g. "origin/feat"). It returns "" when
+// the remote has no such branch or cannot be queried (no origin, offline).
+func fetchRemoteBranch(client remoteBranchFetcher, dir, branch string) (string, error) {
+	exists, err := client.RemoteBranchExists(dir, startRemote, branch)
+	if err != nil {
+		slog.Debug("remote branch lookup failed", "remote", startRemote, "branch", branch, "err", err)
+		return "", nil
+	}
+	if !exists {
+		return "", nil
+	}
+	if err := client.FetchBranch(dir, startRemote, branch); err != nil {
+		return "", err
+	}
+	return startRemote + "/" + branch, nil
+}
+
+// startResult is the --json output of cb start.
+type startResult struct {
+	Session      string   `json:"session"`
+	WorktreePath string   `json:"worktree_path"`
+	Branch       string   `json:"branch"`
+	Windows      []string `json:"windows"`
+}
+
+// writeStartResult prints the created session, its canonical worktree path,
+// and its window names as indented JSON.
+func writeStartResult(w io.Writer, tmuxClient interface {
+	ListWindows(session string) ([]tmux.Window, error)
+}, sessionName, branchName, worktreeDir string) error {
+	result := startResult{Session: sessionName, WorktreePath: worktreeDir, Branch: branchName, Windows: []string{}}
+	if canonical, err := config.CanonicalPath(worktreeDir); err == nil {
+		result.WorktreePath = canonical
+	}
+	windows, err := tmuxClient.ListWindows(sessionName)
+	if err != nil {
+		return err
+	}
+	for _, win := range windows {
+		result.Windows = append(result.Windows, win.Name)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
 
-	// Switch to the session
+// attachOrSwitch switches the current tmux client to sessionName when inside
+// tmux, otherwise attaches to it.
+func attachOrSwitch(tmuxClient *tmux.Client, sessionName string) error {
 	if os.Getenv("TMUX") != "" {
 		return tmuxClient.SwitchClient(sessionName)
 	}
 	return tmuxClient.AttachSession(sessionName)
 }
 
+// sessionForWorktree returns the managed session pinned to worktreeDir.
+func sessionForWorktree(tmuxClient sessionResolver, worktreeDir string) (string, bool) {
+	target, err := config.CanonicalPath(worktreeDir)
+	if err != nil {
+		target = filepath.Clean(worktreeDir)
+	}
+	sessions, err := tmuxClient.ListSessions()
+	if err != nil {
+		return "", false
+	}
+	for _, s := range sessions {
+		home := sessionHomePath(tmuxClient, s.Name)
+		if home == "" {
+			continue
+		}
+		if canonical, err := config.CanonicalPath(home); err == nil {
+			home = canonical
+		}
+		if home == target {
+			return s.Name, true
+		}
+	}
+	return "", false
+}
+
 type sessionOptionSetter interface {
 	SetSessionOption(session, key, value string) error
 }
@@ -136,6 +540,62 @@ func persistSessionHomePath(tmuxClient sessionOptionSetter, sessionName, worktre
 	}
 }
 
+// sessionMetadata is what cb records about a session it creates, besides
+// its home path.
+type sessionMetadata struct {
+	Branch    string
+	Project   string
+	Agent     tmux.AgentType
+	CreatedAt time.Time
+}
+
+// persistSessionMetadata records meta as session options, skipping empty
+// fields. Like the home path, failures only warn.
+func persistSessionMetadata(tmuxClient sessionOptionSetter, sessionName string, meta sessionMetadata, errWriter io.Writer) {
+	createdAt := ""
+	if !meta.CreatedAt.IsZero() {
+		createdAt = strconv.FormatInt(meta.CreatedAt.Unix(), 10)
+	}
+	options := []struct{ key, value string }{
+		{tmux.SessionOptionBranch, meta.Branch},
+		{tmux.SessionOptionProject, meta.Project},
+		{tmux.SessionOptionAgent, string(meta.Agent)},
+		{tmux.SessionOptionCreatedAt, createdAt},
+	}
+	for _, option := range options {
+		if option.value == "" {
+			continue
+		}
+		if err := tmuxClient.SetSessionOption(sessionName, option.key, option.value); err != nil {
+			_, _ = fmt.Fprintf(errWriter, "Warning: failed to set tmux session metadata for %s: %v\n", sessionName, err)
+			return
+		}
+	}
+}
+
+// recordStartedSession adds a freshly created session to the session registry
+// so `cb restore` can recreate it.
+func recordStartedSession(tmuxClient *tmux.Client, sessionName, worktreeDir string, errWriter io.Writer) {
+	homePath, err := config.CanonicalPath(worktreeDir)
+	if err != nil {
+		homePath = worktreeDir
+	}
+	entry := registry.Session{Name: sessionName, HomePath: homePath, UpdatedAt: time.Now()}
+	if windows, err := tmuxClient.ListWindows(sessionName); err == nil {
+		for _, w := range windows {
+			entry.Windows = append(entry.Windows, registry.Window{Index: w.Index, Name: w.Name})
+		}
+	}
+
+	store, err := sessionRegistry()
+	if err == nil {
+		err = store.Upsert(entry)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(errWriter, "Warning: failed to record %s in session registry: %v\n", sessionName, err)
+	}
+}
+
 // sanitizeBranchName converts a string to a valid git branch name.
 func sanitizeBranchName(name string) string {
 	// Replace spaces and special chars with dashes
@@ -159,15 +619,19 @@ func sanitizeBranchName(name string) string {
 	return strings.Trim(cleaned, "-")
 }
 
-func warnIfRepoNotConfigured(repoPath string) error {
+// ensureRepoConfigured adds the repo at repoPath to the configured projects
+// when it is missing and addProject approves, and otherwise warns that its
+// sessions will not appear in the dashboard. It returns the project's
+// display name, or "" when the repo stays unconfigured.
+func ensureRepoConfigured(repoPath string, addProject func(repoPath string) bool) (string, error) {
 	cfg, _, err := config.LoadUserConfigWithMeta()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	canonicalRepoPath, err := config.CanonicalPath(repoPath)
 	if err != nil {
-		return nil
+		return "", nil
 	}
 
 	for _, p := range cfg.Projects {
@@ -176,12 +640,34 @@ func warnIfRepoNotConfigured(repoPath string) error {
 			continue
 		}
 		if canonicalProjectPath == canonicalRepoPath {
+			return projectDisplayName(p), nil
+		}
+	}
+
+	if addProject != nil && addProject(canonicalRepoPath) {
+		project := config.ProjectConfig{Path: canonicalRepoPath}
+		err := config.UpdateUserConfig(func(cfg *config.UserConfig) error {
+			if slices.ContainsFunc(cfg.Projects, func(p config.ProjectConfig) bool { return p.Path == canonicalRepoPath }) {
+				return config.ErrUnchanged
+			}
+			cfg.Projects = append(cfg.Projects, project)
 			return nil
+		})
+		if err != nil {
+			return "", err
 		}
+		_, _ = fmt.Fprintf(startErrWriter, "Added project: %s\n", canonicalRepoPath)
+		return projectDisplayName(project), nil
 	}
 
-	_, _ = fmt.Fprintln(startErrWriter, "Warning: current repo is not configured; sessions started here will not appear in `cb dash` or `cb list`.")
-	return nil
+	_, _ = fmt.Fprintln(startErrWriter, "Warning: current repo is not configured; sessions started here will not appear in `cb dash` or `cb list` (add it with `cb start --add-project` or `cb project add`).")
+	return "", nil
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal.
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
 // ensureGitignoreEntry adds an entry to .gitignore if not already present.
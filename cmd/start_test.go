@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
@@ -133,6 +137,305 @@ func TestRunStart_RejectsEmptySanitizedBranch(t *testing.T) {
 	}
 }
 
+func TestParseWindowsFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []windowSpec
+		wantErr string
+	}{
+		{name: "empty", value: ""},
+		{
+			name:  "names and commands",
+			value: `shell, tests:"npm test -- --watch"`,
+			want: []windowSpec{
+				{Name: "shell", Panes: []string{""}},
+				{Name: "tests", Panes: []string{"npm test -- --watch"}},
+			},
+		},
+		{name: "command keeps later colons", value: "srv:make serve:dev", want: []windowSpec{{Name: "srv", Panes: []string{"make serve:dev"}}}},
+		{name: "missing name", value: ":npm test", wantErr: "invalid window name"},
+		{name: "dotted name", value: "a.b", wantErr: "invalid window name"},
+		{name: "duplicate", value: "shell,shell", wantErr: "duplicate window name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWindowsFlag(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseWindowsFlag() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseWindowsFlag() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseWindowsFlag() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAgentsFlag(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []tmux.AgentType
+		wantErr string
+	}{
+		{name: "empty", value: ""},
+		{name: "commands and types", value: "claude, open_code", want: []tmux.AgentType{tmux.AgentClaude, tmux.AgentOpenCode}},
+		{name: "repeated agent races copies", value: "codex,codex,", want: []tmux.AgentType{tmux.AgentCodex, tmux.AgentCodex}},
+		{name: "unknown agent", value: "claude,aider", wantErr: `unknown agent "aider"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAgentsFlag(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseAgentsFlag() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAgentsFlag() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseAgentsFlag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAgentPurpose(t *testing.T) {
+	if got, err := parseAgentPurpose("  review "); err != nil || got != "review" {
+		t.Fatalf("parseAgentPurpose() = %q, %v; want review", got, err)
+	}
+	if _, err := parseAgentPurpose("v1.2"); err == nil {
+		t.Fatal("parseAgentPurpose() should reject '.', which tmux reads as a pane separator")
+	}
+}
+
+func TestResolveStartOptions(t *testing.T) {
+	defaults := config.StartConfig{Detach: true, Agents: []string{"claude", "codex"}, AddProject: true}
+	tests := []struct {
+		name       string
+		detach     bool
+		addProject bool
+		agents     string
+		changed    []string
+		defaults   config.StartConfig
+		want       startOptions
+	}{
+		{name: "no config keeps flag defaults", want: startOptions{}},
+		{name: "config fills unset flags", defaults: defaults, want: startOptions{Detach: true, AddProject: true, Agents: "claude,codex"}},
+		{
+			name:     "given flags override config",
+			agents:   "",
+			changed:  []string{"detach", "add-project", "agents"},
+			defaults: defaults,
+			want:     startOptions{},
+		},
+		{name: "flags apply without config", detach: true, agents: "opencode", changed: []string{"detach", "agents"}, want: startOptions{Detach: true, Agents: "opencode"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origDetach, origAddProject, origAgents := startDetach, startAddProject, startAgents
+			t.Cleanup(func() { startDetach, startAddProject, startAgents = origDetach, origAddProject, origAgents })
+			startDetach, startAddProject, startAgents = tt.detach, tt.addProject, tt.agents
+
+			changed := func(flag string) bool { return slices.Contains(tt.changed, flag) }
+			if got := resolveStartOptions(changed, tt.defaults); got != tt.want {
+				t.Fatalf("resolveStartOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeAgentWindowLauncher struct {
+	fakeRunTmuxClient
+	selected []string
+}
+
+func (f *fakeAgentWindowLauncher) SelectWindow(target string) error {
+	f.selected = append(f.selected, target)
+	return nil
+}
+
+func TestLaunchAgentWindows(t *testing.T) {
+	client := &fakeAgentWindowLauncher{fakeRunTmuxClient: fakeRunTmuxClient{windows: []tmux.Window{{ID: "@1", Index: 0, Name: "zsh"}}}}
+	var out bytes.Buffer
+
+	naming := agentWindowNaming{Purpose: "login"}
+	if err := launchAgentWindows(client, "cb_feat", "/wt", []tmux.AgentType{tmux.AgentClaude, tmux.AgentCodex}, naming, &out); err != nil {
+		t.Fatalf("launchAgentWindows() error = %v", err)
+	}
+	wantCreated := []string{"cb_feat claude-login claude /wt", "cb_feat codex-login codex /wt"}
+	if !reflect.DeepEqual(client.created, wantCreated) {
+		t.Fatalf("created = %v, want %v", client.created, wantCreated)
+	}
+	if len(client.selected) != 1 {
+		t.Fatalf("selected = %v, want only the first agent window", client.selected)
+	}
+	if !strings.Contains(out.String(), "Launched codex in cb_feat") {
+		t.Fatalf("output = %q, want a line per agent", out.String())
+	}
+
+	none := &fakeAgentWindowLauncher{}
+	if err := launchAgentWindows(none, "cb_feat", "/wt", nil, agentWindowNaming{}, &out); err != nil || len(none.created) != 0 || len(none.selected) != 0 {
+		t.Fatalf("no agents: err = %v, created = %v, selected = %v, want nothing", err, none.created, none.selected)
+	}
+}
+
+type fakeRemoteBranchFetcher struct {
+	exists   bool
+	queryErr error
+	fetchErr error
+	fetched  []string
+}
+
+func (f *fakeRemoteBranchFetcher) RemoteBranchExists(dir, remote, branch string) (bool, error) {
+	return f.exists, f.queryErr
+}
+
+func (f *fakeRemoteBranchFetcher) FetchBranch(dir, remote, branch string) error {
+	f.fetched = append(f.fetched, remote+"/"+branch)
+	return f.fetchErr
+}
+
+func TestFetchRemoteBranch(t *testing.T) {
+	tests := []struct {
+		name        string
+		fetcher     fakeRemoteBranchFetcher
+		want        string
+		wantFetched bool
+		wantErr     bool
+	}{
+		{name: "remote-only branch is fetched", fetcher: fakeRemoteBranchFetcher{exists: true}, want: "origin/feat", wantFetched: true},
+		{name: "absent on remote", fetcher: fakeRemoteBranchFetcher{}},
+		{name: "remote unavailable falls back", fetcher: fakeRemoteBranchFetcher{queryErr: errors.New("no origin")}},
+		{name: "fetch failure is an error", fetcher: fakeRemoteBranchFetcher{exists: true, fetchErr: errors.New("offline")}, wantFetched: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fetchRemoteBranch(&tt.fetcher, "/repo", "feat")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fetchRemoteBranch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("fetchRemoteBranch() = %q, want %q", got, tt.want)
+			}
+			if fetched := len(tt.fetcher.fetched) > 0; fetched != tt.wantFetched {
+				t.Fatalf("fetched = %v, want fetch %v", tt.fetcher.fetched, tt.wantFetched)
+			}
+		})
+	}
+}
+
+type fakeWindowLister struct {
+	windows []tmux.Window
+}
+
+func (f fakeWindowLister) ListWindows(session string) ([]tmux.Window, error) {
+	return f.windows, nil
+}
+
+func TestWriteStartResult(t *testing.T) {
+	worktreeDir := t.TempDir()
+	canonical, err := config.CanonicalPath(worktreeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lister := fakeWindowLister{windows: []tmux.Window{{Index: 0, Name: "zsh"}, {Index: 1, Name: "tests"}}}
+
+	var buf bytes.Buffer
+	if err := writeStartResult(&buf, lister, "cb_feat", "feat", worktreeDir); err != nil {
+		t.Fatalf("writeStartResult() error = %v", err)
+	}
+
+	var got startResult
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	want := startResult{Session: "cb_feat", WorktreePath: canonical, Branch: "feat", Windows: []string{"zsh", "tests"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("result = %+v, want %+v", got, want)
+	}
+	if !strings.Contains(buf.String(), `"worktree_path"`) {
+		t.Fatalf("output missing snake_case keys: %s", buf.String())
+	}
+}
+
+func TestResolveWorktreeDir(t *testing.T) {
+	worktreesDir := t.TempDir()
+	branches := map[string]string{
+		filepath.Join(worktreesDir, "repo-feat"):   "other",
+		filepath.Join(worktreesDir, "repo-feat-2"): "feat",
+		filepath.Join(worktreesDir, "repo-same"):   "same",
+	}
+	for dir := range branches {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	branchAt := func(dir string) (string, error) {
+		if branch, ok := branches[dir]; ok {
+			return branch, nil
+		}
+		return "", errors.New("not a worktree")
+	}
+
+	tests := []struct {
+		name     string
+		wtName   string
+		branch   string
+		wantBase string
+	}{
+		{name: "free name", wtName: "repo-new", branch: "new", wantBase: "repo-new"},
+		{name: "same branch keeps name", wtName: "repo-same", branch: "same", wantBase: "repo-same"},
+		{name: "other branch collides", wtName: "repo-feat", branch: "feat", wantBase: "repo-feat-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveWorktreeDir(worktreesDir, tt.wtName, tt.branch, branchAt)
+			if want := filepath.Join(worktreesDir, tt.wantBase); got != want {
+				t.Fatalf("resolveWorktreeDir() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSessionForWorktree(t *testing.T) {
+	repo := t.TempDir()
+	worktreeDir := filepath.Join(repo, ".worktrees", "repo-feat")
+	otherDir := filepath.Join(repo, ".worktrees", "repo-other")
+	for _, dir := range []string{worktreeDir, otherDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resolver := fakeSessionResolver{
+		sessions: []tmux.Session{{Name: "cb_other"}, {Name: "cb_feat"}},
+		homes: map[string]string{
+			"cb_other": otherDir,
+			"cb_feat":  worktreeDir,
+		},
+	}
+
+	if got, ok := sessionForWorktree(resolver, worktreeDir); !ok || got != "cb_feat" {
+		t.Fatalf("sessionForWorktree() = (%q, %v), want (cb_feat, true)", got, ok)
+	}
+	if got, ok := sessionForWorktree(resolver, filepath.Join(repo, ".worktrees", "repo-none")); ok {
+		t.Fatalf("sessionForWorktree() = %q, want no match", got)
+	}
+}
+
 func TestPersistSessionHomePath(t *testing.T) {
 	t.Run("sets canonical home path metadata", func(t *testing.T) {
 		repo := t.TempDir()
@@ -203,7 +506,37 @@ func TestPersistSessionHomePath(t *testing.T) {
 	})
 }
 
-func TestWarnIfRepoNotConfigured(t *testing.T) {
+type recordingOptionSetter map[string]string
+
+func (r recordingOptionSetter) SetSessionOption(session, key, value string) error {
+	r[session+"|"+key] = value
+	return nil
+}
+
+func TestPersistSessionMetadata(t *testing.T) {
+	setter := recordingOptionSetter{}
+	var stderr bytes.Buffer
+
+	persistSessionMetadata(setter, "cb_feature", sessionMetadata{
+		Branch:    "feature/login",
+		Project:   "web",
+		CreatedAt: time.Unix(1700000000, 0),
+	}, &stderr)
+
+	want := recordingOptionSetter{
+		"cb_feature|" + tmux.SessionOptionBranch:    "feature/login",
+		"cb_feature|" + tmux.SessionOptionProject:   "web",
+		"cb_feature|" + tmux.SessionOptionCreatedAt: "1700000000",
+	}
+	if !reflect.DeepEqual(setter, want) {
+		t.Fatalf("options = %v, want %v (no agent option)", setter, want)
+	}
+	if stderr.Len() != 0 {
+		t.Fatalf("stderr = %q, want empty", stderr.String())
+	}
+}
+
+func TestEnsureRepoConfigured(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
 
@@ -219,19 +552,51 @@ func TestWarnIfRepoNotConfigured(t *testing.T) {
 		var stderr bytes.Buffer
 		startErrWriter = &stderr
 
-		if err := warnIfRepoNotConfigured(repo); err != nil {
-			t.Fatalf("warnIfRepoNotConfigured() error = %v", err)
+		declined := false
+		project, err := ensureRepoConfigured(repo, func(string) bool { declined = true; return false })
+		if err != nil {
+			t.Fatalf("ensureRepoConfigured() error = %v", err)
+		}
+		if project != "" {
+			t.Fatalf("project = %q, want empty", project)
+		}
+		if !declined {
+			t.Fatal("ensureRepoConfigured() did not ask to add the repo")
 		}
 		if !strings.Contains(stderr.String(), "not configured") {
 			t.Fatalf("stderr = %q, want warning", stderr.String())
 		}
 	})
 
+	t.Run("adds repo when approved", func(t *testing.T) {
+		var stderr bytes.Buffer
+		startErrWriter = &stderr
+
+		project, err := ensureRepoConfigured(repo, func(string) bool { return true })
+		if err != nil {
+			t.Fatalf("ensureRepoConfigured() error = %v", err)
+		}
+		if project != "repo" {
+			t.Fatalf("project = %q, want repo", project)
+		}
+		if !strings.Contains(stderr.String(), "Added project") {
+			t.Fatalf("stderr = %q, want added message", stderr.String())
+		}
+		cfg, err := config.LoadUserConfig()
+		if err != nil {
+			t.Fatalf("LoadUserConfig() error = %v", err)
+		}
+		canonical, _ := config.CanonicalPath(repo)
+		if len(cfg.Projects) != 1 || cfg.Projects[0].Path != canonical {
+			t.Fatalf("cfg.Projects = %+v, want %s", cfg.Projects, canonical)
+		}
+	})
+
 	t.Run("no warning when repo is configured", func(t *testing.T) {
 		if err := config.SaveUserConfig(config.UserConfig{
 			Version: config.SupportedConfigVersion,
 			Projects: []config.ProjectConfig{
-				{Path: repo, Name: "repo"},
+				{Path: repo, Name: "web"},
 			},
 		}); err != nil {
 			t.Fatalf("SaveUserConfig() error = %v", err)
@@ -240,8 +605,15 @@ func TestWarnIfRepoNotConfigured(t *testing.T) {
 		var stderr bytes.Buffer
 		startErrWriter = &stderr
 
-		if err := warnIfRepoNotConfigured(repo); err != nil {
-			t.Fatalf("warnIfRepoNotConfigured() error = %v", err)
+		project, err := ensureRepoConfigured(repo, func(string) bool {
+			t.Fatal("ensureRepoConfigured() asked about a configured repo")
+			return false
+		})
+		if err != nil {
+			t.Fatalf("ensureRepoConfigured() error = %v", err)
+		}
+		if project != "web" {
+			t.Fatalf("project = %q, want web", project)
 		}
 		if stderr.Len() != 0 {
 			t.Fatalf("stderr = %q, want empty", stderr.String())
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/activity"
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/ronsanzone/clawd-bay/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var statsReset bool
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [session-name]",
+	Short: "Show cumulative agent WORKING time per session",
+	Long: `Shows how long each session's agents have spent WORKING, most active first.
+
+Activity is sampled whenever cb dash, cb list, or cb daemon refreshes, and kept
+in ~/.local/state/cb/activity.json across restarts. Time is only counted while
+one of them is running; gaps of more than two minutes between refreshes are
+skipped. Archived sessions stay listed until reset.
+
+Example:
+  cb stats
+  cb stats feature
+  cb stats --reset`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().BoolVar(&statsReset, "reset", false, "forget recorded activity (for the named session, or all)")
+	rootCmd.AddCommand(statsCmd)
+}
+
+// activityStore returns the store for per-session activity.
+func activityStore() (*activity.Store, error) {
+	c, err := config.New()
+	if err != nil {
+		return nil, err
+	}
+	return activity.NewStore(c.ActivityPath()), nil
+}
+
+// recordActivity folds the rolled-up status of every discovered session into
+// store. Failures are only logged.
+func recordActivity(store *activity.Store, result discovery.Result, now time.Time) {
+	statuses := make(map[string]tmux.Status)
+	for _, project := range result.Projects {
+		for _, wt := range project.Worktrees {
+			for _, s := range wt.Sessions {
+				statuses[s.Name] = s.Status
+			}
+		}
+	}
+	if err := store.Record(statuses, now); err != nil {
+		slog.Debug("failed to record activity", "err", err)
+	}
+}
+
+func runStats(cmd *cobra.Command, args []string) error {
+	store, err := activityStore()
+	if err != nil {
+		return err
+	}
+	var names []string
+	if len(args) > 0 {
+		names = []string{resolveSessionName(tmux.NewClient(), args[0])}
+	}
+
+	if statsReset {
+		if err := store.Reset(names...); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Activity reset.")
+		return nil
+	}
+
+	sessions, err := store.Load()
+	if err != nil {
+		return err
+	}
+	writeStats(cmd.OutOrStdout(), statsRows(sessions, names))
+	return nil
+}
+
+// statsRows returns the named sessions (or all) ordered by working time,
+// highest first.
+func statsRows(sessions map[string]activity.Session, names []string) []activity.Session {
+	var rows []activity.Session
+	if len(names) > 0 {
+		for _, name := range names {
+			if sess, ok := sessions[name]; ok {
+				rows = append(rows, sess)
+			}
+		}
+	} else {
+		for _, sess := range sessions {
+			rows = append(rows, sess)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Working != rows[j].Working {
+			return rows[i].Working > rows[j].Working
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows
+}
+
+func writeStats(w io.Writer, rows []activity.Session) {
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(w, "No recorded activity. Activity is sampled while cb dash, cb list, or cb daemon runs.")
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%-30s %8s  %-8s %s\n", "SESSION", "ACTIVE", "STATUS", "LAST SEEN")
+	for _, sess := range rows {
+		_, _ = fmt.Fprintf(w, "%-30s %8s  %-8s %s\n",
+			sess.Name, tui.FormatUptime(sess.Working), sess.LastStatus, sess.LastSeen.Local().Format("2006-01-02 15:04"))
+	}
+}
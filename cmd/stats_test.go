@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/activity"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestRecordActivity(t *testing.T) {
+	store := activity.NewStore(filepath.Join(t.TempDir(), "activity.json"))
+	result := func(status tmux.Status) discovery.Result {
+		return discovery.Result{Projects: []discovery.ProjectNode{{
+			Worktrees: []discovery.WorktreeNode{{Sessions: []discovery.SessionNode{{Name: "cb_a", Status: status}}}},
+		}}}
+	}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	recordActivity(store, result(tmux.StatusWorking), start)
+	recordActivity(store, result(tmux.StatusIdle), start.Add(45*time.Second))
+
+	sessions, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := sessions["cb_a"].Working; got != 45*time.Second {
+		t.Fatalf("cb_a working = %v, want 45s", got)
+	}
+}
+
+func TestStatsRowsAndWriteStats(t *testing.T) {
+	seen := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sessions := map[string]activity.Session{
+		"cb_a": {Name: "cb_a", Working: 5 * time.Minute, LastStatus: tmux.StatusIdle, LastSeen: seen},
+		"cb_b": {Name: "cb_b", Working: 2 * time.Hour, LastStatus: tmux.StatusWorking, LastSeen: seen},
+		"cb_c": {Name: "cb_c", LastStatus: tmux.StatusDone, LastSeen: seen},
+	}
+
+	var names []string
+	for _, row := range statsRows(sessions, nil) {
+		names = append(names, row.Name)
+	}
+	if got := strings.Join(names, " "); got != "cb_b cb_a cb_c" {
+		t.Fatalf("statsRows() order = %q, want most active first", got)
+	}
+	if rows := statsRows(sessions, []string{"cb_a", "cb_gone"}); len(rows) != 1 || rows[0].Name != "cb_a" {
+		t.Fatalf("statsRows(cb_a) = %+v", rows)
+	}
+
+	var buf bytes.Buffer
+	writeStats(&buf, statsRows(sessions, nil))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "SESSION") {
+		t.Fatalf("writeStats() = %q", buf.String())
+	}
+	if !strings.Contains(lines[1], "cb_b") || !strings.Contains(lines[1], "2h00m") || !strings.Contains(lines[1], "WORKING") {
+		t.Fatalf("first row = %q, want cb_b with 2h00m", lines[1])
+	}
+
+	buf.Reset()
+	writeStats(&buf, nil)
+	if !strings.Contains(buf.String(), "No recorded activity") {
+		t.Fatalf("writeStats(nil) = %q", buf.String())
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var statusExitCode bool
+var statusJSON bool
+
+// Exit statuses of cb status --exit-code. 1 stays reserved for other errors
+// and 3 matches cb wait's vanished-session status.
+const (
+	statusExitIdle    = 0
+	statusExitWorking = 10
+	statusExitWaiting = 20
+	statusExitError   = 30
+	statusExitLimited = 40
+	statusExitGone    = waitExitGone
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [session-name]",
+	Short: "Print a session's rolled-up agent status",
+	Long: `Prints the rolled-up agent status (WORKING, ERROR, WAITING, LIMITED, IDLE,
+or DONE) of a workflow session, defaulting to the session for the current directory.
+
+With --exit-code, the status is also reported through the exit status so tmux
+hooks and scripts can branch with plain shell conditionals:
+
+  0   IDLE or DONE
+  10  WORKING
+  20  WAITING
+  30  ERROR
+  40  LIMITED
+  3   the session does not exist
+  1   any other error
+
+With --json, the status is printed as an object that also carries what a
+WAITING agent waits for ("permission", "question", or "input") and when a
+LIMITED agent's quota resets.
+
+Example:
+  cb status feat-auth
+  cb status --json feat-auth
+  cb status --exit-code feat-auth; [ $? -eq 20 ] && notify-send "needs input"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusExitCode, "exit-code", false, "exit with a status-dependent code (0 idle/done, 10 working, 20 waiting, 30 error, 40 limited)")
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "print the status, waiting reason, and limit reset time as JSON")
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	sessionName, _, err := resolveWorkflowTarget(tmuxClient, args)
+	if err != nil {
+		return err
+	}
+
+	info, err := sessionStatus(tmuxClient, sessionName)
+	if err != nil {
+		return err
+	}
+	if statusJSON {
+		if err := writeStatusReport(cmd.OutOrStdout(), sessionName, info); err != nil {
+			return err
+		}
+	} else {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), info.Status)
+	}
+
+	if code := statusExitCodeFor(info.Status); statusExitCode && code != 0 {
+		// The exit status is the answer, not a failure: skip cobra's error
+		// and usage output.
+		cmd.SilenceErrors = true
+		cmd.SilenceUsage = true
+		return &exitCodeError{code: code}
+	}
+	return nil
+}
+
+// sessionStatus returns the rolled-up agent info of session (see
+// sessionInfoFromWindows). A missing session is reported as an exitCodeError
+// with statusExitGone.
+func sessionStatus(client waitTmuxClient, session string) (tmux.AgentInfo, error) {
+	wins, err := client.ListWindows(session)
+	switch {
+	case err == nil:
+		return sessionInfoFromWindows(client, session, wins), nil
+	case errors.Is(err, tmux.ErrNoSession) || errors.Is(err, tmux.ErrNoServer):
+		return tmux.AgentInfo{}, &exitCodeError{code: statusExitGone, err: fmt.Errorf("session %s not found: %w", session, err)}
+	default:
+		return tmux.AgentInfo{}, fmt.Errorf("failed to read status of %s: %w", session, err)
+	}
+}
+
+// statusReport is the --json output of cb status.
+type statusReport struct {
+	Session       string             `json:"session"`
+	Status        tmux.Status        `json:"status"`
+	WaitingReason tmux.WaitingReason `json:"waiting_reason,omitempty"`
+	LimitReset    string             `json:"limit_reset,omitempty"`
+}
+
+// writeStatusReport prints a session's rolled-up agent info as indented JSON.
+func writeStatusReport(w io.Writer, session string, info tmux.AgentInfo) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(statusReport{
+		Session:       session,
+		Status:        info.Status,
+		WaitingReason: info.WaitingReason,
+		LimitReset:    info.LimitReset,
+	})
+}
+
+// statusExitCodeFor maps a status to its --exit-code value.
+func statusExitCodeFor(status tmux.Status) int {
+	switch status {
+	case tmux.StatusWorking:
+		return statusExitWorking
+	case tmux.StatusWaiting:
+		return statusExitWaiting
+	case tmux.StatusError:
+		return statusExitError
+	case tmux.StatusLimited:
+		return statusExitLimited
+	default:
+		return statusExitIdle
+	}
+}
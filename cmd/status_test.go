@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestSessionStatusExitCodes(t *testing.T) {
+	tests := []struct {
+		status   tmux.Status
+		wantCode int
+	}{
+		{status: tmux.StatusWorking, wantCode: statusExitWorking},
+		{status: tmux.StatusWaiting, wantCode: statusExitWaiting},
+		{status: tmux.StatusError, wantCode: statusExitError},
+		{status: tmux.StatusLimited, wantCode: statusExitLimited},
+		{status: tmux.StatusIdle, wantCode: statusExitIdle},
+		{status: tmux.StatusDone, wantCode: statusExitIdle},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			info, err := sessionStatus(&fakeWaitTmuxClient{statuses: []tmux.Status{tt.status}}, "cb_feat")
+			if err != nil || info.Status != tt.status {
+				t.Fatalf("sessionStatus() = %s, %v; want %s", info.Status, err, tt.status)
+			}
+			if got := statusExitCodeFor(info.Status); got != tt.wantCode {
+				t.Fatalf("statusExitCodeFor(%s) = %d, want %d", info.Status, got, tt.wantCode)
+			}
+		})
+	}
+
+	_, err := sessionStatus(&fakeWaitTmuxClient{statuses: []tmux.Status{""}}, "cb_gone")
+	if err == nil || exitCode(err) != statusExitGone {
+		t.Fatalf("sessionStatus(gone) error = %v (exit %d), want exit %d", err, exitCode(err), statusExitGone)
+	}
+}
+
+func TestExitCodeErrorWithoutMessage(t *testing.T) {
+	err := &exitCodeError{code: statusExitWaiting}
+	if exitCode(err) != statusExitWaiting || err.Error() != "exit status 20" {
+		t.Fatalf("exitCodeError{code: 20} = %q (exit %d)", err.Error(), exitCode(err))
+	}
+}
+
+func TestWriteStatusReport(t *testing.T) {
+	var buf bytes.Buffer
+	info := tmux.AgentInfo{Status: tmux.StatusWaiting, WaitingReason: tmux.WaitingPermission}
+	if err := writeStatusReport(&buf, "cb_feat", info); err != nil {
+		t.Fatalf("writeStatusReport() error = %v", err)
+	}
+
+	var got statusReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	want := statusReport{Session: "cb_feat", Status: tmux.StatusWaiting, WaitingReason: tmux.WaitingPermission}
+	if got != want {
+		t.Fatalf("writeStatusReport() = %+v, want %+v", got, want)
+	}
+	if strings.Contains(buf.String(), "limit_reset") {
+		t.Fatalf("writeStatusReport() = %s, want limit_reset omitted", buf.String())
+	}
+}
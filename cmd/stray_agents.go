@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/logging"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// strayExecCommand runs ps and lsof for stray agent detection; tests
+// replace it.
+var strayExecCommand = func(name string, args ...string) ([]byte, error) {
+	return logging.Output(exec.Command(name, args...))
+}
+
+// signalProcess sends SIGTERM to pid; tests replace it.
+var signalProcess = func(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Signal(syscall.SIGTERM)
+}
+
+// agentProcess is a running coding agent process.
+type agentProcess struct {
+	PID     int
+	Agent   tmux.AgentType
+	Command string
+	Cwd     string
+}
+
+// findStrayAgents returns the agent processes whose working directory is
+// dir or inside it. Agents are found by command line with ps, then their
+// working directories are looked up with lsof.
+func findStrayAgents(dir string) ([]agentProcess, error) {
+	if canonical, err := config.CanonicalPath(dir); err == nil {
+		dir = canonical
+	}
+
+	output, err := strayExecCommand("ps", "-axo", "pid=,command=")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	agents := parseAgentProcesses(output, os.Getpid())
+	if len(agents) == 0 {
+		return nil, nil
+	}
+
+	pids := make([]string, 0, len(agents))
+	for _, a := range agents {
+		pids = append(pids, strconv.Itoa(a.PID))
+	}
+	// lsof exits non-zero when any listed process is gone or not
+	// inspectable, but still reports the others.
+	output, err = strayExecCommand("lsof", "-a", "-d", "cwd", "-Fpn", "-w", "-p", strings.Join(pids, ","))
+	if err != nil && len(output) == 0 {
+		return nil, fmt.Errorf("failed to look up process directories: %w", err)
+	}
+	cwds := parseLsofCwds(output)
+
+	var strays []agentProcess
+	for _, a := range agents {
+		cwd, ok := cwds[a.PID]
+		if !ok || !discovery.IsPathWithinOrEqual(cwd, dir) {
+			continue
+		}
+		a.Cwd = cwd
+		strays = append(strays, a)
+	}
+	return strays, nil
+}
+
+// parseAgentProcesses returns the agents among `ps -o pid=,command=` rows,
+// skipping self.
+func parseAgentProcesses(output []byte, self int) []agentProcess {
+	var agents []agentProcess
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		pidField, command, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if !ok {
+			continue
+		}
+		pid, err := strconv.Atoi(pidField)
+		if err != nil || pid == self {
+			continue
+		}
+		command = strings.TrimSpace(command)
+		if agent := tmux.ClassifyCommand(command); agent != tmux.AgentNone {
+			agents = append(agents, agentProcess{PID: pid, Agent: agent, Command: command})
+		}
+	}
+	return agents
+}
+
+// parseLsofCwds maps pids to working directories from `lsof -Fpn` output,
+// where "p<pid>" starts a process and "n<path>" names its file.
+func parseLsofCwds(output []byte) map[int]string {
+	cwds := make(map[int]string)
+	pid := -1
+	for line := range strings.SplitSeq(string(output), "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			if n, err := strconv.Atoi(line[1:]); err == nil {
+				pid = n
+			} else {
+				pid = -1
+			}
+		case 'n':
+			if pid >= 0 {
+				cwds[pid] = line[1:]
+			}
+		}
+	}
+	return cwds
+}
+
+// killStrayAgents terminates the agent processes still working in dir,
+// reporting each to out. Failures only warn: the archive goes on.
+func killStrayAgents(dir string, out, errWriter io.Writer) {
+	strays, err := findStrayAgents(dir)
+	if err != nil {
+		_, _ = fmt.Fprintf(errWriter, "Warning: failed to look for stray agents: %v\n", err)
+		return
+	}
+	for _, p := range strays {
+		if err := signalProcess(p.PID); err != nil {
+			_, _ = fmt.Fprintf(errWriter, "Warning: failed to terminate %s process %d: %v\n", p.Agent, p.PID, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "Terminated stray %s process %d (%s)\n", p.Agent, p.PID, p.Command)
+	}
+}
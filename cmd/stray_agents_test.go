@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+)
+
+func TestKillStrayAgents(t *testing.T) {
+	worktree, err := config.CanonicalPath(t.TempDir())
+	if err != nil {
+		t.Fatalf("CanonicalPath() error = %v", err)
+	}
+	elsewhere := filepath.Join(filepath.Dir(worktree), "other")
+
+	origExec, origSignal := strayExecCommand, signalProcess
+	defer func() { strayExecCommand, signalProcess = origExec, origSignal }()
+
+	var lsofArgs string
+	strayExecCommand = func(name string, args ...string) ([]byte, error) {
+		switch name {
+		case "ps":
+			return []byte(strings.Join([]string{
+				"  101 claude --continue",
+				"  102 codex",
+				"  103 vim main.go",
+				"  104 node /usr/lib/node_modules/@anthropic-ai/claude-code/cli.js",
+			}, "\n")), nil
+		case "lsof":
+			lsofArgs = strings.Join(args, " ")
+			output := strings.Join([]string{
+				"p101", "fcwd", "n" + filepath.Join(worktree, "pkg"),
+				"p102", "fcwd", "n" + elsewhere,
+				"p104", "fcwd", "n" + worktree,
+			}, "\n")
+			return []byte(output), errors.New("exit status 1")
+		}
+		return nil, errors.New("unexpected command " + name)
+	}
+	var signaled []int
+	signalProcess = func(pid int) error {
+		signaled = append(signaled, pid)
+		return nil
+	}
+
+	var out, errOut bytes.Buffer
+	killStrayAgents(worktree, &out, &errOut)
+
+	if !strings.HasSuffix(lsofArgs, "-p 101,102,104") {
+		t.Fatalf("lsof args = %q, want only the agent pids", lsofArgs)
+	}
+	if !reflect.DeepEqual(signaled, []int{101, 104}) {
+		t.Fatalf("signaled = %v, want [101 104]", signaled)
+	}
+	if !strings.Contains(out.String(), "Terminated stray claude process 101 (claude --continue)") {
+		t.Fatalf("output = %q, want a line per terminated process", out.String())
+	}
+	if errOut.Len() != 0 {
+		t.Fatalf("stderr = %q, want empty", errOut.String())
+	}
+}
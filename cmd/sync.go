@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var syncUseMerge bool
+var syncNoFetch bool
+
+var syncCmd = &cobra.Command{
+	Use:   "sync [session-name...]",
+	Short: "Fetch and rebase active worktree branches onto their base",
+	Long: `Fetches each configured project and rebases every active worktree's branch
+onto the base branch (the main repo's checked-out branch, or its upstream when
+one is configured). Conflicting rebases are aborted and reported per worktree.
+
+Example:
+  cb sync                  # Rebase all active worktrees
+  cb sync feat-auth        # Only the worktree owning cb_feat-auth
+  cb sync --merge          # Merge base into each branch instead of rebasing`,
+	RunE: runSync,
+}
+
+func init() {
+	syncCmd.Flags().BoolVar(&syncUseMerge, "merge", false, "merge the base branch instead of rebasing")
+	syncCmd.Flags().BoolVar(&syncNoFetch, "no-fetch", false, "skip fetching remotes before syncing")
+	rootCmd.AddCommand(syncCmd)
+}
+
+type syncGitClient interface {
+	CurrentBranch(dir string) (string, error)
+	StatusPorcelain(dir string) ([]string, error)
+	Merge(dir, branch string, ffOnly bool) error
+	Rebase(dir, onto string) error
+	AbortRebase(dir string) error
+	AbortMerge(dir string) error
+	ConflictedFiles(dir string) ([]string, error)
+}
+
+// syncTarget is one active worktree selected for syncing.
+type syncTarget struct {
+	ProjectName  string
+	MainRepoPath string
+	WorktreeName string
+	WorktreePath string
+}
+
+type syncOutcome string
+
+const (
+	syncUpdated  syncOutcome = "updated"
+	syncConflict syncOutcome = "conflict"
+	syncSkipped  syncOutcome = "skipped"
+	syncFailed   syncOutcome = "failed"
+)
+
+type syncResult struct {
+	Outcome   syncOutcome
+	Detail    string
+	Conflicts []string
+}
+
+// syncTargetsFromDiscovery returns linked worktrees with at least one session,
+// optionally restricted to worktrees owning one of sessionNames.
+func syncTargetsFromDiscovery(result discovery.Result, sessionNames []string) []syncTarget {
+	wanted := make(map[string]struct{}, len(sessionNames))
+	for _, name := range sessionNames {
+		wanted[name] = struct{}{}
+	}
+
+	var targets []syncTarget
+	for _, project := range result.Projects {
+		if project.InvalidError != "" {
+			continue
+		}
+		for _, wt := range project.Worktrees {
+			if wt.IsMainRepo || wt.IsMissing || len(wt.Sessions) == 0 {
+				continue
+			}
+			if len(wanted) > 0 && !worktreeHasSession(wt, wanted) {
+				continue
+			}
+			targets = append(targets, syncTarget{
+				ProjectName:  project.Name,
+				MainRepoPath: project.Path,
+				WorktreeName: wt.Name,
+				WorktreePath: wt.Path,
+			})
+		}
+	}
+	return targets
+}
+
+func worktreeHasSession(wt discovery.WorktreeNode, names map[string]struct{}) bool {
+	for _, s := range wt.Sessions {
+		if _, ok := names[s.Name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// syncWorktree rebases (or merges) onto in worktreePath, aborting on conflict.
+func syncWorktree(gitClient syncGitClient, worktreePath, baseBranch, onto string, useMerge bool) syncResult {
+	dirty, err := gitClient.StatusPorcelain(worktreePath)
+	if err != nil {
+		return syncResult{Outcome: syncFailed, Detail: err.Error()}
+	}
+	if len(dirty) > 0 {
+		return syncResult{Outcome: syncSkipped, Detail: fmt.Sprintf("%d uncommitted change(s)", len(dirty))}
+	}
+
+	branch, err := gitClient.CurrentBranch(worktreePath)
+	if err != nil {
+		return syncResult{Outcome: syncFailed, Detail: err.Error()}
+	}
+	if branch == baseBranch {
+		return syncResult{Outcome: syncSkipped, Detail: "worktree is on the base branch"}
+	}
+
+	verb := "rebased onto"
+	if useMerge {
+		verb = "merged"
+		err = gitClient.Merge(worktreePath, onto, false)
+	} else {
+		err = gitClient.Rebase(worktreePath, onto)
+	}
+	if err == nil {
+		return syncResult{Outcome: syncUpdated, Detail: verb + " " + onto}
+	}
+
+	conflicts, _ := gitClient.ConflictedFiles(worktreePath)
+	if useMerge {
+		_ = gitClient.AbortMerge(worktreePath)
+	} else {
+		_ = gitClient.AbortRebase(worktreePath)
+	}
+	if len(conflicts) > 0 {
+		return syncResult{Outcome: syncConflict, Detail: "aborted; resolve manually", Conflicts: conflicts}
+	}
+	return syncResult{Outcome: syncFailed, Detail: err.Error()}
+}
+
+func formatSyncResult(name string, r syncResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %-30s %s (%s)", name, r.Outcome, r.Detail)
+	for _, f := range r.Conflicts {
+		fmt.Fprintf(&b, "\n      conflict: %s", f)
+	}
+	return b.String()
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	result, err := discovery.NewService(tmuxClient).Discover()
+	if err != nil {
+		return err
+	}
+
+	sessionNames := make([]string, 0, len(args))
+	for _, arg := range args {
+		sessionNames = append(sessionNames, resolveSessionName(tmuxClient, arg))
+	}
+	targets := syncTargetsFromDiscovery(result, sessionNames)
+	out := cmd.OutOrStdout()
+	if len(targets) == 0 {
+		_, _ = fmt.Fprintln(out, "No active worktrees to sync.")
+		return nil
+	}
+
+	gitClient := git.NewClient()
+	failures := syncProjects(gitClient, targets, out)
+	if failures > 0 {
+		return fmt.Errorf("sync finished with %d worktree(s) needing attention", failures)
+	}
+	return nil
+}
+
+// syncProjects fetches each project once and syncs its targets in order,
+// returning the number of worktrees that conflicted or failed.
+func syncProjects(gitClient *git.Client, targets []syncTarget, out io.Writer) int {
+	failures := 0
+	currentProject := ""
+	var baseBranch, onto string
+	var projectErr error
+
+	for _, t := range targets {
+		if t.MainRepoPath != currentProject {
+			currentProject = t.MainRepoPath
+			_, _ = fmt.Fprintln(out, t.ProjectName)
+			baseBranch, onto, projectErr = resolveSyncBase(gitClient, t.MainRepoPath)
+		}
+		if projectErr != nil {
+			_, _ = fmt.Fprintln(out, formatSyncResult(t.WorktreeName, syncResult{Outcome: syncFailed, Detail: projectErr.Error()}))
+			failures++
+			continue
+		}
+
+		r := syncWorktree(gitClient, t.WorktreePath, baseBranch, onto, syncUseMerge)
+		if r.Outcome == syncConflict || r.Outcome == syncFailed {
+			failures++
+		}
+		_, _ = fmt.Fprintln(out, formatSyncResult(t.WorktreeName, r))
+	}
+	return failures
+}
+
+func resolveSyncBase(gitClient *git.Client, mainRepoPath string) (baseBranch, onto string, err error) {
+	if !syncNoFetch {
+		if err := gitClient.Fetch(mainRepoPath); err != nil {
+			return "", "", err
+		}
+	}
+
+	baseBranch, err = gitClient.CurrentBranch(mainRepoPath)
+	if err != nil {
+		return "", "", err
+	}
+	onto = baseBranch
+	if upstream, upstreamErr := gitClient.Upstream(mainRepoPath, baseBranch); upstreamErr == nil && upstream != "" {
+		onto = upstream
+	}
+	return baseBranch, onto, nil
+}
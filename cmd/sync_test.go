@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+)
+
+type fakeSyncGitClient struct {
+	branch     string
+	dirty      []string
+	rebaseErr  error
+	mergeErr   error
+	conflicts  []string
+	calls      []string
+	mergedFrom string
+}
+
+func (f *fakeSyncGitClient) CurrentBranch(dir string) (string, error) {
+	return f.branch, nil
+}
+
+func (f *fakeSyncGitClient) StatusPorcelain(dir string) ([]string, error) {
+	return f.dirty, nil
+}
+
+func (f *fakeSyncGitClient) Merge(dir, branch string, ffOnly bool) error {
+	f.calls = append(f.calls, "merge")
+	f.mergedFrom = branch
+	return f.mergeErr
+}
+
+func (f *fakeSyncGitClient) Rebase(dir, onto string) error {
+	f.calls = append(f.calls, "rebase")
+	return f.rebaseErr
+}
+
+func (f *fakeSyncGitClient) AbortRebase(dir string) error {
+	f.calls = append(f.calls, "abort-rebase")
+	return nil
+}
+
+func (f *fakeSyncGitClient) AbortMerge(dir string) error {
+	f.calls = append(f.calls, "abort-merge")
+	return nil
+}
+
+func (f *fakeSyncGitClient) ConflictedFiles(dir string) ([]string, error) {
+	return f.conflicts, nil
+}
+
+func TestSyncTargetsFromDiscovery(t *testing.T) {
+	result := discovery.Result{
+		Projects: []discovery.ProjectNode{
+			{
+				Name: "repo",
+				Path: "/code/repo",
+				Worktrees: []discovery.WorktreeNode{
+					{Name: "(main repo)", Path: "/code/repo", IsMainRepo: true, Sessions: []discovery.SessionNode{{Name: "cb_main"}}},
+					{Name: ".worktrees/repo-a", Path: "/code/repo/.worktrees/repo-a", Sessions: []discovery.SessionNode{{Name: "cb_a"}}},
+					{Name: ".worktrees/repo-idle", Path: "/code/repo/.worktrees/repo-idle"},
+					{Name: ".worktrees/repo-b", Path: "/code/repo/.worktrees/repo-b", Sessions: []discovery.SessionNode{{Name: "cb_b"}}},
+				},
+			},
+			{Name: "broken", Path: "/missing", InvalidError: "missing"},
+		},
+	}
+
+	t.Run("all active linked worktrees", func(t *testing.T) {
+		targets := syncTargetsFromDiscovery(result, nil)
+		if len(targets) != 2 {
+			t.Fatalf("len(targets) = %d, want 2", len(targets))
+		}
+		if targets[0].WorktreePath != "/code/repo/.worktrees/repo-a" || targets[0].MainRepoPath != "/code/repo" {
+			t.Fatalf("targets[0] = %+v, want repo-a under /code/repo", targets[0])
+		}
+	})
+
+	t.Run("restricted by session name", func(t *testing.T) {
+		targets := syncTargetsFromDiscovery(result, []string{"cb_b"})
+		if len(targets) != 1 || targets[0].WorktreeName != ".worktrees/repo-b" {
+			t.Fatalf("targets = %+v, want only repo-b", targets)
+		}
+	})
+}
+
+func TestSyncWorktree(t *testing.T) {
+	t.Run("rebases clean branch", func(t *testing.T) {
+		client := &fakeSyncGitClient{branch: "feat"}
+		r := syncWorktree(client, "/wt", "main", "origin/main", false)
+		if r.Outcome != syncUpdated {
+			t.Fatalf("outcome = %q, want %q", r.Outcome, syncUpdated)
+		}
+		if !strings.Contains(r.Detail, "origin/main") {
+			t.Fatalf("detail = %q, want onto ref", r.Detail)
+		}
+	})
+
+	t.Run("merge mode merges onto ref", func(t *testing.T) {
+		client := &fakeSyncGitClient{branch: "feat"}
+		r := syncWorktree(client, "/wt", "main", "main", true)
+		if r.Outcome != syncUpdated || client.mergedFrom != "main" {
+			t.Fatalf("result = %+v mergedFrom = %q, want merged main", r, client.mergedFrom)
+		}
+	})
+
+	t.Run("skips dirty worktree", func(t *testing.T) {
+		client := &fakeSyncGitClient{branch: "feat", dirty: []string{" M a.go"}}
+		r := syncWorktree(client, "/wt", "main", "main", false)
+		if r.Outcome != syncSkipped {
+			t.Fatalf("outcome = %q, want %q", r.Outcome, syncSkipped)
+		}
+		if len(client.calls) != 0 {
+			t.Fatalf("calls = %v, want none", client.calls)
+		}
+	})
+
+	t.Run("skips base branch worktree", func(t *testing.T) {
+		client := &fakeSyncGitClient{branch: "main"}
+		r := syncWorktree(client, "/wt", "main", "origin/main", false)
+		if r.Outcome != syncSkipped {
+			t.Fatalf("outcome = %q, want %q", r.Outcome, syncSkipped)
+		}
+	})
+
+	t.Run("conflict aborts and reports files", func(t *testing.T) {
+		client := &fakeSyncGitClient{branch: "feat", rebaseErr: errors.New("conflict"), conflicts: []string{"main.go"}}
+		r := syncWorktree(client, "/wt", "main", "main", false)
+		if r.Outcome != syncConflict {
+			t.Fatalf("outcome = %q, want %q", r.Outcome, syncConflict)
+		}
+		if len(r.Conflicts) != 1 || r.Conflicts[0] != "main.go" {
+			t.Fatalf("conflicts = %v, want [main.go]", r.Conflicts)
+		}
+		if client.calls[len(client.calls)-1] != "abort-rebase" {
+			t.Fatalf("calls = %v, want rebase aborted", client.calls)
+		}
+	})
+
+	t.Run("non-conflict error is a failure", func(t *testing.T) {
+		client := &fakeSyncGitClient{branch: "feat", mergeErr: errors.New("boom")}
+		r := syncWorktree(client, "/wt", "main", "main", true)
+		if r.Outcome != syncFailed {
+			t.Fatalf("outcome = %q, want %q", r.Outcome, syncFailed)
+		}
+		if client.calls[len(client.calls)-1] != "abort-merge" {
+			t.Fatalf("calls = %v, want merge aborted", client.calls)
+		}
+	})
+}
+
+func TestFormatSyncResult(t *testing.T) {
+	line := formatSyncResult(".worktrees/repo-a", syncResult{
+		Outcome:   syncConflict,
+		Detail:    "aborted; resolve manually",
+		Conflicts: []string{"a.go", "b.go"},
+	})
+	if !strings.Contains(line, "conflict (aborted; resolve manually)") {
+		t.Fatalf("line = %q, want outcome and detail", line)
+	}
+	if strings.Count(line, "conflict: ") != 2 {
+		t.Fatalf("line = %q, want one line per conflicted file", line)
+	}
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Tag sessions for filtering",
+	Long: `Tags label sessions (for example backend or urgent). They are kept in the
+session's @cb_tags tmux option, so they live as long as the session. cb dash
+shows them as #tag chips and filters on them with tag:<name> (e.g. "/tag:urgent"),
+and cb list --tag lists only the sessions carrying a tag.
+
+Example:
+  cb tag add feature backend urgent
+  cb tag rm feature urgent
+  cb tag list`,
+}
+
+var tagAddCmd = &cobra.Command{
+	Use:   "add <session-name> <tag>...",
+	Short: "Add tags to a session",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTagAdd,
+}
+
+var tagRmCmd = &cobra.Command{
+	Use:   "rm <session-name> <tag>...",
+	Short: "Remove tags from a session",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTagRm,
+}
+
+var tagListCmd = &cobra.Command{
+	Use:   "list [session-name]",
+	Short: "List session tags",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runTagList,
+}
+
+func init() {
+	tagCmd.AddCommand(tagAddCmd)
+	tagCmd.AddCommand(tagRmCmd)
+	tagCmd.AddCommand(tagListCmd)
+	rootCmd.AddCommand(tagCmd)
+}
+
+// normalizeTags lowercases tags and rejects ones that cannot round-trip
+// through the stored list or a tag: filter.
+func normalizeTags(raw []string) ([]string, error) {
+	tags := make([]string, 0, len(raw))
+	for _, r := range raw {
+		tag := strings.ToLower(strings.TrimSpace(r))
+		if tag == "" || strings.ContainsAny(tag, ",: \t") {
+			return nil, fmt.Errorf("invalid tag %q: tags cannot be empty or contain commas, colons, or spaces", r)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// updateSessionTags adds and removes tags on session and returns its
+// resulting tags. The option is unset once no tags remain.
+func updateSessionTags(tmuxClient sessionOptionClient, session string, add, remove []string) ([]string, error) {
+	if !tmuxClient.HasSession(session) {
+		return nil, fmt.Errorf("session %s not found", session)
+	}
+	raw, err := tmuxClient.GetSessionOption(session, tmux.SessionOptionTags)
+	if err != nil {
+		// tmux reports an unset user option as an error.
+		raw = ""
+	}
+	tags := tmux.ParseTags(raw + "," + strings.Join(add, ","))
+	tags = slices.DeleteFunc(tags, func(tag string) bool { return slices.Contains(remove, tag) })
+
+	if len(tags) == 0 {
+		return nil, tmuxClient.UnsetSessionOption(session, tmux.SessionOptionTags)
+	}
+	return tags, tmuxClient.SetSessionOption(session, tmux.SessionOptionTags, strings.Join(tags, ","))
+}
+
+func runTagAdd(cmd *cobra.Command, args []string) error {
+	return runTagUpdate(cmd, args, true)
+}
+
+func runTagRm(cmd *cobra.Command, args []string) error {
+	return runTagUpdate(cmd, args, false)
+}
+
+func runTagUpdate(cmd *cobra.Command, args []string, add bool) error {
+	tags, err := normalizeTags(args[1:])
+	if err != nil {
+		return err
+	}
+	tmuxClient := tmux.NewClient()
+	session := resolveSessionName(tmuxClient, args[0])
+	var updated []string
+	if add {
+		updated, err = updateSessionTags(tmuxClient, session, tags, nil)
+	} else {
+		updated, err = updateSessionTags(tmuxClient, session, nil, tags)
+	}
+	if err != nil {
+		return err
+	}
+	writeSessionTags(cmd.OutOrStdout(), session, updated)
+	return nil
+}
+
+func runTagList(cmd *cobra.Command, args []string) error {
+	tmuxClient := tmux.NewClient()
+	var sessions []string
+	if len(args) > 0 {
+		session := resolveSessionName(tmuxClient, args[0])
+		if !tmuxClient.HasSession(session) {
+			return fmt.Errorf("session %s not found", session)
+		}
+		sessions = []string{session}
+	} else {
+		all, err := tmuxClient.ListSessions()
+		if err != nil {
+			return err
+		}
+		for _, s := range all {
+			sessions = append(sessions, s.Name)
+		}
+	}
+
+	printed := false
+	for _, session := range sessions {
+		tags := tmuxClient.SessionTags(session)
+		if len(tags) == 0 && len(args) == 0 {
+			continue
+		}
+		writeSessionTags(cmd.OutOrStdout(), session, tags)
+		printed = true
+	}
+	if !printed {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No tagged sessions.")
+	}
+	return nil
+}
+
+func writeSessionTags(w io.Writer, session string, tags []string) {
+	if len(tags) == 0 {
+		_, _ = fmt.Fprintf(w, "%s (no tags)\n", session)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%s %s\n", session, formatTagChips(tags))
+}
+
+// formatTagChips renders tags as "#tag" chips separated by spaces.
+func formatTagChips(tags []string) string {
+	chips := make([]string, len(tags))
+	for i, tag := range tags {
+		chips[i] = "#" + tag
+	}
+	return strings.Join(chips, " ")
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	got, err := normalizeTags([]string{"Backend", " urgent "})
+	if err != nil {
+		t.Fatalf("normalizeTags() error = %v", err)
+	}
+	if want := []string{"backend", "urgent"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeTags() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"", "a,b", "tag:x", "two words"} {
+		if _, err := normalizeTags([]string{bad}); err == nil {
+			t.Errorf("normalizeTags(%q) should fail", bad)
+		}
+	}
+}
+
+func TestUpdateSessionTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		add      []string
+		remove   []string
+		want     []string
+		wantOpt  string
+	}{
+		{name: "adds to empty", add: []string{"urgent", "backend"}, want: []string{"backend", "urgent"}, wantOpt: "backend,urgent"},
+		{name: "merges without duplicates", existing: "backend", add: []string{"backend", "api"}, want: []string{"api", "backend"}, wantOpt: "api,backend"},
+		{name: "removes", existing: "backend,urgent", remove: []string{"urgent", "missing"}, want: []string{"backend"}, wantOpt: "backend"},
+		{name: "unsets when empty", existing: "urgent", remove: []string{"urgent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeNoteClient{sessions: map[string]bool{"cb_feat": true}, options: map[string]string{}}
+			if tt.existing != "" {
+				client.options["cb_feat/"+tmux.SessionOptionTags] = tt.existing
+			}
+
+			got, err := updateSessionTags(client, "cb_feat", tt.add, tt.remove)
+			if err != nil {
+				t.Fatalf("updateSessionTags() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("updateSessionTags() = %v, want %v", got, tt.want)
+			}
+			if opt := client.options["cb_feat/"+tmux.SessionOptionTags]; opt != tt.wantOpt {
+				t.Errorf("stored tags = %q, want %q", opt, tt.wantOpt)
+			}
+		})
+	}
+
+	client := &fakeNoteClient{sessions: map[string]bool{}, options: map[string]string{}}
+	if _, err := updateSessionTags(client, "cb_gone", []string{"x"}, nil); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("updateSessionTags() error = %v, want not found", err)
+	}
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/tmuxp"
+	"github.com/spf13/cobra"
+)
+
+var templateImportName string
+var templateImportForce bool
+
+var templateCmd = &cobra.Command{
+	Use:   "template",
+	Short: "Manage session templates",
+	Long: `Session templates are named sets of windows (with pane commands and layouts)
+stored in config.toml and applied with cb start --template <name>.`,
+}
+
+var templateImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a tmuxp or tmuxinator YAML file as a session template",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTemplateImport,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List session templates",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplateList,
+}
+
+func init() {
+	templateImportCmd.Flags().StringVar(&templateImportName, "name", "", "template name (default: the file's session name)")
+	templateImportCmd.Flags().BoolVar(&templateImportForce, "force", false, "replace an existing template with the same name")
+
+	templateCmd.AddCommand(templateImportCmd)
+	templateCmd.AddCommand(templateListCmd)
+	rootCmd.AddCommand(templateCmd)
+}
+
+func runTemplateImport(cmd *cobra.Command, args []string) error {
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+
+	tmpl, err := tmuxp.Parse(content)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", args[0], err)
+	}
+	if name := strings.TrimSpace(templateImportName); name != "" {
+		tmpl.Name = name
+	}
+	if tmpl.Name == "" {
+		return fmt.Errorf("%s has no session name; pass --name", args[0])
+	}
+
+	err = config.UpdateUserConfig(func(cfg *config.UserConfig) error {
+		replaced := false
+		for i, existing := range cfg.Templates {
+			if existing.Name != tmpl.Name {
+				continue
+			}
+			if !templateImportForce {
+				return fmt.Errorf("template %q already exists (use --force to replace it)", tmpl.Name)
+			}
+			cfg.Templates[i] = tmpl
+			replaced = true
+		}
+		if !replaced {
+			cfg.Templates = append(cfg.Templates, tmpl)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Imported template %q (%d windows). Use it with: cb start --template %s <branch>\n",
+		tmpl.Name, len(tmpl.Windows), tmpl.Name)
+	return nil
+}
+
+func runTemplateList(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(cfg.Templates) == 0 {
+		_, _ = fmt.Fprintln(out, "No session templates. Import one with: cb template import <file>")
+		return nil
+	}
+
+	for _, t := range cfg.Templates {
+		_, _ = fmt.Fprintln(out, t.Name)
+		for _, w := range t.Windows {
+			detail := fmt.Sprintf("%d pane(s)", max(len(w.Panes), 1))
+			if w.Layout != "" {
+				detail += ", " + w.Layout
+			}
+			_, _ = fmt.Fprintf(out, "  %-20s %s\n", w.Name, detail)
+		}
+	}
+	return nil
+}
+
+// templateWindowSpecs converts a session template into window specs.
+func templateWindowSpecs(tmpl config.SessionTemplate) []windowSpec {
+	specs := make([]windowSpec, 0, len(tmpl.Windows))
+	for _, w := range tmpl.Windows {
+		specs = append(specs, windowSpec{Name: w.Name, Layout: w.Layout, Panes: w.Panes})
+	}
+	return specs
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+)
+
+func TestRunTemplateImport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	file := filepath.Join(home, "web.yaml")
+	content := "session_name: web\nwindows:\n  - window_name: editor\n    panes: [nvim, npm test]\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	templateImportName = ""
+	templateImportForce = false
+	cmd, out := testProjectCmd()
+	if err := runTemplateImport(cmd, []string{file}); err != nil {
+		t.Fatalf("runTemplateImport() error = %v", err)
+	}
+	if !strings.Contains(out.String(), `Imported template "web" (1 windows)`) {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	want := []config.SessionTemplate{{
+		Name:    "web",
+		Windows: []config.TemplateWindow{{Name: "editor", Panes: []string{"nvim", "npm test"}}},
+	}}
+	if !reflect.DeepEqual(cfg.Templates, want) {
+		t.Fatalf("Templates = %+v, want %+v", cfg.Templates, want)
+	}
+
+	if err := runTemplateImport(cmd, []string{file}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("second import error = %v, want already exists", err)
+	}
+
+	templateImportForce = true
+	templateImportName = "web2"
+	if err := runTemplateImport(cmd, []string{file}); err != nil {
+		t.Fatalf("renamed import error = %v", err)
+	}
+	templateImportForce = false
+	templateImportName = ""
+
+	cfg, err = config.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if len(cfg.Templates) != 2 {
+		t.Fatalf("len(Templates) = %d, want 2", len(cfg.Templates))
+	}
+}
+
+func TestBuildSessionWindows_FromTemplate(t *testing.T) {
+	client := &fakeRestoreTmuxClient{}
+	tmpl := config.SessionTemplate{
+		Name: "web",
+		Windows: []config.TemplateWindow{
+			{Name: "editor", Layout: "main-vertical", Panes: []string{"nvim", "", "npm test"}},
+			{Name: "shell"},
+		},
+	}
+
+	if err := buildSessionWindows(client, "cb_feat", "/wt", templateWindowSpecs(tmpl)); err != nil {
+		t.Fatalf("buildSessionWindows() error = %v", err)
+	}
+
+	want := []string{
+		"rename cb_feat editor",
+		"send cb_feat:editor nvim",
+		"split cb_feat:editor /wt",
+		"split cb_feat:editor /wt",
+		"send %1 npm test",
+		"layout cb_feat:editor main-vertical",
+		"window cb_feat shell /wt",
+	}
+	if !reflect.DeepEqual(client.calls, want) {
+		t.Fatalf("calls = %v, want %v", client.calls, want)
+	}
+}
+
+func TestAppendSessionWindows_KeepsInitialWindow(t *testing.T) {
+	client := &fakeRestoreTmuxClient{}
+	specs := []windowSpec{{Name: "shell", Panes: []string{""}}, {Name: "tests", Panes: []string{"npm test"}}}
+
+	if err := appendSessionWindows(client, "cb_feat", "/wt", specs); err != nil {
+		t.Fatalf("appendSessionWindows() error = %v", err)
+	}
+
+	want := []string{
+		"window cb_feat shell /wt",
+		"window cb_feat tests /wt npm test",
+	}
+	if !reflect.DeepEqual(client.calls, want) {
+		t.Fatalf("calls = %v, want %v", client.calls, want)
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+// Markers delimiting the block cb tmux-install manages in tmux.conf.
+const (
+	tmuxBlockBegin = "# >>> clawd-bay >>>"
+	tmuxBlockEnd   = "# <<< clawd-bay <<<"
+)
+
+var (
+	tmuxInstallFile       string
+	tmuxInstallDryRun     bool
+	tmuxInstallPopupKey   string
+	tmuxInstallWaitingKey string
+)
+
+var tmuxInstallCmd = &cobra.Command{
+	Use:   "tmux-install",
+	Short: "Add recommended ClawdBay key bindings to ~/.tmux.conf",
+	Long: `Writes a block of tmux key bindings for ClawdBay into ~/.tmux.conf, using the
+path of the running cb binary:
+
+  prefix + C   the dashboard in a tmux popup (cb dash --popup)
+  prefix + W   jump to the most urgent WAITING agent (cb focus)
+
+The block is delimited by "# >>> clawd-bay >>>" markers. Running the command
+again replaces the block in place (for example after moving the binary) and
+leaves the rest of the file untouched. Reload tmux afterwards with
+tmux source-file ~/.tmux.conf. On tmux older than 3.2, which has no popups,
+prefix + C opens the dashboard in a new window instead.
+
+Example:
+  cb tmux-install
+  cb tmux-install --dry-run
+  cb tmux-install --popup-key D --file ~/.config/tmux/tmux.conf`,
+	Args: cobra.NoArgs,
+	RunE: runTmuxInstall,
+}
+
+func init() {
+	tmuxInstallCmd.Flags().StringVar(&tmuxInstallFile, "file", "", "tmux config file to update (default ~/.tmux.conf)")
+	tmuxInstallCmd.Flags().BoolVar(&tmuxInstallDryRun, "dry-run", false, "print the bindings without writing them")
+	tmuxInstallCmd.Flags().StringVar(&tmuxInstallPopupKey, "popup-key", "C", "key (after the prefix) that opens the popup dashboard")
+	tmuxInstallCmd.Flags().StringVar(&tmuxInstallWaitingKey, "waiting-key", "W", "key (after the prefix) that jumps to the next WAITING agent")
+	rootCmd.AddCommand(tmuxInstallCmd)
+}
+
+func runTmuxInstall(cmd *cobra.Command, args []string) error {
+	binary, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the cb binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+	popups := true
+	if version, err := tmux.NewClient().Version(); err == nil && !version.AtLeast(tmux.PopupVersion) {
+		popups = false
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "tmux %s has no display-popup (added in %s); binding the dashboard in a new window instead\n", version, tmux.PopupVersion)
+	}
+	block := tmuxBindingBlock(binary, tmuxInstallPopupKey, tmuxInstallWaitingKey, popups)
+
+	out := cmd.OutOrStdout()
+	if tmuxInstallDryRun {
+		_, _ = fmt.Fprint(out, block)
+		return nil
+	}
+
+	path := tmuxInstallFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, ".tmux.conf")
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updated, changed := installTmuxBlock(string(existing), block)
+	if !changed {
+		_, _ = fmt.Fprintf(out, "ClawdBay bindings in %s are already up to date\n", path)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	_, _ = fmt.Fprintf(out, "Updated ClawdBay bindings in %s\nReload with: tmux source-file %s\n", path, path)
+	return nil
+}
+
+// tmuxBindingBlock returns the managed tmux.conf block for the cb binary at
+// binary, ending in a newline. Without popups (tmux older than
+// tmux.PopupVersion) the dashboard opens in a new window instead.
+func tmuxBindingBlock(binary, popupKey, waitingKey string, popups bool) string {
+	command := func(args string) string {
+		return tmuxDoubleQuote(shellQuote(binary) + " " + args)
+	}
+	lines := []string{tmuxBlockBegin, "# Managed by cb tmux-install; re-run it to update."}
+	if popups {
+		lines = append(lines, fmt.Sprintf("bind-key %s display-popup -E -w 80%% -h 60%% %s", popupKey, command("dash --popup")))
+	} else {
+		lines = append(lines, fmt.Sprintf("bind-key %s new-window -n cb %s", popupKey, command("dash")))
+	}
+	lines = append(lines,
+		fmt.Sprintf("bind-key %s run-shell %s", waitingKey, command("focus")),
+		tmuxBlockEnd,
+	)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// installTmuxBlock replaces the managed block in conf with block, or appends
+// block when conf has none. It reports whether conf changed.
+func installTmuxBlock(conf, block string) (string, bool) {
+	start := strings.Index(conf, tmuxBlockBegin)
+	if start >= 0 {
+		if end := strings.Index(conf[start:], tmuxBlockEnd); end >= 0 {
+			end += start + len(tmuxBlockEnd)
+			if end < len(conf) && conf[end] == '\n' {
+				end++
+			}
+			updated := conf[:start] + block + conf[end:]
+			return updated, updated != conf
+		}
+	}
+
+	if conf != "" && !strings.HasSuffix(conf, "\n") {
+		conf += "\n"
+	}
+	if conf != "" {
+		conf += "\n"
+	}
+	return conf + block, true
+}
+
+// shellQuote single-quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// tmuxDoubleQuote wraps s in a tmux.conf double-quoted string.
+func tmuxDoubleQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTmuxBindingBlock(t *testing.T) {
+	block := tmuxBindingBlock("/opt/my tools/cb", "C", "W", true)
+	want := tmuxBlockBegin + "\n" +
+		"# Managed by cb tmux-install; re-run it to update.\n" +
+		`bind-key C display-popup -E -w 80% -h 60% "'/opt/my tools/cb' dash --popup"` + "\n" +
+		`bind-key W run-shell "'/opt/my tools/cb' focus"` + "\n" +
+		tmuxBlockEnd + "\n"
+	if block != want {
+		t.Fatalf("tmuxBindingBlock() =\n%s\nwant\n%s", block, want)
+	}
+
+	if got := tmuxBindingBlock(`/tmp/$x"/cb`, "C", "W", true); !strings.Contains(got, `"'/tmp/\$x\"/cb' dash --popup"`) {
+		t.Fatalf("tmuxBindingBlock() did not escape the path: %s", got)
+	}
+}
+
+func TestTmuxBindingBlock_WithoutPopups(t *testing.T) {
+	block := tmuxBindingBlock("/usr/local/bin/cb", "C", "W", false)
+	if strings.Contains(block, "display-popup") {
+		t.Fatalf("tmuxBindingBlock() without popups uses display-popup:\n%s", block)
+	}
+	if !strings.Contains(block, `bind-key C new-window -n cb "'/usr/local/bin/cb' dash"`) ||
+		!strings.Contains(block, `bind-key W run-shell "'/usr/local/bin/cb' focus"`) {
+		t.Fatalf("tmuxBindingBlock() without popups =\n%s", block)
+	}
+}
+
+func TestInstallTmuxBlock(t *testing.T) {
+	block := tmuxBindingBlock("/usr/local/bin/cb", "C", "W", true)
+	moved := tmuxBindingBlock("/opt/cb", "C", "W", true)
+
+	tests := []struct {
+		name        string
+		conf        string
+		block       string
+		want        string
+		wantChanged bool
+	}{
+		{name: "empty file", conf: "", block: block, want: block, wantChanged: true},
+		{name: "appends after existing config", conf: "set -g mouse on", block: block, want: "set -g mouse on\n\n" + block, wantChanged: true},
+		{name: "already installed", conf: "set -g mouse on\n\n" + block, block: block, want: "set -g mouse on\n\n" + block},
+		{name: "replaces in place", conf: "a\n" + block + "b\n", block: moved, want: "a\n" + moved + "b\n", wantChanged: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := installTmuxBlock(tt.conf, tt.block)
+			if got != tt.want || changed != tt.wantChanged {
+				t.Fatalf("installTmuxBlock() = %q, %v; want %q, %v", got, changed, tt.want, tt.wantChanged)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/ronsanzone/clawd-bay/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	topInterval time.Duration
+	topSort     string
+	topOnce     bool
+)
+
+// topOnceSampleWindow is how long cb top --once measures CPU usage over.
+const topOnceSampleWindow = time.Second
+
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show live CPU and memory for every detected agent",
+	Long: `Shows a live table of every detected agent window with its status and the
+CPU, memory, and uptime of its agent process (from ps), so runaway agents are
+easy to spot. CPU is the usage since the previous refresh, so it shows "-"
+until the second one. Rows at or above 80% CPU are highlighted. Press c, m,
+or u to sort by CPU, memory, or uptime.
+
+--once prints the table a single time instead, for scripts, after measuring
+CPU over one second.
+
+Example:
+  cb top
+  cb top --sort mem --interval 5s
+  cb top --once`,
+	Args: cobra.NoArgs,
+	RunE: runTop,
+}
+
+func init() {
+	topCmd.Flags().DurationVar(&topInterval, "interval", 2*time.Second, "how often to refresh")
+	topCmd.Flags().StringVar(&topSort, "sort", string(tui.TopSortCPU), "initial sort: cpu, mem, or uptime")
+	topCmd.Flags().BoolVar(&topOnce, "once", false, "print the table once and exit")
+	rootCmd.AddCommand(topCmd)
+}
+
+func runTop(cmd *cobra.Command, args []string) error {
+	sortKey, err := tui.ParseTopSort(topSort)
+	if err != nil {
+		return err
+	}
+	if topInterval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	theme, _, err := dashboardDisplay()
+	if err != nil {
+		return err
+	}
+
+	if topOnce {
+		client, sampler := tmux.NewClient(), tui.NewCPUSampler()
+		tui.FetchTopRows(client, sampler)
+		time.Sleep(topOnceSampleWindow)
+		rows := tui.FetchTopRows(client, sampler)
+		tui.SortTopRows(rows, sortKey)
+		writeTopTable(cmd.OutOrStdout(), rows)
+		return nil
+	}
+
+	// As in cb dash, quitting cancels any in-flight tmux/ps commands.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := tmux.NewClientWithContext(ctx)
+	sampler := tui.NewCPUSampler()
+	model := tui.NewTopModel(func() []tui.TopRow { return tui.FetchTopRows(client, sampler) }, sortKey, topInterval)
+	model.Styles = tui.NewStyles(theme)
+	model.Plain = colorDisabled()
+	_, err = tea.NewProgram(model, tea.WithAltScreen()).Run()
+	return err
+}
+
+func writeTopTable(w io.Writer, rows []tui.TopRow) {
+	if len(rows) == 0 {
+		_, _ = fmt.Fprintln(w, "No detected agent windows.")
+		return
+	}
+	_, _ = fmt.Fprintln(w, tui.TopHeader())
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(w, tui.FormatTopRow(row))
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/ronsanzone/clawd-bay/internal/tui"
+)
+
+func TestWriteTopTable(t *testing.T) {
+	var buf bytes.Buffer
+	writeTopTable(&buf, nil)
+	if got := buf.String(); got != "No detected agent windows.\n" {
+		t.Fatalf("empty table = %q", got)
+	}
+
+	buf.Reset()
+	writeTopTable(&buf, []tui.TopRow{{
+		Agent:      tui.AgentWindowRow{SessionName: "cb_feat", WindowName: "codex", AgentType: tmux.AgentCodex, Status: tmux.StatusIdle},
+		Process:    tmux.AgentProcess{PID: 7, CPU: 1.5, RSSKB: 4096, Elapsed: time.Minute},
+		HasProcess: true,
+	}})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "SESSION:WINDOW") || !strings.Contains(lines[1], "cb_feat:codex") {
+		t.Fatalf("table = %q", buf.String())
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var (
+	waitFor     string
+	waitTimeout time.Duration
+)
+
+// Exit statuses of cb wait besides 0 (reached) and 1 (other errors). A
+// session that stops on ERROR or LIMITED exits with cb status's codes.
+const (
+	waitExitTimeout = 2
+	waitExitGone    = 3
+	waitExitError   = statusExitError
+	waitExitLimited = statusExitLimited
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait [session-name]",
+	Short: "Block until a session reaches a status",
+	Long: `Polls a workflow session's rolled-up agent status until it reaches the
+status given by --for, so scripts can send a prompt, wait, and then inspect
+the result.
+
+  waiting  an agent is WAITING for input and none is WORKING
+  done     no agent is running in the session anymore
+
+Waiting also stops when the session rolls up to ERROR (an agent crashed) or
+LIMITED (an agent hit a usage limit), since neither gets there on its own.
+
+Exit status: 0 when the status is reached, 2 on --timeout, 3 if the session
+disappears, 30 on ERROR, 40 on LIMITED, 1 on any other error.
+
+Example:
+  cb wait feat-auth                       # Until cb_feat-auth is WAITING
+  cb wait --for done --timeout 1h feat-auth
+  cb wait && git diff                     # Session for the current directory`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWait,
+}
+
+func init() {
+	waitCmd.Flags().StringVar(&waitFor, "for", "waiting", "Status to wait for: waiting or done")
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 0, "Give up after this long (0 means no limit)")
+	rootCmd.AddCommand(waitCmd)
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	target, err := parseWaitTarget(waitFor)
+	if err != nil {
+		return err
+	}
+
+	tmuxClient := tmux.NewClient()
+	sessionName, _, err := resolveWorkflowTarget(tmuxClient, args)
+	if err != nil {
+		return err
+	}
+
+	status, err := waitForSessionStatus(tmuxClient, newStatusPoller(waitTimeout), sessionName, target)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s is %s\n", sessionName, status)
+	return nil
+}
+
+// parseWaitTarget validates a --for value.
+func parseWaitTarget(value string) (tmux.Status, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "waiting":
+		return tmux.StatusWaiting, nil
+	case "done":
+		return tmux.StatusDone, nil
+	default:
+		return "", fmt.Errorf("invalid --for %q (want waiting or done)", value)
+	}
+}
+
+type waitTmuxClient interface {
+	listAgentDetector
+	ListWindows(session string) ([]tmux.Window, error)
+}
+
+// waitForSessionStatus polls the rolled-up status of session until it equals
+// target, or until it is ERROR or LIMITED. Those, timeouts, and a vanished
+// session are reported as exitCodeErrors.
+func waitForSessionStatus(client waitTmuxClient, poller statusPoller, session string, target tmux.Status) (tmux.Status, error) {
+	detect := func() (tmux.Status, error) {
+		wins, err := client.ListWindows(session)
+		if err != nil {
+			return "", err
+		}
+		return sessionStatusFromWindows(client, session, wins), nil
+	}
+	status, err := pollUntil(poller, detect, func(s tmux.Status) bool {
+		return s == target || s == tmux.StatusError || s == tmux.StatusLimited
+	})
+	switch {
+	case err == nil && status == target:
+		return status, nil
+	case err == nil && status == tmux.StatusError:
+		return status, &exitCodeError{code: waitExitError, err: fmt.Errorf("%s stopped on ERROR before becoming %s", session, target)}
+	case err == nil:
+		return status, &exitCodeError{code: waitExitLimited, err: fmt.Errorf("%s stopped on LIMITED before becoming %s", session, target)}
+	case errors.Is(err, errPollTimeout):
+		return status, &exitCodeError{code: waitExitTimeout, err: fmt.Errorf("%s did not become %s (last status %s): %w", session, target, status, err)}
+	case errors.Is(err, tmux.ErrNoSession) || errors.Is(err, tmux.ErrNoServer):
+		return status, &exitCodeError{code: waitExitGone, err: fmt.Errorf("session %s is gone: %w", session, err)}
+	default:
+		return status, fmt.Errorf("failed to read status of %s: %w", session, err)
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// fakeWaitTmuxClient reports one agent window whose status advances through
+// statuses on each poll; a "" status means the session is gone.
+type fakeWaitTmuxClient struct {
+	statuses []tmux.Status
+	polls    int
+}
+
+func (f *fakeWaitTmuxClient) ListWindows(session string) ([]tmux.Window, error) {
+	s := f.statuses[min(f.polls, len(f.statuses)-1)]
+	f.polls++
+	if s == "" {
+		return nil, fmt.Errorf("failed to list windows for %s: %w", session, tmux.ErrNoSession)
+	}
+	return []tmux.Window{{ID: "@1", Name: string(s)}}, nil
+}
+
+func (f *fakeWaitTmuxClient) DetectAgentInfo(target string) tmux.AgentInfo {
+	status := f.statuses[min(f.polls-1, len(f.statuses)-1)]
+	if status == tmux.StatusDone {
+		return tmux.AgentInfo{Type: tmux.AgentNone, Status: tmux.StatusDone}
+	}
+	return tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: status}
+}
+
+func TestWaitForSessionStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []tmux.Status
+		target   tmux.Status
+		wantCode int
+	}{
+		{name: "reaches waiting", statuses: []tmux.Status{tmux.StatusWorking, tmux.StatusWaiting}, target: tmux.StatusWaiting},
+		{name: "reaches done", statuses: []tmux.Status{tmux.StatusWorking, tmux.StatusIdle, tmux.StatusDone}, target: tmux.StatusDone},
+		{name: "times out", statuses: []tmux.Status{tmux.StatusWorking}, target: tmux.StatusWaiting, wantCode: waitExitTimeout},
+		{name: "agent crashed", statuses: []tmux.Status{tmux.StatusWorking, tmux.StatusError}, target: tmux.StatusWaiting, wantCode: waitExitError},
+		{name: "agent hit a limit", statuses: []tmux.Status{tmux.StatusWorking, tmux.StatusLimited}, target: tmux.StatusDone, wantCode: waitExitLimited},
+		{name: "session gone", statuses: []tmux.Status{tmux.StatusWorking, ""}, target: tmux.StatusDone, wantCode: waitExitGone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+			poller := statusPoller{Interval: time.Second, Timeout: 10 * time.Second, Now: clock.Now, Sleep: clock.Sleep}
+
+			status, err := waitForSessionStatus(&fakeWaitTmuxClient{statuses: tt.statuses}, poller, "cb_feat", tt.target)
+			if tt.wantCode == 0 {
+				if err != nil || status != tt.target {
+					t.Fatalf("waitForSessionStatus() = %s, %v; want %s", status, err, tt.target)
+				}
+				return
+			}
+			if err == nil || exitCode(err) != tt.wantCode {
+				t.Fatalf("waitForSessionStatus() error = %v (exit %d), want exit %d", err, exitCode(err), tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestParseWaitTarget(t *testing.T) {
+	if got, err := parseWaitTarget("Waiting"); err != nil || got != tmux.StatusWaiting {
+		t.Fatalf("parseWaitTarget(Waiting) = %s, %v", got, err)
+	}
+	if got, err := parseWaitTarget("done"); err != nil || got != tmux.StatusDone {
+		t.Fatalf("parseWaitTarget(done) = %s, %v", got, err)
+	}
+	if _, err := parseWaitTarget("working"); err == nil {
+		t.Fatal("parseWaitTarget(working) should fail")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if got := exitCode(errors.New("boom")); got != 1 {
+		t.Fatalf("exitCode(plain) = %d, want 1", got)
+	}
+	wrapped := fmt.Errorf("outer: %w", &exitCodeError{code: 3, err: errors.New("gone")})
+	if got := exitCode(wrapped); got != 3 {
+		t.Fatalf("exitCode(wrapped) = %d, want 3", got)
+	}
+}
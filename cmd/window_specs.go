@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// windowSpec describes a window to create in a new session. Panes holds the
+// command typed into each pane ("" leaves a plain shell); at least one pane
+// is always created. Layout is applied after all panes exist.
+type windowSpec struct {
+	Name   string
+	Layout string
+	Panes  []string
+}
+
+type windowBuilderClient interface {
+	RenameWindow(session, name string) error
+	CreateWindowWithShellInDir(session, name, command, workdir string) error
+	SendCommand(session, window, command string) error
+	SplitWindow(session, window, workdir string) (string, error)
+	SendCommandToPane(target, command string) error
+	SelectLayout(session, window, layout string) error
+}
+
+// buildSessionWindows creates specs in a freshly created session rooted at
+// workdir. The first spec reuses the session's initial window.
+func buildSessionWindows(tmuxClient windowBuilderClient, session, workdir string, specs []windowSpec) error {
+	return buildWindows(tmuxClient, session, workdir, specs, true)
+}
+
+// appendSessionWindows creates specs as new windows after the session's
+// existing ones.
+func appendSessionWindows(tmuxClient windowBuilderClient, session, workdir string, specs []windowSpec) error {
+	return buildWindows(tmuxClient, session, workdir, specs, false)
+}
+
+func buildWindows(tmuxClient windowBuilderClient, session, workdir string, specs []windowSpec, reuseFirst bool) error {
+	for i, spec := range specs {
+		first := ""
+		if len(spec.Panes) > 0 {
+			first = spec.Panes[0]
+		}
+
+		if i == 0 && reuseFirst {
+			if err := tmuxClient.RenameWindow(session, spec.Name); err != nil {
+				return err
+			}
+			if first != "" {
+				if err := tmuxClient.SendCommand(session, spec.Name, first); err != nil {
+					return err
+				}
+			}
+		} else if err := tmuxClient.CreateWindowWithShellInDir(session, spec.Name, first, workdir); err != nil {
+			return err
+		}
+
+		for j := 1; j < len(spec.Panes); j++ {
+			paneID, err := tmuxClient.SplitWindow(session, spec.Name, workdir)
+			if err != nil {
+				return err
+			}
+			if spec.Panes[j] == "" {
+				continue
+			}
+			if err := tmuxClient.SendCommandToPane(paneID, spec.Panes[j]); err != nil {
+				return err
+			}
+		}
+
+		if spec.Layout != "" {
+			if err := tmuxClient.SelectLayout(session, spec.Name, spec.Layout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseWindowsFlag parses a comma-separated list of "name" or "name:command"
+// entries into single-pane window specs.
+func parseWindowsFlag(value string) ([]windowSpec, error) {
+	var specs []windowSpec
+	seen := make(map[string]struct{})
+	for entry := range strings.SplitSeq(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, command, _ := strings.Cut(entry, ":")
+		name = strings.TrimSpace(name)
+		command = strings.Trim(strings.TrimSpace(command), `"'`)
+		if name == "" || strings.ContainsAny(name, ".:") {
+			return nil, fmt.Errorf("invalid window name in %q: names must be non-empty and cannot contain '.' or ':'", entry)
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("duplicate window name %q", name)
+		}
+		seen[name] = struct{}{}
+		specs = append(specs, windowSpec{Name: name, Panes: []string{command}})
+	}
+	return specs, nil
+}
@@ -31,7 +31,7 @@ func TestCLI_Help(t *testing.T) {
 		t.Fatalf("help command failed: %v", err)
 	}
 
-	expected := []string{"start", "list", "archive", "dash", "project"}
+	expected := []string{"start", "list", "archive", "merge", "clean", "gc", "dash", "project", "sync", "conflicts", "checkpoint", "restore", "template", "adopt", "run", "wait", "queue", "fanout", "compare", "daemon", "top", "note", "tag", "stats", "du", "export", "status", "focus", "tmux-install", "rename", "debug"}
 	for _, sub := range expected {
 		if !strings.Contains(string(output), sub) {
 			t.Errorf("help missing subcommand: %s", sub)
@@ -0,0 +1,176 @@
+// Package activity persists how long each session's agents have spent
+// WORKING, accumulated from the statuses seen on discovery refreshes.
+package activity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+const fileVersion = 1
+
+// MaxSampleGap bounds the time credited between two observations of a
+// WORKING session. Longer gaps mean nothing was watching, so the agent's
+// status in between is unknown and the gap is not counted.
+const MaxSampleGap = 2 * time.Minute
+
+// Session is the accumulated activity of one session. LastStatus and
+// LastSeen are the most recent observation.
+type Session struct {
+	Name       string        `json:"name"`
+	Working    time.Duration `json:"working_ns"`
+	LastStatus tmux.Status   `json:"last_status"`
+	LastSeen   time.Time     `json:"last_seen"`
+}
+
+type file struct {
+	Version  int       `json:"version"`
+	Sessions []Session `json:"sessions"`
+}
+
+// Store reads and writes the activity file at a fixed path.
+type Store struct {
+	path string
+}
+
+// NewStore creates a Store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the activity file location.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load returns the recorded sessions keyed by name. A missing file yields
+// no sessions.
+func (s *Store) Load() (map[string]Session, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]Session{}, nil
+		}
+		return nil, fmt.Errorf("failed to read activity file %s: %w", s.path, err)
+	}
+
+	var f file
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse activity file %s: %w", s.path, err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported activity file version %d in %s", f.Version, s.path)
+	}
+	sessions := make(map[string]Session, len(f.Sessions))
+	for _, sess := range f.Sessions {
+		sessions[sess.Name] = sess
+	}
+	return sessions, nil
+}
+
+// Record folds one observation of session statuses, taken at now, into the
+// store. A session that was WORKING at its last observation is credited
+// with the time since, up to MaxSampleGap.
+func (s *Store) Record(statuses map[string]tmux.Status, now time.Time) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	sessions, err := s.Load()
+	if err != nil {
+		return err
+	}
+	for name, status := range statuses {
+		sessions[name] = Observe(sessions[name], name, status, now)
+	}
+	return s.save(sessions)
+}
+
+// Observe returns sess updated with an observation of status at now.
+func Observe(sess Session, name string, status tmux.Status, now time.Time) Session {
+	sess.Name = name
+	if sess.LastStatus == tmux.StatusWorking && !sess.LastSeen.IsZero() {
+		if gap := now.Sub(sess.LastSeen); gap > 0 && gap <= MaxSampleGap {
+			sess.Working += gap
+		}
+	}
+	sess.LastStatus = status
+	sess.LastSeen = now
+	return sess
+}
+
+// Reset drops the named sessions' activity, or every session's when no
+// names are given.
+func (s *Store) Reset(names ...string) error {
+	sessions, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return s.save(map[string]Session{})
+	}
+	for _, name := range names {
+		delete(sessions, name)
+	}
+	return s.save(sessions)
+}
+
+// Rename moves oldName's activity to newName. An unknown oldName is ignored.
+func (s *Store) Rename(oldName, newName string) error {
+	sessions, err := s.Load()
+	if err != nil {
+		return err
+	}
+	sess, ok := sessions[oldName]
+	if !ok {
+		return nil
+	}
+	delete(sessions, oldName)
+	sess.Name = newName
+	sessions[newName] = sess
+	return s.save(sessions)
+}
+
+func (s *Store) save(sessions map[string]Session) error {
+	f := file{Version: fileVersion, Sessions: make([]Session, 0, len(sessions))}
+	for _, sess := range sessions {
+		f.Sessions = append(f.Sessions, sess)
+	}
+	sort.Slice(f.Sessions, func(i, j int) bool {
+		return f.Sessions[i].Name < f.Sessions[j].Name
+	})
+
+	content, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode activity file: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create activity directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "activity-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp activity file in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(append(content, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temp activity file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp activity file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace activity file %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package activity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestObserve(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		prev    Session
+		status  tmux.Status
+		at      time.Time
+		working time.Duration
+	}{
+		{name: "first observation credits nothing", status: tmux.StatusWorking, at: start},
+		{name: "working to working credits gap", prev: Session{LastStatus: tmux.StatusWorking, LastSeen: start, Working: time.Minute}, status: tmux.StatusWorking, at: start.Add(30 * time.Second), working: time.Minute + 30*time.Second},
+		{name: "working to idle credits gap", prev: Session{LastStatus: tmux.StatusWorking, LastSeen: start}, status: tmux.StatusIdle, at: start.Add(10 * time.Second), working: 10 * time.Second},
+		{name: "idle to working credits nothing", prev: Session{LastStatus: tmux.StatusIdle, LastSeen: start}, status: tmux.StatusWorking, at: start.Add(10 * time.Second)},
+		{name: "unwatched gap is skipped", prev: Session{LastStatus: tmux.StatusWorking, LastSeen: start}, status: tmux.StatusWorking, at: start.Add(MaxSampleGap + time.Second)},
+		{name: "clock going backwards is skipped", prev: Session{LastStatus: tmux.StatusWorking, LastSeen: start}, status: tmux.StatusWorking, at: start.Add(-time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Observe(tt.prev, "cb_a", tt.status, tt.at)
+			if got.Working != tt.working {
+				t.Errorf("Working = %v, want %v", got.Working, tt.working)
+			}
+			if got.Name != "cb_a" || got.LastStatus != tt.status || !got.LastSeen.Equal(tt.at) {
+				t.Errorf("Observe() = %+v, want latest observation recorded", got)
+			}
+		})
+	}
+}
+
+func TestStore_RecordAndReset(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", "activity.json"))
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	steps := []map[string]tmux.Status{
+		{"cb_a": tmux.StatusWorking, "cb_b": tmux.StatusIdle},
+		{"cb_a": tmux.StatusWorking, "cb_b": tmux.StatusWorking},
+		{"cb_a": tmux.StatusIdle, "cb_b": tmux.StatusWorking},
+	}
+	for i, statuses := range steps {
+		if err := store.Record(statuses, start.Add(time.Duration(i)*5*time.Second)); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+
+	sessions, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := sessions["cb_a"].Working; got != 10*time.Second {
+		t.Fatalf("cb_a working = %v, want 10s", got)
+	}
+	if got := sessions["cb_b"].Working; got != 5*time.Second {
+		t.Fatalf("cb_b working = %v, want 5s", got)
+	}
+
+	if err := store.Reset("cb_a"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	sessions, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := sessions["cb_a"]; ok || len(sessions) != 1 {
+		t.Fatalf("Load() after Reset = %v, want only cb_b", sessions)
+	}
+
+	if err := store.Rename("cb_b", "cb_c"); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	sessions, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := sessions["cb_c"]; got.Name != "cb_c" || got.Working != 5*time.Second || len(sessions) != 1 {
+		t.Fatalf("Load() after Rename = %v, want only cb_c with 5s", sessions)
+	}
+}
+
+func TestStore_RejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activity.json")
+	if err := os.WriteFile(path, []byte(`{"version": 9, "sessions": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewStore(path).Load(); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
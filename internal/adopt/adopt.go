@@ -0,0 +1,64 @@
+// Package adopt brings an existing tmux session under ClawdBay management
+// without recreating it, for cb adopt and the dashboard's adopt key.
+package adopt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// ErrNoHomePath means no home path was given and the session's first pane
+// directory is unknown.
+var ErrNoHomePath = errors.New("cannot determine a home path")
+
+// Client is the multiplexer surface Session needs.
+type Client interface {
+	HasSession(name string) bool
+	GetPaneWorkingDir(session string) string
+	RenameSession(oldName, newName string) error
+	SetSessionOption(session, key, value string) error
+}
+
+// Session renames session to its cb_ form and pins it to homePath,
+// returning the managed name and canonical home path. An empty homePath uses
+// the first pane's working directory.
+func Session(client Client, session, homePath string) (string, string, error) {
+	if !client.HasSession(session) {
+		return "", "", fmt.Errorf("tmux session %s not found", session)
+	}
+
+	if homePath == "" {
+		homePath = client.GetPaneWorkingDir(session)
+		if homePath == "" {
+			return "", "", fmt.Errorf("%w for %s", ErrNoHomePath, session)
+		}
+	}
+	canonicalHome, err := config.CanonicalPath(homePath)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid home path %s: %w", homePath, err)
+	}
+	if info, err := os.Stat(canonicalHome); err != nil || !info.IsDir() {
+		return "", "", fmt.Errorf("home path %s is not a directory", canonicalHome)
+	}
+
+	managed := session
+	if !strings.HasPrefix(session, "cb_") {
+		managed = "cb_" + session
+		if client.HasSession(managed) {
+			return "", "", fmt.Errorf("cannot adopt %s: session %s already exists", session, managed)
+		}
+		if err := client.RenameSession(session, managed); err != nil {
+			return "", "", err
+		}
+	}
+
+	if err := client.SetSessionOption(managed, tmux.SessionOptionHomePath, canonicalHome); err != nil {
+		return "", "", err
+	}
+	return managed, canonicalHome, nil
+}
@@ -0,0 +1,98 @@
+package adopt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeClient struct {
+	sessions map[string]bool
+	paneDir  string
+	renamed  [][2]string
+	options  map[string]string
+}
+
+func (f *fakeClient) HasSession(name string) bool { return f.sessions[name] }
+
+func (f *fakeClient) GetPaneWorkingDir(session string) string { return f.paneDir }
+
+func (f *fakeClient) RenameSession(oldName, newName string) error {
+	f.renamed = append(f.renamed, [2]string{oldName, newName})
+	delete(f.sessions, oldName)
+	f.sessions[newName] = true
+	return nil
+}
+
+func (f *fakeClient) SetSessionOption(session, key, value string) error {
+	if !f.sessions[session] {
+		return errors.New("no such session")
+	}
+	if f.options == nil {
+		f.options = make(map[string]string)
+	}
+	f.options[session+"/"+key] = value
+	return nil
+}
+
+func TestSession(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		sessions    []string
+		session     string
+		path        string
+		paneDir     string
+		wantManaged string
+		wantRename  bool
+		wantErr     string
+	}{
+		{name: "renames and pins pane dir", sessions: []string{"scratch"}, session: "scratch", paneDir: dir, wantManaged: "cb_scratch", wantRename: true},
+		{name: "already prefixed keeps name", sessions: []string{"cb_scratch"}, session: "cb_scratch", path: dir, wantManaged: "cb_scratch"},
+		{name: "missing session", session: "scratch", path: dir, wantErr: "not found"},
+		{name: "managed name taken", sessions: []string{"scratch", "cb_scratch"}, session: "scratch", path: dir, wantErr: "already exists"},
+		{name: "no pane dir", sessions: []string{"scratch"}, session: "scratch", wantErr: "cannot determine a home path"},
+		{name: "path is not a directory", sessions: []string{"scratch"}, session: "scratch", path: file, wantErr: "not a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{sessions: make(map[string]bool), paneDir: tt.paneDir}
+			for _, s := range tt.sessions {
+				client.sessions[s] = true
+			}
+
+			managed, home, err := Session(client, tt.session, tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Session() error = %v, want containing %q", err, tt.wantErr)
+				}
+				if len(client.renamed) != 0 {
+					t.Fatalf("renamed = %v, want no renames on error", client.renamed)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Session() error = %v", err)
+			}
+			if managed != tt.wantManaged {
+				t.Fatalf("managed = %q, want %q", managed, tt.wantManaged)
+			}
+			if got := len(client.renamed) == 1; got != tt.wantRename {
+				t.Fatalf("renamed = %v, want rename %v", client.renamed, tt.wantRename)
+			}
+			if got := client.options[managed+"/"+tmux.SessionOptionHomePath]; got != home || home == "" {
+				t.Fatalf("home option = %q, returned home = %q", got, home)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+// Package agentconfig installs the agent settings of the cb config file (the
+// [status] detection patterns and the [resume] commands) into the tmux
+// package, so every entry point detects and resumes agents the same way.
+package agentconfig
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// Apply installs the status patterns from the [status] config table and the
+// resume commands from [resume]. A config that fails to load leaves the
+// built-in ones; commands that need the config report the error themselves.
+func Apply() {
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		slog.Debug("status patterns: config not loaded", "err", err)
+		return
+	}
+	if len(cfg.Resume) > 0 {
+		commands := make(map[tmux.AgentType]string, len(cfg.Resume))
+		for name, command := range cfg.Resume {
+			if agent, err := ParseAgentName(name); err == nil {
+				commands[agent] = command
+			}
+		}
+		tmux.SetResumeCommands(commands)
+	}
+	if cfg.Status.IsZero() {
+		return
+	}
+	profile, err := StatusProfile(cfg.Status)
+	if err != nil {
+		slog.Debug("status patterns: invalid config", "err", err)
+		return
+	}
+	tmux.SetStatusProfile(profile)
+}
+
+// ParseAgentName maps an agent name (a launch command such as "opencode" or
+// an agent type such as "open_code") to its agent.
+func ParseAgentName(name string) (tmux.AgentType, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, agent := range tmux.LaunchableAgents {
+		if name == string(agent) || name == agent.LaunchCommand() {
+			return agent, nil
+		}
+	}
+	names := make([]string, 0, len(tmux.LaunchableAgents))
+	for _, agent := range tmux.LaunchableAgents {
+		names = append(names, agent.LaunchCommand())
+	}
+	return "", fmt.Errorf("unknown agent %q (want one of: %s)", name, strings.Join(names, ", "))
+}
+
+// StatusProfile builds the detection patterns for a [status] table: its
+// entries extend the built-in patterns, or replace them with replace_defaults.
+func StatusProfile(sc config.StatusConfig) (tmux.StatusProfile, error) {
+	var profile tmux.StatusProfile
+	if !sc.ReplaceDefaults {
+		profile = tmux.DefaultStatusProfile
+	}
+	profile.BusyStrings = slices.Concat(profile.BusyStrings, lowerAll(sc.Busy))
+	profile.SpinnerChars = slices.Concat(profile.SpinnerChars, []rune(sc.Spinners))
+	profile.PromptStrings = slices.Concat(profile.PromptStrings, lowerAll(sc.Prompts))
+	profile.ConfirmationPatterns = slices.Concat(profile.ConfirmationPatterns, lowerAll(sc.Confirmations))
+	profile.ErrorStrings = slices.Concat(profile.ErrorStrings, lowerAll(sc.Errors))
+	profile.LimitStrings = slices.Concat(profile.LimitStrings, lowerAll(sc.Limits))
+
+	var err error
+	if profile.BusyRegexps, err = compilePatterns("busy_regex", sc.BusyRegex); err != nil {
+		return tmux.StatusProfile{}, err
+	}
+	if profile.WaitingRegexps, err = compilePatterns("waiting_regex", sc.WaitingRegex); err != nil {
+		return tmux.StatusProfile{}, err
+	}
+	if profile.ErrorRegexps, err = compilePatterns("error_regex", sc.ErrorRegex); err != nil {
+		return tmux.StatusProfile{}, err
+	}
+	if profile.LimitRegexps, err = compilePatterns("limit_regex", sc.LimitRegex); err != nil {
+		return tmux.StatusProfile{}, err
+	}
+	return profile, nil
+}
+
+func compilePatterns(key string, patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status.%s pattern %q: %w", key, pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, 0, len(values))
+	for _, v := range values {
+		lowered = append(lowered, strings.ToLower(v))
+	}
+	return lowered
+}
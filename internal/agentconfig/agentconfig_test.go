@@ -0,0 +1,53 @@
+package agentconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestStatusProfile(t *testing.T) {
+	extended, err := StatusProfile(config.StatusConfig{Busy: []string{"Brewing"}, WaitingRegex: []string{`approve \d+`}})
+	if err != nil {
+		t.Fatalf("StatusProfile() error = %v", err)
+	}
+	wantBusy := len(tmux.DefaultStatusProfile.BusyStrings) + 1
+	if len(extended.BusyStrings) != wantBusy || extended.BusyStrings[wantBusy-1] != "brewing" {
+		t.Fatalf("BusyStrings = %q, want defaults plus lowercased brewing", extended.BusyStrings)
+	}
+	if len(extended.PromptStrings) != len(tmux.DefaultStatusProfile.PromptStrings) || len(extended.WaitingRegexps) != 1 {
+		t.Fatalf("profile = %+v, want default prompts and one waiting regexp", extended)
+	}
+
+	replaced, err := StatusProfile(config.StatusConfig{Spinners: "◴◷", ReplaceDefaults: true})
+	if err != nil {
+		t.Fatalf("StatusProfile() error = %v", err)
+	}
+	if len(replaced.BusyStrings) != 0 || string(replaced.SpinnerChars) != "◴◷" || replaced.BrailleSpinners {
+		t.Fatalf("profile = %+v, want only the configured spinners", replaced)
+	}
+
+	if _, err := StatusProfile(config.StatusConfig{BusyRegex: []string{"("}}); err == nil {
+		t.Fatal("StatusProfile() error = nil, want invalid pattern error")
+	}
+}
+
+func TestParseAgentName(t *testing.T) {
+	tests := map[string]tmux.AgentType{
+		"claude":    tmux.AgentClaude,
+		" Codex ":   tmux.AgentCodex,
+		"opencode":  tmux.AgentOpenCode,
+		"open_code": tmux.AgentOpenCode,
+	}
+	for name, want := range tests {
+		got, err := ParseAgentName(name)
+		if err != nil || got != want {
+			t.Errorf("ParseAgentName(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseAgentName("vim"); err == nil || !strings.Contains(err.Error(), "claude, codex, opencode") {
+		t.Fatalf("ParseAgentName(vim) error = %v, want list of agents", err)
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultAgentWindowName is the agent_window_name scheme used when none is
+// set: an agent started for a purpose runs in a window named
+// <agent>-<purpose>, e.g. "claude-review".
+const DefaultAgentWindowName = "{agent}-{purpose}"
+
+// RenderAgentWindowName names the window of agent (its launch command, such
+// as "claude") started for purpose, by the agent_window_name scheme. Without
+// a purpose the window is named after the agent alone. The scheme is
+// expected to have passed validateAgentWindowName.
+func RenderAgentWindowName(scheme, agent, purpose string) string {
+	if purpose == "" {
+		return agent
+	}
+	if scheme == "" {
+		scheme = DefaultAgentWindowName
+	}
+	return strings.NewReplacer("{agent}", agent, "{purpose}", purpose).Replace(scheme)
+}
+
+// ParseAgentWindowName splits a window name rendered by
+// RenderAgentWindowName with a purpose back into the agent and the purpose.
+// ok is false for names that do not follow the scheme, including windows
+// named after the agent alone. Callers parsing many names compile the scheme
+// once with CompileAgentWindowName.
+func ParseAgentWindowName(scheme, name string) (agent, purpose string, ok bool) {
+	return CompileAgentWindowName(scheme).Parse(name)
+}
+
+// AgentWindowNames parses the window names of one agent_window_name scheme.
+// The zero value matches no names.
+type AgentWindowNames struct {
+	pattern *regexp.Regexp
+}
+
+// CompileAgentWindowName compiles scheme (DefaultAgentWindowName when empty)
+// for parsing window names. A scheme that fails to compile matches no names.
+func CompileAgentWindowName(scheme string) AgentWindowNames {
+	if scheme == "" {
+		scheme = DefaultAgentWindowName
+	}
+	pattern, err := agentWindowNamePattern(scheme)
+	if err != nil {
+		return AgentWindowNames{}
+	}
+	return AgentWindowNames{pattern: pattern}
+}
+
+// Parse splits name into the agent and the purpose, as ParseAgentWindowName.
+func (n AgentWindowNames) Parse(name string) (agent, purpose string, ok bool) {
+	if n.pattern == nil {
+		return "", "", false
+	}
+	m := n.pattern.FindStringSubmatch(name)
+	if m == nil {
+		return "", "", false
+	}
+	return m[n.pattern.SubexpIndex("agent")], m[n.pattern.SubexpIndex("purpose")], true
+}
+
+// agentWindowNamePattern compiles scheme into a regexp matching the names it
+// renders, capturing the agent and the purpose.
+func agentWindowNamePattern(scheme string) (*regexp.Regexp, error) {
+	agents := make([]string, 0, len(AgentNames))
+	for _, agent := range AgentNames {
+		agents = append(agents, regexp.QuoteMeta(agent))
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	rest := scheme
+	for rest != "" {
+		agentAt, purposeAt := strings.Index(rest, "{agent}"), strings.Index(rest, "{purpose}")
+		switch {
+		case agentAt < 0 && purposeAt < 0:
+			b.WriteString(regexp.QuoteMeta(rest))
+			rest = ""
+		case purposeAt < 0 || (agentAt >= 0 && agentAt < purposeAt):
+			b.WriteString(regexp.QuoteMeta(rest[:agentAt]))
+			b.WriteString("(?P<agent>" + strings.Join(agents, "|") + ")")
+			rest = rest[agentAt+len("{agent}"):]
+		default:
+			b.WriteString(regexp.QuoteMeta(rest[:purposeAt]))
+			b.WriteString("(?P<purpose>.+)")
+			rest = rest[purposeAt+len("{purpose}"):]
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func validateAgentWindowName(scheme string) error {
+	if scheme == "" {
+		return nil
+	}
+	if strings.Count(scheme, "{agent}") != 1 || strings.Count(scheme, "{purpose}") != 1 {
+		return fmt.Errorf("agent_window_name %q must contain {agent} and {purpose} once each", scheme)
+	}
+	if strings.Contains(scheme, "{agent}{purpose}") || strings.Contains(scheme, "{purpose}{agent}") {
+		return fmt.Errorf("agent_window_name %q needs a separator between {agent} and {purpose}", scheme)
+	}
+	rest := strings.NewReplacer("{agent}", "", "{purpose}", "").Replace(scheme)
+	if strings.ContainsAny(rest, "{}") {
+		return fmt.Errorf("agent_window_name %q: only {agent} and {purpose} placeholders are supported", scheme)
+	}
+	if strings.Contains(rest, ".") {
+		return fmt.Errorf("agent_window_name %q must not contain '.', which tmux reads as a pane separator", scheme)
+	}
+	if strings.Contains(rest, ":") {
+		return fmt.Errorf("agent_window_name %q must not contain ':', which tmux reads as a session separator", scheme)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAgentWindowName_RenderAndParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		scheme      string
+		agent       string
+		purpose     string
+		want        string
+		wantPurpose string
+	}{
+		{name: "default", agent: "claude", purpose: "review", want: "claude-review", wantPurpose: "review"},
+		{name: "purpose with dashes", agent: "codex", purpose: "fix-login", want: "codex-fix-login", wantPurpose: "fix-login"},
+		{name: "slash scheme", scheme: "{agent}/{purpose}", agent: "opencode", purpose: "docs", want: "opencode/docs", wantPurpose: "docs"},
+		{name: "purpose first", scheme: "{purpose}@{agent}", agent: "claude", purpose: "tests", want: "tests@claude", wantPurpose: "tests"},
+		{name: "no purpose", agent: "claude", want: "claude"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderAgentWindowName(tt.scheme, tt.agent, tt.purpose)
+			if got != tt.want {
+				t.Fatalf("RenderAgentWindowName() = %q, want %q", got, tt.want)
+			}
+			agent, purpose, ok := ParseAgentWindowName(tt.scheme, got)
+			if tt.wantPurpose == "" {
+				if ok {
+					t.Fatalf("ParseAgentWindowName(%q) = %q, %q, want no match", got, agent, purpose)
+				}
+				return
+			}
+			if !ok || agent != tt.agent || purpose != tt.wantPurpose {
+				t.Fatalf("ParseAgentWindowName(%q) = %q, %q, %v; want %q, %q", got, agent, purpose, ok, tt.agent, tt.wantPurpose)
+			}
+		})
+	}
+
+	for _, name := range []string{"editor", "claudex-review", "review-claude", "aider-review"} {
+		if agent, purpose, ok := ParseAgentWindowName("", name); ok {
+			t.Fatalf("ParseAgentWindowName(%q) = %q, %q, want no match", name, agent, purpose)
+		}
+	}
+}
+
+func TestValidateAgentWindowName(t *testing.T) {
+	tests := []struct {
+		scheme  string
+		wantErr string
+	}{
+		{scheme: ""},
+		{scheme: "{agent}/{purpose}"},
+		{scheme: "{purpose}", wantErr: "once each"},
+		{scheme: "{agent}-{purpose}-{agent}", wantErr: "once each"},
+		{scheme: "{agent}{purpose}", wantErr: "needs a separator"},
+		{scheme: "{agent}-{purpose}-{branch}", wantErr: "only {agent} and {purpose}"},
+		{scheme: "{agent}.{purpose}", wantErr: "pane separator"},
+		{scheme: "{agent}:{purpose}", wantErr: "session separator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scheme, func(t *testing.T) {
+			err := validateAgentWindowName(tt.scheme)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateAgentWindowName() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateAgentWindowName() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserConfig_AgentWindowNameRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, AgentWindowName: "{agent}/{purpose}"}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if loaded.AgentWindowName != "{agent}/{purpose}" {
+		t.Fatalf("AgentWindowName = %q, want {agent}/{purpose}", loaded.AgentWindowName)
+	}
+}
@@ -5,7 +5,10 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -15,23 +18,227 @@ const (
 	// SupportedConfigVersion is the only config version supported by this binary.
 	SupportedConfigVersion = 1
 	configFileName         = "config.toml"
+	sessionRegistryName    = "sessions.json"
+	promptQueueName        = "queue.json"
+	daemonSocketName       = "daemon.sock"
+	activityFileName       = "activity.json"
+	zellijOptionsName      = "zellij-options.json"
+	debugLogName           = "cb-debug.log"
+)
+
+// StateDirEnv overrides the state directory.
+const StateDirEnv = "CB_STATE_DIR"
+
+// Multiplexer backends selectable with the multiplexer config key.
+const (
+	MultiplexerTmux   = "tmux"
+	MultiplexerZellij = "zellij"
 )
 
 // Config holds ClawdBay configuration paths.
 type Config struct {
 	ConfigDir string
+	// StateDir holds runtime files: the session registry, prompt queues,
+	// activity history, the daemon socket, and the debug log.
+	StateDir string
 }
 
 // UserConfig is the persisted configuration file schema.
 type UserConfig struct {
-	Version  int             `toml:"version"`
-	Projects []ProjectConfig `toml:"projects"`
+	Version   int               `toml:"version"`
+	Projects  []ProjectConfig   `toml:"projects"`
+	Templates []SessionTemplate `toml:"templates"`
+	// IgnoreSessions holds session-name glob patterns (path.Match syntax).
+	// Matching tmux sessions are skipped by discovery and agents mode.
+	IgnoreSessions []string `toml:"ignore_sessions,omitempty"`
+	// WorktreeName is the directory-name template for new worktrees under
+	// .worktrees (see RenderWorktreeName); empty means DefaultWorktreeName.
+	WorktreeName string `toml:"worktree_name,omitempty"`
+	// WorktreeNameMax caps rendered worktree names in bytes; 0 means no cap.
+	WorktreeNameMax int `toml:"worktree_name_max,omitempty"`
+	// SessionName is the style of new workflow session names (see
+	// SessionNameForBranch); empty means SessionNameBranch.
+	SessionName string `toml:"session_name,omitempty"`
+	// AgentWindowName is the naming scheme of agent windows started for a
+	// purpose (see RenderAgentWindowName); empty means
+	// DefaultAgentWindowName.
+	AgentWindowName string `toml:"agent_window_name,omitempty"`
+	// PinnedSessions lists tmux sessions kept at the top of the dashboard.
+	PinnedSessions []string `toml:"pinned_sessions,omitempty"`
+	// Theme selects the dashboard colors: "dark", "light", or "auto" to
+	// follow the terminal background. Empty means dark.
+	Theme string `toml:"theme,omitempty"`
+	// ASCII draws the dashboard with ASCII characters only.
+	ASCII bool `toml:"ascii,omitempty"`
+	// Multiplexer selects the dashboard backend: "tmux" (the default when
+	// empty) or "zellij".
+	Multiplexer string `toml:"multiplexer,omitempty"`
+	// Status adjusts the pane patterns behind agent status detection.
+	Status StatusConfig `toml:"status,omitempty"`
+	// Resume is the [resume] table: per agent launch command (see
+	// AgentNames), the command that resumes its most recent conversation.
+	Resume map[string]string `toml:"resume,omitempty"`
+	// Start holds the cb start defaults.
+	Start StartConfig `toml:"start,omitempty"`
+	// GC holds the policy cb gc archives abandoned workflows by.
+	GC GCConfig `toml:"gc,omitempty"`
+}
+
+// AgentNames are the launch commands of the agents cb knows: the keys of the
+// [resume] table, the agents [start] may launch, and the names
+// agent_window_name matches.
+var AgentNames = []string{"claude", "codex", "opencode"}
+
+// StatusConfig is the [status] table. Its patterns are added to the built-in
+// ones, or replace them when ReplaceDefaults is set, so detection can follow
+// agent UI changes without a new release. Strings match case-insensitively;
+// regexps use Go syntax and match the captured pane text as is.
+type StatusConfig struct {
+	// Busy strings mean the agent is WORKING (e.g. "esc to interrupt").
+	Busy []string `toml:"busy,omitempty"`
+	// Spinners holds spinner characters that mean the agent is WORKING.
+	Spinners string `toml:"spinners,omitempty"`
+	// Prompts are permission dialog strings that mean WAITING.
+	Prompts []string `toml:"prompts,omitempty"`
+	// Confirmations are confirmation prompt strings that mean WAITING.
+	Confirmations []string `toml:"confirmations,omitempty"`
+	// Errors are crash and failure strings that mean ERROR.
+	Errors []string `toml:"errors,omitempty"`
+	// Limits are usage and rate limit strings that mean LIMITED.
+	Limits []string `toml:"limits,omitempty"`
+	// BusyRegex, WaitingRegex, ErrorRegex, and LimitRegex are regexps for
+	// WORKING, WAITING, ERROR, and LIMITED.
+	BusyRegex    []string `toml:"busy_regex,omitempty"`
+	WaitingRegex []string `toml:"waiting_regex,omitempty"`
+	ErrorRegex   []string `toml:"error_regex,omitempty"`
+	LimitRegex   []string `toml:"limit_regex,omitempty"`
+	// ReplaceDefaults drops the built-in patterns instead of extending them.
+	ReplaceDefaults bool `toml:"replace_defaults,omitempty"`
+}
+
+// IsZero reports whether the table sets nothing.
+func (s StatusConfig) IsZero() bool {
+	return len(s.Busy) == 0 && s.Spinners == "" && len(s.Prompts) == 0 && len(s.Confirmations) == 0 &&
+		len(s.Errors) == 0 && len(s.Limits) == 0 && len(s.BusyRegex) == 0 && len(s.WaitingRegex) == 0 &&
+		len(s.ErrorRegex) == 0 && len(s.LimitRegex) == 0 && !s.ReplaceDefaults
+}
+
+// PinsSession reports whether the named session is pinned.
+func (c UserConfig) PinsSession(name string) bool {
+	return slices.Contains(c.PinnedSessions, name)
+}
+
+// TogglePinnedSession pins or unpins the named session and reports whether
+// it is now pinned.
+func (c *UserConfig) TogglePinnedSession(name string) bool {
+	if i := slices.Index(c.PinnedSessions, name); i >= 0 {
+		c.PinnedSessions = slices.Delete(c.PinnedSessions, i, i+1)
+		return false
+	}
+	c.PinnedSessions = append(c.PinnedSessions, name)
+	return true
+}
+
+// RenamePinnedSession carries a pin from oldName to newName and reports
+// whether oldName was pinned.
+func (c *UserConfig) RenamePinnedSession(oldName, newName string) bool {
+	i := slices.Index(c.PinnedSessions, oldName)
+	if i < 0 {
+		return false
+	}
+	c.PinnedSessions[i] = newName
+	return true
+}
+
+// TogglePinnedProject pins or unpins the project at path and reports whether
+// it is now pinned. It returns an error if no project is configured at path.
+func (c *UserConfig) TogglePinnedProject(path string) (bool, error) {
+	for i := range c.Projects {
+		if c.Projects[i].Path == path {
+			c.Projects[i].Pinned = !c.Projects[i].Pinned
+			return c.Projects[i].Pinned, nil
+		}
+	}
+	return false, fmt.Errorf("no configured project at %s", path)
+}
+
+// Workspaces returns the distinct workspace names of the configured
+// projects, sorted.
+func (c UserConfig) Workspaces() []string {
+	var names []string
+	for _, p := range c.Projects {
+		if p.Workspace != "" && !slices.Contains(names, p.Workspace) {
+			names = append(names, p.Workspace)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IgnoresSession reports whether the named tmux session matches one of the
+// ignore_sessions patterns.
+func (c UserConfig) IgnoresSession(name string) bool {
+	for _, pattern := range c.IgnoreSessions {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // ProjectConfig defines one configured project root.
 type ProjectConfig struct {
 	Path string `toml:"path"`
 	Name string `toml:"name,omitempty"`
+	// ExcludeWorktrees holds glob patterns (path.Match syntax) matched against
+	// each worktree's directory name and its path relative to the project.
+	// Matching worktrees and their sessions are hidden from discovery.
+	ExcludeWorktrees []string `toml:"exclude_worktrees,omitempty"`
+	// Pinned keeps the project at the top of the dashboard.
+	Pinned bool `toml:"pinned,omitempty"`
+	// Workspace names the group the project belongs to, such as "work" or
+	// "oss"; cb dash --workspace shows only that group's projects.
+	Workspace string `toml:"workspace,omitempty"`
+}
+
+// ExcludesWorktree reports whether relPath (a worktree path relative to the
+// project root, slash-separated) matches one of the exclusion patterns.
+func (p ProjectConfig) ExcludesWorktree(relPath string) bool {
+	base := path.Base(relPath)
+	for _, pattern := range p.ExcludeWorktrees {
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, relPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionTemplate is a named set of windows applied when starting a session.
+type SessionTemplate struct {
+	Name    string           `toml:"name"`
+	Windows []TemplateWindow `toml:"windows"`
+}
+
+// TemplateWindow is one window of a session template. Panes holds the command
+// typed into each pane in order; an empty command leaves a plain shell. A
+// window with no panes gets a single shell pane.
+type TemplateWindow struct {
+	Name   string   `toml:"name"`
+	Layout string   `toml:"layout,omitempty"`
+	Panes  []string `toml:"panes,omitempty"`
+}
+
+// FindTemplate returns the template with the given name.
+func (c UserConfig) FindTemplate(name string) (SessionTemplate, bool) {
+	for _, t := range c.Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return SessionTemplate{}, false
 }
 
 // New creates a Config with default paths.
@@ -44,24 +251,99 @@ func New() (*Config, error) {
 
 	return &Config{
 		ConfigDir: configDir,
+		StateDir:  stateDir(home),
 	}, nil
 }
 
-// EnsureDirs creates the config directory if it doesn't exist.
+// stateDir returns $CB_STATE_DIR, else $XDG_STATE_HOME/cb, else
+// ~/.local/state/cb. Relative XDG_STATE_HOME values are ignored, as the XDG
+// spec requires.
+func stateDir(home string) string {
+	if dir := os.Getenv(StateDirEnv); dir != "" {
+		return filepath.Clean(dir)
+	}
+	if xdg := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "cb")
+	}
+	return filepath.Join(home, ".local", "state", "cb")
+}
+
+// EnsureDirs creates the config and state directories if they don't exist.
 func (c *Config) EnsureDirs() error {
 	if err := os.MkdirAll(c.ConfigDir, 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
+	if err := os.MkdirAll(c.StateDir, 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
 	return nil
 }
 
+// statePath returns the path of a state file. A file that older versions
+// kept in the config directory is moved over on first use; if that fails,
+// the old path keeps being used so no data is lost.
+func (c *Config) statePath(name string) string {
+	path := filepath.Join(c.StateDir, name)
+	legacy := filepath.Join(c.ConfigDir, name)
+	if _, err := os.Stat(path); err == nil || c.ConfigDir == "" {
+		return path
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return path
+	}
+	if err := os.MkdirAll(c.StateDir, 0700); err != nil {
+		return legacy
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		return legacy
+	}
+	return path
+}
+
 // ConfigFilePath returns ~/.config/cb/config.toml.
 func (c *Config) ConfigFilePath() string {
 	return filepath.Join(c.ConfigDir, configFileName)
 }
 
+// SessionRegistryPath returns ~/.local/state/cb/sessions.json.
+func (c *Config) SessionRegistryPath() string {
+	return c.statePath(sessionRegistryName)
+}
+
+// PromptQueuePath returns ~/.local/state/cb/queue.json.
+func (c *Config) PromptQueuePath() string {
+	return c.statePath(promptQueueName)
+}
+
+// DaemonSocketPath returns ~/.local/state/cb/daemon.sock. Sockets are
+// recreated by each daemon, so none is moved from the config directory.
+func (c *Config) DaemonSocketPath() string {
+	return filepath.Join(c.StateDir, daemonSocketName)
+}
+
+// ActivityPath returns ~/.local/state/cb/activity.json.
+func (c *Config) ActivityPath() string {
+	return c.statePath(activityFileName)
+}
+
+// ZellijOptionsPath returns ~/.local/state/cb/zellij-options.json, where the
+// zellij backend keeps the session options tmux would store itself.
+func (c *Config) ZellijOptionsPath() string {
+	return c.statePath(zellijOptionsName)
+}
+
+// DebugLogPath returns ~/.local/state/cb/cb-debug.log, written by --debug and
+// --log-commands.
+func (c *Config) DebugLogPath() string {
+	return filepath.Join(c.StateDir, debugLogName)
+}
+
 // CanonicalPath resolves a path for all matching/comparison operations.
+// Inside WSL, Windows paths (`C:\src\repo`) resolve to their Linux mounts.
 func CanonicalPath(path string) (string, error) {
+	if isWSL() {
+		path = wslPath(path)
+	}
 	abs, err := filepath.Abs(path)
 	if err != nil {
 		return "", fmt.Errorf("failed to make absolute path %q: %w", path, err)
@@ -87,50 +369,67 @@ func LoadUserConfigWithMeta() (cfg UserConfig, exists bool, err error) {
 	if err != nil {
 		return UserConfig{}, false, err
 	}
+	cfg, exists, _, err = loadUserConfigFile(c.ConfigFilePath())
+	return cfg, exists, err
+}
 
-	path := c.ConfigFilePath()
+// loadUserConfigFile loads the config file at path, also returning its raw
+// content (nil when missing).
+func loadUserConfigFile(path string) (cfg UserConfig, exists bool, content []byte, err error) {
 	content, readErr := os.ReadFile(path)
 	if readErr != nil {
 		if os.IsNotExist(readErr) {
-			return UserConfig{Version: SupportedConfigVersion, Projects: []ProjectConfig{}}, false, nil
+			return UserConfig{Version: SupportedConfigVersion, Projects: []ProjectConfig{}}, false, nil, nil
 		}
-		return UserConfig{}, false, fmt.Errorf("failed to read config file %s: %w", path, readErr)
+		return UserConfig{}, false, nil, fmt.Errorf("failed to read config file %s: %w", path, readErr)
 	}
 
 	if len(bytes.TrimSpace(content)) == 0 {
-		return UserConfig{Version: SupportedConfigVersion, Projects: []ProjectConfig{}}, true, nil
+		return UserConfig{Version: SupportedConfigVersion, Projects: []ProjectConfig{}}, true, content, nil
 	}
 
 	parsed, parseErr := parseUserConfigTOML(content)
 	if parseErr != nil {
-		return UserConfig{}, true, fmt.Errorf("failed to parse config file %s: %w", path, parseErr)
+		return UserConfig{}, true, nil, fmt.Errorf("failed to parse config file %s: %w", path, parseErr)
 	}
 
 	if validateErr := validateLoadedConfig(parsed); validateErr != nil {
-		return UserConfig{}, true, fmt.Errorf("invalid config file %s: %w", path, validateErr)
+		return UserConfig{}, true, nil, fmt.Errorf("invalid config file %s: %w", path, validateErr)
 	}
 
-	return parsed, true, nil
+	return parsed, true, content, nil
 }
 
-// SaveUserConfig validates, canonicalizes, and atomically persists config.toml.
+// SaveUserConfig validates, canonicalizes, and atomically persists config.toml,
+// replacing whatever it holds. To change part of the config, use
+// UpdateUserConfig, which does not lose concurrent edits.
 func SaveUserConfig(cfg UserConfig) error {
-	normalized, err := normalizeForSave(cfg)
+	c, err := New()
 	if err != nil {
 		return err
 	}
+	if err := c.EnsureDirs(); err != nil {
+		return err
+	}
 
-	c, err := New()
+	unlock, err := lockConfigFile(c.ConfigFilePath())
 	if err != nil {
 		return err
 	}
+	defer unlock()
+	return writeUserConfig(c.ConfigFilePath(), cfg, nil)
+}
 
-	if err := c.EnsureDirs(); err != nil {
+// writeUserConfig validates, canonicalizes, and atomically writes cfg to
+// path. precondition, when set, runs just before the file is replaced and
+// aborts the write if it fails.
+func writeUserConfig(path string, cfg UserConfig, precondition func() error) error {
+	normalized, err := normalizeForSave(cfg)
+	if err != nil {
 		return err
 	}
 
 	content := renderUserConfigTOML(normalized)
-	path := c.ConfigFilePath()
 	dir := filepath.Dir(path)
 
 	tmp, err := os.CreateTemp(dir, "config-*.toml")
@@ -154,6 +453,12 @@ func SaveUserConfig(cfg UserConfig) error {
 		return fmt.Errorf("failed to close temp config file: %w", err)
 	}
 
+	if precondition != nil {
+		if err := precondition(); err != nil {
+			return err
+		}
+	}
+
 	if err := os.Rename(tmpPath, path); err != nil {
 		return fmt.Errorf("failed to atomically replace config file %s: %w", path, err)
 	}
@@ -177,8 +482,123 @@ func validateLoadedConfig(cfg UserConfig) error {
 		if p.Name != "" && strings.TrimSpace(p.Name) == "" {
 			return fmt.Errorf("projects[%d].name must be non-empty when provided", i)
 		}
+		if p.Workspace != "" && strings.TrimSpace(p.Workspace) == "" {
+			return fmt.Errorf("projects[%d].workspace must be non-empty when provided", i)
+		}
+		if err := validateExcludePatterns(i, p.ExcludeWorktrees); err != nil {
+			return err
+		}
+	}
+
+	if err := validateIgnorePatterns(cfg.IgnoreSessions); err != nil {
+		return err
+	}
+	if err := validateWorktreeName(cfg.WorktreeName, cfg.WorktreeNameMax); err != nil {
+		return err
+	}
+	if err := validateSessionName(cfg.SessionName); err != nil {
+		return err
+	}
+	if err := validateAgentWindowName(cfg.AgentWindowName); err != nil {
+		return err
+	}
+	if err := validateStatusPatterns(cfg.Status); err != nil {
+		return err
+	}
+	if err := validateMultiplexer(cfg.Multiplexer); err != nil {
+		return err
+	}
+	if err := validateStart(cfg.Start); err != nil {
+		return err
+	}
+	if err := validateGC(cfg.GC); err != nil {
+		return err
+	}
+	return validateTemplates(cfg.Templates)
+}
+
+func validateMultiplexer(name string) error {
+	switch name {
+	case "", MultiplexerTmux, MultiplexerZellij:
+		return nil
+	default:
+		return fmt.Errorf("unknown multiplexer %q (want %q or %q)", name, MultiplexerTmux, MultiplexerZellij)
+	}
+}
+
+func validateResume(commands map[string]string) error {
+	for agent, command := range commands {
+		if !slices.Contains(AgentNames, agent) {
+			return fmt.Errorf("unknown resume agent %q (want one of: %s)", agent, strings.Join(AgentNames, ", "))
+		}
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("resume.%s must be non-empty", agent)
+		}
+	}
+	return nil
+}
+
+func validateStatusPatterns(s StatusConfig) error {
+	for _, pattern := range s.BusyRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("status.busy_regex: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range s.WaitingRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("status.waiting_regex: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range s.ErrorRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("status.error_regex: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	for _, pattern := range s.LimitRegex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("status.limit_regex: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func validateExcludePatterns(projectIndex int, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("projects[%d].exclude_worktrees: invalid pattern %q: %w", projectIndex, pattern, err)
+		}
+	}
+	return nil
+}
+
+func validateIgnorePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("ignore_sessions: invalid pattern %q: %w", pattern, err)
+		}
 	}
+	return nil
+}
 
+func validateTemplates(templates []SessionTemplate) error {
+	seen := map[string]struct{}{}
+	for i, t := range templates {
+		if strings.TrimSpace(t.Name) == "" {
+			return fmt.Errorf("templates[%d].name is required", i)
+		}
+		if _, ok := seen[t.Name]; ok {
+			return fmt.Errorf("duplicate template name: %s", t.Name)
+		}
+		seen[t.Name] = struct{}{}
+		if len(t.Windows) == 0 {
+			return fmt.Errorf("templates[%d] (%s) must define at least one window", i, t.Name)
+		}
+		for j, w := range t.Windows {
+			if strings.TrimSpace(w.Name) == "" {
+				return fmt.Errorf("templates[%d].windows[%d].name is required", i, j)
+			}
+		}
+	}
 	return nil
 }
 
@@ -190,9 +610,54 @@ func normalizeForSave(cfg UserConfig) (UserConfig, error) {
 		return UserConfig{}, fmt.Errorf("unsupported version %d (supported: %d)", cfg.Version, SupportedConfigVersion)
 	}
 
+	if err := validateTemplates(cfg.Templates); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateIgnorePatterns(cfg.IgnoreSessions); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateWorktreeName(cfg.WorktreeName, cfg.WorktreeNameMax); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateSessionName(cfg.SessionName); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateAgentWindowName(cfg.AgentWindowName); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateStatusPatterns(cfg.Status); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateMultiplexer(cfg.Multiplexer); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateResume(cfg.Resume); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateStart(cfg.Start); err != nil {
+		return UserConfig{}, err
+	}
+	if err := validateGC(cfg.GC); err != nil {
+		return UserConfig{}, err
+	}
+
 	normalized := UserConfig{
-		Version:  SupportedConfigVersion,
-		Projects: make([]ProjectConfig, 0, len(cfg.Projects)),
+		Version:         SupportedConfigVersion,
+		Projects:        make([]ProjectConfig, 0, len(cfg.Projects)),
+		Templates:       cfg.Templates,
+		IgnoreSessions:  cfg.IgnoreSessions,
+		WorktreeName:    cfg.WorktreeName,
+		WorktreeNameMax: cfg.WorktreeNameMax,
+		SessionName:     cfg.SessionName,
+		AgentWindowName: cfg.AgentWindowName,
+		PinnedSessions:  cfg.PinnedSessions,
+		Theme:           cfg.Theme,
+		ASCII:           cfg.ASCII,
+		Multiplexer:     cfg.Multiplexer,
+		Status:          cfg.Status,
+		Resume:          cfg.Resume,
+		Start:           cfg.Start,
+		GC:              cfg.GC,
 	}
 
 	seen := map[string]struct{}{}
@@ -203,6 +668,12 @@ func normalizeForSave(cfg UserConfig) (UserConfig, error) {
 		if p.Name != "" && strings.TrimSpace(p.Name) == "" {
 			return UserConfig{}, fmt.Errorf("projects[%d].name must be non-empty when provided", i)
 		}
+		if p.Workspace != "" && strings.TrimSpace(p.Workspace) == "" {
+			return UserConfig{}, fmt.Errorf("projects[%d].workspace must be non-empty when provided", i)
+		}
+		if err := validateExcludePatterns(i, p.ExcludeWorktrees); err != nil {
+			return UserConfig{}, err
+		}
 
 		canonicalPath, err := CanonicalPath(p.Path)
 		if err != nil {
@@ -217,8 +688,11 @@ func normalizeForSave(cfg UserConfig) (UserConfig, error) {
 		seen[canonicalPath] = struct{}{}
 
 		normalized.Projects = append(normalized.Projects, ProjectConfig{
-			Path: canonicalPath,
-			Name: strings.TrimSpace(p.Name),
+			Path:             canonicalPath,
+			Name:             strings.TrimSpace(p.Name),
+			ExcludeWorktrees: p.ExcludeWorktrees,
+			Pinned:           p.Pinned,
+			Workspace:        strings.TrimSpace(p.Workspace),
 		})
 	}
 
@@ -242,7 +716,7 @@ func normalizeForSave(cfg UserConfig) (UserConfig, error) {
 
 func parseUserConfigTOML(content []byte) (UserConfig, error) {
 	cfg := UserConfig{Projects: []ProjectConfig{}}
-	var inProject bool
+	section := ""
 
 	scanner := bufio.NewScanner(bytes.NewReader(content))
 	lineNo := 0
@@ -253,9 +727,34 @@ func parseUserConfigTOML(content []byte) (UserConfig, error) {
 			continue
 		}
 
-		if line == "[[projects]]" {
+		switch line {
+		case "[[projects]]":
 			cfg.Projects = append(cfg.Projects, ProjectConfig{})
-			inProject = true
+			section = "projects"
+			continue
+		case "[[templates]]":
+			cfg.Templates = append(cfg.Templates, SessionTemplate{})
+			section = "templates"
+			continue
+		case "[status]":
+			section = "status"
+			continue
+		case "[resume]":
+			section = "resume"
+			continue
+		case "[start]":
+			section = "start"
+			continue
+		case "[gc]":
+			section = "gc"
+			continue
+		case "[[templates.windows]]":
+			if len(cfg.Templates) == 0 {
+				return UserConfig{}, fmt.Errorf("line %d: [[templates.windows]] must follow [[templates]]", lineNo)
+			}
+			t := &cfg.Templates[len(cfg.Templates)-1]
+			t.Windows = append(t.Windows, TemplateWindow{})
+			section = "templates.windows"
 			continue
 		}
 
@@ -266,9 +765,40 @@ func parseUserConfigTOML(content []byte) (UserConfig, error) {
 		key = strings.TrimSpace(key)
 		value = strings.TrimSpace(value)
 
+		if strings.HasPrefix(section, "templates") {
+			if err := parseTemplateKey(&cfg, section, key, value); err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		if section == "status" {
+			if err := parseStatusKey(&cfg.Status, key, value); err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		if section == "resume" {
+			if err := parseResumeKey(&cfg, key, value); err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		if section == "start" {
+			if err := parseStartKey(&cfg.Start, key, value); err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+		if section == "gc" {
+			if err := parseGCKey(&cfg.GC, key, value); err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			continue
+		}
+
 		switch key {
 		case "version":
-			if inProject {
+			if section != "" {
 				return UserConfig{}, fmt.Errorf("line %d: version must be top-level", lineNo)
 			}
 			v, err := strconv.Atoi(value)
@@ -276,8 +806,89 @@ func parseUserConfigTOML(content []byte) (UserConfig, error) {
 				return UserConfig{}, fmt.Errorf("line %d: invalid version value %q", lineNo, value)
 			}
 			cfg.Version = v
+		case "ignore_sessions":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: ignore_sessions must be top-level", lineNo)
+			}
+			patterns, err := parseTOMLStringArray(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.IgnoreSessions = patterns
+		case "worktree_name":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: worktree_name must be top-level", lineNo)
+			}
+			s, err := parseTOMLString(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.WorktreeName = s
+		case "session_name":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: session_name must be top-level", lineNo)
+			}
+			s, err := parseTOMLString(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.SessionName = s
+		case "agent_window_name":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: agent_window_name must be top-level", lineNo)
+			}
+			s, err := parseTOMLString(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.AgentWindowName = s
+		case "pinned_sessions":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: pinned_sessions must be top-level", lineNo)
+			}
+			names, err := parseTOMLStringArray(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.PinnedSessions = names
+		case "theme":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: theme must be top-level", lineNo)
+			}
+			s, err := parseTOMLString(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.Theme = s
+		case "ascii":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: ascii must be top-level", lineNo)
+			}
+			v, err := strconv.ParseBool(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: invalid ascii value %q", lineNo, value)
+			}
+			cfg.ASCII = v
+		case "multiplexer":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: multiplexer must be top-level", lineNo)
+			}
+			s, err := parseTOMLString(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.Multiplexer = s
+		case "worktree_name_max":
+			if section != "" {
+				return UserConfig{}, fmt.Errorf("line %d: worktree_name_max must be top-level", lineNo)
+			}
+			v, err := strconv.Atoi(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: invalid worktree_name_max value %q", lineNo, value)
+			}
+			cfg.WorktreeNameMax = v
 		case "path":
-			if !inProject || len(cfg.Projects) == 0 {
+			if section != "projects" {
 				return UserConfig{}, fmt.Errorf("line %d: path must be inside [[projects]]", lineNo)
 			}
 			s, err := parseTOMLString(value)
@@ -286,7 +897,7 @@ func parseUserConfigTOML(content []byte) (UserConfig, error) {
 			}
 			cfg.Projects[len(cfg.Projects)-1].Path = s
 		case "name":
-			if !inProject || len(cfg.Projects) == 0 {
+			if section != "projects" {
 				return UserConfig{}, fmt.Errorf("line %d: name must be inside [[projects]]", lineNo)
 			}
 			s, err := parseTOMLString(value)
@@ -294,6 +905,33 @@ func parseUserConfigTOML(content []byte) (UserConfig, error) {
 				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
 			}
 			cfg.Projects[len(cfg.Projects)-1].Name = s
+		case "pinned":
+			if section != "projects" {
+				return UserConfig{}, fmt.Errorf("line %d: pinned must be inside [[projects]]", lineNo)
+			}
+			v, err := strconv.ParseBool(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: invalid pinned value %q", lineNo, value)
+			}
+			cfg.Projects[len(cfg.Projects)-1].Pinned = v
+		case "workspace":
+			if section != "projects" {
+				return UserConfig{}, fmt.Errorf("line %d: workspace must be inside [[projects]]", lineNo)
+			}
+			s, err := parseTOMLString(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.Projects[len(cfg.Projects)-1].Workspace = s
+		case "exclude_worktrees":
+			if section != "projects" {
+				return UserConfig{}, fmt.Errorf("line %d: exclude_worktrees must be inside [[projects]]", lineNo)
+			}
+			patterns, err := parseTOMLStringArray(value)
+			if err != nil {
+				return UserConfig{}, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			cfg.Projects[len(cfg.Projects)-1].ExcludeWorktrees = patterns
 		default:
 			return UserConfig{}, fmt.Errorf("line %d: unknown key %q", lineNo, key)
 		}
@@ -310,6 +948,149 @@ func parseUserConfigTOML(content []byte) (UserConfig, error) {
 	return cfg, nil
 }
 
+// parseTemplateKey assigns one key inside [[templates]] or [[templates.windows]].
+func parseTemplateKey(cfg *UserConfig, section, key, value string) error {
+	t := &cfg.Templates[len(cfg.Templates)-1]
+	if section == "templates" {
+		if key != "name" {
+			return fmt.Errorf("unknown template key %q", key)
+		}
+		s, err := parseTOMLString(value)
+		if err != nil {
+			return err
+		}
+		t.Name = s
+		return nil
+	}
+
+	w := &t.Windows[len(t.Windows)-1]
+	switch key {
+	case "name", "layout":
+		s, err := parseTOMLString(value)
+		if err != nil {
+			return err
+		}
+		if key == "name" {
+			w.Name = s
+		} else {
+			w.Layout = s
+		}
+	case "panes":
+		panes, err := parseTOMLStringArray(value)
+		if err != nil {
+			return err
+		}
+		w.Panes = panes
+	default:
+		return fmt.Errorf("unknown template window key %q", key)
+	}
+	return nil
+}
+
+// parseStatusKey assigns one key inside [status].
+func parseStatusKey(s *StatusConfig, key, value string) error {
+	switch key {
+	case "spinners":
+		v, err := parseTOMLString(value)
+		if err != nil {
+			return err
+		}
+		s.Spinners = v
+	case "replace_defaults":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid replace_defaults value %q", value)
+		}
+		s.ReplaceDefaults = v
+	case "busy", "prompts", "confirmations", "errors", "limits", "busy_regex", "waiting_regex", "error_regex", "limit_regex":
+		patterns, err := parseTOMLStringArray(value)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "busy":
+			s.Busy = patterns
+		case "prompts":
+			s.Prompts = patterns
+		case "confirmations":
+			s.Confirmations = patterns
+		case "errors":
+			s.Errors = patterns
+		case "limits":
+			s.Limits = patterns
+		case "busy_regex":
+			s.BusyRegex = patterns
+		case "waiting_regex":
+			s.WaitingRegex = patterns
+		case "error_regex":
+			s.ErrorRegex = patterns
+		case "limit_regex":
+			s.LimitRegex = patterns
+		}
+	default:
+		return fmt.Errorf("unknown status key %q", key)
+	}
+	return nil
+}
+
+// parseTOMLStringArray parses a single-line array of quoted strings.
+func parseTOMLStringArray(v string) ([]string, error) {
+	if len(v) < 2 || v[0] != '[' || v[len(v)-1] != ']' {
+		return nil, fmt.Errorf("expected array of strings, got %q", v)
+	}
+	inner := strings.TrimSpace(v[1 : len(v)-1])
+	values := []string{}
+	for inner != "" {
+		if inner[0] != '"' {
+			return nil, fmt.Errorf("expected quoted string in array %q", v)
+		}
+		end := closingQuote(inner)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string in array %q", v)
+		}
+		s, err := parseTOMLString(inner[:end+1])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, s)
+
+		inner = strings.TrimSpace(inner[end+1:])
+		if inner == "" {
+			break
+		}
+		if inner[0] != ',' {
+			return nil, fmt.Errorf("expected ',' between array values in %q", v)
+		}
+		inner = strings.TrimSpace(inner[1:])
+	}
+	return values, nil
+}
+
+// closingQuote returns the index of the quote ending the string that starts
+// at s[0], honoring backslash escapes, or -1.
+func closingQuote(s string) int {
+	escaped := false
+	for i := 1; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func renderTOMLStringArray(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
 func parseTOMLString(v string) (string, error) {
 	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
 		return "", fmt.Errorf("expected quoted string, got %q", v)
@@ -344,9 +1125,94 @@ func stripInlineComment(line string) string {
 	return line
 }
 
+// parseResumeKey assigns one key inside [resume].
+func parseResumeKey(cfg *UserConfig, key, value string) error {
+	command, err := parseTOMLString(value)
+	if err != nil {
+		return err
+	}
+	if cfg.Resume == nil {
+		cfg.Resume = make(map[string]string)
+	}
+	cfg.Resume[key] = command
+	return validateResume(map[string]string{key: command})
+}
+
+func renderStatusTable(b *strings.Builder, s StatusConfig) {
+	b.WriteString("\n[status]\n")
+	for _, kv := range []struct {
+		key    string
+		values []string
+	}{
+		{"busy", s.Busy},
+		{"prompts", s.Prompts},
+		{"confirmations", s.Confirmations},
+		{"errors", s.Errors},
+		{"limits", s.Limits},
+		{"busy_regex", s.BusyRegex},
+		{"waiting_regex", s.WaitingRegex},
+		{"error_regex", s.ErrorRegex},
+		{"limit_regex", s.LimitRegex},
+	} {
+		if len(kv.values) > 0 {
+			b.WriteString(fmt.Sprintf("%s = %s\n", kv.key, renderTOMLStringArray(kv.values)))
+		}
+	}
+	if s.Spinners != "" {
+		b.WriteString(fmt.Sprintf("spinners = %s\n", strconv.Quote(s.Spinners)))
+	}
+	if s.ReplaceDefaults {
+		b.WriteString("replace_defaults = true\n")
+	}
+}
+
 func renderUserConfigTOML(cfg UserConfig) []byte {
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("version = %d\n", cfg.Version))
+	if len(cfg.IgnoreSessions) > 0 {
+		b.WriteString(fmt.Sprintf("ignore_sessions = %s\n", renderTOMLStringArray(cfg.IgnoreSessions)))
+	}
+	if cfg.WorktreeName != "" {
+		b.WriteString(fmt.Sprintf("worktree_name = %s\n", strconv.Quote(cfg.WorktreeName)))
+	}
+	if cfg.WorktreeNameMax > 0 {
+		b.WriteString(fmt.Sprintf("worktree_name_max = %d\n", cfg.WorktreeNameMax))
+	}
+	if cfg.SessionName != "" {
+		b.WriteString(fmt.Sprintf("session_name = %s\n", strconv.Quote(cfg.SessionName)))
+	}
+	if cfg.AgentWindowName != "" {
+		b.WriteString(fmt.Sprintf("agent_window_name = %s\n", strconv.Quote(cfg.AgentWindowName)))
+	}
+	if len(cfg.PinnedSessions) > 0 {
+		b.WriteString(fmt.Sprintf("pinned_sessions = %s\n", renderTOMLStringArray(cfg.PinnedSessions)))
+	}
+	if cfg.Theme != "" {
+		b.WriteString(fmt.Sprintf("theme = %s\n", strconv.Quote(cfg.Theme)))
+	}
+	if cfg.ASCII {
+		b.WriteString("ascii = true\n")
+	}
+	if cfg.Multiplexer != "" {
+		b.WriteString(fmt.Sprintf("multiplexer = %s\n", strconv.Quote(cfg.Multiplexer)))
+	}
+	if !cfg.Status.IsZero() {
+		renderStatusTable(&b, cfg.Status)
+	}
+	if len(cfg.Resume) > 0 {
+		b.WriteString("\n[resume]\n")
+		for _, agent := range AgentNames {
+			if command, ok := cfg.Resume[agent]; ok {
+				b.WriteString(fmt.Sprintf("%s = %s\n", agent, strconv.Quote(command)))
+			}
+		}
+	}
+	if !cfg.Start.IsZero() {
+		renderStartTable(&b, cfg.Start)
+	}
+	if !cfg.GC.IsZero() {
+		renderGCTable(&b, cfg.GC)
+	}
 	if len(cfg.Projects) > 0 {
 		b.WriteString("\n")
 	}
@@ -359,6 +1225,29 @@ func renderUserConfigTOML(cfg UserConfig) []byte {
 		if p.Name != "" {
 			b.WriteString(fmt.Sprintf("name = %s\n", strconv.Quote(p.Name)))
 		}
+		if len(p.ExcludeWorktrees) > 0 {
+			b.WriteString(fmt.Sprintf("exclude_worktrees = %s\n", renderTOMLStringArray(p.ExcludeWorktrees)))
+		}
+		if p.Pinned {
+			b.WriteString("pinned = true\n")
+		}
+		if p.Workspace != "" {
+			b.WriteString(fmt.Sprintf("workspace = %s\n", strconv.Quote(p.Workspace)))
+		}
+	}
+	for _, t := range cfg.Templates {
+		b.WriteString("\n[[templates]]\n")
+		b.WriteString(fmt.Sprintf("name = %s\n", strconv.Quote(t.Name)))
+		for _, w := range t.Windows {
+			b.WriteString("\n[[templates.windows]]\n")
+			b.WriteString(fmt.Sprintf("name = %s\n", strconv.Quote(w.Name)))
+			if w.Layout != "" {
+				b.WriteString(fmt.Sprintf("layout = %s\n", strconv.Quote(w.Layout)))
+			}
+			if len(w.Panes) > 0 {
+				b.WriteString(fmt.Sprintf("panes = %s\n", renderTOMLStringArray(w.Panes)))
+			}
+		}
 	}
 	return []byte(b.String())
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"testing"
@@ -24,9 +25,66 @@ func TestDefaultConfigDir(t *testing.T) {
 	}
 }
 
+func TestStateDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		xdg      string
+		want     string
+	}{
+		{"default", "", "", "/home/me/.local/state/cb"},
+		{"XDG_STATE_HOME", "", "/xdg/state", "/xdg/state/cb"},
+		{"relative XDG_STATE_HOME is ignored", "", "state", "/home/me/.local/state/cb"},
+		{"CB_STATE_DIR wins", "/run/cb/", "/xdg/state", "/run/cb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(StateDirEnv, tt.override)
+			t.Setenv("XDG_STATE_HOME", tt.xdg)
+			if got := stateDir("/home/me"); got != tt.want {
+				t.Errorf("stateDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatePath_MovesLegacyFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg := &Config{
+		ConfigDir: filepath.Join(tmpDir, "config"),
+		StateDir:  filepath.Join(tmpDir, "state"),
+	}
+	if err := os.MkdirAll(cfg.ConfigDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := filepath.Join(cfg.ConfigDir, sessionRegistryName)
+	if err := os.WriteFile(legacy, []byte(`{"sessions":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	path := cfg.SessionRegistryPath()
+	if want := filepath.Join(cfg.StateDir, sessionRegistryName); path != want {
+		t.Fatalf("SessionRegistryPath() = %q, want %q", path, want)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != `{"sessions":[]}` {
+		t.Fatalf("moved registry = %q, %v", data, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy registry still exists: %v", err)
+	}
+
+	// Without a legacy file the state path is returned untouched.
+	if got, want := cfg.PromptQueuePath(), filepath.Join(cfg.StateDir, promptQueueName); got != want {
+		t.Errorf("PromptQueuePath() = %q, want %q", got, want)
+	}
+}
+
 func TestEnsureDirs(t *testing.T) {
 	tmpDir := t.TempDir()
-	cfg := &Config{ConfigDir: filepath.Join(tmpDir, ".config", "cb")}
+	cfg := &Config{
+		ConfigDir: filepath.Join(tmpDir, ".config", "cb"),
+		StateDir:  filepath.Join(tmpDir, ".local", "state", "cb"),
+	}
 
 	if err := cfg.EnsureDirs(); err != nil {
 		t.Fatalf("EnsureDirs() error = %v", err)
@@ -34,6 +92,9 @@ func TestEnsureDirs(t *testing.T) {
 	if _, err := os.Stat(cfg.ConfigDir); os.IsNotExist(err) {
 		t.Error("ConfigDir was not created")
 	}
+	if _, err := os.Stat(cfg.StateDir); os.IsNotExist(err) {
+		t.Error("StateDir was not created")
+	}
 	if err := cfg.EnsureDirs(); err != nil {
 		t.Fatalf("EnsureDirs() second call error = %v", err)
 	}
@@ -294,3 +355,343 @@ func TestCanonicalPath(t *testing.T) {
 		t.Fatalf("CanonicalPath() = %q, want %q", got, want)
 	}
 }
+
+func TestSaveAndLoadUserConfig_TemplatesRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	input := UserConfig{
+		Version: SupportedConfigVersion,
+		Templates: []SessionTemplate{{
+			Name: "web",
+			Windows: []TemplateWindow{
+				{Name: "editor", Layout: "main-vertical", Panes: []string{"nvim", "", `echo "hi" # not a comment`}},
+				{Name: "shell"},
+			},
+		}},
+	}
+	if err := SaveUserConfig(input); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Templates, input.Templates) {
+		t.Fatalf("loaded.Templates = %+v, want %+v", loaded.Templates, input.Templates)
+	}
+	if _, ok := loaded.FindTemplate("web"); !ok {
+		t.Fatal("FindTemplate(web) not found")
+	}
+}
+
+func TestParseUserConfigTOML_TemplateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "window before template",
+			content: "version = 1\n[[templates.windows]]\nname = \"x\"\n",
+			wantErr: "must follow [[templates]]",
+		},
+		{
+			name:    "unknown window key",
+			content: "version = 1\n[[templates]]\nname = \"t\"\n[[templates.windows]]\ncolor = \"red\"\n",
+			wantErr: "unknown template window key",
+		},
+		{
+			name:    "bad panes array",
+			content: "version = 1\n[[templates]]\nname = \"t\"\n[[templates.windows]]\nname = \"w\"\npanes = [\"a\" \"b\"]\n",
+			wantErr: "expected ','",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseUserConfigTOML([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseUserConfigTOML() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveUserConfig_RejectsTemplateWithoutWindows(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Templates: []SessionTemplate{{Name: "empty"}}})
+	if err == nil || !strings.Contains(err.Error(), "at least one window") {
+		t.Fatalf("SaveUserConfig() error = %v, want window requirement", err)
+	}
+}
+
+func TestProjectConfig_ExcludesWorktree(t *testing.T) {
+	p := ProjectConfig{ExcludeWorktrees: []string{"repo-release-*", ".worktrees/legacy"}}
+	tests := map[string]bool{
+		".worktrees/repo-release-1.2": true,
+		".worktrees/legacy":           true,
+		".worktrees/repo-feature":     false,
+		"../elsewhere/legacy":         false,
+	}
+	for rel, want := range tests {
+		if got := p.ExcludesWorktree(rel); got != want {
+			t.Errorf("ExcludesWorktree(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestLoadUserConfig_ExcludeWorktreesRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := filepath.Join(home, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	input := UserConfig{
+		Version:  SupportedConfigVersion,
+		Projects: []ProjectConfig{{Path: repo, ExcludeWorktrees: []string{"repo-release-*"}}},
+	}
+	if err := SaveUserConfig(input); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if len(loaded.Projects) != 1 || !reflect.DeepEqual(loaded.Projects[0].ExcludeWorktrees, []string{"repo-release-*"}) {
+		t.Fatalf("loaded.Projects = %+v, want exclude_worktrees preserved", loaded.Projects)
+	}
+
+	input.Projects[0].ExcludeWorktrees = []string{"[bad"}
+	if err := SaveUserConfig(input); err == nil {
+		t.Fatal("SaveUserConfig() error = nil, want invalid pattern error")
+	}
+}
+
+func TestUserConfig_IgnoreSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	input := UserConfig{Version: SupportedConfigVersion, IgnoreSessions: []string{"scratch", "tmp-*"}}
+	if err := SaveUserConfig(input); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.IgnoreSessions, input.IgnoreSessions) {
+		t.Fatalf("loaded.IgnoreSessions = %v, want %v", loaded.IgnoreSessions, input.IgnoreSessions)
+	}
+
+	for name, want := range map[string]bool{"scratch": true, "tmp-1": true, "cb_scratch": false} {
+		if got := loaded.IgnoresSession(name); got != want {
+			t.Errorf("IgnoresSession(%q) = %v, want %v", name, got, want)
+		}
+	}
+
+	if _, err := parseUserConfigTOML([]byte("version = 1\n\n[[projects]]\npath = \"/x\"\nignore_sessions = [\"a\"]\n")); err == nil {
+		t.Fatal("parseUserConfigTOML() error = nil, want ignore_sessions top-level error")
+	}
+}
+
+func TestUserConfig_PinsRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := filepath.Join(home, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	cfg := UserConfig{Version: SupportedConfigVersion, Projects: []ProjectConfig{{Path: repo}}}
+	if !cfg.TogglePinnedSession("cb_focus") || !cfg.TogglePinnedSession("cb_other") {
+		t.Fatal("TogglePinnedSession() = false, want pinned")
+	}
+	if cfg.TogglePinnedSession("cb_other") {
+		t.Fatal("second TogglePinnedSession() = true, want unpinned")
+	}
+	canonical, err := CanonicalPath(repo)
+	if err != nil {
+		t.Fatalf("CanonicalPath() error = %v", err)
+	}
+	cfg.Projects[0].Path = canonical
+	if pinned, err := cfg.TogglePinnedProject(canonical); err != nil || !pinned {
+		t.Fatalf("TogglePinnedProject() = %v, %v; want pinned", pinned, err)
+	}
+	if _, err := cfg.TogglePinnedProject("/nowhere"); err == nil {
+		t.Fatal("TogglePinnedProject(unknown) error = nil, want error")
+	}
+
+	if err := SaveUserConfig(cfg); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.PinnedSessions, []string{"cb_focus"}) || !loaded.PinsSession("cb_focus") {
+		t.Fatalf("loaded.PinnedSessions = %v, want [cb_focus]", loaded.PinnedSessions)
+	}
+	if len(loaded.Projects) != 1 || !loaded.Projects[0].Pinned {
+		t.Fatalf("loaded.Projects = %+v, want pinned project", loaded.Projects)
+	}
+
+	if _, err := parseUserConfigTOML([]byte("version = 1\npinned = true\n")); err == nil {
+		t.Fatal("parseUserConfigTOML() error = nil, want pinned outside [[projects]] error")
+	}
+}
+
+func TestUserConfig_MultiplexerRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Multiplexer: MultiplexerZellij}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if loaded.Multiplexer != MultiplexerZellij {
+		t.Fatalf("loaded.Multiplexer = %q, want %q", loaded.Multiplexer, MultiplexerZellij)
+	}
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Multiplexer: "screen"}); err == nil {
+		t.Fatal("SaveUserConfig() error = nil, want unknown multiplexer error")
+	}
+}
+
+func TestUserConfig_DisplayRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Theme: "light", ASCII: true}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if loaded.Theme != "light" || !loaded.ASCII {
+		t.Fatalf("loaded = %+v, want light theme and ascii", loaded)
+	}
+
+	if _, err := parseUserConfigTOML([]byte("version = 1\n[[projects]]\npath = \"/x\"\ntheme = \"light\"\n")); err == nil {
+		t.Fatal("parseUserConfigTOML() error = nil, want theme must be top-level error")
+	}
+	if _, err := parseUserConfigTOML([]byte("version = 1\nascii = maybe\n")); err == nil {
+		t.Fatal("parseUserConfigTOML() error = nil, want invalid ascii value error")
+	}
+}
+
+func TestUserConfig_StatusRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	status := StatusConfig{
+		Busy:            []string{"Thinking hard"},
+		Spinners:        "◴◷",
+		Confirmations:   []string{"apply patch?"},
+		Errors:          []string{"rate limited"},
+		WaitingRegex:    []string{`(?i)approve \d+ edits`},
+		ReplaceDefaults: true,
+	}
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Theme: "light", Status: status}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Status, status) || loaded.Theme != "light" {
+		t.Fatalf("loaded = %+v, want status %+v and light theme", loaded, status)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown key", content: "version = 1\n[status]\ncolor = \"red\"\n", wantErr: "unknown status key"},
+		{name: "top-level key after table", content: "version = 1\n[status]\nbusy = []\ntheme = \"light\"\n", wantErr: "unknown status key"},
+		{name: "bad replace_defaults", content: "version = 1\n[status]\nreplace_defaults = maybe\n", wantErr: "invalid replace_defaults"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseUserConfigTOML([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseUserConfigTOML() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Status: StatusConfig{BusyRegex: []string{"("}}}); err == nil {
+		t.Fatal("SaveUserConfig() error = nil, want invalid busy_regex error")
+	}
+}
+
+func TestUserConfig_ResumeRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	resume := map[string]string{"claude": "claude --resume", "codex": "codex resume --last --full-auto"}
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Resume: resume}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Resume, resume) {
+		t.Fatalf("loaded.Resume = %v, want %v", loaded.Resume, resume)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown agent", content: "version = 1\n[resume]\naider = \"aider --restore-chat-history\"\n", wantErr: "unknown resume agent"},
+		{name: "empty command", content: "version = 1\n[resume]\nclaude = \" \"\n", wantErr: "resume.claude must be non-empty"},
+		{name: "unquoted command", content: "version = 1\n[resume]\nclaude = claude --continue\n", wantErr: "quoted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseUserConfigTOML([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseUserConfigTOML() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserConfig_Workspaces(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	var projects []ProjectConfig
+	for _, p := range []struct{ dir, workspace string }{{"api", "work"}, {"lib", " oss "}, {"web", "work"}, {"notes", ""}} {
+		dir := filepath.Join(home, p.dir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+		projects = append(projects, ProjectConfig{Path: dir, Workspace: p.workspace})
+	}
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Projects: projects}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if got, want := loaded.Workspaces(), []string{"oss", "work"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Workspaces() = %q, want %q", got, want)
+	}
+
+	err = SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Projects: []ProjectConfig{{Path: projects[0].Path, Workspace: "  "}}})
+	if err == nil || !strings.Contains(err.Error(), "workspace must be non-empty") {
+		t.Fatalf("SaveUserConfig() error = %v, want blank workspace rejected", err)
+	}
+}
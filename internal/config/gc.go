@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GCConfig is the [gc] table: the policy cb gc archives abandoned workflows
+// by.
+type GCConfig struct {
+	// ArchiveAfter is how long a DONE session must have been inactive
+	// before cb gc archives it, as a Go duration ("48h"). Empty disables
+	// the policy.
+	ArchiveAfter string `toml:"archive_after,omitempty"`
+}
+
+// IsZero reports whether the table sets nothing.
+func (g GCConfig) IsZero() bool {
+	return g.ArchiveAfter == ""
+}
+
+// ArchiveAfterDuration returns ArchiveAfter parsed, and false when the
+// policy is disabled.
+func (g GCConfig) ArchiveAfterDuration() (time.Duration, bool) {
+	d, err := time.ParseDuration(g.ArchiveAfter)
+	if err != nil || d <= 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// parseGCKey assigns one key inside [gc].
+func parseGCKey(g *GCConfig, key, value string) error {
+	switch key {
+	case "archive_after":
+		v, err := parseTOMLString(value)
+		if err != nil {
+			return err
+		}
+		g.ArchiveAfter = v
+		return validateGC(*g)
+	default:
+		return fmt.Errorf("unknown gc key %q", key)
+	}
+}
+
+func validateGC(g GCConfig) error {
+	if g.ArchiveAfter == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(g.ArchiveAfter)
+	if err != nil {
+		return fmt.Errorf("invalid gc archive_after %q: want a duration such as \"48h\"", g.ArchiveAfter)
+	}
+	if d <= 0 {
+		return fmt.Errorf("invalid gc archive_after %q: must be positive", g.ArchiveAfter)
+	}
+	return nil
+}
+
+func renderGCTable(b *strings.Builder, g GCConfig) {
+	b.WriteString("\n[gc]\n")
+	if g.ArchiveAfter != "" {
+		b.WriteString(fmt.Sprintf("archive_after = %s\n", strconv.Quote(g.ArchiveAfter)))
+	}
+}
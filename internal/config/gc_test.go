@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserConfig_GCRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, GC: GCConfig{ArchiveAfter: "48h"}}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if d, ok := loaded.GC.ArchiveAfterDuration(); !ok || d != 48*time.Hour {
+		t.Fatalf("ArchiveAfterDuration() = %v, %v; want 48h, true", d, ok)
+	}
+	if _, ok := (GCConfig{}).ArchiveAfterDuration(); ok {
+		t.Fatal("ArchiveAfterDuration() of an empty table should be disabled")
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "not a duration", content: "version = 1\n[gc]\narchive_after = \"2 days\"\n", wantErr: "want a duration"},
+		{name: "not positive", content: "version = 1\n[gc]\narchive_after = \"0s\"\n", wantErr: "must be positive"},
+		{name: "unknown key", content: "version = 1\n[gc]\nstatuses = [\"DONE\"]\n", wantErr: "unknown gc key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseUserConfigTOML([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseUserConfigTOML() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
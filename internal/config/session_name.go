@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Session name styles selectable with the session_name config key.
+const (
+	// SessionNameBranch names a workflow's session cb_<branch>, keeping the
+	// branch's slashes ("cb_feature/add-login").
+	SessionNameBranch = "branch"
+	// SessionNameDashed replaces the branch's slashes with dashes
+	// ("cb_feature-add-login"), which tmux targets and filters handle more
+	// easily. The branch is still recorded in the session's @cb_branch.
+	SessionNameDashed = "dashed"
+)
+
+// SessionNameForBranch returns the session name for branch under the
+// session_name style; empty means SessionNameBranch.
+func SessionNameForBranch(style, branch string) string {
+	if style == SessionNameDashed {
+		branch = strings.ReplaceAll(branch, "/", "-")
+	}
+	return "cb_" + branch
+}
+
+func validateSessionName(style string) error {
+	switch style {
+	case "", SessionNameBranch, SessionNameDashed:
+		return nil
+	default:
+		return fmt.Errorf("unknown session_name %q (want %q or %q)", style, SessionNameBranch, SessionNameDashed)
+	}
+}
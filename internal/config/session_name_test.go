@@ -0,0 +1,40 @@
+package config
+
+import "testing"
+
+func TestSessionNameForBranch(t *testing.T) {
+	tests := []struct {
+		style  string
+		branch string
+		want   string
+	}{
+		{"", "feature/add-login", "cb_feature/add-login"},
+		{SessionNameBranch, "feature/add-login", "cb_feature/add-login"},
+		{SessionNameDashed, "feature/add-login", "cb_feature-add-login"},
+		{SessionNameDashed, "fix-123", "cb_fix-123"},
+	}
+	for _, tt := range tests {
+		if got := SessionNameForBranch(tt.style, tt.branch); got != tt.want {
+			t.Errorf("SessionNameForBranch(%q, %q) = %q, want %q", tt.style, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestUserConfig_SessionNameRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, SessionName: SessionNameDashed}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if loaded.SessionName != SessionNameDashed {
+		t.Fatalf("loaded.SessionName = %q, want %q", loaded.SessionName, SessionNameDashed)
+	}
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, SessionName: "slug"}); err == nil {
+		t.Fatal("SaveUserConfig() error = nil, want unknown session_name error")
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// StartConfig is the [start] table: defaults for cb start, overridden by
+// its flags when they are given.
+type StartConfig struct {
+	// Detach creates sessions without attaching to them (--detach).
+	Detach bool `toml:"detach,omitempty"`
+	// Agents are launched in their own windows (--agents); each is one of
+	// AgentNames.
+	Agents []string `toml:"agents,omitempty"`
+	// AddProject adds an unconfigured repo to the projects without asking
+	// (--add-project).
+	AddProject bool `toml:"add_project,omitempty"`
+	// ManageGitignore set to false stops new worktrees from adding
+	// .worktrees/ to the repo's .gitignore; nil means true.
+	ManageGitignore *bool `toml:"manage_gitignore,omitempty"`
+}
+
+// IsZero reports whether the table sets nothing.
+func (s StartConfig) IsZero() bool {
+	return !s.Detach && len(s.Agents) == 0 && !s.AddProject && s.ManageGitignore == nil
+}
+
+// ManagesGitignore reports whether new worktrees add .worktrees/ to the
+// repo's .gitignore.
+func (s StartConfig) ManagesGitignore() bool {
+	return s.ManageGitignore == nil || *s.ManageGitignore
+}
+
+// parseStartKey assigns one key inside [start].
+func parseStartKey(s *StartConfig, key, value string) error {
+	switch key {
+	case "detach", "add_project", "manage_gitignore":
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s value %q", key, value)
+		}
+		switch key {
+		case "detach":
+			s.Detach = v
+		case "add_project":
+			s.AddProject = v
+		case "manage_gitignore":
+			s.ManageGitignore = &v
+		}
+	case "agents":
+		agents, err := parseTOMLStringArray(value)
+		if err != nil {
+			return err
+		}
+		s.Agents = agents
+		return validateStart(*s)
+	default:
+		return fmt.Errorf("unknown start key %q", key)
+	}
+	return nil
+}
+
+func validateStart(s StartConfig) error {
+	for _, agent := range s.Agents {
+		if !slices.Contains(AgentNames, agent) {
+			return fmt.Errorf("unknown start agent %q (want one of: %s)", agent, strings.Join(AgentNames, ", "))
+		}
+	}
+	return nil
+}
+
+func renderStartTable(b *strings.Builder, s StartConfig) {
+	b.WriteString("\n[start]\n")
+	if s.Detach {
+		b.WriteString("detach = true\n")
+	}
+	if len(s.Agents) > 0 {
+		b.WriteString(fmt.Sprintf("agents = %s\n", renderTOMLStringArray(s.Agents)))
+	}
+	if s.AddProject {
+		b.WriteString("add_project = true\n")
+	}
+	if s.ManageGitignore != nil {
+		b.WriteString(fmt.Sprintf("manage_gitignore = %t\n", *s.ManageGitignore))
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUserConfig_StartRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	off := false
+	start := StartConfig{Detach: true, Agents: []string{"claude", "codex"}, AddProject: true, ManageGitignore: &off}
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, Start: start}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Start, start) {
+		t.Fatalf("loaded.Start = %+v, want %+v", loaded.Start, start)
+	}
+	if loaded.Start.ManagesGitignore() {
+		t.Fatal("ManagesGitignore() = true, want false")
+	}
+	if !(StartConfig{}).ManagesGitignore() {
+		t.Fatal("ManagesGitignore() of an empty table = false, want true")
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown agent", content: "version = 1\n[start]\nagents = [\"aider\"]\n", wantErr: "unknown start agent"},
+		{name: "invalid bool", content: "version = 1\n[start]\ndetach = yes\n", wantErr: "invalid detach value"},
+		{name: "unknown key", content: "version = 1\n[start]\ntemplate = \"web\"\n", wantErr: "unknown start key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseUserConfigTOML([]byte(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parseUserConfigTOML() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrConflict is returned when config.toml keeps changing while
+// UpdateUserConfig rewrites it, e.g. because it is being edited by hand.
+var ErrConflict = errors.New("config file changed while it was being updated")
+
+// ErrUnchanged is returned by an UpdateUserConfig mutate func that found
+// nothing to change, to leave the file as it is.
+var ErrUnchanged = errors.New("config unchanged")
+
+// updateAttempts bounds how often UpdateUserConfig reloads and reapplies its
+// change after a conflict.
+const updateAttempts = 3
+
+// Lock timing. They are variables so tests can shorten them.
+var (
+	// lockTimeout is how long to wait for another cb process to release
+	// the config lock.
+	lockTimeout = 5 * time.Second
+	// lockRetryInterval is how often a held lock is polled.
+	lockRetryInterval = 50 * time.Millisecond
+	// lockStaleAfter is the age at which a lock is taken to be left over
+	// by a cb process that died, and is broken.
+	lockStaleAfter = 30 * time.Second
+)
+
+// UpdateUserConfig loads config.toml, applies mutate, and saves the result,
+// holding a lock file so concurrent cb processes apply their changes one
+// after the other instead of overwriting each other. If the file is changed
+// by something that does not take the lock (an editor, an older cb) between
+// the load and the save, the change is reapplied to the new content; mutate
+// may therefore run more than once. An error from mutate aborts the update
+// and is returned as is, except ErrUnchanged, which skips the save.
+func UpdateUserConfig(mutate func(cfg *UserConfig) error) error {
+	c, err := New()
+	if err != nil {
+		return err
+	}
+	if err := c.EnsureDirs(); err != nil {
+		return err
+	}
+	path := c.ConfigFilePath()
+
+	unlock, err := lockConfigFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for range updateAttempts {
+		cfg, _, loaded, err := loadUserConfigFile(path)
+		if err != nil {
+			return err
+		}
+		if err := mutate(&cfg); err != nil {
+			if errors.Is(err, ErrUnchanged) {
+				return nil
+			}
+			return err
+		}
+		err = writeUserConfig(path, cfg, func() error { return checkUnchanged(path, loaded) })
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to update config file %s: %w; check it and try again", path, ErrConflict)
+}
+
+// checkUnchanged returns ErrConflict unless the file at path still holds
+// loaded. A missing file matches empty content.
+func checkUnchanged(path string, loaded []byte) error {
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if !bytes.Equal(current, loaded) {
+		return ErrConflict
+	}
+	return nil
+}
+
+// lockConfigFile takes the lock file guarding writes to the config file at
+// path, waiting up to lockTimeout for another process to release it. The
+// returned func releases it.
+func lockConfigFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	unlock := func() { _ = os.Remove(lockPath) }
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := createLockFile(lockPath)
+		if err != nil {
+			return nil, err
+		}
+		if locked {
+			return unlock, nil
+		}
+
+		// A stale lock was left by a process that died holding it. Break it
+		// and take it in one step: when another process broke it first and
+		// already holds the new lock, the exclusive create fails and this is
+		// ordinary contention, not a reason to break the lock again.
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			_ = os.Remove(lockPath)
+			locked, err := createLockFile(lockPath)
+			if err != nil {
+				return nil, err
+			}
+			if locked {
+				return unlock, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("config file %s is locked by another cb process; if none is running, remove %s", path, lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// createLockFile creates the lock file at lockPath, recording this process's
+// PID. locked is false when the file already exists.
+func createLockFile(lockPath string) (locked bool, err error) {
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock config file: %w", err)
+	}
+	_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+	_ = f.Close()
+	return true, nil
+}
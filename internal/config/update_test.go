@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func shortenLockTiming(t *testing.T, timeout, staleAfter time.Duration) {
+	t.Helper()
+	origTimeout, origRetry, origStale := lockTimeout, lockRetryInterval, lockStaleAfter
+	lockTimeout, lockRetryInterval, lockStaleAfter = timeout, time.Millisecond, staleAfter
+	t.Cleanup(func() {
+		lockTimeout, lockRetryInterval, lockStaleAfter = origTimeout, origRetry, origStale
+	})
+}
+
+func TestUpdateUserConfig_ConcurrentUpdatesAllApply(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	const writers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- UpdateUserConfig(func(cfg *UserConfig) error {
+				cfg.PinnedSessions = append(cfg.PinnedSessions, fmt.Sprintf("cb_%d", i))
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateUserConfig() error = %v", err)
+		}
+	}
+
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if len(loaded.PinnedSessions) != writers {
+		t.Fatalf("PinnedSessions = %v, want %d entries", loaded.PinnedSessions, writers)
+	}
+}
+
+func TestUpdateUserConfig_UnchangedSkipsWrite(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	err := UpdateUserConfig(func(cfg *UserConfig) error { return ErrUnchanged })
+	if err != nil {
+		t.Fatalf("UpdateUserConfig() error = %v", err)
+	}
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := os.Stat(c.ConfigFilePath()); !os.IsNotExist(err) {
+		t.Fatalf("config file should not be written, stat error = %v", err)
+	}
+
+	wantErr := errors.New("boom")
+	if err := UpdateUserConfig(func(cfg *UserConfig) error { return wantErr }); !errors.Is(err, wantErr) {
+		t.Fatalf("UpdateUserConfig() error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestUpdateUserConfig_ReappliesAfterExternalEdit(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := SaveUserConfig(UserConfig{Version: SupportedConfigVersion, PinnedSessions: []string{"cb_a"}}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	calls := 0
+	err = UpdateUserConfig(func(cfg *UserConfig) error {
+		calls++
+		if calls == 1 {
+			// An editor saves between the load and the write.
+			edited := "version = 1\npinned_sessions = [\"cb_a\", \"cb_edited\"]\n"
+			if err := os.WriteFile(c.ConfigFilePath(), []byte(edited), 0600); err != nil {
+				t.Fatalf("write edited config: %v", err)
+			}
+		}
+		cfg.PinnedSessions = append(cfg.PinnedSessions, "cb_b")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateUserConfig() error = %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("mutate ran %d times, want 2", calls)
+	}
+
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	want := []string{"cb_a", "cb_edited", "cb_b"}
+	if !slices.Equal(loaded.PinnedSessions, want) {
+		t.Fatalf("PinnedSessions = %v, want %v", loaded.PinnedSessions, want)
+	}
+}
+
+func TestUpdateUserConfig_PersistentConflict(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	calls := 0
+	err = UpdateUserConfig(func(cfg *UserConfig) error {
+		calls++
+		content := fmt.Sprintf("version = 1\npinned_sessions = [\"cb_%d\"]\n", calls)
+		if err := os.WriteFile(c.ConfigFilePath(), []byte(content), 0600); err != nil {
+			t.Fatalf("write edited config: %v", err)
+		}
+		return nil
+	})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("UpdateUserConfig() error = %v, want ErrConflict", err)
+	}
+	if calls != updateAttempts {
+		t.Fatalf("mutate ran %d times, want %d", calls, updateAttempts)
+	}
+}
+
+func TestLockConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		lockAge time.Duration
+		wantErr string
+	}{
+		{name: "held lock times out", lockAge: 0, wantErr: "locked by another cb process"},
+		{name: "stale lock is broken", lockAge: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortenLockTiming(t, 20*time.Millisecond, time.Minute)
+			path := t.TempDir() + "/config.toml"
+			lockPath := path + ".lock"
+			if err := os.WriteFile(lockPath, []byte("1\n"), 0600); err != nil {
+				t.Fatalf("write lock: %v", err)
+			}
+			modTime := time.Now().Add(-tt.lockAge)
+			if err := os.Chtimes(lockPath, modTime, modTime); err != nil {
+				t.Fatalf("chtimes lock: %v", err)
+			}
+
+			unlock, err := lockConfigFile(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("lockConfigFile() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("lockConfigFile() error = %v", err)
+			}
+			unlock()
+			if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+				t.Fatalf("lock should be removed on unlock, stat error = %v", err)
+			}
+		})
+	}
+}
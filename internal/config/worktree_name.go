@@ -0,0 +1,130 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultWorktreeName is the worktree_name template used when none is set:
+// new worktrees live at <repo>/.worktrees/<repo>-<branch>.
+const DefaultWorktreeName = "{project}-{branch}"
+
+var (
+	worktreeNamePlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+	ticketPattern           = regexp.MustCompile(`(?i)[a-z][a-z0-9]*-[0-9]+`)
+)
+
+// RenderWorktreeName expands a worktree_name template for project and branch.
+//
+// Placeholders are {project}, {branch}, and {ticket} (the first ticket-like
+// token in the branch, such as "proj-123", or the branch's last path segment
+// when there is none). Each may be followed by filters: |base keeps the last
+// path segment, |dashed replaces slashes with dashes, and |lower lowercases.
+// A positive maxLen truncates longer results to that many bytes, ending in a
+// hash of the full name (see truncateWorktreeName).
+func RenderWorktreeName(template, project, branch string, maxLen int) (string, error) {
+	if template == "" {
+		template = DefaultWorktreeName
+	}
+
+	var renderErr error
+	name := worktreeNamePlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		value, err := expandWorktreePlaceholder(match[1:len(match)-1], project, branch)
+		if err != nil && renderErr == nil {
+			renderErr = err
+		}
+		return value
+	})
+	if renderErr != nil {
+		return "", renderErr
+	}
+
+	name = truncateWorktreeName(name, maxLen)
+	cleaned := path.Clean(name)
+	if name == "" || cleaned == "." || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("worktree_name %q renders to invalid directory name %q", template, name)
+	}
+	return cleaned, nil
+}
+
+func expandWorktreePlaceholder(expr, project, branch string) (string, error) {
+	parts := strings.Split(expr, "|")
+	var value string
+	switch strings.TrimSpace(parts[0]) {
+	case "project":
+		value = project
+	case "branch":
+		value = branch
+	case "ticket":
+		value = ticketPattern.FindString(branch)
+		if value == "" {
+			value = path.Base(branch)
+		}
+	default:
+		return "", fmt.Errorf("worktree_name: unknown placeholder {%s}", expr)
+	}
+
+	for _, filter := range parts[1:] {
+		switch strings.TrimSpace(filter) {
+		case "base":
+			value = path.Base(value)
+		case "dashed":
+			value = strings.ReplaceAll(value, "/", "-")
+		case "lower":
+			value = strings.ToLower(value)
+		default:
+			return "", fmt.Errorf("worktree_name: unknown filter %q in {%s}", filter, expr)
+		}
+	}
+	return value, nil
+}
+
+// worktreeNameHashLen is the number of hex digits of the hash that
+// truncateWorktreeName appends.
+const worktreeNameHashLen = 6
+
+// truncateWorktreeName shortens name to at most maxLen bytes: a prefix cut
+// on a rune boundary, without trailing separators, then "-" and a short hash
+// of the full name, so branches sharing a long prefix still get distinct
+// directories. A maxLen too small for the hash just cuts the name.
+func truncateWorktreeName(name string, maxLen int) string {
+	if maxLen <= 0 || len(name) <= maxLen {
+		return name
+	}
+	if maxLen <= worktreeNameHashLen+1 {
+		return cutWorktreeName(name, maxLen)
+	}
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:worktreeNameHashLen]
+	prefix := cutWorktreeName(name, maxLen-worktreeNameHashLen-1)
+	if prefix == "" {
+		return hash
+	}
+	return prefix + "-" + hash
+}
+
+// cutWorktreeName cuts name to at most maxLen bytes on a rune boundary,
+// dropping trailing separators left by the cut.
+func cutWorktreeName(name string, maxLen int) string {
+	name = name[:maxLen]
+	for !utf8.ValidString(name) {
+		name = name[:len(name)-1]
+	}
+	return strings.TrimRight(name, "-_./")
+}
+
+func validateWorktreeName(template string, maxLen int) error {
+	if maxLen < 0 {
+		return fmt.Errorf("worktree_name_max must not be negative")
+	}
+	if template == "" {
+		return nil
+	}
+	_, err := RenderWorktreeName(template, "project", "feature/proj-1-example", maxLen)
+	return err
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderWorktreeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		branch   string
+		maxLen   int
+		want     string
+		wantErr  string
+	}{
+		{name: "default", branch: "feat", want: "repo-feat"},
+		{name: "default keeps slashes", branch: "feature/add-login", want: "repo-feature/add-login"},
+		{name: "base filter", template: "{branch|base}", branch: "feature/add-login", want: "add-login"},
+		{name: "dashed filter", template: "{project}-{branch|dashed}", branch: "feature/add-login", want: "repo-feature-add-login"},
+		{name: "ticket", template: "{ticket|lower}", branch: "feature/PROJ-123-auth-flow", want: "proj-123"},
+		{name: "ticket falls back to base", template: "{ticket}", branch: "feature/auth", want: "auth"},
+		{name: "truncated with hash", template: "{branch|dashed}", branch: "feature/a-very-long-branch-name", maxLen: 17, want: "feature-a-63143a"},
+		{name: "truncation keeps a short prefix", template: "{branch|dashed}", branch: "feature/x-long", maxLen: 10, want: "fea-d74b5c"},
+		{name: "too short for a hash", template: "{branch|dashed}", branch: "feature/x-long", maxLen: 7, want: "feature"},
+		{name: "unknown placeholder", template: "{owner}-{branch}", branch: "feat", wantErr: "unknown placeholder"},
+		{name: "unknown filter", template: "{branch|upper}", branch: "feat", wantErr: "unknown filter"},
+		{name: "escapes worktrees dir", template: "../{branch}", branch: "feat", wantErr: "invalid directory name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderWorktreeName(tt.template, "repo", tt.branch, tt.maxLen)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RenderWorktreeName() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RenderWorktreeName() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("RenderWorktreeName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserConfig_WorktreeNameRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	input := UserConfig{Version: SupportedConfigVersion, WorktreeName: "{ticket}", WorktreeNameMax: 40}
+	if err := SaveUserConfig(input); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+	loaded, err := LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if loaded.WorktreeName != "{ticket}" || loaded.WorktreeNameMax != 40 {
+		t.Fatalf("loaded = %+v, want worktree_name and worktree_name_max preserved", loaded)
+	}
+
+	input.WorktreeName = "{nope}"
+	if err := SaveUserConfig(input); err == nil {
+		t.Fatal("SaveUserConfig() error = nil, want invalid template error")
+	}
+}
+
+func TestRenderWorktreeName_TruncatedNamesStayDistinct(t *testing.T) {
+	a, err := RenderWorktreeName("{branch|dashed}", "repo", "feature/shared-long-prefix-one", 20)
+	if err != nil {
+		t.Fatalf("RenderWorktreeName() error = %v", err)
+	}
+	b, err := RenderWorktreeName("{branch|dashed}", "repo", "feature/shared-long-prefix-two", 20)
+	if err != nil {
+		t.Fatalf("RenderWorktreeName() error = %v", err)
+	}
+	if a == b || len(a) > 20 || len(b) > 20 {
+		t.Fatalf("names = %q, %q, want distinct names of at most 20 bytes", a, b)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// wslMountRoot is where WSL mounts Windows drives by default (/mnt/c for C:).
+const wslMountRoot = "/mnt/"
+
+// isWSL reports whether cb runs inside Windows Subsystem for Linux.
+var isWSL = sync.OnceValue(func() bool {
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	return err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft")
+})
+
+// wslPath translates a Windows path pasted into WSL ("C:\Users\me\repo",
+// "\\wsl$\Ubuntu\home\me\repo") to the Linux path of the same directory.
+// Other paths are returned unchanged.
+func wslPath(path string) string {
+	if len(path) >= 2 && path[1] == ':' && isDriveLetter(path[0]) && (len(path) == 2 || path[2] == '\\' || path[2] == '/') {
+		rest := strings.ReplaceAll(path[2:], `\`, "/")
+		return wslMountRoot + strings.ToLower(path[:1]) + rest
+	}
+	for _, prefix := range []string{`\\wsl$\`, `\\wsl.localhost\`} {
+		if len(path) > len(prefix) && strings.EqualFold(path[:len(prefix)], prefix) {
+			// Drop the distro name; the rest is rooted at /.
+			_, rest, _ := strings.Cut(path[len(prefix):], `\`)
+			return "/" + strings.ReplaceAll(rest, `\`, "/")
+		}
+	}
+	return path
+}
+
+func isDriveLetter(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWSLPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: `C:\Users\me\src\repo`, want: "/mnt/c/Users/me/src/repo"},
+		{path: "d:/work/repo", want: "/mnt/d/work/repo"},
+		{path: "C:", want: "/mnt/c"},
+		{path: `\\wsl$\Ubuntu\home\me\repo`, want: "/home/me/repo"},
+		{path: `\\wsl.localhost\Ubuntu-22.04\home\me`, want: "/home/me"},
+		{path: "/home/me/repo", want: "/home/me/repo"},
+		{path: "repo:feature", want: "repo:feature"},
+	}
+
+	for _, tt := range tests {
+		if got := wslPath(tt.path); got != tt.want {
+			t.Errorf("wslPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalPath_TranslatesWindowsPathsInWSL(t *testing.T) {
+	orig := isWSL
+	isWSL = func() bool { return true }
+	t.Cleanup(func() { isWSL = orig })
+
+	// /mnt/z is not mounted here, so resolution fails on the translated path.
+	_, err := CanonicalPath(`Z:\repo`)
+	if err == nil || !strings.Contains(err.Error(), "/mnt/z/repo") {
+		t.Fatalf("CanonicalPath() error = %v, want one naming /mnt/z/repo", err)
+	}
+}
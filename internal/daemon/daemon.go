@@ -0,0 +1,227 @@
+// Package daemon keeps the discovery tree and per-window status history in
+// memory and serves them to cb commands over a unix socket, so clients skip
+// the tmux/git/ps round trips of a full discovery.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/events"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// maxHistory bounds the status changes kept per window.
+const maxHistory = 50
+
+// ErrRunning is returned by Listen when another daemon answers on the socket.
+var ErrRunning = errors.New("daemon already running")
+
+// Discoverer loads the project/worktree/session hierarchy.
+type Discoverer interface {
+	Discover() (discovery.Result, error)
+}
+
+// StatusChange records a window entering Status at At.
+type StatusChange struct {
+	Status tmux.Status `json:"status"`
+	At     time.Time   `json:"at"`
+}
+
+// Snapshot is the daemon's latest discovery result. Err holds the last
+// discovery error, in which case Result is the last good result. Interval is
+// how often the daemon refreshes, so clients can tell a snapshot that
+// stopped updating by UpdatedAt. History is keyed by tmux.Window.Target like
+// Result's window maps.
+type Snapshot struct {
+	Result    discovery.Result          `json:"result"`
+	Err       string                    `json:"error,omitempty"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+	Interval  time.Duration             `json:"interval"`
+	History   map[string][]StatusChange `json:"history"`
+}
+
+// Server refreshes a Snapshot on an interval and serves it, along with tmux
+// actions, over the RPC protocol (see rpc.go).
+type Server struct {
+	discoverer Discoverer
+	actions    Actions
+	interval   time.Duration
+	now        func() time.Time
+	events     events.Bus
+
+	refreshMu   sync.Mutex
+	mu          sync.RWMutex
+	snapshot    Snapshot
+	subscribers map[chan Snapshot]struct{}
+}
+
+// NewServer creates a Server that rediscovers every interval and performs
+// kill/create/send requests with actions.
+func NewServer(d Discoverer, actions Actions, interval time.Duration) *Server {
+	s := &Server{
+		discoverer:  d,
+		actions:     actions,
+		interval:    interval,
+		now:         time.Now,
+		snapshot:    Snapshot{Interval: interval, History: map[string][]StatusChange{}},
+		subscribers: map[chan Snapshot]struct{}{},
+	}
+	s.events.Subscribe(s.recordStatus)
+	return s
+}
+
+// Events returns the bus each refresh publishes its changes on (see
+// events.Diff). The first successful refresh reports everything as new.
+func (s *Server) Events() *events.Bus {
+	return &s.events
+}
+
+// Snapshot returns a copy of the current snapshot.
+func (s *Server) Snapshot() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	snap := s.snapshot
+	snap.History = make(map[string][]StatusChange, len(s.snapshot.History))
+	for target, changes := range s.snapshot.History {
+		snap.History[target] = append([]StatusChange(nil), changes...)
+	}
+	return snap
+}
+
+// Refresh runs discovery once and folds the result into the snapshot,
+// publishes the changes on the event bus (which appends a history entry for
+// every window whose status changed), then publishes the snapshot to
+// subscribers.
+func (s *Server) Refresh() {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+	s.refresh()
+	snap := s.Snapshot()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for ch := range s.subscribers {
+		// Subscribers only need the latest snapshot; replace an unread one.
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- snap:
+		default:
+		}
+	}
+}
+
+func (s *Server) refresh() {
+	result, err := s.discoverer.Discover()
+	now := s.now()
+
+	s.mu.Lock()
+	s.snapshot.UpdatedAt = now
+	if err != nil {
+		s.snapshot.Err = err.Error()
+		s.mu.Unlock()
+		return
+	}
+	prev := s.snapshot.Result
+	s.snapshot.Err = ""
+	s.snapshot.Result = result
+	s.mu.Unlock()
+
+	s.events.Publish(events.Diff(prev, result, now)...)
+}
+
+// recordStatus keeps the per-window status history from StatusChanged
+// events, dropping the history of windows whose agent went away.
+func (s *Server) recordStatus(ev events.Event) {
+	if ev.Kind != events.StatusChanged {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ev.Status == "" {
+		delete(s.snapshot.History, ev.Target)
+		return
+	}
+	changes := append(s.snapshot.History[ev.Target], StatusChange{Status: ev.Status, At: ev.At})
+	if len(changes) > maxHistory {
+		changes = changes[len(changes)-maxHistory:]
+	}
+	s.snapshot.History[ev.Target] = changes
+}
+
+// Serve refreshes the snapshot every interval and answers clients on ln
+// until ctx is done.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	s.Refresh()
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Refresh()
+			}
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = ln.Close()
+	}()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to accept daemon connection: %w", err)
+		}
+		go s.handle(conn)
+	}
+}
+
+// subscribe registers a channel that receives each new snapshot. The returned
+// func unregisters it.
+func (s *Server) subscribe() (<-chan Snapshot, func()) {
+	ch := make(chan Snapshot, 1)
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch, func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}
+}
+
+// Listen opens the daemon socket at path, replacing a stale socket file left
+// by a daemon that exited. It returns ErrRunning if a daemon is answering.
+func Listen(path string) (net.Listener, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%w on %s", ErrRunning, path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale daemon socket %s: %w", path, err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("failed to restrict daemon socket %s: %w", path, err)
+	}
+	return ln, nil
+}
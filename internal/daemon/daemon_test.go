@@ -0,0 +1,148 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/events"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeDiscoverer struct {
+	results []discovery.Result
+	err     error
+	calls   int
+}
+
+func (f *fakeDiscoverer) Discover() (discovery.Result, error) {
+	if f.err != nil {
+		return discovery.Result{}, f.err
+	}
+	r := f.results[min(f.calls, len(f.results)-1)]
+	f.calls++
+	return r, nil
+}
+
+func statuses(m map[string]tmux.Status) discovery.Result {
+	return discovery.Result{WindowStatuses: m}
+}
+
+func TestServer_RefreshRecordsStatusChanges(t *testing.T) {
+	d := &fakeDiscoverer{results: []discovery.Result{
+		statuses(map[string]tmux.Status{"@1": tmux.StatusWorking, "@2": tmux.StatusIdle}),
+		statuses(map[string]tmux.Status{"@1": tmux.StatusWorking, "@2": tmux.StatusIdle}),
+		statuses(map[string]tmux.Status{"@1": tmux.StatusWaiting}),
+	}}
+	s := NewServer(d, nil, time.Second)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tick := 0
+	s.now = func() time.Time { tick++; return start.Add(time.Duration(tick) * time.Second) }
+
+	for range 3 {
+		s.Refresh()
+	}
+
+	snap := s.Snapshot()
+	want := []StatusChange{
+		{Status: tmux.StatusWorking, At: start.Add(time.Second)},
+		{Status: tmux.StatusWaiting, At: start.Add(3 * time.Second)},
+	}
+	got := snap.History["@1"]
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("history[@1] = %+v, want %+v", got, want)
+	}
+	if _, ok := snap.History["@2"]; ok {
+		t.Fatal("history for a closed window should be dropped")
+	}
+}
+
+func TestServer_PublishesEvents(t *testing.T) {
+	d := &fakeDiscoverer{results: []discovery.Result{
+		statuses(map[string]tmux.Status{"@1": tmux.StatusWorking}),
+		statuses(map[string]tmux.Status{"@1": tmux.StatusWorking}),
+		statuses(map[string]tmux.Status{"@1": tmux.StatusWaiting}),
+	}}
+	s := NewServer(d, nil, time.Second)
+	var got []events.Event
+	s.Events().Subscribe(func(ev events.Event) { got = append(got, ev) })
+
+	for range 3 {
+		s.Refresh()
+	}
+
+	if len(got) != 2 || got[0].Status != tmux.StatusWorking || got[1].Status != tmux.StatusWaiting || got[1].Previous != tmux.StatusWorking {
+		t.Fatalf("events = %+v, want the initial WORKING and the change to WAITING", got)
+	}
+}
+
+func TestServer_RefreshKeepsLastGoodResult(t *testing.T) {
+	d := &fakeDiscoverer{results: []discovery.Result{statuses(map[string]tmux.Status{"@1": tmux.StatusIdle})}}
+	s := NewServer(d, nil, time.Second)
+	s.Refresh()
+
+	d.err = errors.New("tmux hung")
+	s.Refresh()
+
+	snap := s.Snapshot()
+	if snap.Err != "tmux hung" {
+		t.Fatalf("Err = %q, want the discovery error", snap.Err)
+	}
+	if snap.Result.WindowStatuses["@1"] != tmux.StatusIdle {
+		t.Fatalf("Result = %+v, want last good result", snap.Result)
+	}
+}
+
+func TestServeAndFetch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "d.sock")
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := NewServer(&fakeDiscoverer{results: []discovery.Result{statuses(map[string]tmux.Status{"@1": tmux.StatusWaiting})}}, nil, time.Hour)
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, ln) }()
+
+	snap, err := Fetch(path, time.Second)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if snap.Result.WindowStatuses["@1"] != tmux.StatusWaiting {
+		t.Fatalf("snapshot = %+v", snap.Result)
+	}
+
+	if _, err := Listen(path); !errors.Is(err, ErrRunning) {
+		t.Fatalf("second Listen() error = %v, want ErrRunning", err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+}
+
+func TestListen_ReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "d.sock")
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	_ = ln.Close()
+}
+
+func TestFetch_NoDaemon(t *testing.T) {
+	if _, err := Fetch(filepath.Join(t.TempDir(), "missing.sock"), 100*time.Millisecond); err == nil {
+		t.Fatal("Fetch() should fail without a daemon")
+	}
+}
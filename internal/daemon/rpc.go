@@ -0,0 +1,316 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// The RPC protocol is newline-delimited JSON over the daemon's unix socket.
+// Each Request gets one Response with the same ID, except subscribe, which
+// answers with a Response carrying a Snapshot after every refresh until the
+// client disconnects. A connection may carry any number of requests, but a
+// subscribe takes it over.
+const (
+	// MethodSnapshot returns the current Snapshot.
+	MethodSnapshot = "snapshot"
+	// MethodSubscribe streams a Snapshot after every refresh.
+	MethodSubscribe = "subscribe"
+	// MethodKill kills a tmux session (KillParams).
+	MethodKill = "kill"
+	// MethodCreate creates a tmux session pinned to a directory (CreateParams).
+	MethodCreate = "create"
+	// MethodSend types text into a window and presses Enter (SendParams).
+	MethodSend = "send"
+)
+
+// Request is one RPC call. Params holds the method's parameter object.
+type Request struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Response answers the Request with the same ID. Exactly one of Result and
+// Error is set; Result is null for methods without a result.
+type Response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// KillParams are the parameters of MethodKill.
+type KillParams struct {
+	Session string `json:"session"`
+}
+
+// CreateParams are the parameters of MethodCreate.
+type CreateParams struct {
+	Session string `json:"session"`
+	Dir     string `json:"dir"`
+}
+
+// SendParams are the parameters of MethodSend. Target is a window target as
+// returned by tmux.Window.Target.
+type SendParams struct {
+	Target string `json:"target"`
+	Text   string `json:"text"`
+}
+
+// Actions performs the tmux side effects requested over RPC.
+type Actions interface {
+	KillSession(name string) error
+	CreateSession(name, workdir string) error
+	SetSessionOption(session, key, value string) error
+	SendText(target, text string) error
+}
+
+// handle answers requests on conn until the client hangs up.
+func (s *Server) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	enc := json.NewEncoder(conn)
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			if writeResponse(enc, Response{Error: fmt.Sprintf("invalid request: %v", err)}) != nil {
+				return
+			}
+			continue
+		}
+		if req.Method == MethodSubscribe {
+			s.stream(enc, req.ID)
+			return
+		}
+		result, err := s.call(req)
+		resp := Response{ID: req.ID, Result: result}
+		if err != nil {
+			resp = Response{ID: req.ID, Error: err.Error()}
+		}
+		if writeResponse(enc, resp) != nil {
+			return
+		}
+	}
+}
+
+// call dispatches a non-streaming request.
+func (s *Server) call(req Request) (json.RawMessage, error) {
+	switch req.Method {
+	case MethodSnapshot:
+		return json.Marshal(s.Snapshot())
+	case MethodKill:
+		var p KillParams
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		if p.Session == "" {
+			return nil, errors.New("kill: session is required")
+		}
+		if err := s.actions.KillSession(p.Session); err != nil {
+			return nil, err
+		}
+		s.Refresh()
+		return nil, nil
+	case MethodCreate:
+		var p CreateParams
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		if p.Session == "" || p.Dir == "" {
+			return nil, errors.New("create: session and dir are required")
+		}
+		if err := s.actions.CreateSession(p.Session, p.Dir); err != nil {
+			return nil, err
+		}
+		if err := s.actions.SetSessionOption(p.Session, tmux.SessionOptionHomePath, p.Dir); err != nil {
+			return nil, err
+		}
+		s.Refresh()
+		return nil, nil
+	case MethodSend:
+		var p SendParams
+		if err := decodeParams(req.Params, &p); err != nil {
+			return nil, err
+		}
+		if p.Target == "" {
+			return nil, errors.New("send: target is required")
+		}
+		return nil, s.actions.SendText(p.Target, p.Text)
+	default:
+		return nil, fmt.Errorf("unknown method %q", req.Method)
+	}
+}
+
+// stream writes the current snapshot and then every new one until the client
+// goes away.
+func (s *Server) stream(enc *json.Encoder, id int) {
+	updates, unsubscribe := s.subscribe()
+	defer unsubscribe()
+
+	snap := s.Snapshot()
+	for {
+		result, err := json.Marshal(snap)
+		if err != nil {
+			slog.Debug("daemon: failed to encode snapshot", "err", err)
+			return
+		}
+		if writeResponse(enc, Response{ID: id, Result: result}) != nil {
+			return
+		}
+		snap = <-updates
+	}
+}
+
+func decodeParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		return errors.New("missing params")
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	return nil
+}
+
+func writeResponse(enc *json.Encoder, resp Response) error {
+	if err := enc.Encode(resp); err != nil {
+		slog.Debug("daemon: failed to write response", "err", err)
+		return err
+	}
+	return nil
+}
+
+// Client is an RPC connection to a running daemon. Calls are serialized.
+type Client struct {
+	conn    net.Conn
+	enc     *json.Encoder
+	dec     *json.Decoder
+	timeout time.Duration
+
+	mu     sync.Mutex
+	nextID int
+}
+
+// Dial connects to the daemon listening at path. timeout bounds the connect
+// and each subsequent call.
+func Dial(path string, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("unix", path, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to daemon at %s: %w", path, err)
+	}
+	return &Client{conn: conn, enc: json.NewEncoder(conn), dec: json.NewDecoder(conn), timeout: timeout}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call sends method with params (nil for none) and decodes the result into
+// result (nil to discard it).
+func (c *Client) Call(method string, params, result any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	req := Request{ID: c.nextID, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+		req.Params = raw
+	}
+	if c.timeout > 0 {
+		_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+	if err := c.enc.Encode(req); err != nil {
+		return fmt.Errorf("failed to send daemon request: %w", err)
+	}
+	var resp Response
+	if err := c.dec.Decode(&resp); err != nil {
+		return fmt.Errorf("failed to read daemon response: %w", err)
+	}
+	if resp.ID != req.ID {
+		return fmt.Errorf("daemon answered request %d, want %d", resp.ID, req.ID)
+	}
+	if resp.Error != "" {
+		return fmt.Errorf("daemon %s: %s", method, resp.Error)
+	}
+	if result != nil && len(resp.Result) > 0 {
+		if err := json.Unmarshal(resp.Result, result); err != nil {
+			return fmt.Errorf("failed to decode %s result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// Snapshot returns the daemon's current snapshot.
+func (c *Client) Snapshot() (Snapshot, error) {
+	var snap Snapshot
+	err := c.Call(MethodSnapshot, nil, &snap)
+	return snap, err
+}
+
+// Kill kills session.
+func (c *Client) Kill(session string) error {
+	return c.Call(MethodKill, KillParams{Session: session}, nil)
+}
+
+// Create creates session in dir and pins it there.
+func (c *Client) Create(session, dir string) error {
+	return c.Call(MethodCreate, CreateParams{Session: session, Dir: dir}, nil)
+}
+
+// Send types text into the window at target and presses Enter.
+func (c *Client) Send(target, text string) error {
+	return c.Call(MethodSend, SendParams{Target: target, Text: text}, nil)
+}
+
+// Subscribe streams snapshots to fn, starting with the current one, until fn
+// returns false or the connection fails. The connection cannot be used for
+// other calls afterwards.
+func (c *Client) Subscribe(fn func(Snapshot) bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	_ = c.conn.SetDeadline(time.Time{})
+	if err := c.enc.Encode(Request{ID: c.nextID, Method: MethodSubscribe}); err != nil {
+		return fmt.Errorf("failed to send daemon request: %w", err)
+	}
+	for {
+		var resp Response
+		if err := c.dec.Decode(&resp); err != nil {
+			return fmt.Errorf("daemon subscription ended: %w", err)
+		}
+		if resp.Error != "" {
+			return fmt.Errorf("daemon subscribe: %s", resp.Error)
+		}
+		var snap Snapshot
+		if err := json.Unmarshal(resp.Result, &snap); err != nil {
+			return fmt.Errorf("failed to decode snapshot: %w", err)
+		}
+		if !fn(snap) {
+			return nil
+		}
+	}
+}
+
+// Fetch asks the daemon listening at path for its snapshot over a one-off
+// connection.
+func Fetch(path string, timeout time.Duration) (Snapshot, error) {
+	c, err := Dial(path, timeout)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer func() { _ = c.Close() }()
+	return c.Snapshot()
+}
@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeActions struct {
+	calls chan string
+}
+
+func (f *fakeActions) KillSession(name string) error {
+	f.calls <- "kill " + name
+	return nil
+}
+
+func (f *fakeActions) CreateSession(name, workdir string) error {
+	f.calls <- "create " + name + " " + workdir
+	return nil
+}
+
+func (f *fakeActions) SetSessionOption(session, key, value string) error {
+	f.calls <- "option " + session + " " + key + " " + value
+	return nil
+}
+
+func (f *fakeActions) SendText(target, text string) error {
+	f.calls <- "send " + target + " " + text
+	return nil
+}
+
+// startServer serves s on a temp socket until the test ends.
+func startServer(t *testing.T, s *Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "d.sock")
+	ln, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = s.Serve(ctx, ln)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return path
+}
+
+func TestClient_Actions(t *testing.T) {
+	actions := &fakeActions{calls: make(chan string, 10)}
+	d := &fakeDiscoverer{results: []discovery.Result{statuses(map[string]tmux.Status{"@1": tmux.StatusIdle})}}
+	path := startServer(t, NewServer(d, actions, time.Hour))
+
+	c, err := Dial(path, time.Second)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	if err := c.Create("cb_feat", "/wt"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := c.Send("@1", "run the tests"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := c.Kill("cb_feat"); err != nil {
+		t.Fatalf("Kill() error = %v", err)
+	}
+	close(actions.calls)
+
+	var got []string
+	for call := range actions.calls {
+		got = append(got, call)
+	}
+	want := []string{
+		"create cb_feat /wt",
+		"option cb_feat " + tmux.SessionOptionHomePath + " /wt",
+		"send @1 run the tests",
+		"kill cb_feat",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+
+	if err := c.Kill(""); err == nil || !strings.Contains(err.Error(), "session is required") {
+		t.Fatalf("Kill(\"\") error = %v, want validation error", err)
+	}
+	if err := c.Call("bogus", nil, nil); err == nil || !strings.Contains(err.Error(), "unknown method") {
+		t.Fatalf("Call(bogus) error = %v, want unknown method", err)
+	}
+}
+
+func TestClient_Subscribe(t *testing.T) {
+	d := &fakeDiscoverer{results: []discovery.Result{
+		statuses(map[string]tmux.Status{"@1": tmux.StatusWorking}),
+		statuses(map[string]tmux.Status{"@1": tmux.StatusWaiting}),
+	}}
+	s := NewServer(d, nil, time.Hour)
+	path := startServer(t, s)
+
+	c, err := Dial(path, time.Second)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = c.Close() }()
+
+	var seen []tmux.Status
+	err = c.Subscribe(func(snap Snapshot) bool {
+		seen = append(seen, snap.Result.WindowStatuses["@1"])
+		if len(seen) == 1 {
+			go s.Refresh()
+		}
+		return len(seen) < 2
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if !reflect.DeepEqual(seen, []tmux.Status{tmux.StatusWorking, tmux.StatusWaiting}) {
+		t.Fatalf("seen = %v", seen)
+	}
+}
+
+func TestServer_RejectsMalformedRequest(t *testing.T) {
+	path := startServer(t, NewServer(&fakeDiscoverer{results: []discovery.Result{{}}}, nil, time.Hour))
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.Write([]byte("not json\n")); err != nil {
+		t.Fatal(err)
+	}
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !strings.HasPrefix(resp.Error, "invalid request") {
+		t.Fatalf("response = %+v, want invalid request error", resp)
+	}
+}
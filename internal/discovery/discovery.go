@@ -1,25 +1,32 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ronsanzone/clawd-bay/internal/config"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/logging"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 )
 
 const mainRepoLabel = "(main repo)"
 
+const missingWorktreeLabel = "(missing worktree)"
+
 // TmuxInspector is the tmux surface needed for scoped project discovery.
 type TmuxInspector interface {
 	ListSessions() ([]tmux.Session, error)
 	ListWindows(session string) ([]tmux.Window, error)
 	GetPaneWorkingDir(session string) string
 	GetSessionOption(session, key string) (string, error)
-	DetectAgentInfo(session, window string) tmux.AgentInfo
+	DetectAgentInfo(target string) tmux.AgentInfo
 }
 
 // ProjectNode is one configured project and its worktrees.
@@ -28,52 +35,175 @@ type ProjectNode struct {
 	Path         string
 	Worktrees    []WorktreeNode
 	InvalidError string
+	// StaleWorktrees lists worktrees git still tracks whose directories were
+	// deleted outside cb, as `git worktree prune` would remove them. Names
+	// are relative to the project when inside it.
+	StaleWorktrees []string
 }
 
 // WorktreeNode represents a discovered worktree path (or main repo synthetic node).
+// IsMissing marks the synthetic "(missing worktree)" node holding sessions
+// pinned to a worktree of the project that no longer exists (removed or
+// pruned); it has no Path.
 type WorktreeNode struct {
-	Name       string
-	Path       string
+	Name string
+	Path string
+	// Branch is the branch checked out in a linked worktree, empty when
+	// detached or unknown.
+	Branch     string
 	IsMainRepo bool
+	IsMissing  bool
 	Sessions   []SessionNode
+	// DiffStat is the worktree's change summary against the main repo's
+	// checked-out branch. Nil for the main repo or when it cannot be computed.
+	DiffStat *git.DiffStat
+	// ChangedFiles lists paths changed against the same base as DiffStat.
+	ChangedFiles []string
+	// Overlaps lists other active worktrees in the project that changed
+	// any of the same files. Only populated for worktrees with sessions.
+	Overlaps []FileOverlap
+}
+
+// FileOverlap records files a worktree shares with another active worktree.
+type FileOverlap struct {
+	Worktree string
+	Files    []string
 }
 
-// SessionNode is a tmux session attached to a discovered worktree.
+// SessionNode is a tmux session attached to a discovered worktree. Note and
+// Tags are the session's cb note and cb tags, if any. LimitReset is the
+// reset time of a LIMITED agent in the session and WaitingReason the most
+// urgent reason among its WAITING agents (see tmux.AgentInfo). Branch,
+// Agent, and CreatedAt are recorded by cb when it creates the session; they
+// are empty (zero) for sessions it did not create.
 type SessionNode struct {
-	Name    string
-	Status  tmux.Status
-	Windows []tmux.Window
+	Name          string
+	Status        tmux.Status
+	LimitReset    string
+	WaitingReason tmux.WaitingReason
+	Windows       []tmux.Window
+	Note          string
+	Tags          []string
+	Branch        string
+	Agent         tmux.AgentType
+	CreatedAt     time.Time
 }
 
-// Result is the shared discovery output for dash/list.
+// Result is the shared discovery output for dash/list. Window maps are keyed
+// by tmux.Window.Target; WindowStatuses only holds windows with a detected
+// agent, WindowAgents holds tmux.AgentNone for windows checked without one,
+// and WindowWaitingReasons only holds WAITING windows.
 type Result struct {
-	Projects       []ProjectNode
-	WindowStatuses map[string]tmux.Status
-	WindowAgents   map[string]tmux.AgentType
-	ConfigMissing  bool
+	Projects             []ProjectNode
+	WindowStatuses       map[string]tmux.Status
+	WindowAgents         map[string]tmux.AgentType
+	WindowWaitingReasons map[string]tmux.WaitingReason
+	ConfigMissing        bool
+}
+
+// refreshScoper is implemented by tmux clients that can memoize pane captures
+// for one discovery pass (see tmux.Client.ForRefresh).
+type refreshScoper interface {
+	ForRefresh() *tmux.Client
+}
+
+// contextScoper is implemented by tmux clients whose commands can be bound to
+// a context (see tmux.Client.WithContext).
+type contextScoper interface {
+	WithContext(ctx context.Context) *tmux.Client
+}
+
+// inspector returns c as a TmuxInspector, keeping a nil client a literal nil
+// interface so the nil checks in discover still see it.
+func inspector(c *tmux.Client) TmuxInspector {
+	if c == nil {
+		return nil
+	}
+	return c
+}
+
+// ContextDiscoverer is implemented by discoverers that can bound a single
+// discovery by a context, killing its commands once the context is done.
+type ContextDiscoverer interface {
+	DiscoverContext(ctx context.Context) (Result, error)
+}
+
+// DiscoverWithContext discovers with d, bounded by ctx when d implements
+// ContextDiscoverer.
+func DiscoverWithContext(ctx context.Context, d interface{ Discover() (Result, error) }) (Result, error) {
+	if cd, ok := d.(ContextDiscoverer); ok {
+		return cd.DiscoverContext(ctx)
+	}
+	return d.Discover()
+}
+
+// sessionOptionLister is implemented by tmux clients that can read the
+// options of every session in one call (see tmux.Client.ListSessionOptions).
+type sessionOptionLister interface {
+	ListSessionOptions() (map[string]map[string]string, error)
 }
 
 // Service discovers configured project/worktree/session hierarchy.
 type Service struct {
 	tmuxClient TmuxInspector
-	execCmd    func(name string, args ...string) ([]byte, error)
+	// ctx kills git commands once done; nil when execCmd is a test fake.
+	ctx     context.Context
+	execCmd func(name string, args ...string) ([]byte, error)
+	// sessionOptions holds the options of every session for one discovery
+	// pass when the tmux client lists them at once; nil otherwise.
+	sessionOptions map[string]map[string]string
 }
 
 // NewService creates a discovery service.
 func NewService(tmuxClient TmuxInspector) *Service {
+	return NewServiceWithContext(context.Background(), tmuxClient)
+}
+
+// NewServiceWithContext creates a discovery service whose git commands are
+// killed once ctx is done.
+func NewServiceWithContext(ctx context.Context, tmuxClient TmuxInspector) *Service {
 	return &Service{
 		tmuxClient: tmuxClient,
-		execCmd: func(name string, args ...string) ([]byte, error) {
-			return exec.Command(name, args...).Output()
-		},
+		ctx:        ctx,
+		execCmd:    commandRunner(ctx),
+	}
+}
+
+func commandRunner(ctx context.Context) func(name string, args ...string) ([]byte, error) {
+	return func(name string, args ...string) ([]byte, error) {
+		return logging.Output(exec.CommandContext(ctx, name, args...))
 	}
 }
 
+// DiscoverContext is Discover with its git and tmux commands killed once ctx
+// is done, rather than when the service's own context is.
+func (s *Service) DiscoverContext(ctx context.Context) (Result, error) {
+	pass := *s
+	if s.ctx != nil {
+		pass.ctx, pass.execCmd = ctx, commandRunner(ctx)
+	}
+	if scoper, ok := s.tmuxClient.(contextScoper); ok {
+		pass.tmuxClient = inspector(scoper.WithContext(ctx))
+	}
+	return pass.Discover()
+}
+
 // Discover builds project/worktree hierarchy and overlays tmux runtime state.
+// Each pass captures a pane at most once, and reads the options of all
+// sessions in one call, when the tmux client supports it.
 func (s *Service) Discover() (Result, error) {
+	pass := *s
+	if scoper, ok := s.tmuxClient.(refreshScoper); ok {
+		pass.tmuxClient = inspector(scoper.ForRefresh())
+	}
+	return pass.discover()
+}
+
+func (s *Service) discover() (Result, error) {
 	result := Result{
-		WindowStatuses: make(map[string]tmux.Status),
-		WindowAgents:   make(map[string]tmux.AgentType),
+		WindowStatuses:       make(map[string]tmux.Status),
+		WindowAgents:         make(map[string]tmux.AgentType),
+		WindowWaitingReasons: make(map[string]tmux.WaitingReason),
 	}
 
 	cfg, exists, err := config.LoadUserConfigWithMeta()
@@ -103,14 +233,18 @@ func (s *Service) Discover() (Result, error) {
 		}
 
 		node.Path = canonicalProjectPath
-		worktrees, worktreeErr := s.discoverWorktrees(canonicalProjectPath)
+		worktrees, excluded, stale, worktreeErr := s.discoverWorktrees(canonicalProjectPath, p)
+		node.StaleWorktrees = stale
 		if worktreeErr != nil {
 			node.InvalidError = worktreeErr.Error()
+		} else {
+			s.annotateDiffStats(canonicalProjectPath, worktrees)
 		}
 		node.Worktrees = worktrees
 		runtimeProjects = append(runtimeProjects, runtimeProject{
-			canonicalPath: canonicalProjectPath,
-			node:          node,
+			canonicalPath:     canonicalProjectPath,
+			node:              node,
+			excludedWorktrees: excluded,
 		})
 	}
 
@@ -122,13 +256,14 @@ func (s *Service) Discover() (Result, error) {
 	})
 
 	if s.tmuxClient != nil {
-		if err := s.overlaySessions(runtimeProjects, &result); err != nil {
+		if err := s.overlaySessions(runtimeProjects, cfg, &result); err != nil {
 			return Result{}, err
 		}
 	}
 
 	result.Projects = make([]ProjectNode, 0, len(runtimeProjects))
 	for _, rp := range runtimeProjects {
+		annotateOverlaps(rp.node.Worktrees)
 		for wi := range rp.node.Worktrees {
 			sort.SliceStable(rp.node.Worktrees[wi].Sessions, func(i, j int) bool {
 				return rp.node.Worktrees[wi].Sessions[i].Name < rp.node.Worktrees[wi].Sessions[j].Name
@@ -143,23 +278,37 @@ func (s *Service) Discover() (Result, error) {
 type runtimeProject struct {
 	canonicalPath string
 	node          ProjectNode
+	// excludedWorktrees are worktree paths hidden by exclude_worktrees; their
+	// sessions are hidden too rather than falling back to the main repo.
+	excludedWorktrees []string
 }
 
-func (s *Service) discoverWorktrees(projectPath string) ([]WorktreeNode, error) {
+// discoverWorktrees lists the project's worktrees, returning excluded
+// worktree paths and the names of stale entries separately.
+func (s *Service) discoverWorktrees(projectPath string, project config.ProjectConfig) (worktrees []WorktreeNode, excluded []string, stale []string, err error) {
 	main := WorktreeNode{Name: mainRepoLabel, Path: projectPath, IsMainRepo: true}
 
 	if s.execCmd == nil {
-		return []WorktreeNode{main}, nil
+		return []WorktreeNode{main}, nil, nil, nil
 	}
 
 	output, err := s.execCmd("git", "-C", projectPath, "worktree", "list", "--porcelain")
 	if err != nil {
-		return []WorktreeNode{main}, fmt.Errorf("failed to list worktrees for %s: %w", projectPath, err)
+		return []WorktreeNode{main}, nil, nil, fmt.Errorf("failed to list worktrees for %s: %w", projectPath, err)
+	}
+
+	for _, rawPath := range parseWorktreePrunable(string(output)) {
+		if cleaned := filepath.Clean(rawPath); isPathWithin(cleaned, projectPath) {
+			rawPath = relativeWorktreeName(projectPath, cleaned)
+		}
+		stale = append(stale, rawPath)
 	}
 
 	seen := map[string]struct{}{projectPath: {}}
+	branches := make(map[string]string)
 	worktreesRoot := filepath.Join(projectPath, ".worktrees")
 
+	rawBranches := parseWorktreeBranches(string(output))
 	for _, rawPath := range ParseWorktreeListPorcelain(string(output)) {
 		canonicalPath, canonicalErr := config.CanonicalPath(rawPath)
 		if canonicalErr != nil {
@@ -167,6 +316,7 @@ func (s *Service) discoverWorktrees(projectPath string) ([]WorktreeNode, error)
 		}
 		if canonicalPath == projectPath || isPathWithin(canonicalPath, worktreesRoot) {
 			seen[canonicalPath] = struct{}{}
+			branches[canonicalPath] = rawBranches[rawPath]
 		}
 	}
 
@@ -187,25 +337,109 @@ func (s *Service) discoverWorktrees(projectPath string) ([]WorktreeNode, error)
 		return paths[i] < paths[j]
 	})
 
-	result := []WorktreeNode{main}
+	worktrees = []WorktreeNode{main}
 	for _, wtPath := range paths {
-		result = append(result, WorktreeNode{
-			Name:       relativeWorktreeName(projectPath, wtPath),
+		name := relativeWorktreeName(projectPath, wtPath)
+		if project.ExcludesWorktree(name) {
+			excluded = append(excluded, wtPath)
+			continue
+		}
+		worktrees = append(worktrees, WorktreeNode{
+			Name:       name,
 			Path:       wtPath,
+			Branch:     branches[wtPath],
 			IsMainRepo: false,
 		})
 	}
 
-	return result, nil
+	return worktrees, excluded, stale, nil
+}
+
+// annotateDiffStats computes each linked worktree's diff stat and changed
+// files against the branch checked out in the main repo. Failures leave
+// DiffStat nil.
+func (s *Service) annotateDiffStats(projectPath string, worktrees []WorktreeNode) {
+	if s.execCmd == nil || len(worktrees) < 2 {
+		return
+	}
+
+	output, err := s.execCmd("git", "-C", projectPath, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return
+	}
+	base := strings.TrimSpace(string(output))
+	if base == "" || base == "HEAD" {
+		return
+	}
+
+	for i := range worktrees {
+		if worktrees[i].IsMainRepo {
+			continue
+		}
+		output, err := s.execCmd("git", "-C", worktrees[i].Path, "diff", "--numstat", "--merge-base", base)
+		if err != nil {
+			continue
+		}
+		stat, files := git.ParseNumStat(string(output))
+		worktrees[i].DiffStat = &stat
+		worktrees[i].ChangedFiles = files
+	}
 }
 
-func (s *Service) overlaySessions(projects []runtimeProject, result *Result) error {
+// annotateOverlaps records, for every active linked worktree, which other
+// active worktrees modified overlapping files relative to the base branch.
+func annotateOverlaps(worktrees []WorktreeNode) {
+	for i := range worktrees {
+		if !isActiveLinkedWorktree(worktrees[i]) {
+			continue
+		}
+		mine := make(map[string]struct{}, len(worktrees[i].ChangedFiles))
+		for _, f := range worktrees[i].ChangedFiles {
+			mine[f] = struct{}{}
+		}
+
+		for j := range worktrees {
+			if i == j || !isActiveLinkedWorktree(worktrees[j]) {
+				continue
+			}
+			var shared []string
+			for _, f := range worktrees[j].ChangedFiles {
+				if _, ok := mine[f]; ok {
+					shared = append(shared, f)
+				}
+			}
+			if len(shared) == 0 {
+				continue
+			}
+			sort.Strings(shared)
+			worktrees[i].Overlaps = append(worktrees[i].Overlaps, FileOverlap{
+				Worktree: worktrees[j].Name,
+				Files:    shared,
+			})
+		}
+	}
+}
+
+func isActiveLinkedWorktree(wt WorktreeNode) bool {
+	return !wt.IsMainRepo && len(wt.Sessions) > 0 && len(wt.ChangedFiles) > 0
+}
+
+func (s *Service) overlaySessions(projects []runtimeProject, cfg config.UserConfig, result *Result) error {
 	sessions, err := s.tmuxClient.ListSessions()
 	if err != nil {
 		return fmt.Errorf("failed to list tmux sessions: %w", err)
 	}
+	if lister, ok := s.tmuxClient.(sessionOptionLister); ok {
+		// On failure sessionOption falls back to reading each option.
+		if all, err := lister.ListSessionOptions(); err == nil {
+			s.sessionOptions = all
+		}
+	}
 
 	for _, session := range sessions {
+		if cfg.IgnoresSession(session.Name) {
+			continue
+		}
 		projectIndex, worktreeIndex := s.sessionPlacement(projects, session.Name)
 		if projectIndex < 0 || worktreeIndex < 0 {
 			continue
@@ -220,21 +454,42 @@ func (s *Service) overlaySessions(projects []runtimeProject, result *Result) err
 		})
 
 		windowStatuses := make([]tmux.Status, 0, len(windows))
+		limitReset := ""
+		var waitingReason tmux.WaitingReason
 		for _, w := range windows {
-			key := session.Name + ":" + w.Name
-			info := s.tmuxClient.DetectAgentInfo(session.Name, w.Name)
+			key := w.Target(session.Name)
+			info := s.tmuxClient.DetectAgentInfo(key)
+			if !info.Detected {
+				result.WindowAgents[key] = tmux.AgentNone
+			}
 			if info.Detected {
 				result.WindowStatuses[key] = info.Status
 				result.WindowAgents[key] = info.Type
 				windowStatuses = append(windowStatuses, info.Status)
+				if limitReset == "" {
+					limitReset = info.LimitReset
+				}
+				if info.WaitingReason != "" {
+					result.WindowWaitingReasons[key] = info.WaitingReason
+					if info.WaitingReason.Urgency() > waitingReason.Urgency() {
+						waitingReason = info.WaitingReason
+					}
+				}
 			}
 		}
 		projects[projectIndex].node.Worktrees[worktreeIndex].Sessions = append(
 			projects[projectIndex].node.Worktrees[worktreeIndex].Sessions,
 			SessionNode{
-				Name:    session.Name,
-				Status:  rollupStatuses(windowStatuses),
-				Windows: windows,
+				Name:          session.Name,
+				Status:        tmux.RollupStatus(windowStatuses),
+				LimitReset:    limitReset,
+				WaitingReason: waitingReason,
+				Windows:       windows,
+				Note:          s.sessionOption(session.Name, tmux.SessionOptionNote),
+				Tags:          tmux.ParseTags(s.sessionOption(session.Name, tmux.SessionOptionTags)),
+				Branch:        s.sessionOption(session.Name, tmux.SessionOptionBranch),
+				Agent:         tmux.AgentType(s.sessionOption(session.Name, tmux.SessionOptionAgent)),
+				CreatedAt:     parseUnixTime(s.sessionOption(session.Name, tmux.SessionOptionCreatedAt)),
 			},
 		)
 	}
@@ -250,6 +505,17 @@ func (s *Service) sessionPlacement(projects []runtimeProject, sessionName string
 
 	// Unpinned/invalid pinned sessions are owned by pane cwd, but always grouped
 	// under the project's synthetic "(main repo)" node.
+	projectIndex, worktreeIndex = s.sessionPlacementFromPane(projects, sessionName)
+	if projectIndex >= 0 && worktreeIndex >= 0 {
+		return projectIndex, worktreeIndex
+	}
+
+	// The panes wandered outside every project; the project cb recorded at
+	// creation still knows where the session belongs.
+	return s.sessionPlacementFromProjectOption(projects, sessionName)
+}
+
+func (s *Service) sessionPlacementFromPane(projects []runtimeProject, sessionName string) (projectIndex, worktreeIndex int) {
 	panePath := s.tmuxClient.GetPaneWorkingDir(sessionName)
 	if panePath == "" {
 		return -1, -1
@@ -266,29 +532,107 @@ func (s *Service) sessionPlacement(projects []runtimeProject, sessionName string
 	return projectIndex, mainRepoWorktreeIndex(projects[projectIndex].node.Worktrees)
 }
 
-func (s *Service) sessionPlacementFromPinnedHome(projects []runtimeProject, sessionName string) (projectIndex, worktreeIndex int) {
-	homePath, err := s.tmuxClient.GetSessionOption(sessionName, tmux.SessionOptionHomePath)
-	if err != nil || strings.TrimSpace(homePath) == "" {
+// sessionPlacementFromProjectOption places a session under the main repo
+// node of the project named by its @cb_project option, unless its home path
+// is an excluded worktree of that project.
+func (s *Service) sessionPlacementFromProjectOption(projects []runtimeProject, sessionName string) (projectIndex, worktreeIndex int) {
+	name := s.sessionOption(sessionName, tmux.SessionOptionProject)
+	if name == "" {
 		return -1, -1
 	}
+	for i := range projects {
+		if projects[i].node.Name != name {
+			continue
+		}
+		if home := s.sessionOption(sessionName, tmux.SessionOptionHomePath); home != "" {
+			for _, excluded := range projects[i].excludedWorktrees {
+				if IsPathWithinOrEqual(home, excluded) {
+					return -1, -1
+				}
+			}
+		}
+		worktreeIndex = mainRepoWorktreeIndex(projects[i].node.Worktrees)
+		if worktreeIndex < 0 {
+			return -1, -1
+		}
+		return i, worktreeIndex
+	}
+	return -1, -1
+}
 
-	canonicalHomePath, err := config.CanonicalPath(homePath)
+// sessionOption returns the session's option key, or "" when it is unset.
+func (s *Service) sessionOption(sessionName, key string) string {
+	if s.sessionOptions != nil {
+		return s.sessionOptions[sessionName][key]
+	}
+	value, err := s.tmuxClient.GetSessionOption(sessionName, key)
 	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// parseUnixTime parses a unix time option value; malformed or empty values
+// give the zero time.
+func parseUnixTime(value string) time.Time {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+func (s *Service) sessionPlacementFromPinnedHome(projects []runtimeProject, sessionName string) (projectIndex, worktreeIndex int) {
+	homePath := s.sessionOption(sessionName, tmux.SessionOptionHomePath)
+	if strings.TrimSpace(homePath) == "" {
 		return -1, -1
 	}
 
+	canonicalHomePath, err := config.CanonicalPath(homePath)
+	missing := err != nil
+	if missing {
+		// A removed worktree no longer resolves; cb pins canonical paths, so
+		// the recorded one still matches its project.
+		canonicalHomePath = filepath.Clean(homePath)
+	}
+
 	projectIndex = bestProjectMatch(projects, canonicalHomePath)
 	if projectIndex < 0 {
 		return -1, -1
 	}
+	project := &projects[projectIndex]
+	for _, excluded := range project.excludedWorktrees {
+		if IsPathWithinOrEqual(canonicalHomePath, excluded) {
+			return -1, -1
+		}
+	}
 
-	worktreeIndex = bestWorktreeMatch(projects[projectIndex].node.Worktrees, canonicalHomePath)
+	worktreeIndex = bestWorktreeMatch(project.node.Worktrees, canonicalHomePath)
 	if worktreeIndex < 0 {
 		return -1, -1
 	}
+	// Only the main repo contains a home path under no linked worktree. If
+	// the path is gone or under .worktrees/, its worktree was removed rather
+	// than the session living in the main repo.
+	if project.node.Worktrees[worktreeIndex].IsMainRepo && canonicalHomePath != project.canonicalPath &&
+		(missing || isPathWithin(canonicalHomePath, filepath.Join(project.canonicalPath, ".worktrees"))) {
+		return projectIndex, missingWorktreeIndex(&project.node)
+	}
 	return projectIndex, worktreeIndex
 }
 
+// missingWorktreeIndex returns the index of the project's missing-worktree
+// node, appending it on first use.
+func missingWorktreeIndex(project *ProjectNode) int {
+	for i := range project.Worktrees {
+		if project.Worktrees[i].IsMissing {
+			return i
+		}
+	}
+	project.Worktrees = append(project.Worktrees, WorktreeNode{Name: missingWorktreeLabel, IsMissing: true})
+	return len(project.Worktrees) - 1
+}
+
 func bestProjectMatch(projects []runtimeProject, path string) int {
 	best := -1
 	bestLen := -1
@@ -296,7 +640,7 @@ func bestProjectMatch(projects []runtimeProject, path string) int {
 		if p.canonicalPath == "" {
 			continue
 		}
-		if !isPathWithinOrEqual(path, p.canonicalPath) {
+		if !IsPathWithinOrEqual(path, p.canonicalPath) {
 			continue
 		}
 		if len(p.canonicalPath) > bestLen {
@@ -311,7 +655,7 @@ func bestWorktreeMatch(worktrees []WorktreeNode, path string) int {
 	best := -1
 	bestLen := -1
 	for i, wt := range worktrees {
-		if !isPathWithinOrEqual(path, wt.Path) {
+		if !IsPathWithinOrEqual(path, wt.Path) {
 			continue
 		}
 		if len(wt.Path) > bestLen {
@@ -343,10 +687,12 @@ func isPathWithin(path, root string) bool {
 	if path == root {
 		return false
 	}
-	return isPathWithinOrEqual(path, root)
+	return IsPathWithinOrEqual(path, root)
 }
 
-func isPathWithinOrEqual(path, root string) bool {
+// IsPathWithinOrEqual reports whether path is root or inside it, comparing
+// cleaned paths without resolving symlinks.
+func IsPathWithinOrEqual(path, root string) bool {
 	cleanPath := filepath.Clean(path)
 	cleanRoot := filepath.Clean(root)
 	if cleanPath == cleanRoot {
@@ -356,26 +702,49 @@ func isPathWithinOrEqual(path, root string) bool {
 	return strings.HasPrefix(cleanPath, prefix)
 }
 
-func rollupStatuses(statuses []tmux.Status) tmux.Status {
-	hasWaiting := false
-	hasIdle := false
-	for _, s := range statuses {
-		switch s {
-		case tmux.StatusWorking:
-			return tmux.StatusWorking
-		case tmux.StatusWaiting:
-			hasWaiting = true
-		case tmux.StatusIdle:
-			hasIdle = true
+// parseWorktreeBranches maps each worktree path in git worktree list
+// --porcelain output to its checked-out branch, without refs/heads/.
+// Detached worktrees are left out.
+func parseWorktreeBranches(output string) map[string]string {
+	branches := make(map[string]string)
+	var path string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = strings.TrimSpace(strings.TrimPrefix(line, "worktree "))
+		case strings.HasPrefix(line, "branch ") && path != "":
+			branches[path] = strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(line, "branch ")), "refs/heads/")
 		}
 	}
-	if hasWaiting {
-		return tmux.StatusWaiting
+	return branches
+}
+
+// parseWorktreePrunable returns the worktree paths in git worktree list
+// --porcelain output that git marks prunable: their directories are gone.
+func parseWorktreePrunable(output string) []string {
+	var prunable []string
+	var path string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = strings.TrimSpace(strings.TrimPrefix(line, "worktree "))
+		case (line == "prunable" || strings.HasPrefix(line, "prunable ")) && path != "":
+			prunable = append(prunable, path)
+		}
 	}
-	if hasIdle {
-		return tmux.StatusIdle
+	return prunable
+}
+
+// WorktreeLabel returns a worktree's display name: its name, followed by its
+// branch when the name does not already show it, as with names truncated by
+// worktree_name_max or rendered from {ticket}.
+func WorktreeLabel(name, branch string) string {
+	if branch == "" || strings.Contains(name, branch) || strings.Contains(name, strings.ReplaceAll(branch, "/", "-")) {
+		return name
 	}
-	return tmux.StatusDone
+	return name + " (" + branch + ")"
 }
 
 // ParseWorktreeListPorcelain parses `git worktree list --porcelain` output.
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
@@ -48,8 +50,8 @@ func (f fakeTmux) GetSessionOption(session, key string) (string, error) {
 	return "", errors.New("missing option")
 }
 
-func (f fakeTmux) DetectAgentInfo(session, window string) tmux.AgentInfo {
-	if info, ok := f.infos[session+":"+window]; ok {
+func (f fakeTmux) DetectAgentInfo(target string) tmux.AgentInfo {
+	if info, ok := f.infos[target]; ok {
 		return info
 	}
 	return tmux.AgentInfo{Type: tmux.AgentNone, Detected: false, Status: tmux.StatusDone}
@@ -73,6 +75,86 @@ branch refs/heads/feature/add-login`
 	}
 }
 
+func TestParseWorktreeBranches(t *testing.T) {
+	out := `worktree /tmp/repo
+HEAD abc
+branch refs/heads/main
+
+worktree /tmp/repo/.worktrees/repo-feature-ab12cd
+HEAD def
+branch refs/heads/feature/add-login
+
+worktree /tmp/repo/.worktrees/detached
+HEAD 123
+detached`
+
+	got := parseWorktreeBranches(out)
+	want := map[string]string{
+		"/tmp/repo": "main",
+		"/tmp/repo/.worktrees/repo-feature-ab12cd": "feature/add-login",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseWorktreeBranches() = %v, want %v", got, want)
+	}
+}
+
+func TestParseWorktreePrunable(t *testing.T) {
+	out := `worktree /tmp/repo
+HEAD abc
+branch refs/heads/main
+
+worktree /tmp/repo/.worktrees/repo-gone
+HEAD def
+branch refs/heads/gone
+prunable gitdir file points to non-existent location
+
+worktree /tmp/repo/.worktrees/repo-live
+HEAD 123
+branch refs/heads/live`
+
+	got := parseWorktreePrunable(out)
+	if want := []string{"/tmp/repo/.worktrees/repo-gone"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseWorktreePrunable() = %v, want %v", got, want)
+	}
+}
+
+func TestWorktreeLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		want   string
+	}{
+		{"repo-feature/add-login", "feature/add-login", "repo-feature/add-login"},
+		{"repo-feature-add-login", "feature/add-login", "repo-feature-add-login"},
+		{"repo-feature-a-63143a", "feature/a-very-long-branch-name", "repo-feature-a-63143a (feature/a-very-long-branch-name)"},
+		{"proj-123", "feature/PROJ-123-auth", "proj-123 (feature/PROJ-123-auth)"},
+		{"repo-detached", "", "repo-detached"},
+	}
+	for _, tt := range tests {
+		if got := WorktreeLabel(tt.name, tt.branch); got != tt.want {
+			t.Errorf("WorktreeLabel(%q, %q) = %q, want %q", tt.name, tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestPathWithinOrEqual(t *testing.T) {
+	tests := []struct {
+		path string
+		root string
+		want bool
+	}{
+		{"/src/repo/.worktrees/feat", "/src/repo/.worktrees/feat", true},
+		{"/src/repo/.worktrees/feat/pkg", "/src/repo/.worktrees/feat", true},
+		{"/src/repo/.worktrees/feature", "/src/repo/.worktrees/feat", false},
+		{"/src/repo", "/src/repo/.worktrees/feat", false},
+	}
+	for _, tt := range tests {
+		if got := IsPathWithinOrEqual(tt.path, tt.root); got != tt.want {
+			t.Errorf("IsPathWithinOrEqual(%q, %q) = %v, want %v", tt.path, tt.root, got, tt.want)
+		}
+	}
+}
+
 func TestDiscover_MainRepoAndLongestWorktreeMatch(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -110,15 +192,17 @@ func TestDiscover_MainRepoAndLongestWorktreeMatch(t *testing.T) {
 		},
 		options: map[string]string{
 			"cb_main|" + tmux.SessionOptionHomePath:   repo,
+			"cb_main|" + tmux.SessionOptionNote:       "waiting on review",
+			"cb_main|" + tmux.SessionOptionTags:       "urgent,backend",
 			"cb_nested|" + tmux.SessionOptionHomePath: wtNested,
 		},
 		windows: map[string][]tmux.Window{
-			"cb_main":   {{Index: 0, Name: "claude"}},
-			"cb_nested": {{Index: 0, Name: "claude"}},
+			"cb_main":   {{ID: "@1", Index: 0, Name: "claude"}},
+			"cb_nested": {{ID: "@2", Index: 0, Name: "claude"}},
 		},
 		infos: map[string]tmux.AgentInfo{
-			"cb_main:claude":   {Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusIdle},
-			"cb_nested:claude": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWorking},
+			"@1": {Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusIdle},
+			"@2": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWorking},
 		},
 	}
 
@@ -155,6 +239,12 @@ func TestDiscover_MainRepoAndLongestWorktreeMatch(t *testing.T) {
 	if len(project.Worktrees[0].Sessions) != 1 || project.Worktrees[0].Sessions[0].Name != "cb_main" {
 		t.Fatalf("main repo session mapping incorrect: %+v", project.Worktrees[0].Sessions)
 	}
+	if got := project.Worktrees[0].Sessions[0].Note; got != "waiting on review" {
+		t.Fatalf("cb_main note = %q, want %q", got, "waiting on review")
+	}
+	if got := strings.Join(project.Worktrees[0].Sessions[0].Tags, ","); got != "backend,urgent" {
+		t.Fatalf("cb_main tags = %q, want %q", got, "backend,urgent")
+	}
 
 	var nestedSessions []SessionNode
 	canonicalNestedPath, err := config.CanonicalPath(wtNested)
@@ -203,10 +293,10 @@ func TestDiscover_PinnedHomePlacementIgnoresPaneDrift(t *testing.T) {
 			"cb_stable|" + tmux.SessionOptionHomePath: wt,
 		},
 		windows: map[string][]tmux.Window{
-			"cb_stable": {{Index: 0, Name: "claude"}},
+			"cb_stable": {{ID: "@1", Index: 0, Name: "claude"}},
 		},
 		infos: map[string]tmux.AgentInfo{
-			"cb_stable:claude": {Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusIdle},
+			"@1": {Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusIdle},
 		},
 	}
 
@@ -238,6 +328,71 @@ func TestDiscover_PinnedHomePlacementIgnoresPaneDrift(t *testing.T) {
 	}
 }
 
+func TestDiscover_SessionsOfMissingWorktrees(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repo := filepath.Join(home, "repo")
+	pruned := filepath.Join(repo, ".worktrees", "repo-pruned")
+	for _, p := range []string{repo, pruned} {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", p, err)
+		}
+	}
+	canonicalRepo, err := config.CanonicalPath(repo)
+	if err != nil {
+		t.Fatalf("CanonicalPath() error = %v", err)
+	}
+	if err := config.SaveUserConfig(config.UserConfig{
+		Version:  config.SupportedConfigVersion,
+		Projects: []config.ProjectConfig{{Path: repo, Name: "repo"}},
+	}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+
+	f := fakeTmux{
+		sessions: []tmux.Session{{Name: "cb_main"}, {Name: "cb_pruned"}, {Name: "cb_removed"}},
+		options: map[string]string{
+			"cb_main|" + tmux.SessionOptionHomePath:    canonicalRepo,
+			"cb_pruned|" + tmux.SessionOptionHomePath:  pruned,
+			"cb_removed|" + tmux.SessionOptionHomePath: filepath.Join(canonicalRepo, ".worktrees", "repo-removed"),
+		},
+	}
+	svc := &Service{
+		tmuxClient: f,
+		execCmd: func(name string, args ...string) ([]byte, error) {
+			removed := filepath.Join(canonicalRepo, ".worktrees", "repo-removed")
+			return []byte("worktree " + repo + "\n\nworktree " + removed + "\nprunable gitdir file points to non-existent location\n"), nil
+		},
+	}
+
+	result, err := svc.Discover()
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	if got, want := result.Projects[0].StaleWorktrees, []string{".worktrees/repo-removed"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("StaleWorktrees = %v, want %v", got, want)
+	}
+	worktrees := result.Projects[0].Worktrees
+	if len(worktrees) != 2 || !worktrees[1].IsMissing || worktrees[1].Name != missingWorktreeLabel {
+		t.Fatalf("worktrees = %+v, want main repo and a missing-worktree node", worktrees)
+	}
+	if got := sessionNames(worktrees[0].Sessions); got != "cb_main" {
+		t.Fatalf("main repo sessions = %s, want cb_main", got)
+	}
+	if got := sessionNames(worktrees[1].Sessions); got != "cb_pruned,cb_removed" {
+		t.Fatalf("missing worktree sessions = %s, want cb_pruned,cb_removed", got)
+	}
+}
+
+func sessionNames(sessions []SessionNode) string {
+	names := make([]string, 0, len(sessions))
+	for _, s := range sessions {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, ",")
+}
+
 func TestDiscover_UnpinnedSessionFallsBackToMainRepo(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -267,10 +422,10 @@ func TestDiscover_UnpinnedSessionFallsBackToMainRepo(t *testing.T) {
 			"cb_untagged": wtPkg,
 		},
 		windows: map[string][]tmux.Window{
-			"cb_untagged": {{Index: 0, Name: "claude"}},
+			"cb_untagged": {{ID: "@1", Index: 0, Name: "claude"}},
 		},
 		infos: map[string]tmux.AgentInfo{
-			"cb_untagged:claude": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWorking},
+			"@1": {Type: tmux.AgentCodex, Detected: true, Status: tmux.StatusWorking},
 		},
 		optionErrs: map[string]error{
 			"cb_untagged|" + tmux.SessionOptionHomePath: errors.New("missing option"),
@@ -303,6 +458,123 @@ func TestDiscover_UnpinnedSessionFallsBackToMainRepo(t *testing.T) {
 	}
 }
 
+func TestDiscover_RecordedMetadataPlacesWanderedSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repo := filepath.Join(home, "repo")
+	elsewhere := filepath.Join(home, "elsewhere")
+	for _, p := range []string{repo, elsewhere} {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", p, err)
+		}
+	}
+	if err := config.SaveUserConfig(config.UserConfig{
+		Version:  config.SupportedConfigVersion,
+		Projects: []config.ProjectConfig{{Path: repo, Name: "web"}},
+	}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+
+	f := fakeTmux{
+		sessions: []tmux.Session{{Name: "cb_wandered"}, {Name: "cb_stray"}},
+		paths: map[string]string{
+			"cb_wandered": elsewhere,
+			"cb_stray":    elsewhere,
+		},
+		options: map[string]string{
+			"cb_wandered|" + tmux.SessionOptionProject:   "web",
+			"cb_wandered|" + tmux.SessionOptionBranch:    "feature/login",
+			"cb_wandered|" + tmux.SessionOptionAgent:     "codex",
+			"cb_wandered|" + tmux.SessionOptionCreatedAt: "1700000000",
+			"cb_stray|" + tmux.SessionOptionProject:      "gone",
+		},
+	}
+	svc := &Service{
+		tmuxClient: f,
+		execCmd: func(name string, args ...string) ([]byte, error) {
+			return []byte("worktree " + repo + "\n"), nil
+		},
+	}
+
+	result, err := svc.Discover()
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	sessions := result.Projects[0].Worktrees[0].Sessions
+	if got := sessionNames(sessions); got != "cb_wandered" {
+		t.Fatalf("main repo sessions = %s, want cb_wandered", got)
+	}
+	s := sessions[0]
+	if s.Branch != "feature/login" || s.Agent != tmux.AgentCodex || !s.CreatedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("session metadata = branch %q, agent %q, created %v", s.Branch, s.Agent, s.CreatedAt)
+	}
+}
+
+// listingTmux serves session options only through ListSessionOptions.
+type listingTmux struct {
+	fakeTmux
+	t *testing.T
+}
+
+func (f listingTmux) ListSessionOptions() (map[string]map[string]string, error) {
+	all := map[string]map[string]string{}
+	for optionKey, value := range f.options {
+		session, key, _ := strings.Cut(optionKey, "|")
+		if all[session] == nil {
+			all[session] = map[string]string{}
+		}
+		all[session][key] = value
+	}
+	return all, nil
+}
+
+func (f listingTmux) GetSessionOption(session, key string) (string, error) {
+	f.t.Errorf("GetSessionOption(%q, %q) called, want options read by ListSessionOptions", session, key)
+	return "", errors.New("unexpected call")
+}
+
+func TestDiscover_ReadsSessionOptionsInOneCall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repo := filepath.Join(home, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("mkdir repo: %v", err)
+	}
+	if err := config.SaveUserConfig(config.UserConfig{
+		Version:  config.SupportedConfigVersion,
+		Projects: []config.ProjectConfig{{Path: repo, Name: "web"}},
+	}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+
+	f := listingTmux{t: t, fakeTmux: fakeTmux{
+		sessions: []tmux.Session{{Name: "cb_wandered"}},
+		paths:    map[string]string{"cb_wandered": filepath.Join(home, "elsewhere")},
+		options: map[string]string{
+			"cb_wandered|" + tmux.SessionOptionProject: "web",
+			"cb_wandered|" + tmux.SessionOptionNote:    "fix login",
+			"cb_wandered|" + tmux.SessionOptionAgent:   "codex",
+		},
+	}}
+	svc := &Service{
+		tmuxClient: f,
+		execCmd: func(name string, args ...string) ([]byte, error) {
+			return []byte("worktree " + repo + "\n"), nil
+		},
+	}
+
+	result, err := svc.Discover()
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	sessions := result.Projects[0].Worktrees[0].Sessions
+	if len(sessions) != 1 || sessions[0].Note != "fix login" || sessions[0].Agent != tmux.AgentCodex {
+		t.Fatalf("sessions = %+v, want cb_wandered with its note and agent", sessions)
+	}
+}
+
 func TestDiscover_InvalidConfiguredProjectIsWarningOnly(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
@@ -392,3 +664,184 @@ func TestDiscover_ConfigMissing(t *testing.T) {
 		t.Fatal("ConfigMissing = false, want true")
 	}
 }
+
+func TestDiscover_AnnotatesWorktreeDiffStats(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repo := filepath.Join(home, "repo")
+	wt := filepath.Join(repo, ".worktrees", "repo-feat")
+	for _, p := range []string{repo, wt} {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", p, err)
+		}
+	}
+	if err := config.SaveUserConfig(config.UserConfig{
+		Version:  config.SupportedConfigVersion,
+		Projects: []config.ProjectConfig{{Path: repo, Name: "repo"}},
+	}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+
+	canonicalRepo, err := config.CanonicalPath(repo)
+	if err != nil {
+		t.Fatalf("CanonicalPath() error = %v", err)
+	}
+
+	var diffBase string
+	svc := &Service{
+		tmuxClient: fakeTmux{},
+		execCmd: func(name string, args ...string) ([]byte, error) {
+			switch {
+			case len(args) > 2 && args[2] == "worktree":
+				return []byte("worktree " + repo + "\nworktree " + wt + "\n"), nil
+			case len(args) > 2 && args[2] == "rev-parse":
+				if args[1] != canonicalRepo {
+					return nil, fmt.Errorf("unexpected rev-parse dir %s", args[1])
+				}
+				return []byte("main\n"), nil
+			case len(args) > 2 && args[2] == "diff":
+				diffBase = args[len(args)-1]
+				return []byte("4\t1\tcmd/start.go\n1\t0\tREADME.md\n"), nil
+			}
+			return nil, fmt.Errorf("unexpected command %v", args)
+		},
+	}
+
+	result, err := svc.Discover()
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	worktrees := result.Projects[0].Worktrees
+	if len(worktrees) != 2 {
+		t.Fatalf("len(worktrees) = %d, want 2", len(worktrees))
+	}
+	if worktrees[0].DiffStat != nil {
+		t.Fatalf("main repo DiffStat = %+v, want nil", worktrees[0].DiffStat)
+	}
+	if worktrees[1].DiffStat == nil || worktrees[1].DiffStat.Files != 2 || worktrees[1].DiffStat.Insertions != 5 || worktrees[1].DiffStat.Deletions != 1 {
+		t.Fatalf("worktree DiffStat = %+v, want 2 files +5 -1", worktrees[1].DiffStat)
+	}
+	if diffBase != "main" {
+		t.Fatalf("diff base = %q, want main", diffBase)
+	}
+}
+
+func TestAnnotateOverlaps(t *testing.T) {
+	active := []SessionNode{{Name: "cb_x"}}
+	worktrees := []WorktreeNode{
+		{Name: mainRepoLabel, IsMainRepo: true, ChangedFiles: []string{"a.go"}, Sessions: active},
+		{Name: ".worktrees/repo-a", ChangedFiles: []string{"b.go", "a.go", "c.go"}, Sessions: active},
+		{Name: ".worktrees/repo-b", ChangedFiles: []string{"c.go", "a.go"}, Sessions: active},
+		{Name: ".worktrees/repo-c", ChangedFiles: []string{"d.go"}, Sessions: active},
+		{Name: ".worktrees/repo-idle", ChangedFiles: []string{"a.go"}},
+	}
+
+	annotateOverlaps(worktrees)
+
+	want := []FileOverlap{{Worktree: ".worktrees/repo-b", Files: []string{"a.go", "c.go"}}}
+	if !reflect.DeepEqual(worktrees[1].Overlaps, want) {
+		t.Fatalf("repo-a overlaps = %+v, want %+v", worktrees[1].Overlaps, want)
+	}
+	want = []FileOverlap{{Worktree: ".worktrees/repo-a", Files: []string{"a.go", "c.go"}}}
+	if !reflect.DeepEqual(worktrees[2].Overlaps, want) {
+		t.Fatalf("repo-b overlaps = %+v, want %+v", worktrees[2].Overlaps, want)
+	}
+	for _, i := range []int{0, 3, 4} {
+		if len(worktrees[i].Overlaps) != 0 {
+			t.Fatalf("%s overlaps = %+v, want none", worktrees[i].Name, worktrees[i].Overlaps)
+		}
+	}
+}
+
+func TestDiscover_ExcludedWorktreesAreHidden(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repo := filepath.Join(home, "repo")
+	release := filepath.Join(repo, ".worktrees", "repo-release-1")
+	feature := filepath.Join(repo, ".worktrees", "repo-feature")
+	for _, p := range []string{repo, release, feature} {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", p, err)
+		}
+	}
+
+	if err := config.SaveUserConfig(config.UserConfig{
+		Version: config.SupportedConfigVersion,
+		Projects: []config.ProjectConfig{
+			{Path: repo, Name: "repo", ExcludeWorktrees: []string{"repo-release-*"}},
+		},
+	}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+
+	f := fakeTmux{
+		sessions: []tmux.Session{{Name: "cb_release"}, {Name: "cb_feature"}},
+		options: map[string]string{
+			"cb_release|" + tmux.SessionOptionHomePath: release,
+			"cb_feature|" + tmux.SessionOptionHomePath: feature,
+		},
+	}
+	svc := &Service{
+		tmuxClient: f,
+		execCmd: func(name string, args ...string) ([]byte, error) {
+			if len(args) > 3 && args[2] == "worktree" {
+				return []byte(strings.Join([]string{
+					"worktree " + repo,
+					"worktree " + release,
+					"worktree " + feature,
+				}, "\n")), nil
+			}
+			return nil, errors.New("unexpected command")
+		},
+	}
+
+	result, err := svc.Discover()
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	worktrees := result.Projects[0].Worktrees
+	if len(worktrees) != 2 || worktrees[1].Name != ".worktrees/repo-feature" {
+		t.Fatalf("worktrees = %+v, want main repo and feature only", worktrees)
+	}
+	if len(worktrees[0].Sessions) != 0 {
+		t.Fatalf("excluded session fell back to main repo: %+v", worktrees[0].Sessions)
+	}
+	if len(worktrees[1].Sessions) != 1 || worktrees[1].Sessions[0].Name != "cb_feature" {
+		t.Fatalf("feature sessions = %+v", worktrees[1].Sessions)
+	}
+}
+
+func TestDiscover_IgnoredSessionsAreSkipped(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	repo := filepath.Join(home, "repo")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("mkdir %s: %v", repo, err)
+	}
+	if err := config.SaveUserConfig(config.UserConfig{
+		Version:        config.SupportedConfigVersion,
+		Projects:       []config.ProjectConfig{{Path: repo, Name: "repo"}},
+		IgnoreSessions: []string{"scratch*"},
+	}); err != nil {
+		t.Fatalf("SaveUserConfig() error = %v", err)
+	}
+
+	f := fakeTmux{
+		sessions: []tmux.Session{{Name: "scratch-notes"}, {Name: "cb_work"}},
+		paths:    map[string]string{"scratch-notes": repo, "cb_work": repo},
+	}
+	svc := &Service{tmuxClient: f}
+
+	result, err := svc.Discover()
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	sessions := result.Projects[0].Worktrees[0].Sessions
+	if len(sessions) != 1 || sessions[0].Name != "cb_work" {
+		t.Fatalf("sessions = %+v, want only cb_work", sessions)
+	}
+}
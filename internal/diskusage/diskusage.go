@@ -0,0 +1,133 @@
+// Package diskusage measures how much disk a worktree takes up. Sizes come
+// from du, which already accounts for hard links and sparse files, and are
+// cached because a node_modules-heavy worktree takes seconds to walk.
+package diskusage
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/logging"
+)
+
+// DefaultTTL is how long a cached measurement is reused.
+const DefaultTTL = 10 * time.Minute
+
+// execCommand runs du; tests replace it.
+var execCommand = func(name string, args ...string) ([]byte, error) {
+	return logging.Output(exec.Command(name, args...))
+}
+
+// Measure returns the disk usage of path in KiB, as reported by du -sk.
+// du exits non-zero when some entries cannot be read but still prints a
+// total, which is returned.
+func Measure(path string) (int64, error) {
+	output, err := execCommand("du", "-sk", path)
+	if kb, parseErr := parseDuKB(output); parseErr == nil {
+		return kb, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure disk usage of %s: %w", path, err)
+	}
+	return 0, fmt.Errorf("failed to parse du output for %s: %q", path, strings.TrimSpace(string(output)))
+}
+
+// parseDuKB returns the size field of `du -sk` output ("123\t/path").
+func parseDuKB(output []byte) (int64, error) {
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty du output")
+	}
+	return strconv.ParseInt(fields[0], 10, 64)
+}
+
+// Entry is one cached measurement. Err is set when it failed.
+type Entry struct {
+	KB         int64
+	Err        error
+	MeasuredAt time.Time
+}
+
+// Cache remembers measurements per path for a TTL. It is safe for
+// concurrent use; concurrent measurements of one path share a single du.
+type Cache struct {
+	ttl     time.Duration
+	now     func() time.Time
+	measure func(path string) (int64, error)
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	pending map[string]chan struct{}
+}
+
+// NewCache creates a Cache that measures paths with measure (normally
+// Measure) and reuses results for ttl.
+func NewCache(ttl time.Duration, measure func(path string) (int64, error)) *Cache {
+	return &Cache{
+		ttl:     ttl,
+		now:     time.Now,
+		measure: measure,
+		entries: make(map[string]Entry),
+		pending: make(map[string]chan struct{}),
+	}
+}
+
+// Get returns the measurement of path if one is cached and not older than
+// the TTL.
+func (c *Cache) Get(path string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fresh(path)
+}
+
+// Pending reports whether path is being measured.
+func (c *Cache) Pending(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.pending[path]
+	return ok
+}
+
+// fresh is Get with c.mu held.
+func (c *Cache) fresh(path string) (Entry, bool) {
+	entry, ok := c.entries[path]
+	if !ok || c.now().Sub(entry.MeasuredAt) > c.ttl {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Measure returns the cached measurement of path, measuring it first when
+// there is none or it expired. It blocks while du runs; a call made while
+// path is already being measured waits for that result instead of starting
+// another du.
+func (c *Cache) Measure(path string) Entry {
+	c.mu.Lock()
+	if entry, ok := c.fresh(path); ok {
+		c.mu.Unlock()
+		return entry
+	}
+	if done, ok := c.pending[path]; ok {
+		c.mu.Unlock()
+		<-done
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.entries[path]
+	}
+	done := make(chan struct{})
+	c.pending[path] = done
+	c.mu.Unlock()
+
+	kb, err := c.measure(path)
+	entry := Entry{KB: kb, Err: err, MeasuredAt: c.now()}
+	c.mu.Lock()
+	c.entries[path] = entry
+	delete(c.pending, path)
+	c.mu.Unlock()
+	close(done)
+	return entry
+}
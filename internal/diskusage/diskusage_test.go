@@ -0,0 +1,125 @@
+package diskusage
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		want    int64
+		wantErr string
+	}{
+		{name: "parses du output", output: "123456\t/code/repo/.worktrees/repo-feat\n", want: 123456},
+		{name: "keeps the total despite unreadable entries", output: "42\t/wt\n", err: errors.New("exit status 1"), want: 42},
+		{name: "reports du failures", err: errors.New("exit status 1"), wantErr: "failed to measure disk usage"},
+		{name: "reports unparseable output", output: "du: illegal option\n", wantErr: "failed to parse du output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured string
+			orig := execCommand
+			execCommand = func(name string, args ...string) ([]byte, error) {
+				captured = strings.Join(append([]string{name}, args...), " ")
+				return []byte(tt.output), tt.err
+			}
+			defer func() { execCommand = orig }()
+
+			got, err := Measure("/wt")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Measure() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Measure() = (%d, %v), want %d", got, err, tt.want)
+			}
+			if captured != "du -sk /wt" {
+				t.Fatalf("command = %q, want du -sk /wt", captured)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	calls := 0
+	c := NewCache(time.Minute, func(path string) (int64, error) {
+		calls++
+		return int64(calls * 100), nil
+	})
+	c.now = func() time.Time { return now }
+
+	if _, ok := c.Get("/wt"); ok {
+		t.Fatal("Get() before measuring reported a cached entry")
+	}
+	if got := c.Measure("/wt"); got.KB != 100 {
+		t.Fatalf("Measure() = %+v, want 100 KiB", got)
+	}
+	if got := c.Measure("/wt"); got.KB != 100 || calls != 1 {
+		t.Fatalf("second Measure() = %+v after %d calls, want the cached 100 KiB", got, calls)
+	}
+	if got, ok := c.Get("/wt"); !ok || got.KB != 100 {
+		t.Fatalf("Get() = (%+v, %v), want the cached entry", got, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.Get("/wt"); ok {
+		t.Fatal("Get() returned an expired entry")
+	}
+	if got := c.Measure("/wt"); got.KB != 200 || calls != 2 {
+		t.Fatalf("Measure() after expiry = %+v after %d calls, want a fresh 200 KiB", got, calls)
+	}
+}
+
+func TestCache_ConcurrentMeasuresShareOneDu(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	calls := 0
+	c := NewCache(time.Minute, func(path string) (int64, error) {
+		calls++
+		close(started)
+		<-release
+		return 100, nil
+	})
+
+	var wg sync.WaitGroup
+	results := make(chan Entry, 3)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results <- c.Measure("/wt")
+	}()
+	<-started
+	if !c.Pending("/wt") {
+		t.Fatal("Pending() = false while du runs")
+	}
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- c.Measure("/wt")
+		}()
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for got := range results {
+		if got.KB != 100 {
+			t.Fatalf("Measure() = %+v, want 100 KiB", got)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("du ran %d times, want 1", calls)
+	}
+	if c.Pending("/wt") {
+		t.Fatal("Pending() = true after the measurement finished")
+	}
+}
@@ -0,0 +1,211 @@
+// Package events derives change events (status changed, session created or
+// removed, worktree added) from successive discovery results and fans them
+// out to subscribers, so consumers such as the daemon's status history react
+// to changes without being wired to the code that discovers them.
+package events
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// Kind identifies what an Event reports.
+type Kind string
+
+const (
+	// StatusChanged reports a window's agent status changing. Status is
+	// empty when the window closed or its agent went away.
+	StatusChanged Kind = "status_changed"
+	// SessionCreated reports a session appearing under a project.
+	SessionCreated Kind = "session_created"
+	// SessionRemoved reports a session disappearing.
+	SessionRemoved Kind = "session_removed"
+	// WorktreeAdded reports a linked worktree appearing under a project.
+	WorktreeAdded Kind = "worktree_added"
+)
+
+// Event is one change between two discovery results. Fields that do not
+// apply to the Kind are empty.
+type Event struct {
+	Kind    Kind      `json:"kind"`
+	At      time.Time `json:"at"`
+	Project string    `json:"project,omitempty"`
+	// Worktree is the worktree path.
+	Worktree string `json:"worktree,omitempty"`
+	Session  string `json:"session,omitempty"`
+	// Target is the window (see tmux.Window.Target) of a StatusChanged
+	// event, whose status went from Previous to Status.
+	Target   string      `json:"target,omitempty"`
+	Status   tmux.Status `json:"status,omitempty"`
+	Previous tmux.Status `json:"previous,omitempty"`
+}
+
+// Handler receives published events. Handlers run synchronously in the
+// publisher's goroutine, in subscription order, so they must not block.
+type Handler func(Event)
+
+// Bus delivers published events to every subscribed Handler. The zero value
+// is ready to use.
+type Bus struct {
+	mu   sync.RWMutex
+	subs []*subscription
+}
+
+type subscription struct {
+	handler Handler
+}
+
+// Subscribe registers h for every later event. The returned func
+// unsubscribes it.
+func (b *Bus) Subscribe(h Handler) func() {
+	sub := &subscription{handler: h}
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.subs = slices.DeleteFunc(b.subs, func(s *subscription) bool { return s == sub })
+	}
+}
+
+// Publish delivers events, in order, to every subscriber.
+func (b *Bus) Publish(events ...Event) {
+	b.mu.RLock()
+	subs := slices.Clone(b.subs)
+	b.mu.RUnlock()
+
+	for _, ev := range events {
+		for _, sub := range subs {
+			sub.handler(ev)
+		}
+	}
+}
+
+// sessionRef locates a session in a discovery result.
+type sessionRef struct {
+	project  string
+	worktree string
+}
+
+// Diff returns the events that turn prev into next, stamped with at.
+// Diffing against the zero Result reports everything in next as new, which
+// is how subscribers learn the initial state. Events come sorted by kind,
+// then by name, so output is deterministic.
+func Diff(prev, next discovery.Result, at time.Time) []Event {
+	var events []Event
+
+	prevWorktrees, prevSessions := index(prev)
+	nextWorktrees, nextSessions := index(next)
+	for path, project := range nextWorktrees {
+		if _, ok := prevWorktrees[path]; !ok {
+			events = append(events, Event{Kind: WorktreeAdded, At: at, Project: project, Worktree: path})
+		}
+	}
+	for name, ref := range nextSessions {
+		if _, ok := prevSessions[name]; !ok {
+			events = append(events, Event{Kind: SessionCreated, At: at, Project: ref.project, Worktree: ref.worktree, Session: name})
+		}
+	}
+	for name, ref := range prevSessions {
+		if _, ok := nextSessions[name]; !ok {
+			events = append(events, Event{Kind: SessionRemoved, At: at, Project: ref.project, Worktree: ref.worktree, Session: name})
+		}
+	}
+
+	windowSessions := windowSessionNames(next)
+	for target, status := range next.WindowStatuses {
+		if previous := prev.WindowStatuses[target]; previous != status {
+			events = append(events, statusEvent(target, status, previous, at, windowSessions, nextSessions))
+		}
+	}
+	prevWindowSessions := windowSessionNames(prev)
+	for target, previous := range prev.WindowStatuses {
+		if _, ok := next.WindowStatuses[target]; !ok {
+			events = append(events, statusEvent(target, "", previous, at, prevWindowSessions, prevSessions))
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.Kind != b.Kind {
+			return kindOrder(a.Kind) < kindOrder(b.Kind)
+		}
+		if a.Worktree != b.Worktree {
+			return a.Worktree < b.Worktree
+		}
+		if a.Session != b.Session {
+			return a.Session < b.Session
+		}
+		return a.Target < b.Target
+	})
+	return events
+}
+
+func statusEvent(target string, status, previous tmux.Status, at time.Time, windowSessions map[string]string, sessions map[string]sessionRef) Event {
+	session := windowSessions[target]
+	ref := sessions[session]
+	return Event{
+		Kind:     StatusChanged,
+		At:       at,
+		Project:  ref.project,
+		Worktree: ref.worktree,
+		Session:  session,
+		Target:   target,
+		Status:   status,
+		Previous: previous,
+	}
+}
+
+// kindOrder orders events so worktrees appear before their sessions and
+// sessions before their statuses.
+func kindOrder(k Kind) int {
+	switch k {
+	case WorktreeAdded:
+		return 0
+	case SessionCreated:
+		return 1
+	case StatusChanged:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// index maps the linked worktree paths of result to their project names,
+// and its session names to their locations.
+func index(result discovery.Result) (map[string]string, map[string]sessionRef) {
+	worktrees := make(map[string]string)
+	sessions := make(map[string]sessionRef)
+	for _, p := range result.Projects {
+		for _, wt := range p.Worktrees {
+			if !wt.IsMainRepo && !wt.IsMissing && wt.Path != "" {
+				worktrees[wt.Path] = p.Name
+			}
+			for _, s := range wt.Sessions {
+				sessions[s.Name] = sessionRef{project: p.Name, worktree: wt.Path}
+			}
+		}
+	}
+	return worktrees, sessions
+}
+
+// windowSessionNames maps the window targets of result to their sessions.
+func windowSessionNames(result discovery.Result) map[string]string {
+	names := make(map[string]string)
+	for _, p := range result.Projects {
+		for _, wt := range p.Worktrees {
+			for _, s := range wt.Sessions {
+				for _, w := range s.Windows {
+					names[w.Target(s.Name)] = s.Name
+				}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,108 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func result(worktrees []discovery.WorktreeNode, statuses map[string]tmux.Status) discovery.Result {
+	return discovery.Result{
+		Projects:       []discovery.ProjectNode{{Name: "app", Path: "/src/app", Worktrees: worktrees}},
+		WindowStatuses: statuses,
+	}
+}
+
+func TestDiff(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	main := discovery.WorktreeNode{Name: "(main repo)", Path: "/src/app", IsMainRepo: true}
+	feat := discovery.WorktreeNode{
+		Name: "app-feat",
+		Path: "/src/app/.worktrees/app-feat",
+		Sessions: []discovery.SessionNode{{
+			Name:    "cb_feat",
+			Windows: []tmux.Window{{ID: "@1", Name: "claude"}},
+		}},
+	}
+	fix := discovery.WorktreeNode{
+		Name: "app-fix",
+		Path: "/src/app/.worktrees/app-fix",
+		Sessions: []discovery.SessionNode{{
+			Name:    "cb_fix",
+			Windows: []tmux.Window{{ID: "@2", Name: "codex"}},
+		}},
+	}
+	before := result([]discovery.WorktreeNode{main, feat}, map[string]tmux.Status{"@1": tmux.StatusWorking})
+
+	tests := []struct {
+		name string
+		prev discovery.Result
+		next discovery.Result
+		want []Event
+	}{
+		{
+			name: "unchanged",
+			prev: before,
+			next: before,
+			want: nil,
+		},
+		{
+			name: "initial state",
+			prev: discovery.Result{},
+			next: before,
+			want: []Event{
+				{Kind: WorktreeAdded, At: at, Project: "app", Worktree: feat.Path},
+				{Kind: SessionCreated, At: at, Project: "app", Worktree: feat.Path, Session: "cb_feat"},
+				{Kind: StatusChanged, At: at, Project: "app", Worktree: feat.Path, Session: "cb_feat", Target: "@1", Status: tmux.StatusWorking},
+			},
+		},
+		{
+			name: "status changed",
+			prev: before,
+			next: result([]discovery.WorktreeNode{main, feat}, map[string]tmux.Status{"@1": tmux.StatusWaiting}),
+			want: []Event{
+				{Kind: StatusChanged, At: at, Project: "app", Worktree: feat.Path, Session: "cb_feat", Target: "@1", Status: tmux.StatusWaiting, Previous: tmux.StatusWorking},
+			},
+		},
+		{
+			name: "session replaced",
+			prev: before,
+			next: result([]discovery.WorktreeNode{main, fix}, map[string]tmux.Status{"@2": tmux.StatusIdle}),
+			want: []Event{
+				{Kind: WorktreeAdded, At: at, Project: "app", Worktree: fix.Path},
+				{Kind: SessionCreated, At: at, Project: "app", Worktree: fix.Path, Session: "cb_fix"},
+				{Kind: StatusChanged, At: at, Project: "app", Worktree: feat.Path, Session: "cb_feat", Target: "@1", Previous: tmux.StatusWorking},
+				{Kind: StatusChanged, At: at, Project: "app", Worktree: fix.Path, Session: "cb_fix", Target: "@2", Status: tmux.StatusIdle},
+				{Kind: SessionRemoved, At: at, Project: "app", Worktree: feat.Path, Session: "cb_feat"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.prev, tt.next, at); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBus(t *testing.T) {
+	var bus Bus
+	var first, second []Kind
+	unsubscribe := bus.Subscribe(func(ev Event) { first = append(first, ev.Kind) })
+	bus.Subscribe(func(ev Event) { second = append(second, ev.Kind) })
+
+	bus.Publish(Event{Kind: SessionCreated}, Event{Kind: StatusChanged})
+	unsubscribe()
+	bus.Publish(Event{Kind: SessionRemoved})
+
+	if want := []Kind{SessionCreated, StatusChanged}; !reflect.DeepEqual(first, want) {
+		t.Errorf("unsubscribed handler got %v, want %v", first, want)
+	}
+	if want := []Kind{SessionCreated, StatusChanged, SessionRemoved}; !reflect.DeepEqual(second, want) {
+		t.Errorf("subscribed handler got %v, want %v", second, want)
+	}
+}
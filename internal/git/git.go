@@ -0,0 +1,301 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/logging"
+)
+
+// Client provides git operations against repositories and worktrees.
+type Client struct {
+	execCommand func(name string, args ...string) ([]byte, error)
+}
+
+// NewClient creates a Client that executes real git commands.
+func NewClient() *Client {
+	return &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			return logging.Output(exec.Command(name, args...))
+		},
+	}
+}
+
+// run executes git -C dir <args> and folds stderr into returned errors.
+func (c *Client) run(dir string, args ...string) (string, error) {
+	fullArgs := append([]string{"-C", dir}, args...)
+	output, err := c.execCommand("git", fullArgs...)
+	if err != nil {
+		return "", commandError(err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+func commandError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+			return fmt.Errorf("%w: %s", err, stderr)
+		}
+	}
+	return err
+}
+
+// CurrentBranch returns the branch checked out in dir.
+func (c *Client) CurrentBranch(dir string) (string, error) {
+	branch, err := c.run(dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine current branch in %s: %w", dir, err)
+	}
+	branch = strings.TrimSpace(branch)
+	if branch == "HEAD" {
+		return "", fmt.Errorf("%s has a detached HEAD", dir)
+	}
+	return branch, nil
+}
+
+// StatusPorcelain returns `git status --porcelain` entries for dir.
+// An empty slice means the working tree is clean.
+func (c *Client) StatusPorcelain(dir string) ([]string, error) {
+	output, err := c.run(dir, "status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status in %s: %w", dir, err)
+	}
+	return splitNonEmptyLines(output), nil
+}
+
+// MainWorktree returns the main (non-linked) worktree path for the repo containing dir.
+func (c *Client) MainWorktree(dir string) (string, error) {
+	output, err := c.run(dir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return "", fmt.Errorf("failed to list worktrees for %s: %w", dir, err)
+	}
+	for _, line := range splitNonEmptyLines(output) {
+		if path, ok := strings.CutPrefix(line, "worktree "); ok {
+			return strings.TrimSpace(path), nil
+		}
+	}
+	return "", fmt.Errorf("no worktrees reported for %s", dir)
+}
+
+// Merge merges branch into the branch checked out in dir.
+// When ffOnly is true the merge is refused unless it can fast-forward.
+func (c *Client) Merge(dir, branch string, ffOnly bool) error {
+	args := []string{"merge", "--no-edit"}
+	if ffOnly {
+		args = append(args, "--ff-only")
+	}
+	args = append(args, branch)
+	if _, err := c.run(dir, args...); err != nil {
+		return fmt.Errorf("failed to merge %s in %s: %w", branch, dir, err)
+	}
+	return nil
+}
+
+// RemoveWorktree removes a linked worktree registered with the repo at repoDir.
+func (c *Client) RemoveWorktree(repoDir, worktreePath string) error {
+	if _, err := c.run(repoDir, "worktree", "remove", worktreePath); err != nil {
+		return fmt.Errorf("failed to remove worktree %s: %w", worktreePath, err)
+	}
+	return nil
+}
+
+// DeleteBranch deletes a local branch. Unmerged branches are refused by git.
+func (c *Client) DeleteBranch(repoDir, branch string) error {
+	if _, err := c.run(repoDir, "branch", "-d", branch); err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", branch, err)
+	}
+	return nil
+}
+
+// MergedBranches returns the local branches whose tips are reachable from
+// base, base itself included.
+func (c *Client) MergedBranches(repoDir, base string) ([]string, error) {
+	output, err := c.run(repoDir, "branch", "--merged", base, "--format=%(refname:short)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches merged into %s: %w", base, err)
+	}
+	return splitNonEmptyLines(output), nil
+}
+
+// BranchTip returns the commit hash at the tip of the local branch.
+func (c *Client) BranchTip(repoDir, branch string) (string, error) {
+	output, err := c.run(repoDir, "rev-parse", "--verify", "refs/heads/"+branch)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve branch %s: %w", branch, err)
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// PruneWorktrees removes the administrative entries of worktrees whose
+// directories no longer exist.
+func (c *Client) PruneWorktrees(repoDir string) error {
+	if _, err := c.run(repoDir, "worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees in %s: %w", repoDir, err)
+	}
+	return nil
+}
+
+// Fetch fetches all remotes for the repo containing dir.
+func (c *Client) Fetch(dir string) error {
+	if _, err := c.run(dir, "fetch", "--all", "--prune"); err != nil {
+		return fmt.Errorf("failed to fetch in %s: %w", dir, err)
+	}
+	return nil
+}
+
+// RemoteBranchExists reports whether remote has a branch named branch. It
+// queries the remote directly, so it also sees branches not yet fetched.
+func (c *Client) RemoteBranchExists(dir, remote, branch string) (bool, error) {
+	output, err := c.run(dir, "ls-remote", "--heads", remote, "refs/heads/"+branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to query %s for branch %s: %w", remote, branch, err)
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
+// FetchBranch fetches branch from remote into its remote-tracking ref
+// (for example refs/remotes/origin/<branch>).
+func (c *Client) FetchBranch(dir, remote, branch string) error {
+	refspec := fmt.Sprintf("refs/heads/%s:refs/remotes/%s/%s", branch, remote, branch)
+	if _, err := c.run(dir, "fetch", remote, refspec); err != nil {
+		return fmt.Errorf("failed to fetch %s from %s: %w", branch, remote, err)
+	}
+	return nil
+}
+
+// Upstream returns the upstream ref tracked by branch (for example
+// "origin/main"). An error is returned when no upstream is configured.
+func (c *Client) Upstream(dir, branch string) (string, error) {
+	upstream, err := c.run(dir, "rev-parse", "--abbrev-ref", branch+"@{upstream}")
+	if err != nil {
+		return "", fmt.Errorf("no upstream for %s: %w", branch, err)
+	}
+	return strings.TrimSpace(upstream), nil
+}
+
+// Rebase rebases the branch checked out in dir onto the given ref.
+func (c *Client) Rebase(dir, onto string) error {
+	if _, err := c.run(dir, "rebase", onto); err != nil {
+		return fmt.Errorf("failed to rebase %s onto %s: %w", dir, onto, err)
+	}
+	return nil
+}
+
+// AbortRebase aborts an in-progress rebase in dir.
+func (c *Client) AbortRebase(dir string) error {
+	if _, err := c.run(dir, "rebase", "--abort"); err != nil {
+		return fmt.Errorf("failed to abort rebase in %s: %w", dir, err)
+	}
+	return nil
+}
+
+// AbortMerge aborts an in-progress merge in dir.
+func (c *Client) AbortMerge(dir string) error {
+	if _, err := c.run(dir, "merge", "--abort"); err != nil {
+		return fmt.Errorf("failed to abort merge in %s: %w", dir, err)
+	}
+	return nil
+}
+
+// ConflictedFiles returns paths with unresolved merge conflicts in dir.
+func (c *Client) ConflictedFiles(dir string) ([]string, error) {
+	output, err := c.run(dir, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conflicts in %s: %w", dir, err)
+	}
+	return splitNonEmptyLines(output), nil
+}
+
+func splitNonEmptyLines(output string) []string {
+	var lines []string
+	for line := range strings.SplitSeq(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// DiffStat summarizes changed files and lines between two revisions.
+type DiffStat struct {
+	Files      int
+	Insertions int
+	Deletions  int
+}
+
+// NumStatAgainst returns the diff stat and changed paths of dir's working
+// tree against its merge-base with base.
+func (c *Client) NumStatAgainst(dir, base string) (DiffStat, []string, error) {
+	output, err := c.run(dir, "diff", "--numstat", "--merge-base", base)
+	if err != nil {
+		return DiffStat{}, nil, fmt.Errorf("failed to diff %s against %s: %w", dir, base, err)
+	}
+	stat, files := ParseNumStat(output)
+	return stat, files, nil
+}
+
+// ParseNumStat parses `git diff --numstat` output into a summary and the list
+// of changed paths. Binary files ("-" counts) contribute to Files only.
+func ParseNumStat(output string) (DiffStat, []string) {
+	var stat DiffStat
+	var files []string
+	for _, line := range splitNonEmptyLines(output) {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		stat.Files++
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			stat.Insertions += n
+		}
+		if n, err := strconv.Atoi(fields[1]); err == nil {
+			stat.Deletions += n
+		}
+		files = append(files, fields[2])
+	}
+	return stat, files
+}
+
+// UnpushedCommits returns one-line summaries of commits on dir's HEAD that are
+// not on its upstream, or on any remote when no upstream is configured.
+// Repositories without remotes report no unpushed commits.
+func (c *Client) UnpushedCommits(dir string) ([]string, error) {
+	if _, err := c.run(dir, "rev-parse", "--abbrev-ref", "@{upstream}"); err == nil {
+		output, err := c.run(dir, "log", "--oneline", "@{upstream}..HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list unpushed commits in %s: %w", dir, err)
+		}
+		return splitNonEmptyLines(output), nil
+	}
+
+	remotes, err := c.run(dir, "remote")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes in %s: %w", dir, err)
+	}
+	if strings.TrimSpace(remotes) == "" {
+		return nil, nil
+	}
+
+	output, err := c.run(dir, "log", "--oneline", "HEAD", "--not", "--remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unpushed commits in %s: %w", dir, err)
+	}
+	return splitNonEmptyLines(output), nil
+}
+
+// CommitAll stages every change in dir (including untracked files) and
+// commits it with message.
+func (c *Client) CommitAll(dir, message string) error {
+	if _, err := c.run(dir, "add", "-A"); err != nil {
+		return fmt.Errorf("failed to stage changes in %s: %w", dir, err)
+	}
+	if _, err := c.run(dir, "commit", "-m", message); err != nil {
+		return fmt.Errorf("failed to commit in %s: %w", dir, err)
+	}
+	return nil
+}
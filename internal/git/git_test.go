@@ -0,0 +1,425 @@
+package git
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient_CurrentBranch(t *testing.T) {
+	var captured []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			captured = append([]string{name}, args...)
+			return []byte("feature/login\n"), nil
+		},
+	}
+
+	branch, err := client.CurrentBranch("/repo/.worktrees/repo-feature")
+	if err != nil {
+		t.Fatalf("CurrentBranch() error = %v", err)
+	}
+	if branch != "feature/login" {
+		t.Fatalf("branch = %q, want %q", branch, "feature/login")
+	}
+	want := "git -C /repo/.worktrees/repo-feature rev-parse --abbrev-ref HEAD"
+	if got := strings.Join(captured, " "); got != want {
+		t.Fatalf("command = %q, want %q", got, want)
+	}
+}
+
+func TestClient_CurrentBranch_DetachedHead(t *testing.T) {
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			return []byte("HEAD\n"), nil
+		},
+	}
+
+	if _, err := client.CurrentBranch("/repo"); err == nil {
+		t.Fatal("expected detached HEAD error, got nil")
+	}
+}
+
+func TestClient_StatusPorcelain(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{name: "clean", output: "", want: nil},
+		{name: "preserves leading status column", output: " M main.go\n?? notes.txt\n", want: []string{" M main.go", "?? notes.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{
+				execCommand: func(name string, args ...string) ([]byte, error) {
+					return []byte(tt.output), nil
+				},
+			}
+
+			got, err := client.StatusPorcelain("/repo")
+			if err != nil {
+				t.Fatalf("StatusPorcelain() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("entries = %q, want %q", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("entries[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestClient_MainWorktree(t *testing.T) {
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			return []byte("worktree /repo\nHEAD abc\nbranch refs/heads/main\n\nworktree /repo/.worktrees/repo-feat\nHEAD def\n"), nil
+		},
+	}
+
+	got, err := client.MainWorktree("/repo/.worktrees/repo-feat")
+	if err != nil {
+		t.Fatalf("MainWorktree() error = %v", err)
+	}
+	if got != "/repo" {
+		t.Fatalf("MainWorktree() = %q, want %q", got, "/repo")
+	}
+}
+
+func TestClient_Merge(t *testing.T) {
+	tests := []struct {
+		name   string
+		ffOnly bool
+		want   string
+	}{
+		{name: "default merge", want: "git -C /repo merge --no-edit feat"},
+		{name: "fast-forward only", ffOnly: true, want: "git -C /repo merge --no-edit --ff-only feat"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var captured []string
+			client := &Client{
+				execCommand: func(name string, args ...string) ([]byte, error) {
+					captured = append([]string{name}, args...)
+					return nil, nil
+				},
+			}
+
+			if err := client.Merge("/repo", "feat", tt.ffOnly); err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+			if got := strings.Join(captured, " "); got != tt.want {
+				t.Fatalf("command = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_Merge_Error(t *testing.T) {
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			return nil, errors.New("conflict")
+		},
+	}
+
+	err := client.Merge("/repo", "feat", false)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "failed to merge feat") {
+		t.Fatalf("error = %q, want merge context", err)
+	}
+}
+
+func TestClient_RemoveWorktreeAndDeleteBranch(t *testing.T) {
+	var calls []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+			return nil, nil
+		},
+	}
+
+	if err := client.RemoveWorktree("/repo", "/repo/.worktrees/repo-feat"); err != nil {
+		t.Fatalf("RemoveWorktree() error = %v", err)
+	}
+	if err := client.DeleteBranch("/repo", "feat"); err != nil {
+		t.Fatalf("DeleteBranch() error = %v", err)
+	}
+	if err := client.PruneWorktrees("/repo"); err != nil {
+		t.Fatalf("PruneWorktrees() error = %v", err)
+	}
+
+	want := []string{
+		"git -C /repo worktree remove /repo/.worktrees/repo-feat",
+		"git -C /repo branch -d feat",
+		"git -C /repo worktree prune",
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
+
+func TestClient_MergedBranches(t *testing.T) {
+	var captured string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			captured = strings.Join(append([]string{name}, args...), " ")
+			return []byte("feat/done\nmain\n"), nil
+		},
+	}
+
+	got, err := client.MergedBranches("/repo", "main")
+	if err != nil {
+		t.Fatalf("MergedBranches() error = %v", err)
+	}
+	if want := []string{"feat/done", "main"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("MergedBranches() = %v, want %v", got, want)
+	}
+	want := "git -C /repo branch --merged main --format=%(refname:short)"
+	if captured != want {
+		t.Fatalf("command = %q, want %q", captured, want)
+	}
+}
+
+func TestClient_BranchTip(t *testing.T) {
+	var captured string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			captured = strings.Join(append([]string{name}, args...), " ")
+			return []byte("0123abcd\n"), nil
+		},
+	}
+
+	got, err := client.BranchTip("/repo", "feat/done")
+	if err != nil {
+		t.Fatalf("BranchTip() error = %v", err)
+	}
+	if got != "0123abcd" {
+		t.Fatalf("BranchTip() = %q, want 0123abcd", got)
+	}
+	if want := "git -C /repo rev-parse --verify refs/heads/feat/done"; captured != want {
+		t.Fatalf("command = %q, want %q", captured, want)
+	}
+}
+
+func TestClient_SyncCommands(t *testing.T) {
+	var calls []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+			if len(args) > 2 && args[2] == "rev-parse" {
+				return []byte("origin/main\n"), nil
+			}
+			if len(args) > 2 && args[2] == "diff" {
+				return []byte("a.go\nb.go\n"), nil
+			}
+			return nil, nil
+		},
+	}
+
+	if err := client.Fetch("/repo"); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	upstream, err := client.Upstream("/repo", "main")
+	if err != nil || upstream != "origin/main" {
+		t.Fatalf("Upstream() = (%q, %v), want origin/main", upstream, err)
+	}
+	if err := client.Rebase("/wt", "origin/main"); err != nil {
+		t.Fatalf("Rebase() error = %v", err)
+	}
+	conflicts, err := client.ConflictedFiles("/wt")
+	if err != nil || len(conflicts) != 2 {
+		t.Fatalf("ConflictedFiles() = (%v, %v), want 2 files", conflicts, err)
+	}
+	if err := client.AbortRebase("/wt"); err != nil {
+		t.Fatalf("AbortRebase() error = %v", err)
+	}
+	if err := client.AbortMerge("/wt"); err != nil {
+		t.Fatalf("AbortMerge() error = %v", err)
+	}
+
+	want := []string{
+		"git -C /repo fetch --all --prune",
+		"git -C /repo rev-parse --abbrev-ref main@{upstream}",
+		"git -C /wt rebase origin/main",
+		"git -C /wt diff --name-only --diff-filter=U",
+		"git -C /wt rebase --abort",
+		"git -C /wt merge --abort",
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("calls[%d] = %q, want %q", i, calls[i], want[i])
+		}
+	}
+}
+
+func TestClient_RemoteBranch(t *testing.T) {
+	var calls []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, strings.Join(append([]string{name}, args...), " "))
+			if args[2] == "ls-remote" && args[5] == "refs/heads/feat" {
+				return []byte("abc123\trefs/heads/feat\n"), nil
+			}
+			return nil, nil
+		},
+	}
+
+	exists, err := client.RemoteBranchExists("/repo", "origin", "feat")
+	if err != nil || !exists {
+		t.Fatalf("RemoteBranchExists(feat) = (%v, %v), want true", exists, err)
+	}
+	exists, err = client.RemoteBranchExists("/repo", "origin", "missing")
+	if err != nil || exists {
+		t.Fatalf("RemoteBranchExists(missing) = (%v, %v), want false", exists, err)
+	}
+	if err := client.FetchBranch("/repo", "origin", "feat"); err != nil {
+		t.Fatalf("FetchBranch() error = %v", err)
+	}
+
+	want := []string{
+		"git -C /repo ls-remote --heads origin refs/heads/feat",
+		"git -C /repo ls-remote --heads origin refs/heads/missing",
+		"git -C /repo fetch origin refs/heads/feat:refs/remotes/origin/feat",
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestClient_NumStatAgainst(t *testing.T) {
+	var captured string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			captured = strings.Join(append([]string{name}, args...), " ")
+			return []byte("4\t1\tmain.go\n-\t-\tlogo.png\n"), nil
+		},
+	}
+
+	stat, files, err := client.NumStatAgainst("/wt", "main")
+	if err != nil {
+		t.Fatalf("NumStatAgainst() error = %v", err)
+	}
+	if stat != (DiffStat{Files: 2, Insertions: 4, Deletions: 1}) {
+		t.Fatalf("stat = %+v", stat)
+	}
+	if strings.Join(files, ",") != "main.go,logo.png" {
+		t.Fatalf("files = %v", files)
+	}
+	if captured != "git -C /wt diff --numstat --merge-base main" {
+		t.Fatalf("command = %q", captured)
+	}
+}
+
+func TestParseNumStat(t *testing.T) {
+	output := "10\t2\tcmd/start.go\n-\t-\tassets/logo.png\n3\t0\tREADME.md\n"
+
+	stat, files := ParseNumStat(output)
+	want := DiffStat{Files: 3, Insertions: 13, Deletions: 2}
+	if stat != want {
+		t.Fatalf("ParseNumStat() stat = %+v, want %+v", stat, want)
+	}
+	wantFiles := []string{"cmd/start.go", "assets/logo.png", "README.md"}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Fatalf("ParseNumStat() files = %v, want %v", files, wantFiles)
+	}
+
+	if stat, files := ParseNumStat(""); stat != (DiffStat{}) || len(files) != 0 {
+		t.Fatalf("ParseNumStat(\"\") = %+v, %v, want empty", stat, files)
+	}
+}
+
+func TestClient_UnpushedCommits(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses map[string]string
+		failing   map[string]bool
+		wantLog   string
+		want      []string
+	}{
+		{
+			name:      "upstream configured",
+			responses: map[string]string{"log": "abc123 add feature\n"},
+			wantLog:   "@{upstream}..HEAD",
+			want:      []string{"abc123 add feature"},
+		},
+		{
+			name:      "no upstream falls back to remotes",
+			responses: map[string]string{"remote": "origin\n", "log": "def456 wip\n"},
+			failing:   map[string]bool{"rev-parse": true},
+			wantLog:   "--remotes",
+			want:      []string{"def456 wip"},
+		},
+		{
+			name:      "no remotes",
+			responses: map[string]string{"remote": ""},
+			failing:   map[string]bool{"rev-parse": true},
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logArgs []string
+			client := &Client{
+				execCommand: func(name string, args ...string) ([]byte, error) {
+					sub := args[2]
+					if tt.failing[sub] {
+						return nil, errors.New("exit status 128")
+					}
+					if sub == "log" {
+						logArgs = args
+					}
+					return []byte(tt.responses[sub]), nil
+				},
+			}
+
+			got, err := client.UnpushedCommits("/wt")
+			if err != nil {
+				t.Fatalf("UnpushedCommits() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("UnpushedCommits() = %v, want %v", got, tt.want)
+			}
+			if tt.wantLog != "" && logArgs[len(logArgs)-1] != tt.wantLog {
+				t.Fatalf("log args = %v, want last %q", logArgs, tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestClient_CommitAll(t *testing.T) {
+	var calls [][]string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, args)
+			return nil, nil
+		},
+	}
+
+	if err := client.CommitAll("/wt", "checkpoint: now"); err != nil {
+		t.Fatalf("CommitAll() error = %v", err)
+	}
+	want := [][]string{
+		{"-C", "/wt", "add", "-A"},
+		{"-C", "/wt", "commit", "-m", "checkpoint: now"},
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// commandMessage is the log message of external command records.
+const commandMessage = "exec"
+
+// commandAudit enables external command records; Setup turns it on whenever
+// the log goes to a file.
+var commandAudit atomic.Bool
+
+// valueOptions are global options whose value precedes the subcommand
+// ("git -C dir status", "zellij --session s action").
+var valueOptions = map[string]bool{"-C": true, "-c": true, "-L": true, "-S": true, "-f": true, "--session": true}
+
+// CommandRecord is one external command read back from the log.
+type CommandRecord struct {
+	// Op is the program and its subcommand, e.g. "tmux list-panes".
+	Op       string
+	Duration time.Duration
+	// Exit is the exit status, or -1 when the command did not run to
+	// completion (not found, killed).
+	Exit int
+}
+
+// Output runs cmd like cmd.Output and, while commands are audited, records
+// the program and subcommand, its duration, and its exit status at Info
+// level. The remaining arguments are left out: they can carry keystrokes sent
+// to panes and commit messages.
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	if !commandAudit.Load() {
+		return cmd.Output()
+	}
+	start := time.Now()
+	output, err := cmd.Output()
+	logCommand(cmd, time.Since(start), err)
+	return output, err
+}
+
+func logCommand(cmd *exec.Cmd, elapsed time.Duration, err error) {
+	exit := -1
+	if cmd.ProcessState != nil {
+		exit = cmd.ProcessState.ExitCode()
+	}
+	attrs := []any{
+		"op", commandOp(cmd.Args),
+		"duration", elapsed,
+		"exit", exit,
+	}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		attrs = append(attrs, "err", err)
+	}
+	slog.Info(commandMessage, attrs...)
+}
+
+// commandOp names a command line by its program and subcommand, skipping
+// global options.
+func commandOp(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	op := filepath.Base(args[0])
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if valueOptions[arg] {
+			i++
+			continue
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return op + " " + arg
+		}
+	}
+	return op
+}
+
+// ReadCommandRecords returns the external command records of a log written
+// by Setup, in order. Other lines are skipped.
+func ReadCommandRecords(r io.Reader) ([]CommandRecord, error) {
+	var records []CommandRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := parseLogLine(scanner.Text())
+		if fields["msg"] != commandMessage || fields["op"] == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(fields["duration"])
+		if err != nil {
+			continue
+		}
+		exit, err := strconv.Atoi(fields["exit"])
+		if err != nil {
+			continue
+		}
+		records = append(records, CommandRecord{Op: fields["op"], Duration: duration, Exit: exit})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	return records, nil
+}
+
+// parseLogLine splits a slog text line into its key=value fields, unquoting
+// quoted values. Malformed input ends the line early.
+func parseLogLine(line string) map[string]string {
+	fields := make(map[string]string)
+	for line != "" {
+		line = strings.TrimLeft(line, " ")
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.Contains(key, " ") {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				break
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+		fields[key] = value
+		line = rest
+	}
+	return fields
+}
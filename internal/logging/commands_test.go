@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCommandOp(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"tmux", "list-panes", "-a", "-F", "#{pane_id}"}, "tmux list-panes"},
+		{[]string{"/usr/bin/git", "-C", "/src/app", "rev-parse", "--show-toplevel"}, "git rev-parse"},
+		{[]string{"zellij", "--session", "work", "action", "dump-screen"}, "zellij action"},
+		{[]string{"tmux", "-V"}, "tmux"},
+	}
+	for _, tt := range tests {
+		if got := commandOp(tt.args); got != tt.want {
+			t.Errorf("commandOp(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestOutput_RecordsCommandsWhileAudited(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	t.Cleanup(func() {
+		slog.SetDefault(prev)
+		commandAudit.Store(false)
+	})
+
+	commandAudit.Store(false)
+	if _, err := Output(exec.Command("sh", "-c", "true")); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("unaudited command was logged: %q", buf.String())
+	}
+
+	commandAudit.Store(true)
+	output, err := Output(exec.Command("sh", "-c", "echo hi"))
+	if err != nil || strings.TrimSpace(string(output)) != "hi" {
+		t.Fatalf("Output() = %q, %v, want hi", output, err)
+	}
+	if _, err := Output(exec.Command("sh", "-c", "exit 3")); err == nil {
+		t.Fatal("Output() error = nil, want exit status 3")
+	}
+	if strings.Contains(buf.String(), "echo hi") {
+		t.Fatalf("command arguments were logged: %q", buf.String())
+	}
+	buf.WriteString("time=2026-10-16T10:00:00Z level=DEBUG msg=\"discovery done\" projects=2\n")
+
+	records, err := ReadCommandRecords(&buf)
+	if err != nil {
+		t.Fatalf("ReadCommandRecords() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want 2", records)
+	}
+	if records[0].Op != "sh" || records[0].Exit != 0 || records[0].Duration <= 0 {
+		t.Errorf("records[0] = %+v, want a successful sh run", records[0])
+	}
+	if records[1].Exit != 3 {
+		t.Errorf("records[1].Exit = %d, want 3", records[1].Exit)
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	line := `time=2026-10-16T10:00:00Z level=INFO msg=exec op="tmux list-panes" args="list-panes -a -F \"#{pane_id}\"" duration=1.5ms exit=0`
+	fields := parseLogLine(line)
+	want := map[string]string{
+		"msg":      "exec",
+		"op":       "tmux list-panes",
+		"args":     `list-panes -a -F "#{pane_id}"`,
+		"duration": "1.5ms",
+		"exit":     "0",
+	}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("fields[%q] = %q, want %q", key, fields[key], value)
+		}
+	}
+}
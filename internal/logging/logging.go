@@ -7,23 +7,28 @@ import (
 	"path/filepath"
 )
 
-const debugLogPath = "/tmp/cb-debug.log"
-
 // Setup configures the default slog logger.
-// When debug is true, logs at Debug level to /tmp/cb-debug.log.
+// When debug is true, logs at Debug level to the file at path, truncating it.
+// When only logCommands is true, logs at Info level there, which keeps the
+// external command records (see Output) without the debug messages.
 // Otherwise defaults to Warn level on stderr.
-func Setup(debug bool) {
+func Setup(debug, logCommands bool, path string) {
 	level := slog.LevelWarn
 	output := os.Stderr
+	commandAudit.Store(false)
 
-	if debug {
-		level = slog.LevelDebug
-		f, err := os.OpenFile(debugLogPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if debug || logCommands {
+		level = slog.LevelInfo
+		if debug {
+			level = slog.LevelDebug
+		}
+		f, err := openLog(path)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "failed to open debug log %s: %v\n", debugLogPath, err)
+			fmt.Fprintf(os.Stderr, "failed to open debug log %s: %v\n", path, err)
 		} else {
 			output = f
-			fmt.Fprintf(os.Stderr, "debug logs: %s\n", filepath.Clean(debugLogPath))
+			commandAudit.Store(true)
+			fmt.Fprintf(os.Stderr, "debug logs: %s\n", filepath.Clean(path))
 		}
 	}
 
@@ -32,3 +37,10 @@ func Setup(debug bool) {
 	})
 	slog.SetDefault(slog.New(handler))
 }
+
+func openLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+}
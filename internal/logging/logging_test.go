@@ -3,11 +3,12 @@ package logging
 import (
 	"context"
 	"log/slog"
+	"path/filepath"
 	"testing"
 )
 
 func TestSetup_DebugMode(t *testing.T) {
-	Setup(true)
+	Setup(true, false, filepath.Join(t.TempDir(), "cb-debug.log"))
 
 	logger := slog.Default()
 	if !logger.Enabled(context.TODO(), slog.LevelDebug) {
@@ -16,7 +17,7 @@ func TestSetup_DebugMode(t *testing.T) {
 }
 
 func TestSetup_DefaultMode(t *testing.T) {
-	Setup(false)
+	Setup(false, false, "")
 
 	logger := slog.Default()
 	if logger.Enabled(context.TODO(), slog.LevelInfo) {
@@ -0,0 +1,50 @@
+// Package multiplexer defines the terminal multiplexer surface ClawdBay
+// drives: sessions, windows, panes, attaching, sending keys, capturing
+// output, and agent detection. tmux.Client is the default implementation;
+// other backends and test fakes implement the same interface so the
+// dashboard and discovery do not depend on tmux directly.
+package multiplexer
+
+import "github.com/ronsanzone/clawd-bay/internal/tmux"
+
+// Multiplexer is a terminal multiplexer backend. Targets address a window as
+// returned by tmux.Window.Target, or a pane by its ID.
+type Multiplexer interface {
+	// ListSessions returns the ClawdBay-managed sessions.
+	ListSessions() ([]tmux.Session, error)
+	// ListAllSessions returns every session, managed or not.
+	ListAllSessions() ([]tmux.Session, error)
+	HasSession(name string) bool
+	CreateSession(name, workdir string) error
+	RenameSession(oldName, newName string) error
+	KillSession(name string) error
+	GetSessionOption(session, key string) (string, error)
+	SetSessionOption(session, key, value string) error
+	// GetPaneWorkingDir returns the working directory of the session's first
+	// pane, or "" when unknown.
+	GetPaneWorkingDir(session string) string
+
+	ListWindows(session string) ([]tmux.Window, error)
+	CreateWindow(session, name, command string) error
+	CreateWindowWithShellInDir(session, name, command, workdir string) error
+	RenameWindow(session, name string) error
+	SelectWindow(target string) error
+	// GetWindowWorkingDir returns the working directory of the window at
+	// windowIndex, or "" when unknown.
+	GetWindowWorkingDir(session string, windowIndex int) string
+
+	ListPanes(target string) ([]tmux.Pane, error)
+	SendText(target, text string) error
+	SendCommandToPane(target, command string) error
+	CapturePane(target string, history int) (string, error)
+
+	// AttachOrSwitchToSession attaches to name, or switches the current
+	// client to it when already inside the multiplexer.
+	AttachOrSwitchToSession(name string, inMultiplexer bool) error
+
+	DetectAgentInfo(target string) tmux.AgentInfo
+	ListSessionWindowInfo() ([]tmux.SessionWindowInfo, error)
+	AgentProcessStats(target string) (tmux.AgentProcess, bool)
+}
+
+var _ Multiplexer = (*tmux.Client)(nil)
@@ -0,0 +1,161 @@
+// Package queue persists per-session prompt queues that cb queue watch feeds
+// to agents one prompt at a time.
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const fileVersion = 1
+
+// Prompt is one queued prompt.
+type Prompt struct {
+	Text    string    `json:"text"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+type file struct {
+	Version int                 `json:"version"`
+	Queues  map[string][]Prompt `json:"queues"`
+}
+
+// Store reads and writes the queue file at a fixed path.
+type Store struct {
+	path string
+}
+
+// NewStore creates a Store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the queue file location.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load returns the non-empty queues keyed by session name. A missing file
+// yields no queues.
+func (s *Store) Load() (map[string][]Prompt, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string][]Prompt{}, nil
+		}
+		return nil, fmt.Errorf("failed to read prompt queue %s: %w", s.path, err)
+	}
+
+	var f file
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt queue %s: %w", s.path, err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported prompt queue version %d in %s", f.Version, s.path)
+	}
+	if f.Queues == nil {
+		f.Queues = map[string][]Prompt{}
+	}
+	return f.Queues, nil
+}
+
+// Sessions returns the names of sessions with queued prompts, sorted.
+func (s *Store) Sessions() ([]string, error) {
+	queues, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(queues))
+	for name := range queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Add appends prompt to the end of session's queue and returns the queue length.
+func (s *Store) Add(session string, prompt Prompt) (int, error) {
+	queues, err := s.Load()
+	if err != nil {
+		return 0, err
+	}
+	queues[session] = append(queues[session], prompt)
+	if err := s.save(queues); err != nil {
+		return 0, err
+	}
+	return len(queues[session]), nil
+}
+
+// Pop removes and returns the first prompt in session's queue. It reports
+// false when the queue is empty.
+func (s *Store) Pop(session string) (Prompt, bool, error) {
+	queues, err := s.Load()
+	if err != nil {
+		return Prompt{}, false, err
+	}
+	pending := queues[session]
+	if len(pending) == 0 {
+		return Prompt{}, false, nil
+	}
+	queues[session] = pending[1:]
+	if err := s.save(queues); err != nil {
+		return Prompt{}, false, err
+	}
+	return pending[0], true, nil
+}
+
+// Clear drops session's queue and returns how many prompts it held.
+func (s *Store) Clear(session string) (int, error) {
+	queues, err := s.Load()
+	if err != nil {
+		return 0, err
+	}
+	n := len(queues[session])
+	if n == 0 {
+		return 0, nil
+	}
+	delete(queues, session)
+	return n, s.save(queues)
+}
+
+func (s *Store) save(queues map[string][]Prompt) error {
+	f := file{Version: fileVersion, Queues: make(map[string][]Prompt, len(queues))}
+	for name, pending := range queues {
+		if len(pending) > 0 {
+			f.Queues[name] = pending
+		}
+	}
+
+	content, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode prompt queue: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create prompt queue directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "queue-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp prompt queue file in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(append(content, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temp prompt queue file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp prompt queue file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace prompt queue %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStore_LoadMissingFile(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "queue.json"))
+
+	queues, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(queues) != 0 {
+		t.Fatalf("Load() = %v, want empty", queues)
+	}
+}
+
+func TestStore_AddPopClear(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", "queue.json"))
+	added := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := Prompt{Text: "write tests", AddedAt: added}
+	second := Prompt{Text: "fix lint", AddedAt: added}
+
+	for i, p := range []Prompt{first, second} {
+		n, err := store.Add("cb_a", p)
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		if n != i+1 {
+			t.Fatalf("Add() length = %d, want %d", n, i+1)
+		}
+	}
+	if _, err := store.Add("cb_b", first); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	sessions, err := store.Sessions()
+	if err != nil {
+		t.Fatalf("Sessions() error = %v", err)
+	}
+	if !reflect.DeepEqual(sessions, []string{"cb_a", "cb_b"}) {
+		t.Fatalf("Sessions() = %v", sessions)
+	}
+
+	got, ok, err := store.Pop("cb_a")
+	if err != nil || !ok || got != first {
+		t.Fatalf("Pop() = %+v, %v, %v; want %+v", got, ok, err, first)
+	}
+	got, ok, err = store.Pop("cb_a")
+	if err != nil || !ok || got != second {
+		t.Fatalf("Pop() = %+v, %v, %v; want %+v", got, ok, err, second)
+	}
+	if _, ok, err := store.Pop("cb_a"); err != nil || ok {
+		t.Fatalf("Pop() on empty queue = %v, %v; want false", ok, err)
+	}
+
+	n, err := store.Clear("cb_b")
+	if err != nil || n != 1 {
+		t.Fatalf("Clear() = %d, %v; want 1", n, err)
+	}
+	queues, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(queues) != 0 {
+		t.Fatalf("Load() = %v, want empty queues dropped", queues)
+	}
+}
+
+func TestStore_RejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	if err := os.WriteFile(path, []byte(`{"version": 9, "queues": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewStore(path).Load(); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
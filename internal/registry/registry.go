@@ -0,0 +1,184 @@
+// Package registry persists the set of ClawdBay-managed tmux sessions so they
+// can be recreated after tmux (or the machine) restarts.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const fileVersion = 1
+
+// Session is the restorable description of one managed tmux session.
+type Session struct {
+	Name      string    `json:"name"`
+	HomePath  string    `json:"home_path"`
+	Windows   []Window  `json:"windows"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Window is one window of a managed session. Agent is the detected agent
+// type (see tmux.AgentType) or empty when no agent was running. Layout is the
+// tmux window_layout string, recorded when the window had Panes > 1.
+type Window struct {
+	Index  int    `json:"index"`
+	Name   string `json:"name"`
+	Agent  string `json:"agent,omitempty"`
+	Panes  int    `json:"panes,omitempty"`
+	Layout string `json:"layout,omitempty"`
+}
+
+type file struct {
+	Version  int       `json:"version"`
+	Sessions []Session `json:"sessions"`
+}
+
+// Store reads and writes the registry file at a fixed path.
+type Store struct {
+	path string
+}
+
+// NewStore creates a Store backed by the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the registry file location.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Load returns all recorded sessions sorted by name. A missing file yields
+// an empty registry.
+func (s *Store) Load() ([]Session, error) {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Session{}, nil
+		}
+		return nil, fmt.Errorf("failed to read session registry %s: %w", s.path, err)
+	}
+
+	var f file
+	if err := json.Unmarshal(content, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse session registry %s: %w", s.path, err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported session registry version %d in %s", f.Version, s.path)
+	}
+	sortSessions(f.Sessions)
+	return f.Sessions, nil
+}
+
+// Upsert records sessions, replacing existing entries with the same name.
+func (s *Store) Upsert(sessions ...Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	existing, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]Session, len(existing)+len(sessions))
+	for _, sess := range existing {
+		byName[sess.Name] = sess
+	}
+	for _, sess := range sessions {
+		byName[sess.Name] = sess
+	}
+	return s.save(byName)
+}
+
+// Remove deletes the named sessions from the registry. Unknown names are ignored.
+func (s *Store) Remove(names ...string) error {
+	existing, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]Session, len(existing))
+	for _, sess := range existing {
+		byName[sess.Name] = sess
+	}
+	changed := false
+	for _, name := range names {
+		if _, ok := byName[name]; ok {
+			delete(byName, name)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return s.save(byName)
+}
+
+// Rename moves the entry recorded as oldName to newName, replacing any entry
+// already named newName. An unknown oldName is ignored.
+func (s *Store) Rename(oldName, newName string) error {
+	existing, err := s.Load()
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]Session, len(existing))
+	for _, sess := range existing {
+		byName[sess.Name] = sess
+	}
+	sess, ok := byName[oldName]
+	if !ok {
+		return nil
+	}
+	delete(byName, oldName)
+	sess.Name = newName
+	byName[newName] = sess
+	return s.save(byName)
+}
+
+func (s *Store) save(byName map[string]Session) error {
+	f := file{Version: fileVersion, Sessions: make([]Session, 0, len(byName))}
+	for _, sess := range byName {
+		f.Sessions = append(f.Sessions, sess)
+	}
+	sortSessions(f.Sessions)
+
+	content, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session registry: %w", err)
+	}
+
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create registry directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "sessions-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temp registry file in %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(append(content, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temp registry file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp registry file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace session registry %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func sortSessions(sessions []Session) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Name < sessions[j].Name
+	})
+}
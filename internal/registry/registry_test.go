@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStore_LoadMissingFile(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "sessions.json"))
+
+	sessions, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Fatalf("Load() = %v, want empty", sessions)
+	}
+}
+
+func TestStore_UpsertAndRemove(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", "sessions.json"))
+	updated := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	first := Session{Name: "cb_b", HomePath: "/repo/.worktrees/b", UpdatedAt: updated}
+	second := Session{
+		Name:      "cb_a",
+		HomePath:  "/repo/.worktrees/a",
+		Windows:   []Window{{Index: 0, Name: "shell"}, {Index: 1, Name: "claude", Agent: "claude"}},
+		UpdatedAt: updated,
+	}
+	if err := store.Upsert(first, second); err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+
+	replaced := first
+	replaced.HomePath = "/elsewhere"
+	if err := store.Upsert(replaced); err != nil {
+		t.Fatalf("Upsert() replace error = %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []Session{second, replaced}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Load() = %+v, want %+v", got, want)
+	}
+
+	if err := store.Remove("cb_a", "cb_missing"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	got, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "cb_b" {
+		t.Fatalf("Load() after Remove = %+v, want only cb_b", got)
+	}
+
+	if err := store.Rename("cb_b", "cb_c"); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	got, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "cb_c" || got[0].HomePath != "/elsewhere" {
+		t.Fatalf("Load() after Rename = %+v, want only cb_c", got)
+	}
+}
+
+func TestStore_LoadRejectsUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "sessions": []}`), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := NewStore(path).Load(); err == nil {
+		t.Fatal("Load() error = nil, want version error")
+	}
+}
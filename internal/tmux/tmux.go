@@ -1,12 +1,23 @@
 package tmux
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ronsanzone/clawd-bay/internal/logging"
 )
 
 // Session represents a tmux session.
@@ -15,19 +26,38 @@ type Session struct {
 }
 
 // Window represents a tmux window with its index, name, and active state.
+// ID is tmux's stable window ID (e.g. "@12"); unlike the index and name it
+// survives renames, moves, and duplicate names. Purpose is the window's
+// WindowOptionPurpose, empty unless cb started its agent for a purpose.
 type Window struct {
-	Index  int
-	Name   string
-	Active bool
+	ID      string
+	Index   int
+	Name    string
+	Active  bool
+	Purpose string
+}
+
+// Target returns the tmux target for the window: its ID when known,
+// otherwise "session:index". Targets also key per-window maps.
+func (w Window) Target(session string) string {
+	if w.ID != "" {
+		return w.ID
+	}
+	return fmt.Sprintf("%s:%d", session, w.Index)
 }
 
 // SessionWindowInfo combines session, window, repo, and detected agent metadata.
+// RepoName is the session's git toplevel directory name; Project is the main
+// repository's, which all of its worktrees share, and ProjectPath its path.
 type SessionWindowInfo struct {
 	SessionName string
 	RepoName    string
+	Project     string
+	ProjectPath string
 	Window      Window
 	AgentInfo   AgentInfo
 	Managed     bool
+	Tags        []string
 }
 
 // AgentType identifies which coding agent process is active in a pane.
@@ -40,13 +70,141 @@ const (
 	AgentOpenCode AgentType = "open_code"
 )
 
+// LaunchableAgents lists the agents ClawdBay can start, in menu order.
+var LaunchableAgents = []AgentType{AgentClaude, AgentCodex, AgentOpenCode}
+
+// LaunchCommand returns the shell command that starts the agent, or "" when
+// the agent type is unknown.
+func (a AgentType) LaunchCommand() string {
+	switch a {
+	case AgentClaude:
+		return "claude"
+	case AgentCodex:
+		return "codex"
+	case AgentOpenCode:
+		return "opencode"
+	default:
+		return ""
+	}
+}
+
+// resumeCommands overrides ResumeCommand per agent; see SetResumeCommands.
+var resumeCommands = map[AgentType]string{}
+
+// SetResumeCommands replaces the configured resume commands. It is meant to
+// be called once at startup, before any agent is launched.
+func SetResumeCommands(commands map[AgentType]string) {
+	resumeCommands = commands
+}
+
+// ResumeCommand returns the shell command that starts the agent on its most
+// recent conversation in the current directory, or "" when the agent type
+// is unknown. Configured commands take precedence over the built-in ones.
+func (a AgentType) ResumeCommand() string {
+	if command, ok := resumeCommands[a]; ok {
+		return command
+	}
+	switch a {
+	case AgentClaude:
+		return "claude --continue"
+	case AgentCodex:
+		return "codex resume --last"
+	case AgentOpenCode:
+		return "opencode --continue"
+	default:
+		return ""
+	}
+}
+
 const SessionOptionHomePath = "@cb_home_path"
 
-// AgentInfo bundles the detected agent and its current status.
+// SessionOptionNote holds a session's freeform note (see cb note).
+const SessionOptionNote = "@cb_note"
+
+// SessionOptionTags holds a session's comma-separated tags (see cb tag).
+const SessionOptionTags = "@cb_tags"
+
+// Session options cb records when it creates a session, so the session's
+// origin does not have to be inferred from where its panes are.
+// SessionOptionCreatedAt is the unix time of creation, SessionOptionAgent the
+// agent cb launched in it (WindowOptionAgent holds each window's detected
+// agent), and SessionOptionProject its project's display name.
+const (
+	SessionOptionBranch    = "@cb_branch"
+	SessionOptionCreatedAt = "@cb_created_at"
+	SessionOptionAgent     = "@cb_session_agent"
+	SessionOptionProject   = "@cb_project"
+)
+
+// sessionOptionKeys are the session options ListSessionOptions reads.
+var sessionOptionKeys = []string{
+	SessionOptionHomePath, SessionOptionNote, SessionOptionTags,
+	SessionOptionBranch, SessionOptionCreatedAt, SessionOptionAgent, SessionOptionProject,
+}
+
+// Window options recording a window's last agent detection, so other cb
+// commands and tmux formats can read it instead of detecting again.
+// WindowOptionChecked is the unix time of the check,
+// WindowOptionStatusDetail the WaitingReason of a WAITING agent or the
+// LimitReset of a LIMITED one, and WindowOptionDetector the DetectorVersion
+// that made it.
+const (
+	WindowOptionStatus       = "@cb_status"
+	WindowOptionAgent        = "@cb_agent"
+	WindowOptionStatusDetail = "@cb_status_detail"
+	WindowOptionChecked      = "@cb_checked"
+	WindowOptionDetector     = "@cb_detector"
+)
+
+// DetectorVersion identifies how agent detection reads a window. Detections
+// recorded by another version are detected again rather than reused, so bump
+// it whenever detection changes what it reports for the same pane.
+const DetectorVersion = "1"
+
+// WindowOptionPurpose records the purpose cb started a window's agent for
+// (see config.RenderAgentWindowName), so only windows cb named for a purpose
+// are shown by it.
+const WindowOptionPurpose = "@cb_purpose"
+
+// AgentInfo bundles the detected agent and its current status. LimitReset
+// is when a LIMITED agent's quota resets, as worded in its message (e.g.
+// "3pm (America/New_York)" or "in 2 days 3 hours"); empty when unknown.
+// WaitingReason says what a WAITING agent is waiting for.
 type AgentInfo struct {
-	Type     AgentType
-	Detected bool
-	Status   Status
+	Type          AgentType
+	Detected      bool
+	Status        Status
+	LimitReset    string
+	WaitingReason WaitingReason
+}
+
+// WaitingReason says what a WAITING agent needs from the user.
+type WaitingReason string
+
+const (
+	// WaitingPermission is a permission dialog ("Yes, allow once").
+	WaitingPermission WaitingReason = "permission"
+	// WaitingQuestion is a confirmation or choice prompt ("Continue? (y/n)").
+	WaitingQuestion WaitingReason = "question"
+	// WaitingInput is the agent at its input prompt, ready for the next
+	// instruction.
+	WaitingInput WaitingReason = "input"
+)
+
+// Urgency orders reasons by how soon the user should answer: a permission
+// dialog blocks work mid-task, a question less so, and an input prompt means
+// the task is finished. Unknown reasons rank lowest.
+func (r WaitingReason) Urgency() int {
+	switch r {
+	case WaitingPermission:
+		return 3
+	case WaitingQuestion:
+		return 2
+	case WaitingInput:
+		return 1
+	default:
+		return 0
+	}
 }
 
 // Status represents a coding agent session's current state.
@@ -61,6 +219,16 @@ const (
 	StatusIdle Status = "IDLE"
 	// StatusDone indicates the agent has exited or the session is complete.
 	StatusDone Status = "DONE"
+	// StatusError indicates the agent crashed or failed: its window fell
+	// back to the shell with an error (a stack trace, "command not found")
+	// on screen.
+	StatusError Status = "ERROR"
+	// StatusLimited indicates the agent is stalled on a usage or rate limit
+	// until its quota resets.
+	StatusLimited Status = "LIMITED"
+	// StatusUnknown indicates an agent is running but its state cannot be
+	// read, as for agents in unfocused zellij tabs.
+	StatusUnknown Status = "UNKNOWN"
 )
 
 var agentProcessSignatures = []struct {
@@ -74,19 +242,149 @@ var agentProcessSignatures = []struct {
 
 // Client provides tmux operations.
 type Client struct {
+	// ctx kills non-interactive commands once done (see WithContext).
+	ctx context.Context
+	// execCommand replaces running non-interactive commands under ctx; nil
+	// outside of tests.
 	execCommand     func(name string, args ...string) ([]byte, error)
 	execInteractive func(name string, args ...string) error
+	// captures memoizes status captures for one refresh; nil outside of
+	// ForRefresh.
+	captures *paneCaptures
+	// allPanes lists the panes of all windows once per refresh; nil outside
+	// of ForRefresh.
+	allPanes *paneListing
+	// detections remembers each window's last detection across refreshes;
+	// nil disables reuse.
+	detections *detectionCache
+	// recordDetections publishes detections as window options (see
+	// WindowOptionStatus) and reuses fresh ones recorded by other clients.
+	recordDetections bool
+	// repoRoots remembers the git toplevel of each pane directory across
+	// refreshes; nil disables reuse.
+	repoRoots *repoRootCache
 }
 
 // NewClient creates a Client that executes real tmux commands.
 func NewClient() *Client {
+	return NewClientWithContext(context.Background())
+}
+
+// NewClientWithContext creates a Client whose non-interactive commands are
+// killed once ctx is done, so callers can cancel in-flight queries.
+func NewClientWithContext(ctx context.Context) *Client {
 	return &Client{
-		execCommand: func(name string, args ...string) ([]byte, error) {
-			return exec.Command(name, args...).Output()
-		},
+		ctx: ctx,
 		execInteractive: func(name string, args ...string) error {
 			return runInteractiveCommand(name, args...)
 		},
+		detections:       &detectionCache{byWindow: make(map[string]cachedDetection)},
+		recordDetections: true,
+		repoRoots:        &repoRootCache{byDir: make(map[string]string)},
+	}
+}
+
+// WithContext returns a copy of c whose non-interactive commands are killed
+// once ctx is done, instead of when c's own context is. Caches are shared with
+// c, so a copy can be taken for every refresh. A nil c stays nil.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if c == nil {
+		return nil
+	}
+	rc := *c
+	rc.ctx = ctx
+	return &rc
+}
+
+// run runs a non-interactive command, killing it once c's context is done.
+func (c *Client) run(name string, args ...string) ([]byte, error) {
+	if c.execCommand != nil {
+		return c.execCommand(name, args...)
+	}
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return logging.Output(exec.CommandContext(ctx, name, args...))
+}
+
+// ForRefresh returns a copy of c for one refresh cycle: the pane captures
+// status detection reads are memoized by target, so each pane is captured at
+// most once however many windows, sessions, or lookups reach it, and the
+// panes of all windows are listed in one call. Neither is invalidated, so
+// take a new copy for every cycle. A nil c stays nil.
+func (c *Client) ForRefresh() *Client {
+	if c == nil {
+		return nil
+	}
+	rc := *c
+	rc.captures = &paneCaptures{byTarget: make(map[string]paneCapture)}
+	rc.allPanes = &paneListing{}
+	return &rc
+}
+
+// paneCaptures holds the capture-pane results of one refresh cycle.
+type paneCaptures struct {
+	mu       sync.Mutex
+	byTarget map[string]paneCapture
+}
+
+type paneCapture struct {
+	output []byte
+	err    error
+}
+
+// Sentinel errors returned (wrapped) by Client methods, so callers can branch
+// on the kind of tmux failure with errors.Is.
+var (
+	// ErrNoServer means no tmux server is running.
+	ErrNoServer = errors.New("tmux server not running")
+	// ErrNoSession means the target session does not exist (or none do).
+	ErrNoSession = errors.New("tmux session not found")
+	// ErrWindowNotFound means the target window does not exist.
+	ErrWindowNotFound = errors.New("tmux window not found")
+)
+
+// tmux runs a tmux subcommand, classifying failures into sentinel errors.
+func (c *Client) tmux(args ...string) ([]byte, error) {
+	output, err := c.run("tmux", args...)
+	if err != nil {
+		return output, classifyError(err)
+	}
+	return output, nil
+}
+
+// classifyError wraps err with the matching sentinel based on tmux's stderr
+// (or the error text when stderr was not captured), keeping the original
+// error in the chain.
+func classifyError(err error) error {
+	detail := err.Error()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if stderr := strings.TrimSpace(string(exitErr.Stderr)); stderr != "" {
+			detail = stderr
+		}
+	}
+
+	var kind error
+	switch {
+	case strings.Contains(detail, "no server running"), strings.Contains(detail, "error connecting to"):
+		kind = ErrNoServer
+	case strings.Contains(detail, "no sessions"), strings.Contains(detail, "can't find session"), strings.Contains(detail, "session not found"):
+		kind = ErrNoSession
+	case strings.Contains(detail, "can't find window"), strings.Contains(detail, "window not found"):
+		kind = ErrWindowNotFound
+	}
+
+	switch {
+	case kind != nil && detail != err.Error():
+		return fmt.Errorf("%w: %w: %s", kind, err, detail)
+	case kind != nil:
+		return fmt.Errorf("%w: %w", kind, err)
+	case detail != err.Error():
+		return fmt.Errorf("%w: %s", err, detail)
+	default:
+		return err
 	}
 }
 
@@ -105,12 +403,10 @@ func newInteractiveCommand(name string, args ...string) *exec.Cmd {
 
 // ListSessions returns all ClawdBay tmux sessions.
 func (c *Client) ListAllSessions() ([]Session, error) {
-	output, err := c.execCommand("tmux", "list-sessions")
+	output, err := c.tmux("list-sessions")
 	if err != nil {
 		// tmux not running or no sessions is expected, return empty list
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "no server running") ||
-			strings.Contains(errMsg, "no sessions") {
+		if errors.Is(err, ErrNoServer) || errors.Is(err, ErrNoSession) {
 			return []Session{}, nil
 		}
 		return nil, fmt.Errorf("failed to list tmux sessions: %w", err)
@@ -140,12 +436,10 @@ func (c *Client) ListAllSessions() ([]Session, error) {
 
 // ListSessions returns all ClawdBay tmux sessions.
 func (c *Client) ListSessions() ([]Session, error) {
-	output, err := c.execCommand("tmux", "list-sessions")
+	output, err := c.tmux("list-sessions")
 	if err != nil {
 		// tmux not running or no sessions is expected, return empty list
-		errMsg := err.Error()
-		if strings.Contains(errMsg, "no server running") ||
-			strings.Contains(errMsg, "no sessions") {
+		if errors.Is(err, ErrNoServer) || errors.Is(err, ErrNoSession) {
 			return []Session{}, nil
 		}
 		return nil, fmt.Errorf("failed to list tmux sessions: %w", err)
@@ -155,15 +449,79 @@ func (c *Client) ListSessions() ([]Session, error) {
 
 // ListWindows returns all windows in the given session.
 func (c *Client) ListWindows(session string) ([]Window, error) {
-	output, err := c.execCommand("tmux", "list-windows", "-t", session, "-F", "#{window_index}:#{window_name}:#{window_active}")
+	output, err := c.tmux("list-windows", "-t", session, "-F", "#{window_id}:#{window_index}:#{window_name}:#{window_active}\t#{"+WindowOptionPurpose+"}")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list windows for %s: %w", session, err)
 	}
 	return ParseWindowList(string(output)), nil
 }
 
+// WindowLayout is a window's pane count and tmux layout string.
+type WindowLayout struct {
+	Panes  int
+	Layout string
+}
+
+// ListWindowLayouts returns the pane layout of every window in session, keyed
+// by window index.
+func (c *Client) ListWindowLayouts(session string) (map[int]WindowLayout, error) {
+	output, err := c.tmux("list-windows", "-t", session, "-F", "#{window_index}\t#{window_panes}\t#{window_layout}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list window layouts for %s: %w", session, err)
+	}
+	return ParseWindowLayouts(string(output)), nil
+}
+
+// ParseWindowLayouts parses tab-separated "index, panes, layout" lines.
+func ParseWindowLayouts(output string) map[int]WindowLayout {
+	layouts := make(map[int]WindowLayout)
+	for line := range strings.SplitSeq(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		panes, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		layouts[index] = WindowLayout{Panes: panes, Layout: fields[2]}
+	}
+	return layouts
+}
+
+// SplitWindow adds a pane to the named window, starting in workdir when set,
+// and returns the new pane's ID. Focus stays on the current pane.
+func (c *Client) SplitWindow(session, window, workdir string) (string, error) {
+	args := []string{"split-window", "-d", "-P", "-F", "#{pane_id}", "-t", session + ":" + window}
+	if workdir != "" {
+		args = append(args, "-c", workdir)
+	}
+	output, err := c.tmux(args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to split window %s:%s: %w", session, window, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SelectLayout applies a tmux layout string to the named window.
+func (c *Client) SelectLayout(session, window, layout string) error {
+	if _, err := c.tmux("select-layout", "-t", session+":"+window, layout); err != nil {
+		return fmt.Errorf("failed to apply layout to %s:%s: %w", session, window, err)
+	}
+	return nil
+}
+
 // ListSessionWindowInfo returns all windows across all tmux sessions with agent detection metadata.
+// The call is one refresh cycle: a pane reached through linked windows is
+// captured once (see ForRefresh).
 func (c *Client) ListSessionWindowInfo() ([]SessionWindowInfo, error) {
+	if c.captures == nil {
+		c = c.ForRefresh()
+	}
 	sessions, err := c.ListAllSessions()
 	if err != nil {
 		return nil, err
@@ -171,26 +529,73 @@ func (c *Client) ListSessionWindowInfo() ([]SessionWindowInfo, error) {
 
 	rows := make([]SessionWindowInfo, 0)
 	for _, s := range sessions {
-		repoName := c.GetRepoName(s.Name)
+		repoName, project, projectDir := "Unknown", "", ""
+		if repoRoot := c.getRepoRoot(s.Name); repoRoot != "" {
+			projectDir = projectPath(repoRoot)
+			repoName, project = filepath.Base(repoRoot), filepath.Base(projectDir)
+		}
 		wins, winErr := c.ListWindows(s.Name)
 		if winErr != nil {
 			continue
 		}
 
 		managed := strings.HasPrefix(s.Name, "cb_")
+		tags := c.SessionTags(s.Name)
 		for _, w := range wins {
 			rows = append(rows, SessionWindowInfo{
 				SessionName: s.Name,
 				RepoName:    repoName,
+				Project:     project,
+				ProjectPath: projectDir,
 				Window:      w,
-				AgentInfo:   c.DetectAgentInfo(s.Name, w.Name),
+				AgentInfo:   c.DetectAgentInfo(w.Target(s.Name)),
 				Managed:     managed,
+				Tags:        tags,
 			})
 		}
 	}
 	return rows, nil
 }
 
+// ListSessionOptions returns the options cb keeps on sessions (@cb_home_path,
+// @cb_note, and the others above) for every session in one list-sessions
+// call, keyed by session name and then option. Unset options are left out.
+func (c *Client) ListSessionOptions() (map[string]map[string]string, error) {
+	var format strings.Builder
+	format.WriteString("#{session_name}")
+	for _, key := range sessionOptionKeys {
+		format.WriteString("\t#{" + key + "}")
+	}
+	output, err := c.tmux("list-sessions", "-F", format.String())
+	if err != nil {
+		if errors.Is(err, ErrNoServer) || errors.Is(err, ErrNoSession) {
+			return map[string]map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("failed to list tmux session options: %w", err)
+	}
+	return parseSessionOptions(string(output)), nil
+}
+
+// parseSessionOptions parses ListSessionOptions output: a session name
+// followed by the sessionOptionKeys values, tab separated, per line.
+func parseSessionOptions(output string) map[string]map[string]string {
+	all := map[string]map[string]string{}
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != len(sessionOptionKeys)+1 || fields[0] == "" {
+			continue
+		}
+		options := map[string]string{}
+		for i, key := range sessionOptionKeys {
+			if value := strings.TrimSpace(fields[i+1]); value != "" {
+				options[key] = value
+			}
+		}
+		all[fields[0]] = options
+	}
+	return all
+}
+
 // ParseSessionList parses tmux list-sessions output and returns only cb_ prefixed sessions.
 func ParseSessionList(output string) []Session {
 	var sessions []Session
@@ -222,8 +627,9 @@ func ParseSessionList(output string) []Session {
 }
 
 // ParseWindowList parses output from:
-// tmux list-windows -F "#{window_index}:#{window_name}:#{window_active}"
-// Format: "0:shell:1" or "1:claude:default:0"
+// tmux list-windows -F "#{window_id}:#{window_index}:#{window_name}:#{window_active}\t#{@cb_purpose}"
+// Format: "@1:0:shell:1" or "@2:1:claude-review:0\treview". The leading
+// window ID and the trailing purpose are optional.
 func ParseWindowList(output string) []Window {
 	var windows []Window
 	lines := strings.Split(strings.TrimSpace(output), "\n")
@@ -233,6 +639,20 @@ func ParseWindowList(output string) []Window {
 			continue
 		}
 
+		var purpose string
+		if tab := strings.LastIndex(line, "\t"); tab >= 0 {
+			line, purpose = line[:tab], line[tab+1:]
+		}
+
+		var id string
+		if strings.HasPrefix(line, "@") {
+			idEnd := strings.Index(line, ":")
+			if idEnd == -1 {
+				continue
+			}
+			id, line = line[:idEnd], line[idEnd+1:]
+		}
+
 		// Split from the end to handle window names with colons (like "claude:default")
 		// Format: index:name:active where active is 0 or 1
 		lastColon := strings.LastIndex(line, ":")
@@ -255,22 +675,26 @@ func ParseWindowList(output string) []Window {
 		_, _ = fmt.Sscanf(idxStr, "%d", &idx)
 
 		windows = append(windows, Window{
-			Index:  idx,
-			Name:   name,
-			Active: activeStr == "1",
+			ID:      id,
+			Index:   idx,
+			Name:    name,
+			Active:  activeStr == "1",
+			Purpose: purpose,
 		})
 	}
 
 	return windows
 }
 
-func (c *Client) DetectAgentProcess(session, window string) bool {
-	return c.DetectAgentType(session, window) != AgentNone
+// DetectAgentProcess reports whether a coding agent runs in the window at
+// target (see Window.Target).
+func (c *Client) DetectAgentProcess(target string) bool {
+	return c.DetectAgentType(target) != AgentNone
 }
 
-// DetectAgentType returns the detected agent type for a tmux window pane.
-func (c *Client) DetectAgentType(session, window string) AgentType {
-	target := session + ":" + window
+// DetectAgentType returns the detected agent type for the window at target
+// (see Window.Target).
+func (c *Client) DetectAgentType(target string) AgentType {
 	return c.detectAgentTypeForTarget(target)
 }
 
@@ -280,17 +704,70 @@ func (c *Client) detectAgentTypeForTarget(target string) AgentType {
 		slog.Debug("DetectAgentProcess getDisplayMessage failed", "target", target, "err", err)
 		return AgentNone
 	}
+	return c.agentTypeForTTY(paneTty)
+}
 
-	output, err := c.execCommand("ps", "-t", paneTty)
+// agentTypeForTTY classifies the processes on a pane's tty, which covers the
+// pane's whole process tree, so an agent started by a wrapper (npx, node,
+// ...) is found through its own process or its wrapper's command line.
+func (c *Client) agentTypeForTTY(paneTty string) AgentType {
+	output, err := c.run("ps", "-t", paneTty, "-o", "command=")
 	if err != nil {
-		slog.Debug("DetectAgentProcess ps failed", "target", target, "err", err)
+		slog.Debug("DetectAgentProcess ps failed", "tty", paneTty, "err", err)
+		return AgentNone
+	}
+
+	for line := range strings.SplitSeq(string(output), "\n") {
+		if agent := ClassifyCommand(line); agent != AgentNone {
+			return agent
+		}
+	}
+	return AgentNone
+}
+
+// agentWrappers launch the agent named by their first non-flag argument,
+// as in "npx claude", "bunx @openai/codex", or "node .../claude-code/cli.js".
+var agentWrappers = map[string]bool{
+	"npx": true, "npm": true, "bunx": true, "bun": true, "pnpx": true, "pnpm": true, "yarn": true,
+	"node": true, "deno": true, "uvx": true, "python": true, "python3": true,
+}
+
+// wrapperSubcommands are skipped when looking for a wrapper's target, as in
+// "pnpm dlx claude" or "npm exec codex".
+var wrapperSubcommands = map[string]bool{"dlx": true, "exec": true, "x": true, "run": true}
+
+// ClassifyCommand returns the agent a process command line runs: matched on
+// the executable's name, or for a wrapper on the script or package it runs.
+func ClassifyCommand(command string) AgentType {
+	fields := strings.Fields(strings.ToLower(command))
+	if len(fields) == 0 {
 		return AgentNone
 	}
+	name := filepath.Base(fields[0])
+	if !agentWrappers[name] {
+		return agentForSignature(name)
+	}
+	for _, arg := range fields[1:] {
+		if strings.HasPrefix(arg, "-") || wrapperSubcommands[arg] {
+			continue
+		}
+		return agentForSignature(arg)
+	}
+	return AgentNone
+}
+
+// AgentForWindowName returns the agent a window is named after ("claude",
+// "codex-review"), or AgentNone. cb names agent windows this way, so a shell
+// in such a window usually means its agent exited.
+func AgentForWindowName(name string) AgentType {
+	return agentForSignature(strings.ToLower(name))
+}
 
-	processStr := strings.ToLower(strings.TrimSpace(string(output)))
+// agentForSignature returns the agent whose signature s contains.
+func agentForSignature(s string) AgentType {
 	for _, profile := range agentProcessSignatures {
 		for _, sig := range profile.signatures {
-			if strings.Contains(processStr, strings.ToLower(sig)) {
+			if strings.Contains(s, sig) {
 				return profile.agent
 			}
 		}
@@ -298,40 +775,537 @@ func (c *Client) detectAgentTypeForTarget(target string) AgentType {
 	return AgentNone
 }
 
-// DetectAgentInfo returns the detected agent type and derived pane status.
-func (c *Client) DetectAgentInfo(session, window string) AgentInfo {
-	target := session + ":" + window
-	cmd, err := c.getDisplayMessage(target, "#{pane_current_command}")
+// Pane is one pane of a window, as listed by list-panes. WindowName,
+// WindowActivity (the unix time of the window's last output), and Recorded
+// (the detection recorded in its window options, see WindowOptionStatus)
+// describe the pane's window.
+type Pane struct {
+	ID             string
+	Command        string
+	TTY            string
+	WindowActivity int64
+	Recorded       RecordedDetection
+	WindowName     string
+}
+
+// RecordedDetection is a detection recorded in a window's options.
+// CheckedAt is zero when none is.
+type RecordedDetection struct {
+	CheckedAt int64
+	Status    Status
+	Agent     AgentType
+	Detail    string
+	Detector  string
+}
+
+// paneFormat is the tmux format of a Pane, as parsed by parsePaneList.
+const paneFormat = "#{pane_id}\t#{pane_current_command}\t#{pane_tty}\t#{window_activity}" +
+	"\t#{" + WindowOptionChecked + "}\t#{" + WindowOptionStatus + "}\t#{" + WindowOptionAgent + "}\t#{" + WindowOptionStatusDetail + "}" +
+	"\t#{" + WindowOptionDetector + "}\t#{window_name}"
+
+// allPanesFormat prefixes paneFormat with the pane's window, for list-panes -a.
+const allPanesFormat = "#{session_name}\t#{window_index}\t#{window_id}\t" + paneFormat
+
+// ListPanes returns the panes of the window at target (see Window.Target).
+// Within a refresh cycle (see ForRefresh) the panes of every window are read
+// by one list-panes -a call.
+func (c *Client) ListPanes(target string) ([]Pane, error) {
+	if c.allPanes != nil {
+		if panes, ok := c.allPanes.lookup(c, target); ok {
+			return panes, nil
+		}
+	}
+	output, err := c.tmux("list-panes", "-t", target, "-F", paneFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list panes of %s: %w", target, err)
+	}
+	return parsePaneList(string(output)), nil
+}
+
+// paneListing holds the panes of every window of one refresh cycle, keyed
+// by window ID and by "session:index", read on first use.
+type paneListing struct {
+	once     sync.Once
+	byWindow map[string][]Pane
+}
+
+// lookup returns the panes of the window at target, listing all panes with
+// c the first time. ok is false when the listing failed or did not include
+// the window.
+func (l *paneListing) lookup(c *Client, target string) ([]Pane, bool) {
+	l.once.Do(func() {
+		output, err := c.tmux("list-panes", "-a", "-F", allPanesFormat)
+		if err != nil {
+			slog.Debug("ListPanes: list-panes -a failed, listing windows one by one", "err", err)
+			return
+		}
+		l.byWindow = parseAllPanes(string(output))
+	})
+	panes, ok := l.byWindow[target]
+	return panes, ok
+}
+
+// parseAllPanes parses list-panes -a output in allPanesFormat into the panes
+// of each window, keyed by window ID and by "session:index".
+func parseAllPanes(output string) map[string][]Pane {
+	byWindow := map[string][]Pane{}
+	for line := range strings.SplitSeq(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		for _, pane := range parsePaneList(fields[3]) {
+			byWindow[fields[2]] = append(byWindow[fields[2]], pane)
+			byWindow[fields[0]+":"+fields[1]] = append(byWindow[fields[0]+":"+fields[1]], pane)
+		}
+	}
+	return byWindow
+}
+
+// parsePaneList parses paneFormat lines, skipping malformed ones; fields
+// after the tty may be missing. The window name comes last because it may
+// itself contain tabs.
+func parsePaneList(output string) []Pane {
+	var panes []Pane
+	for line := range strings.SplitSeq(strings.TrimSpace(output), "\n") {
+		fields := strings.SplitN(line, "\t", 10)
+		if len(fields) < 3 || !strings.HasPrefix(fields[0], "%") {
+			continue
+		}
+		pane := Pane{ID: fields[0], Command: fields[1], TTY: fields[2]}
+		if len(fields) >= 4 {
+			pane.WindowActivity, _ = strconv.ParseInt(fields[3], 10, 64)
+		}
+		if len(fields) >= 9 {
+			checkedAt, _ := strconv.ParseInt(fields[4], 10, 64)
+			pane.Recorded = RecordedDetection{CheckedAt: checkedAt, Status: Status(fields[5]), Agent: AgentType(fields[6]), Detail: fields[7], Detector: fields[8]}
+		}
+		if len(fields) == 10 {
+			pane.WindowName = fields[9]
+		}
+		panes = append(panes, pane)
+	}
+	return panes
+}
+
+// maxCachedDetections bounds the detection cache; it is cleared when full so
+// closed windows do not accumulate in long-running clients.
+const maxCachedDetections = 1024
+
+// detectionCache holds the last detection of each window, keyed by target,
+// so a window without new output since then is not captured and matched
+// again.
+type detectionCache struct {
+	mu       sync.Mutex
+	byWindow map[string]cachedDetection
+}
+
+type cachedDetection struct {
+	panes     string
+	activity  int64
+	checkedAt int64
+	info      AgentInfo
+}
+
+// paneSignature identifies a window's panes and what runs in them, so a new
+// pane or a changed foreground command invalidates a cached detection.
+func paneSignature(panes []Pane) string {
+	var b strings.Builder
+	for _, p := range panes {
+		b.WriteString(p.ID + "\t" + p.Command + "\t" + p.TTY + "\t" + p.WindowName + "\n")
+	}
+	return b.String()
+}
+
+// lookup returns the cached detection of target if the window has had no
+// output since it was taken. window_activity has one-second resolution, so a
+// detection taken in the same second as the last output is not trusted.
+func (d *detectionCache) lookup(target string, panes []Pane) (AgentInfo, bool) {
+	if d == nil {
+		return AgentInfo{}, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cached, ok := d.byWindow[target]
+	if !ok || cached.panes != paneSignature(panes) || cached.activity != panes[0].WindowActivity || cached.activity >= cached.checkedAt {
+		return AgentInfo{}, false
+	}
+	return cached.info, true
+}
+
+// store records info as the detection of target, taken at checkedAt (unix
+// seconds) while the window looked like panes.
+func (d *detectionCache) store(target string, panes []Pane, checkedAt int64, info AgentInfo) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.byWindow) >= maxCachedDetections {
+		clear(d.byWindow)
+	}
+	d.byWindow[target] = cachedDetection{
+		panes:     paneSignature(panes),
+		activity:  panes[0].WindowActivity,
+		checkedAt: checkedAt,
+		info:      info,
+	}
+}
+
+// DetectAgentInfo returns the detected agent type and derived status for the
+// window at target (see Window.Target). Every pane is inspected, so an agent
+// in a split pane is found too; with several agent panes the window reports
+// the most urgent status (WORKING, then ERROR, WAITING, LIMITED, and IDLE;
+// among WAITING panes, see WaitingReason.Urgency) and that pane's agent
+// type. If the panes cannot be listed only the active pane is checked.
+//
+// A window with no output (window_activity unchanged) and the same panes and
+// commands since its last detection by this client reuses that result
+// instead of capturing and matching the panes again. Failing that, a
+// detection another cb process recorded on the window after its last output
+// is reused; fresh detections are recorded for others in turn.
+func (c *Client) DetectAgentInfo(target string) AgentInfo {
+	panes, err := c.ListPanes(target)
+	if err != nil || len(panes) == 0 {
+		slog.Debug("DetectAgentInfo: list-panes failed, checking active pane", "target", target, "err", err)
+		return c.detectActivePaneInfo(target)
+	}
+	if cached, ok := c.detections.lookup(target, panes); ok {
+		return cached
+	}
+	if recorded, checkedAt, ok := c.recordedAgentInfo(panes[0]); ok {
+		c.detections.store(target, panes, checkedAt, recorded)
+		return recorded
+	}
+
+	checkedAt := time.Now().Unix()
+	best := AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone}
+	for _, pane := range panes {
+		info := c.detectPaneInfo(pane.ID, pane.Command, pane.TTY, pane.WindowName)
+		if info.Detected && (!best.Detected || moreUrgent(info, best)) {
+			best = info
+		}
+	}
+	c.detections.store(target, panes, checkedAt, best)
+	c.recordAgentInfo(target, checkedAt, best)
+	return best
+}
+
+// recordedAgentInfo returns the detection recorded in the window options of
+// pane's window, as listed with it. ok is false when recording is off,
+// nothing was recorded, the record was made by another DetectorVersion, or
+// the window had output in or after the recorded second.
+func (c *Client) recordedAgentInfo(pane Pane) (info AgentInfo, checkedAt int64, ok bool) {
+	recorded := pane.Recorded
+	if !c.recordDetections || recorded.Status == "" || recorded.CheckedAt == 0 || recorded.Detector != DetectorVersion || pane.WindowActivity >= recorded.CheckedAt {
+		return AgentInfo{}, 0, false
+	}
+	info = AgentInfo{Type: recorded.Agent, Status: recorded.Status}
+	info.Detected = info.Type != AgentNone && info.Type != ""
+	switch info.Status {
+	case StatusWaiting:
+		info.WaitingReason = WaitingReason(recorded.Detail)
+	case StatusLimited:
+		info.LimitReset = recorded.Detail
+	}
+	return info, recorded.CheckedAt, true
+}
+
+// recordAgentInfo writes info, detected at checkedAt, to the window options of
+// target in one tmux call. Failures only cost other readers a detection.
+func (c *Client) recordAgentInfo(target string, checkedAt int64, info AgentInfo) {
+	if !c.recordDetections {
+		return
+	}
+	detail := string(info.WaitingReason)
+	if info.Status == StatusLimited {
+		detail = info.LimitReset
+	}
+	values := [][2]string{
+		{WindowOptionStatus, string(info.Status)},
+		{WindowOptionAgent, string(info.Type)},
+		{WindowOptionStatusDetail, detail},
+		{WindowOptionChecked, strconv.FormatInt(checkedAt, 10)},
+		{WindowOptionDetector, DetectorVersion},
+	}
+	var args []string
+	for i, kv := range values {
+		if i > 0 {
+			args = append(args, ";")
+		}
+		args = append(args, "set-option", "-w", "-t", target, kv[0], kv[1])
+	}
+	if _, err := c.tmux(args...); err != nil {
+		slog.Debug("recordAgentInfo: set-option failed", "target", target, "err", err)
+	}
+}
+
+// detectActivePaneInfo detects the agent in the window's active pane only,
+// reading its command, tty, and window name in one display-message call.
+func (c *Client) detectActivePaneInfo(target string) AgentInfo {
+	output, err := c.getDisplayMessage(target, paneFormat)
 	if err != nil {
 		slog.Debug("DetectAgentInfo: getDisplayMessage failed", "target", target, "err", err)
 		return AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone}
 	}
+	panes := parsePaneList(output)
+	if len(panes) == 0 {
+		return AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone}
+	}
+	return c.detectPaneInfo(target, panes[0].Command, panes[0].TTY, panes[0].WindowName)
+}
 
+// detectPaneInfo classifies one pane from its current command and tty;
+// target addresses the pane for capture-pane. windowName, when known, lets a
+// shell pane in a window named after an agent be reported as a crashed agent.
+func (c *Client) detectPaneInfo(target, command, paneTty, windowName string) AgentInfo {
 	// If the pane is running a shell, no coding agent is active.
-	if cmd == "zsh" || cmd == "bash" || cmd == "sh" {
+	if isShellCommand(command) {
+		return c.detectExitedAgent(target, windowName)
+	}
+
+	agentType := c.agentTypeForTTY(paneTty)
+	if agentType == AgentNone {
 		return AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone}
 	}
 
-	agentType := c.detectAgentTypeForTarget(target)
+	info := c.detectAgentActivity(target)
+	info.Type = agentType
+	info.Detected = true
+	return info
+}
+
+// detectExitedAgent reports an agent window whose agent has exited back to
+// the shell with an error on screen (a stack trace, "command not found") as
+// ERROR. Other shell panes have no agent. Only windows named after an agent
+// (as cb names the windows it launches agents in) are captured, so plain
+// shells cost no extra tmux call.
+func (c *Client) detectExitedAgent(target, windowName string) AgentInfo {
+	agentType := AgentForWindowName(windowName)
 	if agentType == AgentNone {
 		return AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone}
 	}
+	output, err := c.captureTail(target)
+	if err != nil || !hasErrorIndicator(string(output)) {
+		return AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone}
+	}
+	return AgentInfo{Type: agentType, Detected: true, Status: StatusError}
+}
+
+// moreUrgent reports whether a outranks b by status, or by waiting reason
+// when both are WAITING.
+func moreUrgent(a, b AgentInfo) bool {
+	if a.Status != b.Status {
+		return statusRank(a.Status) > statusRank(b.Status)
+	}
+	return a.WaitingReason.Urgency() > b.WaitingReason.Urgency()
+}
+
+func isShellCommand(cmd string) bool {
+	return cmd == "zsh" || cmd == "bash" || cmd == "sh"
+}
+
+// RollupStatus returns the most active of statuses, as shown for a window of
+// several panes, a session of several windows, or a group of sessions:
+// WORKING over ERROR over WAITING over LIMITED over IDLE over UNKNOWN over
+// DONE. It is DONE when statuses is empty.
+func RollupStatus(statuses []Status) Status {
+	rolled := StatusDone
+	for _, s := range statuses {
+		if statusRank(s) > statusRank(rolled) {
+			rolled = s
+		}
+	}
+	return rolled
+}
 
-	return AgentInfo{
-		Type:     agentType,
-		Detected: true,
-		Status:   c.detectAgentActivity(target),
+// statusRank orders statuses for RollupStatus; unrecognized statuses rank
+// with DONE.
+func statusRank(status Status) int {
+	switch status {
+	case StatusWorking:
+		return 6
+	case StatusError:
+		return 5
+	case StatusWaiting:
+		return 4
+	case StatusLimited:
+		return 3
+	case StatusIdle:
+		return 2
+	case StatusUnknown:
+		return 1
+	default:
+		return 0
 	}
 }
 
-// GetPaneStatus detects if an agent session is IDLE, WORKING, WAITING, or DONE.
-func (c *Client) GetPaneStatus(session, window string) Status {
-	return c.DetectAgentInfo(session, window).Status
+// GetPaneStatus detects if an agent session is IDLE, WORKING, WAITING,
+// LIMITED, ERROR, or DONE.
+func (c *Client) GetPaneStatus(target string) Status {
+	return c.DetectAgentInfo(target).Status
+}
+
+// AgentProcess holds resource usage for the agent processes in a pane.
+// CPUTime and RSSKB are summed over every matching process (agents often fork
+// helpers); PID, Elapsed, and Command describe the first one. CPUTime is
+// cumulative; CPU, the percentage used recently, is left for callers to
+// derive from two samples, since ps's own pcpu is a lifetime average.
+type AgentProcess struct {
+	PID     int
+	CPU     float64
+	CPUTime time.Duration
+	RSSKB   int64
+	Elapsed time.Duration
+	Command string
+}
+
+// AgentProcessStats returns resource usage for the agent processes running in
+// the window at target (see Window.Target). ok is false when no agent process
+// was found.
+func (c *Client) AgentProcessStats(target string) (AgentProcess, bool) {
+	paneTty, err := c.getDisplayMessage(target, "#{pane_tty}")
+	if err != nil {
+		slog.Debug("AgentProcessStats getDisplayMessage failed", "target", target, "err", err)
+		return AgentProcess{}, false
+	}
+	output, err := c.run("ps", "-t", paneTty, "-o", "pid=,time=,rss=,etime=,command=")
+	if err != nil {
+		slog.Debug("AgentProcessStats ps failed", "target", target, "err", err)
+		return AgentProcess{}, false
+	}
+	return parseProcessStats(string(output))
+}
+
+// parseProcessStats parses "pid time rss etime command" lines from ps and
+// aggregates the ones whose command matches an agent signature.
+func parseProcessStats(output string) (AgentProcess, bool) {
+	var proc AgentProcess
+	found := false
+	for line := range strings.SplitSeq(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 5 {
+			continue
+		}
+		command := strings.Join(fields[4:], " ")
+		if !matchesAgentSignature(command) {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		cpuTime, err := parseCPUTime(fields[1])
+		if err != nil {
+			continue
+		}
+		rss, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		proc.CPUTime += cpuTime
+		proc.RSSKB += rss
+		if !found {
+			proc.PID = pid
+			proc.Command = command
+			proc.Elapsed, _ = parseElapsed(fields[3])
+			found = true
+		}
+	}
+	return proc, found
+}
+
+func matchesAgentSignature(command string) bool {
+	return ClassifyCommand(command) != AgentNone
+}
+
+// parseElapsed parses ps's etime format, [[dd-]hh:]mm:ss. etimes (plain
+// seconds) would be simpler but is missing from the BSD ps on macOS.
+func parseElapsed(etime string) (time.Duration, error) {
+	var days int
+	if d, rest, ok := strings.Cut(etime, "-"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, fmt.Errorf("invalid elapsed time %q", etime)
+		}
+		days, etime = n, rest
+	}
+	parts := strings.Split(etime, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid elapsed time %q", etime)
+	}
+	seconds := 0
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return 0, fmt.Errorf("invalid elapsed time %q", etime)
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds)*time.Second, nil
+}
+
+// parseCPUTime parses ps's time column: [dd-]hh:mm:ss on Linux, and
+// mm:ss.cc (minutes unbounded) on macOS.
+func parseCPUTime(cputime string) (time.Duration, error) {
+	whole, frac, hasFrac := strings.Cut(cputime, ".")
+	d, err := parseElapsed(whole)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cpu time %q", cputime)
+	}
+	if hasFrac {
+		hundredths, err := strconv.Atoi(frac)
+		if err != nil || len(frac) != 2 {
+			return 0, fmt.Errorf("invalid cpu time %q", cputime)
+		}
+		d += time.Duration(hundredths) * 10 * time.Millisecond
+	}
+	return d, nil
+}
+
+// CapturePane returns the visible content of the pane at target plus up to
+// history lines of scrollback.
+func (c *Client) CapturePane(target string, history int) (string, error) {
+	output, err := c.tmux("capture-pane", "-t", target, "-p", "-S", strconv.Itoa(-history))
+	if err != nil {
+		return "", fmt.Errorf("failed to capture pane %s: %w", target, err)
+	}
+	return string(output), nil
+}
+
+// AtInputPrompt reports whether the agent in the window at target is idle at
+// its input prompt, as opposed to busy or showing a permission or
+// confirmation dialog, so typed text becomes its next instruction.
+func (c *Client) AtInputPrompt(target string) bool {
+	output, err := c.captureTail(target)
+	if err != nil {
+		slog.Debug("AtInputPrompt: capture-pane failed", "target", target, "err", err)
+		return false
+	}
+	content := string(output)
+	return !hasBusyIndicator(content) && hasInputPrompt(content)
+}
+
+// captureTail captures the pane at target with the last 20 lines of
+// scrollback, the content status detection reads. Within a ForRefresh cycle
+// the result is memoized.
+func (c *Client) captureTail(target string) ([]byte, error) {
+	if c.captures == nil {
+		return c.tmux("capture-pane", "-t", target, "-p", "-S", "20")
+	}
+	c.captures.mu.Lock()
+	defer c.captures.mu.Unlock()
+	if cached, ok := c.captures.byTarget[target]; ok {
+		return cached.output, cached.err
+	}
+	output, err := c.tmux("capture-pane", "-t", target, "-p", "-S", "20")
+	c.captures.byTarget[target] = paneCapture{output: output, err: err}
+	return output, err
 }
 
 // getDisplayMessage executes a display-message call with a given printFilter
 func (c *Client) getDisplayMessage(target string, printFilter string) (string, error) {
-	output, err := c.execCommand("tmux", "display-message", "-t", target, "-p", printFilter)
+	output, err := c.tmux("display-message", "-t", target, "-p", printFilter)
 	if err != nil {
 		slog.Debug("getDisplayMessage: display-message failed", "target", target, "err", err)
 		return "", err
@@ -345,57 +1319,148 @@ func (c *Client) getDisplayMessage(target string, printFilter string) (string, e
 //
 // Detection priority (matches agent-deck approach):
 //  1. Busy indicators (spinners, interrupt messages) → WORKING
-//  2. Prompt indicators (permission dialogs, input prompts) → WAITING
-//  3. Default → IDLE
-func (c *Client) detectAgentActivity(target string) Status {
+//  2. Usage or rate limit messages → LIMITED, with the reset time
+//  3. Prompt indicators (permission dialogs, input prompts) → WAITING, with
+//     the WaitingReason of the matched pattern
+//  4. Default → IDLE
+//
+// Error indicators are not checked here: a running agent's pane can show a
+// stack trace or "command not found" from a command it ran. They only mark
+// an agent ERROR once its process has exited (see detectExitedAgent).
+//
+// Only the Status, LimitReset, and WaitingReason fields are set.
+func (c *Client) detectAgentActivity(target string) AgentInfo {
 	slog.Debug("detectAgentActivity", "target", target)
-	output, err := c.execCommand("tmux", "capture-pane", "-t", target, "-p", "-S", "20")
+	output, err := c.captureTail(target)
 	if err != nil {
 		slog.Debug("detectAgentActivity", "tmux err", err)
-		return StatusIdle
+		return AgentInfo{Status: StatusIdle}
 	}
 
 	content := string(output)
-	slog.Debug("detectAgentActivity", "target", target, "content", content)
+	slog.Debug("detectAgentActivity", "target", target, "bytes", len(content))
+	return ClassifyContent(content)
+}
 
+// ClassifyContent derives an agent's status from captured pane content, in
+// the priority order described on detectAgentActivity. Only the Status,
+// LimitReset, and WaitingReason fields are set.
+func ClassifyContent(content string) AgentInfo {
 	// Priority 1: Check busy indicators
 	if hasBusyIndicator(content) {
-		return StatusWorking
+		return AgentInfo{Status: StatusWorking}
 	}
 
-	// Priority 2: Check prompt indicators
-	if hasPromptIndicator(content) {
-		return StatusWaiting
+	// Priority 2: Check limit messages
+	if reset, limited := limitReset(content); limited {
+		return AgentInfo{Status: StatusLimited, LimitReset: reset}
 	}
 
-	return StatusIdle
+	// Priority 3: Check prompt indicators
+	if reason, ok := waitingReason(content); ok {
+		return AgentInfo{Status: StatusWaiting, WaitingReason: reason}
+	}
+
+	return AgentInfo{Status: StatusIdle}
+}
+
+// StatusProfile holds the pane-content patterns that classify an agent's
+// status. Strings are lowercase and match the lowercased pane content;
+// regexps match it unchanged.
+type StatusProfile struct {
+	// BusyStrings are text patterns that indicate the agent is working.
+	BusyStrings []string
+	// SpinnerChars are spinner frames drawn while the agent is working.
+	SpinnerChars []rune
+	// BrailleSpinners also counts any other Braille pattern (U+2801 to
+	// U+28FF) as a spinner frame, for spinners older than SpinnerChars.
+	BrailleSpinners bool
+	// PromptStrings are permission dialog patterns.
+	PromptStrings []string
+	// ConfirmationPatterns are patterns for confirmation prompts.
+	ConfirmationPatterns []string
+	// ErrorStrings are crash and failure patterns, matched against the last
+	// recentLines non-empty lines of panes whose agent has exited only.
+	ErrorStrings []string
+	// LimitStrings are usage and rate limit messages, matched like
+	// ErrorStrings.
+	LimitStrings []string
+	// BusyRegexps indicate working; WaitingRegexps indicate a dialog;
+	// ErrorRegexps and LimitRegexps work like ErrorStrings and LimitStrings.
+	BusyRegexps    []*regexp.Regexp
+	WaitingRegexps []*regexp.Regexp
+	ErrorRegexps   []*regexp.Regexp
+	LimitRegexps   []*regexp.Regexp
 }
 
-// busyStrings are text patterns that indicate Claude is actively working.
-var busyStrings = []string{
-	"ctrl+c to interrupt",
-	"esc to interrupt",
+// DefaultStatusProfile holds the built-in patterns for the supported agents.
+var DefaultStatusProfile = StatusProfile{
+	BusyStrings: []string{
+		"ctrl+c to interrupt",
+		"esc to interrupt",
+	},
+	SpinnerChars: []rune{
+		// Braille spinners
+		'⠋', '⠙', '⠹', '⠸', '⠼', '⠴', '⠦', '⠧', '⠇', '⠏',
+		// Asterisk spinners (Claude 2.1.25+)
+		'✳', '✽', '✶', '✢',
+	},
+	BrailleSpinners: true,
+	PromptStrings: []string{
+		"yes, allow once",
+		"yes, allow always",
+		"no, and tell claude",
+	},
+	ConfirmationPatterns: []string{
+		"continue?",
+		"proceed?",
+		"(y/n)",
+		"[yes/no]",
+		"enter to select",
+	},
+	ErrorStrings: []string{
+		"api error",
+		"command not found",
+		"traceback (most recent call last)",
+		"unhandled promise rejection",
+		"error: cannot find module",
+		"panic:",
+		"fatal error:",
+		"segmentation fault",
+	},
+	LimitStrings: []string{
+		"limit reached",
+		"hit your usage limit",
+		"rate limit exceeded",
+		"too many requests",
+	},
 }
 
-// spinnerChars includes both Braille and asterisk spinner characters.
-var spinnerChars = []rune{
-	// Braille spinners
-	'⠋', '⠙', '⠹', '⠸', '⠼', '⠴', '⠦', '⠧', '⠇', '⠏',
-	// Asterisk spinners (Claude 2.1.25+)
-	'✳', '✽', '✶', '✢',
+// statusProfile is the profile used by status detection.
+var statusProfile = DefaultStatusProfile
+
+// SetStatusProfile replaces the patterns used by status detection. It is
+// meant to be called once at startup, before any Client is used.
+func SetStatusProfile(p StatusProfile) {
+	statusProfile = p
 }
 
-// hasBusyIndicator reports whether content contains indicators that Claude
-// is actively working: interrupt messages or spinner characters.
+// hasBusyIndicator reports whether content contains indicators that the
+// agent is actively working: interrupt messages or spinner characters.
 func hasBusyIndicator(content string) bool {
 	lower := strings.ToLower(content)
 
 	// Check interrupt messages
-	for _, s := range busyStrings {
+	for _, s := range statusProfile.BusyStrings {
 		if strings.Contains(lower, s) {
 			return true
 		}
 	}
+	for _, re := range statusProfile.BusyRegexps {
+		if re.MatchString(content) {
+			return true
+		}
+	}
 
 	// Check spinner characters
 	return containsSpinnerChars(content)
@@ -404,62 +1469,194 @@ func hasBusyIndicator(content string) bool {
 // containsSpinnerChars checks for any spinner character in the content.
 func containsSpinnerChars(s string) bool {
 	for _, r := range s {
-		for _, spinner := range spinnerChars {
-			if r == spinner {
-				return true
-			}
+		if slices.Contains(statusProfile.SpinnerChars, r) {
+			return true
 		}
-		// Also check Braille range for backwards compatibility
-		if r > 0x2800 && r <= 0x28FF {
+		if statusProfile.BrailleSpinners && r > 0x2800 && r <= 0x28FF {
 			return true
 		}
 	}
 	return false
 }
 
-// promptStrings are permission dialog patterns.
-var promptStrings = []string{
-	"yes, allow once",
-	"yes, allow always",
-	"no, and tell claude",
+// errorTailLines bounds how far up error and limit messages are looked for,
+// so one the agent printed earlier and recovered from does not linger.
+const errorTailLines = 10
+
+// recentLines returns the last errorTailLines non-empty lines of content.
+func recentLines(content string) string {
+	var tail []string
+	lines := strings.Split(content, "\n")
+	for i := len(lines) - 1; i >= 0 && len(tail) < errorTailLines; i-- {
+		if strings.TrimSpace(lines[i]) != "" {
+			tail = append(tail, lines[i])
+		}
+	}
+	slices.Reverse(tail)
+	return strings.Join(tail, "\n")
+}
+
+// limitResetPattern captures the reset time from limit messages such as
+// "Your limit will reset at 3pm (America/New_York)", "5-hour limit reached ∙
+// resets 3pm", or "try again in 2 days 3 hours".
+var limitResetPattern = regexp.MustCompile(`(?i)(?:reset|resets|try again)\s+(?:at\s+)?([^.\n·∙|]+)`)
+
+// limitReset reports whether the last lines of content show a usage or rate
+// limit message, and the reset time it names, if any.
+func limitReset(content string) (string, bool) {
+	recent := recentLines(content)
+	lower := strings.ToLower(recent)
+	limited := slices.ContainsFunc(statusProfile.LimitStrings, func(s string) bool {
+		return strings.Contains(lower, s)
+	}) || slices.ContainsFunc(statusProfile.LimitRegexps, func(re *regexp.Regexp) bool {
+		return re.MatchString(recent)
+	})
+	if !limited {
+		return "", false
+	}
+	if match := limitResetPattern.FindStringSubmatch(recent); match != nil {
+		return strings.TrimSpace(match[1]), true
+	}
+	return "", true
 }
 
-// confirmationPatterns are patterns for confirmation prompts.
-var confirmationPatterns = []string{
-	"continue?",
-	"proceed?",
-	"(y/n)",
-	"[yes/no]",
-	"enter to select",
+// hasErrorIndicator reports whether the last lines of content show that the
+// agent failed: an API error banner, a stack trace, or a missing command.
+func hasErrorIndicator(content string) bool {
+	recent := recentLines(content)
+	lower := strings.ToLower(recent)
+	for _, s := range statusProfile.ErrorStrings {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	for _, re := range statusProfile.ErrorRegexps {
+		if re.MatchString(recent) {
+			return true
+		}
+	}
+	return false
 }
 
 // hasPromptIndicator reports whether content contains indicators that Claude
 // is waiting for user input: permission dialogs or input prompts.
 func hasPromptIndicator(content string) bool {
+	_, ok := waitingReason(content)
+	return ok
+}
+
+// waitingReason classifies the prompt content shows: a permission dialog, a
+// confirmation or choice prompt (including WaitingRegexps matches), or a
+// plain input prompt. ok is false when content shows no prompt.
+func waitingReason(content string) (reason WaitingReason, ok bool) {
+	lower := strings.ToLower(content)
+	switch {
+	case slices.ContainsFunc(statusProfile.PromptStrings, func(s string) bool { return strings.Contains(lower, s) }):
+		return WaitingPermission, true
+	case hasDialogIndicator(content):
+		return WaitingQuestion, true
+	case endsAtInputPrompt(content):
+		return WaitingInput, true
+	default:
+		return "", false
+	}
+}
+
+// hasInputPrompt reports whether content shows an agent waiting at its input
+// prompt for the next instruction, not at a permission or confirmation dialog.
+func hasInputPrompt(content string) bool {
+	return !hasDialogIndicator(content) && endsAtInputPrompt(content)
+}
+
+// hasDialogIndicator reports whether content contains a permission dialog or
+// confirmation prompt.
+func hasDialogIndicator(content string) bool {
 	lower := strings.ToLower(content)
 
 	// Check permission prompts
-	for _, s := range promptStrings {
+	for _, s := range statusProfile.PromptStrings {
 		if strings.Contains(lower, s) {
 			return true
 		}
 	}
 
 	// Check confirmation prompts
-	for _, p := range confirmationPatterns {
+	for _, p := range statusProfile.ConfirmationPatterns {
 		if strings.Contains(lower, p) {
 			return true
 		}
 	}
+	for _, re := range statusProfile.WaitingRegexps {
+		if re.MatchString(content) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsAtInputPrompt reports whether the last non-empty line ends with > or ❯.
+func endsAtInputPrompt(content string) bool {
+	lines := strings.Split(content, "\n")
+	trimmed := strings.TrimSpace(getLastNonEmptyLine(lines))
+	return strings.HasSuffix(trimmed, ">") || strings.HasSuffix(trimmed, "❯")
+}
+
+// messageMarkers prefix an agent's response blocks (Claude "⏺", Codex "•").
+var messageMarkers = []string{"⏺", "•"}
 
-	// Check for input prompt (last non-empty line ends with > or ❯)
+// LastAgentMessage extracts the agent's final response from captured pane
+// content: the last block that starts with a message marker, up to the input
+// box or prompt. Without a marker it falls back to the last few lines of
+// output above the input box.
+func LastAgentMessage(content string) string {
 	lines := strings.Split(content, "\n")
-	lastLine := getLastNonEmptyLine(lines)
-	trimmed := strings.TrimSpace(lastLine)
-	if strings.HasSuffix(trimmed, ">") || strings.HasSuffix(trimmed, "❯") {
-		return true
+	start := -1
+	for i := len(lines) - 1; i >= 0 && start < 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		for _, marker := range messageMarkers {
+			if strings.HasPrefix(trimmed, marker) {
+				start = i
+				break
+			}
+		}
 	}
 
+	var message []string
+	if start >= 0 {
+		for _, line := range lines[start:] {
+			if isInputChrome(line) {
+				break
+			}
+			message = append(message, strings.TrimSpace(line))
+		}
+		first := message[0]
+		for _, marker := range messageMarkers {
+			first = strings.TrimPrefix(first, marker)
+		}
+		message[0] = strings.TrimSpace(first)
+	} else {
+		for _, line := range lines {
+			if isInputChrome(line) {
+				break
+			}
+			if strings.TrimSpace(line) != "" {
+				message = append(message, strings.TrimSpace(line))
+			}
+		}
+		message = message[max(0, len(message)-3):]
+	}
+	return strings.TrimSpace(strings.Join(message, "\n"))
+}
+
+// isInputChrome reports whether line belongs to an agent's input box or
+// prompt rather than its output.
+func isInputChrome(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"╭", "╰", "│", "───", ">", "❯", "›"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
 	return false
 }
 
@@ -475,7 +1672,7 @@ func getLastNonEmptyLine(lines []string) string {
 
 // CreateSession creates a new detached tmux session with the given name and working directory.
 func (c *Client) CreateSession(name, workdir string) error {
-	_, err := c.execCommand("tmux", "new-session", "-d", "-s", name, "-c", workdir)
+	_, err := c.tmux("new-session", "-d", "-s", name, "-c", workdir)
 	if err != nil {
 		return fmt.Errorf("failed to create session %s: %w", name, err)
 	}
@@ -492,7 +1689,7 @@ func (c *Client) CreateWindow(session, name string, command string) error {
 	if command != "" {
 		args = append(args, command)
 	}
-	_, err := c.execCommand("tmux", args...)
+	_, err := c.tmux(args...)
 	if err != nil {
 		return fmt.Errorf("failed to create window %s in %s: %w", name, session, err)
 	}
@@ -514,18 +1711,107 @@ func (c *Client) CreateWindowWithShellInDir(session, name, command, workdir stri
 	if workdir != "" {
 		args = append(args, "-c", workdir)
 	}
-	_, err := c.execCommand("tmux", args...)
+	_, err := c.tmux(args...)
 	if err != nil {
 		return fmt.Errorf("failed to create window %s in %s: %w", name, session, err)
 	}
 
 	// Send the command to the new window's shell
 	if command != "" {
-		target := session + ":" + name
-		_, err = c.execCommand("tmux", "send-keys", "-t", target, command, "Enter")
+		return c.SendCommand(session, name, command)
+	}
+	return nil
+}
+
+// SendCommand types command into the given window's shell and presses Enter.
+func (c *Client) SendCommand(session, window, command string) error {
+	return c.SendCommandToPane(session+":"+window, command)
+}
+
+// SendCommandToPane types command into the shell of target (a pane ID such as
+// "%3", or any tmux target) and presses Enter.
+func (c *Client) SendCommandToPane(target, command string) error {
+	_, err := c.tmux("send-keys", "-t", target, command, "Enter")
+	if err != nil {
+		return fmt.Errorf("failed to send command to %s: %w", target, err)
+	}
+	return nil
+}
+
+// SendText types text literally into the pane at target, so key names such as
+// "Enter" in it are not interpreted, then presses Enter to submit it.
+func (c *Client) SendText(target, text string) error {
+	if _, err := c.tmux("send-keys", "-t", target, "-l", text); err != nil {
+		return fmt.Errorf("failed to send text to %s: %w", target, err)
+	}
+	if _, err := c.tmux("send-keys", "-t", target, "Enter"); err != nil {
+		return fmt.Errorf("failed to send text to %s: %w", target, err)
+	}
+	return nil
+}
+
+// RenameWindow renames the active window of session.
+func (c *Client) RenameWindow(session, name string) error {
+	_, err := c.tmux("rename-window", "-t", session, name)
+	if err != nil {
+		return fmt.Errorf("failed to rename window in %s to %s: %w", session, name, err)
+	}
+	return nil
+}
+
+// HasSession reports whether a tmux session with exactly this name exists.
+func (c *Client) HasSession(name string) bool {
+	_, err := c.tmux("has-session", "-t", "="+name)
+	return err == nil
+}
+
+// UniqueName returns base, or the first free base-2, base-3, ... when base is
+// taken, for naming a new session or window next to existing ones.
+func UniqueName(base string, exists func(string) bool) string {
+	if !exists(base) {
+		return base
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", base, i)
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// KillSession kills the given tmux session.
+func (c *Client) KillSession(name string) error {
+	_, err := c.tmux("kill-session", "-t", name)
+	if err != nil {
+		return fmt.Errorf("failed to kill session %s: %w", name, err)
+	}
+	return nil
+}
+
+// SessionActivity returns when any window of the session last had output:
+// the latest #{window_activity} among them. #{session_activity} is not used,
+// as it only moves with client input.
+func (c *Client) SessionActivity(name string) (time.Time, error) {
+	output, err := c.tmux("list-windows", "-t", "="+name+":", "-F", "#{window_activity}")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read activity of session %s: %w", name, err)
+	}
+	var latest int64
+	for line := range strings.SplitSeq(strings.TrimSpace(string(output)), "\n") {
+		secs, err := strconv.ParseInt(strings.TrimSpace(line), 10, 64)
 		if err != nil {
-			return fmt.Errorf("failed to send command to %s:%s: %w", session, name, err)
+			return time.Time{}, fmt.Errorf("failed to parse activity of session %s: %w", name, err)
 		}
+		latest = max(latest, secs)
+	}
+	return time.Unix(latest, 0), nil
+}
+
+// RenameSession renames a tmux session in place; its windows and processes
+// keep running.
+func (c *Client) RenameSession(oldName, newName string) error {
+	if _, err := c.tmux("rename-session", "-t", "="+oldName, newName); err != nil {
+		return fmt.Errorf("failed to rename session %s to %s: %w", oldName, newName, err)
 	}
 	return nil
 }
@@ -557,28 +1843,70 @@ func (c *Client) AttachOrSwitchToSession(name string, inTmux bool) error {
 	return c.AttachSession(name)
 }
 
-// SelectWindow selects a window by index inside a session.
-func (c *Client) SelectWindow(session string, windowIndex int) error {
-	target := fmt.Sprintf("%s:%d", session, windowIndex)
-	_, err := c.execCommand("tmux", "select-window", "-t", target)
+// SelectWindow selects the window at target (see Window.Target).
+func (c *Client) SelectWindow(target string) error {
+	_, err := c.tmux("select-window", "-t", target)
 	if err != nil {
-		return fmt.Errorf("failed to select window %d in session %s: %w", windowIndex, session, err)
+		return fmt.Errorf("failed to select window %s: %w", target, err)
 	}
 	return nil
 }
 
 // SetSessionOption sets a tmux session-scoped option value.
 func (c *Client) SetSessionOption(session, key, value string) error {
-	_, err := c.execCommand("tmux", "set-option", "-t", session, key, value)
+	_, err := c.tmux("set-option", "-t", session, key, value)
 	if err != nil {
 		return fmt.Errorf("failed to set option %s on session %s: %w", key, session, err)
 	}
 	return nil
 }
 
+// SetWindowOption sets a tmux window-scoped option on the window at target
+// (see Window.Target).
+func (c *Client) SetWindowOption(target, key, value string) error {
+	if _, err := c.tmux("set-option", "-w", "-t", target, key, value); err != nil {
+		return fmt.Errorf("failed to set option %s on window %s: %w", key, target, err)
+	}
+	return nil
+}
+
+// UnsetSessionOption removes a tmux session-scoped option.
+func (c *Client) UnsetSessionOption(session, key string) error {
+	_, err := c.tmux("set-option", "-u", "-t", session, key)
+	if err != nil {
+		return fmt.Errorf("failed to unset option %s on session %s: %w", key, session, err)
+	}
+	return nil
+}
+
+// SessionTags returns the session's tags, or none when it has no tags.
+func (c *Client) SessionTags(session string) []string {
+	raw, err := c.GetSessionOption(session, SessionOptionTags)
+	if err != nil {
+		return nil
+	}
+	return ParseTags(raw)
+}
+
+// ParseTags splits a comma- or space-separated tag list into lowercase,
+// deduplicated, sorted tags.
+func ParseTags(raw string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, field := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		tag := strings.ToLower(field)
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
 // GetSessionOption gets a tmux session-scoped option value.
 func (c *Client) GetSessionOption(session, key string) (string, error) {
-	output, err := c.execCommand("tmux", "show-options", "-t", session, "-v", key)
+	output, err := c.tmux("show-options", "-t", session, "-v", key)
 	if err != nil {
 		return "", fmt.Errorf("failed to get option %s on session %s: %w", key, session, err)
 	}
@@ -595,7 +1923,7 @@ func (c *Client) GetPaneWorkingDir(session string) string {
 // Returns empty string on error.
 func (c *Client) GetWindowWorkingDir(session string, windowIndex int) string {
 	target := fmt.Sprintf("%s:%d", session, windowIndex)
-	output, err := c.execCommand("tmux", "display-message", "-t", target, "-p", "#{pane_current_path}")
+	output, err := c.tmux("display-message", "-t", target, "-p", "#{pane_current_path}")
 	if err != nil {
 		return ""
 	}
@@ -606,16 +1934,75 @@ func (c *Client) GetWindowWorkingDir(session string, windowIndex int) string {
 // pane's working directory and deriving the git toplevel.
 // Returns "Unknown" if the repo cannot be determined.
 func (c *Client) GetRepoName(session string) string {
+	repoRoot := c.getRepoRoot(session)
+	if repoRoot == "" {
+		return "Unknown"
+	}
+	return filepath.Base(repoRoot)
+}
+
+// getRepoRoot returns the git toplevel of a session's first pane, or "" if
+// it cannot be determined.
+func (c *Client) getRepoRoot(session string) string {
 	paneDir := c.GetPaneWorkingDir(session)
 	if paneDir == "" {
-		return "Unknown"
+		return ""
 	}
 
-	output, err := c.execCommand("git", "-C", paneDir, "rev-parse", "--show-toplevel")
+	if root, ok := c.repoRoots.lookup(paneDir); ok {
+		return root
+	}
+	output, err := c.run("git", "-C", paneDir, "rev-parse", "--show-toplevel")
 	if err != nil {
-		return "Unknown"
+		return ""
 	}
+	root := strings.TrimSpace(string(output))
+	c.repoRoots.store(paneDir, root)
+	return root
+}
 
-	repoRoot := strings.TrimSpace(string(output))
-	return filepath.Base(repoRoot)
+// maxCachedRepoRoots bounds repoRootCache; the cache is cleared when full.
+const maxCachedRepoRoots = 1024
+
+// repoRootCache holds the git toplevel of each directory a session's first
+// pane has been in. A directory's repository practically never changes, so
+// listings and refreshes need one git call per new directory rather than one
+// per session every time. Directories outside a repository are not cached,
+// so running git init there is picked up.
+type repoRootCache struct {
+	mu    sync.Mutex
+	byDir map[string]string
+}
+
+func (r *repoRootCache) lookup(dir string) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	root, ok := r.byDir[dir]
+	return root, ok
+}
+
+func (r *repoRootCache) store(dir, root string) {
+	if r == nil || root == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.byDir) >= maxCachedRepoRoots {
+		clear(r.byDir)
+	}
+	r.byDir[dir] = root
+}
+
+// projectPath returns the project a git toplevel belongs to: the main
+// repository for a cb worktree (<repo>/.worktrees/<name>), otherwise the
+// toplevel itself.
+func projectPath(repoRoot string) string {
+	parent := filepath.Dir(repoRoot)
+	if filepath.Base(parent) == ".worktrees" {
+		return filepath.Dir(parent)
+	}
+	return repoRoot
 }
@@ -1,10 +1,16 @@
 package tmux
 
 import (
+	"context"
 	"errors"
 	"os"
+	"os/exec"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseSessionList(t *testing.T) {
@@ -61,6 +67,52 @@ func TestClient_ListSessions_NoTmux(t *testing.T) {
 	}
 }
 
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "no server text", err: &mockError{msg: "no server running on /tmp/tmux-501/default"}, want: ErrNoServer},
+		{name: "no server stderr", err: &exec.ExitError{Stderr: []byte("error connecting to /tmp/tmux-501/default (No such file or directory)\n")}, want: ErrNoServer},
+		{name: "missing session", err: &exec.ExitError{Stderr: []byte("can't find session: cb_gone\n")}, want: ErrNoSession},
+		{name: "no sessions", err: &mockError{msg: "no sessions"}, want: ErrNoSession},
+		{name: "missing window", err: &exec.ExitError{Stderr: []byte("can't find window: 7\n")}, want: ErrWindowNotFound},
+		{name: "other", err: &mockError{msg: "unknown option"}, want: nil},
+	}
+
+	sentinels := []error{ErrNoServer, ErrNoSession, ErrWindowNotFound}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err)
+			if !errors.Is(got, tt.err) {
+				t.Fatalf("classifyError() = %v, should wrap the original error", got)
+			}
+			for _, sentinel := range sentinels {
+				if errors.Is(got, sentinel) != (sentinel == tt.want) {
+					t.Fatalf("errors.Is(%v, %v) = %v, want %v", got, sentinel, !(sentinel == tt.want), sentinel == tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_SelectWindowMissingReturnsErrWindowNotFound(t *testing.T) {
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			return nil, &exec.ExitError{Stderr: []byte("can't find window: 3")}
+		},
+	}
+
+	err := client.SelectWindow("@3")
+	if !errors.Is(err, ErrWindowNotFound) {
+		t.Fatalf("SelectWindow() error = %v, want ErrWindowNotFound", err)
+	}
+	if !strings.Contains(err.Error(), "can't find window") {
+		t.Fatalf("SelectWindow() error = %q, want tmux stderr detail", err)
+	}
+}
+
 func TestClient_ListSessionWindowInfo(t *testing.T) {
 	client := &Client{
 		execCommand: func(name string, args ...string) ([]byte, error) {
@@ -77,23 +129,25 @@ func TestClient_ListSessionWindowInfo(t *testing.T) {
 						}
 						return []byte("/tmp/repo-b"), nil
 					}
-					if format == "#{pane_current_command}" {
-						if target == "cb_demo:workbench" {
-							return []byte("codex"), nil
+					if format == paneFormat {
+						if target == "@5" {
+							return []byte("%1\tcodex\t/dev/ttys001\t1700000000\t\t\t\t\t\tworkbench"), nil
 						}
-						return []byte("zsh"), nil
-					}
-					if format == "#{pane_tty}" {
-						return []byte("/dev/ttys001"), nil
+						return []byte("%2\tzsh\t/dev/ttys002\t1700000000\t\t\t\t\t\tshell"), nil
 					}
 				case "list-windows":
 					session := args[2]
 					if session == "cb_demo" {
-						return []byte("1:workbench:1\n"), nil
+						return []byte("@5:1:workbench:1\n"), nil
 					}
-					return []byte("0:shell:1\n"), nil
+					return []byte("@6:0:shell:1\n"), nil
 				case "capture-pane":
 					return []byte("ctrl+c to interrupt\n"), nil
+				case "show-options":
+					if args[2] == "cb_demo" && args[4] == SessionOptionTags {
+						return []byte("urgent,backend\n"), nil
+					}
+					return nil, errors.New("invalid option")
 				}
 			}
 
@@ -106,7 +160,7 @@ func TestClient_ListSessionWindowInfo(t *testing.T) {
 			}
 
 			if name == "ps" {
-				return []byte("123 ttys001 codex\n"), nil
+				return []byte("codex\n"), nil
 			}
 
 			return nil, errors.New("unexpected command")
@@ -131,6 +185,9 @@ func TestClient_ListSessionWindowInfo(t *testing.T) {
 	if rows[0].AgentInfo.Type != AgentCodex || !rows[0].AgentInfo.Detected {
 		t.Fatalf("rows[0].AgentInfo = %+v, want detected codex", rows[0].AgentInfo)
 	}
+	if got := strings.Join(rows[0].Tags, ","); got != "backend,urgent" {
+		t.Fatalf("rows[0].Tags = %q, want backend,urgent", got)
+	}
 
 	if rows[1].SessionName != "team-sync" {
 		t.Fatalf("rows[1].SessionName = %q, want %q", rows[1].SessionName, "team-sync")
@@ -174,6 +231,49 @@ func TestParseWindowList(t *testing.T) {
 	}
 }
 
+func TestParseWindowListWithIDs(t *testing.T) {
+	output := `@3:0:shell:1
+@7:1:claude:0
+@9:2:claude:0`
+
+	windows := ParseWindowList(output)
+	if len(windows) != 3 {
+		t.Fatalf("got %d windows, want 3", len(windows))
+	}
+	if windows[1].ID != "@7" || windows[1].Index != 1 || windows[1].Name != "claude" {
+		t.Fatalf("window 1 = %+v, want @7/1/claude", windows[1])
+	}
+	// Duplicate names still yield distinct targets.
+	if windows[1].Target("cb_demo") == windows[2].Target("cb_demo") {
+		t.Fatalf("duplicate window names share target %q", windows[1].Target("cb_demo"))
+	}
+}
+
+func TestParseWindowListWithPurpose(t *testing.T) {
+	windows := ParseWindowList("@3:0:shell:1\t\n@7:1:claude-review:0\treview\n")
+	if len(windows) != 2 || windows[0].Purpose != "" || windows[1].Name != "claude-review" || windows[1].Purpose != "review" {
+		t.Fatalf("windows = %+v, want claude-review with purpose review", windows)
+	}
+}
+
+func TestWindowTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		window Window
+		want   string
+	}{
+		{name: "window ID", window: Window{ID: "@12", Index: 2, Name: "claude"}, want: "@12"},
+		{name: "falls back to index", window: Window{Index: 2, Name: "claude"}, want: "cb_demo:2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Target("cb_demo"); got != tt.want {
+				t.Fatalf("Target() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestClient_DetectAgentType(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -186,25 +286,25 @@ func TestClient_DetectAgentType(t *testing.T) {
 		{
 			name:     "detect claude",
 			paneTTY:  "/dev/ttys001",
-			psOutput: "1234 ttys001  0:00.10 Claude",
+			psOutput: "Claude",
 			want:     AgentClaude,
 		},
 		{
 			name:     "detect codex",
 			paneTTY:  "/dev/ttys001",
-			psOutput: "1234 ttys001  0:00.10 codex",
+			psOutput: "codex",
 			want:     AgentCodex,
 		},
 		{
 			name:     "detect open code",
 			paneTTY:  "/dev/ttys001",
-			psOutput: "1234 ttys001  0:00.10 open-code",
+			psOutput: "open-code",
 			want:     AgentOpenCode,
 		},
 		{
 			name:     "none when no matching process",
 			paneTTY:  "/dev/ttys001",
-			psOutput: "1234 ttys001  0:00.10 vim",
+			psOutput: "vim",
 			want:     AgentNone,
 		},
 		{
@@ -234,7 +334,7 @@ func TestClient_DetectAgentType(t *testing.T) {
 				},
 			}
 
-			got := client.DetectAgentType("cb_demo", "random")
+			got := client.DetectAgentType("@4")
 			if got != tt.want {
 				t.Fatalf("DetectAgentType() = %q, want %q", got, tt.want)
 			}
@@ -242,6 +342,33 @@ func TestClient_DetectAgentType(t *testing.T) {
 	}
 }
 
+func TestRollupStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []Status
+		want     Status
+	}{
+		{"working wins all", []Status{StatusDone, StatusWorking, StatusWaiting}, StatusWorking},
+		{"error over waiting", []Status{StatusWaiting, StatusError, StatusIdle}, StatusError},
+		{"waiting over idle", []Status{StatusIdle, StatusWaiting, StatusDone}, StatusWaiting},
+		{"limited over idle", []Status{StatusIdle, StatusLimited}, StatusLimited},
+		{"idle over done", []Status{StatusDone, StatusIdle}, StatusIdle},
+		{"unknown over done", []Status{StatusDone, StatusUnknown}, StatusUnknown},
+		{"idle over unknown", []Status{StatusUnknown, StatusIdle}, StatusIdle},
+		{"all done", []Status{StatusDone, StatusDone}, StatusDone},
+		{"empty returns done", []Status{}, StatusDone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RollupStatus(tt.statuses)
+			if got != tt.want {
+				t.Errorf("RollupStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestClient_DetectAgentInfo(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -255,21 +382,21 @@ func TestClient_DetectAgentInfo(t *testing.T) {
 		{
 			name:        "detected agent working",
 			cmdOutput:   "codex",
-			psOutput:    "1234 ttys001 codex",
+			psOutput:    "codex",
 			paneContent: "ctrl+c to interrupt",
 			expected:    AgentInfo{Type: AgentCodex, Detected: true, Status: StatusWorking},
 		},
 		{
 			name:        "detected agent waiting",
 			cmdOutput:   "claude",
-			psOutput:    "1234 ttys001 claude",
+			psOutput:    "claude",
 			paneContent: "Continue? (Y/n)",
-			expected:    AgentInfo{Type: AgentClaude, Detected: true, Status: StatusWaiting},
+			expected:    AgentInfo{Type: AgentClaude, Detected: true, Status: StatusWaiting, WaitingReason: WaitingQuestion},
 		},
 		{
 			name:        "detected agent idle",
 			cmdOutput:   "open-code",
-			psOutput:    "1234 ttys001 open-code",
+			psOutput:    "open-code",
 			paneContent: "all done output",
 			expected:    AgentInfo{Type: AgentOpenCode, Detected: true, Status: StatusIdle},
 		},
@@ -281,7 +408,7 @@ func TestClient_DetectAgentInfo(t *testing.T) {
 		{
 			name:      "no detected process is done",
 			cmdOutput: "python",
-			psOutput:  "1234 ttys001 python",
+			psOutput:  "python",
 			expected:  AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone},
 		},
 		{
@@ -293,17 +420,14 @@ func TestClient_DetectAgentInfo(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			displays := 0
 			client := &Client{
 				execCommand: func(name string, args ...string) ([]byte, error) {
 					if name == "tmux" && len(args) > 0 {
 						switch args[0] {
 						case "display-message":
-							// pane_current_command query
-							if len(args) > 0 && args[len(args)-1] == "#{pane_current_command}" {
-								return []byte(tt.cmdOutput), tt.cmdErr
-							}
-							// pane_tty query
-							return []byte("/dev/ttys001"), nil
+							displays++
+							return []byte("%4\t" + tt.cmdOutput + "\t/dev/ttys001\t1700000000\t"), tt.cmdErr
 						case "capture-pane":
 							return []byte(tt.paneContent), nil
 						}
@@ -314,10 +438,249 @@ func TestClient_DetectAgentInfo(t *testing.T) {
 					return nil, errors.New("unexpected command")
 				},
 			}
-			got := client.DetectAgentInfo("session", "window")
+			got := client.DetectAgentInfo("@4")
 			if got != tt.expected {
 				t.Fatalf("DetectAgentInfo() = %+v, want %+v", got, tt.expected)
 			}
+			if displays != 1 {
+				t.Fatalf("display-message calls = %d, want 1", displays)
+			}
+		})
+	}
+}
+
+func TestClient_DetectAgentInfoChecksEveryPane(t *testing.T) {
+	tests := []struct {
+		name     string
+		panes    string
+		ps       map[string]string
+		content  map[string]string
+		expected AgentInfo
+	}{
+		{
+			name:     "agent in split pane",
+			panes:    "%1\tzsh\t/dev/ttys001\n%2\tnode\t/dev/ttys002",
+			ps:       map[string]string{"/dev/ttys002": "claude"},
+			content:  map[string]string{"%2": "Continue? (Y/n)"},
+			expected: AgentInfo{Type: AgentClaude, Detected: true, Status: StatusWaiting, WaitingReason: WaitingQuestion},
+		},
+		{
+			name:  "most urgent pane wins",
+			panes: "%1\tclaude\t/dev/ttys001\n%2\tcodex\t/dev/ttys002",
+			ps: map[string]string{
+				"/dev/ttys001": "claude",
+				"/dev/ttys002": "codex",
+			},
+			content: map[string]string{
+				"%1": "all done output",
+				"%2": "esc to interrupt",
+			},
+			expected: AgentInfo{Type: AgentCodex, Detected: true, Status: StatusWorking},
+		},
+		{
+			name:     "only shells",
+			panes:    "%1\tzsh\t/dev/ttys001\n%2\tbash\t/dev/ttys002",
+			expected: AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone},
+		},
+		{
+			name:     "running agent with a panic on screen is not ERROR",
+			panes:    "%1\tnode\t/dev/ttys001\t1700000000\t\t\t\t\t\tclaude",
+			ps:       map[string]string{"/dev/ttys001": "claude"},
+			content:  map[string]string{"%1": "⏺ Bash(go test ./...)\n  panic: runtime error: index out of range\n\n> "},
+			expected: AgentInfo{Type: AgentClaude, Detected: true, Status: StatusWaiting, WaitingReason: WaitingInput},
+		},
+		{
+			name:     "agent window crashed back to shell",
+			panes:    "%1\tzsh\t/dev/ttys001\t1700000000\t\t\t\t\t\tcodex",
+			content:  map[string]string{"%1": "TypeError: x is undefined\n    at main (cli.js:1:1)\nUnhandled promise rejection\n$ "},
+			expected: AgentInfo{Type: AgentCodex, Detected: true, Status: StatusError},
+		},
+		{
+			name:     "agent at usage limit",
+			panes:    "%1\tnode\t/dev/ttys001\t1700000000\t\t\t\t\t\tclaude",
+			ps:       map[string]string{"/dev/ttys001": "claude"},
+			content:  map[string]string{"%1": "API Error: Claude usage limit reached. Your limit will reset at 3pm.\n> "},
+			expected: AgentInfo{Type: AgentClaude, Detected: true, Status: StatusLimited, LimitReset: "3pm"},
+		},
+		{
+			name:     "agent window exited cleanly",
+			panes:    "%1\tzsh\t/dev/ttys001\t1700000000\t\t\t\t\t\tclaude",
+			content:  map[string]string{"%1": "Goodbye!\n$ "},
+			expected: AgentInfo{Type: AgentNone, Detected: false, Status: StatusDone},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{
+				execCommand: func(name string, args ...string) ([]byte, error) {
+					switch {
+					case name == "tmux" && args[0] == "list-panes":
+						return []byte(tt.panes), nil
+					case name == "tmux" && args[0] == "capture-pane":
+						return []byte(tt.content[args[2]]), nil
+					case name == "ps":
+						return []byte(tt.ps[args[1]]), nil
+					}
+					return nil, errors.New("unexpected command")
+				},
+			}
+			if got := client.DetectAgentInfo("@4"); got != tt.expected {
+				t.Fatalf("DetectAgentInfo() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClient_DetectAgentInfoReusesQuietWindows(t *testing.T) {
+	activity := "1700000000"
+	captures := 0
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			switch {
+			case name == "tmux" && args[0] == "list-panes":
+				return []byte("%1\tnode\t/dev/ttys001\t" + activity + "\t\t\t\t\t\tclaude"), nil
+			case name == "tmux" && args[0] == "capture-pane":
+				captures++
+				return []byte("Done editing.\n> "), nil
+			case name == "ps":
+				return []byte("claude"), nil
+			}
+			return nil, errors.New("unexpected command")
+		},
+		detections: &detectionCache{byWindow: make(map[string]cachedDetection)},
+	}
+
+	want := AgentInfo{Type: AgentClaude, Detected: true, Status: StatusWaiting, WaitingReason: WaitingInput}
+	for range 2 {
+		if got := client.DetectAgentInfo("@1"); got != want {
+			t.Fatalf("DetectAgentInfo() = %+v, want %+v", got, want)
+		}
+	}
+	if captures != 1 {
+		t.Fatalf("captures without new output = %d, want 1", captures)
+	}
+
+	activity = "1700000005"
+	client.DetectAgentInfo("@1")
+	if captures != 2 {
+		t.Fatalf("captures after new output = %d, want 2", captures)
+	}
+
+	activity = strconv.FormatInt(time.Now().Unix()+60, 10)
+	client.DetectAgentInfo("@1")
+	client.DetectAgentInfo("@1")
+	if captures != 4 {
+		t.Fatalf("captures with output in the checked second = %d, want 4", captures)
+	}
+}
+
+func TestClient_DetectAgentInfoRecordsWindowOptions(t *testing.T) {
+	recorded := "1700000000\tWAITING\tcodex\tpermission\t" + DetectorVersion
+	var setArgs []string
+	captures := 0
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			switch {
+			case name == "tmux" && args[0] == "list-panes":
+				return []byte("%1\tnode\t/dev/ttys001\t1699999999\t" + recorded + "\tcodex"), nil
+			case name == "tmux" && args[0] == "set-option":
+				setArgs = args
+				return nil, nil
+			case name == "tmux" && args[0] == "capture-pane":
+				captures++
+				return []byte("esc to interrupt"), nil
+			case name == "ps":
+				return []byte("codex"), nil
+			}
+			return nil, errors.New("unexpected command")
+		},
+		recordDetections: true,
+	}
+
+	want := AgentInfo{Type: AgentCodex, Detected: true, Status: StatusWaiting, WaitingReason: WaitingPermission}
+	if got := client.DetectAgentInfo("@1"); got != want || captures != 0 || setArgs != nil {
+		t.Fatalf("DetectAgentInfo() = %+v (captures %d, set %v), want recorded %+v", got, captures, setArgs, want)
+	}
+
+	recorded = "1700000000\tWAITING\tcodex\tpermission\t0"
+	want = AgentInfo{Type: AgentCodex, Detected: true, Status: StatusWorking}
+	if got := client.DetectAgentInfo("@1"); got != want || captures != 1 {
+		t.Fatalf("DetectAgentInfo() with record of another detector = %+v (captures %d), want %+v", got, captures, want)
+	}
+
+	recorded = "1699999999\tWAITING\tcodex\tpermission\t" + DetectorVersion
+	if got := client.DetectAgentInfo("@1"); got != want || captures != 2 {
+		t.Fatalf("DetectAgentInfo() with stale record = %+v (captures %d), want %+v", got, captures, want)
+	}
+	joined := strings.Join(setArgs, " ")
+	for _, part := range []string{"set-option -w -t @1 @cb_status WORKING ;", "set-option -w -t @1 @cb_agent codex ;", "set-option -w -t @1 @cb_checked ", "set-option -w -t @1 @cb_detector " + DetectorVersion} {
+		if !strings.Contains(joined, part) {
+			t.Fatalf("set-option args = %q, want %q", joined, part)
+		}
+	}
+}
+
+func TestClient_ForRefreshListsAllPanesOnce(t *testing.T) {
+	var listCalls []string
+	client := (&Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			switch {
+			case name == "tmux" && args[0] == "list-panes":
+				listCalls = append(listCalls, strings.Join(args[1:3], " "))
+				return []byte("cb_a\t0\t@1\t%1\tzsh\t/dev/ttys001\t1700000000\t\t\t\t\t\tshell\n" +
+					"cb_b\t2\t@2\t%2\tzsh\t/dev/ttys002\t1700000000\t\t\t\t\t\tshell\n"), nil
+			}
+			return nil, errors.New("unexpected command")
+		},
+	}).ForRefresh()
+
+	for _, target := range []string{"@1", "cb_b:2", "@2"} {
+		panes, err := client.ListPanes(target)
+		if err != nil || len(panes) != 1 || panes[0].WindowName != "shell" {
+			t.Fatalf("ListPanes(%q) = %+v, %v; want its one pane", target, panes, err)
+		}
+	}
+	if len(listCalls) != 1 || listCalls[0] != "-a -F" {
+		t.Fatalf("list-panes calls = %q, want one list-panes -a", listCalls)
+	}
+}
+
+func TestParsePaneList(t *testing.T) {
+	got := parsePaneList("%1\tzsh\t/dev/ttys001\nbogus\n%2\tclaude\t/dev/ttys002\t1700000000\t1700000001\tWAITING\tclaude\tpermission\t1\tagent\twork\n")
+	want := []Pane{{ID: "%1", Command: "zsh", TTY: "/dev/ttys001"}, {
+		ID: "%2", Command: "claude", TTY: "/dev/ttys002", WindowActivity: 1700000000,
+		Recorded:   RecordedDetection{CheckedAt: 1700000001, Status: StatusWaiting, Agent: AgentClaude, Detail: "permission", Detector: "1"},
+		WindowName: "agent\twork",
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parsePaneList() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClassifyCommand(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected AgentType
+	}{
+		{"claude --resume", AgentClaude},
+		{"/usr/local/bin/codex", AgentCodex},
+		{"npx claude", AgentClaude},
+		{"npx -y @anthropic-ai/claude-code", AgentClaude},
+		{"bunx @openai/codex", AgentCodex},
+		{"pnpm dlx opencode", AgentOpenCode},
+		{"node /usr/lib/node_modules/@anthropic-ai/claude-code/cli.js", AgentClaude},
+		{"node server.js", AgentNone},
+		{"vim claude-notes.md", AgentNone},
+		{"-zsh", AgentNone},
+		{"", AgentNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := ClassifyCommand(tt.command); got != tt.expected {
+				t.Fatalf("ClassifyCommand(%q) = %q, want %q", tt.command, got, tt.expected)
+			}
 		})
 	}
 }
@@ -357,10 +720,13 @@ func TestClient_GetPaneStatus(t *testing.T) {
 					if len(args) > 0 && args[0] == "capture-pane" {
 						return []byte(tt.paneContent), nil
 					}
+					if len(args) > 0 && args[0] == "display-message" {
+						return []byte("%4\t" + tt.cmdOutput + "\t/dev/ttys001\t1700000000\t"), tt.cmdErr
+					}
 					return []byte(tt.cmdOutput), tt.cmdErr
 				},
 			}
-			status := client.GetPaneStatus("session", "window")
+			status := client.GetPaneStatus("@4")
 			if status != tt.expected {
 				t.Errorf("GetPaneStatus() = %v, want %v", status, tt.expected)
 			}
@@ -461,6 +827,230 @@ func TestHasPromptIndicator(t *testing.T) {
 	}
 }
 
+func TestSetStatusProfile(t *testing.T) {
+	t.Cleanup(func() { SetStatusProfile(DefaultStatusProfile) })
+	SetStatusProfile(StatusProfile{
+		BusyStrings:    []string{"brewing"},
+		SpinnerChars:   []rune{'◴'},
+		PromptStrings:  []string{"apply patch?"},
+		BusyRegexps:    []*regexp.Regexp{regexp.MustCompile(`Working \(\d+s\)`)},
+		WaitingRegexps: []*regexp.Regexp{regexp.MustCompile(`approve \d+ edits`)},
+	})
+
+	tests := []struct {
+		content string
+		status  Status
+	}{
+		{"Brewing a plan", StatusWorking},
+		{"◴ compiling", StatusWorking},
+		{"Working (12s)", StatusWorking},
+		{"Apply patch? [y]", StatusWaiting},
+		{"approve 3 edits", StatusWaiting},
+		{"ctrl+c to interrupt", StatusIdle},
+		{"Continue?", StatusIdle},
+		{"⣾ loading", StatusIdle},
+	}
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			client := &Client{execCommand: func(name string, args ...string) ([]byte, error) {
+				return []byte(tt.content), nil
+			}}
+			if got := client.detectAgentActivity("@1").Status; got != tt.status {
+				t.Fatalf("detectAgentActivity(%q) = %s, want %s", tt.content, got, tt.status)
+			}
+		})
+	}
+}
+
+func TestHasInputPrompt(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"arrow prompt", "Done editing.\n> ", true},
+		{"chevron prompt", "Ready\n❯ ", true},
+		{"permission dialog", "Yes, allow once\n❯ ", false},
+		{"confirmation", "Continue? (Y/n)\n> ", false},
+		{"no prompt", "Just some text", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasInputPrompt(tt.content); got != tt.want {
+				t.Errorf("hasInputPrompt(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasErrorIndicator(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"api error banner", "⏺ API Error: 500 {\"type\":\"error\"}\n> ", true},
+		{"python traceback", "Traceback (most recent call last):\n  File \"x.py\"", true},
+		{"go panic", "panic: runtime error: index out of range\n\ngoroutine 1 [running]:", true},
+		{"missing command", "zsh: command not found: claude\n$ ", true},
+		{"plain prompt", "Done editing.\n> ", false},
+		{"error scrolled out of the tail", "API Error: 529\n" + strings.Repeat("line\n", errorTailLines), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasErrorIndicator(tt.content); got != tt.want {
+				t.Errorf("hasErrorIndicator(%q) = %v, want %v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitReset(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantReset   string
+		wantLimited bool
+	}{
+		{"claude usage limit", "⏺ Claude usage limit reached. Your limit will reset at 3pm (America/New_York).\n> ", "3pm (America/New_York)", true},
+		{"claude session limit", "5-hour limit reached ∙ resets 10pm\n> ", "10pm", true},
+		{"codex usage limit", "■ You've hit your usage limit. Upgrade to Pro, or try again in 2 days 3 hours 5 minutes.", "in 2 days 3 hours 5 minutes", true},
+		{"rate limit without reset", "stream error: 429 Too Many Requests", "", true},
+		{"approaching limit", "Approaching usage limit · resets at 10pm\n> ", "", false},
+		{"no limit", "Done editing.\n> ", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset, limited := limitReset(tt.content)
+			if reset != tt.wantReset || limited != tt.wantLimited {
+				t.Fatalf("limitReset(%q) = %q, %v; want %q, %v", tt.content, reset, limited, tt.wantReset, tt.wantLimited)
+			}
+		})
+	}
+}
+
+func TestWaitingReason(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		wantReason WaitingReason
+		wantOK     bool
+	}{
+		{"permission dialog", "Bash(rm -rf build)\n❯ 1. Yes\n  2. Yes, allow always\n  3. No, and tell Claude what to do", WaitingPermission, true},
+		{"confirmation prompt", "Overwrite config? Continue? (y/n)", WaitingQuestion, true},
+		{"input prompt", "Done editing.\n> ", WaitingInput, true},
+		{"no prompt", "Compiling packages...", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, ok := waitingReason(tt.content)
+			if reason != tt.wantReason || ok != tt.wantOK {
+				t.Fatalf("waitingReason(%q) = %q, %v; want %q, %v", tt.content, reason, ok, tt.wantReason, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLastAgentMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "claude response above input box",
+			content: "⏺ Reading files\n\n⏺ Fixed the redirect in login.go.\n  Tests pass.\n\n╭────╮\n│ >  │\n╰────╯\n  ? for shortcuts\n",
+			want:    "Fixed the redirect in login.go.\nTests pass.",
+		},
+		{
+			name:    "codex bullet before prompt",
+			content: "• Updated CHANGELOG.md\n\n› \n",
+			want:    "Updated CHANGELOG.md",
+		},
+		{
+			name:    "no marker falls back to last lines",
+			content: "one\ntwo\nthree\nfour\n> \n",
+			want:    "two\nthree\nfour",
+		},
+		{name: "empty", content: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LastAgentMessage(tt.content); got != tt.want {
+				t.Errorf("LastAgentMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_CapturePane(t *testing.T) {
+	var capturedArgs []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			capturedArgs = args
+			return []byte("output"), nil
+		},
+	}
+
+	got, err := client.CapturePane("@3", 200)
+	if err != nil || got != "output" {
+		t.Fatalf("CapturePane() = %q, %v", got, err)
+	}
+	if strings.Join(capturedArgs, " ") != "capture-pane -t @3 -p -S -200" {
+		t.Fatalf("args = %v", capturedArgs)
+	}
+}
+
+func TestClient_WithContextKillsCommands(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	client := NewClient().WithContext(ctx)
+
+	started := time.Now()
+	if _, err := client.run("sleep", "5"); err == nil {
+		t.Fatal("run() error = nil, want the command killed")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("run() took %s, want it killed at the deadline", elapsed)
+	}
+}
+
+func TestClient_ForRefreshMemoizesCaptures(t *testing.T) {
+	captures := map[string]int{}
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			if name == "tmux" && args[0] == "capture-pane" {
+				captures[args[2]]++
+			}
+			return []byte("Done editing.\n> "), nil
+		},
+	}
+
+	refresh := client.ForRefresh()
+	for range 3 {
+		refresh.detectAgentActivity("%1")
+	}
+	refresh.AtInputPrompt("%1")
+	refresh.detectAgentActivity("%2")
+	if captures["%1"] != 1 || captures["%2"] != 1 {
+		t.Fatalf("captures in one refresh = %v, want one per pane", captures)
+	}
+
+	client.detectAgentActivity("%1")
+	client.ForRefresh().detectAgentActivity("%1")
+	if captures["%1"] != 3 {
+		t.Fatalf("captures of %%1 = %d, want 3 (no memo outside a refresh)", captures["%1"])
+	}
+}
+
 func TestDetectionPriority(t *testing.T) {
 	// Verify busy takes precedence over prompt
 	tests := []struct {
@@ -674,6 +1264,82 @@ func TestClient_CreateWindowWithShellInDir(t *testing.T) {
 	}
 }
 
+func TestClient_KillSession(t *testing.T) {
+	var capturedArgs []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			capturedArgs = args
+			return nil, nil
+		},
+	}
+
+	if err := client.KillSession("cb_demo"); err != nil {
+		t.Fatalf("KillSession() error = %v", err)
+	}
+
+	expected := []string{"kill-session", "-t", "cb_demo"}
+	if strings.Join(capturedArgs, " ") != strings.Join(expected, " ") {
+		t.Fatalf("args = %v, want %v", capturedArgs, expected)
+	}
+}
+
+func TestClient_RenameSession(t *testing.T) {
+	var capturedArgs []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			capturedArgs = args
+			return nil, nil
+		},
+	}
+
+	if err := client.RenameSession("scratch", "cb_scratch"); err != nil {
+		t.Fatalf("RenameSession() error = %v", err)
+	}
+
+	expected := []string{"rename-session", "-t", "=scratch", "cb_scratch"}
+	if strings.Join(capturedArgs, " ") != strings.Join(expected, " ") {
+		t.Fatalf("args = %v, want %v", capturedArgs, expected)
+	}
+}
+
+func TestClient_SendText(t *testing.T) {
+	var calls []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, strings.Join(args, " "))
+			return nil, nil
+		},
+	}
+
+	if err := client.SendText("@7", "fix the Enter key"); err != nil {
+		t.Fatalf("SendText() error = %v", err)
+	}
+
+	expected := []string{
+		"send-keys -t @7 -l fix the Enter key",
+		"send-keys -t @7 Enter",
+	}
+	if strings.Join(calls, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("calls = %v, want %v", calls, expected)
+	}
+}
+
+func TestClient_KillSession_Error(t *testing.T) {
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			return nil, errors.New("tmux error")
+		},
+	}
+
+	err := client.KillSession("cb_demo")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), "failed to kill session") {
+		t.Errorf("error = %q, want to contain 'failed to kill session'", err)
+	}
+}
+
 func TestClient_AttachSession_Error(t *testing.T) {
 	client := &Client{
 		execInteractive: func(name string, args ...string) error {
@@ -715,12 +1381,12 @@ func TestClient_SelectWindow(t *testing.T) {
 		},
 	}
 
-	err := client.SelectWindow("cb_test", 3)
+	err := client.SelectWindow("@7")
 	if err != nil {
 		t.Fatalf("SelectWindow() error = %v", err)
 	}
 
-	expected := []string{"tmux", "select-window", "-t", "cb_test:3"}
+	expected := []string{"tmux", "select-window", "-t", "@7"}
 	if len(capturedArgs) != len(expected) {
 		t.Fatalf("args = %v, want %v", capturedArgs, expected)
 	}
@@ -756,6 +1422,41 @@ func TestClient_SetSessionOption(t *testing.T) {
 	}
 }
 
+func TestClient_UnsetSessionOption(t *testing.T) {
+	var capturedArgs []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			capturedArgs = append([]string{name}, args...)
+			return nil, nil
+		},
+	}
+
+	if err := client.UnsetSessionOption("cb_test", SessionOptionNote); err != nil {
+		t.Fatalf("UnsetSessionOption() error = %v", err)
+	}
+	expected := []string{"tmux", "set-option", "-u", "-t", "cb_test", "@cb_note"}
+	if strings.Join(capturedArgs, " ") != strings.Join(expected, " ") {
+		t.Fatalf("args = %v, want %v", capturedArgs, expected)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{raw: "", want: nil},
+		{raw: "backend", want: []string{"backend"}},
+		{raw: "urgent, Backend urgent", want: []string{"backend", "urgent"}},
+		{raw: " ,, ", want: nil},
+	}
+	for _, tt := range tests {
+		if got := ParseTags(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseTags(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
 func TestClient_SetSessionOption_Error(t *testing.T) {
 	client := &Client{
 		execCommand: func(name string, args ...string) ([]byte, error) {
@@ -800,6 +1501,33 @@ func TestClient_GetSessionOption(t *testing.T) {
 	}
 }
 
+func TestClient_SessionActivity(t *testing.T) {
+	var capturedArgs []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			capturedArgs = append([]string{name}, args...)
+			return []byte("1699990000\n1700000000\n1699000000\n"), nil
+		},
+	}
+
+	got, err := client.SessionActivity("cb_test")
+	if err != nil {
+		t.Fatalf("SessionActivity() error = %v", err)
+	}
+	if !got.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("SessionActivity() = %v, want unix 1700000000", got)
+	}
+	expected := []string{"tmux", "list-windows", "-t", "=cb_test:", "-F", "#{window_activity}"}
+	if !reflect.DeepEqual(capturedArgs, expected) {
+		t.Fatalf("args = %v, want %v", capturedArgs, expected)
+	}
+
+	client.execCommand = func(name string, args ...string) ([]byte, error) { return []byte("\n"), nil }
+	if _, err := client.SessionActivity("cb_test"); err == nil {
+		t.Fatal("SessionActivity() with empty output should fail")
+	}
+}
+
 func TestClient_GetSessionOption_Error(t *testing.T) {
 	client := &Client{
 		execCommand: func(name string, args ...string) ([]byte, error) {
@@ -968,6 +1696,20 @@ func TestClient_GetWindowWorkingDir(t *testing.T) {
 	}
 }
 
+func TestProjectPath(t *testing.T) {
+	tests := map[string]string{
+		"/Users/ron/code/my-project":                              "/Users/ron/code/my-project",
+		"/Users/ron/code/my-project/.worktrees/my-project-feat":   "/Users/ron/code/my-project",
+		"/Users/ron/code/my-project/.worktrees/my-project-feat-2": "/Users/ron/code/my-project",
+		"/Users/ron/code/other/worktrees/other-feat":              "/Users/ron/code/other/worktrees/other-feat",
+	}
+	for root, want := range tests {
+		if got := projectPath(root); got != want {
+			t.Errorf("projectPath(%q) = %q, want %q", root, got, want)
+		}
+	}
+}
+
 func TestClient_GetRepoName(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1015,3 +1757,317 @@ func TestClient_GetRepoName(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetRepoNameCachesRepoRoots(t *testing.T) {
+	paneDir := "/Users/ron/code/my-project/.worktrees/my-project-feat"
+	gitCalls := 0
+	client := &Client{
+		repoRoots: &repoRootCache{byDir: make(map[string]string)},
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			if name == "git" {
+				gitCalls++
+				if args[1] == "/tmp/scratch" {
+					return nil, errors.New("not a git repository")
+				}
+				return []byte("/Users/ron/code/my-project\n"), nil
+			}
+			return []byte(paneDir), nil
+		},
+	}
+
+	for range 3 {
+		if got := client.GetRepoName("cb_test"); got != "my-project" {
+			t.Fatalf("GetRepoName() = %q, want my-project", got)
+		}
+	}
+	if gitCalls != 1 {
+		t.Fatalf("git calls = %d, want 1 for an unchanged pane directory", gitCalls)
+	}
+
+	paneDir = "/tmp/scratch"
+	client.GetRepoName("cb_test")
+	client.GetRepoName("cb_test")
+	if gitCalls != 3 {
+		t.Fatalf("git calls = %d, want a lookup per call outside a repository", gitCalls)
+	}
+}
+
+func TestClient_RestoreHelpers(t *testing.T) {
+	var calls []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, strings.Join(args, " "))
+			if args[0] == "has-session" && args[2] == "=cb_missing" {
+				return nil, errors.New("can't find session")
+			}
+			return nil, nil
+		},
+	}
+
+	if err := client.RenameWindow("cb_demo", "shell"); err != nil {
+		t.Fatalf("RenameWindow() error = %v", err)
+	}
+	if err := client.SendCommand("cb_demo", "claude", "claude"); err != nil {
+		t.Fatalf("SendCommand() error = %v", err)
+	}
+	if !client.HasSession("cb_demo") {
+		t.Error("HasSession(cb_demo) = false, want true")
+	}
+	if client.HasSession("cb_missing") {
+		t.Error("HasSession(cb_missing) = true, want false")
+	}
+
+	expected := []string{
+		"rename-window -t cb_demo shell",
+		"send-keys -t cb_demo:claude claude Enter",
+		"has-session -t =cb_demo",
+		"has-session -t =cb_missing",
+	}
+	if strings.Join(calls, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("calls = %v, want %v", calls, expected)
+	}
+}
+
+func TestParseWindowLayouts(t *testing.T) {
+	output := "0\t1\tb25d,191x50,0,0,1\n1\t2\t5e1c,191x50,0,0{95x50,0,0,2,95x50,96,0,3}\nbad line\n"
+
+	got := ParseWindowLayouts(output)
+	if len(got) != 2 {
+		t.Fatalf("len(layouts) = %d, want 2", len(got))
+	}
+	if got[1].Panes != 2 || got[1].Layout != "5e1c,191x50,0,0{95x50,0,0,2,95x50,96,0,3}" {
+		t.Fatalf("layout[1] = %+v", got[1])
+	}
+	if got[0].Panes != 1 {
+		t.Fatalf("layout[0] = %+v", got[0])
+	}
+}
+
+func TestClient_LayoutCommands(t *testing.T) {
+	var calls []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			calls = append(calls, strings.Join(args, " "))
+			if args[0] == "split-window" {
+				return []byte("%7\n"), nil
+			}
+			return nil, nil
+		},
+	}
+
+	paneID, err := client.SplitWindow("cb_demo", "editor", "/wt")
+	if err != nil {
+		t.Fatalf("SplitWindow() error = %v", err)
+	}
+	if paneID != "%7" {
+		t.Fatalf("SplitWindow() pane = %q, want %%7", paneID)
+	}
+	if err := client.SendCommandToPane(paneID, "npm test"); err != nil {
+		t.Fatalf("SendCommandToPane() error = %v", err)
+	}
+	if err := client.SelectLayout("cb_demo", "editor", "abcd,80x24,0,0,1"); err != nil {
+		t.Fatalf("SelectLayout() error = %v", err)
+	}
+
+	expected := []string{
+		"split-window -d -P -F #{pane_id} -t cb_demo:editor -c /wt",
+		"send-keys -t %7 npm test Enter",
+		"select-layout -t cb_demo:editor abcd,80x24,0,0,1",
+	}
+	if strings.Join(calls, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("calls = %v, want %v", calls, expected)
+	}
+}
+
+func TestParseProcessStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   AgentProcess
+		wantOK bool
+	}{
+		{
+			name:   "single agent",
+			output: " 4242 00:00:12 204800   01:02:03 claude --resume\n",
+			want:   AgentProcess{PID: 4242, CPUTime: 12 * time.Second, RSSKB: 204800, Elapsed: time.Hour + 2*time.Minute + 3*time.Second, Command: "claude --resume"},
+			wantOK: true,
+		},
+		{
+			name: "sums agent processes and skips the shell",
+			output: "  100  0:00.01   2048 2-00:00:05 -zsh\n" +
+				"  200  5:00.50 100000      10:00 node /usr/local/bin/codex\n" +
+				"  201  0:30.25  50000      09:59 codex-helper\n",
+			want:   AgentProcess{PID: 200, CPUTime: 5*time.Minute + 30*time.Second + 750*time.Millisecond, RSSKB: 150000, Elapsed: 10 * time.Minute, Command: "node /usr/local/bin/codex"},
+			wantOK: true,
+		},
+		{
+			name:   "no agent",
+			output: "  100 00:00:00   2048 00:05 -zsh\n  101 00:00:01   4096 00:01 vim main.go\n",
+			wantOK: false,
+		},
+		{
+			name:   "malformed lines",
+			output: "garbage\nabc 00:00:01 10 00:01 claude\n  7 1.0 10 00:01 claude\n",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseProcessStats(tt.output)
+			if ok != tt.wantOK {
+				t.Fatalf("parseProcessStats() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Fatalf("parseProcessStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseElapsed(t *testing.T) {
+	tests := []struct {
+		etime   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{etime: "00:07", want: 7 * time.Second},
+		{etime: "12:34", want: 12*time.Minute + 34*time.Second},
+		{etime: "01:00:00", want: time.Hour},
+		{etime: "3-04:05:06", want: 3*24*time.Hour + 4*time.Hour + 5*time.Minute + 6*time.Second},
+		{etime: "7", wantErr: true},
+		{etime: "x-01:00", wantErr: true},
+		{etime: "aa:bb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.etime, func(t *testing.T) {
+			got, err := parseElapsed(tt.etime)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseElapsed(%q) err = %v, wantErr %v", tt.etime, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("parseElapsed(%q) = %v, want %v", tt.etime, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCPUTime(t *testing.T) {
+	tests := []struct {
+		cputime string
+		want    time.Duration
+		wantErr bool
+	}{
+		{cputime: "00:00:07", want: 7 * time.Second},
+		{cputime: "1-02:00:00", want: 26 * time.Hour},
+		{cputime: "0:01.25", want: time.Second + 250*time.Millisecond},
+		{cputime: "123:45.67", want: 123*time.Minute + 45*time.Second + 670*time.Millisecond},
+		{cputime: "1.0", wantErr: true},
+		{cputime: "0:01.x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cputime, func(t *testing.T) {
+			got, err := parseCPUTime(tt.cputime)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCPUTime(%q) err = %v, wantErr %v", tt.cputime, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("parseCPUTime(%q) = %v, want %v", tt.cputime, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_AgentProcessStats(t *testing.T) {
+	var psArgs []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			if name == "tmux" {
+				return []byte("/dev/ttys003\n"), nil
+			}
+			psArgs = args
+			return []byte("  300 00:00:03  1024   00:30 opencode\n"), nil
+		},
+	}
+
+	got, ok := client.AgentProcessStats("@7")
+	if !ok {
+		t.Fatal("AgentProcessStats() ok = false, want true")
+	}
+	if got.PID != 300 || got.CPUTime != 3*time.Second || got.RSSKB != 1024 {
+		t.Fatalf("AgentProcessStats() = %+v", got)
+	}
+	if psArgs[0] != "-t" || psArgs[1] != "/dev/ttys003" {
+		t.Fatalf("ps args = %v, want -t /dev/ttys003 ...", psArgs)
+	}
+}
+
+func TestResumeCommand(t *testing.T) {
+	t.Cleanup(func() { SetResumeCommands(map[AgentType]string{}) })
+
+	if got := AgentClaude.ResumeCommand(); got != "claude --continue" {
+		t.Errorf("AgentClaude.ResumeCommand() = %q, want claude --continue", got)
+	}
+	SetResumeCommands(map[AgentType]string{AgentClaude: "claude --resume"})
+	if got := AgentClaude.ResumeCommand(); got != "claude --resume" {
+		t.Errorf("configured ResumeCommand() = %q, want claude --resume", got)
+	}
+	if got := AgentCodex.ResumeCommand(); got != "codex resume --last" {
+		t.Errorf("AgentCodex.ResumeCommand() = %q, want the built-in command", got)
+	}
+	if got := AgentNone.ResumeCommand(); got != "" {
+		t.Errorf("AgentNone.ResumeCommand() = %q, want empty", got)
+	}
+}
+
+func TestClient_ListSessionOptions(t *testing.T) {
+	var gotArgs []string
+	client := &Client{
+		execCommand: func(name string, args ...string) ([]byte, error) {
+			gotArgs = args
+			return []byte("cb_demo\t/tmp/repo\tfix login\turgent\tfeat\t1700000000\tclaude\trepo\ncb_bare\t\t\t\t\t\t\t\n"), nil
+		},
+	}
+
+	all, err := client.ListSessionOptions()
+	if err != nil {
+		t.Fatalf("ListSessionOptions() error = %v", err)
+	}
+	if len(gotArgs) != 3 || gotArgs[0] != "list-sessions" || !strings.Contains(gotArgs[2], "#{"+SessionOptionAgent+"}") {
+		t.Fatalf("args = %v, want one list-sessions reading the options", gotArgs)
+	}
+	demo := all["cb_demo"]
+	if demo[SessionOptionHomePath] != "/tmp/repo" || demo[SessionOptionNote] != "fix login" || demo[SessionOptionAgent] != "claude" || demo[SessionOptionProject] != "repo" {
+		t.Fatalf("cb_demo options = %v", demo)
+	}
+	if bare, ok := all["cb_bare"]; !ok || len(bare) != 0 {
+		t.Fatalf("cb_bare options = %v, %v; want present and empty", bare, ok)
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		existing map[string]struct{}
+		want     string
+	}{
+		{name: "unused base", base: "demo", existing: map[string]struct{}{}, want: "demo"},
+		{name: "first suffix", base: "demo", existing: map[string]struct{}{"demo": {}}, want: "demo-2"},
+		{name: "next suffix", base: "demo", existing: map[string]struct{}{"demo": {}, "demo-2": {}}, want: "demo-3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UniqueName(tt.base, func(name string) bool {
+				_, ok := tt.existing[name]
+				return ok
+			})
+			if got != tt.want {
+				t.Fatalf("UniqueName(%q) = %q, want %q", tt.base, got, tt.want)
+			}
+		})
+	}
+}
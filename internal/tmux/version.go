@@ -0,0 +1,128 @@
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is a tmux release as reported by "tmux -V" ("tmux 3.3a" is 3.3).
+// Dev marks development and OS-bundled builds ("tmux master",
+// "tmux next-3.5", "tmux openbsd-7.4"), which track the newest release.
+type Version struct {
+	Major int
+	Minor int
+	Dev   bool
+}
+
+// MinVersion is the oldest tmux ClawdBay supports.
+var MinVersion = Version{Major: 3, Minor: 0}
+
+// PopupVersion is the first tmux with display-popup.
+var PopupVersion = Version{Major: 3, Minor: 2}
+
+// ErrUnsupportedVersion means the installed tmux is older than MinVersion.
+var ErrUnsupportedVersion = errors.New("unsupported tmux version")
+
+func (v Version) String() string {
+	if v.Dev && v.Major == 0 {
+		return "dev"
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// AtLeast reports whether v is min or newer. Dev builds always are.
+func (v Version) AtLeast(min Version) bool {
+	if v.Dev {
+		return true
+	}
+	if v.Major != min.Major {
+		return v.Major > min.Major
+	}
+	return v.Minor >= min.Minor
+}
+
+// ParseVersion parses "tmux -V" output.
+func ParseVersion(output string) (Version, error) {
+	raw, ok := strings.CutPrefix(strings.TrimSpace(output), "tmux ")
+	if !ok {
+		return Version{}, fmt.Errorf("unrecognized tmux version %q", strings.TrimSpace(output))
+	}
+	var v Version
+	if prefix, rest, found := strings.Cut(raw, "-"); found && !startsWithDigit(prefix) {
+		v.Dev = true
+		if prefix == "openbsd" {
+			return v, nil
+		}
+		raw = rest
+	}
+	if raw == "master" {
+		v.Dev = true
+		return v, nil
+	}
+
+	major, minor, _ := strings.Cut(raw, ".")
+	var err error
+	if v.Major, err = strconv.Atoi(major); err != nil {
+		return Version{}, fmt.Errorf("unrecognized tmux version %q", raw)
+	}
+	// Drop patch letters and release-candidate suffixes ("3a", "4-rc").
+	digits := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' })
+	if digits >= 0 {
+		minor = minor[:digits]
+	}
+	if minor != "" {
+		if v.Minor, err = strconv.Atoi(minor); err != nil {
+			return Version{}, fmt.Errorf("unrecognized tmux version %q", raw)
+		}
+	}
+	return v, nil
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
+
+// installedVersion caches the tmux version once it has been read: every
+// command checks it on startup, and the binary does not change under a
+// running cb.
+var installedVersion struct {
+	sync.Mutex
+	version Version
+	read    bool
+}
+
+// Version returns the installed tmux version, running "tmux -V" only the
+// first time it succeeds in this process.
+func (c *Client) Version() (Version, error) {
+	installedVersion.Lock()
+	defer installedVersion.Unlock()
+	if installedVersion.read {
+		return installedVersion.version, nil
+	}
+	output, err := c.run("tmux", "-V")
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to read tmux version: %w", err)
+	}
+	v, err := ParseVersion(string(output))
+	if err != nil {
+		return Version{}, err
+	}
+	installedVersion.version, installedVersion.read = v, true
+	return v, nil
+}
+
+// CheckVersion returns the installed tmux version, or an error wrapping
+// ErrUnsupportedVersion that names the minimum when it is too old.
+func (c *Client) CheckVersion() (Version, error) {
+	v, err := c.Version()
+	if err != nil {
+		return Version{}, err
+	}
+	if !v.AtLeast(MinVersion) {
+		return v, fmt.Errorf("%w: tmux %s is installed, but ClawdBay needs tmux %s or newer; please upgrade tmux", ErrUnsupportedVersion, v, MinVersion)
+	}
+	return v, nil
+}
@@ -0,0 +1,89 @@
+package tmux
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    Version
+		wantErr bool
+	}{
+		{output: "tmux 3.3a\n", want: Version{Major: 3, Minor: 3}},
+		{output: "tmux 2.9", want: Version{Major: 2, Minor: 9}},
+		{output: "tmux 3.4-rc", want: Version{Major: 3, Minor: 4}},
+		{output: "tmux next-3.5", want: Version{Major: 3, Minor: 5, Dev: true}},
+		{output: "tmux master", want: Version{Dev: true}},
+		{output: "tmux openbsd-7.4", want: Version{Dev: true}},
+		{output: "screen 4.9", wantErr: true},
+		{output: "tmux banana", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			got, err := ParseVersion(tt.output)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("ParseVersion(%q) = %+v, %v; want %+v (error %v)", tt.output, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+// resetVersionCache forgets the cached tmux version for the duration of a
+// test.
+func resetVersionCache(t *testing.T) {
+	t.Helper()
+	installedVersion.Lock()
+	installedVersion.read = false
+	installedVersion.Unlock()
+	t.Cleanup(func() {
+		installedVersion.Lock()
+		installedVersion.read = false
+		installedVersion.Unlock()
+	})
+}
+
+func TestClient_VersionIsReadOnce(t *testing.T) {
+	resetVersionCache(t)
+	calls := 0
+	client := &Client{execCommand: func(name string, args ...string) ([]byte, error) {
+		calls++
+		return []byte("tmux 3.4"), nil
+	}}
+
+	for range 3 {
+		v, err := client.Version()
+		if err != nil || v != (Version{Major: 3, Minor: 4}) {
+			t.Fatalf("Version() = %v, %v; want 3.4", v, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("tmux -V ran %d times, want 1", calls)
+	}
+}
+
+func TestClient_CheckVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		wantErr bool
+	}{
+		{output: "tmux 3.0", wantErr: false},
+		{output: "tmux master", wantErr: false},
+		{output: "tmux 2.9a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			resetVersionCache(t)
+			client := &Client{execCommand: func(name string, args ...string) ([]byte, error) {
+				return []byte(tt.output), nil
+			}}
+			_, err := client.CheckVersion()
+			if got := errors.Is(err, ErrUnsupportedVersion); got != tt.wantErr {
+				t.Fatalf("CheckVersion() with %q error = %v, want unsupported %v", tt.output, err, tt.wantErr)
+			}
+		})
+	}
+}
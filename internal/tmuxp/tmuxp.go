@@ -0,0 +1,141 @@
+// Package tmuxp converts tmuxp and tmuxinator YAML session files into
+// ClawdBay session templates.
+package tmuxp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+)
+
+// Parse converts a tmuxp or tmuxinator session file into a session template.
+// Window names, layouts, and pane commands are kept; start directories are
+// dropped because ClawdBay always starts windows in the session's worktree.
+// Multiple commands for one pane are chained with " && ".
+func Parse(content []byte) (config.SessionTemplate, error) {
+	doc, err := parseYAML(string(content))
+	if err != nil {
+		return config.SessionTemplate{}, err
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return config.SessionTemplate{}, fmt.Errorf("expected a mapping at the top level")
+	}
+
+	tmpl := config.SessionTemplate{Name: firstString(root, "session_name", "name")}
+	before := commandList(root["shell_command_before"])
+
+	windows, ok := root["windows"].([]any)
+	if !ok || len(windows) == 0 {
+		return config.SessionTemplate{}, fmt.Errorf("no windows defined")
+	}
+	for i, raw := range windows {
+		w, ok := raw.(map[string]any)
+		if !ok {
+			return config.SessionTemplate{}, fmt.Errorf("windows[%d]: expected a mapping", i)
+		}
+
+		var window config.TemplateWindow
+		if _, isTmuxp := w["window_name"]; isTmuxp || len(w) != 1 {
+			window, err = tmuxpWindow(w, before)
+		} else {
+			window, err = tmuxinatorWindow(w)
+		}
+		if err != nil {
+			return config.SessionTemplate{}, fmt.Errorf("windows[%d]: %w", i, err)
+		}
+		tmpl.Windows = append(tmpl.Windows, window)
+	}
+	return tmpl, nil
+}
+
+// tmuxpWindow converts {window_name, layout, shell_command_before, panes}.
+func tmuxpWindow(w map[string]any, sessionBefore []string) (config.TemplateWindow, error) {
+	window := config.TemplateWindow{
+		Name:   firstString(w, "window_name"),
+		Layout: firstString(w, "layout"),
+	}
+	if window.Name == "" {
+		return config.TemplateWindow{}, fmt.Errorf("window_name is required")
+	}
+	before := append(append([]string(nil), sessionBefore...), commandList(w["shell_command_before"])...)
+
+	panes, _ := w["panes"].([]any)
+	if len(panes) == 0 {
+		window.Panes = []string{chain(before, nil)}
+	}
+	for _, pane := range panes {
+		var cmds []string
+		if m, ok := pane.(map[string]any); ok {
+			cmds = commandList(m["shell_command"])
+		} else {
+			cmds = commandList(pane)
+		}
+		window.Panes = append(window.Panes, chain(before, cmds))
+	}
+	window.Panes = trimTrailingEmpty(window.Panes)
+	return window, nil
+}
+
+// tmuxinatorWindow converts the single-key forms "name: cmd", "name: [cmds]",
+// and "name: {layout, panes}".
+func tmuxinatorWindow(w map[string]any) (config.TemplateWindow, error) {
+	for name, value := range w {
+		window := config.TemplateWindow{Name: name}
+		if m, ok := value.(map[string]any); ok {
+			window.Layout = firstString(m, "layout")
+			panes, _ := m["panes"].([]any)
+			for _, pane := range panes {
+				window.Panes = append(window.Panes, chain(nil, commandList(pane)))
+			}
+		} else {
+			window.Panes = []string{chain(nil, commandList(value))}
+		}
+		window.Panes = trimTrailingEmpty(window.Panes)
+		return window, nil
+	}
+	return config.TemplateWindow{}, fmt.Errorf("empty window definition")
+}
+
+// commandList normalizes a string, list of strings, or nil into commands.
+func commandList(v any) []string {
+	switch t := v.(type) {
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return nil
+		}
+		return []string{t}
+	case []any:
+		var cmds []string
+		for _, item := range t {
+			cmds = append(cmds, commandList(item)...)
+		}
+		return cmds
+	default:
+		return nil
+	}
+}
+
+func chain(before, cmds []string) string {
+	all := append(append([]string(nil), before...), cmds...)
+	return strings.Join(all, " && ")
+}
+
+// trimTrailingEmpty drops a lone empty command so a plain shell window stores
+// no panes at all.
+func trimTrailingEmpty(panes []string) []string {
+	if len(panes) == 1 && panes[0] == "" {
+		return nil
+	}
+	return panes
+}
+
+func firstString(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
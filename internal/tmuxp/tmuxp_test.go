@@ -0,0 +1,100 @@
+package tmuxp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/config"
+)
+
+func TestParse_Tmuxp(t *testing.T) {
+	content := `
+session_name: web   # the app
+start_directory: ~/code/web
+shell_command_before:
+  - source .env
+windows:
+  - window_name: editor
+    layout: main-vertical
+    panes:
+      - shell_command:
+          - nvim
+      - "npm run dev"
+      -
+  - window_name: shell
+  - window_name: logs
+    shell_command_before: cd logs
+    panes:
+    - shell_command: [tail -f app.log]
+`
+
+	got, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := config.SessionTemplate{
+		Name: "web",
+		Windows: []config.TemplateWindow{
+			{Name: "editor", Layout: "main-vertical", Panes: []string{"source .env && nvim", "source .env && npm run dev", "source .env"}},
+			{Name: "shell", Panes: []string{"source .env"}},
+			{Name: "logs", Panes: []string{"source .env && cd logs && tail -f app.log"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParse_Tmuxinator(t *testing.T) {
+	content := `name: api
+root: ~/code/api
+windows:
+  - editor: vim
+  - shell:
+  - server:
+      layout: even-horizontal
+      panes:
+        - rails s
+        - - cd spec
+          - guard
+`
+
+	got, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := config.SessionTemplate{
+		Name: "api",
+		Windows: []config.TemplateWindow{
+			{Name: "editor", Panes: []string{"vim"}},
+			{Name: "shell"},
+			{Name: "server", Layout: "even-horizontal", Panes: []string{"rails s", "cd spec && guard"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "no windows", content: "session_name: x\n"},
+		{name: "top-level list", content: "- a\n- b\n"},
+		{name: "missing window name", content: "windows:\n  - layout: tiled\n    panes: [ls]\n"},
+		{name: "block scalar", content: "session_name: x\nwindows:\n  - window_name: a\n    shell_command_before: |\n      ls\n"},
+		{name: "tab indentation", content: "windows:\n\t- a: b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.content)); err == nil {
+				t.Fatal("Parse() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package tmuxp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The session files we import use a small, regular slice of YAML: block maps,
+// block lists, plain/quoted scalars, and single-line flow lists. yamlLine and
+// parseYAML implement exactly that subset so the importer needs no dependency.
+
+type yamlLine struct {
+	no     int
+	indent int
+	text   string
+}
+
+// parseYAML parses content into nested map[string]any, []any, and string
+// values. Empty values parse as nil.
+func parseYAML(content string) (any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(content, "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		if strings.HasPrefix(strings.TrimLeft(raw, " "), "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		text := strings.TrimSpace(stripYAMLComment(raw))
+		if text == "" || text == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{no: i + 1, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].no)
+	}
+	return value, nil
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	if strings.HasPrefix(p.lines[p.pos].text, "- ") || p.lines[p.pos].text == "-" {
+		return p.parseList(indent)
+	}
+	return p.parseMap(indent)
+}
+
+func (p *yamlParser) parseList(indent int) (any, error) {
+	var items []any
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent != indent || !(strings.HasPrefix(line.text, "- ") || line.text == "-") {
+			break
+		}
+		rest := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+
+		switch {
+		case rest == "":
+			p.pos++
+			item, err := p.parseChild(indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		case strings.HasPrefix(rest, "- "):
+			// "- - a" starts a nested list aligned with the inner dash.
+			itemIndent := indent + (len(line.text) - len(rest))
+			p.lines[p.pos] = yamlLine{no: line.no, indent: itemIndent, text: rest}
+			item, err := p.parseList(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		case isYAMLMapEntry(rest):
+			// "- key: value" starts a map whose remaining keys are indented
+			// to line up with "key".
+			itemIndent := indent + (len(line.text) - len(rest))
+			p.lines[p.pos] = yamlLine{no: line.no, indent: itemIndent, text: rest}
+			item, err := p.parseMap(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		default:
+			p.pos++
+			value, err := parseYAMLScalar(rest, line.no)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+	}
+	return items, nil
+}
+
+func (p *yamlParser) parseMap(indent int) (any, error) {
+	m := map[string]any{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", line.no)
+		}
+		if !isYAMLMapEntry(line.text) {
+			if strings.HasPrefix(line.text, "- ") {
+				break
+			}
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.no)
+		}
+
+		key, value := splitYAMLMapEntry(line.text)
+		p.pos++
+		if value != "" {
+			parsed, err := parseYAMLScalar(value, line.no)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = parsed
+			continue
+		}
+
+		child, err := p.parseChild(indent)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = child
+	}
+	return m, nil
+}
+
+// parseChild parses the nested block after "key:" or "-". Lists may sit at the
+// parent's indentation ("key:\n- a"); anything else must be indented deeper.
+func (p *yamlParser) parseChild(parentIndent int) (any, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > parentIndent {
+		return p.parseBlock(next.indent)
+	}
+	if next.indent == parentIndent && strings.HasPrefix(next.text, "- ") {
+		return p.parseList(next.indent)
+	}
+	return nil, nil
+}
+
+func isYAMLMapEntry(text string) bool {
+	if text == "" || text[0] == '"' || text[0] == '\'' || text[0] == '[' || text[0] == '{' {
+		return false
+	}
+	idx := strings.Index(text, ":")
+	return idx > 0 && (idx == len(text)-1 || text[idx+1] == ' ')
+}
+
+func splitYAMLMapEntry(text string) (string, string) {
+	idx := strings.Index(text, ":")
+	key := strings.TrimSpace(text[:idx])
+	value := strings.TrimSpace(text[idx+1:])
+	if unquoted, err := unquoteYAML(key); err == nil {
+		key = unquoted
+	}
+	return key, value
+}
+
+func parseYAMLScalar(value string, lineNo int) (any, error) {
+	switch {
+	case value == "~" || value == "null":
+		return nil, nil
+	case value == "|" || value == ">" || strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">"):
+		return nil, fmt.Errorf("line %d: block scalars are not supported", lineNo)
+	case strings.HasPrefix(value, "{"):
+		return nil, fmt.Errorf("line %d: flow maps are not supported", lineNo)
+	case strings.HasPrefix(value, "["):
+		if !strings.HasSuffix(value, "]") {
+			return nil, fmt.Errorf("line %d: unterminated flow list", lineNo)
+		}
+		inner := strings.TrimSpace(value[1 : len(value)-1])
+		items := []any{}
+		if inner == "" {
+			return items, nil
+		}
+		for _, part := range splitFlowList(inner) {
+			s, err := unquoteYAML(strings.TrimSpace(part))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			items = append(items, s)
+		}
+		return items, nil
+	default:
+		s, err := unquoteYAML(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		return s, nil
+	}
+}
+
+// splitFlowList splits "a, 'b, c', d" on commas outside quotes.
+func splitFlowList(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == quote:
+			quote = 0
+		case quote == 0 && (s[i] == '"' || s[i] == '\''):
+			quote = s[i]
+		case quote == 0 && s[i] == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unquoteYAML(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		unquoted, err := strconv.Unquote(s)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted string %s", s)
+		}
+		return unquoted, nil
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	}
+	return s, nil
+}
+
+// stripYAMLComment removes a trailing "# comment" that is outside quotes and
+// starts the line or follows whitespace.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" :[,-", line[i-1]) >= 0):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' '):
+			return line[:i]
+		}
+	}
+	return line
+}
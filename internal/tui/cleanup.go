@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cleanupResultMsg is sent after killing the sessions of a missing worktree.
+type cleanupResultMsg struct {
+	Killed []string
+	Err    error
+}
+
+// missingWorktreeSessions returns the sessions "x" cleans up for node: every
+// session of a missing-worktree node, or the node's own session when it sits
+// under one. It returns nil for nodes outside missing worktrees.
+func (m Model) missingWorktreeSessions(node TreeNode) []string {
+	if m.Mode != DashboardModeWorktree || node.RepoIndex < 0 || node.RepoIndex >= len(m.Groups) {
+		return nil
+	}
+	worktrees := m.Groups[node.RepoIndex].Worktrees
+	if node.Type == NodeRepo || node.WorktreeIndex < 0 || node.WorktreeIndex >= len(worktrees) {
+		return nil
+	}
+	worktree := worktrees[node.WorktreeIndex]
+	if !worktree.IsMissing {
+		return nil
+	}
+	if node.Type == NodeWorktree {
+		sessions := make([]string, 0, len(worktree.Sessions))
+		for _, s := range worktree.Sessions {
+			sessions = append(sessions, s.Name)
+		}
+		return sessions
+	}
+	return []string{worktree.Sessions[node.SessionIndex].Name}
+}
+
+// cleanupMissingForNode kills the sessions of the missing worktree under the
+// cursor (see missingWorktreeSessions). The first press only asks for
+// confirmation; pressing "x" again on the same sessions kills them.
+func (m Model) cleanupMissingForNode(node TreeNode) (Model, tea.Cmd) {
+	sessions := m.missingWorktreeSessions(node)
+	if len(sessions) == 0 {
+		m.StatusMsg = "Only sessions of a missing worktree can be cleaned up"
+		return m, nil
+	}
+	client := m.TmuxClient
+	if client == nil {
+		m.StatusMsg = "Error: tmux client is not available"
+		return m, nil
+	}
+
+	key := strings.Join(sessions, "\n")
+	if m.confirmCleanup != key {
+		m.confirmCleanup = key
+		m.StatusMsg = fmt.Sprintf("Kill %s? Press x again to confirm", strings.Join(sessions, ", "))
+		return m, nil
+	}
+	m.confirmCleanup = ""
+	m.StatusMsg = fmt.Sprintf("Killing %d session(s)...", len(sessions))
+	return m, func() tea.Msg {
+		var killed []string
+		var errs []error
+		for _, name := range sessions {
+			if err := client.KillSession(name); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			killed = append(killed, name)
+		}
+		return cleanupResultMsg{Killed: killed, Err: errors.Join(errs...)}
+	}
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ronsanzone/clawd-bay/internal/multiplexer"
+)
+
+// fakeKiller records KillSession calls; other Multiplexer methods are unused.
+type fakeKiller struct {
+	multiplexer.Multiplexer
+	killed []string
+}
+
+func (f *fakeKiller) KillSession(name string) error {
+	f.killed = append(f.killed, name)
+	return nil
+}
+
+func TestCleanupKeyKillsMissingWorktreeSessions(t *testing.T) {
+	client := &fakeKiller{}
+	m := Model{
+		Mode:       DashboardModeWorktree,
+		TmuxClient: client,
+		Styles:     NewStyles(KanagawaClaw),
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Path:     "/repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{
+				{Name: "(main repo)", Path: "/repo", IsMainRepo: true, Expanded: true, Sessions: []WorktreeSession{{Name: "cb_main"}}},
+				{Name: "(missing worktree)", IsMissing: true, Expanded: true, Sessions: []WorktreeSession{{Name: "cb_gone"}, {Name: "cb_pruned"}}},
+			},
+		}},
+	}
+	m.Nodes = BuildNodes(m.Groups)
+	press := func(m Model, key rune) (Model, tea.Cmd) {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		return updated.(Model), cmd
+	}
+
+	m.Cursor = 2 // cb_main
+	if m, cmd := press(m, 'x'); cmd != nil || m.confirmCleanup != "" {
+		t.Fatal("x should not clean up sessions of existing worktrees")
+	}
+
+	m.Cursor = 3 // the missing-worktree node
+	m, cmd := press(m, 'x')
+	if cmd != nil || m.confirmCleanup == "" {
+		t.Fatalf("first x should ask for confirmation, status = %q", m.StatusMsg)
+	}
+	m, _ = press(m, 'j')
+	m.Cursor = 3
+	if m, cmd = press(m, 'x'); cmd != nil {
+		t.Fatal("another key in between should reset the confirmation")
+	}
+
+	_, cmd = press(m, 'x')
+	if cmd == nil {
+		t.Fatal("second x should kill the sessions")
+	}
+	msg, ok := cmd().(cleanupResultMsg)
+	if !ok || msg.Err != nil || len(msg.Killed) != 2 {
+		t.Fatalf("cleanup result = %+v, want both sessions killed", msg)
+	}
+	if len(client.killed) != 2 || client.killed[0] != "cb_gone" || client.killed[1] != "cb_pruned" {
+		t.Fatalf("killed = %v, want [cb_gone cb_pruned]", client.killed)
+	}
+}
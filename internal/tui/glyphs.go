@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Glyphs are the symbols the dashboard draws with. ASCIIGlyphs replaces every
+// non-ASCII symbol, for fonts that render them badly and for copy-pasting.
+type Glyphs struct {
+	Working string
+	Waiting string
+	Idle    string
+	Done    string
+	Error   string
+	Limited string
+	// Shell marks a window checked without an agent; Unknown one not
+	// checked yet.
+	Shell   string
+	Unknown string
+
+	Collapsed string
+	Expanded  string
+	Cursor    string
+	Pin       string
+	Note      string
+	Warning   string
+	Minus     string
+	Ellipsis  string
+	Dot       string
+	// Spinner frames animate the status bar while a refresh runs.
+	Spinner []string
+
+	Border lipgloss.Border
+}
+
+// UnicodeGlyphs is the default glyph set.
+var UnicodeGlyphs = Glyphs{
+	Working: "•",
+	Waiting: "◐",
+	Idle:    "◦",
+	Done:    "·",
+	Error:   "✗",
+	Limited: "◷",
+	Shell:   "›",
+	Unknown: "◌",
+
+	Collapsed: "▸",
+	Expanded:  "▼",
+	Cursor:    "❯",
+	Pin:       "★",
+	Note:      "✎",
+	Warning:   "⚠",
+	Minus:     "−",
+	Ellipsis:  "…",
+	Dot:       "·",
+	Spinner:   []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+
+	Border: lipgloss.RoundedBorder(),
+}
+
+// ASCIIGlyphs is the glyph set for ASCII-only rendering.
+var ASCIIGlyphs = Glyphs{
+	Working: "*",
+	Waiting: "?",
+	Idle:    "o",
+	Done:    ".",
+	Error:   "x",
+	Limited: "z",
+	Shell:   "$",
+	Unknown: "-",
+
+	Collapsed: ">",
+	Expanded:  "v",
+	Cursor:    ">",
+	Pin:       "+",
+	Note:      "~",
+	Warning:   "!",
+	Minus:     "-",
+	Ellipsis:  "...",
+	Dot:       "|",
+	Spinner:   []string{"|", "/", "-", "\\"},
+
+	Border: lipgloss.Border{
+		Top: "-", Bottom: "-", Left: "|", Right: "|",
+		TopLeft: "+", TopRight: "+", BottomLeft: "+", BottomRight: "+",
+		MiddleLeft: "+", MiddleRight: "+", Middle: "+", MiddleTop: "+", MiddleBottom: "+",
+	},
+}
+
+// glyphs returns the model's glyph set.
+func (m Model) glyphs() Glyphs {
+	if m.ASCII {
+		return ASCIIGlyphs
+	}
+	return UnicodeGlyphs
+}
+
+// asciiReplacer rewrites the symbols embedded in dashboard text (diff stats,
+// overlap warnings, note excerpts, footers) to their ASCII equivalents.
+var asciiReplacer = strings.NewReplacer(
+	UnicodeGlyphs.Dot, ASCIIGlyphs.Dot,
+	UnicodeGlyphs.Minus, ASCIIGlyphs.Minus,
+	UnicodeGlyphs.Warning, ASCIIGlyphs.Warning,
+	UnicodeGlyphs.Ellipsis, ASCIIGlyphs.Ellipsis,
+	UnicodeGlyphs.Note, ASCIIGlyphs.Note,
+)
+
+// asciiText returns s with embedded symbols rewritten in ASCII mode.
+func (m Model) asciiText(s string) string {
+	if !m.ASCII {
+		return s
+	}
+	return asciiReplacer.Replace(s)
+}
@@ -1,23 +1,55 @@
 package tui
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"log/slog"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ronsanzone/clawd-bay/internal/activity"
+	"github.com/ronsanzone/clawd-bay/internal/adopt"
 	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/diskusage"
+	"github.com/ronsanzone/clawd-bay/internal/git"
+	"github.com/ronsanzone/clawd-bay/internal/multiplexer"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 )
 
 const refreshInterval = 3 * time.Second
 
+// refreshTimeout bounds a single dashboard refresh. It is a variable so tests
+// can shorten it.
+var refreshTimeout = 10 * time.Second
+
+// maxRefreshInterval caps how far a slow refresh can stretch the next tick.
+const maxRefreshInterval = 30 * time.Second
+
+// spinnerInterval is how often the status bar spinner advances while a
+// refresh runs. Each frame re-renders the whole dashboard, so it stays slow.
+const spinnerInterval = 300 * time.Millisecond
+
+// statusMsgTTL is how long a status message stays in the footer.
+const statusMsgTTL = 5 * time.Second
+
+// clock returns the current time; tests replace it.
+var clock = time.Now
+
 // tickMsg triggers periodic refresh.
 type tickMsg time.Time
 
+// spinnerTickMsg advances the status bar spinner.
+type spinnerTickMsg struct{}
+
 // refreshMsg carries new data from a refresh.
 type refreshMsg struct {
 	Groups         []RepoGroup
@@ -25,7 +57,44 @@ type refreshMsg struct {
 	WindowStatuses map[string]tmux.Status
 	WindowAgents   map[string]tmux.AgentType
 	ConfigMissing  bool
+	Pins           Pins
 	Err            error
+	Duration       time.Duration
+	// ConfigPath is where config.toml is expected, and ProjectCount how
+	// many projects it configures.
+	ConfigPath   string
+	ProjectCount int
+	// Workspace is the workspace the refresh was scoped to, and Workspaces
+	// the names configured.
+	Workspace  string
+	Workspaces []string
+	// At is when the refresh finished.
+	At time.Time
+	// Hash fingerprints the refreshed content; zero when it could not be
+	// computed (or on error), which always forces an update.
+	Hash uint64
+}
+
+// refreshHash fingerprints refresh content so unchanged results can be
+// skipped. JSON is used because it sorts map keys and follows pointers.
+func refreshHash(msg refreshMsg) uint64 {
+	content, err := json.Marshal(struct {
+		Groups         []RepoGroup
+		AgentRows      []AgentWindowRow
+		WindowStatuses map[string]tmux.Status
+		WindowAgents   map[string]tmux.AgentType
+		ConfigMissing  bool
+		ConfigPath     string
+		ProjectCount   int
+		Workspaces     []string
+		Pins           Pins
+	}{msg.Groups, msg.AgentRows, msg.WindowStatuses, msg.WindowAgents, msg.ConfigMissing, msg.ConfigPath, msg.ProjectCount, msg.Workspaces, msg.Pins})
+	if err != nil {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(content)
+	return h.Sum64()
 }
 
 // AddKind identifies which add flow is active.
@@ -35,6 +104,7 @@ const (
 	AddKindNone AddKind = iota
 	AddKindSession
 	AddKindWindow
+	AddKindAgent
 )
 
 // AddDialogState stores state for the add name dialog.
@@ -46,6 +116,40 @@ type AddDialogState struct {
 	RepoIndex   int
 	WorktreeIdx int
 	SessionName string
+	// AgentChoice indexes tmux.LaunchableAgents for AddKindAgent dialogs.
+	AgentChoice int
+	// Resume starts the agent on its most recent conversation (see
+	// tmux.AgentType.ResumeCommand) in AddKindAgent dialogs.
+	Resume bool
+	// Purpose makes the Input of an AddKindAgent dialog the purpose the
+	// window is named for by the agent_window_name scheme, rather than the
+	// window name.
+	Purpose bool
+}
+
+// agentWindowName returns the name of the window an AddKindAgent dialog
+// creates and the purpose it is named for: the Input rendered by scheme in
+// Purpose mode, else the Input itself, or the agent command when it is
+// empty.
+func (d AddDialogState) agentWindowName(scheme string) (name, purpose string) {
+	command := d.selectedAgent().LaunchCommand()
+	input := sanitizeAddName(d.Input)
+	switch {
+	case input == "":
+		return command, ""
+	case d.Purpose:
+		return config.RenderAgentWindowName(scheme, command, input), input
+	default:
+		return input, ""
+	}
+}
+
+// selectedAgent returns the agent chosen in an AddKindAgent dialog.
+func (d AddDialogState) selectedAgent() tmux.AgentType {
+	if d.AgentChoice < 0 || d.AgentChoice >= len(tmux.LaunchableAgents) {
+		return tmux.LaunchableAgents[0]
+	}
+	return tmux.LaunchableAgents[d.AgentChoice]
 }
 
 // addResultMsg is sent after attempting to create a session or window.
@@ -56,6 +160,13 @@ type addResultMsg struct {
 	Err    error
 }
 
+// adoptResultMsg is sent after attempting to adopt an unmanaged session.
+type adoptResultMsg struct {
+	Session string
+	Managed string
+	Err     error
+}
+
 // NodeType represents what kind of tree node the cursor is on.
 type NodeType int
 
@@ -70,6 +181,8 @@ const (
 	NodeWindow
 	// NodeAgentWindow is a flat agent window row in agents mode.
 	NodeAgentWindow
+	// NodeAgentRepo is a collapsible repo header in agents mode.
+	NodeAgentRepo
 )
 
 // DashboardMode controls which dashboard representation is shown.
@@ -99,28 +212,45 @@ type RepoGroup struct {
 	Name         string
 	Path         string
 	InvalidError string
-	Worktrees    []WorktreeGroup
-	Expanded     bool
+	// StaleWorktrees are worktrees git still tracks whose directories were
+	// deleted outside cb (see discovery.ProjectNode).
+	StaleWorktrees []string
+	Worktrees      []WorktreeGroup
+	Expanded       bool
 }
 
 // WorktreeGroup represents one discovered worktree path under a project.
 type WorktreeGroup struct {
 	Name       string
 	Path       string
+	Branch     string
 	IsMainRepo bool
-	Sessions   []WorktreeSession
-	Expanded   bool
+	// IsMissing marks the node of sessions whose worktree no longer exists
+	// (see discovery.WorktreeNode).
+	IsMissing bool
+	DiffStat  *git.DiffStat
+	Overlaps  []discovery.FileOverlap
+	Sessions  []WorktreeSession
+	Expanded  bool
 }
 
-// WorktreeSession represents a tmux session tied to a worktree.
+// WorktreeSession represents a tmux session tied to a worktree. Note and
+// Tags are the session's cb note and cb tags, if any; ActiveTime is its
+// cumulative WORKING time (see cb stats).
 type WorktreeSession struct {
-	Name     string
-	Status   tmux.Status
-	Windows  []tmux.Window
-	Expanded bool
+	Name          string
+	Status        tmux.Status
+	LimitReset    string
+	WaitingReason tmux.WaitingReason
+	Windows       []tmux.Window
+	Note          string
+	Tags          []string
+	ActiveTime    time.Duration
+	Expanded      bool
 }
 
 // TreeNode represents a flattened position in the tree for cursor navigation.
+// For NodeAgentRepo, AgentIndex is the group's first row.
 type TreeNode struct {
 	Type          NodeType
 	RepoIndex     int
@@ -135,10 +265,52 @@ type AgentWindowRow struct {
 	SessionName string
 	WindowName  string
 	WindowIndex int
+	WindowID    string
 	RepoName    string
-	AgentType   tmux.AgentType
-	Status      tmux.Status
-	Managed     bool
+	Project     string
+	// ProjectPath is the main repository the window's session works in, ""
+	// when unknown.
+	ProjectPath string
+	// WindowPurpose is the window's recorded purpose (see tmux.Window).
+	WindowPurpose string
+	AgentType     tmux.AgentType
+	Status        tmux.Status
+	LimitReset    string
+	// WaitingReason is set for WAITING rows.
+	WaitingReason tmux.WaitingReason
+	Managed       bool
+	Tags          []string
+}
+
+// Target returns the row's tmux window target (see tmux.Window.Target).
+func (r AgentWindowRow) Target() string {
+	return tmux.Window{ID: r.WindowID, Index: r.WindowIndex}.Target(r.SessionName)
+}
+
+// Group returns the repo header the row is listed under: its project, or
+// its repo name when the project is unknown.
+func (r AgentWindowRow) Group() string {
+	switch {
+	case r.Project != "":
+		return r.Project
+	case r.RepoName != "":
+		return r.RepoName
+	default:
+		return "Unknown"
+	}
+}
+
+// RepoScope limits the dashboard to one configured project. Label is shown in
+// the status bar and Path is the configured project path. The zero value
+// means unscoped.
+type RepoScope struct {
+	Label string
+	Path  string
+}
+
+// Active reports whether the scope limits the dashboard.
+func (s RepoScope) Active() bool {
+	return s.Path != ""
 }
 
 // Discoverer loads the project/worktree/session hierarchy.
@@ -146,56 +318,103 @@ type Discoverer interface {
 	Discover() (discovery.Result, error)
 }
 
+// contextScoper is implemented by multiplexer clients whose commands can be
+// bound to a context (see tmux.Client.WithContext).
+type contextScoper interface {
+	WithContext(ctx context.Context) *tmux.Client
+}
+
 // Model is the Bubbletea model for the dashboard.
 type Model struct {
-	Mode                DashboardMode
-	Groups              []RepoGroup
-	AgentRows           []AgentWindowRow
-	Cursor              int
-	Nodes               []TreeNode
-	FilterMode          bool
-	FilterQuery         string
-	FilteredNodes       []TreeNode
-	FilteredCursor      int
-	Quitting            bool
-	TmuxClient          *tmux.Client
-	Discoverer          Discoverer
-	SelectedName        string
-	SelectedWindow      string
-	SelectedWindowIndex int
-	WindowStatuses      map[string]tmux.Status
-	WindowAgentTypes    map[string]tmux.AgentType
-	Width               int
-	Height              int
-	ScrollOffset        int
-	Styles              Styles
-	StatusMsg           string
-	ConfigMissing       bool
-	AddDialog           AddDialogState
-}
-
-// RollupStatus returns the most active status from a slice.
-// Priority: WORKING > WAITING > IDLE > DONE
-func RollupStatus(statuses []tmux.Status) tmux.Status {
-	hasWaiting := false
-	hasIdle := false
-	for _, s := range statuses {
-		switch s {
-		case tmux.StatusWorking:
-			return tmux.StatusWorking
-		case tmux.StatusWaiting:
-			hasWaiting = true
-		case tmux.StatusIdle:
-			hasIdle = true
-		}
-	}
-	if hasWaiting {
-		return tmux.StatusWaiting
-	}
-	if hasIdle {
-		return tmux.StatusIdle
-	}
-	return tmux.StatusDone
+	Mode           DashboardMode
+	Groups         []RepoGroup
+	AgentRows      []AgentWindowRow
+	Cursor         int
+	Nodes          []TreeNode
+	FilterMode     bool
+	FilterQuery    string
+	FilteredNodes  []TreeNode
+	FilteredCursor int
+	Quitting       bool
+	TmuxClient     multiplexer.Multiplexer
+	Discoverer     Discoverer
+	SelectedName   string
+	SelectedWindow string
+	// SelectedWindowTarget is the tmux target of the selected window (see
+	// tmux.Window.Target), or empty when a whole session was selected.
+	SelectedWindowTarget string
+	// WindowStatuses and WindowAgentTypes are keyed by tmux.Window.Target.
+	WindowStatuses   map[string]tmux.Status
+	WindowAgentTypes map[string]tmux.AgentType
+	Width            int
+	Height           int
+	ScrollOffset     int
+	Styles           Styles
+	StatusMsg        string
+	// statusMsgShown is the StatusMsg statusMsgExpiresAt was stamped for; a
+	// tick that sees a different message stamps it anew.
+	statusMsgShown     string
+	statusMsgExpiresAt time.Time
+	ConfigMissing      bool
+	AddDialog          AddDialogState
+	RepoScope          RepoScope
+	// ConfigPath and ProjectCount describe the config file as of the last
+	// refresh, for the status bar.
+	ConfigPath   string
+	ProjectCount int
+	// Workspace limits the dashboard to the projects of one workspace (see
+	// config.ProjectConfig.Workspace); empty shows every project.
+	// Workspaces are the names configured as of the last refresh, which "w"
+	// cycles through.
+	Workspace  string
+	Workspaces []string
+	// AgentWindowName is the agent_window_name scheme (see
+	// config.RenderAgentWindowName) used to name agent windows added from
+	// the dashboard and to show their purpose apart from the agent; set it
+	// with WithAgentWindowName, which compiles agentWindowNames.
+	AgentWindowName  string
+	agentWindowNames config.AgentWindowNames
+	// ReadOnly disables every keybinding that changes tmux or git state.
+	ReadOnly bool
+	// Compact drops the frame and fills the whole terminal, for running
+	// inside tmux display-popup.
+	Compact bool
+	// Plain renders statuses as words instead of colored glyphs, for
+	// NO_COLOR and --no-color.
+	Plain bool
+	// ASCII draws badges, arrows, and borders with ASCII characters only.
+	ASCII bool
+	// ShowLegend toggles the status/agent legend popup ("?").
+	ShowLegend bool
+	// ShowDetail toggles the detail popup ("i") for the session under the
+	// cursor.
+	ShowDetail bool
+	// Pins are the pinned sessions and projects as of the last refresh.
+	Pins Pins
+	// DiskUsage caches worktree sizes for the detail popup; they are
+	// measured when the popup first shows a worktree. Nil disables them.
+	DiskUsage *diskusage.Cache
+	// CollapsedAgentRepos holds the agents-mode repo headers (see
+	// AgentWindowRow.Group) the user collapsed; it survives refreshes.
+	CollapsedAgentRepos map[string]bool
+
+	lastRefreshHash uint64
+	// confirmCleanup holds the sessions a first "x" asked to kill (see
+	// cleanupMissingForNode); any other key clears it.
+	confirmCleanup string
+	// refreshInFlight is set while a tick-driven refresh runs so slow
+	// refreshes never overlap; lastRefreshDuration stretches the next tick.
+	refreshInFlight     bool
+	lastRefreshDuration time.Duration
+	// lastRefreshAt is when data last refreshed successfully, and
+	// lastRefreshErr why the latest refresh failed (nil if it did not); the
+	// status bar shows both so stale data is noticeable.
+	lastRefreshAt  time.Time
+	lastRefreshErr error
+	// spinnerFrame animates the status bar while refreshInFlight;
+	// spinnerActive is set while spinner ticks are scheduled.
+	spinnerFrame  int
+	spinnerActive bool
 }
 
 // SessionCounts returns total sessions and counts by status.
@@ -233,6 +452,30 @@ func (m Model) SessionCounts() (total, working, waiting, idle int) {
 	return
 }
 
+// erroredCount returns how many sessions (or agent windows in agents mode)
+// are in ERROR.
+func (m Model) erroredCount() int {
+	count := 0
+	if m.Mode == DashboardModeAgents {
+		for _, row := range m.AgentRows {
+			if row.Status == tmux.StatusError {
+				count++
+			}
+		}
+		return count
+	}
+	for _, g := range m.Groups {
+		for _, wt := range g.Worktrees {
+			for _, s := range wt.Sessions {
+				if s.Status == tmux.StatusError {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
+
 // BuildNodes flattens the tree into a list of navigable nodes.
 func BuildNodes(groups []RepoGroup) []TreeNode {
 	var nodes []TreeNode
@@ -268,15 +511,32 @@ func BuildNodes(groups []RepoGroup) []TreeNode {
 	return nodes
 }
 
-// BuildAgentNodes flattens agent rows into a list of navigable nodes.
-func BuildAgentNodes(rows []AgentWindowRow) []TreeNode {
+// BuildAgentNodes flattens agent rows into a list of navigable nodes, with a
+// repo header before each group of rows. Rows must already be grouped (see
+// sortAgentRows); rows under a collapsed header are omitted.
+func BuildAgentNodes(rows []AgentWindowRow, collapsed map[string]bool) []TreeNode {
 	nodes := make([]TreeNode, 0, len(rows))
-	for i := range rows {
-		nodes = append(nodes, TreeNode{Type: NodeAgentWindow, AgentIndex: i})
+	for i, row := range rows {
+		group := row.Group()
+		if i == 0 || rows[i-1].Group() != group {
+			nodes = append(nodes, TreeNode{Type: NodeAgentRepo, AgentIndex: i})
+		}
+		if !collapsed[group] {
+			nodes = append(nodes, TreeNode{Type: NodeAgentWindow, AgentIndex: i})
+		}
 	}
 	return nodes
 }
 
+// agentGroupRows returns the rows under the header whose first row is start.
+func agentGroupRows(rows []AgentWindowRow, start int) []AgentWindowRow {
+	end := start + 1
+	for end < len(rows) && rows[end].Group() == rows[start].Group() {
+		end++
+	}
+	return rows[start:end]
+}
+
 // VisibleRange calculates which lines to display given viewport constraints.
 // Returns start (inclusive), end (exclusive), and new scroll offset.
 func VisibleRange(lineCount, viewHeight, cursorLine, scrollOffset int) (start, end, newOffset int) {
@@ -311,75 +571,220 @@ func CursorToLine(nodes []TreeNode, cursor int) int {
 }
 
 // InitialModel creates the initial dashboard model.
-func InitialModel(tmuxClient *tmux.Client) Model {
+func InitialModel(tmuxClient multiplexer.Multiplexer) Model {
 	return InitialModelWithMode(tmuxClient, DashboardModeWorktree)
 }
 
 // InitialModelWithMode creates the initial dashboard model with an explicit mode.
-func InitialModelWithMode(tmuxClient *tmux.Client, mode DashboardMode) Model {
+func InitialModelWithMode(tmuxClient multiplexer.Multiplexer, mode DashboardMode) Model {
 	return Model{
-		Mode:                mode,
-		Groups:              []RepoGroup{},
-		AgentRows:           []AgentWindowRow{},
-		TmuxClient:          tmuxClient,
-		Discoverer:          discovery.NewService(tmuxClient),
-		WindowStatuses:      make(map[string]tmux.Status),
-		WindowAgentTypes:    make(map[string]tmux.AgentType),
-		SelectedWindowIndex: -1,
-		Styles:              NewStyles(KanagawaClaw),
+		Mode:             mode,
+		Groups:           []RepoGroup{},
+		AgentRows:        []AgentWindowRow{},
+		TmuxClient:       tmuxClient,
+		Discoverer:       discovery.NewService(tmuxClient),
+		WindowStatuses:   make(map[string]tmux.Status),
+		WindowAgentTypes: make(map[string]tmux.AgentType),
+		Styles:           NewStyles(KanagawaClaw),
+		agentWindowNames: config.CompileAgentWindowName(""),
+		DiskUsage:        diskusage.NewCache(diskusage.DefaultTTL, diskusage.Measure),
+		refreshInFlight:  true, // Init starts the first refresh
+		spinnerActive:    true, // and the spinner
 	}
 }
 
+// WithAgentWindowName returns the model naming agent windows by scheme (see
+// Model.AgentWindowName).
+func (m Model) WithAgentWindowName(scheme string) Model {
+	m.AgentWindowName = scheme
+	m.agentWindowNames = config.CompileAgentWindowName(scheme)
+	return m
+}
+
+// WithFilter returns the model with filter mode pre-applied for query, as if
+// the user had typed it after pressing "/".
+func (m Model) WithFilter(query string) Model {
+	m.FilterMode = true
+	m.FilterQuery = query
+	m.FilteredCursor = 0
+	m.updateFilteredNodes()
+	return m
+}
+
 // Init implements tea.Model.
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(m.refreshCmd(), m.tickCmd())
+	return tea.Batch(m.refreshCmd(), m.tickCmd(), spinnerTickCmd())
+}
+
+// expireStatusMsg clears StatusMsg once it has been shown for statusMsgTTL,
+// so a message set just before a tick still stays readable.
+func (m *Model) expireStatusMsg(now time.Time) {
+	switch {
+	case m.StatusMsg == "":
+		m.statusMsgShown = ""
+	case m.StatusMsg != m.statusMsgShown:
+		m.statusMsgShown = m.StatusMsg
+		m.statusMsgExpiresAt = now.Add(statusMsgTTL)
+	case !now.Before(m.statusMsgExpiresAt):
+		m.StatusMsg = ""
+		m.statusMsgShown = ""
+	}
 }
 
 func (m Model) tickCmd() tea.Cmd {
-	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+	return tea.Tick(nextRefreshInterval(m.lastRefreshDuration), func(t time.Time) tea.Msg {
 		return tickMsg(t)
 	})
 }
 
+func spinnerTickCmd() tea.Cmd {
+	return tea.Tick(spinnerInterval, func(time.Time) tea.Msg {
+		return spinnerTickMsg{}
+	})
+}
+
+// nextRefreshInterval stretches the tick interval when the last refresh took
+// longer than refreshInterval, up to maxRefreshInterval.
+func nextRefreshInterval(lastDuration time.Duration) time.Duration {
+	if lastDuration <= refreshInterval {
+		return refreshInterval
+	}
+	return min(2*lastDuration, maxRefreshInterval)
+}
+
+// slowRefresh reports whether the last refresh exceeded refreshInterval.
+func (m Model) slowRefresh() bool {
+	return m.lastRefreshDuration > refreshInterval
+}
+
+// refreshCmd fetches dashboard data, giving up after refreshTimeout so a hung
+// tmux or ps call surfaces as an error instead of silently stalling updates.
+// The fetch runs under a context that expires with the deadline, which kills
+// the commands it still has running; its late result is dropped.
 func (m Model) refreshCmd() tea.Cmd {
 	return func() tea.Msg {
-		groups, rows, statuses, agents, missing, err := fetchDashboardData(m.Discoverer, m.TmuxClient, m.Mode)
-		return refreshMsg{
-			Groups:         groups,
-			AgentRows:      rows,
-			WindowStatuses: statuses,
-			WindowAgents:   agents,
-			ConfigMissing:  missing,
-			Err:            err,
+		started := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		defer cancel()
+		done := make(chan refreshMsg, 1)
+		go func() { done <- m.fetchRefresh(ctx) }()
+
+		select {
+		case msg := <-done:
+			msg.Duration = time.Since(started)
+			msg.At = clock()
+			return msg
+		case <-ctx.Done():
+			return refreshMsg{
+				Err:      fmt.Errorf("refresh timed out after %s", refreshTimeout),
+				Duration: time.Since(started),
+				At:       clock(),
+			}
+		}
+	}
+}
+
+func (m Model) fetchRefresh(ctx context.Context) refreshMsg {
+	groups, rows, statuses, agents, missing, err := fetchDashboardData(ctx, m.Discoverer, m.TmuxClient, m.Mode)
+	cfg, exists, cfgErr := config.LoadUserConfigWithMeta()
+	if cfgErr != nil {
+		slog.Debug("fetchRefresh: LoadUserConfig failed, ignoring pins", "err", cfgErr)
+	}
+	pins := pinsFromConfig(cfg)
+	applyActiveTimes(groups)
+	groups, rows = scopeGroups(groups, m.RepoScope), scopeAgentRows(rows, m.RepoScope)
+	if m.Workspace != "" {
+		scope := workspaceScope(cfg, m.Workspace)
+		groups, rows = scope.groups(groups), scope.agentRows(rows)
+	}
+	pinGroups(groups, pins)
+	pinAgentRows(rows, pins)
+	msg := refreshMsg{
+		Groups:         groups,
+		AgentRows:      rows,
+		WindowStatuses: statuses,
+		WindowAgents:   agents,
+		ConfigMissing:  missing || (cfgErr == nil && !exists),
+		ProjectCount:   len(cfg.Projects),
+		Workspace:      m.Workspace,
+		Workspaces:     cfg.Workspaces(),
+		Pins:           pins,
+		Err:            err,
+	}
+	if c, pathErr := config.New(); pathErr == nil {
+		msg.ConfigPath = c.ConfigFilePath()
+	}
+	if err == nil {
+		msg.Hash = refreshHash(msg)
+	}
+	return msg
+}
+
+// applyActiveTimes fills each session's ActiveTime from the activity store.
+func applyActiveTimes(groups []RepoGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	c, err := config.New()
+	if err != nil {
+		return
+	}
+	sessions, err := activity.NewStore(c.ActivityPath()).Load()
+	if err != nil {
+		slog.Debug("applyActiveTimes: activity unavailable", "err", err)
+		return
+	}
+	for gi := range groups {
+		for wi := range groups[gi].Worktrees {
+			wt := &groups[gi].Worktrees[wi]
+			for si := range wt.Sessions {
+				wt.Sessions[si].ActiveTime = sessions[wt.Sessions[si].Name].Working
+			}
 		}
 	}
 }
 
 // fetchDashboardData queries tmux for all data needed by the selected mode.
 func fetchDashboardData(
+	ctx context.Context,
 	discoverer Discoverer,
-	tmuxClient *tmux.Client,
+	tmuxClient multiplexer.Multiplexer,
 	mode DashboardMode,
 ) ([]RepoGroup, []AgentWindowRow, map[string]tmux.Status, map[string]tmux.AgentType, bool, error) {
 	switch mode {
 	case DashboardModeAgents:
-		rows, statuses, agents := fetchAgentRowsData(tmuxClient)
+		rows, statuses, agents := fetchAgentRowsData(withContext(tmuxClient, ctx))
 		return nil, rows, statuses, agents, false, nil
 	default:
-		groups, statuses, agents, missing, err := fetchGroups(discoverer)
+		groups, statuses, agents, missing, err := fetchGroups(ctx, discoverer)
 		return groups, nil, statuses, agents, missing, err
 	}
 }
 
+// withContext binds tmuxClient's commands to ctx when it supports that. A
+// nil client comes back as a literal nil interface, never as a nil
+// *tmux.Client, so nil checks on the result still see it.
+func withContext(tmuxClient multiplexer.Multiplexer, ctx context.Context) multiplexer.Multiplexer {
+	scoper, ok := tmuxClient.(contextScoper)
+	if !ok {
+		return tmuxClient
+	}
+	scoped := scoper.WithContext(ctx)
+	if scoped == nil {
+		return nil
+	}
+	return scoped
+}
+
 // fetchGroups queries shared discovery data.
-func fetchGroups(discoverer Discoverer) ([]RepoGroup, map[string]tmux.Status, map[string]tmux.AgentType, bool, error) {
+func fetchGroups(ctx context.Context, discoverer Discoverer) ([]RepoGroup, map[string]tmux.Status, map[string]tmux.AgentType, bool, error) {
 	slog.Debug("fetchGroups called")
 	if discoverer == nil {
 		slog.Debug("fetchGroups: discoverer is nil")
 		return nil, map[string]tmux.Status{}, map[string]tmux.AgentType{}, false, nil
 	}
 
-	result, err := discoverer.Discover()
+	result, err := discovery.DiscoverWithContext(ctx, discoverer)
 	if err != nil {
 		return nil, nil, nil, false, err
 	}
@@ -387,26 +792,35 @@ func fetchGroups(discoverer Discoverer) ([]RepoGroup, map[string]tmux.Status, ma
 	groups := make([]RepoGroup, 0, len(result.Projects))
 	for _, p := range result.Projects {
 		group := RepoGroup{
-			Name:         p.Name,
-			Path:         p.Path,
-			InvalidError: p.InvalidError,
-			Expanded:     true,
-			Worktrees:    make([]WorktreeGroup, 0, len(p.Worktrees)),
+			Name:           p.Name,
+			Path:           p.Path,
+			InvalidError:   p.InvalidError,
+			StaleWorktrees: p.StaleWorktrees,
+			Expanded:       true,
+			Worktrees:      make([]WorktreeGroup, 0, len(p.Worktrees)),
 		}
 		for _, wt := range p.Worktrees {
 			worktree := WorktreeGroup{
 				Name:       wt.Name,
 				Path:       wt.Path,
+				Branch:     wt.Branch,
 				IsMainRepo: wt.IsMainRepo,
+				IsMissing:  wt.IsMissing,
+				DiffStat:   wt.DiffStat,
+				Overlaps:   wt.Overlaps,
 				Expanded:   true,
 				Sessions:   make([]WorktreeSession, 0, len(wt.Sessions)),
 			}
 			for _, s := range wt.Sessions {
 				worktree.Sessions = append(worktree.Sessions, WorktreeSession{
-					Name:     s.Name,
-					Status:   s.Status,
-					Windows:  s.Windows,
-					Expanded: true,
+					Name:          s.Name,
+					Status:        s.Status,
+					LimitReset:    s.LimitReset,
+					WaitingReason: s.WaitingReason,
+					Windows:       s.Windows,
+					Note:          s.Note,
+					Tags:          s.Tags,
+					Expanded:      true,
 				})
 			}
 			group.Worktrees = append(group.Worktrees, worktree)
@@ -417,7 +831,7 @@ func fetchGroups(discoverer Discoverer) ([]RepoGroup, map[string]tmux.Status, ma
 	return groups, result.WindowStatuses, result.WindowAgents, result.ConfigMissing, nil
 }
 
-func fetchAgentRowsData(tmuxClient *tmux.Client) ([]AgentWindowRow, map[string]tmux.Status, map[string]tmux.AgentType) {
+func fetchAgentRowsData(tmuxClient multiplexer.Multiplexer) ([]AgentWindowRow, map[string]tmux.Status, map[string]tmux.AgentType) {
 	slog.Debug("fetchAgentRowsData called")
 	if tmuxClient == nil {
 		slog.Debug("fetchAgentRowsData: tmuxClient is nil")
@@ -430,34 +844,185 @@ func fetchAgentRowsData(tmuxClient *tmux.Client) ([]AgentWindowRow, map[string]t
 		return nil, map[string]tmux.Status{}, map[string]tmux.AgentType{}
 	}
 
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		slog.Debug("fetchAgentRowsData: LoadUserConfig failed, not ignoring sessions", "err", err)
+	}
+	return buildAgentRows(infos, cfg.IgnoresSession)
+}
+
+// buildAgentRows converts detected agent windows into agents-mode rows,
+// skipping sessions for which ignored returns true. Rows are ordered by
+// attention priority (see sortAgentRows).
+func buildAgentRows(infos []tmux.SessionWindowInfo, ignored func(string) bool) ([]AgentWindowRow, map[string]tmux.Status, map[string]tmux.AgentType) {
 	rows := make([]AgentWindowRow, 0, len(infos))
 	statusMap := make(map[string]tmux.Status)
 	agentMap := make(map[string]tmux.AgentType)
 
 	for _, info := range infos {
-		if !info.AgentInfo.Detected {
+		if !info.AgentInfo.Detected || ignored(info.SessionName) {
 			continue
 		}
 
 		row := AgentWindowRow{
-			SessionName: info.SessionName,
-			WindowName:  info.Window.Name,
-			WindowIndex: info.Window.Index,
-			RepoName:    info.RepoName,
-			AgentType:   info.AgentInfo.Type,
-			Status:      info.AgentInfo.Status,
-			Managed:     info.Managed,
+			SessionName:   info.SessionName,
+			WindowName:    info.Window.Name,
+			WindowIndex:   info.Window.Index,
+			WindowID:      info.Window.ID,
+			RepoName:      info.RepoName,
+			Project:       info.Project,
+			ProjectPath:   info.ProjectPath,
+			WindowPurpose: info.Window.Purpose,
+			AgentType:     info.AgentInfo.Type,
+			Status:        info.AgentInfo.Status,
+			LimitReset:    info.AgentInfo.LimitReset,
+			WaitingReason: info.AgentInfo.WaitingReason,
+			Managed:       info.Managed,
+			Tags:          info.Tags,
 		}
 		rows = append(rows, row)
 
-		key := row.SessionName + ":" + row.WindowName
+		key := row.Target()
 		statusMap[key] = row.Status
 		agentMap[key] = row.AgentType
 	}
 
+	sortAgentRows(rows)
 	return rows, statusMap, agentMap
 }
 
+// attentionRank orders statuses by how urgently they need the user:
+// ERROR first, then WAITING, WORKING, LIMITED, IDLE, and DONE.
+func attentionRank(status tmux.Status) int {
+	switch status {
+	case tmux.StatusError:
+		return 0
+	case tmux.StatusWaiting:
+		return 1
+	case tmux.StatusWorking:
+		return 2
+	case tmux.StatusLimited:
+		return 3
+	case tmux.StatusIdle:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// sortAgentRows groups rows by repo (see AgentWindowRow.Group), putting the
+// group with the most urgent row first, and orders each group by attention
+// priority, then repo, session, and window index so the list is stable
+// between refreshes.
+func sortAgentRows(rows []AgentWindowRow) {
+	groupRank := make(map[string]int)
+	for _, row := range rows {
+		rank := attentionRank(row.Status)
+		if current, ok := groupRank[row.Group()]; !ok || rank < current {
+			groupRank[row.Group()] = rank
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if ga, gb := a.Group(), b.Group(); ga != gb {
+			if groupRank[ga] != groupRank[gb] {
+				return groupRank[ga] < groupRank[gb]
+			}
+			return ga < gb
+		}
+		if ra, rb := attentionRank(a.Status), attentionRank(b.Status); ra != rb {
+			return ra < rb
+		}
+		if ua, ub := a.WaitingReason.Urgency(), b.WaitingReason.Urgency(); ua != ub {
+			return ua > ub
+		}
+		if a.RepoName != b.RepoName {
+			return a.RepoName < b.RepoName
+		}
+		if a.SessionName != b.SessionName {
+			return a.SessionName < b.SessionName
+		}
+		return a.WindowIndex < b.WindowIndex
+	})
+}
+
+// scopeGroups keeps only the group for the scoped project.
+func scopeGroups(groups []RepoGroup, scope RepoScope) []RepoGroup {
+	if !scope.Active() || groups == nil {
+		return groups
+	}
+	scoped := make([]RepoGroup, 0, 1)
+	for _, g := range groups {
+		if g.Path == scope.Path {
+			scoped = append(scoped, g)
+		}
+	}
+	return scoped
+}
+
+// scopeAgentRows keeps agent windows working in the scoped project or one of
+// its worktrees.
+func scopeAgentRows(rows []AgentWindowRow, scope RepoScope) []AgentWindowRow {
+	if !scope.Active() || rows == nil {
+		return rows
+	}
+	return projectScope{paths: map[string]bool{canonicalOrClean(scope.Path): true}}.agentRows(rows)
+}
+
+// projectScope is a set of configured projects, by canonical path.
+type projectScope struct {
+	paths map[string]bool
+}
+
+// workspaceScope returns the projects of cfg in workspace.
+func workspaceScope(cfg config.UserConfig, workspace string) projectScope {
+	scope := projectScope{paths: map[string]bool{}}
+	for _, p := range cfg.Projects {
+		if p.Workspace == workspace {
+			scope.paths[canonicalOrClean(p.Path)] = true
+		}
+	}
+	return scope
+}
+
+// groups keeps the groups of the scope's projects.
+func (s projectScope) groups(groups []RepoGroup) []RepoGroup {
+	if groups == nil {
+		return nil
+	}
+	scoped := make([]RepoGroup, 0, len(groups))
+	for _, g := range groups {
+		if s.paths[canonicalOrClean(g.Path)] {
+			scoped = append(scoped, g)
+		}
+	}
+	return scoped
+}
+
+// agentRows keeps agent windows working in one of the scope's projects or
+// their worktrees.
+func (s projectScope) agentRows(rows []AgentWindowRow) []AgentWindowRow {
+	if rows == nil {
+		return nil
+	}
+	scoped := make([]AgentWindowRow, 0, len(rows))
+	for _, row := range rows {
+		if row.ProjectPath != "" && s.paths[canonicalOrClean(row.ProjectPath)] {
+			scoped = append(scoped, row)
+		}
+	}
+	return scoped
+}
+
+// canonicalOrClean returns path canonicalized, or only cleaned when it no
+// longer resolves.
+func canonicalOrClean(path string) string {
+	if canonical, err := config.CanonicalPath(path); err == nil {
+		return canonical
+	}
+	return filepath.Clean(path)
+}
+
 // adjustScroll updates ScrollOffset to keep cursor visible in the viewport.
 func (m *Model) adjustScroll() {
 	treeHeight := m.treeHeight()
@@ -486,6 +1051,10 @@ func (m *Model) adjustScroll() {
 // treeHeight returns the number of lines available for the tree view.
 // Accounts for borders (2), status bar (1), and frame padding (1).
 func (m Model) treeHeight() int {
+	if m.Compact {
+		// Only the status bar and footer lines remain below the tree.
+		return max(m.Height-2, 1)
+	}
 	h := max(m.Height-4, 1)
 	return h
 }
@@ -506,13 +1075,13 @@ func (m Model) totalDisplayLines() int {
 }
 
 func (m *Model) updateFilteredNodes() {
-	query := strings.ToLower(strings.TrimSpace(m.FilterQuery))
-	if query == "" {
+	text, tags := parseFilterQuery(m.FilterQuery)
+	if text == "" && len(tags) == 0 {
 		m.FilteredNodes = append([]TreeNode(nil), m.Nodes...)
 	} else {
 		m.FilteredNodes = m.FilteredNodes[:0]
 		for _, node := range m.Nodes {
-			if strings.Contains(strings.ToLower(m.filterSearchText(node)), query) {
+			if m.nodeHasTags(node, tags) && strings.Contains(strings.ToLower(m.filterSearchText(node)), text) {
 				m.FilteredNodes = append(m.FilteredNodes, node)
 			}
 		}
@@ -526,6 +1095,45 @@ func (m *Model) updateFilteredNodes() {
 	}
 }
 
+// parseFilterQuery splits a filter query into its lowercase free text and
+// the tags named by "tag:<name>" terms.
+func parseFilterQuery(query string) (string, []string) {
+	var words, tags []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		if tag, ok := strings.CutPrefix(field, "tag:"); ok {
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+			continue
+		}
+		words = append(words, field)
+	}
+	return strings.Join(words, " "), tags
+}
+
+// nodeHasTags reports whether node belongs to a session carrying every tag.
+// Nodes outside a session match only an empty tag list.
+func (m Model) nodeHasTags(node TreeNode, tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	var have []string
+	switch node.Type {
+	case NodeSession, NodeWindow:
+		have = m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex].Sessions[node.SessionIndex].Tags
+	case NodeAgentWindow:
+		have = m.AgentRows[node.AgentIndex].Tags
+	default:
+		return false
+	}
+	for _, tag := range tags {
+		if !slices.Contains(have, tag) {
+			return false
+		}
+	}
+	return true
+}
+
 func (m Model) filterSearchText(node TreeNode) string {
 	switch node.Type {
 	case NodeRepo:
@@ -539,13 +1147,19 @@ func (m Model) filterSearchText(node TreeNode) string {
 		group := m.Groups[node.RepoIndex]
 		worktree := group.Worktrees[node.WorktreeIndex]
 		session := worktree.Sessions[node.SessionIndex]
-		return session.Name + " " + worktree.Name + " " + group.Name
+		return session.Name + " " + worktree.Name + " " + group.Name + " " + string(session.Status)
 	case NodeWindow:
 		group := m.Groups[node.RepoIndex]
 		worktree := group.Worktrees[node.WorktreeIndex]
 		session := worktree.Sessions[node.SessionIndex]
 		window := session.Windows[node.WindowIndex]
-		return window.Name + " " + session.Name + " " + worktree.Name + " " + group.Name
+		text := window.Name + " " + session.Name + " " + worktree.Name + " " + group.Name
+		if status, ok := m.WindowStatuses[window.Target(session.Name)]; ok {
+			text += " " + string(status)
+		}
+		return text
+	case NodeAgentRepo:
+		return m.AgentRows[node.AgentIndex].Group()
 	case NodeAgentWindow:
 		row := m.AgentRows[node.AgentIndex]
 		return strings.Join([]string{
@@ -578,15 +1192,32 @@ func (m Model) cursorForView() int {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case refreshMsg:
+		m.refreshInFlight = false
+		m.lastRefreshDuration = msg.Duration
+		m.lastRefreshErr = msg.Err
 		if msg.Err != nil {
 			m.StatusMsg = fmt.Sprintf("Error: %v", msg.Err)
+			m.lastRefreshHash = 0
 			return m, nil
 		}
+		m.lastRefreshAt = msg.At
+		if msg.Workspace != m.Workspace {
+			// Started before the workspace was switched.
+			return m, nil
+		}
+		if msg.Hash != 0 && msg.Hash == m.lastRefreshHash {
+			return m, nil
+		}
+		m.lastRefreshHash = msg.Hash
 		m.ConfigMissing = msg.ConfigMissing
+		m.ConfigPath = msg.ConfigPath
+		m.ProjectCount = msg.ProjectCount
+		m.Workspaces = msg.Workspaces
+		m.Pins = msg.Pins
 
 		if m.Mode == DashboardModeAgents {
 			m.AgentRows = msg.AgentRows
-			m.Nodes = BuildAgentNodes(m.AgentRows)
+			m.Nodes = BuildAgentNodes(m.AgentRows, m.CollapsedAgentRepos)
 			m.Groups = nil
 		} else {
 			m.Groups = mergeExpandState(m.Groups, msg.Groups)
@@ -613,15 +1244,69 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.StatusMsg = fmt.Sprintf("Session created: %s", msg.Name)
 			case AddKindWindow:
 				m.StatusMsg = fmt.Sprintf("Window created: %s", msg.Name)
+			case AddKindAgent:
+				m.StatusMsg = fmt.Sprintf("Agent window created: %s", msg.Name)
 			default:
 				m.StatusMsg = "Created"
 			}
 		}
 		return m, m.refreshCmd()
 
+	case pinResultMsg:
+		switch {
+		case msg.Err != nil:
+			m.StatusMsg = fmt.Sprintf("Error: %v", msg.Err)
+		case msg.Pinned:
+			m.StatusMsg = fmt.Sprintf("Pinned %s", msg.Name)
+		default:
+			m.StatusMsg = fmt.Sprintf("Unpinned %s", msg.Name)
+		}
+		return m, m.refreshCmd()
+
+	case spinnerTickMsg:
+		if !m.refreshInFlight {
+			m.spinnerActive = false
+			return m, nil
+		}
+		m.spinnerFrame++
+		return m, spinnerTickCmd()
+
+	case diskUsageMsg:
+		// The cache holds the result; the next render shows it.
+		return m, nil
+
+	case cleanupResultMsg:
+		switch {
+		case msg.Err != nil:
+			m.StatusMsg = fmt.Sprintf("Error: %v", msg.Err)
+		default:
+			m.StatusMsg = fmt.Sprintf("Killed %s", strings.Join(msg.Killed, ", "))
+		}
+		return m, m.refreshCmd()
+
+	case adoptResultMsg:
+		if msg.Err != nil {
+			m.StatusMsg = fmt.Sprintf("Error: %v", msg.Err)
+		} else {
+			m.StatusMsg = fmt.Sprintf("Adopted %s as %s", msg.Session, msg.Managed)
+		}
+		return m, m.refreshCmd()
+
 	case tickMsg:
-		m.StatusMsg = ""
-		return m, tea.Batch(m.refreshCmd(), m.tickCmd())
+		m.expireStatusMsg(time.Time(msg))
+		// A size that expires while the detail popup is open is measured
+		// again rather than left at "measuring...".
+		measure := m.detailDiskUsageCmd()
+		if m.refreshInFlight {
+			return m, tea.Batch(m.tickCmd(), measure)
+		}
+		m.refreshInFlight = true
+		cmds := []tea.Cmd{m.refreshCmd(), m.tickCmd(), measure}
+		if !m.spinnerActive {
+			m.spinnerActive = true
+			cmds = append(cmds, spinnerTickCmd())
+		}
+		return m, tea.Batch(cmds...)
 
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
@@ -643,6 +1328,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			case "enter":
 				return m.submitAddDialog()
+			case "tab", "shift+tab":
+				if m.AddDialog.Kind == AddKindAgent {
+					step := 1
+					if msg.String() == "shift+tab" {
+						step = len(tmux.LaunchableAgents) - 1
+					}
+					m.AddDialog.AgentChoice = (m.AddDialog.AgentChoice + step) % len(tmux.LaunchableAgents)
+				}
+				return m, nil
+			case "ctrl+r":
+				if m.AddDialog.Kind == AddKindAgent {
+					m.AddDialog.Resume = !m.AddDialog.Resume
+				}
+				return m, nil
+			case "ctrl+p":
+				if m.AddDialog.Kind == AddKindAgent {
+					m.AddDialog.Purpose = !m.AddDialog.Purpose
+				}
+				return m, nil
 			}
 
 			if len(msg.Runes) > 0 {
@@ -652,6 +1356,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.ShowLegend {
+			switch msg.String() {
+			case "?", "esc":
+				m.ShowLegend = false
+			case "q", "ctrl+c":
+				m.Quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		if m.ShowDetail {
+			switch msg.String() {
+			case "i", "esc":
+				m.ShowDetail = false
+			case "q", "ctrl+c":
+				m.Quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
 		if m.FilterMode {
 			switch msg.String() {
 			case "esc":
@@ -669,13 +1395,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.updateFilteredNodes()
 				m.adjustScroll()
 				return m, nil
-			case "up", "k":
+			case "up":
 				if m.FilteredCursor > 0 {
 					m.FilteredCursor--
 					m.adjustScroll()
 				}
 				return m, nil
-			case "down", "j":
+			case "down":
 				if m.FilteredCursor < len(m.FilteredNodes)-1 {
 					m.FilteredCursor++
 					m.adjustScroll()
@@ -693,6 +1419,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if msg.String() != "x" {
+			m.confirmCleanup = ""
+		}
 		switch msg.String() {
 		case "q", "esc", "ctrl+c":
 			m.Quitting = true
@@ -700,6 +1429,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "m":
 			m.toggleMode()
 			return m, m.refreshCmd()
+		case "w":
+			if len(m.Workspaces) == 0 {
+				m.StatusMsg = "No workspaces configured (set workspace in [[projects]])"
+				return m, nil
+			}
+			m.cycleWorkspace()
+			return m, m.refreshCmd()
 		case "up", "k":
 			if m.Cursor > 0 {
 				m.Cursor--
@@ -714,22 +1450,63 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleEnter()
 		case "l", "right":
 			if m.Mode == DashboardModeAgents {
-				return m, nil
+				return m.setAgentRepoCollapsed(false)
 			}
 			return m.handleExpand()
 		case "h", "left":
 			if m.Mode == DashboardModeAgents {
-				return m, nil
+				return m.setAgentRepoCollapsed(true)
 			}
 			return m.handleCollapse()
 		case "a":
-			if m.Mode == DashboardModeAgents {
+			if m.ReadOnly {
+				m.StatusMsg = "Read-only mode"
 				return m, nil
 			}
 			if m.Cursor >= len(m.Nodes) {
 				return m, nil
 			}
+			if m.Mode == DashboardModeAgents {
+				return m.openAgentDialogForNode(m.Nodes[m.Cursor])
+			}
 			return m.openAddDialogForNode(m.Nodes[m.Cursor])
+		case "A":
+			if m.ReadOnly {
+				m.StatusMsg = "Read-only mode"
+				return m, nil
+			}
+			if m.Mode != DashboardModeAgents || m.Cursor >= len(m.Nodes) {
+				return m, nil
+			}
+			return m.adoptSessionForNode(m.Nodes[m.Cursor])
+		case "p":
+			if m.ReadOnly {
+				m.StatusMsg = "Read-only mode"
+				return m, nil
+			}
+			if m.Cursor >= len(m.Nodes) {
+				return m, nil
+			}
+			return m.togglePinForNode(m.Nodes[m.Cursor])
+		case "x":
+			if m.ReadOnly {
+				m.StatusMsg = "Read-only mode"
+				return m, nil
+			}
+			if m.Cursor >= len(m.Nodes) {
+				return m, nil
+			}
+			return m.cleanupMissingForNode(m.Nodes[m.Cursor])
+		case "i":
+			_, worktree, ok := m.detailSession()
+			if !ok {
+				m.StatusMsg = "Select a session to see its details"
+				return m, nil
+			}
+			m.ShowDetail = true
+			return m, m.measureDiskUsageCmd(worktree)
+		case "?":
+			m.ShowLegend = true
 		case "/":
 			m.FilterMode = true
 			m.FilterQuery = ""
@@ -753,6 +1530,7 @@ func (m *Model) toggleMode() {
 	m.Groups = nil
 	m.AgentRows = nil
 	m.ScrollOffset = 0
+	m.lastRefreshHash = 0
 
 	m.FilterMode = false
 	m.FilterQuery = ""
@@ -761,6 +1539,27 @@ func (m *Model) toggleMode() {
 	m.AddDialog = AddDialogState{}
 }
 
+// cycleWorkspace switches to the next configured workspace, wrapping back
+// to all projects after the last one.
+func (m *Model) cycleWorkspace() {
+	next := ""
+	if i := slices.Index(m.Workspaces, m.Workspace); i < 0 {
+		next = m.Workspaces[0]
+	} else if i+1 < len(m.Workspaces) {
+		next = m.Workspaces[i+1]
+	}
+	m.Workspace = next
+
+	m.Cursor = 0
+	m.ScrollOffset = 0
+	m.lastRefreshHash = 0
+	if next == "" {
+		m.StatusMsg = "Workspace: all projects"
+	} else {
+		m.StatusMsg = "Workspace: " + next
+	}
+}
+
 // mergeExpandState preserves expand/collapse state across refreshes.
 func mergeExpandState(old, updated []RepoGroup) []RepoGroup {
 	repoState := make(map[string]bool)
@@ -833,25 +1632,124 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 	case NodeSession:
 		session := m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex].Sessions[node.SessionIndex]
 		m.SelectedName = session.Name
-		m.SelectedWindowIndex = -1
+		m.SelectedWindowTarget = ""
 		return m, tea.Quit
 	case NodeWindow:
 		session := m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex].Sessions[node.SessionIndex]
 		window := session.Windows[node.WindowIndex]
 		m.SelectedName = session.Name
 		m.SelectedWindow = window.Name
-		m.SelectedWindowIndex = window.Index
+		m.SelectedWindowTarget = window.Target(session.Name)
 		return m, tea.Quit
+	case NodeAgentRepo:
+		group := m.AgentRows[node.AgentIndex].Group()
+		m.toggleAgentRepo(group, !m.CollapsedAgentRepos[group])
 	case NodeAgentWindow:
 		row := m.AgentRows[node.AgentIndex]
 		m.SelectedName = row.SessionName
 		m.SelectedWindow = row.WindowName
-		m.SelectedWindowIndex = row.WindowIndex
+		m.SelectedWindowTarget = row.Target()
 		return m, tea.Quit
 	}
 	return m, nil
 }
 
+// setAgentRepoCollapsed expands or collapses the agents-mode repo under the
+// cursor. Collapsing from a row moves the cursor to its header.
+func (m Model) setAgentRepoCollapsed(collapsed bool) (tea.Model, tea.Cmd) {
+	if m.Cursor >= len(m.Nodes) {
+		return m, nil
+	}
+	node := m.Nodes[m.Cursor]
+	if node.Type == NodeAgentWindow && !collapsed {
+		return m, nil
+	}
+	group := m.AgentRows[node.AgentIndex].Group()
+	m.toggleAgentRepo(group, collapsed)
+	if node.Type == NodeAgentWindow {
+		for i, n := range m.Nodes {
+			if n.Type == NodeAgentRepo && m.AgentRows[n.AgentIndex].Group() == group {
+				m.Cursor = i
+				break
+			}
+		}
+	}
+	m.adjustScroll()
+	return m, nil
+}
+
+// toggleAgentRepo records whether group is collapsed and rebuilds the nodes.
+func (m *Model) toggleAgentRepo(group string, collapsed bool) {
+	if m.CollapsedAgentRepos == nil {
+		m.CollapsedAgentRepos = make(map[string]bool)
+	}
+	if collapsed {
+		m.CollapsedAgentRepos[group] = true
+	} else {
+		delete(m.CollapsedAgentRepos, group)
+	}
+	m.Nodes = BuildAgentNodes(m.AgentRows, m.CollapsedAgentRepos)
+	if m.FilterMode {
+		m.updateFilteredNodes()
+	}
+	m.adjustScroll()
+}
+
+// diskUsageMsg is sent once the disk usage of a worktree has been measured
+// into Model.DiskUsage.
+type diskUsageMsg struct {
+	Path string
+}
+
+// diskUsageMeasurable reports whether the detail popup shows a size for
+// worktree. The main repo is left out: its size includes .worktrees/.
+func (m Model) diskUsageMeasurable(worktree WorktreeGroup) bool {
+	return m.DiskUsage != nil && !worktree.IsMainRepo && !worktree.IsMissing && worktree.Path != ""
+}
+
+// measureDiskUsageCmd measures worktree in the background unless a fresh
+// size is already cached or a measurement is under way.
+func (m Model) measureDiskUsageCmd(worktree WorktreeGroup) tea.Cmd {
+	if !m.diskUsageMeasurable(worktree) {
+		return nil
+	}
+	if _, ok := m.DiskUsage.Get(worktree.Path); ok || m.DiskUsage.Pending(worktree.Path) {
+		return nil
+	}
+	cache, path := m.DiskUsage, worktree.Path
+	return func() tea.Msg {
+		cache.Measure(path)
+		return diskUsageMsg{Path: path}
+	}
+}
+
+// detailDiskUsageCmd measures the worktree shown in the open detail popup,
+// as measureDiskUsageCmd.
+func (m Model) detailDiskUsageCmd() tea.Cmd {
+	if !m.ShowDetail {
+		return nil
+	}
+	_, worktree, ok := m.detailSession()
+	if !ok {
+		return nil
+	}
+	return m.measureDiskUsageCmd(worktree)
+}
+
+// detailSession returns the worktree-mode session (and its worktree) under
+// the cursor, for the detail popup.
+func (m Model) detailSession() (WorktreeSession, WorktreeGroup, bool) {
+	if m.Mode == DashboardModeAgents || m.Cursor >= len(m.Nodes) {
+		return WorktreeSession{}, WorktreeGroup{}, false
+	}
+	node := m.Nodes[m.Cursor]
+	if node.Type != NodeSession && node.Type != NodeWindow {
+		return WorktreeSession{}, WorktreeGroup{}, false
+	}
+	worktree := m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex]
+	return worktree.Sessions[node.SessionIndex], worktree, true
+}
+
 func (m Model) handleExpand() (tea.Model, tea.Cmd) {
 	if m.Cursor >= len(m.Nodes) {
 		return m, nil
@@ -934,6 +1832,10 @@ func (m Model) openAddDialogForNode(node TreeNode) (Model, tea.Cmd) {
 		if node.WorktreeIndex < 0 || node.WorktreeIndex >= len(m.Groups[node.RepoIndex].Worktrees) {
 			return m, nil
 		}
+		if m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex].IsMissing {
+			m.StatusMsg = "Worktree is missing; press x to clean up its sessions"
+			return m, nil
+		}
 		m.AddDialog = AddDialogState{
 			Active:      true,
 			Kind:        AddKindSession,
@@ -952,6 +1854,10 @@ func (m Model) openAddDialogForNode(node TreeNode) (Model, tea.Cmd) {
 		if node.SessionIndex < 0 || node.SessionIndex >= len(worktree.Sessions) {
 			return m, nil
 		}
+		if worktree.IsMissing {
+			m.StatusMsg = "Worktree is missing; press x to clean up its sessions"
+			return m, nil
+		}
 		sessionName := worktree.Sessions[node.SessionIndex].Name
 		m.AddDialog = AddDialogState{
 			Active:      true,
@@ -966,7 +1872,62 @@ func (m Model) openAddDialogForNode(node TreeNode) (Model, tea.Cmd) {
 	}
 }
 
+// openAgentDialogForNode opens the add-agent dialog for the session of the
+// agents-mode row under the cursor, preselecting that row's agent type.
+func (m Model) openAgentDialogForNode(node TreeNode) (Model, tea.Cmd) {
+	if node.Type != NodeAgentWindow || node.AgentIndex < 0 || node.AgentIndex >= len(m.AgentRows) {
+		return m, nil
+	}
+	row := m.AgentRows[node.AgentIndex]
+	choice := 0
+	for i, agent := range tmux.LaunchableAgents {
+		if agent == row.AgentType {
+			choice = i
+			break
+		}
+	}
+	m.AddDialog = AddDialogState{
+		Active:      true,
+		Kind:        AddKindAgent,
+		RepoIndex:   -1,
+		WorktreeIdx: -1,
+		SessionName: row.SessionName,
+		AgentChoice: choice,
+	}
+	return m, nil
+}
+
+// adoptSessionForNode brings the unmanaged session behind an agents-mode row
+// under management with adopt.Session, pinned to the row's window directory
+// (falling back to the session's first pane directory, as cb adopt does).
+func (m Model) adoptSessionForNode(node TreeNode) (Model, tea.Cmd) {
+	if node.Type != NodeAgentWindow || node.AgentIndex < 0 || node.AgentIndex >= len(m.AgentRows) {
+		return m, nil
+	}
+	row := m.AgentRows[node.AgentIndex]
+	if row.Managed {
+		m.StatusMsg = fmt.Sprintf("%s is already managed", row.SessionName)
+		return m, nil
+	}
+	client := m.TmuxClient
+	if client == nil {
+		m.StatusMsg = "Error: tmux client is not available"
+		return m, nil
+	}
+
+	session, windowIndex := row.SessionName, row.WindowIndex
+	m.StatusMsg = fmt.Sprintf("Adopting %s...", session)
+	return m, func() tea.Msg {
+		managed, _, err := adopt.Session(client, session, client.GetWindowWorkingDir(session, windowIndex))
+		return adoptResultMsg{Session: session, Managed: managed, Err: err}
+	}
+}
+
 func (m Model) submitAddDialog() (tea.Model, tea.Cmd) {
+	if m.AddDialog.Kind == AddKindAgent {
+		return m.submitAgentDialog()
+	}
+
 	dialog := m.AddDialog
 	rawName := dialog.Input
 	sanitized := sanitizeAddName(rawName)
@@ -993,6 +1954,7 @@ func (m Model) submitAddDialog() (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		worktreePath := group.Worktrees[dialog.WorktreeIdx].Path
+		projectName := group.Name
 		candidate := ensureSessionPrefix(sanitized)
 		if candidate == "cb_" {
 			m.AddDialog.Error = "name is required"
@@ -1011,7 +1973,7 @@ func (m Model) submitAddDialog() (tea.Model, tea.Cmd) {
 			for _, s := range sessions {
 				existing[s.Name] = struct{}{}
 			}
-			finalName := uniquifyName(candidate, func(name string) bool {
+			finalName := tmux.UniqueName(candidate, func(name string) bool {
 				_, ok := existing[name]
 				return ok
 			})
@@ -1027,6 +1989,9 @@ func (m Model) submitAddDialog() (tea.Model, tea.Cmd) {
 			if err := client.SetSessionOption(finalName, tmux.SessionOptionHomePath, canonicalPath); err != nil {
 				return addResultMsg{Kind: AddKindSession, Name: finalName, Target: worktreePath, Err: err}
 			}
+			// Best effort, like cb start: placement only needs the home path.
+			_ = client.SetSessionOption(finalName, tmux.SessionOptionProject, projectName)
+			_ = client.SetSessionOption(finalName, tmux.SessionOptionCreatedAt, strconv.FormatInt(time.Now().Unix(), 10))
 
 			return addResultMsg{Kind: AddKindSession, Name: finalName, Target: worktreePath}
 		}
@@ -1052,7 +2017,7 @@ func (m Model) submitAddDialog() (tea.Model, tea.Cmd) {
 				break
 			}
 		}
-		windowName := uniquifyName(sanitized, func(name string) bool {
+		windowName := tmux.UniqueName(sanitized, func(name string) bool {
 			_, ok := existing[name]
 			return ok
 		})
@@ -1074,6 +2039,90 @@ func (m Model) submitAddDialog() (tea.Model, tea.Cmd) {
 	}
 }
 
+// submitAgentDialog creates a window in the dialog's session running the
+// chosen agent, resuming its last conversation when the dialog asks to. The
+// window is named as the dialog says (see AddDialogState.agentWindowName),
+// records its purpose when it has one, and opens in the session's pinned
+// home path when it has one.
+func (m Model) submitAgentDialog() (tea.Model, tea.Cmd) {
+	dialog := m.AddDialog
+	agent := dialog.selectedAgent()
+	command := agent.LaunchCommand()
+
+	baseName, purpose := dialog.agentWindowName(m.AgentWindowName)
+	if dialog.Resume {
+		command = agent.ResumeCommand()
+	}
+
+	client := m.TmuxClient
+	if client == nil {
+		m.AddDialog.Error = "tmux client is not available"
+		return m, nil
+	}
+	sessionName := dialog.SessionName
+	if sessionName == "" {
+		m.AddDialog.Error = "target session no longer exists"
+		return m, nil
+	}
+
+	m.AddDialog = AddDialogState{}
+	m.StatusMsg = fmt.Sprintf("Starting %s in %s...", command, sessionName)
+	return m, func() tea.Msg {
+		windows, err := client.ListWindows(sessionName)
+		if err != nil {
+			return addResultMsg{Kind: AddKindAgent, Target: sessionName, Err: err}
+		}
+		existing := make(map[string]struct{}, len(windows))
+		for _, w := range windows {
+			existing[w.Name] = struct{}{}
+		}
+		windowName := tmux.UniqueName(baseName, func(name string) bool {
+			_, ok := existing[name]
+			return ok
+		})
+
+		workdir, optErr := client.GetSessionOption(sessionName, tmux.SessionOptionHomePath)
+		if optErr != nil {
+			workdir = ""
+		}
+		err = client.CreateWindowWithShellInDir(sessionName, windowName, command, workdir)
+		if err == nil && purpose != "" {
+			recordWindowPurpose(client, sessionName, windowName, purpose)
+		}
+		return addResultMsg{Kind: AddKindAgent, Name: windowName, Target: sessionName, Err: err}
+	}
+}
+
+// windowOptionSetter is implemented by multiplexer clients that keep window
+// options (see tmux.Client.SetWindowOption).
+type windowOptionSetter interface {
+	SetWindowOption(target, key, value string) error
+}
+
+// recordWindowPurpose records purpose on the window named windowName in
+// session (see tmux.WindowOptionPurpose). Clients without window options
+// skip it; the window then shows under its full name.
+func recordWindowPurpose(client multiplexer.Multiplexer, session, windowName, purpose string) {
+	setter, ok := client.(windowOptionSetter)
+	if !ok {
+		return
+	}
+	windows, err := client.ListWindows(session)
+	if err != nil {
+		slog.Debug("recordWindowPurpose: ListWindows failed", "session", session, "err", err)
+		return
+	}
+	for _, w := range windows {
+		if w.Name != windowName {
+			continue
+		}
+		if err := setter.SetWindowOption(w.Target(session), tmux.WindowOptionPurpose, purpose); err != nil {
+			slog.Debug("recordWindowPurpose: SetWindowOption failed", "window", windowName, "err", err)
+		}
+		return
+	}
+}
+
 func sanitizeAddName(raw string) string {
 	var b strings.Builder
 	for _, r := range strings.ToLower(strings.TrimSpace(raw)) {
@@ -1098,15 +2147,3 @@ func ensureSessionPrefix(name string) string {
 	}
 	return "cb_" + name
 }
-
-func uniquifyName(base string, exists func(string) bool) string {
-	if !exists(base) {
-		return base
-	}
-	for i := 2; ; i++ {
-		candidate := fmt.Sprintf("%s-%d", base, i)
-		if !exists(candidate) {
-			return candidate
-		}
-	}
-}
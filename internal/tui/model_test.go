@@ -1,11 +1,21 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ronsanzone/clawd-bay/internal/config"
 	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/multiplexer"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 )
 
@@ -18,29 +28,6 @@ func (s stubDiscoverer) Discover() (discovery.Result, error) {
 	return s.result, s.err
 }
 
-func TestRollupStatus(t *testing.T) {
-	tests := []struct {
-		name     string
-		statuses []tmux.Status
-		want     tmux.Status
-	}{
-		{"working wins all", []tmux.Status{tmux.StatusDone, tmux.StatusWorking, tmux.StatusWaiting}, tmux.StatusWorking},
-		{"waiting over idle", []tmux.Status{tmux.StatusIdle, tmux.StatusWaiting, tmux.StatusDone}, tmux.StatusWaiting},
-		{"idle over done", []tmux.Status{tmux.StatusDone, tmux.StatusIdle}, tmux.StatusIdle},
-		{"all done", []tmux.Status{tmux.StatusDone, tmux.StatusDone}, tmux.StatusDone},
-		{"empty returns done", []tmux.Status{}, tmux.StatusDone},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := RollupStatus(tt.statuses)
-			if got != tt.want {
-				t.Errorf("RollupStatus() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 func TestBuildNodes_FourLevelHierarchy(t *testing.T) {
 	groups := []RepoGroup{
 		{
@@ -183,7 +170,7 @@ func TestUpdate_ExpandCollapseProjectAndWorktree(t *testing.T) {
 	}
 }
 
-func TestHandleEnter_WindowSetsSelectedWindowIndex(t *testing.T) {
+func TestHandleEnter_WindowSetsSelectedWindowTarget(t *testing.T) {
 	m := Model{
 		Groups: []RepoGroup{
 			{
@@ -198,8 +185,8 @@ func TestHandleEnter_WindowSetsSelectedWindowIndex(t *testing.T) {
 								Name:     "cb_test",
 								Expanded: true,
 								Windows: []tmux.Window{
-									{Index: 0, Name: "shell"},
-									{Index: 5, Name: "claude"},
+									{ID: "@4", Index: 0, Name: "shell"},
+									{ID: "@9", Index: 5, Name: "claude"},
 								},
 							},
 						},
@@ -207,10 +194,9 @@ func TestHandleEnter_WindowSetsSelectedWindowIndex(t *testing.T) {
 				},
 			},
 		},
-		Styles:              NewStyles(KanagawaClaw),
-		WindowStatuses:      make(map[string]tmux.Status),
-		SelectedWindowIndex: -1,
-		Cursor:              4,
+		Styles:         NewStyles(KanagawaClaw),
+		WindowStatuses: make(map[string]tmux.Status),
+		Cursor:         4,
 	}
 	m.Nodes = BuildNodes(m.Groups)
 
@@ -222,8 +208,8 @@ func TestHandleEnter_WindowSetsSelectedWindowIndex(t *testing.T) {
 	if result.SelectedName != "cb_test" {
 		t.Fatalf("SelectedName = %q, want cb_test", result.SelectedName)
 	}
-	if result.SelectedWindowIndex != 5 {
-		t.Fatalf("SelectedWindowIndex = %d, want 5", result.SelectedWindowIndex)
+	if result.SelectedWindowTarget != "@9" {
+		t.Fatalf("SelectedWindowTarget = %q, want @9", result.SelectedWindowTarget)
 	}
 }
 
@@ -261,12 +247,11 @@ func TestFilterModeMatchesWorktreeNames(t *testing.T) {
 
 func TestUpdateRefreshMsgSetsWindowAgentTypes(t *testing.T) {
 	m := Model{
-		Styles:              NewStyles(KanagawaClaw),
-		WindowStatuses:      make(map[string]tmux.Status),
-		WindowAgentTypes:    make(map[string]tmux.AgentType),
-		SelectedWindowIndex: -1,
-		Width:               80,
-		Height:              24,
+		Styles:           NewStyles(KanagawaClaw),
+		WindowStatuses:   make(map[string]tmux.Status),
+		WindowAgentTypes: make(map[string]tmux.AgentType),
+		Width:            80,
+		Height:           24,
 	}
 
 	msg := refreshMsg{
@@ -280,25 +265,279 @@ func TestUpdateRefreshMsgSetsWindowAgentTypes(t *testing.T) {
 					Name:     "cb_demo",
 					Status:   tmux.StatusWorking,
 					Expanded: true,
-					Windows:  []tmux.Window{{Index: 1, Name: "custom-window"}},
+					Windows:  []tmux.Window{{ID: "@4", Index: 1, Name: "custom-window"}},
 				}},
 			}},
 		}},
-		WindowStatuses: map[string]tmux.Status{"cb_demo:custom-window": tmux.StatusWorking},
-		WindowAgents:   map[string]tmux.AgentType{"cb_demo:custom-window": tmux.AgentCodex},
+		WindowStatuses: map[string]tmux.Status{"@4": tmux.StatusWorking},
+		WindowAgents:   map[string]tmux.AgentType{"@4": tmux.AgentCodex},
 	}
 
 	updated, _ := m.Update(msg)
 	out := updated.(Model)
 
-	if got := out.WindowAgentTypes["cb_demo:custom-window"]; got != tmux.AgentCodex {
+	if got := out.WindowAgentTypes["@4"]; got != tmux.AgentCodex {
 		t.Fatalf("WindowAgentTypes[...] = %q, want %q", got, tmux.AgentCodex)
 	}
-	if got := out.WindowStatuses["cb_demo:custom-window"]; got != tmux.StatusWorking {
+	if got := out.WindowStatuses["@4"]; got != tmux.StatusWorking {
 		t.Fatalf("WindowStatuses[...] = %q, want %q", got, tmux.StatusWorking)
 	}
 }
 
+func TestUpdateRefreshMsgSkipsUnchangedContent(t *testing.T) {
+	msg := refreshMsg{
+		Groups:         []RepoGroup{{Name: "repo", Path: "/tmp/repo"}},
+		WindowStatuses: map[string]tmux.Status{"@1": tmux.StatusIdle},
+	}
+	msg.Hash = refreshHash(msg)
+
+	m := Model{Styles: NewStyles(KanagawaClaw)}
+	updated, _ := m.Update(msg)
+	m = updated.(Model)
+	if len(m.Nodes) != 1 {
+		t.Fatalf("first refresh should apply, nodes = %+v", m.Nodes)
+	}
+
+	m.Nodes = nil
+	updated, _ = m.Update(msg)
+	m = updated.(Model)
+	if m.Nodes != nil {
+		t.Fatal("identical refresh should not rebuild nodes")
+	}
+
+	changed := msg
+	changed.WindowStatuses = map[string]tmux.Status{"@1": tmux.StatusWorking}
+	changed.Hash = refreshHash(changed)
+	if changed.Hash == msg.Hash {
+		t.Fatal("refreshHash() should change with window statuses")
+	}
+	updated, _ = m.Update(changed)
+	m = updated.(Model)
+	if len(m.Nodes) != 1 || m.WindowStatuses["@1"] != tmux.StatusWorking {
+		t.Fatalf("changed refresh should apply, nodes = %+v statuses = %v", m.Nodes, m.WindowStatuses)
+	}
+}
+
+func TestNextRefreshInterval(t *testing.T) {
+	tests := []struct {
+		last time.Duration
+		want time.Duration
+	}{
+		{last: 0, want: refreshInterval},
+		{last: 500 * time.Millisecond, want: refreshInterval},
+		{last: 4200 * time.Millisecond, want: 8400 * time.Millisecond},
+		{last: time.Minute, want: maxRefreshInterval},
+	}
+	for _, tt := range tests {
+		if got := nextRefreshInterval(tt.last); got != tt.want {
+			t.Errorf("nextRefreshInterval(%v) = %v, want %v", tt.last, got, tt.want)
+		}
+	}
+}
+
+func TestTickSkipsRefreshWhileInFlight(t *testing.T) {
+	m := Model{Styles: NewStyles(KanagawaClaw), Width: 80, Height: 24, refreshInFlight: true}
+
+	updated, cmd := m.Update(tickMsg(time.Now()))
+	m = updated.(Model)
+	if cmd == nil || !m.refreshInFlight {
+		t.Fatal("tick should reschedule without starting another refresh")
+	}
+
+	updated, _ = m.Update(refreshMsg{Duration: 4200 * time.Millisecond})
+	m = updated.(Model)
+	if m.refreshInFlight {
+		t.Fatal("refresh completion should clear the in-flight flag")
+	}
+	if bar := m.renderStatusBar(); !strings.Contains(bar, "refresh took 4.2s") {
+		t.Fatalf("status bar = %q, want slow refresh notice", bar)
+	}
+}
+
+func TestTickExpiresStatusMsg(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := Model{Styles: NewStyles(KanagawaClaw), Width: 80, Height: 24, refreshInFlight: true, StatusMsg: "Pinned cb_demo"}
+
+	for _, at := range []time.Time{start, start.Add(statusMsgTTL - time.Second)} {
+		updated, _ := m.Update(tickMsg(at))
+		if m = updated.(Model); m.StatusMsg != "Pinned cb_demo" {
+			t.Fatalf("tick at %v cleared the status message early", at.Sub(start))
+		}
+	}
+	updated, _ := m.Update(tickMsg(start.Add(statusMsgTTL)))
+	if m = updated.(Model); m.StatusMsg != "" {
+		t.Fatalf("StatusMsg = %q after %v, want cleared", m.StatusMsg, statusMsgTTL)
+	}
+}
+
+func TestRenderStatusBar_RefreshHealth(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	orig := clock
+	clock = func() time.Time { return now }
+	t.Cleanup(func() { clock = orig })
+
+	failed := errors.New("tmux: no server running")
+	tests := []struct {
+		name    string
+		model   Model
+		want    string
+		notWant string
+	}{
+		{"before the first refresh", Model{}, "", "updated"},
+		{"first refresh running", Model{refreshInFlight: true}, "⠋ loading", "updated"},
+		{"refreshed", Model{lastRefreshAt: now.Add(-2 * time.Second)}, "updated 2s ago", "⚠"},
+		{"refresh running", Model{lastRefreshAt: now.Add(-3 * time.Second), refreshInFlight: true, spinnerFrame: 1}, "⠙ updated 3s ago", "⚠"},
+		{"last refresh failed", Model{lastRefreshAt: now.Add(-time.Minute), lastRefreshErr: failed}, "⚠ updated 1m00s ago", ""},
+		{"first refresh failed", Model{lastRefreshErr: failed}, "⚠ not loaded", "updated"},
+		{"ascii", Model{ASCII: true, lastRefreshAt: now, lastRefreshErr: failed, refreshInFlight: true, spinnerFrame: 3}, "! \\ updated 0s ago", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.model.Styles = NewStyles(KanagawaClaw)
+			bar := tt.model.renderStatusBar()
+			if tt.want != "" && !strings.Contains(bar, tt.want) {
+				t.Errorf("status bar = %q, want %q", bar, tt.want)
+			}
+			if tt.notWant != "" && strings.Contains(bar, tt.notWant) {
+				t.Errorf("status bar = %q, should not contain %q", bar, tt.notWant)
+			}
+		})
+	}
+}
+
+func TestRenderStatusBar_ConfigSummary(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "cb", "config.toml")
+	tests := []struct {
+		name    string
+		model   Model
+		want    string
+		notWant string
+	}{
+		{"before the first refresh", Model{}, "", "config:"},
+		{"missing config", Model{ConfigPath: path, ConfigMissing: true}, "config: ~/.config/cb/config.toml", "projects"},
+		{"no projects", Model{ConfigPath: path}, "config: ~/.config/cb/config.toml", "projects"},
+		{"one project", Model{ConfigPath: path, ProjectCount: 1}, "1 project", "config:"},
+		{"several projects", Model{ConfigPath: path, ProjectCount: 3}, "3 projects", "config:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.model.Styles = NewStyles(KanagawaClaw)
+			bar := tt.model.renderStatusBar()
+			if tt.want != "" && !strings.Contains(bar, tt.want) {
+				t.Errorf("status bar = %q, want %q", bar, tt.want)
+			}
+			if tt.notWant != "" && strings.Contains(bar, tt.notWant) {
+				t.Errorf("status bar = %q, should not contain %q", bar, tt.notWant)
+			}
+		})
+	}
+}
+
+func TestRefreshMsgTracksHealth(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m := Model{Styles: NewStyles(KanagawaClaw), refreshInFlight: true}
+
+	updated, _ := m.Update(refreshMsg{At: at, ConfigPath: "/tmp/config.toml", ProjectCount: 2})
+	m = updated.(Model)
+	if m.ConfigPath != "/tmp/config.toml" || m.ProjectCount != 2 {
+		t.Fatalf("config metadata not applied: ConfigPath = %q, ProjectCount = %d", m.ConfigPath, m.ProjectCount)
+	}
+	if !m.lastRefreshAt.Equal(at) || m.lastRefreshErr != nil {
+		t.Fatalf("after success: lastRefreshAt = %v, lastRefreshErr = %v", m.lastRefreshAt, m.lastRefreshErr)
+	}
+
+	updated, _ = m.Update(refreshMsg{Err: errors.New("boom"), At: at.Add(time.Minute)})
+	m = updated.(Model)
+	if !m.lastRefreshAt.Equal(at) || m.lastRefreshErr == nil {
+		t.Fatalf("after failure: lastRefreshAt = %v, lastRefreshErr = %v; want the last success kept and the error set", m.lastRefreshAt, m.lastRefreshErr)
+	}
+
+	// The spinner stops ticking once no refresh is in flight.
+	m.spinnerActive = true
+	updated, cmd := m.Update(spinnerTickMsg{})
+	m = updated.(Model)
+	if cmd != nil || m.spinnerActive {
+		t.Fatalf("spinner tick without refresh: cmd = %v, spinnerActive = %v", cmd != nil, m.spinnerActive)
+	}
+	updated, cmd = m.Update(tickMsg(at))
+	m = updated.(Model)
+	if cmd == nil || !m.spinnerActive {
+		t.Fatal("tick starting a refresh should restart the spinner")
+	}
+	updated, cmd = m.Update(spinnerTickMsg{})
+	if updated.(Model).spinnerFrame != 1 || cmd == nil {
+		t.Fatal("spinner tick during a refresh should advance and reschedule")
+	}
+}
+
+type blockingDiscoverer struct {
+	release chan struct{}
+}
+
+func (b blockingDiscoverer) Discover() (discovery.Result, error) {
+	<-b.release
+	return discovery.Result{}, nil
+}
+
+// cancellableDiscoverer blocks until its context is done, reporting that on
+// cancelled.
+type cancellableDiscoverer struct {
+	cancelled chan struct{}
+}
+
+func (c cancellableDiscoverer) Discover() (discovery.Result, error) {
+	select {}
+}
+
+func (c cancellableDiscoverer) DiscoverContext(ctx context.Context) (discovery.Result, error) {
+	<-ctx.Done()
+	close(c.cancelled)
+	return discovery.Result{}, ctx.Err()
+}
+
+func TestRefreshCmdTimesOut(t *testing.T) {
+	orig := refreshTimeout
+	refreshTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { refreshTimeout = orig })
+
+	release := make(chan struct{})
+	defer close(release)
+	m := Model{Discoverer: blockingDiscoverer{release: release}}
+
+	msg, ok := m.refreshCmd()().(refreshMsg)
+	if !ok {
+		t.Fatal("refreshCmd() did not return a refreshMsg")
+	}
+	if msg.Err == nil || !strings.Contains(msg.Err.Error(), "timed out") {
+		t.Fatalf("refreshMsg.Err = %v, want timeout", msg.Err)
+	}
+
+	updated, _ := (Model{Styles: NewStyles(KanagawaClaw), refreshInFlight: true}).Update(msg)
+	out := updated.(Model)
+	if out.refreshInFlight || !strings.Contains(out.StatusMsg, "timed out") {
+		t.Fatalf("timeout not surfaced: inFlight=%v StatusMsg=%q", out.refreshInFlight, out.StatusMsg)
+	}
+}
+
+func TestRefreshCmdTimeoutCancelsDiscovery(t *testing.T) {
+	orig := refreshTimeout
+	refreshTimeout = 10 * time.Millisecond
+	t.Cleanup(func() { refreshTimeout = orig })
+
+	cancelled := make(chan struct{})
+	m := Model{Discoverer: cancellableDiscoverer{cancelled: cancelled}}
+	if msg, ok := m.refreshCmd()().(refreshMsg); !ok || msg.Err == nil {
+		t.Fatalf("refreshCmd() = %+v, want a timeout refreshMsg", msg)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("timed-out refresh did not cancel the discovery's context")
+	}
+}
+
 func TestCursorToLine_Table(t *testing.T) {
 	nodes := []TreeNode{
 		{Type: NodeRepo},
@@ -325,19 +564,348 @@ func TestCursorToLine_Table(t *testing.T) {
 
 func TestBuildAgentNodes(t *testing.T) {
 	rows := []AgentWindowRow{
-		{SessionName: "cb_demo", WindowName: "claude", WindowIndex: 1},
-		{SessionName: "other", WindowName: "codex", WindowIndex: 3},
+		{SessionName: "cb_demo", WindowName: "claude", WindowIndex: 1, RepoName: "demo-feat", Project: "demo"},
+		{SessionName: "cb_demo2", WindowName: "codex", WindowIndex: 2, RepoName: "demo", Project: "demo"},
+		{SessionName: "other", WindowName: "codex", WindowIndex: 3, RepoName: "other"},
+	}
+
+	tests := []struct {
+		name      string
+		collapsed map[string]bool
+		want      []TreeNode
+	}{
+		{
+			name: "headers before each repo",
+			want: []TreeNode{
+				{Type: NodeAgentRepo, AgentIndex: 0},
+				{Type: NodeAgentWindow, AgentIndex: 0},
+				{Type: NodeAgentWindow, AgentIndex: 1},
+				{Type: NodeAgentRepo, AgentIndex: 2},
+				{Type: NodeAgentWindow, AgentIndex: 2},
+			},
+		},
+		{
+			name:      "collapsed repo hides its rows",
+			collapsed: map[string]bool{"demo": true},
+			want: []TreeNode{
+				{Type: NodeAgentRepo, AgentIndex: 0},
+				{Type: NodeAgentRepo, AgentIndex: 2},
+				{Type: NodeAgentWindow, AgentIndex: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildAgentNodes(rows, tt.collapsed)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("BuildAgentNodes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentsModeCollapseRepo(t *testing.T) {
+	m := Model{
+		Mode: DashboardModeAgents,
+		AgentRows: []AgentWindowRow{
+			{SessionName: "cb_a", WindowName: "claude", Project: "alpha", Status: tmux.StatusWaiting},
+			{SessionName: "cb_b", WindowName: "claude", Project: "alpha", Status: tmux.StatusIdle},
+			{SessionName: "cb_c", WindowName: "codex", Project: "beta", Status: tmux.StatusWorking},
+		},
+		Styles: NewStyles(KanagawaClaw),
+		Width:  80,
+		Height: 24,
+	}
+	m.Nodes = BuildAgentNodes(m.AgentRows, nil)
+	m.Cursor = 2 // cb_b
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'h'}})
+	m = updated.(Model)
+	if !m.CollapsedAgentRepos["alpha"] || len(m.Nodes) != 3 || m.Cursor != 0 {
+		t.Fatalf("after h: collapsed=%v nodes=%+v cursor=%d; want alpha collapsed, cursor on its header", m.CollapsedAgentRepos, m.Nodes, m.Cursor)
+	}
+	if view := m.View(); !strings.Contains(view, "▸") || !strings.Contains(view, "alpha") || strings.Contains(view, "cb_a:") {
+		t.Fatalf("collapsed view should show the alpha header only:\n%s", view)
+	}
+
+	// Collapsed state survives a refresh.
+	updated, _ = m.Update(refreshMsg{AgentRows: m.AgentRows})
+	m = updated.(Model)
+	if len(m.Nodes) != 3 {
+		t.Fatalf("after refresh nodes = %+v, want alpha still collapsed", m.Nodes)
+	}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if cmd != nil || m.CollapsedAgentRepos["alpha"] || len(m.Nodes) != 5 {
+		t.Fatalf("enter on header should expand alpha: collapsed=%v nodes=%+v", m.CollapsedAgentRepos, m.Nodes)
+	}
+}
+
+func TestBuildAgentRowsSkipsIgnoredSessions(t *testing.T) {
+	infos := []tmux.SessionWindowInfo{
+		{SessionName: "cb_demo", Window: tmux.Window{ID: "@1", Index: 1, Name: "claude"}, AgentInfo: tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusIdle}},
+		{SessionName: "scratch", Window: tmux.Window{ID: "@2", Index: 0, Name: "claude"}, AgentInfo: tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusWorking}},
+		{SessionName: "cb_demo", Window: tmux.Window{ID: "@3", Index: 2, Name: "shell"}},
+	}
+
+	rows, statuses, agents := buildAgentRows(infos, func(name string) bool { return name == "scratch" })
+	if len(rows) != 1 || rows[0].SessionName != "cb_demo" {
+		t.Fatalf("rows = %+v, want only cb_demo agent window", rows)
+	}
+	if _, ok := statuses["@2"]; ok {
+		t.Fatalf("statuses include ignored session: %v", statuses)
+	}
+	if agents["@1"] != tmux.AgentClaude {
+		t.Fatalf("agents = %v, want @1 (cb_demo:claude)", agents)
+	}
+}
+
+func TestScopeGroupsAndAgentRows(t *testing.T) {
+	scope := RepoScope{Label: "alpha", Path: "/code/repo"}
+	groups := []RepoGroup{{Name: "alpha", Path: "/code/repo"}, {Name: "other", Path: "/code/other"}}
+	if got := scopeGroups(groups, scope); len(got) != 1 || got[0].Path != "/code/repo" {
+		t.Fatalf("scopeGroups() = %+v, want only /code/repo", got)
+	}
+	if got := scopeGroups(groups, RepoScope{}); len(got) != 2 {
+		t.Fatalf("unscoped scopeGroups() = %+v, want all groups", got)
+	}
+
+	rows := []AgentWindowRow{
+		{RepoName: "repo", ProjectPath: "/code/repo"},
+		{RepoName: "repo-feature", ProjectPath: "/code/repo"},
+		{RepoName: "repo-tools", ProjectPath: "/code/repo-tools"},
+		{RepoName: "other", ProjectPath: "/code/other"},
+	}
+	got := scopeAgentRows(rows, scope)
+	if len(got) != 2 || got[0].RepoName != "repo" || got[1].RepoName != "repo-feature" {
+		t.Fatalf("scopeAgentRows() = %+v, want repo and repo-feature", got)
+	}
+}
+
+func TestWorkspaceScope(t *testing.T) {
+	cfg := config.UserConfig{Projects: []config.ProjectConfig{
+		{Path: "/code/api", Workspace: "work"},
+		{Path: "/code/web", Workspace: "work"},
+		{Path: "/oss/lib", Workspace: "oss"},
+		{Path: "/code/notes"},
+	}}
+	scope := workspaceScope(cfg, "work")
+
+	groups := []RepoGroup{{Path: "/code/api"}, {Path: "/oss/lib"}, {Path: "/code/web"}, {Path: "/code/notes"}}
+	got := scope.groups(groups)
+	if len(got) != 2 || got[0].Path != "/code/api" || got[1].Path != "/code/web" {
+		t.Fatalf("groups() = %+v, want /code/api and /code/web", got)
+	}
+
+	rows := []AgentWindowRow{
+		{RepoName: "api", ProjectPath: "/code/api"},
+		{RepoName: "web-feature", ProjectPath: "/code/web"},
+		{RepoName: "lib", ProjectPath: "/oss/lib"},
+		{RepoName: "api-tools", ProjectPath: "/code/api-tools"},
+		{RepoName: "Unknown"},
+	}
+	gotRows := scope.agentRows(rows)
+	if len(gotRows) != 2 || gotRows[0].RepoName != "api" || gotRows[1].RepoName != "web-feature" {
+		t.Fatalf("agentRows() = %+v, want api and web-feature", gotRows)
+	}
+}
+
+func TestWorkspaceScope_CanonicalPaths(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "api")
+	link := filepath.Join(dir, "api-link")
+	if err := os.MkdirAll(real, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
 	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	scope := workspaceScope(config.UserConfig{Projects: []config.ProjectConfig{{Path: link, Workspace: "work"}}}, "work")
 
-	nodes := BuildAgentNodes(rows)
-	if len(nodes) != 2 {
-		t.Fatalf("len(nodes) = %d, want %d", len(nodes), 2)
+	if got := scope.groups([]RepoGroup{{Path: real}}); len(got) != 1 {
+		t.Fatalf("groups() = %+v, want the project under its resolved path", got)
+	}
+	if got := scope.agentRows([]AgentWindowRow{{ProjectPath: real}}); len(got) != 1 {
+		t.Fatalf("agentRows() = %+v, want the row under the resolved path", got)
 	}
-	if nodes[0].Type != NodeAgentWindow || nodes[0].AgentIndex != 0 {
-		t.Fatalf("node[0] = %+v, want NodeAgentWindow index 0", nodes[0])
+}
+
+func TestCycleWorkspace(t *testing.T) {
+	m := Model{Workspaces: []string{"oss", "work"}, Cursor: 3}
+
+	var got []string
+	for range 3 {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+		m = updated.(Model)
+		if cmd == nil {
+			t.Fatal("switching workspace should refresh")
+		}
+		got = append(got, m.Workspace)
 	}
-	if nodes[1].Type != NodeAgentWindow || nodes[1].AgentIndex != 1 {
-		t.Fatalf("node[1] = %+v, want NodeAgentWindow index 1", nodes[1])
+	if want := []string{"oss", "work", ""}; !slices.Equal(got, want) {
+		t.Fatalf("workspaces = %q, want %q", got, want)
+	}
+	if m.Cursor != 0 {
+		t.Fatalf("Cursor = %d, want 0 after switching", m.Cursor)
+	}
+
+	// A refresh started before the switch is dropped.
+	m.Workspace = "work"
+	updated, _ := m.Update(refreshMsg{Workspace: "oss", Groups: []RepoGroup{{Path: "/oss/lib"}}})
+	if m = updated.(Model); len(m.Groups) != 0 {
+		t.Fatalf("Groups = %+v, want stale refresh ignored", m.Groups)
+	}
+
+	m = Model{}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	if m = updated.(Model); m.Workspace != "" || !strings.Contains(m.StatusMsg, "No workspaces") {
+		t.Fatalf("without workspaces: Workspace = %q, StatusMsg = %q", m.Workspace, m.StatusMsg)
+	}
+}
+
+func TestWithFilterMatchesWorktreeSessionStatus(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     "(main repo)",
+				Expanded: true,
+				Sessions: []WorktreeSession{
+					{Name: "cb_busy", Status: tmux.StatusWorking},
+					{Name: "cb_blocked", Status: tmux.StatusWaiting},
+				},
+			}},
+		}},
+		Styles: NewStyles(KanagawaClaw),
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	m = m.WithFilter("waiting")
+	if !m.FilterMode || m.FilterQuery != "waiting" {
+		t.Fatalf("filter not applied: mode=%v query=%q", m.FilterMode, m.FilterQuery)
+	}
+	if len(m.FilteredNodes) != 1 || m.FilteredNodes[0].Type != NodeSession || m.FilteredNodes[0].SessionIndex != 1 {
+		t.Fatalf("FilteredNodes = %+v, want only cb_blocked", m.FilteredNodes)
+	}
+}
+
+func TestWithFilterMatchesSessionTags(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     "(main repo)",
+				Expanded: true,
+				Sessions: []WorktreeSession{
+					{Name: "cb_api", Tags: []string{"backend", "urgent"}, Windows: []tmux.Window{{Index: 0, Name: "claude"}}, Expanded: true},
+					{Name: "cb_ui", Tags: []string{"frontend", "urgent"}},
+				},
+			}},
+		}},
+		Styles: NewStyles(KanagawaClaw),
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "tag:urgent", want: []string{"cb_api", "cb_api:claude", "cb_ui"}},
+		{query: "tag:urgent tag:backend", want: []string{"cb_api", "cb_api:claude"}},
+		{query: "TAG:urgent ui", want: []string{"cb_ui"}},
+		{query: "tag:missing", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filtered := m.WithFilter(tt.query)
+			var got []string
+			for _, node := range filtered.FilteredNodes {
+				session := m.Groups[0].Worktrees[0].Sessions[node.SessionIndex]
+				name := session.Name
+				if node.Type == NodeWindow {
+					name += ":" + session.Windows[node.WindowIndex].Name
+				}
+				got = append(got, name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("filter %q matched %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterModeTypesNavigationLetters(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     "(main repo)",
+				Expanded: true,
+				Sessions: []WorktreeSession{
+					{Name: "cb_api", Tags: []string{"backend"}},
+					{Name: "cb_jobs", Tags: []string{"backend"}},
+					{Name: "cb_ui", Tags: []string{"frontend"}},
+				},
+			}},
+		}},
+		Styles:     NewStyles(KanagawaClaw),
+		FilterMode: true,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	for _, r := range "tag:backend" {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(Model)
+	}
+	if m.FilterQuery != "tag:backend" || len(m.FilteredNodes) != 2 {
+		t.Fatalf("FilterQuery = %q matching %d nodes, want tag:backend matching 2", m.FilterQuery, len(m.FilteredNodes))
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(Model)
+	if m.FilteredCursor != 1 {
+		t.Fatalf("FilteredCursor after down = %d, want 1", m.FilteredCursor)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m = updated.(Model)
+	if m.FilteredCursor != 0 || m.FilterQuery != "tag:backend" {
+		t.Fatalf("after up: cursor %d, query %q; want cursor 0, query unchanged", m.FilteredCursor, m.FilterQuery)
+	}
+}
+
+func TestBuildAgentRowsGroupsByRepoAndSortsByAttention(t *testing.T) {
+	info := func(session, repo string, index int, status tmux.Status) tmux.SessionWindowInfo {
+		return tmux.SessionWindowInfo{
+			SessionName: session,
+			RepoName:    repo,
+			Window:      tmux.Window{Index: index, Name: fmt.Sprintf("w%d", index)},
+			AgentInfo:   tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: status},
+		}
+	}
+	infos := []tmux.SessionWindowInfo{
+		info("cb_b", "repo-b", 0, tmux.StatusIdle),
+		info("cb_a", "repo-a", 1, tmux.StatusWorking),
+		info("cb_b", "repo-b", 2, tmux.StatusWaiting),
+		info("cb_a", "repo-a", 3, tmux.StatusDone),
+		info("cb_a", "repo-a", 0, tmux.StatusWaiting),
+		info("cb_a", "repo-a", 4, tmux.StatusIdle),
+	}
+
+	infos = append(infos, info("cb_c", "repo-c", 0, tmux.StatusWorking))
+
+	rows, _, _ := buildAgentRows(infos, func(string) bool { return false })
+	var got []string
+	for _, row := range rows {
+		got = append(got, fmt.Sprintf("%s:%d", row.SessionName, row.WindowIndex))
+	}
+	// Repos with a WAITING row come first (ties by name), then repo-c.
+	want := "cb_a:0 cb_a:1 cb_a:4 cb_a:3 cb_b:2 cb_b:0 cb_c:0"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("row order = %v, want %s", got, want)
 	}
 }
 
@@ -364,14 +932,13 @@ func TestAgentsModeFilterAndEnterSelectsWindowByIndex(t *testing.T) {
 				Managed:     false,
 			},
 		},
-		Styles:              NewStyles(KanagawaClaw),
-		WindowStatuses:      make(map[string]tmux.Status),
-		WindowAgentTypes:    make(map[string]tmux.AgentType),
-		Width:               80,
-		Height:              24,
-		SelectedWindowIndex: -1,
+		Styles:           NewStyles(KanagawaClaw),
+		WindowStatuses:   make(map[string]tmux.Status),
+		WindowAgentTypes: make(map[string]tmux.AgentType),
+		Width:            80,
+		Height:           24,
 	}
-	m.Nodes = BuildAgentNodes(m.AgentRows)
+	m.Nodes = BuildAgentNodes(m.AgentRows, nil)
 
 	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
 	m = updated.(Model)
@@ -390,8 +957,8 @@ func TestAgentsModeFilterAndEnterSelectsWindowByIndex(t *testing.T) {
 	if result.SelectedName != "cb_demo" {
 		t.Fatalf("SelectedName = %q, want %q", result.SelectedName, "cb_demo")
 	}
-	if result.SelectedWindowIndex != 9 {
-		t.Fatalf("SelectedWindowIndex = %d, want %d", result.SelectedWindowIndex, 9)
+	if result.SelectedWindowTarget != "cb_demo:9" {
+		t.Fatalf("SelectedWindowTarget = %q, want %q", result.SelectedWindowTarget, "cb_demo:9")
 	}
 }
 
@@ -434,7 +1001,7 @@ func TestToggleModeResetsFilterAndCursor(t *testing.T) {
 	}
 }
 
-func TestAgentsModeIgnoresTreeAndCreateKeys(t *testing.T) {
+func TestAgentsModeIgnoresTreeKeysAndOpensAgentDialog(t *testing.T) {
 	m := Model{
 		Mode: DashboardModeAgents,
 		AgentRows: []AgentWindowRow{
@@ -452,24 +1019,177 @@ func TestAgentsModeIgnoresTreeAndCreateKeys(t *testing.T) {
 		Width:          80,
 		Height:         24,
 	}
-	m.Nodes = BuildAgentNodes(m.AgentRows)
+	m.Nodes = BuildAgentNodes(m.AgentRows, nil)
+	m.Cursor = 1 // the row under the repo header
 
 	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
 	m = updated.(Model)
 	if cmd != nil {
 		t.Fatal("expected nil cmd for expand in agents mode")
 	}
-	if len(m.Nodes) != 1 || m.Nodes[0].Type != NodeAgentWindow {
+	if len(m.Nodes) != 2 || m.Nodes[1].Type != NodeAgentWindow || m.Cursor != 1 {
 		t.Fatalf("nodes changed unexpectedly: %+v", m.Nodes)
 	}
 
 	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
 	m = updated.(Model)
 	if cmd != nil {
-		t.Fatal("expected nil cmd for add in agents mode")
+		t.Fatal("expected nil cmd when opening the agent dialog")
+	}
+	if !m.AddDialog.Active || m.AddDialog.Kind != AddKindAgent || m.AddDialog.SessionName != "cb_demo" {
+		t.Fatalf("AddDialog = %+v, want agent dialog for cb_demo", m.AddDialog)
+	}
+	if got := m.AddDialog.selectedAgent(); got != tmux.AgentCodex {
+		t.Fatalf("selectedAgent() = %q, want row's agent %q", got, tmux.AgentCodex)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(Model)
+	if got := m.AddDialog.selectedAgent(); got != tmux.AgentOpenCode {
+		t.Fatalf("after tab selectedAgent() = %q, want %q", got, tmux.AgentOpenCode)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(Model)
+	if got := m.AddDialog.selectedAgent(); got != tmux.AgentClaude {
+		t.Fatalf("tab should wrap, selectedAgent() = %q, want %q", got, tmux.AgentClaude)
+	}
+	if view := m.View(); !strings.Contains(view, "Add Agent Window") || !strings.Contains(view, "agent: claude") {
+		t.Fatalf("view missing agent dialog:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updated.(Model)
+	if !m.AddDialog.Resume {
+		t.Fatal("ctrl+r should turn on resume")
+	}
+	if view := m.View(); !strings.Contains(view, "yes: claude --continue") {
+		t.Fatalf("view missing resume command:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Review")})
+	m = updated.(Model)
+	if view := m.View(); !strings.Contains(view, "name: Review") || !strings.Contains(view, "window: review") {
+		t.Fatalf("view missing the window named as typed:\n%s", view)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = updated.(Model)
+	if view := m.View(); !strings.Contains(view, "purpose: Review") || !strings.Contains(view, "window: claude-review") {
+		t.Fatalf("view missing the window named for the purpose:\n%s", view)
+	}
+}
+
+func TestReadOnlyBlocksAddKey(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:      "repo",
+			Expanded:  true,
+			Worktrees: []WorktreeGroup{{Name: "(main repo)", Path: "/tmp/repo", IsMainRepo: true}},
+		}},
+		Styles:   NewStyles(KanagawaClaw),
+		ReadOnly: true,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = updated.(Model)
+	if cmd != nil || m.AddDialog.Active {
+		t.Fatalf("add dialog opened in read-only mode: %+v", m.AddDialog)
+	}
+	if m.StatusMsg != "Read-only mode" {
+		t.Fatalf("StatusMsg = %q, want read-only notice", m.StatusMsg)
 	}
-	if m.StatusMsg != "" {
-		t.Fatalf("StatusMsg should remain empty, got %q", m.StatusMsg)
+}
+
+func TestAdoptKeyOnAgentRows(t *testing.T) {
+	tests := []struct {
+		name       string
+		managed    bool
+		readOnly   bool
+		wantStatus string
+	}{
+		{name: "managed session", managed: true, wantStatus: "cb_demo is already managed"},
+		{name: "read-only", readOnly: true, wantStatus: "Read-only mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{
+				Mode: DashboardModeAgents,
+				AgentRows: []AgentWindowRow{{
+					SessionName: "cb_demo",
+					WindowName:  "claude",
+					AgentType:   tmux.AgentClaude,
+					Managed:     tt.managed,
+				}},
+				Styles:   NewStyles(KanagawaClaw),
+				ReadOnly: tt.readOnly,
+			}
+			m.Nodes = BuildAgentNodes(m.AgentRows, nil)
+			m.Cursor = 1
+
+			updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+			m = updated.(Model)
+			if cmd != nil {
+				t.Fatal("expected nil cmd")
+			}
+			if m.StatusMsg != tt.wantStatus {
+				t.Fatalf("StatusMsg = %q, want %q", m.StatusMsg, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// fakeAdopter is the tmux surface the adopt key uses; other Multiplexer
+// methods are unused.
+type fakeAdopter struct {
+	multiplexer.Multiplexer
+	sessions map[string]bool
+	dir      string
+	options  map[string]string
+}
+
+func (f *fakeAdopter) HasSession(name string) bool { return f.sessions[name] }
+
+func (f *fakeAdopter) GetPaneWorkingDir(session string) string { return "" }
+
+func (f *fakeAdopter) GetWindowWorkingDir(session string, windowIndex int) string { return f.dir }
+
+func (f *fakeAdopter) RenameSession(oldName, newName string) error {
+	delete(f.sessions, oldName)
+	f.sessions[newName] = true
+	return nil
+}
+
+func (f *fakeAdopter) SetSessionOption(session, key, value string) error {
+	f.options[session+"/"+key] = value
+	return nil
+}
+
+func TestAdoptKeyAdoptsUnmanagedSession(t *testing.T) {
+	dir, err := config.CanonicalPath(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeAdopter{sessions: map[string]bool{"scratch": true}, dir: dir, options: map[string]string{}}
+	m := Model{
+		Mode:       DashboardModeAgents,
+		TmuxClient: client,
+		AgentRows:  []AgentWindowRow{{SessionName: "scratch", WindowName: "claude", AgentType: tmux.AgentClaude}},
+		Styles:     NewStyles(KanagawaClaw),
+	}
+	m.Nodes = BuildAgentNodes(m.AgentRows, nil)
+	m.Cursor = 1
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	if cmd == nil {
+		t.Fatal("A on an unmanaged session should adopt it")
+	}
+	msg, ok := cmd().(adoptResultMsg)
+	if !ok || msg.Err != nil || msg.Managed != "cb_scratch" {
+		t.Fatalf("adopt result = %+v, want cb_scratch", msg)
+	}
+	if got := client.options["cb_scratch/"+tmux.SessionOptionHomePath]; got != dir {
+		t.Fatalf("home path option = %q, want %q", got, dir)
 	}
 }
 
@@ -655,28 +1375,14 @@ func TestEnsureSessionPrefix(t *testing.T) {
 	}
 }
 
-func TestUniquifyName(t *testing.T) {
-	tests := []struct {
-		name     string
-		base     string
-		existing map[string]struct{}
-		want     string
-	}{
-		{name: "unused base", base: "demo", existing: map[string]struct{}{}, want: "demo"},
-		{name: "first suffix", base: "demo", existing: map[string]struct{}{"demo": {}}, want: "demo-2"},
-		{name: "next suffix", base: "demo", existing: map[string]struct{}{"demo": {}, "demo-2": {}}, want: "demo-3"},
+func TestFetchDashboardData_NilTmuxClient(t *testing.T) {
+	var client *tmux.Client
+	if scoped := withContext(client, context.Background()); scoped != nil {
+		t.Fatalf("withContext(nil client) = %#v, want a nil interface", scoped)
 	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := uniquifyName(tt.base, func(name string) bool {
-				_, ok := tt.existing[name]
-				return ok
-			})
-			if got != tt.want {
-				t.Fatalf("uniquifyName(%q) = %q, want %q", tt.base, got, tt.want)
-			}
-		})
+	_, rows, statuses, _, _, err := fetchDashboardData(context.Background(), nil, client, DashboardModeAgents)
+	if err != nil || rows != nil || len(statuses) != 0 {
+		t.Fatalf("fetchDashboardData() = %v, %v, %v; want no rows", rows, statuses, err)
 	}
 }
 
@@ -700,7 +1406,7 @@ func TestFetchGroups_MapsSessionFields(t *testing.T) {
 		},
 	}
 
-	groups, _, _, _, err := fetchGroups(discoverer)
+	groups, _, _, _, err := fetchGroups(context.Background(), discoverer)
 	if err != nil {
 		t.Fatalf("fetchGroups() error = %v", err)
 	}
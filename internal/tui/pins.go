@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ronsanzone/clawd-bay/internal/config"
+)
+
+// Pins are the sessions and projects kept at the top of both modes,
+// loaded from the pinned_sessions and [[projects]] pinned config keys.
+// Projects is keyed by canonical project path.
+type Pins struct {
+	Sessions map[string]bool
+	Projects map[string]bool
+}
+
+// pinsFromConfig collects the pins configured in cfg.
+func pinsFromConfig(cfg config.UserConfig) Pins {
+	pins := Pins{Sessions: map[string]bool{}, Projects: map[string]bool{}}
+	for _, name := range cfg.PinnedSessions {
+		pins.Sessions[name] = true
+	}
+	for _, p := range cfg.Projects {
+		if p.Pinned {
+			pins.Projects[p.Path] = true
+		}
+	}
+	return pins
+}
+
+// projectNamed reports whether a pinned project's directory is named name,
+// which is how agents-mode rows identify their project.
+func (p Pins) projectNamed(name string) bool {
+	for path := range p.Projects {
+		if filepath.Base(path) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pinGroups moves pinned projects to the top, and within each project the
+// worktrees holding pinned sessions, and within each worktree the pinned
+// sessions. Everything else keeps its order.
+func pinGroups(groups []RepoGroup, pins Pins) {
+	for gi := range groups {
+		worktrees := groups[gi].Worktrees
+		for wi := range worktrees {
+			sessions := worktrees[wi].Sessions
+			sort.SliceStable(sessions, func(i, j int) bool {
+				return pins.Sessions[sessions[i].Name] && !pins.Sessions[sessions[j].Name]
+			})
+		}
+		hasPinned := func(wt WorktreeGroup) bool {
+			return len(wt.Sessions) > 0 && pins.Sessions[wt.Sessions[0].Name]
+		}
+		sort.SliceStable(worktrees, func(i, j int) bool {
+			return hasPinned(worktrees[i]) && !hasPinned(worktrees[j])
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return pins.Projects[groups[i].Path] && !pins.Projects[groups[j].Path]
+	})
+}
+
+// pinAgentRows moves repo groups that are pinned or hold a pinned session to
+// the top and, within each group, pinned sessions' rows first. Rows must
+// already be grouped (see sortAgentRows); groups stay contiguous.
+func pinAgentRows(rows []AgentWindowRow, pins Pins) {
+	groupOrder := make(map[string]int)
+	groupPinned := make(map[string]bool)
+	for _, row := range rows {
+		group := row.Group()
+		if _, ok := groupOrder[group]; !ok {
+			groupOrder[group] = len(groupOrder)
+		}
+		if pins.Sessions[row.SessionName] || pins.projectNamed(group) {
+			groupPinned[group] = true
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		ga, gb := a.Group(), b.Group()
+		if groupPinned[ga] != groupPinned[gb] {
+			return groupPinned[ga]
+		}
+		if ga != gb {
+			return groupOrder[ga] < groupOrder[gb]
+		}
+		return pins.Sessions[a.SessionName] && !pins.Sessions[b.SessionName]
+	})
+}
+
+// pinResultMsg is sent after toggling a pin in the config file.
+type pinResultMsg struct {
+	Name   string
+	Pinned bool
+	Err    error
+}
+
+// togglePinForNode pins or unpins the session or project under the cursor:
+// the project for repo nodes and headers, otherwise the node's session.
+func (m Model) togglePinForNode(node TreeNode) (Model, tea.Cmd) {
+	var session, projectPath, name string
+	switch node.Type {
+	case NodeRepo:
+		projectPath, name = m.Groups[node.RepoIndex].Path, m.Groups[node.RepoIndex].Name
+	case NodeSession, NodeWindow:
+		session = m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex].Sessions[node.SessionIndex].Name
+	case NodeAgentWindow:
+		session = m.AgentRows[node.AgentIndex].SessionName
+	case NodeAgentRepo:
+		name = m.AgentRows[node.AgentIndex].Group()
+	default:
+		m.StatusMsg = "Select a repo or session to pin"
+		return m, nil
+	}
+	if session != "" {
+		name = session
+	}
+
+	return m, func() tea.Msg {
+		var pinned bool
+		err := config.UpdateUserConfig(func(cfg *config.UserConfig) error {
+			var err error
+			switch {
+			case session != "":
+				pinned = cfg.TogglePinnedSession(session)
+			case projectPath != "":
+				pinned, err = cfg.TogglePinnedProject(projectPath)
+			default:
+				pinned, err = togglePinnedProjectNamed(cfg, name)
+			}
+			return err
+		})
+		return pinResultMsg{Name: name, Pinned: pinned, Err: err}
+	}
+}
+
+// togglePinnedProjectNamed toggles the pin of the one configured project
+// whose directory is named name.
+func togglePinnedProjectNamed(cfg *config.UserConfig, name string) (bool, error) {
+	var paths []string
+	for _, p := range cfg.Projects {
+		if filepath.Base(p.Path) == name {
+			paths = append(paths, p.Path)
+		}
+	}
+	switch len(paths) {
+	case 0:
+		return false, fmt.Errorf("%s is not a configured project (see cb project add)", name)
+	case 1:
+		return cfg.TogglePinnedProject(paths[0])
+	default:
+		return false, fmt.Errorf("several configured projects are named %s; pin it in worktree mode", name)
+	}
+}
@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ronsanzone/clawd-bay/internal/config"
+)
+
+func TestPinGroups(t *testing.T) {
+	groups := []RepoGroup{
+		{Name: "alpha", Path: "/code/alpha", Worktrees: []WorktreeGroup{
+			{Name: "(main repo)", Sessions: []WorktreeSession{{Name: "cb_a1"}}},
+			{Name: "alpha-feat", Sessions: []WorktreeSession{{Name: "cb_a2"}, {Name: "cb_focus"}}},
+		}},
+		{Name: "beta", Path: "/code/beta"},
+	}
+	pins := Pins{Sessions: map[string]bool{"cb_focus": true}, Projects: map[string]bool{"/code/beta": true}}
+
+	pinGroups(groups, pins)
+
+	if groups[0].Name != "beta" || groups[1].Name != "alpha" {
+		t.Fatalf("group order = %s, %s; want pinned beta first", groups[0].Name, groups[1].Name)
+	}
+	alpha := groups[1]
+	if alpha.Worktrees[0].Name != "alpha-feat" {
+		t.Fatalf("first worktree = %s, want alpha-feat (holds a pinned session)", alpha.Worktrees[0].Name)
+	}
+	if got := alpha.Worktrees[0].Sessions[0].Name; got != "cb_focus" {
+		t.Fatalf("first session = %s, want cb_focus", got)
+	}
+}
+
+func TestPinAgentRows(t *testing.T) {
+	rows := []AgentWindowRow{
+		{SessionName: "cb_a1", Project: "alpha"},
+		{SessionName: "cb_a2", Project: "alpha"},
+		{SessionName: "cb_b1", Project: "beta"},
+		{SessionName: "cb_b2", Project: "beta"},
+		{SessionName: "cb_c1", Project: "gamma"},
+	}
+	pins := Pins{Sessions: map[string]bool{"cb_b2": true}, Projects: map[string]bool{"/code/gamma": true}}
+
+	pinAgentRows(rows, pins)
+
+	var got []string
+	for _, r := range rows {
+		got = append(got, r.SessionName)
+	}
+	if want := "cb_b2 cb_b1 cb_c1 cb_a1 cb_a2"; strings.Join(got, " ") != want {
+		t.Fatalf("row order = %v, want %s", got, want)
+	}
+}
+
+func TestPinKeyTogglesConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	m := Model{
+		Mode:      DashboardModeAgents,
+		AgentRows: []AgentWindowRow{{SessionName: "cb_focus", WindowName: "claude", Project: "alpha"}},
+		Styles:    NewStyles(KanagawaClaw),
+	}
+	m.Nodes = BuildAgentNodes(m.AgentRows, nil)
+	m.Cursor = 1
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if cmd == nil {
+		t.Fatal("expected a pin cmd")
+	}
+	msg, ok := cmd().(pinResultMsg)
+	if !ok || msg.Err != nil || !msg.Pinned || msg.Name != "cb_focus" {
+		t.Fatalf("pin result = %+v, want cb_focus pinned", msg)
+	}
+	cfg, err := config.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("LoadUserConfig() error = %v", err)
+	}
+	if !cfg.PinsSession("cb_focus") {
+		t.Fatalf("config pinned_sessions = %v, want cb_focus", cfg.PinnedSessions)
+	}
+
+	m = updated.(Model)
+	m.Cursor = 0 // the alpha header, which is not a configured project
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if msg := cmd().(pinResultMsg); msg.Err == nil {
+		t.Fatalf("pinning an unconfigured project = %+v, want error", msg)
+	}
+
+	m.ReadOnly = true
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	if cmd != nil || updated.(Model).StatusMsg != "Read-only mode" {
+		t.Fatal("p should be blocked in read-only mode")
+	}
+}
+
+func TestTogglePinnedProjectNamed(t *testing.T) {
+	home := t.TempDir()
+	repo := filepath.Join(home, "alpha")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	cfg := config.UserConfig{Projects: []config.ProjectConfig{{Path: repo}, {Path: "/other/alpha"}}}
+
+	if _, err := togglePinnedProjectNamed(&cfg, "alpha"); err == nil {
+		t.Fatal("ambiguous name should fail")
+	}
+	cfg.Projects = cfg.Projects[:1]
+	if pinned, err := togglePinnedProjectNamed(&cfg, "alpha"); err != nil || !pinned || !cfg.Projects[0].Pinned {
+		t.Fatalf("togglePinnedProjectNamed() = %v, %v; want pinned", pinned, err)
+	}
+}
@@ -1,6 +1,11 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 // Theme defines all colors for the TUI.
 type Theme struct {
@@ -21,6 +26,8 @@ type Theme struct {
 	Waiting lipgloss.Color
 	Idle    lipgloss.Color
 	Done    lipgloss.Color
+	Error   lipgloss.Color
+	Limited lipgloss.Color
 }
 
 // KanagawaClaw is the default theme inspired by Kanagawa.nvim.
@@ -42,6 +49,58 @@ var KanagawaClaw = Theme{
 	Waiting: lipgloss.Color("#FFA066"),
 	Idle:    lipgloss.Color("#7FB4CA"),
 	Done:    lipgloss.Color("#54546D"),
+	Error:   lipgloss.Color("#FF5D62"),
+	Limited: lipgloss.Color("#E6C384"),
+}
+
+// KanagawaLotus is a light theme for light terminal backgrounds, based on
+// Kanagawa.nvim's lotus palette.
+var KanagawaLotus = Theme{
+	Bg:      lipgloss.Color("#F2ECBC"),
+	BgDark:  lipgloss.Color("#E5DDB0"),
+	BgLight: lipgloss.Color("#E7DBA0"),
+	Border:  lipgloss.Color("#C7BE98"),
+
+	Fg:      lipgloss.Color("#545464"),
+	FgDim:   lipgloss.Color("#43436C"),
+	FgMuted: lipgloss.Color("#8A8980"),
+
+	Accent:    lipgloss.Color("#624C83"),
+	Highlight: lipgloss.Color("#B35B79"),
+	Info:      lipgloss.Color("#4D699B"),
+
+	Working: lipgloss.Color("#6F894E"),
+	Waiting: lipgloss.Color("#CC6D00"),
+	Idle:    lipgloss.Color("#4E8CA2"),
+	Done:    lipgloss.Color("#A09CAC"),
+	Error:   lipgloss.Color("#C84053"),
+	Limited: lipgloss.Color("#77713F"),
+}
+
+// Theme names accepted by ResolveTheme.
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+	ThemeAuto  = "auto"
+)
+
+// ResolveTheme returns the theme called name: "dark" (or "kanagawa-claw"),
+// "light" (or "kanagawa-lotus"), or "auto", which picks between them by
+// asking hasDarkBackground. An empty name is the dark default.
+func ResolveTheme(name string, hasDarkBackground func() bool) (Theme, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", ThemeDark, "kanagawa-claw":
+		return KanagawaClaw, nil
+	case ThemeLight, "kanagawa-lotus":
+		return KanagawaLotus, nil
+	case ThemeAuto:
+		if hasDarkBackground() {
+			return KanagawaClaw, nil
+		}
+		return KanagawaLotus, nil
+	default:
+		return Theme{}, fmt.Errorf("unknown theme %q (want dark, light, or auto)", name)
+	}
 }
 
 // Styles holds all pre-built lipgloss styles derived from a Theme.
@@ -61,6 +120,11 @@ type Styles struct {
 	StatusWaiting lipgloss.Style
 	StatusIdle    lipgloss.Style
 	StatusDone    lipgloss.Style
+	StatusError   lipgloss.Style
+	StatusLimited lipgloss.Style
+
+	// Session tag chips
+	Tag lipgloss.Style
 
 	// UI chrome
 	Footer    lipgloss.Style
@@ -105,6 +169,16 @@ func NewStyles(t Theme) Styles {
 		StatusDone: lipgloss.NewStyle().
 			Foreground(t.Done),
 
+		StatusError: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(t.Error),
+
+		StatusLimited: lipgloss.NewStyle().
+			Foreground(t.Limited),
+
+		Tag: lipgloss.NewStyle().
+			Foreground(t.Idle),
+
 		Footer: lipgloss.NewStyle().
 			Foreground(t.FgMuted),
 
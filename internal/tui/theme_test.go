@@ -49,3 +49,32 @@ func TestBuildStylesFromTheme(t *testing.T) {
 		t.Error("StatusWorking style renders empty")
 	}
 }
+
+func TestResolveTheme(t *testing.T) {
+	dark := func() bool { return true }
+	light := func() bool { return false }
+
+	tests := []struct {
+		name    string
+		hasDark func() bool
+		want    Theme
+		wantErr bool
+	}{
+		{name: "", hasDark: light, want: KanagawaClaw},
+		{name: "dark", hasDark: light, want: KanagawaClaw},
+		{name: "Light", hasDark: dark, want: KanagawaLotus},
+		{name: "kanagawa-lotus", hasDark: dark, want: KanagawaLotus},
+		{name: "auto", hasDark: dark, want: KanagawaClaw},
+		{name: "auto", hasDark: light, want: KanagawaLotus},
+		{name: "solarized", hasDark: dark, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveTheme(tt.name, tt.hasDark)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ResolveTheme(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ResolveTheme(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ronsanzone/clawd-bay/internal/multiplexer"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// runawayCPU is the CPU percentage at which a top row is highlighted.
+const runawayCPU = 80.0
+
+// TopSort selects the column cb top orders rows by, descending.
+type TopSort string
+
+const (
+	TopSortCPU    TopSort = "cpu"
+	TopSortMemory TopSort = "mem"
+	TopSortUptime TopSort = "uptime"
+)
+
+// ParseTopSort validates a --sort value.
+func ParseTopSort(raw string) (TopSort, error) {
+	switch TopSort(strings.ToLower(strings.TrimSpace(raw))) {
+	case TopSortCPU:
+		return TopSortCPU, nil
+	case TopSortMemory, "memory":
+		return TopSortMemory, nil
+	case TopSortUptime:
+		return TopSortUptime, nil
+	default:
+		return "", fmt.Errorf("invalid sort %q (want cpu, mem, or uptime)", raw)
+	}
+}
+
+// TopRow is one detected agent window with its process metrics. HasProcess
+// is false when the agent's process could not be read from ps; HasCPU is
+// false until a CPUSampler has seen the process twice.
+type TopRow struct {
+	Agent      AgentWindowRow
+	Process    tmux.AgentProcess
+	HasProcess bool
+	HasCPU     bool
+}
+
+// Runaway reports whether the row's CPU usage warrants attention.
+func (r TopRow) Runaway() bool {
+	return r.HasCPU && r.Process.CPU >= runawayCPU
+}
+
+type processStatsReader interface {
+	AgentProcessStats(target string) (tmux.AgentProcess, bool)
+}
+
+// FetchTopRows returns every detected agent window outside ignored sessions
+// with its process metrics. CPU usage is measured by sampler against its
+// previous sample, so only rows it has seen before have HasCPU set.
+func FetchTopRows(tmuxClient multiplexer.Multiplexer, sampler *CPUSampler) []TopRow {
+	rows, _, _ := fetchAgentRowsData(tmuxClient)
+	top := buildTopRows(rows, tmuxClient)
+	sampler.Sample(top, clock())
+	return top
+}
+
+func buildTopRows(rows []AgentWindowRow, reader processStatsReader) []TopRow {
+	top := make([]TopRow, 0, len(rows))
+	for _, row := range rows {
+		proc, ok := reader.AgentProcessStats(row.Target())
+		top = append(top, TopRow{Agent: row, Process: proc, HasProcess: ok})
+	}
+	return top
+}
+
+// CPUSampler derives current CPU usage from the cumulative CPU time of agent
+// processes: the CPU time used since the previous sample over the wall time
+// between them. ps's pcpu would be simpler, but it averages over the whole
+// process lifetime, so an agent that just went idle after an hour of work
+// would still read high.
+type CPUSampler struct {
+	prev map[cpuSampleKey]cpuSample
+}
+
+type cpuSampleKey struct {
+	target string
+	pid    int
+}
+
+type cpuSample struct {
+	cpuTime time.Duration
+	at      time.Time
+}
+
+// NewCPUSampler creates a sampler with no previous samples.
+func NewCPUSampler() *CPUSampler {
+	return &CPUSampler{prev: map[cpuSampleKey]cpuSample{}}
+}
+
+// Sample sets CPU and HasCPU on the rows whose process was sampled before,
+// then records rows as the previous sample. A process whose CPU time went
+// down (a helper exited) gets no reading until the next sample.
+func (s *CPUSampler) Sample(rows []TopRow, now time.Time) {
+	next := make(map[cpuSampleKey]cpuSample, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		if !row.HasProcess {
+			continue
+		}
+		key := cpuSampleKey{target: row.Agent.Target(), pid: row.Process.PID}
+		if prev, ok := s.prev[key]; ok {
+			wall, used := now.Sub(prev.at), row.Process.CPUTime-prev.cpuTime
+			if wall > 0 && used >= 0 {
+				row.Process.CPU = float64(used) / float64(wall) * 100
+				row.HasCPU = true
+			}
+		}
+		next[key] = cpuSample{cpuTime: row.Process.CPUTime, at: now}
+	}
+	s.prev = next
+}
+
+// SortTopRows orders rows by key, highest first. Rows without process
+// metrics sort last; ties keep session/window order.
+func SortTopRows(rows []TopRow, key TopSort) {
+	value := func(r TopRow) float64 {
+		switch key {
+		case TopSortMemory:
+			return float64(r.Process.RSSKB)
+		case TopSortUptime:
+			return float64(r.Process.Elapsed)
+		default:
+			if !r.HasCPU {
+				return -1
+			}
+			return r.Process.CPU
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.HasProcess != b.HasProcess {
+			return a.HasProcess
+		}
+		if va, vb := value(a), value(b); va != vb {
+			return va > vb
+		}
+		if a.Agent.SessionName != b.Agent.SessionName {
+			return a.Agent.SessionName < b.Agent.SessionName
+		}
+		return a.Agent.WindowIndex < b.Agent.WindowIndex
+	})
+}
+
+// TopHeader is the column header matching FormatTopRow.
+func TopHeader() string {
+	return fmt.Sprintf("%-28s %-8s %-8s %7s %6s %8s %8s", "SESSION:WINDOW", "AGENT", "STATUS", "PID", "CPU%", "MEM", "UPTIME")
+}
+
+// FormatTopRow renders row as plain, fixed-width columns.
+func FormatTopRow(row TopRow) string {
+	name := fmt.Sprintf("%s:%s", row.Agent.SessionName, row.Agent.WindowName)
+	if runes := []rune(name); len(runes) > 28 {
+		name = string(runes[:27]) + "…"
+	}
+	pid, cpu, mem, uptime := "-", "-", "-", "-"
+	if row.HasProcess {
+		pid = fmt.Sprintf("%d", row.Process.PID)
+		mem = FormatMemory(row.Process.RSSKB)
+		uptime = FormatUptime(row.Process.Elapsed)
+	}
+	if row.HasCPU {
+		cpu = fmt.Sprintf("%.1f", row.Process.CPU)
+	}
+	return fmt.Sprintf("%-28s %-8s %-8s %7s %6s %8s %8s", name, row.Agent.AgentType, row.Agent.Status, pid, cpu, mem, uptime)
+}
+
+// FormatMemory renders a size in KiB (a resident set, or disk usage) as a
+// short human size.
+func FormatMemory(kb int64) string {
+	switch {
+	case kb >= 1024*1024:
+		return fmt.Sprintf("%.1fG", float64(kb)/(1024*1024))
+	case kb >= 1024:
+		return fmt.Sprintf("%.1fM", float64(kb)/1024)
+	default:
+		return fmt.Sprintf("%dK", kb)
+	}
+}
+
+// FormatUptime renders d with its two most significant units.
+func FormatUptime(d time.Duration) string {
+	d = d.Round(time.Second)
+	days := int(d / (24 * time.Hour))
+	hours := int(d/time.Hour) % 24
+	minutes := int(d/time.Minute) % 60
+	seconds := int(d/time.Second) % 60
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd%02dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh%02dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm%02ds", minutes, seconds)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+// topRefreshMsg carries new rows for the top view.
+type topRefreshMsg []TopRow
+
+// TopModel is the cb top view: a live table of agent process metrics.
+type TopModel struct {
+	Rows     []TopRow
+	Sort     TopSort
+	Interval time.Duration
+	Width    int
+	Height   int
+	Quitting bool
+	Styles   Styles
+	// Plain marks runaway rows with a word instead of a color, as
+	// Model.Plain does for statuses.
+	Plain bool
+
+	fetch func() []TopRow
+}
+
+// NewTopModel creates a top view that refreshes rows from fetch every
+// interval.
+func NewTopModel(fetch func() []TopRow, sortKey TopSort, interval time.Duration) TopModel {
+	return TopModel{
+		Sort:     sortKey,
+		Interval: interval,
+		Styles:   NewStyles(KanagawaClaw),
+		fetch:    fetch,
+	}
+}
+
+// Init implements tea.Model.
+func (m TopModel) Init() tea.Cmd {
+	return m.refreshCmd()
+}
+
+func (m TopModel) refreshCmd() tea.Cmd {
+	fetch := m.fetch
+	return func() tea.Msg {
+		return topRefreshMsg(fetch())
+	}
+}
+
+func (m TopModel) tickCmd() tea.Cmd {
+	return tea.Tick(m.Interval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Update implements tea.Model.
+func (m TopModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case topRefreshMsg:
+		m.Rows = []TopRow(msg)
+		SortTopRows(m.Rows, m.Sort)
+		return m, m.tickCmd()
+
+	case tickMsg:
+		return m, m.refreshCmd()
+
+	case tea.WindowSizeMsg:
+		m.Width = msg.Width
+		m.Height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			m.Quitting = true
+			return m, tea.Quit
+		case "c":
+			m.Sort = TopSortCPU
+		case "m":
+			m.Sort = TopSortMemory
+		case "u":
+			m.Sort = TopSortUptime
+		}
+		SortTopRows(m.Rows, m.Sort)
+		return m, nil
+	}
+	return m, nil
+}
+
+// View implements tea.Model.
+func (m TopModel) View() string {
+	if m.Quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(m.Styles.Title.Render(fmt.Sprintf("ClawdBay · top · sorted by %s", m.Sort)))
+	b.WriteString("\n\n")
+	b.WriteString(m.Styles.Session.Render(TopHeader()))
+	b.WriteString("\n")
+
+	if len(m.Rows) == 0 {
+		b.WriteString(m.Styles.Window.Render("No detected agent windows."))
+		b.WriteString("\n")
+	}
+	rows := m.Rows
+	if m.Height > 0 {
+		// The title, header, footer, and their spacing take five lines.
+		rows = rows[:min(len(rows), max(m.Height-5, 1))]
+	}
+	for _, row := range rows {
+		line := FormatTopRow(row)
+		if row.Runaway() {
+			if m.Plain {
+				line += "  [runaway]"
+			} else {
+				line = m.Styles.StatusWaiting.Bold(true).Render(line)
+			}
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(m.Styles.Footer.Render("c cpu · m mem · u uptime · q quit"))
+	return b.String()
+}
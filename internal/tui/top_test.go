@@ -0,0 +1,212 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+type fakeProcessStats map[string]tmux.AgentProcess
+
+func (f fakeProcessStats) AgentProcessStats(target string) (tmux.AgentProcess, bool) {
+	proc, ok := f[target]
+	return proc, ok
+}
+
+func topRow(session string, index int, cpu float64, rssKB int64, elapsed time.Duration) TopRow {
+	return TopRow{
+		Agent:      AgentWindowRow{SessionName: session, WindowName: "claude", WindowIndex: index, AgentType: tmux.AgentClaude, Status: tmux.StatusWorking},
+		Process:    tmux.AgentProcess{PID: 100 + index, CPU: cpu, RSSKB: rssKB, Elapsed: elapsed},
+		HasProcess: true,
+		HasCPU:     true,
+	}
+}
+
+func topSessions(rows []TopRow) []string {
+	names := make([]string, 0, len(rows))
+	for _, r := range rows {
+		names = append(names, r.Agent.SessionName)
+	}
+	return names
+}
+
+func TestBuildTopRows(t *testing.T) {
+	rows := []AgentWindowRow{
+		{SessionName: "cb_a", WindowID: "@1"},
+		{SessionName: "cb_b", WindowID: "@2"},
+	}
+	got := buildTopRows(rows, fakeProcessStats{"@1": {PID: 42, CPU: 3}})
+
+	if len(got) != 2 {
+		t.Fatalf("buildTopRows() returned %d rows, want 2", len(got))
+	}
+	if !got[0].HasProcess || got[0].Process.PID != 42 {
+		t.Fatalf("row 0 = %+v, want process 42", got[0])
+	}
+	if got[1].HasProcess {
+		t.Fatalf("row 1 = %+v, want no process", got[1])
+	}
+}
+
+func TestCPUSampler(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	row := func(pid int, cpuTime time.Duration) TopRow {
+		return TopRow{
+			Agent:      AgentWindowRow{SessionName: "cb_a", WindowID: "@1"},
+			Process:    tmux.AgentProcess{PID: pid, CPUTime: cpuTime},
+			HasProcess: true,
+		}
+	}
+	sampler := NewCPUSampler()
+
+	first := []TopRow{row(42, time.Hour)}
+	sampler.Sample(first, start)
+	if first[0].HasCPU {
+		t.Fatalf("first sample = %+v, want no CPU reading", first[0])
+	}
+
+	// An hour of lifetime CPU time must not count; only the last 2s do.
+	second := []TopRow{row(42, time.Hour+500*time.Millisecond)}
+	sampler.Sample(second, start.Add(2*time.Second))
+	if !second[0].HasCPU || second[0].Process.CPU != 25 {
+		t.Fatalf("second sample = %+v, want 25%% CPU", second[0])
+	}
+
+	// A new process in the same window starts over.
+	restarted := []TopRow{row(43, time.Second)}
+	sampler.Sample(restarted, start.Add(4*time.Second))
+	if restarted[0].HasCPU {
+		t.Fatalf("restarted sample = %+v, want no CPU reading", restarted[0])
+	}
+}
+
+func TestSortTopRows(t *testing.T) {
+	base := func() []TopRow {
+		missing := topRow("cb_none", 0, 0, 0, 0)
+		missing.HasProcess = false
+		return []TopRow{
+			missing,
+			topRow("cb_small", 1, 5, 900_000, time.Hour),
+			topRow("cb_hot", 2, 95, 100_000, time.Minute),
+			topRow("cb_old", 3, 5, 50_000, 48*time.Hour),
+		}
+	}
+
+	tests := []struct {
+		key  TopSort
+		want string
+	}{
+		{key: TopSortCPU, want: "cb_hot,cb_old,cb_small,cb_none"},
+		{key: TopSortMemory, want: "cb_small,cb_hot,cb_old,cb_none"},
+		{key: TopSortUptime, want: "cb_old,cb_small,cb_hot,cb_none"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.key), func(t *testing.T) {
+			rows := base()
+			SortTopRows(rows, tt.key)
+			if got := strings.Join(topSessions(rows), ","); got != tt.want {
+				t.Fatalf("SortTopRows(%s) = %s, want %s", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTopSort(t *testing.T) {
+	for raw, want := range map[string]TopSort{"cpu": TopSortCPU, "MEM": TopSortMemory, "memory": TopSortMemory, "uptime": TopSortUptime} {
+		got, err := ParseTopSort(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseTopSort(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := ParseTopSort("pid"); err == nil {
+		t.Fatal("ParseTopSort(pid) succeeded, want error")
+	}
+}
+
+func TestFormatMemoryAndUptime(t *testing.T) {
+	memory := map[int64]string{512: "512K", 2048: "2.0M", 3 * 1024 * 1024: "3.0G"}
+	for kb, want := range memory {
+		if got := FormatMemory(kb); got != want {
+			t.Fatalf("FormatMemory(%d) = %q, want %q", kb, got, want)
+		}
+	}
+	uptime := map[time.Duration]string{
+		42 * time.Second:                               "42s",
+		3*time.Minute + 4*time.Second:                  "3m04s",
+		2*time.Hour + 5*time.Minute:                    "2h05m",
+		50*time.Hour + 10*time.Minute + 30*time.Second: "2d02h",
+	}
+	for d, want := range uptime {
+		if got := FormatUptime(d); got != want {
+			t.Fatalf("FormatUptime(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestFormatTopRow(t *testing.T) {
+	row := topRow("cb_feature", 1, 12.34, 204800, 90*time.Minute)
+	got := FormatTopRow(row)
+	for _, want := range []string{"cb_feature:claude", "claude", "WORKING", "101", "12.3", "200.0M", "1h30m"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("FormatTopRow() = %q, missing %q", got, want)
+		}
+	}
+
+	row.HasCPU = false
+	if got := FormatTopRow(row); strings.Contains(got, "12.3") || !strings.Contains(got, "101") {
+		t.Fatalf("FormatTopRow() before a CPU reading = %q, want PID without CPU", got)
+	}
+
+	row.HasProcess = false
+	if got := FormatTopRow(row); strings.Contains(got, "101") {
+		t.Fatalf("FormatTopRow() without process = %q, want placeholders", got)
+	}
+}
+
+func TestTopModelUpdate(t *testing.T) {
+	rows := []TopRow{topRow("cb_big", 0, 1, 900_000, time.Minute), topRow("cb_hot", 1, 90, 1_000, time.Minute)}
+	m := NewTopModel(func() []TopRow { return rows }, TopSortCPU, time.Second)
+
+	updated, cmd := m.Update(topRefreshMsg(rows))
+	m = updated.(TopModel)
+	if cmd == nil {
+		t.Fatal("refresh did not schedule the next tick")
+	}
+	if got := m.Rows[0].Agent.SessionName; got != "cb_hot" {
+		t.Fatalf("first row by cpu = %s, want cb_hot", got)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	m = updated.(TopModel)
+	if m.Sort != TopSortMemory || m.Rows[0].Agent.SessionName != "cb_big" {
+		t.Fatalf("after m: sort %s, first row %s; want mem, cb_big", m.Sort, m.Rows[0].Agent.SessionName)
+	}
+
+	if !strings.Contains(m.View(), "sorted by mem") {
+		t.Fatalf("View() = %q, want sort label", m.View())
+	}
+
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if !updated.(TopModel).Quitting || cmd == nil {
+		t.Fatal("q did not quit")
+	}
+}
+
+func TestTopModelViewPlainMarksRunaway(t *testing.T) {
+	m := NewTopModel(nil, TopSortCPU, time.Second)
+	m.Plain = true
+	m.Rows = []TopRow{topRow("cb_hot", 0, 95, 1_000, time.Minute), topRow("cb_calm", 1, 5, 1_000, time.Minute)}
+
+	for _, line := range strings.Split(m.View(), "\n") {
+		runaway := strings.HasSuffix(line, "  [runaway]")
+		switch {
+		case strings.Contains(line, "cb_hot") && !runaway:
+			t.Fatalf("runaway row %q, want it marked", line)
+		case strings.Contains(line, "cb_calm") && runaway:
+			t.Fatalf("calm row %q, want it unmarked", line)
+		}
+	}
+}
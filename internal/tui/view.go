@@ -2,19 +2,36 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/git"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 )
 
 const maxPanelWidth = 100
 
-// frameWidth returns the panel width, capped at maxPanelWidth.
+// frameWidth returns the panel width, capped at maxPanelWidth. Compact
+// panels use the full width.
 func (m Model) frameWidth() int {
+	if m.Compact {
+		return m.Width
+	}
 	return min(m.Width, maxPanelWidth)
 }
 
+// treeWidth is the width available to tree lines inside the frame.
+func (m Model) treeWidth() int {
+	if m.Compact {
+		return max(m.Width, 10)
+	}
+	return max(m.frameWidth()-2, 10)
+}
+
 func (m Model) modeLabel() DashboardMode {
 	if m.Mode == DashboardModeAgents {
 		return DashboardModeAgents
@@ -32,12 +49,13 @@ func (m Model) View() string {
 		return "Initializing..."
 	}
 
-	fw := m.frameWidth()
-	innerWidth := max(fw-2, 10)
+	if m.Compact {
+		return m.renderCompact()
+	}
 
-	tree := m.renderTree(innerWidth)
+	tree := m.renderTree(m.treeWidth())
 	statusBar := m.renderStatusBar()
-	footer := m.renderFooter()
+	footer := m.asciiText(m.renderFooter())
 
 	frame := m.renderFrame(tree, statusBar, footer)
 
@@ -45,6 +63,17 @@ func (m Model) View() string {
 	return lipgloss.Place(m.Width, m.Height, lipgloss.Center, lipgloss.Center, frame)
 }
 
+// renderCompact renders the dashboard without a frame: the tree, then the
+// status bar and footer on one line each. tmux display-popup already draws
+// a border around it.
+func (m Model) renderCompact() string {
+	width := m.treeWidth()
+	tree := m.renderTree(width)
+	statusBar := padToWidth(" "+m.renderStatusBar(), width)
+	footer := m.Styles.Footer.Render(fitAndPad(" "+m.asciiText(m.renderFooter()), width))
+	return tree + "\n" + statusBar + "\n" + footer
+}
+
 // renderTree renders the scrollable tree content.
 func (m Model) renderTree(width int) string {
 	nodes := m.nodesForView()
@@ -82,14 +111,18 @@ func (m Model) renderTree(width int) string {
 	}
 
 	if m.AddDialog.Active {
-		result = m.overlayAddDialog(result, width)
+		result = overlayPopup(result, m.renderAddDialogBox(width), width)
+	} else if m.ShowLegend {
+		result = overlayPopup(result, m.renderLegendBox(width), width)
+	} else if m.ShowDetail {
+		result = overlayPopup(result, m.renderDetailBox(width), width)
 	}
 
 	return strings.Join(result, "\n")
 }
 
-func (m Model) overlayAddDialog(lines []string, width int) []string {
-	popup := m.renderAddDialogBox(width)
+// overlayPopup draws popup centered over lines.
+func overlayPopup(lines, popup []string, width int) []string {
 	if len(popup) == 0 || len(lines) == 0 {
 		return lines
 	}
@@ -110,8 +143,11 @@ func (m Model) overlayAddDialog(lines []string, width int) []string {
 func (m Model) renderAddDialogBox(width int) []string {
 	title := "Add Session"
 	target := m.addDialogTarget()
-	if m.AddDialog.Kind == AddKindWindow {
+	switch m.AddDialog.Kind {
+	case AddKindWindow:
 		title = "Add Window"
+	case AddKindAgent:
+		title = "Add Agent Window"
 	}
 
 	dialogWidth := min(min(64, max(44, width-8)), width)
@@ -124,25 +160,186 @@ func (m Model) renderAddDialogBox(width int) []string {
 
 	inner := dialogWidth - 2
 	rows := []string{
-		fitAndPad(title, inner),
-		fitAndPad("target: "+target, inner),
-		fitAndPad("name: "+m.AddDialog.Input, inner),
-		fitAndPad("enter create  esc cancel", inner),
+		m.fitRow(title, inner),
+		m.fitRow("target: "+target, inner),
+	}
+	if m.AddDialog.Kind == AddKindAgent {
+		agent := m.AddDialog.selectedAgent()
+		resume := "no"
+		if m.AddDialog.Resume {
+			resume = "yes: " + agent.ResumeCommand()
+		}
+		label := "name: "
+		if m.AddDialog.Purpose {
+			label = "purpose: "
+		}
+		windowName, _ := m.AddDialog.agentWindowName(m.AgentWindowName)
+		rows = append(rows,
+			m.fitRow("agent: "+agent.LaunchCommand()+"  (tab to change)", inner),
+			m.fitRow("resume last conversation: "+resume+"  (ctrl+r)", inner),
+			m.fitRow(label+m.AddDialog.Input+"  (ctrl+p name/purpose)", inner),
+			m.fitRow("window: "+windowName, inner),
+			m.fitRow("enter create (optional, defaults to agent)  esc cancel", inner),
+		)
+	} else {
+		rows = append(rows,
+			m.fitRow("name: "+m.AddDialog.Input, inner),
+			m.fitRow("enter create  esc cancel", inner),
+		)
 	}
 	if m.AddDialog.Error != "" {
-		rows = append(rows, fitAndPad("error: "+m.AddDialog.Error, inner))
+		rows = append(rows, m.fitRow("error: "+m.AddDialog.Error, inner))
+	}
+
+	return m.boxRows(rows, inner)
+}
+
+// agentWindowPurpose splits the name of a window cb started an agent in for
+// recordedPurpose into the agent and the purpose. ok is false for windows
+// without a recorded purpose, whatever they are named, and for names not
+// following the agent_window_name scheme.
+func (m Model) agentWindowPurpose(name, recordedPurpose string) (agent, purpose string, ok bool) {
+	if recordedPurpose == "" {
+		return "", "", false
+	}
+	return m.agentWindowNames.Parse(name)
+}
+
+// renderLegendBox explains the status badges and agent tags.
+func (m Model) renderLegendBox(width int) []string {
+	dialogWidth := min(48, width)
+	if dialogWidth < 4 {
+		return nil
 	}
 
+	inner := dialogWidth - 2
+	rows := []string{
+		m.fitRow("Legend", inner),
+		m.fitRow(" "+m.renderStatusBadge(tmux.StatusWorking)+" WORKING  agent is busy", inner),
+		m.fitRow(" "+m.renderStatusBadge(tmux.StatusWaiting)+" WAITING  agent needs your input", inner),
+		m.fitRow(" "+m.renderStatusBadge(tmux.StatusIdle)+" IDLE     agent is at its prompt", inner),
+		m.fitRow(" "+m.renderStatusBadge(tmux.StatusError)+" ERROR    agent crashed or failed", inner),
+		m.fitRow(" "+m.renderStatusBadge(tmux.StatusLimited)+" LIMITED  agent hit a usage limit", inner),
+		m.fitRow(" "+m.renderStatusBadge(tmux.StatusDone)+" DONE     no agent running", inner),
+		m.fitRow(" "+m.renderShellMark()+" shell    window without an agent", inner),
+		m.fitRow(" "+m.renderUnknownMark()+" unknown  not checked yet or unreadable", inner),
+		m.fitRow(" "+m.renderAgentTag(tmux.AgentClaude)+" "+m.renderAgentTag(tmux.AgentCodex)+" "+m.renderAgentTag(tmux.AgentOpenCode)+"  agent type", inner),
+	}
+	if len(m.Workspaces) > 0 {
+		rows = append(rows, m.fitRow(" w        switch workspace", inner))
+	}
+	rows = append(rows, m.fitRow("? or esc close", inner))
+
+	return m.boxRows(rows, inner)
+}
+
+// renderDetailBox shows the session under the cursor, including its full
+// note.
+func (m Model) renderDetailBox(width int) []string {
+	session, worktree, ok := m.detailSession()
+	dialogWidth := min(64, width)
+	if !ok || dialogWidth < 4 {
+		return nil
+	}
+
+	inner := dialogWidth - 2
+	// The name and path wrap rather than truncate, since the tree may have
+	// shortened them.
+	var rows []string
+	for _, line := range wrapText(session.Name, inner) {
+		rows = append(rows, m.fitRow(line, inner))
+	}
+	rows = append(rows, m.fitRow(" "+m.renderStatusBadge(session.Status)+" "+string(session.Status)+fmt.Sprintf("  ·  %d window(s)", len(session.Windows)), inner))
+	worktreePath := worktree.Path
+	if worktree.IsMissing {
+		worktreePath = worktree.Name
+	}
+	for _, line := range wrapText("worktree: "+worktreePath, inner-1) {
+		rows = append(rows, m.fitRow(" "+line, inner))
+	}
+	if m.diskUsageMeasurable(worktree) {
+		disk := " disk: measuring..."
+		if entry, ok := m.DiskUsage.Get(worktree.Path); ok && entry.Err != nil {
+			disk = " disk: unavailable (" + entry.Err.Error() + ")"
+		} else if ok {
+			disk = " disk: " + FormatMemory(entry.KB)
+		}
+		rows = append(rows, m.fitRow(disk, inner))
+	}
+	rows = append(rows, m.fitRow(" active: "+FormatUptime(session.ActiveTime)+" working (see cb stats)", inner))
+	if session.Note == "" {
+		rows = append(rows, m.fitRow(" no note (add one with cb note)", inner))
+	} else {
+		rows = append(rows, m.fitRow(" note:", inner))
+		for _, line := range wrapText(session.Note, inner-4) {
+			rows = append(rows, m.fitRow("   "+line, inner))
+		}
+	}
+	rows = append(rows, m.fitRow("i or esc close", inner))
+
+	return m.boxRows(rows, inner)
+}
+
+// fitRow fits s to a popup row of width, rewriting its symbols for ASCII
+// mode first so the row is measured as it is drawn.
+func (m Model) fitRow(s string, width int) string {
+	return fitAndPad(m.asciiText(s), width)
+}
+
+// boxRows draws a border around rows, each already fitted to inner width
+// (see fitRow).
+func (m Model) boxRows(rows []string, inner int) []string {
+	border := m.glyphs().Border
 	popup := make([]string, 0, len(rows)+2)
-	popup = append(popup, "╭"+strings.Repeat("─", inner)+"╮")
+	popup = append(popup, border.TopLeft+strings.Repeat(border.Top, inner)+border.TopRight)
 	for _, row := range rows {
-		popup = append(popup, "│"+row+"│")
+		popup = append(popup, border.Left+row+border.Right)
 	}
-	popup = append(popup, "╰"+strings.Repeat("─", inner)+"╯")
-
+	popup = append(popup, border.BottomLeft+strings.Repeat(border.Bottom, inner)+border.BottomRight)
 	return popup
 }
 
+// wrapText breaks text into lines of at most width runes at spaces; words
+// longer than width are split.
+func wrapText(text string, width int) []string {
+	if width < 1 {
+		return []string{text}
+	}
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(text) {
+		w := []rune(word)
+		if len(line) > 0 && len(line)+1+len(w) > width {
+			lines = append(lines, string(line))
+			line = nil
+		}
+		for len(w) > width {
+			lines = append(lines, string(w[:width]))
+			w = w[width:]
+		}
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, w...)
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// noteExcerptLen bounds the note shown on a session row.
+const noteExcerptLen = 32
+
+// noteExcerpt returns note truncated to noteExcerptLen runes.
+func noteExcerpt(note string) string {
+	runes := []rune(strings.Join(strings.Fields(note), " "))
+	if len(runes) <= noteExcerptLen {
+		return string(runes)
+	}
+	return string(runes[:noteExcerptLen-1]) + "…"
+}
+
 func (m Model) addDialogTarget() string {
 	switch m.AddDialog.Kind {
 	case AddKindSession:
@@ -152,7 +349,7 @@ func (m Model) addDialogTarget() string {
 				return group.Worktrees[m.AddDialog.WorktreeIdx].Path
 			}
 		}
-	case AddKindWindow:
+	case AddKindWindow, AddKindAgent:
 		return m.AddDialog.SessionName
 	}
 	return ""
@@ -174,76 +371,124 @@ func (m Model) buildDisplayLines(nodes []TreeNode) []string {
 	return lines
 }
 
-// renderNodeLine renders one tree node.
+// renderNodeLine renders one tree node. The node's name is middle-truncated
+// so the line fits the tree width; the detail view shows it in full.
 func (m Model) renderNodeLine(node TreeNode, nodeIdx int) string {
 	selected := nodeIdx == m.cursorForView()
 	cursor := "  "
 	if selected {
-		cursor = "❯ "
+		cursor = m.glyphs().Cursor + " "
 	}
 
-	var line string
+	var prefix, name, suffix string
+	nameStyle := m.Styles.Window
+	collapsed, expanded := m.glyphs().Collapsed, m.glyphs().Expanded
 
 	switch node.Type {
 	case NodeRepo:
 		repo := m.Groups[node.RepoIndex]
-		icon := "▸"
+		icon := collapsed
 		if repo.Expanded {
-			icon = "▼"
+			icon = expanded
 		}
+		prefix, name, nameStyle = cursor+icon+" ", repo.Name, m.Styles.Repo
 		if repo.InvalidError != "" {
-			line = cursor + icon + " " + m.Styles.Repo.Render(repo.Name) + " " + m.Styles.StatusWaiting.Render("[INVALID]")
-		} else {
-			line = cursor + icon + " " + m.Styles.Repo.Render(repo.Name)
+			suffix = " " + m.Styles.StatusWaiting.Render("[INVALID]")
 		}
+		if n := len(repo.StaleWorktrees); n > 0 {
+			suffix += " " + m.Styles.StatusWaiting.Render(fmt.Sprintf("[%d STALE]", n))
+		}
+		suffix += m.renderPinMark(m.Pins.Projects[repo.Path])
 
 	case NodeWorktree:
 		worktree := m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex]
-		icon := "▸"
+		icon := collapsed
 		if worktree.Expanded {
-			icon = "▼"
+			icon = expanded
+		}
+		prefix, name, nameStyle = cursor+"  "+icon+" ", discovery.WorktreeLabel(worktree.Name, worktree.Branch), m.Styles.StatusDone
+		if worktree.IsMissing {
+			nameStyle = m.Styles.StatusError
+		}
+		if stat := formatDiffStat(worktree.DiffStat); stat != "" {
+			suffix += "  " + m.Styles.StatusBar.Render(m.asciiText(stat))
+		}
+		if badge := formatOverlapBadge(worktree.Overlaps); badge != "" {
+			suffix += "  " + m.Styles.StatusWaiting.Render(m.asciiText(badge))
 		}
-		line = cursor + "  " + icon + " " + m.Styles.StatusDone.Render(worktree.Name)
 
 	case NodeSession:
 		session := m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex].Sessions[node.SessionIndex]
-		icon := "▸"
+		icon := collapsed
 		if session.Expanded {
-			icon = "▼"
+			icon = expanded
 		}
 		badge := m.renderStatusBadge(session.Status)
-		line = cursor + "    " + icon + " " + badge + " " + m.Styles.Session.Render(session.Name)
+		prefix, name, nameStyle = cursor+"    "+icon+" "+badge+" ", session.Name, m.Styles.Session
+		suffix = m.renderPinMark(m.Pins.Sessions[session.Name]) + m.renderTagChips(session.Tags) +
+			m.renderLimitReset(session.Status, session.LimitReset) + m.renderWaitingReason(session.Status, session.WaitingReason)
+		if session.Note != "" {
+			suffix += "  " + m.Styles.StatusBar.Render(m.asciiText(m.glyphs().Note+" "+noteExcerpt(session.Note)))
+		}
 
 	case NodeWindow:
 		session := m.Groups[node.RepoIndex].Worktrees[node.WorktreeIndex].Sessions[node.SessionIndex]
 		window := session.Windows[node.WindowIndex]
-		key := session.Name + ":" + window.Name
-		badge := " "
-		if status, ok := m.WindowStatuses[key]; ok {
-			badge = m.renderStatusBadge(status)
-		}
+		key := window.Target(session.Name)
+		prefix = cursor + "      " + m.renderWindowBadge(window, key) + " "
 		tag := m.renderAgentTag(m.WindowAgentTypes[key])
 		if tag != "" {
-			line = cursor + "      " + badge + " " + tag + " " + m.Styles.Window.Render(window.Name)
-		} else {
-			line = cursor + "      " + badge + " " + m.Styles.Window.Render(window.Name)
+			prefix += tag + " "
 		}
+		name = window.Name
+		if agent, purpose, ok := m.agentWindowPurpose(window.Name, window.Purpose); ok {
+			name = purpose
+			if tag == "" {
+				// The agent exited; keep saying which one the window is for.
+				suffix = "  " + m.Styles.StatusBar.Render(agent)
+			}
+		}
+
+	case NodeAgentRepo:
+		rows := agentGroupRows(m.AgentRows, node.AgentIndex)
+		icon := expanded
+		if m.CollapsedAgentRepos[rows[0].Group()] {
+			icon = collapsed
+		}
+		statuses := make([]tmux.Status, 0, len(rows))
+		for _, row := range rows {
+			statuses = append(statuses, row.Status)
+		}
+		prefix = cursor + icon + " " + m.renderStatusBadge(tmux.RollupStatus(statuses)) + " "
+		name, nameStyle = rows[0].Group(), m.Styles.Repo
+		suffix = m.renderPinMark(m.Pins.projectNamed(rows[0].Group())) +
+			"  " + m.Styles.StatusBar.Render(fmt.Sprintf("(%d)", len(rows)))
 
 	case NodeAgentWindow:
 		row := m.AgentRows[node.AgentIndex]
-		target := fmt.Sprintf("%s:%d", row.SessionName, row.WindowIndex)
 		repo := row.RepoName
 		if repo == "" {
 			repo = "Unknown"
 		}
 		tag := m.renderAgentTag(row.AgentType)
 		badge := m.renderStatusBadge(row.Status)
-		line = cursor + badge + " " + tag + " " + m.Styles.Window.Render(row.WindowName) +
-			"  " + m.Styles.Session.Render(target) +
-			"  " + m.Styles.StatusBar.Render("repo="+repo)
+		windowName := row.WindowName
+		if _, purpose, ok := m.agentWindowPurpose(row.WindowName, row.WindowPurpose); ok {
+			windowName = purpose
+		}
+		prefix = cursor + "  " + badge + " " + tag + " " + m.Styles.Window.Render(windowName) + "  "
+		name, nameStyle = fmt.Sprintf("%s:%d", row.SessionName, row.WindowIndex), m.Styles.Session
+		suffix = "  " + m.Styles.StatusBar.Render("repo="+repo) + m.renderPinMark(m.Pins.Sessions[row.SessionName]) +
+			m.renderTagChips(row.Tags) + m.renderLimitReset(row.Status, row.LimitReset) +
+			m.renderWaitingReason(row.Status, row.WaitingReason)
 
 	default:
-		line = cursor + "Unknown"
+		prefix, name = cursor, "Unknown"
+	}
+
+	line := prefix + nameStyle.Render(m.fitName(prefix, name, suffix)) + suffix
+	if m.Width > 0 {
+		line = lipgloss.NewStyle().MaxWidth(m.treeWidth()).Render(line)
 	}
 
 	if selected {
@@ -253,6 +498,90 @@ func (m Model) renderNodeLine(node TreeNode, nodeIdx int) string {
 	return line
 }
 
+// minNameWidth is the narrowest a truncated node name gets; past that the
+// end of the line is cut instead.
+const minNameWidth = 12
+
+// fitName middle-truncates name so that prefix, name, and suffix fit the
+// tree width on one line.
+func (m Model) fitName(prefix, name, suffix string) string {
+	if m.Width == 0 {
+		return name
+	}
+	avail := m.treeWidth() - lipgloss.Width(prefix) - lipgloss.Width(suffix)
+	return truncateMiddle(name, max(avail, minNameWidth), m.glyphs().Ellipsis)
+}
+
+// truncateMiddle shortens s to at most width cells by replacing its middle
+// with ellipsis, keeping both ends (such as a worktree's project prefix and
+// branch suffix).
+func truncateMiddle(s string, width int, ellipsis string) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	keep := width - lipgloss.Width(ellipsis)
+	if keep <= 0 {
+		return ellipsis
+	}
+	runes := []rune(s)
+	i, j := 0, len(runes)
+	headW, tailW := 0, 0
+	for i < j {
+		if headW <= tailW {
+			w := lipgloss.Width(string(runes[i]))
+			if headW+tailW+w > keep {
+				break
+			}
+			headW += w
+			i++
+		} else {
+			w := lipgloss.Width(string(runes[j-1]))
+			if headW+tailW+w > keep {
+				break
+			}
+			tailW += w
+			j--
+		}
+	}
+	return string(runes[:i]) + ellipsis + string(runes[j:])
+}
+
+// formatDiffStat renders a worktree diff summary like "+123 −45 (8 files)".
+// Returns an empty string when there is nothing to show.
+func formatDiffStat(stat *git.DiffStat) string {
+	if stat == nil || stat.Files == 0 {
+		return ""
+	}
+	fileWord := "files"
+	if stat.Files == 1 {
+		fileWord = "file"
+	}
+	return fmt.Sprintf("+%d −%d (%d %s)", stat.Insertions, stat.Deletions, stat.Files, fileWord)
+}
+
+// formatOverlapBadge warns that other active worktrees touch the same files,
+// e.g. "⚠ overlaps repo-b (2 files)". Returns "" when there is no overlap.
+func formatOverlapBadge(overlaps []discovery.FileOverlap) string {
+	if len(overlaps) == 0 {
+		return ""
+	}
+	files := make(map[string]struct{})
+	for _, o := range overlaps {
+		for _, f := range o.Files {
+			files[f] = struct{}{}
+		}
+	}
+	fileWord := "files"
+	if len(files) == 1 {
+		fileWord = "file"
+	}
+	target := path.Base(overlaps[0].Worktree)
+	if len(overlaps) > 1 {
+		target = fmt.Sprintf("%d worktrees", len(overlaps))
+	}
+	return fmt.Sprintf("⚠ overlaps %s (%d %s)", target, len(files), fileWord)
+}
+
 func (m Model) renderAgentTag(agentType tmux.AgentType) string {
 	switch agentType {
 	case tmux.AgentClaude:
@@ -266,17 +595,106 @@ func (m Model) renderAgentTag(agentType tmux.AgentType) string {
 	}
 }
 
-// renderStatusBadge renders a colored status badge.
+// renderPinMark renders the marker shown after pinned sessions and repos.
+func (m Model) renderPinMark(pinned bool) string {
+	if !pinned {
+		return ""
+	}
+	return " " + m.Styles.Title.Render(m.glyphs().Pin)
+}
+
+// renderTagChips renders a session's tags as "#tag" chips.
+func (m Model) renderTagChips(tags []string) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		b.WriteString(" " + m.Styles.Tag.Render("#"+tag))
+	}
+	return b.String()
+}
+
+// renderStatusBadge renders a colored status badge, or in plain mode the
+// status word padded to a fixed width.
 func (m Model) renderStatusBadge(status tmux.Status) string {
+	if m.Plain {
+		if status == "" {
+			status = tmux.StatusDone
+		}
+		return fmt.Sprintf("%-9s", "["+strings.ToLower(string(status))+"]")
+	}
 	switch status {
 	case tmux.StatusWorking:
-		return m.Styles.StatusWorking.Render("•")
+		return m.Styles.StatusWorking.Render(m.glyphs().Working)
 	case tmux.StatusWaiting:
-		return m.Styles.StatusWaiting.Render("◐")
+		return m.Styles.StatusWaiting.Render(m.glyphs().Waiting)
 	case tmux.StatusIdle:
-		return m.Styles.StatusIdle.Render("◦")
+		return m.Styles.StatusIdle.Render(m.glyphs().Idle)
+	case tmux.StatusError:
+		return m.Styles.StatusError.Render(m.glyphs().Error)
+	case tmux.StatusLimited:
+		return m.Styles.StatusLimited.Render(m.glyphs().Limited)
+	case tmux.StatusUnknown:
+		return m.Styles.StatusBar.Render(m.glyphs().Unknown)
+	default:
+		return m.Styles.StatusDone.Render(m.glyphs().Done)
+	}
+}
+
+// renderWindowBadge renders a worktree-mode window's agent status. A window
+// checked without finding an agent gets the DONE badge when named after an
+// agent (its agent exited) and a shell mark otherwise; a window discovery has
+// no result for gets an unknown mark.
+func (m Model) renderWindowBadge(window tmux.Window, key string) string {
+	if status, ok := m.WindowStatuses[key]; ok {
+		return m.renderStatusBadge(status)
+	}
+	if _, checked := m.WindowAgentTypes[key]; !checked {
+		return m.renderUnknownMark()
+	}
+	if tmux.AgentForWindowName(window.Name) != tmux.AgentNone {
+		return m.renderStatusBadge(tmux.StatusDone)
+	}
+	return m.renderShellMark()
+}
+
+// renderShellMark renders the badge of a window without an agent.
+func (m Model) renderShellMark() string {
+	if m.Plain {
+		return fmt.Sprintf("%-9s", "[shell]")
+	}
+	return m.Styles.StatusBar.Render(m.glyphs().Shell)
+}
+
+// renderUnknownMark renders the badge of a window discovery has no result
+// for.
+func (m Model) renderUnknownMark() string {
+	if m.Plain {
+		return "[unknown]"
+	}
+	return m.Styles.StatusBar.Render(m.glyphs().Unknown)
+}
+
+// renderLimitReset renders when a LIMITED agent's quota resets, or nothing
+// for other statuses and unknown reset times.
+func (m Model) renderLimitReset(status tmux.Status, reset string) string {
+	if status != tmux.StatusLimited || reset == "" {
+		return ""
+	}
+	return "  " + m.Styles.StatusLimited.Render(m.asciiText("resets "+reset))
+}
+
+// renderWaitingReason labels what a WAITING agent needs. Permission dialogs
+// are bold since they block work mid-task; a plain input prompt is dimmed.
+func (m Model) renderWaitingReason(status tmux.Status, reason tmux.WaitingReason) string {
+	if status != tmux.StatusWaiting || reason == "" {
+		return ""
+	}
+	switch reason {
+	case tmux.WaitingPermission:
+		return "  " + m.Styles.StatusWaiting.Bold(true).Render("needs permission")
+	case tmux.WaitingQuestion:
+		return "  " + m.Styles.StatusWaiting.Render("asks a question")
 	default:
-		return m.Styles.StatusDone.Render("·")
+		return "  " + m.Styles.StatusBar.Render("awaiting input")
 	}
 }
 
@@ -292,10 +710,22 @@ func (m Model) renderStatusBar() string {
 		parts = append(parts, fmt.Sprintf("mode: %s", DashboardModeWorktree))
 		parts = append(parts, fmt.Sprintf("%d sessions", total))
 	}
+	if m.RepoScope.Active() {
+		parts = append(parts, fmt.Sprintf("repo: %s", m.RepoScope.Label))
+	}
+	if m.Workspace != "" {
+		parts = append(parts, fmt.Sprintf("workspace: %s", m.Workspace))
+	}
+	if summary := m.configSummary(); summary != "" {
+		parts = append(parts, summary)
+	}
 
 	if working > 0 {
 		parts = append(parts, m.Styles.StatusWorking.Render(fmt.Sprintf("%d working", working)))
 	}
+	if errored := m.erroredCount(); errored > 0 {
+		parts = append(parts, m.Styles.StatusError.Render(fmt.Sprintf("%d error", errored)))
+	}
 	if waiting > 0 {
 		parts = append(parts, m.Styles.StatusWaiting.Render(fmt.Sprintf("%d waiting", waiting)))
 	}
@@ -303,40 +733,147 @@ func (m Model) renderStatusBar() string {
 		parts = append(parts, m.Styles.StatusIdle.Render(fmt.Sprintf("%d idle", idle)))
 	}
 
+	if health := m.refreshHealth(); health != "" {
+		parts = append(parts, health)
+	}
+	if m.slowRefresh() {
+		parts = append(parts, m.Styles.StatusWaiting.Render(fmt.Sprintf("refresh took %.1fs", m.lastRefreshDuration.Seconds())))
+	}
 	if m.StatusMsg != "" {
 		parts = append(parts, m.Styles.StatusDone.Render(m.StatusMsg))
 	}
 
-	sep := m.Styles.StatusBar.Render(" · ")
+	sep := m.Styles.StatusBar.Render(" " + m.glyphs().Dot + " ")
 	return "  " + strings.Join(parts, sep)
 }
 
-// renderFooter renders context-sensitive keybindings.
+// configSummary names the expected config file when it is missing or
+// configures no projects, and otherwise counts the projects. It is empty
+// before the first refresh.
+func (m Model) configSummary() string {
+	if m.ConfigPath == "" {
+		return ""
+	}
+	if m.ConfigMissing || m.ProjectCount == 0 {
+		return m.Styles.StatusWaiting.Render("config: " + homeRelative(m.ConfigPath))
+	}
+	if m.ProjectCount == 1 {
+		return "1 project"
+	}
+	return fmt.Sprintf("%d projects", m.ProjectCount)
+}
+
+// homeRelative writes file under the home directory as ~/....
+func homeRelative(file string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == "/" {
+		return file
+	}
+	if rel, ok := strings.CutPrefix(file, home+string(filepath.Separator)); ok {
+		return "~" + string(filepath.Separator) + rel
+	}
+	return file
+}
+
+// refreshHealth describes how fresh the data is: how long ago it last
+// refreshed, prefixed by a spinner while a refresh runs and a warning glyph
+// when the latest refresh failed. It is empty before the first refresh.
+func (m Model) refreshHealth() string {
+	glyphs := m.glyphs()
+	var prefix string
+	if m.lastRefreshErr != nil {
+		prefix = glyphs.Warning + " "
+	}
+	if m.refreshInFlight {
+		prefix += glyphs.Spinner[m.spinnerFrame%len(glyphs.Spinner)] + " "
+	}
+
+	var text string
+	switch {
+	case !m.lastRefreshAt.IsZero():
+		text = "updated " + FormatUptime(clock().Sub(m.lastRefreshAt)) + " ago"
+	case m.refreshInFlight:
+		text = "loading"
+	case m.lastRefreshErr != nil:
+		text = "not loaded"
+	default:
+		return ""
+	}
+	if m.lastRefreshErr != nil {
+		return m.Styles.StatusError.Render(prefix + text)
+	}
+	return m.Styles.StatusBar.Render(prefix + text)
+}
+
+// canAdoptNode reports whether node is an agents-mode row in an unmanaged
+// session.
+func (m Model) canAdoptNode(node TreeNode) bool {
+	return node.Type == NodeAgentWindow && node.AgentIndex >= 0 && node.AgentIndex < len(m.AgentRows) &&
+		!m.AgentRows[node.AgentIndex].Managed
+}
+
+// renderFooter renders context-sensitive keybindings, offering the
+// workspace switcher when workspaces are configured.
 func (m Model) renderFooter() string {
 	if m.FilterMode {
-		return fmt.Sprintf("filter: %q  ·  type to search  ·  j/k navigate  ·  enter select  ·  esc clear  ·  m mode", m.FilterQuery)
+		return fmt.Sprintf("filter: %q  ·  type to search  ·  up/down navigate  ·  enter select  ·  esc clear  ·  m mode", m.FilterQuery)
+	}
+
+	mode := "  ·  m mode"
+	if len(m.Workspaces) > 0 {
+		mode = "  ·  w workspace" + mode
 	}
 
 	if m.Cursor >= len(m.Nodes) {
-		return "/ filter  ·  j/k navigate  ·  m mode  ·  q/esc quit"
+		return "/ filter  ·  j/k navigate" + mode + "  ·  ? legend  ·  q/esc quit"
 	}
 
 	if m.Mode == DashboardModeAgents {
-		return "/ filter  ·  j/k navigate  ·  enter attach  ·  m mode  ·  r refresh  ·  q/esc quit"
+		pin := "  ·  p pin"
+		if m.ReadOnly {
+			pin = ""
+		}
+		if m.Nodes[m.Cursor].Type == NodeAgentRepo {
+			return "/ filter  ·  j/k navigate  ·  enter toggle" + pin + mode + "  ·  r refresh  ·  ? legend  ·  q/esc quit"
+		}
+		addAgent := "  ·  a add agent"
+		if m.canAdoptNode(m.Nodes[m.Cursor]) {
+			addAgent += "  ·  A adopt"
+		}
+		if m.ReadOnly {
+			addAgent = ""
+		}
+		addAgent += pin
+		return "/ filter  ·  j/k navigate  ·  enter attach" + addAgent + mode + "  ·  r refresh  ·  ? legend  ·  q/esc quit"
+	}
+
+	addSession, addWindow, pin := "  ·  a add session", "  ·  a add window", "  ·  p pin"
+	if m.ReadOnly {
+		addSession, addWindow, pin = "", "", ""
 	}
 
 	node := m.Nodes[m.Cursor]
+	if len(m.missingWorktreeSessions(node)) > 0 {
+		enter, cleanup := "enter attach", "  ·  x clean up"
+		if node.Type == NodeWorktree {
+			enter = "enter toggle"
+		}
+		if m.ReadOnly {
+			cleanup = ""
+		}
+		return "/ filter  ·  j/k navigate  ·  " + enter + cleanup + mode + "  ·  ? legend  ·  q/esc quit"
+	}
 	switch node.Type {
 	case NodeRepo:
-		return "/ filter  ·  j/k navigate  ·  enter toggle  ·  a add session  ·  m mode  ·  q/esc quit"
+		return "/ filter  ·  j/k navigate  ·  enter toggle" + addSession + pin + mode + "  ·  ? legend  ·  q/esc quit"
 	case NodeWorktree:
-		return "/ filter  ·  j/k navigate  ·  enter toggle  ·  a add session  ·  m mode  ·  q/esc quit"
+		return "/ filter  ·  j/k navigate  ·  enter toggle" + addSession + mode + "  ·  ? legend  ·  q/esc quit"
 	case NodeSession:
-		return "/ filter  ·  j/k navigate  ·  enter attach  ·  a add window  ·  m mode  ·  q/esc quit"
+		return "/ filter  ·  j/k navigate  ·  enter attach" + addWindow + pin + "  ·  i details" + mode + "  ·  ? legend  ·  q/esc quit"
 	case NodeWindow:
-		return "/ filter  ·  j/k navigate  ·  enter attach  ·  a add window  ·  m mode  ·  q/esc quit"
+		return "/ filter  ·  j/k navigate  ·  enter attach" + addWindow + pin + "  ·  i details" + mode + "  ·  ? legend  ·  q/esc quit"
 	default:
-		return "/ filter  ·  j/k navigate  ·  q/esc quit"
+		return "/ filter  ·  j/k navigate  ·  ? legend  ·  q/esc quit"
 	}
 }
 
@@ -344,11 +881,15 @@ func (m Model) renderFooter() string {
 func (m Model) renderFrame(tree, statusBar, footer string) string {
 	w := max(m.frameWidth(), 20)
 
-	border := lipgloss.RoundedBorder()
+	border := m.glyphs().Border
 	bStyle := lipgloss.NewStyle().Foreground(m.Styles.Frame.GetBorderTopForeground())
 
 	// Top border with title: ╭─ ClawdBay ─────────────────╮
-	title := m.Styles.Title.Render(fmt.Sprintf(" ClawdBay · %s ", m.modeLabel()))
+	titleText := fmt.Sprintf(" ClawdBay · %s ", m.modeLabel())
+	if m.ReadOnly {
+		titleText = fmt.Sprintf(" ClawdBay · %s · read-only ", m.modeLabel())
+	}
+	title := m.Styles.Title.Render(m.asciiText(titleText))
 	titleW := lipgloss.Width(title)
 	topLine := bStyle.Render(border.TopLeft+border.Top) +
 		title +
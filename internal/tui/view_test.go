@@ -1,9 +1,17 @@
 package tui
 
 import (
+	"reflect"
 	"strings"
 	"testing"
+	"time"
+	"unicode"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/diskusage"
+	"github.com/ronsanzone/clawd-bay/internal/git"
 	"github.com/ronsanzone/clawd-bay/internal/tmux"
 )
 
@@ -30,6 +38,26 @@ func TestRenderStatusBadge(t *testing.T) {
 	}
 }
 
+func TestRenderStatusBadgePlain(t *testing.T) {
+	m := Model{Styles: NewStyles(KanagawaClaw), Plain: true}
+
+	tests := []struct {
+		status tmux.Status
+		want   string
+	}{
+		{tmux.StatusWorking, "[working]"},
+		{tmux.StatusWaiting, "[waiting]"},
+		{tmux.StatusIdle, "[idle]   "},
+		{tmux.StatusDone, "[done]   "},
+	}
+
+	for _, tt := range tests {
+		if got := m.renderStatusBadge(tt.status); got != tt.want {
+			t.Errorf("renderStatusBadge(%s) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
 func TestRenderStatusBar(t *testing.T) {
 	m := Model{
 		Groups: []RepoGroup{{
@@ -130,6 +158,44 @@ func TestViewContainsFrameElements(t *testing.T) {
 	}
 }
 
+func TestViewASCIIModeHasNoUnicode(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     "feature",
+				Expanded: true,
+				DiffStat: &git.DiffStat{Files: 2, Insertions: 10, Deletions: 3},
+				Sessions: []WorktreeSession{{
+					Name:     "s1",
+					Status:   tmux.StatusWaiting,
+					Note:     "a note that is long enough to be cut off in the row",
+					Expanded: false,
+				}},
+			}},
+		}},
+		Pins:           Pins{Sessions: map[string]bool{"s1": true}},
+		Styles:         NewStyles(KanagawaClaw),
+		WindowStatuses: make(map[string]tmux.Status),
+		Width:          100,
+		Height:         24,
+		ASCII:          true,
+		ShowLegend:     true,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	view := m.View()
+	for _, r := range view {
+		if r > unicode.MaxASCII {
+			t.Fatalf("ASCII view contains %q:\n%s", r, view)
+		}
+	}
+	if !strings.Contains(view, "+10 -3") {
+		t.Errorf("ASCII view missing diff stat:\n%s", view)
+	}
+}
+
 func TestViewEmptyStates(t *testing.T) {
 	base := Model{
 		Groups:         []RepoGroup{},
@@ -194,15 +260,15 @@ func TestRenderNodeLineWindowIncludesAgentTag(t *testing.T) {
 				Sessions: []WorktreeSession{{
 					Name:     "cb_demo",
 					Expanded: true,
-					Windows:  []tmux.Window{{Index: 3, Name: "workbench"}},
+					Windows:  []tmux.Window{{ID: "@3", Index: 3, Name: "workbench"}},
 				}},
 			}},
 		}},
 		WindowStatuses: map[string]tmux.Status{
-			"cb_demo:workbench": tmux.StatusWorking,
+			"@3": tmux.StatusWorking,
 		},
 		WindowAgentTypes: map[string]tmux.AgentType{
-			"cb_demo:workbench": tmux.AgentCodex,
+			"@3": tmux.AgentCodex,
 		},
 		Styles: NewStyles(KanagawaClaw),
 		Width:  80,
@@ -218,6 +284,58 @@ func TestRenderNodeLineWindowIncludesAgentTag(t *testing.T) {
 		t.Fatalf("window line missing status badge: %q", line)
 	}
 }
+func TestRenderNodeLineWindowShowsPurpose(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheme    string
+		window    string
+		purpose   string
+		agentType tmux.AgentType
+		want      []string
+		notWant   string
+	}{
+		{name: "running agent", window: "claude-review", purpose: "review", agentType: tmux.AgentClaude, want: []string{"[CLAUDE]", "review"}, notWant: "claude-review"},
+		{name: "exited agent", scheme: "{agent}/{purpose}", window: "codex/fix-login", purpose: "fix-login", agentType: tmux.AgentNone, want: []string{"fix-login", "codex"}, notWant: "codex/fix-login"},
+		{name: "not in the scheme", window: "claude", agentType: tmux.AgentClaude, want: []string{"[CLAUDE] claude"}},
+		{name: "uniquified agent window", window: "claude-2", agentType: tmux.AgentClaude, want: []string{"[CLAUDE] claude-2"}},
+		{name: "named by the user", window: "claude-notes", agentType: tmux.AgentClaude, want: []string{"[CLAUDE] claude-notes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{
+				Groups: []RepoGroup{{
+					Name:     "repo",
+					Expanded: true,
+					Worktrees: []WorktreeGroup{{
+						Name:     "(main repo)",
+						Expanded: true,
+						Sessions: []WorktreeSession{{
+							Name:     "cb_demo",
+							Expanded: true,
+							Windows:  []tmux.Window{{ID: "@2", Index: 2, Name: tt.window, Purpose: tt.purpose}},
+						}},
+					}},
+				}},
+				WindowAgentTypes: map[string]tmux.AgentType{"@2": tt.agentType},
+				Styles:           NewStyles(KanagawaClaw),
+				Plain:            true,
+				Width:            80,
+			}.WithAgentWindowName(tt.scheme)
+			m.Nodes = BuildNodes(m.Groups)
+
+			line := m.renderNodeLine(m.Nodes[3], 0)
+			for _, want := range tt.want {
+				if !strings.Contains(line, want) {
+					t.Fatalf("window line %q missing %q", line, want)
+				}
+			}
+			if tt.notWant != "" && strings.Contains(line, tt.notWant) {
+				t.Fatalf("window line %q should show the purpose apart from the agent", line)
+			}
+		})
+	}
+}
+
 func TestRenderNodeLineWindowNoAgentTagForNone(t *testing.T) {
 	m := Model{
 		Groups: []RepoGroup{{
@@ -229,15 +347,15 @@ func TestRenderNodeLineWindowNoAgentTagForNone(t *testing.T) {
 				Sessions: []WorktreeSession{{
 					Name:     "cb_demo",
 					Expanded: true,
-					Windows:  []tmux.Window{{Index: 1, Name: "shell"}},
+					Windows:  []tmux.Window{{ID: "@1", Index: 1, Name: "shell"}},
 				}},
 			}},
 		}},
 		WindowStatuses: map[string]tmux.Status{
-			"cb_demo:shell": tmux.StatusDone,
+			"@1": tmux.StatusDone,
 		},
 		WindowAgentTypes: map[string]tmux.AgentType{
-			"cb_demo:shell": tmux.AgentNone,
+			"@1": tmux.AgentNone,
 		},
 		Styles: NewStyles(KanagawaClaw),
 		Width:  80,
@@ -254,6 +372,42 @@ func TestRenderNodeLineWindowNoAgentTagForNone(t *testing.T) {
 	}
 }
 
+func TestRenderWindowBadgeWithoutAgent(t *testing.T) {
+	m := Model{
+		Styles:           NewStyles(KanagawaClaw),
+		Plain:            true,
+		WindowAgentTypes: map[string]tmux.AgentType{"@1": tmux.AgentNone, "@2": tmux.AgentNone},
+	}
+	tests := []struct {
+		window tmux.Window
+		want   string
+	}{
+		{window: tmux.Window{ID: "@1", Name: "shell"}, want: "[shell]"},
+		{window: tmux.Window{ID: "@2", Name: "claude"}, want: "[done]"},
+		{window: tmux.Window{ID: "@3", Name: "shell"}, want: "[unknown]"},
+	}
+	for _, tt := range tests {
+		if got := strings.TrimSpace(m.renderWindowBadge(tt.window, tt.window.ID)); got != tt.want {
+			t.Errorf("renderWindowBadge(%s %q) = %q, want %q", tt.window.ID, tt.window.Name, got, tt.want)
+		}
+	}
+}
+
+func TestRenderLegendBoxPlain(t *testing.T) {
+	m := Model{Styles: NewStyles(KanagawaClaw), Plain: true}
+	legend := strings.Join(m.renderLegendBox(80), "\n")
+	for _, want := range []string{"[working]", "[done]", "[shell]", "[unknown]"} {
+		if !strings.Contains(legend, want) {
+			t.Fatalf("plain legend missing %q:\n%s", want, legend)
+		}
+	}
+	for _, glyph := range []string{UnicodeGlyphs.Shell, UnicodeGlyphs.Unknown} {
+		if strings.Contains(legend, glyph) {
+			t.Fatalf("plain legend shows glyph %q:\n%s", glyph, legend)
+		}
+	}
+}
+
 func TestViewAgentsModeEmptyState(t *testing.T) {
 	m := Model{
 		Mode:           DashboardModeAgents,
@@ -263,7 +417,7 @@ func TestViewAgentsModeEmptyState(t *testing.T) {
 		Width:          80,
 		Height:         24,
 	}
-	m.Nodes = BuildAgentNodes(m.AgentRows)
+	m.Nodes = BuildAgentNodes(m.AgentRows, nil)
 
 	view := m.View()
 	if !strings.Contains(view, "No detected agent windows") {
@@ -286,11 +440,16 @@ func TestRenderNodeLineAgentRowIncludesMetadata(t *testing.T) {
 		},
 		Styles: NewStyles(KanagawaClaw),
 		Width:  80,
-		Cursor: 0,
+		Cursor: 1,
+	}
+	m.Nodes = BuildAgentNodes(m.AgentRows, nil)
+
+	header := m.renderNodeLine(m.Nodes[0], 0)
+	if !strings.Contains(header, "demo-repo") || !strings.Contains(header, "(1)") {
+		t.Fatalf("repo header missing name or count: %q", header)
 	}
-	m.Nodes = BuildAgentNodes(m.AgentRows)
 
-	line := m.renderNodeLine(m.Nodes[0], 0)
+	line := m.renderNodeLine(m.Nodes[1], 1)
 	if !strings.Contains(line, "[CODEX]") {
 		t.Fatalf("agent row missing [CODEX] tag: %q", line)
 	}
@@ -325,7 +484,7 @@ func TestRenderFooterAgentsMode(t *testing.T) {
 		Width:  80,
 		Height: 24,
 	}
-	m.Nodes = BuildAgentNodes(m.AgentRows)
+	m.Nodes = BuildAgentNodes(m.AgentRows, nil)
 
 	footer := m.renderFooter()
 	if !strings.Contains(footer, "m mode") {
@@ -388,6 +547,30 @@ func TestRenderFooterWorktreeAddHints(t *testing.T) {
 	}
 }
 
+func TestRenderFooterReadOnlyHidesAddHints(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     "(main repo)",
+				Expanded: true,
+				Sessions: []WorktreeSession{{Name: "cb_demo"}},
+			}},
+		}},
+		Styles:   NewStyles(KanagawaClaw),
+		ReadOnly: true,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	for cursor := range m.Nodes {
+		m.Cursor = cursor
+		if footer := m.renderFooter(); strings.Contains(footer, "a add") {
+			t.Fatalf("read-only footer = %q, want no add hint", footer)
+		}
+	}
+}
+
 func TestViewRendersAddDialogPopup(t *testing.T) {
 	m := Model{
 		Groups: []RepoGroup{{
@@ -426,3 +609,390 @@ func TestViewRendersAddDialogPopup(t *testing.T) {
 		t.Fatalf("view missing dialog hint: %q", view)
 	}
 }
+
+func TestRenderNodeLineWorktreeShowsDiffStat(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{
+				{Name: "(main repo)", IsMainRepo: true},
+				{Name: ".worktrees/repo-feat", DiffStat: &git.DiffStat{Files: 8, Insertions: 123, Deletions: 45}},
+				{Name: ".worktrees/repo-clean", DiffStat: &git.DiffStat{}},
+			},
+		}},
+		Styles: NewStyles(KanagawaClaw),
+		Width:  80,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	line := m.renderNodeLine(m.Nodes[2], 2)
+	if !strings.Contains(line, "+123 −45 (8 files)") {
+		t.Fatalf("worktree line missing diff stat: %q", line)
+	}
+
+	clean := m.renderNodeLine(m.Nodes[3], 3)
+	if strings.Contains(clean, "files)") || strings.Contains(clean, "file)") {
+		t.Fatalf("clean worktree line should omit diff stat: %q", clean)
+	}
+}
+
+func TestRenderNodeLineRepoShowsStaleWorktrees(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:           "repo",
+			Expanded:       true,
+			StaleWorktrees: []string{".worktrees/repo-gone", ".worktrees/repo-old"},
+			Worktrees:      []WorktreeGroup{{Name: "(main repo)", IsMainRepo: true}},
+		}},
+		Styles: NewStyles(KanagawaClaw),
+		Width:  80,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	if line := m.renderNodeLine(m.Nodes[0], 0); !strings.Contains(line, "[2 STALE]") {
+		t.Fatalf("repo line missing stale warning: %q", line)
+	}
+}
+
+func TestFormatDiffStat(t *testing.T) {
+	tests := []struct {
+		name string
+		stat *git.DiffStat
+		want string
+	}{
+		{name: "nil", stat: nil, want: ""},
+		{name: "no changes", stat: &git.DiffStat{}, want: ""},
+		{name: "single file", stat: &git.DiffStat{Files: 1, Insertions: 2}, want: "+2 −0 (1 file)"},
+		{name: "many files", stat: &git.DiffStat{Files: 3, Insertions: 10, Deletions: 4}, want: "+10 −4 (3 files)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDiffStat(tt.stat); got != tt.want {
+				t.Fatalf("formatDiffStat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatOverlapBadge(t *testing.T) {
+	tests := []struct {
+		name     string
+		overlaps []discovery.FileOverlap
+		want     string
+	}{
+		{name: "none", overlaps: nil, want: ""},
+		{
+			name:     "single worktree",
+			overlaps: []discovery.FileOverlap{{Worktree: ".worktrees/repo-b", Files: []string{"a.go"}}},
+			want:     "⚠ overlaps repo-b (1 file)",
+		},
+		{
+			name: "several worktrees share files",
+			overlaps: []discovery.FileOverlap{
+				{Worktree: ".worktrees/repo-b", Files: []string{"a.go", "b.go"}},
+				{Worktree: ".worktrees/repo-c", Files: []string{"b.go", "c.go"}},
+			},
+			want: "⚠ overlaps 2 worktrees (3 files)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatOverlapBadge(tt.overlaps); got != tt.want {
+				t.Fatalf("formatOverlapBadge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestViewRendersLegendPopup(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{Name: "repo", Path: "/tmp/repo"}},
+		Styles: NewStyles(KanagawaClaw),
+		Width:  80,
+		Height: 24,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	m = updated.(Model)
+	view := m.View()
+	for _, want := range []string{"Legend", "WAITING  agent needs your input", "[CLAUDE]", "agent type"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = updated.(Model)
+	if !m.ShowLegend {
+		t.Fatal("navigation keys should not close the legend")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(Model)
+	if m.ShowLegend || strings.Contains(m.View(), "Legend") {
+		t.Fatal("esc should close the legend")
+	}
+}
+
+func TestViewRendersSessionNoteAndDetailPopup(t *testing.T) {
+	note := "waiting on review from Sam before merging the migration"
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:       "(main repo)",
+				Path:       "/tmp/repo",
+				IsMainRepo: true,
+				Expanded:   true,
+				Sessions:   []WorktreeSession{{Name: "cb_main", Status: tmux.StatusIdle, Note: note, ActiveTime: 90 * time.Minute}},
+			}},
+		}},
+		Styles:         NewStyles(KanagawaClaw),
+		WindowStatuses: make(map[string]tmux.Status),
+		Width:          100,
+		Height:         24,
+		Cursor:         2,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	line := m.renderNodeLine(m.Nodes[2], 0)
+	if !strings.Contains(line, noteExcerpt(note)) || strings.Contains(line, note) {
+		t.Fatalf("session row should show the truncated note: %q", line)
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = updated.(Model)
+	view := m.View()
+	for _, want := range []string{"worktree: /tmp/repo", "active: 1h30m working", "waiting on review from Sam before", "the migration"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("detail popup missing %q:\n%s", want, view)
+		}
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = updated.(Model)
+	if m.ShowDetail {
+		t.Fatal("i should close the detail popup")
+	}
+}
+
+func TestDetailPopupMeasuresWorktreeDiskUsage(t *testing.T) {
+	measured := 0
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     ".worktrees/repo-feat",
+				Path:     "/tmp/repo/.worktrees/repo-feat",
+				Expanded: true,
+				Sessions: []WorktreeSession{{Name: "cb_feat", Status: tmux.StatusIdle}},
+			}},
+		}},
+		Styles:         NewStyles(KanagawaClaw),
+		WindowStatuses: make(map[string]tmux.Status),
+		Width:          100,
+		Height:         24,
+		Cursor:         2,
+		DiskUsage: diskusage.NewCache(time.Minute, func(path string) (int64, error) {
+			measured++
+			return 3 * 1024 * 1024, nil
+		}),
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = updated.(Model)
+	if !strings.Contains(m.View(), "disk: measuring...") {
+		t.Fatalf("detail popup should show the size being measured:\n%s", m.View())
+	}
+	if cmd == nil {
+		t.Fatal("opening the detail popup should measure the worktree")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if !strings.Contains(m.View(), "disk: 3.0G") {
+		t.Fatalf("detail popup missing the measured size:\n%s", m.View())
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = updated.(Model)
+	if _, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}}); cmd != nil || measured != 1 {
+		t.Fatalf("reopening should reuse the cached size, measured %d time(s)", measured)
+	}
+}
+
+func TestDetailPopupASCIIRowsKeepWidth(t *testing.T) {
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     ".worktrees/repo-feat",
+				Path:     "/tmp/repo/.worktrees/repo-feat",
+				Expanded: true,
+				Sessions: []WorktreeSession{{Name: "cb_feat", Status: tmux.StatusIdle, Note: strings.Repeat("wait… ", 12)}},
+			}},
+		}},
+		Styles:         NewStyles(KanagawaClaw),
+		WindowStatuses: make(map[string]tmux.Status),
+		Width:          100,
+		Height:         24,
+		Cursor:         2,
+		ASCII:          true,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	rows := m.renderDetailBox(m.Width)
+	if len(rows) == 0 {
+		t.Fatal("renderDetailBox() returned no rows")
+	}
+	want := lipgloss.Width(rows[0])
+	for _, row := range rows {
+		if got := lipgloss.Width(row); got != want {
+			t.Fatalf("row %q is %d columns wide, want %d", row, got, want)
+		}
+	}
+}
+
+func TestDetailPopupRemeasuresExpiredDiskUsage(t *testing.T) {
+	measured := 0
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     ".worktrees/repo-feat",
+				Path:     "/tmp/repo/.worktrees/repo-feat",
+				Expanded: true,
+				Sessions: []WorktreeSession{{Name: "cb_feat", Status: tmux.StatusIdle}},
+			}},
+		}},
+		Styles:          NewStyles(KanagawaClaw),
+		WindowStatuses:  make(map[string]tmux.Status),
+		Width:           100,
+		Height:          24,
+		Cursor:          2,
+		ShowDetail:      true,
+		refreshInFlight: true,
+		// A negative TTL expires every measurement at once.
+		DiskUsage: diskusage.NewCache(-time.Nanosecond, func(path string) (int64, error) {
+			measured++
+			return 1024, nil
+		}),
+	}
+	m.Nodes = BuildNodes(m.Groups)
+	m.DiskUsage.Measure("/tmp/repo/.worktrees/repo-feat")
+
+	_, cmd := m.Update(tickMsg(time.Now()))
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("tick with the detail popup open = %T, want the tick and a measurement", cmd())
+	}
+	batch[1]()
+	if measured != 2 {
+		t.Fatalf("measured %d time(s), want the expired size measured again", measured)
+	}
+	if m.ShowDetail = false; m.detailDiskUsageCmd() != nil {
+		t.Fatal("a closed detail popup should not be measured")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{text: "one two three", width: 7, want: []string{"one two", "three"}},
+		{text: "  spaced   out  ", width: 20, want: []string{"spaced out"}},
+		{text: "abcdefgh", width: 3, want: []string{"abc", "def", "gh"}},
+		{text: "", width: 5, want: nil},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{s: "short", width: 10, want: "short"},
+		{s: "repo-feature-login", width: 10, want: "repo-…ogin"},
+		{s: "abcdefghij", width: 9, want: "abcd…ghij"},
+		{s: "abcdef", width: 1, want: "…"},
+	}
+	for _, tt := range tests {
+		if got := truncateMiddle(tt.s, tt.width, "…"); got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestRenderNodeLineTruncatesLongNames(t *testing.T) {
+	longName := "cb_" + strings.Repeat("very-long-session-name-", 6) + "end"
+	m := Model{
+		Groups: []RepoGroup{{
+			Name:     "repo",
+			Expanded: true,
+			Worktrees: []WorktreeGroup{{
+				Name:     "(main repo)",
+				Path:     "/code/repo",
+				Expanded: true,
+				Sessions: []WorktreeSession{{Name: longName, Status: tmux.StatusIdle}},
+			}},
+		}},
+		Styles:         NewStyles(KanagawaClaw),
+		WindowStatuses: make(map[string]tmux.Status),
+		Width:          60,
+		Height:         20,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	line := m.renderNodeLine(m.Nodes[2], 0)
+	if w := lipgloss.Width(line); w > m.treeWidth() {
+		t.Fatalf("line width = %d, want <= %d: %q", w, m.treeWidth(), line)
+	}
+	if !strings.Contains(line, "cb_very") || !strings.Contains(line, "name-end") || !strings.Contains(line, "…") {
+		t.Fatalf("line = %q, want middle-truncated session name", line)
+	}
+
+	m.Cursor = 2
+	m.ShowDetail = true
+	m.Width = 200
+	if view := m.View(); !strings.Contains(view, "name-end") || !strings.Contains(view, "cb_very-long") {
+		t.Fatalf("detail view missing full session name:\n%s", view)
+	}
+}
+
+func TestViewCompactDropsFrame(t *testing.T) {
+	m := Model{
+		Groups:  []RepoGroup{{Name: "repo", Path: "/tmp/repo"}},
+		Styles:  NewStyles(KanagawaClaw),
+		Width:   60,
+		Height:  10,
+		Compact: true,
+	}
+	m.Nodes = BuildNodes(m.Groups)
+
+	view := m.View()
+	lines := strings.Split(view, "\n")
+	if len(lines) != m.Height {
+		t.Fatalf("compact view has %d lines, want %d:\n%s", len(lines), m.Height, view)
+	}
+	if strings.ContainsAny(view, "╭╰│") {
+		t.Fatalf("compact view should not draw a frame:\n%s", view)
+	}
+	if !strings.Contains(lines[0], "repo") || !strings.Contains(lines[len(lines)-2], "mode: worktree") || !strings.Contains(lines[len(lines)-1], "/ filter") {
+		t.Fatalf("compact view missing tree, status bar, or footer:\n%s", view)
+	}
+}
@@ -0,0 +1,546 @@
+// Package zellij implements multiplexer.Multiplexer on top of the zellij CLI,
+// so the dashboard can manage worktree sessions in zellij. Tabs play the role
+// of tmux windows and are addressed as "session:index" (see
+// tmux.Window.Target). zellij has no session user options, so the options
+// cb stores on tmux sessions are kept in a JSON file instead.
+package zellij
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ronsanzone/clawd-bay/internal/logging"
+	"github.com/ronsanzone/clawd-bay/internal/multiplexer"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+var _ multiplexer.Multiplexer = (*Client)(nil)
+
+// errNotSupported is returned (wrapped) for operations zellij's CLI cannot
+// perform.
+var errNotSupported = errors.New("not supported by the zellij backend")
+
+// Client drives zellij through its CLI.
+type Client struct {
+	// execCommand runs name with args in dir ("" for the current directory).
+	execCommand     func(dir, name string, args ...string) ([]byte, error)
+	execInteractive func(name string, args ...string) error
+	optionsPath     string
+}
+
+// NewClient creates a Client that runs real zellij commands and keeps session
+// options in the file at optionsPath.
+func NewClient(optionsPath string) *Client {
+	return &Client{
+		execCommand: func(dir, name string, args ...string) ([]byte, error) {
+			cmd := exec.Command(name, args...)
+			cmd.Dir = dir
+			return logging.Output(cmd)
+		},
+		execInteractive: func(name string, args ...string) error {
+			cmd := exec.Command(name, args...)
+			cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+			return cmd.Run()
+		},
+		optionsPath: optionsPath,
+	}
+}
+
+// action runs "zellij --session session action args...".
+func (c *Client) action(session string, args ...string) ([]byte, error) {
+	return c.execCommand("", "zellij", append([]string{"--session", session, "action"}, args...)...)
+}
+
+// ListAllSessions returns every running zellij session.
+func (c *Client) ListAllSessions() ([]tmux.Session, error) {
+	output, err := c.execCommand("", "zellij", "list-sessions", "--short", "--no-formatting")
+	if err != nil {
+		// zellij exits non-zero when no session exists.
+		if strings.Contains(string(output), "No active zellij sessions") || isNoSessionsError(err) {
+			return []tmux.Session{}, nil
+		}
+		return nil, fmt.Errorf("failed to list zellij sessions: %w", err)
+	}
+	sessions := []tmux.Session{}
+	for line := range strings.SplitSeq(strings.TrimSpace(string(output)), "\n") {
+		// Exited sessions kept for resurrection are listed with a suffix.
+		name := strings.TrimSpace(line)
+		if name == "" || strings.Contains(name, "EXITED") {
+			continue
+		}
+		sessions = append(sessions, tmux.Session{Name: name})
+	}
+	return sessions, nil
+}
+
+func isNoSessionsError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && strings.Contains(string(exitErr.Stderr), "No active zellij sessions")
+}
+
+// ListSessions returns the ClawdBay-managed (cb_ prefixed) sessions.
+func (c *Client) ListSessions() ([]tmux.Session, error) {
+	all, err := c.ListAllSessions()
+	if err != nil {
+		return nil, err
+	}
+	sessions := []tmux.Session{}
+	for _, s := range all {
+		if strings.HasPrefix(s.Name, "cb_") {
+			sessions = append(sessions, s)
+		}
+	}
+	return sessions, nil
+}
+
+// HasSession reports whether a session named name is running.
+func (c *Client) HasSession(name string) bool {
+	sessions, err := c.ListAllSessions()
+	if err != nil {
+		return false
+	}
+	for _, s := range sessions {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateSession starts a detached session whose first tab opens in workdir.
+func (c *Client) CreateSession(name, workdir string) error {
+	if _, err := c.execCommand(workdir, "zellij", "attach", "--create-background", name); err != nil {
+		return fmt.Errorf("failed to create zellij session %s: %w", name, err)
+	}
+	return nil
+}
+
+// RenameSession renames oldName and moves its options along.
+func (c *Client) RenameSession(oldName, newName string) error {
+	if _, err := c.action(oldName, "rename-session", newName); err != nil {
+		return fmt.Errorf("failed to rename zellij session %s: %w", oldName, err)
+	}
+	return c.updateOptions(func(all map[string]map[string]string) {
+		if opts, ok := all[oldName]; ok {
+			all[newName] = opts
+			delete(all, oldName)
+		}
+	})
+}
+
+// KillSession kills and deletes name, dropping its options.
+func (c *Client) KillSession(name string) error {
+	if _, err := c.execCommand("", "zellij", "delete-session", "--force", name); err != nil {
+		return fmt.Errorf("failed to kill zellij session %s: %w", name, err)
+	}
+	return c.updateOptions(func(all map[string]map[string]string) { delete(all, name) })
+}
+
+// GetSessionOption returns the option key recorded for session.
+func (c *Client) GetSessionOption(session, key string) (string, error) {
+	all, err := c.loadOptions()
+	if err != nil {
+		return "", err
+	}
+	value, ok := all[session][key]
+	if !ok {
+		return "", fmt.Errorf("option %s not set on session %s", key, session)
+	}
+	return value, nil
+}
+
+// SetSessionOption records the option key for session.
+func (c *Client) SetSessionOption(session, key, value string) error {
+	return c.updateOptions(func(all map[string]map[string]string) {
+		if all[session] == nil {
+			all[session] = map[string]string{}
+		}
+		all[session][key] = value
+	})
+}
+
+// GetPaneWorkingDir returns the session's recorded home path; zellij does not
+// report pane working directories.
+func (c *Client) GetPaneWorkingDir(session string) string {
+	home, _ := c.GetSessionOption(session, tmux.SessionOptionHomePath)
+	return home
+}
+
+// GetWindowWorkingDir returns the session's recorded home path, as
+// GetPaneWorkingDir.
+func (c *Client) GetWindowWorkingDir(session string, windowIndex int) string {
+	return c.GetPaneWorkingDir(session)
+}
+
+// ListWindows returns the tabs of session. Tabs have no stable IDs, so
+// windows are addressed by index.
+func (c *Client) ListWindows(session string) ([]tmux.Window, error) {
+	output, err := c.action(session, "query-tab-names")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tabs of %s: %w", session, err)
+	}
+	windows := []tmux.Window{}
+	for i, name := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if name == "" {
+			continue
+		}
+		windows = append(windows, tmux.Window{Index: i, Name: name})
+	}
+	return windows, nil
+}
+
+// CreateWindow opens a tab named name in session and types command into it.
+func (c *Client) CreateWindow(session, name, command string) error {
+	return c.newTab(session, name, command, "")
+}
+
+// CreateWindowWithShellInDir opens a tab named name in workdir and types
+// command into its shell, which stays open when the command exits.
+func (c *Client) CreateWindowWithShellInDir(session, name, command, workdir string) error {
+	return c.newTab(session, name, command, workdir)
+}
+
+func (c *Client) newTab(session, name, command, workdir string) error {
+	args := []string{"new-tab", "--name", name}
+	if workdir != "" {
+		args = append(args, "--cwd", workdir)
+	}
+	if _, err := c.action(session, args...); err != nil {
+		return fmt.Errorf("failed to create tab %s in %s: %w", name, session, err)
+	}
+	if command == "" {
+		return nil
+	}
+	return c.typeLine(session, command)
+}
+
+// RenameWindow renames the tab at target ("session:index"), or the focused
+// tab when target is a bare session name.
+func (c *Client) RenameWindow(target, name string) error {
+	rename := func(session string) error {
+		if _, err := c.action(session, "rename-tab", name); err != nil {
+			return fmt.Errorf("failed to rename tab %s: %w", target, err)
+		}
+		return nil
+	}
+	if _, _, err := parseTarget(target); err != nil {
+		return rename(target)
+	}
+	return c.onTab(target, rename)
+}
+
+// SelectWindow focuses the tab at target ("session:index").
+func (c *Client) SelectWindow(target string) error {
+	session, index, err := parseTarget(target)
+	if err != nil {
+		return err
+	}
+	if _, err := c.action(session, "go-to-tab", strconv.Itoa(index+1)); err != nil {
+		return fmt.Errorf("failed to select tab %s: %w", target, err)
+	}
+	return nil
+}
+
+// ListPanes is not supported: zellij's CLI does not list panes.
+func (c *Client) ListPanes(target string) ([]tmux.Pane, error) {
+	return nil, fmt.Errorf("failed to list panes of %s: %w", target, errNotSupported)
+}
+
+// SendText types text followed by Enter into the focused pane of the tab at
+// target (see onTab).
+func (c *Client) SendText(target, text string) error {
+	return c.onTab(target, func(session string) error {
+		return c.typeLine(session, text)
+	})
+}
+
+// SendCommandToPane types command into the tab at target; zellij panes are
+// not addressable, so the tab's focused pane receives it.
+func (c *Client) SendCommandToPane(target, command string) error {
+	return c.SendText(target, command)
+}
+
+// CapturePane dumps the focused pane of the tab at target (see onTab), with
+// scrollback when history is positive.
+func (c *Client) CapturePane(target string, history int) (string, error) {
+	var content string
+	err := c.onTab(target, func(session string) error {
+		var err error
+		content, err = c.dumpScreen(session, history > 0)
+		return err
+	})
+	return content, err
+}
+
+// AttachOrSwitchToSession attaches to name. zellij cannot switch an attached
+// client to another session from the CLI, so inside zellij it reports how to
+// get there instead.
+func (c *Client) AttachOrSwitchToSession(name string, inMultiplexer bool) error {
+	if inMultiplexer {
+		return fmt.Errorf("cannot switch sessions from inside zellij: detach and run zellij attach %s", name)
+	}
+	if err := c.execInteractive("zellij", "attach", name); err != nil {
+		return fmt.Errorf("failed to attach to zellij session %s: %w", name, err)
+	}
+	return nil
+}
+
+// DetectAgentInfo identifies the agent of the tab at target from its name, as
+// cb names agent tabs after the agent command. Reading a tab's screen needs
+// it focused, so the status is only derived for the focused tab while a
+// client's focused pane runs that agent; other agent tabs are reported
+// UNKNOWN.
+func (c *Client) DetectAgentInfo(target string) tmux.AgentInfo {
+	session, index, err := parseTarget(target)
+	if err != nil {
+		return noAgent
+	}
+	windows, err := c.ListWindows(session)
+	if err != nil || index >= len(windows) {
+		return noAgent
+	}
+	return c.detectTab(session, windows[index], c.focusOf(session))
+}
+
+// noAgent is the AgentInfo of a tab that runs no agent.
+var noAgent = tmux.AgentInfo{Type: tmux.AgentNone, Status: tmux.StatusDone}
+
+// sessionFocus is what a session's clients have focused: the focused tab (-1
+// when unknown) and the commands running in the focused panes.
+type sessionFocus struct {
+	tab      int
+	commands []string
+}
+
+// focusOf reads what session's clients have focused.
+func (c *Client) focusOf(session string) sessionFocus {
+	focus := sessionFocus{tab: -1}
+	if tab, ok := c.focusedTab(session); ok {
+		focus.tab = tab
+	}
+	output, err := c.action(session, "list-clients")
+	if err != nil {
+		return focus
+	}
+	// "CLIENT_ID PANE_ID RUNNING_COMMAND" lines.
+	for line := range strings.SplitSeq(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] == "CLIENT_ID" {
+			continue
+		}
+		focus.commands = append(focus.commands, strings.Join(fields[2:], " "))
+	}
+	return focus
+}
+
+// runs reports whether a focused pane runs agent.
+func (f sessionFocus) runs(agent tmux.AgentType) bool {
+	for _, command := range f.commands {
+		if tmux.ClassifyCommand(command) == agent {
+			return true
+		}
+	}
+	return false
+}
+
+// detectTab identifies the agent of window, a tab of session, given what the
+// session's clients have focused.
+func (c *Client) detectTab(session string, window tmux.Window, focus sessionFocus) tmux.AgentInfo {
+	agent := tmux.ClassifyCommand(window.Name)
+	if agent == tmux.AgentNone {
+		return noAgent
+	}
+	info := tmux.AgentInfo{Type: agent, Detected: true, Status: tmux.StatusUnknown}
+	if focus.tab != window.Index || !focus.runs(agent) {
+		return info
+	}
+	content, err := c.dumpScreen(session, false)
+	if err != nil {
+		slog.Debug("zellij DetectAgentInfo: dump-screen failed", "target", window.Target(session), "err", err)
+		return info
+	}
+	status := tmux.ClassifyContent(content)
+	info.Status, info.LimitReset, info.WaitingReason = status.Status, status.LimitReset, status.WaitingReason
+	return info
+}
+
+// ListSessionWindowInfo returns every tab of every session with its detected
+// agent.
+func (c *Client) ListSessionWindowInfo() ([]tmux.SessionWindowInfo, error) {
+	sessions, err := c.ListAllSessions()
+	if err != nil {
+		return nil, err
+	}
+	rows := []tmux.SessionWindowInfo{}
+	for _, s := range sessions {
+		repoName, home := "Unknown", c.GetPaneWorkingDir(s.Name)
+		if home != "" {
+			repoName = filepath.Base(home)
+		}
+		windows, err := c.ListWindows(s.Name)
+		if err != nil {
+			continue
+		}
+		focus := c.focusOf(s.Name)
+		tags, _ := c.GetSessionOption(s.Name, tmux.SessionOptionTags)
+		for _, w := range windows {
+			rows = append(rows, tmux.SessionWindowInfo{
+				SessionName: s.Name,
+				RepoName:    repoName,
+				Project:     repoName,
+				ProjectPath: home,
+				Window:      w,
+				AgentInfo:   c.detectTab(s.Name, w, focus),
+				Managed:     strings.HasPrefix(s.Name, "cb_"),
+				Tags:        tmux.ParseTags(tags),
+			})
+		}
+	}
+	return rows, nil
+}
+
+// AgentProcessStats is not supported: zellij does not expose pane ttys.
+func (c *Client) AgentProcessStats(target string) (tmux.AgentProcess, bool) {
+	return tmux.AgentProcess{}, false
+}
+
+// onTab runs fn against the tab at target. zellij's CLI only reaches the
+// focused pane, so an unfocused tab is focused for fn and the tab focused
+// before is focused again afterwards, leaving the user's view as it was.
+func (c *Client) onTab(target string, fn func(session string) error) error {
+	session, index, err := parseTarget(target)
+	if err != nil {
+		return err
+	}
+	focused, ok := c.focusedTab(session)
+	if ok && focused == index {
+		return fn(session)
+	}
+	if err := c.SelectWindow(target); err != nil {
+		return err
+	}
+	if ok {
+		defer func() {
+			if _, err := c.action(session, "go-to-tab", strconv.Itoa(focused+1)); err != nil {
+				slog.Debug("zellij: failed to restore focused tab", "session", session, "err", err)
+			}
+		}()
+	}
+	return fn(session)
+}
+
+// focusedTab returns the index of session's focused tab, read from its
+// dumped layout.
+func (c *Client) focusedTab(session string) (int, bool) {
+	output, err := c.action(session, "dump-layout")
+	if err != nil {
+		return 0, false
+	}
+	return parseFocusedTab(string(output))
+}
+
+// parseFocusedTab finds the tab marked focus=true among the tabs directly
+// inside a KDL layout's layout node, skipping tab templates and swap layouts.
+func parseFocusedTab(layout string) (int, bool) {
+	depth, index := 0, 0
+	for line := range strings.SplitSeq(layout, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if depth == 1 && (trimmed == "tab" || strings.HasPrefix(trimmed, "tab ")) {
+			if strings.Contains(trimmed, "focus=true") {
+				return index, true
+			}
+			index++
+		}
+		depth += strings.Count(trimmed, "{") - strings.Count(trimmed, "}")
+	}
+	return 0, false
+}
+
+// typeLine types text into the focused pane of session and presses Enter.
+func (c *Client) typeLine(session, text string) error {
+	if _, err := c.action(session, "write-chars", text); err != nil {
+		return fmt.Errorf("failed to send text to %s: %w", session, err)
+	}
+	if _, err := c.action(session, "write", "13"); err != nil {
+		return fmt.Errorf("failed to send enter to %s: %w", session, err)
+	}
+	return nil
+}
+
+// dumpScreen returns the content of session's focused pane.
+func (c *Client) dumpScreen(session string, full bool) (string, error) {
+	tmp, err := os.CreateTemp("", "cb-zellij-dump-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create screen dump file: %w", err)
+	}
+	path := tmp.Name()
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(path) }()
+
+	args := []string{"dump-screen", path}
+	if full {
+		args = append(args, "--full")
+	}
+	if _, err := c.action(session, args...); err != nil {
+		return "", fmt.Errorf("failed to capture %s: %w", session, err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read screen dump of %s: %w", session, err)
+	}
+	return string(content), nil
+}
+
+// parseTarget splits a "session:index" window target.
+func parseTarget(target string) (string, int, error) {
+	i := strings.LastIndex(target, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid zellij tab target %q (want session:index): %w", target, tmux.ErrWindowNotFound)
+	}
+	index, err := strconv.Atoi(target[i+1:])
+	if err != nil || index < 0 {
+		return "", 0, fmt.Errorf("invalid zellij tab target %q (want session:index): %w", target, tmux.ErrWindowNotFound)
+	}
+	return target[:i], index, nil
+}
+
+func (c *Client) loadOptions() (map[string]map[string]string, error) {
+	all := map[string]map[string]string{}
+	content, err := os.ReadFile(c.optionsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return all, nil
+		}
+		return nil, fmt.Errorf("failed to read zellij options %s: %w", c.optionsPath, err)
+	}
+	if err := json.Unmarshal(content, &all); err != nil {
+		return nil, fmt.Errorf("failed to parse zellij options %s: %w", c.optionsPath, err)
+	}
+	return all, nil
+}
+
+func (c *Client) updateOptions(update func(map[string]map[string]string)) error {
+	all, err := c.loadOptions()
+	if err != nil {
+		return err
+	}
+	update(all)
+	content, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode zellij options: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.optionsPath), 0755); err != nil {
+		return fmt.Errorf("failed to create zellij options directory: %w", err)
+	}
+	if err := os.WriteFile(c.optionsPath, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write zellij options %s: %w", c.optionsPath, err)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package zellij
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// fakeZellij answers zellij CLI calls from canned output keyed by the
+// subcommand (or the action name for "zellij --session s action ...").
+type fakeZellij struct {
+	output map[string]string
+	screen string
+	calls  []string
+}
+
+func (f *fakeZellij) exec(dir, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, strings.Join(args, " "))
+	key := args[0]
+	if key == "--session" {
+		key = args[3]
+		if key == "dump-screen" {
+			return nil, os.WriteFile(args[4], []byte(f.screen), 0644)
+		}
+	}
+	if out, ok := f.output[key]; ok {
+		return []byte(out), nil
+	}
+	return nil, errors.New("unexpected command")
+}
+
+func newFakeClient(t *testing.T, f *fakeZellij) *Client {
+	return &Client{execCommand: f.exec, optionsPath: filepath.Join(t.TempDir(), "zellij-options.json")}
+}
+
+func TestClient_ListSessions(t *testing.T) {
+	f := &fakeZellij{output: map[string]string{"list-sessions": "cb_feat\nscratch\n"}}
+	client := newFakeClient(t, f)
+
+	got, err := client.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if want := []tmux.Session{{Name: "cb_feat"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListSessions() = %+v, want %+v", got, want)
+	}
+	if !client.HasSession("scratch") || client.HasSession("cb_gone") {
+		t.Fatal("HasSession() should report exactly the listed sessions")
+	}
+}
+
+func TestClient_ListWindows(t *testing.T) {
+	f := &fakeZellij{output: map[string]string{"query-tab-names": "shell\nclaude\n"}}
+
+	got, err := newFakeClient(t, f).ListWindows("cb_feat")
+	if err != nil {
+		t.Fatalf("ListWindows() error = %v", err)
+	}
+	want := []tmux.Window{{Index: 0, Name: "shell"}, {Index: 1, Name: "claude"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ListWindows() = %+v, want %+v", got, want)
+	}
+	if target := got[1].Target("cb_feat"); target != "cb_feat:1" {
+		t.Fatalf("Target() = %q, want cb_feat:1", target)
+	}
+}
+
+// layoutFocusing dumps a two-tab layout with the tab at index focused.
+func layoutFocusing(index int) string {
+	focus := [2]string{}
+	focus[index] = " focus=true"
+	return "layout {\n" +
+		"    cwd \"/code/repo\"\n" +
+		"    tab name=\"shell\"" + focus[0] + " hide_floating_panes=true {\n" +
+		"        pane size=1 borderless=true {\n" +
+		"            plugin location=\"zellij:tab-bar\"\n" +
+		"        }\n" +
+		"        pane focus=true\n" +
+		"    }\n" +
+		"    tab name=\"claude\"" + focus[1] + " hide_floating_panes=true {\n" +
+		"        pane command=\"claude\" focus=true\n" +
+		"    }\n" +
+		"    new_tab_template {\n" +
+		"        pane\n" +
+		"    }\n" +
+		"    swap_tiled_layout name=\"vertical\" {\n" +
+		"        tab max_panes=5 focus=true {\n" +
+		"            pane\n" +
+		"        }\n" +
+		"    }\n" +
+		"}\n"
+}
+
+func TestParseFocusedTab(t *testing.T) {
+	for _, index := range []int{0, 1} {
+		if got, ok := parseFocusedTab(layoutFocusing(index)); !ok || got != index {
+			t.Fatalf("parseFocusedTab() = %d, %v, want %d", got, ok, index)
+		}
+	}
+	if _, ok := parseFocusedTab("layout {\n    tab name=\"a\" {\n    }\n}\n"); ok {
+		t.Fatal("parseFocusedTab() ok without a focused tab")
+	}
+}
+
+func TestClient_DetectAgentInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		focused int
+		clients string
+		target  string
+		want    tmux.AgentInfo
+	}{
+		{
+			name:    "focused agent is classified from its screen",
+			focused: 1,
+			clients: "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND\n1 terminal_2 claude\n",
+			target:  "cb_feat:1",
+			want:    tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusWaiting, WaitingReason: tmux.WaitingPermission},
+		},
+		{
+			name:    "agent whose pane is not focused is unknown",
+			focused: 1,
+			clients: "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND\n1 terminal_1 zsh\n",
+			target:  "cb_feat:1",
+			want:    tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusUnknown},
+		},
+		{
+			name:    "agent in an unfocused tab is unknown",
+			focused: 0,
+			clients: "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND\n1 terminal_2 claude\n",
+			target:  "cb_feat:1",
+			want:    tmux.AgentInfo{Type: tmux.AgentClaude, Detected: true, Status: tmux.StatusUnknown},
+		},
+		{
+			name:   "shell tab has no agent",
+			target: "cb_feat:0",
+			want:   tmux.AgentInfo{Type: tmux.AgentNone, Status: tmux.StatusDone},
+		},
+		{
+			name:   "malformed target",
+			target: "@1",
+			want:   tmux.AgentInfo{Type: tmux.AgentNone, Status: tmux.StatusDone},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeZellij{
+				output: map[string]string{
+					"query-tab-names": "shell\nclaude\n",
+					"list-clients":    tt.clients,
+					"dump-layout":     layoutFocusing(tt.focused),
+				},
+				screen: "Bash(rm -rf build)\n❯ 1. Yes\n  2. Yes, allow always\n",
+			}
+			if got := newFakeClient(t, f).DetectAgentInfo(tt.target); got != tt.want {
+				t.Fatalf("DetectAgentInfo(%q) = %+v, want %+v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_ListSessionWindowInfo(t *testing.T) {
+	f := &fakeZellij{output: map[string]string{
+		"list-sessions":   "cb_feat\n",
+		"query-tab-names": "shell\nclaude\n",
+		"list-clients":    "CLIENT_ID ZELLIJ_PANE_ID RUNNING_COMMAND\n1 terminal_1 zsh\n",
+		"dump-layout":     layoutFocusing(0),
+	}}
+
+	rows, err := newFakeClient(t, f).ListSessionWindowInfo()
+	if err != nil {
+		t.Fatalf("ListSessionWindowInfo() error = %v", err)
+	}
+	if len(rows) != 2 || rows[1].AgentInfo.Status != tmux.StatusUnknown {
+		t.Fatalf("rows = %+v, want two tabs with the claude tab UNKNOWN", rows)
+	}
+	lists := 0
+	for _, call := range f.calls {
+		if strings.HasSuffix(call, "query-tab-names") {
+			lists++
+		}
+	}
+	if lists != 1 {
+		t.Fatalf("query-tab-names ran %d times, want once per session", lists)
+	}
+}
+
+func TestClient_SessionOptions(t *testing.T) {
+	f := &fakeZellij{output: map[string]string{"rename-session": "", "delete-session": ""}}
+	client := newFakeClient(t, f)
+
+	if err := client.SetSessionOption("cb_old", tmux.SessionOptionHomePath, "/repo/.worktrees/feat"); err != nil {
+		t.Fatalf("SetSessionOption() error = %v", err)
+	}
+	if err := client.RenameSession("cb_old", "cb_new"); err != nil {
+		t.Fatalf("RenameSession() error = %v", err)
+	}
+	if got := client.GetPaneWorkingDir("cb_new"); got != "/repo/.worktrees/feat" {
+		t.Fatalf("GetPaneWorkingDir() after rename = %q", got)
+	}
+	if _, err := client.GetSessionOption("cb_old", tmux.SessionOptionHomePath); err == nil {
+		t.Fatal("GetSessionOption() on the old name error = nil, want unset")
+	}
+
+	if err := client.KillSession("cb_new"); err != nil {
+		t.Fatalf("KillSession() error = %v", err)
+	}
+	if got := client.GetPaneWorkingDir("cb_new"); got != "" {
+		t.Fatalf("GetPaneWorkingDir() after kill = %q, want empty", got)
+	}
+}
+
+func TestClient_SendText(t *testing.T) {
+	tests := []struct {
+		name    string
+		focused int
+		target  string
+		want    []string
+	}{
+		{
+			name:    "unfocused tab is focused and the previous tab restored",
+			focused: 0,
+			target:  "cb_feat:1",
+			want: []string{
+				"--session cb_feat action dump-layout",
+				"--session cb_feat action go-to-tab 2",
+				"--session cb_feat action write-chars run the tests",
+				"--session cb_feat action write 13",
+				"--session cb_feat action go-to-tab 1",
+			},
+		},
+		{
+			name:    "focused tab is typed into directly",
+			focused: 1,
+			target:  "cb_feat:1",
+			want: []string{
+				"--session cb_feat action dump-layout",
+				"--session cb_feat action write-chars run the tests",
+				"--session cb_feat action write 13",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeZellij{output: map[string]string{
+				"dump-layout": layoutFocusing(tt.focused), "go-to-tab": "", "write-chars": "", "write": "",
+			}}
+			if err := newFakeClient(t, f).SendText(tt.target, "run the tests"); err != nil {
+				t.Fatalf("SendText() error = %v", err)
+			}
+			if !reflect.DeepEqual(f.calls, tt.want) {
+				t.Fatalf("calls = %q, want %q", f.calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_RenameWindow(t *testing.T) {
+	f := &fakeZellij{output: map[string]string{"dump-layout": layoutFocusing(0), "go-to-tab": "", "rename-tab": ""}}
+	client := newFakeClient(t, f)
+
+	if err := client.RenameWindow("cb_feat:1", "claude-review"); err != nil {
+		t.Fatalf("RenameWindow() error = %v", err)
+	}
+	want := []string{
+		"--session cb_feat action dump-layout",
+		"--session cb_feat action go-to-tab 2",
+		"--session cb_feat action rename-tab claude-review",
+		"--session cb_feat action go-to-tab 1",
+	}
+	if !reflect.DeepEqual(f.calls, want) {
+		t.Fatalf("calls = %q, want %q", f.calls, want)
+	}
+
+	f.calls = nil
+	if err := client.RenameWindow("cb_feat", "notes"); err != nil {
+		t.Fatalf("RenameWindow() error = %v", err)
+	}
+	if want := []string{"--session cb_feat action rename-tab notes"}; !reflect.DeepEqual(f.calls, want) {
+		t.Fatalf("calls = %q, want %q", f.calls, want)
+	}
+}
@@ -0,0 +1,198 @@
+// Package clawdbay discovers ClawdBay projects, their worktrees, and the
+// tmux sessions running in them, the same way cb list and cb dash do, so
+// other Go tools can embed it without running the cb binary.
+//
+// Projects come from the cb config file (~/.config/cb/config.toml); sessions
+// and agent statuses are read from the running tmux server:
+//
+//	projects, err := clawdbay.Discover(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	for _, p := range projects {
+//		for _, wt := range p.Worktrees {
+//			for _, s := range wt.Sessions {
+//				fmt.Println(p.Name, wt.Name, s.Name, s.Status)
+//			}
+//		}
+//	}
+//
+// The types here are a stable view of cb's internal discovery model: fields
+// may be added, but existing ones keep their meaning.
+package clawdbay
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/agentconfig"
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+// Status is the state of a coding agent, rolled up per session with the
+// priority WORKING > ERROR > WAITING > LIMITED > IDLE > UNKNOWN > DONE.
+type Status string
+
+const (
+	// StatusWorking is an agent actively processing a task.
+	StatusWorking Status = Status(tmux.StatusWorking)
+	// StatusError is an agent that crashed or failed.
+	StatusError Status = Status(tmux.StatusError)
+	// StatusWaiting is an agent that needs user input.
+	StatusWaiting Status = Status(tmux.StatusWaiting)
+	// StatusLimited is an agent stalled on a usage or rate limit.
+	StatusLimited Status = Status(tmux.StatusLimited)
+	// StatusIdle is an agent running but not working.
+	StatusIdle Status = Status(tmux.StatusIdle)
+	// StatusUnknown is an agent whose state cannot be read.
+	StatusUnknown Status = Status(tmux.StatusUnknown)
+	// StatusDone is an agent that exited, or a session without agents.
+	StatusDone Status = Status(tmux.StatusDone)
+)
+
+// Agent is a coding agent CLI.
+type Agent string
+
+// The agents cb detects.
+const (
+	AgentClaude   Agent = Agent(tmux.AgentClaude)
+	AgentCodex    Agent = Agent(tmux.AgentCodex)
+	AgentOpenCode Agent = Agent(tmux.AgentOpenCode)
+)
+
+// Project is a project configured in cb.
+type Project struct {
+	Name string
+	Path string
+	// Invalid explains why the project could not be inspected (path gone,
+	// not a git repo); it then has no worktrees.
+	Invalid   string
+	Worktrees []Worktree
+	// StaleWorktrees lists worktrees git still tracks whose directories
+	// were deleted.
+	StaleWorktrees []string
+}
+
+// Worktree is the main repo or a linked worktree of a project.
+type Worktree struct {
+	Name string
+	// Path is empty for the Missing worktree.
+	Path string
+	// Branch is the branch checked out in a linked worktree, empty when
+	// detached or unknown.
+	Branch   string
+	MainRepo bool
+	// Missing marks the placeholder holding sessions whose worktree no
+	// longer exists.
+	Missing  bool
+	Sessions []Session
+}
+
+// Session is a tmux session running in a worktree.
+type Session struct {
+	Name string
+	// Status rolls up the statuses of the session's agent windows.
+	Status  Status
+	Windows []Window
+	Note    string
+	Tags    []string
+	// Branch, Agent, and CreatedAt are recorded when cb creates the
+	// session; they are empty (zero) for sessions it did not create.
+	Branch    string
+	Agent     Agent
+	CreatedAt time.Time
+}
+
+// Window is a tmux window of a session.
+type Window struct {
+	// ID is the tmux window ID (@N), stable across renames.
+	ID     string
+	Index  int
+	Name   string
+	Active bool
+	// Agent and Status are empty when no agent runs in the window.
+	Agent  Agent
+	Status Status
+}
+
+// applyAgentConfig installs the [status] and [resume] config once per
+// process, as cb does at startup.
+var applyAgentConfig = sync.OnceFunc(agentconfig.Apply)
+
+// Discover returns the configured projects with their worktrees and
+// sessions. It returns no projects when the config file is missing. Agent
+// statuses honor the config's [status] patterns, read on the first call. The
+// tmux and git commands it runs are killed once ctx is done.
+func Discover(ctx context.Context) ([]Project, error) {
+	applyAgentConfig()
+	result, err := discovery.NewServiceWithContext(ctx, tmux.NewClientWithContext(ctx)).Discover()
+	if err != nil {
+		return nil, err
+	}
+	return projectsFromResult(result), nil
+}
+
+// projectsFromResult converts a discovery result to the public types.
+func projectsFromResult(result discovery.Result) []Project {
+	projects := make([]Project, 0, len(result.Projects))
+	for _, p := range result.Projects {
+		project := Project{
+			Name:           p.Name,
+			Path:           p.Path,
+			Invalid:        p.InvalidError,
+			StaleWorktrees: p.StaleWorktrees,
+			Worktrees:      make([]Worktree, 0, len(p.Worktrees)),
+		}
+		for _, wt := range p.Worktrees {
+			worktree := Worktree{
+				Name:     wt.Name,
+				Path:     wt.Path,
+				Branch:   wt.Branch,
+				MainRepo: wt.IsMainRepo,
+				Missing:  wt.IsMissing,
+				Sessions: make([]Session, 0, len(wt.Sessions)),
+			}
+			for _, s := range wt.Sessions {
+				worktree.Sessions = append(worktree.Sessions, sessionFromNode(s, result))
+			}
+			project.Worktrees = append(project.Worktrees, worktree)
+		}
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+func sessionFromNode(s discovery.SessionNode, result discovery.Result) Session {
+	session := Session{
+		Name:      s.Name,
+		Status:    Status(s.Status),
+		Windows:   make([]Window, 0, len(s.Windows)),
+		Note:      s.Note,
+		Tags:      s.Tags,
+		Branch:    s.Branch,
+		Agent:     agentFrom(s.Agent),
+		CreatedAt: s.CreatedAt,
+	}
+	for _, w := range s.Windows {
+		target := w.Target(s.Name)
+		session.Windows = append(session.Windows, Window{
+			ID:     w.ID,
+			Index:  w.Index,
+			Name:   w.Name,
+			Active: w.Active,
+			Agent:  agentFrom(result.WindowAgents[target]),
+			Status: Status(result.WindowStatuses[target]),
+		})
+	}
+	return session
+}
+
+// agentFrom converts a detected agent, mapping "none" to "".
+func agentFrom(agent tmux.AgentType) Agent {
+	if agent == tmux.AgentNone {
+		return ""
+	}
+	return Agent(agent)
+}
@@ -0,0 +1,83 @@
+package clawdbay
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ronsanzone/clawd-bay/internal/discovery"
+	"github.com/ronsanzone/clawd-bay/internal/tmux"
+)
+
+func TestProjectsFromResult(t *testing.T) {
+	created := time.Unix(1700000000, 0)
+	result := discovery.Result{
+		Projects: []discovery.ProjectNode{
+			{
+				Name:           "app",
+				Path:           "/src/app",
+				StaleWorktrees: []string{".worktrees/app-old"},
+				Worktrees: []discovery.WorktreeNode{
+					{Name: "(main repo)", Path: "/src/app", IsMainRepo: true},
+					{
+						Name:   "app-feat",
+						Path:   "/src/app/.worktrees/app-feat",
+						Branch: "feat",
+						Sessions: []discovery.SessionNode{{
+							Name:      "cb_feat",
+							Status:    tmux.StatusWaiting,
+							Note:      "review",
+							Tags:      []string{"api"},
+							Branch:    "feat",
+							Agent:     tmux.AgentClaude,
+							CreatedAt: created,
+							Windows: []tmux.Window{
+								{ID: "@1", Index: 0, Name: "claude", Active: true},
+								{ID: "@2", Index: 1, Name: "shell"},
+							},
+						}},
+					},
+					{Name: "(missing worktree)", IsMissing: true},
+				},
+			},
+			{Name: "gone", Path: "/src/gone", InvalidError: "path does not exist"},
+		},
+		WindowStatuses: map[string]tmux.Status{"@1": tmux.StatusWaiting},
+		WindowAgents:   map[string]tmux.AgentType{"@1": tmux.AgentClaude, "@2": tmux.AgentNone},
+	}
+
+	want := []Project{
+		{
+			Name:           "app",
+			Path:           "/src/app",
+			StaleWorktrees: []string{".worktrees/app-old"},
+			Worktrees: []Worktree{
+				{Name: "(main repo)", Path: "/src/app", MainRepo: true, Sessions: []Session{}},
+				{
+					Name:   "app-feat",
+					Path:   "/src/app/.worktrees/app-feat",
+					Branch: "feat",
+					Sessions: []Session{{
+						Name:      "cb_feat",
+						Status:    StatusWaiting,
+						Note:      "review",
+						Tags:      []string{"api"},
+						Branch:    "feat",
+						Agent:     AgentClaude,
+						CreatedAt: created,
+						Windows: []Window{
+							{ID: "@1", Index: 0, Name: "claude", Active: true, Agent: AgentClaude, Status: StatusWaiting},
+							{ID: "@2", Index: 1, Name: "shell"},
+						},
+					}},
+				},
+				{Name: "(missing worktree)", Missing: true, Sessions: []Session{}},
+			},
+		},
+		{Name: "gone", Path: "/src/gone", Invalid: "path does not exist", Worktrees: []Worktree{}},
+	}
+
+	if got := projectsFromResult(result); !reflect.DeepEqual(got, want) {
+		t.Fatalf("projectsFromResult() = %+v, want %+v", got, want)
+	}
+}